@@ -527,9 +527,9 @@ type StorageConfig struct {
 	// UseFileRegistry is true if the file registry is needed (eg: encryption-at-rest).
 	// This may force the store version to versionFileRegistry if currently lower.
 	UseFileRegistry bool
-	// EncryptionOptions is a serialized protobuf set by Go CCL code and passed
-	// through to C CCL code to set up encryption-at-rest.  Must be set if and
-	// only if encryption is enabled, otherwise left empty.
+	// EncryptionOptions is a serialized encryptionpb.EncryptionOptions protobuf
+	// used to set up encryption-at-rest. Must be set if and only if encryption
+	// is enabled, otherwise left empty.
 	EncryptionOptions []byte
 }
 
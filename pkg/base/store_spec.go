@@ -176,7 +176,7 @@ type StoreSpec struct {
 	// This only applies to in-memory storage engine.
 	StickyInMemoryEngineID string
 	// UseFileRegistry is true if the "file registry" store version is desired.
-	// This is set by CCL code when encryption-at-rest is in use.
+	// This is set when encryption-at-rest is in use.
 	UseFileRegistry bool
 	// RocksDBOptions contains RocksDB specific options using a semicolon
 	// separated key-value syntax ("key1=value1; key2=value2").
@@ -185,9 +185,9 @@ type StoreSpec struct {
 	// Pebble OPTIONS file but treating any whitespace as a newline:
 	// (Eg, "[Options] delete_range_flush_delay=2s flush_split_bytes=4096")
 	PebbleOptions string
-	// EncryptionOptions is a serialized protobuf set by Go CCL code and passed
-	// through to C CCL code to set up encryption-at-rest.  Must be set if and
-	// only if encryption is enabled, otherwise left empty.
+	// EncryptionOptions is a serialized encryptionpb.EncryptionOptions protobuf
+	// used to set up encryption-at-rest. Must be set if and only if encryption
+	// is enabled, otherwise left empty.
 	EncryptionOptions []byte
 }
 
@@ -937,6 +937,32 @@ which use 'cockroach-data-tenant-X' for tenant 'X')
 `,
 	}
 
+	EnterpriseEncryption = FlagInfo{
+		Name: "enterprise-encryption",
+		Description: `
+Specify encryption options for one of the stores on a node. If multiple
+stores exist, the flag must be specified for each store.
+<PRE>
+
+  --enterprise-encryption=path=/mnt/ssd01,key=/keys/aes-128.key,old-key=plain
+
+</PRE>
+Valid fields:
+<PRE>
+
+  path    (required): must match the path of one of the stores
+  key     (required): path to the current key file, or "plain"
+  old-key (required): path to the previous key file, or "plain"
+  rotation-period   : amount of time after which data keys should be rotated
+
+</PRE>
+Key files can be generated with "cockroach gen encryption-key". To rotate the
+store key, restart the node with the new key file as "key" and the previous
+one as "old-key". Data keys are rotated automatically every rotation-period
+(default 168h).
+`,
+	}
+
 	StorageEngine = FlagInfo{
 		Name: "storage-engine",
 		Description: `
@@ -94,11 +94,6 @@ Create a ballast file to fill the store directory up to a given amount
 	RunE: runDebugBallast,
 }
 
-// PopulateStorageConfigHook is a callback set by CCL code.
-// It populates any needed fields in the StorageConfig.
-// It must do nothing in OSS code.
-var PopulateStorageConfigHook func(*base.StorageConfig) error
-
 func parsePositiveInt(arg string) (int64, error) {
 	i, err := strconv.ParseInt(arg, 10, 64)
 	if err != nil {
@@ -174,7 +169,7 @@ func OpenEngine(dir string, stopper *stop.Stopper, opts OpenEngineOptions) (stor
 		storage.MaxOpenFiles(int(maxOpenFiles)),
 		storage.CacheSize(server.DefaultCacheSize),
 		storage.Settings(serverCfg.Settings),
-		storage.Hook(PopulateStorageConfigHook),
+		storage.Hook(fillEncryptionOptionsForStore),
 		storage.CombineOptions(opts.configOptions()...))
 	if err != nil {
 		return nil, err
@@ -1527,11 +1522,12 @@ func runDebugIntentCount(cmd *cobra.Command, args []string) error {
 }
 
 // DebugCommandsRequiringEncryption lists debug commands that access Pebble through the engine
-// and need encryption flags (injected by CCL code).
+// and need encryption flags.
 // Note: do NOT include commands that just call Pebble code without setting up an engine.
 var DebugCommandsRequiringEncryption = []*cobra.Command{
 	debugCheckStoreCmd,
 	debugCompactCmd,
+	debugEncryptionStatusCmd,
 	debugGCCmd,
 	debugIntentCount,
 	debugKeysCmd,
@@ -1547,6 +1543,8 @@ var DebugCommandsRequiringEncryption = []*cobra.Command{
 var debugCmds = []*cobra.Command{
 	debugCheckStoreCmd,
 	debugCompactCmd,
+	debugEncryptionActiveKeyCmd,
+	debugEncryptionStatusCmd,
 	debugGCCmd,
 	debugIntentCount,
 	debugKeysCmd,
@@ -1757,10 +1755,8 @@ func pebbleCryptoInitializer() error {
 		Dir:      serverCfg.Stores.Specs[0].Path,
 	}
 
-	if PopulateStorageConfigHook != nil {
-		if err := PopulateStorageConfigHook(&storageConfig); err != nil {
-			return err
-		}
+	if err := fillEncryptionOptionsForStore(&storageConfig); err != nil {
+		return err
 	}
 
 	cfg := storage.PebbleConfig{
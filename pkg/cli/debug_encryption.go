@@ -0,0 +1,225 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/clierrorplus"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption"
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/spf13/cobra"
+)
+
+var debugEncryptionStatusCmd = &cobra.Command{
+	Use:   "encryption-status <directory>",
+	Short: "show encryption status of a store",
+	Long: `
+Shows encryption status of the store located in 'directory'.
+Encryption keys must be specified in the '--enterprise-encryption' flag.
+
+Displays all keys in the data and store key managers, with the list of files
+currently encrypted with each of them. Files are not included for a particular
+key if there are none.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: clierrorplus.MaybeDecorateError(runDebugEncryptionStatus),
+}
+
+var debugEncryptionActiveKeyCmd = &cobra.Command{
+	Use:   "encryption-active-key <directory>",
+	Short: "return ID of the active store key",
+	Long: `
+Display the algorithm and key ID of the active store key for existing data
+directory 'directory'. Does not require knowing the key.
+
+Some sample outputs:
+Plaintext:            # encryption not enabled
+AES128_CTR:be235...   # AES-128 encryption with store key ID
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: clierrorplus.MaybeDecorateError(runDebugEncryptionActiveKey),
+}
+
+// prettyDataKey is the description of a data key printed by
+// debug encryption-status.
+type prettyDataKey struct {
+	ID      string
+	Active  bool `json:",omitempty"`
+	Exposed bool `json:",omitempty"`
+	Created string
+	Files   []string `json:",omitempty"`
+}
+
+// prettyStoreKey is the description of a store key printed by
+// debug encryption-status, along with the data keys it encrypted.
+type prettyStoreKey struct {
+	ID       string
+	Active   bool `json:",omitempty"`
+	Type     string
+	Created  string
+	Source   string
+	DataKeys []prettyDataKey `json:",omitempty"`
+}
+
+func formatKeyCreationTime(info *encryptionpb.KeyInfo) string {
+	return timeutil.Unix(info.CreationTime, 0).Format(time.RFC3339)
+}
+
+func runDebugEncryptionStatus(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	db, err := OpenExistingStore(args[0], stopper, true /* readOnly */, false /* disableAutomaticCompactions */)
+	if err != nil {
+		return err
+	}
+
+	registries, err := db.GetEncryptionRegistries()
+	if err != nil {
+		return err
+	}
+	if len(registries.KeyRegistry) == 0 {
+		return nil
+	}
+
+	var fileRegistry enginepb.FileRegistry
+	if err := protoutil.Unmarshal(registries.FileRegistry, &fileRegistry); err != nil {
+		return err
+	}
+	var keyRegistry encryptionpb.DataKeysRegistry
+	if err := protoutil.Unmarshal(registries.KeyRegistry, &keyRegistry); err != nil {
+		return err
+	}
+
+	// Attach the file names to the data keys encrypting them.
+	filesByKeyID := make(map[string][]string)
+	for name, entry := range fileRegistry.Files {
+		if entry.EnvType != enginepb.EnvType_Data {
+			continue
+		}
+		var settings encryptionpb.EncryptionSettings
+		if err := protoutil.Unmarshal(entry.EncryptionSettings, &settings); err != nil {
+			return err
+		}
+		filesByKeyID[settings.KeyId] = append(filesByKeyID[settings.KeyId], name)
+	}
+
+	// Build the list of store keys, each with the data keys it was the active
+	// store key for. Keys are sorted by creation time.
+	storeKeys := make([]*encryptionpb.KeyInfo, 0, len(keyRegistry.StoreKeys))
+	for _, storeKey := range keyRegistry.StoreKeys {
+		storeKeys = append(storeKeys, storeKey)
+	}
+	sort.Slice(storeKeys, func(i, j int) bool {
+		return storeKeys[i].CreationTime < storeKeys[j].CreationTime
+	})
+	dataKeys := make([]*encryptionpb.KeyInfo, 0, len(keyRegistry.DataKeys))
+	for _, dataKey := range keyRegistry.DataKeys {
+		dataKeys = append(dataKeys, dataKey.Info)
+	}
+	sort.Slice(dataKeys, func(i, j int) bool {
+		return dataKeys[i].CreationTime < dataKeys[j].CreationTime
+	})
+
+	out := make([]prettyStoreKey, 0, len(storeKeys))
+	for _, storeKey := range storeKeys {
+		s := prettyStoreKey{
+			ID:      storeKey.KeyId,
+			Active:  storeKey.KeyId == keyRegistry.ActiveStoreKeyId,
+			Type:    storeKey.EncryptionType.String(),
+			Created: formatKeyCreationTime(storeKey),
+			Source:  storeKey.Source,
+		}
+		for _, dataKey := range dataKeys {
+			if dataKey.ParentKeyId != storeKey.KeyId {
+				continue
+			}
+			files := filesByKeyID[dataKey.KeyId]
+			sort.Strings(files)
+			s.DataKeys = append(s.DataKeys, prettyDataKey{
+				ID:      dataKey.KeyId,
+				Active:  dataKey.KeyId == keyRegistry.ActiveDataKeyId,
+				Exposed: dataKey.WasExposed,
+				Created: formatKeyCreationTime(dataKey),
+				Files:   files,
+			})
+		}
+		out = append(out, s)
+	}
+
+	j, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", j)
+	return nil
+}
+
+func runDebugEncryptionActiveKey(cmd *cobra.Command, args []string) error {
+	keyType, keyID, err := getActiveEncryptionKey(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s:%s\n", keyType, keyID)
+	return nil
+}
+
+// getActiveEncryptionKey opens the file registry directly, bypassing Pebble.
+// This allows looking up the active store key ID without knowing the key.
+func getActiveEncryptionKey(dir string) (string, string, error) {
+	// If the data directory does not exist, we return an error.
+	if _, err := os.Stat(dir); err != nil {
+		return "", "", errors.Wrapf(err, "data directory %s does not exist", dir)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	fileRegistry := &storage.PebbleFileRegistry{FS: vfs.Default, DBDir: dir, ReadOnly: true}
+	if err := fileRegistry.Load(); err != nil {
+		return "", "", errors.Wrapf(err, "could not load the file registry of %s", dir)
+	}
+	defer fileRegistry.Close()
+
+	// The key registry is encrypted with the active store key. If it has no
+	// entry, encryption was never enabled on this store.
+	entry := fileRegistry.GetFileEntry(filepath.Join(dir, encryption.KeyRegistryFilename))
+	if entry == nil {
+		return encryptionpb.EncryptionType_Plaintext.String(), "", nil
+	}
+	if entry.EnvType != enginepb.EnvType_Store {
+		return "", "", fmt.Errorf("key registry file %s does not have a Store env type",
+			encryption.KeyRegistryFilename)
+	}
+
+	var settings encryptionpb.EncryptionSettings
+	if err := protoutil.Unmarshal(entry.EncryptionSettings, &settings); err != nil {
+		return "", "", errors.Wrapf(err, "could not unmarshal encryption settings for %s",
+			encryption.KeyRegistryFilename)
+	}
+	return settings.EncryptionType.String(), settings.KeyId, nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logflags"
 	"github.com/cockroachdb/cockroach/pkg/util/netutil/addr"
@@ -56,6 +57,7 @@ var serverHTTPAdvertiseAddr, serverHTTPAdvertisePort string
 var localityAdvertiseHosts localityList
 var startBackground bool
 var storeSpecs base.StoreSpecList
+var storeEncryptionSpecs encryption.EncryptionSpecList
 
 // initPreFlagsDefaults initializes the values of the global variables
 // defined above.
@@ -84,6 +86,7 @@ func initPreFlagsDefaults() {
 	startBackground = false
 
 	storeSpecs = base.StoreSpecList{}
+	storeEncryptionSpecs = encryption.EncryptionSpecList{}
 }
 
 // AddPersistentPreRunE add 'fn' as a persistent pre-run function to 'cmd'.
@@ -533,6 +536,7 @@ func init() {
 		varFlag(f, &serverCfg.Locality, cliflags.Locality)
 
 		varFlag(f, &storeSpecs, cliflags.Store)
+		varFlag(f, &storeEncryptionSpecs, cliflags.EnterpriseEncryption)
 		varFlag(f, &serverCfg.StorageEngine, cliflags.StorageEngine)
 		varFlag(f, &serverCfg.MaxOffset, cliflags.MaxOffset)
 		stringFlag(f, &serverCfg.ClockDevicePath, cliflags.ClockDevice)
@@ -996,6 +1000,11 @@ func init() {
 		// TODO(ayang): clean up so dir isn't passed to both pebble and --store
 		f := DebugPebbleCmd.PersistentFlags()
 		varFlag(f, &storeSpecs, cliflags.Store)
+		varFlag(f, &storeEncryptionSpecs, cliflags.EnterpriseEncryption)
+	}
+	for _, c := range DebugCommandsRequiringEncryption {
+		f := c.Flags()
+		varFlag(f, &storeEncryptionSpecs, cliflags.EnterpriseEncryption)
 	}
 	{
 		for _, c := range []*cobra.Command{
@@ -1301,6 +1310,22 @@ func extraStoreFlagInit(cmd *cobra.Command) error {
 		ss.Path = absPath
 		serverCfg.Stores.Specs[i] = ss
 	}
+	// Attach the encryption options to the stores they were specified for.
+	return encryption.PopulateStoreSpecWithEncryption(serverCfg.Stores, storeEncryptionSpecs)
+}
+
+// fillEncryptionOptionsForStore fills the encryption options of cfg from the
+// --enterprise-encryption flag, if one matches the store directory.
+func fillEncryptionOptionsForStore(cfg *base.StorageConfig) error {
+	opts, err := encryption.EncryptionOptionsForStore(cfg.Dir, storeEncryptionSpecs)
+	if err != nil {
+		return err
+	}
+
+	if opts != nil {
+		cfg.EncryptionOptions = opts
+		cfg.UseFileRegistry = true
+	}
 	return nil
 }
 
@@ -123,9 +123,9 @@ eexpect "ALTER TABLE"
 eexpect $prompt
 end_test
 
-start_test "Ensure that licensed commands with -e error when license acquisition is disabled"
+start_test "Ensure that partitioning does not require a license"
 send "$argv demo --disable-demo-license -e \"ALTER TABLE users PARTITION BY LIST (city) (PARTITION p1 VALUES IN ('new york'))\"\r"
-eexpect "ERROR: use of partitions requires an enterprise license"
+eexpect "ALTER TABLE"
 eexpect $prompt
 end_test
 
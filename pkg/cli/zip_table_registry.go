@@ -359,6 +359,7 @@ var zipInternalTablesPerCluster = DebugZipTableRegistry{
 			"writes_per_replica",
 			"metrics",
 			"properties",
+			"encryption_status",
 		},
 	},
 	"crdb_internal.partitions": {
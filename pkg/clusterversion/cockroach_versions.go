@@ -349,6 +349,31 @@ const (
 	// V22_1 is CockroachDB v22.1. It's used for all v22.1.x patch releases.
 	V22_1
 
+	// NotificationsTable adds system.notifications, which backs LISTEN and
+	// NOTIFY.
+	NotificationsTable
+	// MultidimensionalArrays enables the encodings of arrays with multiple
+	// dimensions or a non-default lower bound.
+	MultidimensionalArrays
+	// UserDefinedFunctions enables CREATE FUNCTION, which adds function
+	// descriptors to the catalog.
+	UserDefinedFunctions
+	// RowLevelTriggers enables CREATE TRIGGER, which adds triggers to table
+	// descriptors.
+	RowLevelTriggers
+	// DeferrableConstraints enables DEFERRABLE foreign key and unique
+	// constraints, whose deferrability is stored in table descriptors.
+	DeferrableConstraints
+	// DomainsAndCompositeTypes enables CREATE DOMAIN and composite types,
+	// which add type descriptors of new kinds to the catalog.
+	DomainsAndCompositeTypes
+	// ExclusionConstraints enables exclusion constraints, which add a new field
+	// to table descriptors.
+	ExclusionConstraints
+	// ReadCommittedIsolation allows transactions to run under READ COMMITTED
+	// isolation, which nodes running an older version would not honor.
+	ReadCommittedIsolation
+
 	// *************************************************
 	// Step (1): Add new versions here.
 	// Do not add new versions to a patch release.
@@ -603,6 +628,38 @@ var versionsSingleton = keyedVersions{
 		Key:     V22_1,
 		Version: roachpb.Version{Major: 22, Minor: 1},
 	},
+	{
+		Key:     NotificationsTable,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 2},
+	},
+	{
+		Key:     MultidimensionalArrays,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 4},
+	},
+	{
+		Key:     UserDefinedFunctions,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 6},
+	},
+	{
+		Key:     RowLevelTriggers,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 8},
+	},
+	{
+		Key:     DeferrableConstraints,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 10},
+	},
+	{
+		Key:     DomainsAndCompositeTypes,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 12},
+	},
+	{
+		Key:     ExclusionConstraints,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 14},
+	},
+	{
+		Key:     ReadCommittedIsolation,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 16},
+	},
 
 	// *************************************************
 	// Step (2): Add new versions here.
@@ -630,7 +687,9 @@ var (
 func init() {
 	const isReleaseBranch = true
 	if isReleaseBranch {
-		if binaryVersion != ByKey(V22_1) {
+		// The versions added after the release are internal versions of the
+		// release (i.e. 22.1-x) which gate the features backported to it.
+		if v := ByKey(V22_1); binaryVersion.Major != v.Major || binaryVersion.Minor != v.Minor {
 			panic("unexpected cluster version greater than release's binary version")
 		}
 	}
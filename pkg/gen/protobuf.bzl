@@ -34,6 +34,7 @@ PROTOBUF_SRCS = [
   "//pkg/server/serverpb:serverpb_go_proto",
   "//pkg/server/status/statuspb:statuspb_go_proto",
   "//pkg/settings:settings_go_proto",
+  "//pkg/sql/backup/backuppb:backuppb_go_proto",
   "//pkg/sql/catalog/catpb:catpb_go_proto",
   "//pkg/sql/catalog/descpb:descpb_go_proto",
   "//pkg/sql/contentionpb:contentionpb_go_proto",
@@ -49,7 +50,9 @@ PROTOBUF_SRCS = [
   "//pkg/sql/stats:stats_go_proto",
   "//pkg/sql/types:types_go_proto",
   "//pkg/startupmigrations/leasemanager:leasemanager_go_proto",
+  "//pkg/storage/encryption/encryptionpb:encryptionpb_go_proto",
   "//pkg/storage/enginepb:enginepb_go_proto",
+  "//pkg/streaming/streampb:streampb_go_proto",
   "//pkg/testutils/grpcutils:grpcutils_go_proto",
   "//pkg/ts/catalog:catalog_go_proto",
   "//pkg/ts/tspb:tspb_go_proto",
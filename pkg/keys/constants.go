@@ -460,6 +460,7 @@ const (
 	TenantUsageTableID                  = 45
 	SQLInstancesTableID                 = 46
 	SpanConfigurationsTableID           = 47
+	NotificationsTableID                = 48
 )
 
 // CommentType the type of the schema object on which a comment has been
@@ -481,6 +482,8 @@ const (
 	SchemaCommentType CommentType = 4
 	// ConstraintCommentType comment on a constraint.
 	ConstraintCommentType CommentType = 5
+	// FunctionCommentType comment on a user-defined function.
+	FunctionCommentType CommentType = 6
 )
 
 const (
@@ -175,8 +175,8 @@ This counts the number of ranges with an active rangefeed that are performing ca
 
 // CanSendToFollower is used by the DistSender to determine if it needs to look
 // up the current lease holder for a request. It is used by the
-// followerreadsccl code to inject logic to check if follower reads are enabled.
-// By default, without CCL code, this function returns false.
+// kvfollowerreads package to inject logic to check if follower reads are
+// enabled. By default, without that package, this function returns false.
 var CanSendToFollower = func(
 	_ uuid.UUID,
 	_ *cluster.Settings,
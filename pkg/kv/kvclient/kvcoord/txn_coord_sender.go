@@ -18,6 +18,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -773,7 +774,8 @@ func (tc *TxnCoordSender) UpdateStateOnRemoteRetryableErr(
 // the TxnCoordSender's state. Depending on the error, the TxnCoordSender might
 // not be usable afterwards (in case of TransactionAbortedError). The caller is
 // expected to check the ID of the resulting transaction. If the TxnCoordSender
-// can still be used, it will have been prepared for a new epoch.
+// can still be used, it will have been prepared for a new epoch, unless the
+// error allows for a partial retry (see PrepareForPartialRetry).
 func (tc *TxnCoordSender) handleRetryableErrLocked(
 	ctx context.Context, pErr *roachpb.Error,
 ) *roachpb.TransactionRetryWithProtoRefreshError {
@@ -839,6 +841,17 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 		return retErr
 	}
 
+	// If the transaction uses a per-statement read snapshot, the error might be
+	// handled by retrying only the statement that encountered it, without
+	// bumping the epoch. Whether that happens is up to the client, so the
+	// transaction is left untouched for now: either PrepareForPartialRetry
+	// moves it to the new read timestamp, or ClearTxnRetryableErr restarts it
+	// at a new epoch.
+	if roachpb.CanRetryStatementWithoutRestart(pErr) {
+		retErr.PartialRetry = true
+		return retErr
+	}
+
 	// This is where we get a new epoch.
 	tc.mu.txn.Update(&newTxn)
 
@@ -1024,6 +1037,24 @@ func (tc *TxnCoordSender) SetUserPriority(pri roachpb.UserPriority) error {
 	return nil
 }
 
+// SetIsoLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) SetIsoLevel(isoLevel isolation.Level) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.mu.active && isoLevel != tc.mu.txn.IsoLevel {
+		return errors.New("cannot change the isolation level of a running transaction")
+	}
+	tc.mu.txn.IsoLevel = isoLevel
+	return nil
+}
+
+// IsoLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) IsoLevel() isolation.Level {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.mu.txn.IsoLevel
+}
+
 // SetDebugName is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) SetDebugName(name string) {
 	tc.mu.Lock()
@@ -1138,6 +1169,11 @@ func (tc *TxnCoordSender) IsSerializablePushAndRefreshNotPossible() bool {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
+	// Transactions that tolerate write skew can commit after being pushed
+	// without refreshing their reads.
+	if tc.mu.txn.IsoLevel.ToleratesWriteSkew() {
+		return false
+	}
 	isTxnPushed := tc.mu.txn.WriteTimestamp != tc.mu.txn.ReadTimestamp
 	refreshAttemptNotPossible := tc.interceptorAlloc.txnSpanRefresher.refreshInvalid ||
 		tc.mu.txn.CommitTimestampFixed
@@ -1305,12 +1341,33 @@ func (tc *TxnCoordSender) PrepareRetryableError(ctx context.Context, msg string)
 }
 
 // Step is part of the TxnSender interface.
-func (tc *TxnCoordSender) Step(ctx context.Context) error {
+func (tc *TxnCoordSender) Step(ctx context.Context, allowReadTimestampStep bool) error {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
+	if allowReadTimestampStep && tc.mu.txn.IsoLevel.PerStatementReadSnapshot() {
+		tc.maybeStepReadTimestampLocked(ctx)
+	}
 	return tc.interceptorAlloc.txnSeqNumAllocator.stepLocked(ctx)
 }
 
+// maybeStepReadTimestampLocked moves the read timestamp of a transaction that
+// uses a per-statement read snapshot forward to the current time, so that the
+// next statement observes all the writes committed before it started. The
+// uncertainty interval is reset accordingly. The read timestamp is not moved
+// if the transaction's commit timestamp is fixed or if the transaction is
+// not in a state where it can perform further reads.
+func (tc *TxnCoordSender) maybeStepReadTimestampLocked(ctx context.Context) {
+	if tc.typ != kv.RootTxn || tc.mu.txnState != txnPending || tc.mu.txn.CommitTimestampFixed {
+		return
+	}
+	now := tc.clock.Now()
+	tc.mu.txn.BumpReadTimestamp(now)
+	tc.mu.txn.GlobalUncertaintyLimit = now.Add(tc.clock.MaxOffset().Nanoseconds(), 0)
+	tc.mu.txn.ResetObservedTimestamps()
+	tc.interceptorAlloc.txnSpanRefresher.readTimestampSteppedLocked()
+	log.VEventf(ctx, 2, "stepped read timestamp to %s", tc.mu.txn.ReadTimestamp)
+}
+
 // SetReadSeqNum is part of the TxnSender interface.
 func (tc *TxnCoordSender) SetReadSeqNum(seq enginepb.TxnSeq) error {
 	tc.mu.Lock()
@@ -1399,11 +1456,60 @@ func (tc *TxnCoordSender) ClearTxnRetryableErr(ctx context.Context) {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 	if tc.mu.txnState == txnRetryableError {
+		retErr := tc.mu.storedRetryableErr
 		tc.mu.storedRetryableErr = nil
 		tc.mu.txnState = txnPending
+		if retErr.PartialRetry {
+			// The error allowed for a partial retry, but the client is restarting
+			// the whole transaction instead. The epoch has not been bumped yet.
+			tc.restartAfterPartialRetryErrLocked(ctx, retErr)
+		}
+	}
+}
+
+// restartAfterPartialRetryErrLocked bumps the epoch of the transaction after
+// an error that allowed for a partial retry, for use when the transaction
+// needs to be restarted in its entirety.
+func (tc *TxnCoordSender) restartAfterPartialRetryErrLocked(
+	ctx context.Context, retErr *roachpb.TransactionRetryWithProtoRefreshError,
+) {
+	newTxn := retErr.Transaction.Clone()
+	newTxn.Restart(tc.mu.userPriority, newTxn.Priority, newTxn.WriteTimestamp)
+	tc.mu.txn.Update(newTxn)
+
+	log.VEventf(ctx, 2, "resetting epoch-based coordinator state on retry")
+	for _, reqInt := range tc.interceptorStack {
+		reqInt.epochBumpedLocked()
 	}
 }
 
+// PrepareForPartialRetry is part of the TxnSender interface.
+func (tc *TxnCoordSender) PrepareForPartialRetry(ctx context.Context) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.mu.txnState != txnRetryableError {
+		return errors.AssertionFailedf(
+			"cannot prepare for partial retry in state %s", tc.mu.txnState)
+	}
+	retErr := tc.mu.storedRetryableErr
+	if !retErr.PartialRetry {
+		return errors.AssertionFailedf(
+			"cannot prepare for partial retry after error: %s", retErr)
+	}
+	tc.mu.storedRetryableErr = nil
+	tc.mu.txnState = txnPending
+
+	// Move the transaction to the read timestamp computed for the retry. The
+	// epoch stays the same, so the writes performed by previous statements
+	// remain valid; the client is expected to roll back the writes of the
+	// statement being retried to a savepoint.
+	log.VEventf(ctx, 2, "partially retrying transaction at timestamp %s because of: %s",
+		retErr.Transaction.ReadTimestamp, retErr)
+	tc.mu.txn.Update(&retErr.Transaction)
+	tc.interceptorAlloc.txnSpanRefresher.readTimestampSteppedLocked()
+	return nil
+}
+
 // HasPerformedReads is part of the TxnSender interface.
 func (tc *TxnCoordSender) HasPerformedReads() bool {
 	tc.mu.Lock()
@@ -446,9 +446,13 @@ func (sr *txnSpanRefresher) maybeRefreshPreemptivelyLocked(
 	// If true, tryRefreshTxnSpans will trivially succeed.
 	refreshFree := ba.CanForwardReadTimestamp
 
-	// If true, this batch is guaranteed to fail without a refresh.
+	// If true, this batch is guaranteed to fail without a refresh. Transactions
+	// whose isolation level tolerates write skew are allowed to commit with a
+	// write timestamp above their read timestamp, so they never need to refresh
+	// before committing.
 	args, hasET := ba.GetArg(roachpb.EndTxn)
-	refreshInevitable := hasET && args.(*roachpb.EndTxnRequest).Commit
+	refreshInevitable := hasET && args.(*roachpb.EndTxnRequest).Commit &&
+		!ba.Txn.IsoLevel.ToleratesWriteSkew()
 
 	// If neither condition is true, defer the refresh.
 	if !refreshFree && !refreshInevitable && !force {
@@ -588,13 +592,12 @@ func (sr *txnSpanRefresher) appendRefreshSpans(
 			errors.Safe(readTimestamp), errors.Safe(sr.refreshedTimestamp), ba)
 	}
 
-	ba.RefreshSpanIterate(br, func(span roachpb.Span) {
+	return ba.RefreshSpanIterate(br, func(span roachpb.Span) {
 		if log.ExpensiveLogEnabled(ctx, 3) {
 			log.VEventf(ctx, 3, "recording span to refresh: %s", span.String())
 		}
 		sr.refreshFootprint.insert(span)
 	})
-	return nil
 }
 
 // canForwardReadTimestampWithoutRefresh returns whether the transaction can
@@ -683,6 +686,22 @@ func (sr *txnSpanRefresher) importLeafFinalState(
 
 // epochBumpedLocked implements the txnInterceptor interface.
 func (sr *txnSpanRefresher) epochBumpedLocked() {
+	sr.resetLocked()
+}
+
+// readTimestampSteppedLocked is called when the transaction's read timestamp
+// is moved forward without an epoch bump, either because a new statement of a
+// transaction with a per-statement read snapshot is starting or because a
+// single statement of such a transaction is being retried. Reads performed at
+// the previous read timestamp never need to be refreshed, so they are
+// forgotten.
+func (sr *txnSpanRefresher) readTimestampSteppedLocked() {
+	sr.resetLocked()
+}
+
+// resetLocked forgets all the refresh spans collected so far, as well as the
+// timestamp up to which they've been refreshed.
+func (sr *txnSpanRefresher) resetLocked() {
 	sr.refreshFootprint.clear()
 	sr.refreshInvalid = false
 	sr.refreshedTimestamp.Reset()
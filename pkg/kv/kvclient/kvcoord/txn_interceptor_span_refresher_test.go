@@ -16,6 +16,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -1135,6 +1136,71 @@ func TestTxnSpanRefresherEpochIncrement(t *testing.T) {
 	require.Equal(t, hlc.Timestamp{}, tsr.refreshedTimestamp)
 }
 
+// TestTxnSpanRefresherReadCommitted tests that the txnSpanRefresher does not
+// refresh a transaction that tolerates write skew before committing it, and
+// that it forgets the refresh spans of such a transaction when its read
+// timestamp is stepped.
+func TestTxnSpanRefresherReadCommitted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	tsr, mockSender := makeMockTxnSpanRefresher()
+
+	txn := makeTxnProto()
+	txn.IsoLevel = isolation.ReadCommitted
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+
+	// Send a Scan request to collect refresh spans.
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	scanArgs := roachpb.ScanRequest{RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyB}}
+	ba.Add(&scanArgs)
+
+	br, pErr := tsr.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, []roachpb.Span{scanArgs.Span()}, tsr.refreshFootprint.asSlice())
+
+	// Push the txn and send an EndTxn request. A serializable transaction would
+	// need to refresh before committing; this one commits at its pushed write
+	// timestamp without refreshing.
+	txn.WriteTimestamp = txn.WriteTimestamp.Add(1, 0)
+	origReadTs := txn.ReadTimestamp
+	pushedWriteTs := txn.WriteTimestamp
+
+	ba.Requests = nil
+	etArgs := roachpb.EndTxnRequest{Commit: true}
+	ba.Add(&etArgs)
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 1)
+		require.False(t, ba.CanForwardReadTimestamp)
+		require.IsType(t, &roachpb.EndTxnRequest{}, ba.Requests[0].GetInner())
+
+		// The transaction should not be refreshed.
+		require.Equal(t, origReadTs, ba.Txn.ReadTimestamp)
+		require.Equal(t, pushedWriteTs, ba.Txn.WriteTimestamp)
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn.Clone()
+		br.Txn.Status = roachpb.COMMITTED
+		return br, nil
+	})
+
+	br, pErr = tsr.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, int64(0), tsr.refreshSuccess.Count())
+	require.Equal(t, int64(0), tsr.refreshFail.Count())
+
+	// Stepping the read timestamp clears the spans.
+	tsr.readTimestampSteppedLocked()
+
+	require.Equal(t, []roachpb.Span(nil), tsr.refreshFootprint.asSlice())
+	require.False(t, tsr.refreshInvalid)
+	require.Equal(t, hlc.Timestamp{}, tsr.refreshedTimestamp)
+}
+
 // TestTxnSpanRefresherSavepoint checks that the span refresher can savepoint
 // its state and restore it.
 func TestTxnSpanRefresherSavepoint(t *testing.T) {
@@ -0,0 +1,117 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package kvfollowerreads implements the client side of follower reads: the
+// DistSender routes reads below the closed timestamp to the nearest replica.
+// The replicas serve follower reads on their own, see
+// kvserver.Replica.canServeFollowerReadRLocked. The SQL layer uses the
+// helpers of this package to plan such reads on the nearest replica, see
+// sql.followerReadOracle.
+package kvfollowerreads
+
+import (
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// ClosedTimestampPropagationSlack is used by follower_read_timestamp() as a
+// measure of how long closed timestamp updates are supposed to take from the
+// leaseholder to the followers.
+var ClosedTimestampPropagationSlack = settings.RegisterDurationSetting(
+	settings.TenantReadOnly,
+	"kv.closed_timestamp.propagation_slack",
+	"a conservative estimate of the amount of time expect for closed timestamps to "+
+		"propagate from a leaseholder to followers. This is taken into account by "+
+		"follower_read_timestamp().",
+	time.Second,
+	settings.NonNegativeDuration,
+)
+
+// FollowerReadLag returns the (negative) offset duration from hlc.Now()
+// which should be used to request a follower read. The same value is used to
+// determine at the kv layer if a query can use a follower read for ranges with
+// the default LAG_BY_CLUSTER_SETTING closed timestamp policy.
+func FollowerReadLag(st *cluster.Settings) time.Duration {
+	targetDuration := closedts.TargetDuration.Get(&st.SV)
+	sideTransportInterval := closedts.SideTransportCloseInterval.Get(&st.SV)
+	slack := ClosedTimestampPropagationSlack.Get(&st.SV)
+	// Zero targetDuration means follower reads are disabled.
+	if targetDuration == 0 {
+		// Returning an infinitely large negative value would push safe
+		// request timestamp into the distant past thus disabling follower reads.
+		return math.MinInt64
+	}
+	return -targetDuration - sideTransportInterval - slack
+}
+
+// getGlobalReadsLead returns the (positive) offset duration from hlc.Now()
+// which clients can expect followers of a range with the LEAD_FOR_GLOBAL_READS
+// closed timestamp policy to have closed off. This offset is equal to the
+// maximum clock offset, allowing present-time (i.e. those not pushed into the
+// future) transactions to serve reads from followers.
+func getGlobalReadsLead(clock *hlc.Clock) time.Duration {
+	return clock.MaxOffset()
+}
+
+// Enabled returns whether follower reads are enabled.
+func Enabled(st *cluster.Settings) bool {
+	return kvserver.FollowerReadsEnabled.Get(&st.SV)
+}
+
+// ClosedTimestampLikelySufficient determines if a request with a given required
+// frontier timestamp is likely to be below a follower's closed timestamp and
+// serviceable as a follower read were the request to be sent to a follower
+// replica.
+func ClosedTimestampLikelySufficient(
+	st *cluster.Settings,
+	clock *hlc.Clock,
+	ctPolicy roachpb.RangeClosedTimestampPolicy,
+	requiredFrontierTS hlc.Timestamp,
+) bool {
+	var offset time.Duration
+	switch ctPolicy {
+	case roachpb.LAG_BY_CLUSTER_SETTING:
+		offset = FollowerReadLag(st)
+	case roachpb.LEAD_FOR_GLOBAL_READS:
+		offset = getGlobalReadsLead(clock)
+	default:
+		panic("unknown RangeClosedTimestampPolicy")
+	}
+	expectedClosedTS := clock.Now().Add(offset.Nanoseconds(), 0)
+	return requiredFrontierTS.LessEq(expectedClosedTS)
+}
+
+// canSendToFollower implements kvcoord.CanSendToFollower: a batch may be sent
+// to a follower if it can be evaluated there and its timestamp is likely to be
+// closed by the time it arrives.
+func canSendToFollower(
+	_ uuid.UUID,
+	st *cluster.Settings,
+	clock *hlc.Clock,
+	ctPolicy roachpb.RangeClosedTimestampPolicy,
+	ba roachpb.BatchRequest,
+) bool {
+	return kvserver.BatchCanBeEvaluatedOnFollower(ba) &&
+		ClosedTimestampLikelySufficient(st, clock, ctPolicy, ba.RequiredFrontier()) &&
+		Enabled(st)
+}
+
+func init() {
+	kvcoord.CanSendToFollower = canSendToFollower
+}
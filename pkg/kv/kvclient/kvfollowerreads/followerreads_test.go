@@ -0,0 +1,97 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvfollowerreads_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanSendToFollower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	clock := hlc.NewClock(hlc.NewManualClock(timeutil.Now().UnixNano()).UnixNano, base.DefaultMaxClockOffset)
+	now := clock.Now()
+	stale := now.Add(-time.Minute.Nanoseconds(), 0)
+
+	txn := func(ts hlc.Timestamp) *roachpb.Transaction {
+		txn := roachpb.MakeTransaction("txn", nil, 0, ts, 0 /* maxOffsetNs */, 1)
+		return &txn
+	}
+	batch := func(txn *roachpb.Transaction, req roachpb.Request) roachpb.BatchRequest {
+		var ba roachpb.BatchRequest
+		ba.Txn = txn
+		ba.Add(req)
+		return ba
+	}
+
+	for _, tc := range []struct {
+		name     string
+		ba       roachpb.BatchRequest
+		ctPolicy roachpb.RangeClosedTimestampPolicy
+		disabled bool
+		exp      bool
+	}{
+		{
+			name: "stale read",
+			ba:   batch(txn(stale), &roachpb.GetRequest{}),
+			exp:  true,
+		},
+		{
+			name: "stale locking read",
+			ba:   batch(txn(stale), &roachpb.ScanRequest{KeyLocking: lock.Exclusive}),
+			exp:  false,
+		},
+		{
+			name: "stale write",
+			ba:   batch(txn(stale), &roachpb.PutRequest{}),
+			exp:  false,
+		},
+		{
+			name: "current read",
+			ba:   batch(txn(now), &roachpb.GetRequest{}),
+			exp:  false,
+		},
+		{
+			name:     "current read, global reads policy",
+			ba:       batch(txn(now), &roachpb.GetRequest{}),
+			ctPolicy: roachpb.LEAD_FOR_GLOBAL_READS,
+			exp:      true,
+		},
+		{
+			name:     "stale read, follower reads disabled",
+			ba:       batch(txn(stale), &roachpb.GetRequest{}),
+			disabled: true,
+			exp:      false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			st := cluster.MakeTestingClusterSettings()
+			kvserver.FollowerReadsEnabled.Override(context.Background(), &st.SV, !tc.disabled)
+			require.Equal(t, tc.exp, kvcoord.CanSendToFollower(uuid.UUID{}, st, clock, tc.ctPolicy, tc.ba))
+		})
+	}
+}
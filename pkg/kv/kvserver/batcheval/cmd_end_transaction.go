@@ -464,8 +464,10 @@ func IsEndTxnTriggeringRetryError(
 		isTxnPushed := txn.WriteTimestamp != readTimestamp
 
 		// Return a transaction retry error if the commit timestamp isn't equal to
-		// the txn timestamp.
-		if isTxnPushed {
+		// the txn timestamp. Isolation levels that tolerate write skew are
+		// allowed to commit at a timestamp above their read timestamp without
+		// refreshing their reads.
+		if isTxnPushed && !txn.IsoLevel.ToleratesWriteSkew() {
 			retry, reason = true, roachpb.RETRY_SERIALIZABLE
 		}
 	}
@@ -58,6 +58,8 @@ func Get(
 		FailOnMoreRecent: args.KeyLocking != lock.None,
 		Uncertainty:      cArgs.Uncertainty,
 		MemoryAccount:    cArgs.EvalCtx.GetResponseMemoryAccount(),
+		SkipLocked:       h.WaitPolicy == lock.WaitPolicy_SkipLocked,
+		LockTable:        cArgs.Concurrency,
 	})
 	if err != nil {
 		return result.Result{}, err
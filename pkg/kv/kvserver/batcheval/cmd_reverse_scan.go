@@ -55,6 +55,8 @@ func ReverseScan(
 		FailOnMoreRecent:       args.KeyLocking != lock.None,
 		Reverse:                true,
 		MemoryAccount:          cArgs.EvalCtx.GetResponseMemoryAccount(),
+		SkipLocked:             h.WaitPolicy == lock.WaitPolicy_SkipLocked,
+		LockTable:              cArgs.Concurrency,
 	}
 
 	switch args.ScanFormat {
@@ -55,6 +55,8 @@ func Scan(
 		FailOnMoreRecent:       args.KeyLocking != lock.None,
 		Reverse:                false,
 		MemoryAccount:          cArgs.EvalCtx.GetResponseMemoryAccount(),
+		SkipLocked:             h.WaitPolicy == lock.WaitPolicy_SkipLocked,
+		LockTable:              cArgs.Concurrency,
 	}
 
 	switch args.ScanFormat {
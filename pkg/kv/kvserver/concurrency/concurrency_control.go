@@ -767,6 +767,17 @@ type lockTableGuard interface {
 	// so this checking is practically only going to find unreplicated locks
 	// that conflict.
 	CheckOptimisticNoConflicts(*spanset.SpanSet) (ok bool)
+
+	// IsKeyLockedByConflictingTxn returns whether the specified key is locked or
+	// reserved (see lockTable "reservations") by a conflicting transaction in the
+	// lockTableGuard's snapshot of the lock table, given the caller's own desired
+	// locking strength. If so, true is returned. If the key is locked, the lock
+	// holder is also returned. Otherwise, if the key is reserved, nil is also
+	// returned.
+	//
+	// This method is used by requests using the SkipLocked wait policy, which
+	// do not wait in lock wait-queues and instead skip over conflicting keys.
+	IsKeyLockedByConflictingTxn(roachpb.Key, lock.Strength) (bool, *enginepb.TxnMeta)
 }
 
 // lockTableWaiter is concerned with waiting in lock wait-queues for locks held
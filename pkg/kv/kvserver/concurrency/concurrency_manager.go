@@ -748,6 +748,18 @@ func (g *Guard) CheckOptimisticNoLatchConflicts() (ok bool) {
 	return g.lm.CheckOptimisticNoConflicts(g.lg, g.Req.LatchSpans)
 }
 
+// IsKeyLockedByConflictingTxn returns whether the specified key is locked or
+// reserved by a conflicting transaction, given the caller's own desired locking
+// strength. See lockTableGuard.IsKeyLockedByConflictingTxn for details.
+func (g *Guard) IsKeyLockedByConflictingTxn(
+	key roachpb.Key, strength lock.Strength,
+) (bool, *enginepb.TxnMeta) {
+	if g == nil || g.ltg == nil {
+		return false, nil
+	}
+	return g.ltg.IsKeyLockedByConflictingTxn(key, strength)
+}
+
 func (g *Guard) moveLatchGuard() latchGuard {
 	lg := g.lg
 	g.lg = nil
@@ -0,0 +1,38 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package isolation provides type definitions for isolation level-related
+// concepts used by concurrency control in the key-value layer.
+package isolation
+
+// WeakerThan returns whether the receiver's isolation level is weaker than
+// the parameter's isolation level.
+func (l Level) WeakerThan(l2 Level) bool {
+	// Internally, we exploit the fact that the enum's underlying integer values
+	// are ordered from strongest to weakest.
+	return l > l2
+}
+
+// ToleratesWriteSkew returns whether the isolation level permits write skew.
+// Transactions that tolerate write skew may commit at a timestamp above their
+// read timestamp without first refreshing their reads.
+func (l Level) ToleratesWriteSkew() bool {
+	return l.WeakerThan(Serializable)
+}
+
+// PerStatementReadSnapshot returns whether the isolation level establishes a
+// new read snapshot for each statement. If not, a single read snapshot is used
+// for the entire transaction.
+func (l Level) PerStatementReadSnapshot() bool {
+	return l == ReadCommitted
+}
+
+// SafeValue implements redact.SafeValue.
+func (Level) SafeValue() {}
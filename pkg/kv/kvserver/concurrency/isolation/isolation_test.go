@@ -0,0 +1,30 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package isolation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevel(t *testing.T) {
+	require.False(t, Serializable.WeakerThan(Serializable))
+	require.False(t, Serializable.WeakerThan(ReadCommitted))
+	require.True(t, ReadCommitted.WeakerThan(Serializable))
+	require.False(t, ReadCommitted.WeakerThan(ReadCommitted))
+
+	require.False(t, Serializable.ToleratesWriteSkew())
+	require.True(t, ReadCommitted.ToleratesWriteSkew())
+
+	require.False(t, Serializable.PerStatementReadSnapshot())
+	require.True(t, ReadCommitted.PerStatementReadSnapshot())
+}
@@ -560,6 +560,23 @@ func (g *lockTableGuardImpl) CheckOptimisticNoConflicts(spanSet *spanset.SpanSet
 	return true
 }
 
+func (g *lockTableGuardImpl) IsKeyLockedByConflictingTxn(
+	key roachpb.Key, strength lock.Strength,
+) (bool, *enginepb.TxnMeta) {
+	ss := spanset.SpanGlobal
+	if keys.IsLocal(key) {
+		ss = spanset.SpanLocal
+	}
+	tree := g.tableSnapshot[ss]
+	iter := tree.MakeIter()
+	iter.FirstOverlap(&lockState{key: key})
+	if !iter.Valid() {
+		// No lock on key.
+		return false, nil
+	}
+	return iter.Cur().isLockedByConflictingTxn(g, strength)
+}
+
 func (g *lockTableGuardImpl) notify() {
 	select {
 	case g.mu.signal <- struct{}{}:
@@ -1767,6 +1784,41 @@ func (l *lockState) isNonConflictingLock(g *lockTableGuardImpl, sa spanset.SpanA
 	return false
 }
 
+// isLockedByConflictingTxn returns whether the lock is held or reserved by a
+// transaction that conflicts with a request from g that intends to access the
+// lock's key with the provided strength. If so, it also returns the holder of
+// the lock, which is nil if the lock is only reserved.
+// Acquires l.mu.
+func (l *lockState) isLockedByConflictingTxn(
+	g *lockTableGuardImpl, strength lock.Strength,
+) (bool, *enginepb.TxnMeta) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// It is possible that this lock is empty and has not yet been deleted.
+	if l.isEmptyLock() {
+		return false, nil
+	}
+	lockHolderTxn, lockHolderTS := l.getLockHolder()
+	if lockHolderTxn == nil {
+		// Reservation holders only conflict with locking requests from other
+		// transactions, since they are about to acquire the lock.
+		if strength == lock.None || l.reservation == nil || g.isSameTxn(l.reservation.txn) {
+			return false, nil
+		}
+		return true, nil
+	}
+	if g.isSameTxn(lockHolderTxn) {
+		// Already locked by this txn.
+		return false, nil
+	}
+	if strength == lock.None && g.ts.Less(lockHolderTS) {
+		// Non-locking reads below the lock's timestamp do not conflict.
+		return false, nil
+	}
+	return true, lockHolderTxn
+}
+
 // Acquires this lock. Returns the list of guards that are done actively
 // waiting at this key -- these will be requests from the same transaction
 // that is acquiring the lock.
@@ -2455,6 +2507,15 @@ func (t *lockTableImpl) ScanAndEnqueue(req Request, guard lockTableGuard) lockTa
 		g.toResolve = g.toResolve[:0]
 	}
 	t.doSnapshotForGuard(g)
+
+	if req.WaitPolicy == lock.WaitPolicy_SkipLocked {
+		// If the request is using a SkipLocked wait policy, it captures a lockTable
+		// snapshot but does not scan the lock table when sequencing. Instead, it
+		// calls into IsKeyLockedByConflictingTxn before adding keys to its result
+		// set to determine which keys it should skip.
+		return g
+	}
+
 	g.findNextLockAfter(true /* notify */)
 	if g.notRemovableLock != nil {
 		// Either waiting at the notRemovableLock, or elsewhere. Either way we are
@@ -492,6 +492,13 @@ func (w *lockTableWaiterImpl) pushLockTxn(
 		pushType = roachpb.PUSH_TOUCH
 		log.VEventf(ctx, 2, "pushing txn %s to check if abandoned", ws.txn.ID.Short())
 
+	case lock.WaitPolicy_SkipLocked:
+		// This wait policy signifies that the request wants to skip over keys
+		// that are locked by conflicting transactions. Such requests do not scan
+		// the lockTable when sequencing, so they never enter lock wait-queues or
+		// push lock holders.
+		log.Fatalf(ctx, "unexpected WaitPolicy: %v", req.WaitPolicy)
+
 	default:
 		log.Fatalf(ctx, "unexpected WaitPolicy: %v", req.WaitPolicy)
 	}
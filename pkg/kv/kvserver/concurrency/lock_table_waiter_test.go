@@ -85,6 +85,11 @@ func (g *mockLockTableGuard) ResolveBeforeScanning() []roachpb.LockUpdate {
 func (g *mockLockTableGuard) CheckOptimisticNoConflicts(*spanset.SpanSet) (ok bool) {
 	return true
 }
+func (g *mockLockTableGuard) IsKeyLockedByConflictingTxn(
+	roachpb.Key, lock.Strength,
+) (bool, *enginepb.TxnMeta) {
+	panic("unimplemented")
+}
 func (g *mockLockTableGuard) notify() { g.signal <- struct{}{} }
 
 // mockLockTable overrides TransactionIsFinalized, which is the only LockTable
@@ -149,6 +149,7 @@ func evaluateBatch(
 	ms *enginepb.MVCCStats,
 	ba *roachpb.BatchRequest,
 	ui uncertainty.Interval,
+	g *concurrency.Guard,
 	readOnly bool,
 ) (_ *roachpb.BatchResponse, _ result.Result, retErr *roachpb.Error) {
 
@@ -268,7 +269,7 @@ func evaluateBatch(
 		// may carry a response transaction and in the case of WriteTooOldError
 		// (which is sometimes deferred) it is fully populated.
 		curResult, err := evaluateCommand(
-			ctx, readWriter, rec, ms, baHeader, args, reply, ui)
+			ctx, readWriter, rec, ms, baHeader, args, reply, g, ui)
 
 		if filter := rec.EvalKnobs().TestingPostEvalFilter; filter != nil {
 			filterArgs := kvserverbase.FilterArgs{
@@ -475,6 +476,7 @@ func evaluateCommand(
 	h roachpb.Header,
 	args roachpb.Request,
 	reply roachpb.Response,
+	g *concurrency.Guard,
 	ui uncertainty.Interval,
 ) (result.Result, error) {
 	var err error
@@ -486,6 +488,7 @@ func evaluateCommand(
 			Header:      h,
 			Args:        args,
 			Stats:       ms,
+			Concurrency: g,
 			Uncertainty: ui,
 		}
 
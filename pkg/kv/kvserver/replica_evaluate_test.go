@@ -666,6 +666,7 @@ func TestEvaluateBatch(t *testing.T) {
 				&d.ms,
 				&d.ba,
 				uncertainty.Interval{},
+				nil, /* g */
 				d.readOnly,
 			)
 
@@ -17,6 +17,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/poison"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
@@ -1055,7 +1056,12 @@ func (r *Replica) collectSpans(
 
 	// Note that we are letting locking readers be considered for optimistic
 	// evaluation. This is correct, though not necessarily beneficial.
+	//
+	// Requests that skip locked keys are not considered for optimistic
+	// evaluation, since they consult a snapshot of the lock table during
+	// evaluation that optimistic evaluation does not capture.
 	considerOptEval := ba.IsReadOnly() && ba.IsAllTransactional() && ba.Header.MaxSpanRequestKeys > 0 &&
+		ba.WaitPolicy != lock.WaitPolicy_SkipLocked &&
 		optimisticEvalLimitedScans.Get(&r.ClusterSettings().SV)
 	// When considerOptEval, these are computed below and used to decide whether
 	// to actually do optimistic evaluation.
@@ -14,6 +14,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/readsummary/rspb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/tscache"
@@ -107,6 +108,21 @@ func (r *Replica) updateTimestampCache(
 		}
 		header := args.Header()
 		start, end := header.Key, header.EndKey
+
+		if ba.WaitPolicy == lock.WaitPolicy_SkipLocked && roachpb.CanSkipLocked(args) {
+			// Requests that skip locked keys do not observe the keys that they
+			// skip, so only the keys that they return are added to the timestamp
+			// cache. Writes to the skipped keys beneath the request's timestamp
+			// remain possible.
+			if err := roachpb.ResponseKeyIterate(args, br.Responses[i].GetInner(), func(key roachpb.Key) {
+				addToTSCache(key, nil, ts, txnID)
+			}); err != nil {
+				log.Errorf(ctx, "error iterating over response keys while "+
+					"updating timestamp cache for ba=%v, br=%v: %v", ba, br, err)
+			}
+			continue
+		}
+
 		switch t := args.(type) {
 		case *roachpb.EndTxnRequest:
 			// EndTxn requests record a tombstone in the timestamp cache to ensure
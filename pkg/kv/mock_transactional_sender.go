@@ -13,6 +13,7 @@ package kv
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -82,6 +83,17 @@ func (m *MockTransactionalSender) SetUserPriority(pri roachpb.UserPriority) erro
 	return nil
 }
 
+// SetIsoLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) SetIsoLevel(isoLevel isolation.Level) error {
+	m.txn.IsoLevel = isoLevel
+	return nil
+}
+
+// IsoLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) IsoLevel() isolation.Level {
+	return m.txn.IsoLevel
+}
+
 // SetDebugName is part of the TxnSender interface.
 func (m *MockTransactionalSender) SetDebugName(name string) {
 	m.txn.Name = name
@@ -189,7 +201,7 @@ func (m *MockTransactionalSender) PrepareRetryableError(ctx context.Context, msg
 }
 
 // Step is part of the TxnSender interface.
-func (m *MockTransactionalSender) Step(_ context.Context) error {
+func (m *MockTransactionalSender) Step(_ context.Context, _ bool) error {
 	// At least one test (e.g sql/TestPortalsDestroyedOnTxnFinish) requires
 	// the ability to run simple statements that do not access storage,
 	// and that requires a non-panicky Step().
@@ -231,6 +243,11 @@ func (m *MockTransactionalSender) GetTxnRetryableErr(
 func (m *MockTransactionalSender) ClearTxnRetryableErr(ctx context.Context) {
 }
 
+// PrepareForPartialRetry is part of the TxnSender interface.
+func (m *MockTransactionalSender) PrepareForPartialRetry(ctx context.Context) error {
+	panic("unimplemented")
+}
+
 // HasPerformedReads is part of TxnSenderFactory.
 func (m *MockTransactionalSender) HasPerformedReads() bool {
 	panic("unimplemented")
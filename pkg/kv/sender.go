@@ -13,6 +13,7 @@ package kv
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -126,6 +127,13 @@ type TxnSender interface {
 	// SetUserPriority sets the txn's priority.
 	SetUserPriority(roachpb.UserPriority) error
 
+	// SetIsoLevel sets the txn's isolation level. The isolation level can only
+	// be changed before the transaction has performed any operations.
+	SetIsoLevel(isolation.Level) error
+
+	// IsoLevel returns the txn's isolation level.
+	IsoLevel() isolation.Level
+
 	// SetDebugName sets the txn's debug name.
 	SetDebugName(name string)
 
@@ -282,8 +290,13 @@ type TxnSender interface {
 	// Step() can only be called after stepping mode has been enabled
 	// using ConfigureStepping(SteppingEnabled).
 	//
+	// If allowReadTimestampStep is set and the transaction's isolation
+	// level uses a per-statement read snapshot, the sequencing point also
+	// moves the transaction's read timestamp forward to the current time.
+	// Callers set it at the start of a new statement.
+	//
 	// The method is idempotent.
-	Step(context.Context) error
+	Step(ctx context.Context, allowReadTimestampStep bool) error
 
 	// SetReadSeqNum sets the read sequence point for the current transaction.
 	SetReadSeqNum(seq enginepb.TxnSeq) error
@@ -334,9 +347,18 @@ type TxnSender interface {
 	// TxnSender usable again.
 	GetTxnRetryableErr(ctx context.Context) *roachpb.TransactionRetryWithProtoRefreshError
 
-	// ClearTxnRetryableErr clears the retryable error, if any.
+	// ClearTxnRetryableErr clears the retryable error, if any. If the error
+	// allowed for a partial retry, the transaction is restarted at a new
+	// epoch regardless.
 	ClearTxnRetryableErr(ctx context.Context)
 
+	// PrepareForPartialRetry clears a retryable error that allows for a
+	// partial retry (see TransactionRetryWithProtoRefreshError.PartialRetry)
+	// and moves the transaction to the read timestamp at which the statement
+	// that encountered the error can be retried, without bumping its epoch.
+	// It returns an error if there is no such retryable error.
+	PrepareForPartialRetry(ctx context.Context) error
+
 	// HasPerformedReads returns true if a read has been performed.
 	HasPerformedReads() bool
 
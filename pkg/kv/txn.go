@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
@@ -301,6 +302,26 @@ func (txn *Txn) SetUserPriority(userPriority roachpb.UserPriority) error {
 	return txn.mu.sender.SetUserPriority(userPriority)
 }
 
+// SetIsoLevel sets the transaction's isolation level. Transactions default to
+// Serializable isolation. The isolation level must be set before any
+// operations are performed on the transaction.
+func (txn *Txn) SetIsoLevel(isoLevel isolation.Level) error {
+	if txn.typ != RootTxn {
+		return errors.AssertionFailedf("SetIsoLevel() called on leaf txn")
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.SetIsoLevel(isoLevel)
+}
+
+// IsoLevel returns the transaction's isolation level.
+func (txn *Txn) IsoLevel() isolation.Level {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.IsoLevel()
+}
+
 // TestingSetPriority sets the transaction priority. It is intended for
 // internal (testing) use only.
 func (txn *Txn) TestingSetPriority(priority enginepb.TxnPriority) {
@@ -1061,6 +1082,24 @@ func (txn *Txn) PrepareForRetry(ctx context.Context) {
 	txn.handleRetryableErrLocked(ctx, retryErr)
 }
 
+// PrepareForPartialRetry needs to be called before retrying a single statement
+// of a transaction after a retryable error that allows for a partial retry
+// (see roachpb.TransactionRetryWithProtoRefreshError.PartialRetry). Unlike
+// PrepareForRetry, the transaction is not restarted: it keeps its epoch and
+// moves to the read timestamp at which the statement can be retried. The
+// caller is responsible for rolling back the writes performed by the statement,
+// typically by rolling back to a savepoint created when the statement started.
+func (txn *Txn) PrepareForPartialRetry(ctx context.Context) error {
+	if txn.typ != RootTxn {
+		return errors.AssertionFailedf("PrepareForPartialRetry() called on leaf txn")
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	log.VEventf(ctx, 2, "partially retrying transaction: %s", txn.debugNameLocked())
+	return txn.mu.sender.PrepareForPartialRetry(ctx)
+}
+
 // IsRetryableErrMeantForTxn returns true if err is a retryable
 // error meant to restart this client transaction.
 func (txn *Txn) IsRetryableErrMeantForTxn(
@@ -1404,7 +1443,14 @@ func (txn *Txn) UpdateStateOnRemoteRetryableErr(ctx context.Context, pErr *roach
 	}
 
 	pErr = txn.mu.sender.UpdateStateOnRemoteRetryableErr(ctx, pErr)
-	txn.replaceRootSenderIfTxnAbortedLocked(ctx, pErr.GetDetail().(*roachpb.TransactionRetryWithProtoRefreshError), origTxnID)
+	retryErr := pErr.GetDetail().(*roachpb.TransactionRetryWithProtoRefreshError)
+	if retryErr.PartialRetry {
+		// Leave the retryable error in place; the caller decides whether to
+		// retry only the current statement (see PrepareForPartialRetry) or the
+		// whole transaction (see PrepareForRetry).
+		return pErr.GoError()
+	}
+	txn.replaceRootSenderIfTxnAbortedLocked(ctx, retryErr, origTxnID)
 
 	return pErr.GoError()
 }
@@ -1584,10 +1630,15 @@ func (txn *Txn) Active() bool {
 //
 // In step-wise execution, reads operate at a snapshot established at
 // the last step, instead of the latest write if not yet enabled.
-func (txn *Txn) Step(ctx context.Context) error {
+//
+// If allowReadTimestampStep is set and the transaction's isolation level uses
+// a per-statement read snapshot, the step also establishes a new read
+// timestamp for the transaction. This should only be set when a new statement
+// is starting.
+func (txn *Txn) Step(ctx context.Context, allowReadTimestampStep bool) error {
 	txn.mu.Lock()
 	defer txn.mu.Unlock()
-	return txn.mu.sender.Step(ctx)
+	return txn.mu.sender.Step(ctx, allowReadTimestampStep)
 }
 
 // SetReadSeqNum sets the read sequence number for this transaction.
@@ -157,6 +157,12 @@ var migrations = []migration.Migration{
 		NoPrecondition,
 		seedSpanCountTableMigration,
 	),
+	migration.NewTenantMigration(
+		"add the system.notifications table",
+		toCV(clusterversion.NotificationsTable),
+		NoPrecondition,
+		notificationsTableMigration,
+	),
 }
 
 func init() {
@@ -95,6 +95,7 @@ const (
 	needsRefresh                                   // commands which require refreshes to avoid serializable retries
 	canBackpressure                                // commands which deserve backpressure when a Range grows too large
 	bypassesReplicaCircuitBreaker                  // commands which bypass the replica circuit breaker, i.e. opt out of fail-fast
+	canSkipLocked                                  // commands which can evaluate under the SkipLocked wait policy
 )
 
 // flagDependencies specifies flag dependencies, asserted by TestFlagCombinations.
@@ -104,11 +105,13 @@ var flagDependencies = map[flag][]flag{
 	isIntentWrite:   {isWrite, isLocking},
 	appliesTSCache:  {isWrite},
 	skipsLeaseCheck: {isAlone},
+	canSkipLocked:   {isRead},
 }
 
 // flagExclusions specifies flag incompatibilities, asserted by TestFlagCombinations.
 var flagExclusions = map[flag][]flag{
 	skipsLeaseCheck: {isIntentWrite},
+	canSkipLocked:   {isWrite},
 }
 
 // IsReadOnly returns true iff the request is read-only. A request is
@@ -211,6 +214,13 @@ func BypassesReplicaCircuitBreaker(args Request) bool {
 	return (args.flags() & bypassesReplicaCircuitBreaker) != 0
 }
 
+// CanSkipLocked returns whether the command can evaluate under the SkipLocked
+// wait policy, skipping over keys that are locked by conflicting transactions
+// instead of waiting on them.
+func CanSkipLocked(args Request) bool {
+	return (args.flags() & canSkipLocked) != 0
+}
+
 // Request is an interface for RPC requests.
 type Request interface {
 	protoutil.Message
@@ -1218,7 +1228,7 @@ func flagForLockStrength(l lock.Strength) flag {
 
 func (gr *GetRequest) flags() flag {
 	maybeLocking := flagForLockStrength(gr.KeyLocking)
-	return isRead | isTxn | maybeLocking | updatesTSCache | needsRefresh
+	return isRead | isTxn | maybeLocking | updatesTSCache | needsRefresh | canSkipLocked
 }
 
 func (*PutRequest) flags() flag {
@@ -1300,12 +1310,12 @@ func (*RevertRangeRequest) flags() flag {
 
 func (sr *ScanRequest) flags() flag {
 	maybeLocking := flagForLockStrength(sr.KeyLocking)
-	return isRead | isRange | isTxn | maybeLocking | updatesTSCache | needsRefresh
+	return isRead | isRange | isTxn | maybeLocking | updatesTSCache | needsRefresh | canSkipLocked
 }
 
 func (rsr *ReverseScanRequest) flags() flag {
 	maybeLocking := flagForLockStrength(rsr.KeyLocking)
-	return isRead | isRange | isReverse | isTxn | maybeLocking | updatesTSCache | needsRefresh
+	return isRead | isRange | isReverse | isTxn | maybeLocking | updatesTSCache | needsRefresh | canSkipLocked
 }
 
 // EndTxn updates the timestamp cache to prevent replays.
@@ -459,7 +459,10 @@ func (ba *BatchRequest) LockSpanIterate(br *BatchResponse, fn func(Span, lock.Du
 // ResumeSpan is subtracted from the request span to provide a more
 // minimal span of keys affected by the request. The supplied function
 // is called with each span.
-func (ba *BatchRequest) RefreshSpanIterate(br *BatchResponse, fn func(Span)) {
+//
+// Requests evaluated under the SkipLocked wait policy did not observe the
+// keys that they skipped, so only the keys that they returned are refreshed.
+func (ba *BatchRequest) RefreshSpanIterate(br *BatchResponse, fn func(Span)) error {
 	for i, arg := range ba.Requests {
 		req := arg.GetInner()
 		if !NeedsRefresh(req) {
@@ -469,10 +472,62 @@ func (ba *BatchRequest) RefreshSpanIterate(br *BatchResponse, fn func(Span)) {
 		if br != nil {
 			resp = br.Responses[i].GetInner()
 		}
+		if ba.WaitPolicy == lock.WaitPolicy_SkipLocked && CanSkipLocked(req) {
+			if err := ResponseKeyIterate(req, resp, func(k Key) {
+				fn(Span{Key: k})
+			}); err != nil {
+				return err
+			}
+			continue
+		}
 		if span, ok := ActualSpan(req, resp); ok {
 			fn(span)
 		}
 	}
+	return nil
+}
+
+// ResponseKeyIterate calls the passed function with the keys returned in the
+// provided request's response. If the request is being evaluated under the
+// SkipLocked wait policy, these are the keys that were not skipped. The
+// function is not called for requests that return no keys.
+func ResponseKeyIterate(req Request, resp Response, fn func(Key)) error {
+	if resp == nil {
+		return nil
+	}
+	switch v := resp.(type) {
+	case *GetResponse:
+		if v.Value != nil {
+			fn(req.Header().Key)
+		}
+	case *ScanResponse:
+		return scanResponseKeyIterate(v.Rows, v.BatchResponses, fn)
+	case *ReverseScanResponse:
+		return scanResponseKeyIterate(v.Rows, v.BatchResponses, fn)
+	default:
+		return errors.AssertionFailedf("cannot iterate over response keys of %s request", req.Method())
+	}
+	return nil
+}
+
+// scanResponseKeyIterate calls the passed function with the keys of a scan
+// response, in either the KEY_VALUES or the BATCH_RESPONSE format.
+func scanResponseKeyIterate(rows []KeyValue, batchResponses [][]byte, fn func(Key)) error {
+	for _, kv := range rows {
+		fn(kv.Key)
+	}
+	for _, b := range batchResponses {
+		for len(b) > 0 {
+			var key []byte
+			var err error
+			key, _, b, err = enginepb.ScanDecodeKeyValueNoTS(b)
+			if err != nil {
+				return err
+			}
+			fn(key)
+		}
+	}
+	return nil
 }
 
 // ActualSpan returns the actual request span which was operated on,
@@ -740,5 +795,21 @@ func (ba BatchRequest) ValidateForEvaluation() error {
 			return errors.AssertionFailedf("WriteTooOld set but no offset in timestamps. txn: %s", ba.Txn)
 		}
 	}
+	if ba.WaitPolicy == lock.WaitPolicy_SkipLocked {
+		if ba.ReadConsistency != CONSISTENT {
+			return errors.AssertionFailedf("%s read consistency not compatible with %s wait policy",
+				ba.ReadConsistency, ba.WaitPolicy)
+		}
+		for _, ru := range ba.Requests {
+			// QueryIntent requests are added to batches by the transaction's
+			// coordinator to prove pipelined writes. They are not affected by the
+			// batch's wait policy.
+			req := ru.GetInner()
+			if !CanSkipLocked(req) && req.Method() != QueryIntent {
+				return errors.AssertionFailedf("request %s not compatible with %s wait policy",
+					req.Method(), ba.WaitPolicy)
+			}
+		}
+	}
 	return nil
 }
@@ -310,7 +310,7 @@ func TestRefreshSpanIterate(t *testing.T) {
 	fn := func(span Span) {
 		readSpans = append(readSpans, span)
 	}
-	ba.RefreshSpanIterate(&br, fn)
+	require.NoError(t, ba.RefreshSpanIterate(&br, fn))
 	// The conditional put and init put are not considered read spans.
 	expReadSpans := []Span{testCases[4].span, testCases[5].span, testCases[6].span, testCases[7].span}
 	require.Equal(t, expReadSpans, readSpans)
@@ -329,7 +329,7 @@ func TestRefreshSpanIterate(t *testing.T) {
 	}
 
 	readSpans = []Span{}
-	ba.RefreshSpanIterate(&br, fn)
+	require.NoError(t, ba.RefreshSpanIterate(&br, fn))
 	expReadSpans = []Span{
 		sp("a", "b"),
 		sp("b", ""),
@@ -337,6 +337,30 @@ func TestRefreshSpanIterate(t *testing.T) {
 		sp("g", "h"),
 	}
 	require.Equal(t, expReadSpans, readSpans)
+
+	// Batch responses under the SkipLocked wait policy only refresh the keys
+	// that were returned.
+	ba = BatchRequest{}
+	ba.WaitPolicy = lock.WaitPolicy_SkipLocked
+	br = BatchResponse{}
+	ba.Add(&GetRequest{RequestHeader: RequestHeaderFromSpan(sp("a", ""))})
+	br.Add(&GetResponse{})
+	ba.Add(&GetRequest{RequestHeader: RequestHeaderFromSpan(sp("b", ""))})
+	br.Add(&GetResponse{Value: &Value{}})
+	ba.Add(&ScanRequest{RequestHeader: RequestHeaderFromSpan(sp("c", "g"))})
+	br.Add(&ScanResponse{Rows: []KeyValue{{Key: Key("c")}, {Key: Key("e")}}})
+	ba.Add(&ReverseScanRequest{RequestHeader: RequestHeaderFromSpan(sp("h", "l"))})
+	br.Add(&ReverseScanResponse{Rows: []KeyValue{{Key: Key("k")}}})
+
+	readSpans = []Span{}
+	require.NoError(t, ba.RefreshSpanIterate(&br, fn))
+	expReadSpans = []Span{
+		sp("b", ""),
+		sp("c", ""),
+		sp("e", ""),
+		sp("k", ""),
+	}
+	require.Equal(t, expReadSpans, readSpans)
 }
 
 func TestBatchResponseCombine(t *testing.T) {
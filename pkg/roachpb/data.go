@@ -1128,6 +1128,18 @@ func (t *Transaction) Refresh(timestamp hlc.Timestamp) {
 	t.WriteTooOld = false
 }
 
+// BumpReadTimestamp forwards the transaction's read timestamp to the specified
+// timestamp without bumping its epoch. It is used by isolation levels that use
+// a new read snapshot for each statement, either when a statement starts or
+// when a single statement is retried. Unlike Refresh, the reads performed by
+// the transaction at its previous read timestamp are not validated; doing so
+// is not needed for isolation levels that tolerate write skew.
+func (t *Transaction) BumpReadTimestamp(timestamp hlc.Timestamp) {
+	t.WriteTimestamp.Forward(timestamp)
+	t.ReadTimestamp.Forward(t.WriteTimestamp)
+	t.WriteTooOld = false
+}
+
 // Update ratchets priority, timestamp and original timestamp values (among
 // others) for the transaction. If t.ID is empty, then the transaction is
 // copied from o.
@@ -1146,6 +1158,13 @@ func (t *Transaction) Update(o *Transaction) {
 	if len(t.Key) == 0 {
 		t.Key = o.Key
 	}
+	// The isolation level can only be weakened. A transaction proto coming back
+	// from a node that doesn't know about the field carries the default
+	// (strongest) level, which must not override a weaker one set by the
+	// client.
+	if o.IsoLevel.WeakerThan(t.IsoLevel) {
+		t.IsoLevel = o.IsoLevel
+	}
 
 	// Update epoch-scoped state, depending on the two transactions' epochs.
 	if t.Epoch < o.Epoch {
@@ -1451,6 +1470,8 @@ func PrepareTransactionForRetry(
 		)
 		// Use the priority communicated back by the server.
 		txn.Priority = errTxnPri
+		// The new transaction keeps the isolation level of the aborted one.
+		txn.IsoLevel = pErr.GetTxn().IsoLevel
 	case *ReadWithinUncertaintyIntervalError:
 		txn.WriteTimestamp.Forward(tErr.RetryTimestamp())
 	case *TransactionPushError:
@@ -1491,11 +1512,40 @@ func PrepareTransactionForRetry(
 		if txn.Status.IsFinalized() {
 			log.Fatalf(ctx, "transaction unexpectedly finalized in (%T): %s", pErr.GetDetail(), pErr)
 		}
-		txn.Restart(pri, txn.Priority, txn.WriteTimestamp)
+		if CanRetryStatementWithoutRestart(pErr) {
+			// The transaction takes a new read snapshot for every statement, so
+			// only the statement that hit the error needs to be retried, at the
+			// bumped timestamp. The epoch stays the same, so the writes performed
+			// by earlier statements remain valid.
+			txn.BumpReadTimestamp(txn.WriteTimestamp)
+		} else {
+			txn.Restart(pri, txn.Priority, txn.WriteTimestamp)
+		}
 	}
 	return txn
 }
 
+// CanRetryStatementWithoutRestart returns whether the supplied retryable error
+// can be handled by retrying only the statement that encountered it at a
+// higher read timestamp, without restarting the whole transaction. This is
+// only possible for transactions whose isolation level uses a per-statement
+// read snapshot, and only for errors that are caused by the read or write
+// timestamp being too low.
+func CanRetryStatementWithoutRestart(pErr *Error) bool {
+	txn := pErr.GetTxn()
+	if txn == nil || !txn.IsoLevel.PerStatementReadSnapshot() {
+		return false
+	}
+	switch tErr := pErr.GetDetail().(type) {
+	case *ReadWithinUncertaintyIntervalError, *WriteTooOldError, *TransactionPushError:
+		return true
+	case *TransactionRetryError:
+		return tErr.Reason == RETRY_WRITE_TOO_OLD || tErr.Reason == RETRY_SERIALIZABLE
+	default:
+		return false
+	}
+}
+
 // TransactionRefreshTimestamp returns whether the supplied error is a retry
 // error that can be discarded if the transaction in the error is refreshed. If
 // true, the function returns the timestamp that the Transaction object should
@@ -22,6 +22,7 @@ import (
 
 	"github.com/cockroachdb/apd/v3"
 	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils/zerofields"
@@ -490,6 +491,7 @@ var nonZeroTxn = Transaction{
 		Priority:          957356782,
 		Sequence:          123,
 		CoordinatorNodeID: 3,
+		IsoLevel:          isolation.ReadCommitted,
 	},
 	Name:                   "name",
 	Status:                 COMMITTED,
@@ -0,0 +1,696 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/ui"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// The DB Console supports logging in through an OpenID Connect identity
+// provider, using the authorization code flow:
+//
+//  1. The UI sends the browser to oidcLoginPath. The server generates a random
+//     state, stores it in a cookie, and redirects the browser to the
+//     authorization endpoint of the provider.
+//  2. After the user authenticates, the provider redirects the browser to the
+//     configured redirect URL, which must point to oidcCallbackPath, with an
+//     authorization code and the state.
+//  3. The server checks the state against the cookie, exchanges the code for
+//     an ID token at the token endpoint of the provider, and verifies the
+//     token's signature against the keys published by the provider.
+//  4. The value of the claim named by the claim_json_key setting is mapped to
+//     a SQL user with the principal_regex setting, and a web session is
+//     created for that user, just like for a password login.
+//
+// The provider's endpoints and keys are discovered through its
+// /.well-known/openid-configuration document. Discovery happens on the first
+// login after the configuration changes, so that a misconfigured or
+// unavailable provider does not affect server startup.
+const (
+	oidcLoginPath    = "/oidc/v1/login"
+	oidcCallbackPath = "/oidc/v1/callback"
+
+	// oidcStateCookieName is the name of the cookie holding the state of a
+	// login in progress.
+	oidcStateCookieName = "oidc_state"
+	// oidcStateTimeout is the time a user has to complete the login with the
+	// identity provider.
+	oidcStateTimeout = 5 * time.Minute
+
+	oidcDiscoveryPath = "/.well-known/openid-configuration"
+	// oidcHTTPTimeout bounds requests made to the identity provider.
+	oidcHTTPTimeout = 30 * time.Second
+
+	oidcSettingPrefix = "server.oidc_authentication."
+)
+
+var oidcEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	oidcSettingPrefix+"enabled",
+	"enables or disables OIDC login for the DB Console",
+	false,
+).WithPublic()
+
+var oidcClientID = settings.RegisterStringSetting(
+	settings.TenantWritable,
+	oidcSettingPrefix+"client_id",
+	"sets OIDC client id",
+	"",
+).WithPublic()
+
+var oidcClientSecret = func() *settings.StringSetting {
+	s := settings.RegisterStringSetting(
+		settings.TenantWritable,
+		oidcSettingPrefix+"client_secret",
+		"sets OIDC client secret",
+		"",
+	)
+	s.SetReportable(false)
+	return s.WithPublic()
+}()
+
+var oidcProviderURL = settings.RegisterValidatedStringSetting(
+	settings.TenantWritable,
+	oidcSettingPrefix+"provider_url",
+	"sets OIDC provider URL ({provider_url}"+oidcDiscoveryPath+" must resolve)",
+	"",
+	func(_ *settings.Values, s string) error {
+		if s != "" && !strings.HasPrefix(s, "https://") && !strings.HasPrefix(s, "http://") {
+			return errors.Newf("OIDC provider URL must start with https:// or http://: %s", s)
+		}
+		return nil
+	},
+).WithPublic()
+
+var oidcRedirectURL = settings.RegisterStringSetting(
+	settings.TenantWritable,
+	oidcSettingPrefix+"redirect_url",
+	"sets OIDC redirect URL (base HTTP URL, likely your load balancer, must route to the path "+
+		oidcCallbackPath+")",
+	"https://localhost:8080"+oidcCallbackPath,
+).WithPublic()
+
+var oidcScopes = settings.RegisterValidatedStringSetting(
+	settings.TenantWritable,
+	oidcSettingPrefix+"scopes",
+	"sets OIDC scopes to include with authentication request "+
+		"(space delimited list of strings, required to start with `openid`)",
+	"openid",
+	func(_ *settings.Values, s string) error {
+		if s != "openid" && !strings.HasPrefix(s, "openid ") {
+			return errors.New("missing openid scope")
+		}
+		return nil
+	},
+).WithPublic()
+
+var oidcClaimJSONKey = settings.RegisterStringSetting(
+	settings.TenantWritable,
+	oidcSettingPrefix+"claim_json_key",
+	"sets JSON key of principal to extract from payload after OIDC authentication completes "+
+		"(usually email or sid)",
+	"",
+).WithPublic()
+
+var oidcPrincipalRegex = settings.RegisterValidatedStringSetting(
+	settings.TenantWritable,
+	oidcSettingPrefix+"principal_regex",
+	"regular expression to apply to extracted principal (see claim_json_key setting) to "+
+		"translate to SQL user (golang regex format, must include 1 grouping to extract)",
+	"(.+)",
+	func(_ *settings.Values, s string) error {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return errors.Wrapf(err, "unable to initialize %s setting, regex does not compile",
+				oidcSettingPrefix+"principal_regex")
+		}
+		if re.NumSubexp() != 1 {
+			return errors.Newf("regex must have exactly one capture group, found %d", re.NumSubexp())
+		}
+		return nil
+	},
+).WithPublic()
+
+var oidcButtonText = settings.RegisterStringSetting(
+	settings.TenantWritable,
+	oidcSettingPrefix+"button_text",
+	"text to show on button on DB Console login page to login with your OIDC provider "+
+		"(only shown if OIDC is enabled)",
+	"Log in with your OIDC provider",
+).WithPublic()
+
+var oidcAutoLogin = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	oidcSettingPrefix+"autologin",
+	"if true, logged-out visitors to the DB Console will be automatically redirected to the "+
+		"OIDC login endpoint",
+	false,
+).WithPublic()
+
+// oidcAuthenticationConf is a snapshot of the OIDC cluster settings.
+type oidcAuthenticationConf struct {
+	enabled        bool
+	clientID       string
+	clientSecret   string
+	providerURL    string
+	redirectURL    string
+	scopes         []string
+	claimJSONKey   string
+	principalRegex *regexp.Regexp
+	buttonText     string
+	autoLogin      bool
+}
+
+func makeOIDCAuthenticationConf(sv *settings.Values) oidcAuthenticationConf {
+	return oidcAuthenticationConf{
+		enabled:      oidcEnabled.Get(sv),
+		clientID:     oidcClientID.Get(sv),
+		clientSecret: oidcClientSecret.Get(sv),
+		providerURL:  oidcProviderURL.Get(sv),
+		redirectURL:  oidcRedirectURL.Get(sv),
+		scopes:       strings.Fields(oidcScopes.Get(sv)),
+		claimJSONKey: oidcClaimJSONKey.Get(sv),
+		// The setting is validated, so the regexp compiles.
+		principalRegex: regexp.MustCompile(oidcPrincipalRegex.Get(sv)),
+		buttonText:     oidcButtonText.Get(sv),
+		autoLogin:      oidcAutoLogin.Get(sv),
+	}
+}
+
+// isUsable returns true if OIDC is enabled and all the settings required to
+// complete a login are set.
+func (c *oidcAuthenticationConf) isUsable() bool {
+	return c.enabled && c.clientID != "" && c.clientSecret != "" && c.providerURL != "" &&
+		c.redirectURL != "" && c.claimJSONKey != ""
+}
+
+// oidcProvider holds the endpoints and keys of an identity provider, as
+// found through discovery.
+type oidcProvider struct {
+	issuer       string
+	oauth2Config oauth2.Config
+	jwksURL      string
+
+	mu struct {
+		syncutil.Mutex
+		// keys maps the key IDs published by the provider to their public
+		// keys.
+		keys map[string]interface{}
+	}
+}
+
+// oidcAuthenticationServer implements the OIDC login flow of the DB Console.
+// It is configured through cluster settings, and picks up changes to them
+// without a restart.
+type oidcAuthenticationServer struct {
+	st               *cluster.Settings
+	ambientCtx       log.AmbientContext
+	userLoginFromSSO func(ctx context.Context, username string) (*http.Cookie, error)
+	// secureCookie is set if the state cookie must only be sent over HTTPS.
+	secureCookie bool
+	httpClient   *http.Client
+
+	mu struct {
+		syncutil.Mutex
+		conf oidcAuthenticationConf
+		// provider is nil until the first login after a configuration change.
+		provider *oidcProvider
+	}
+}
+
+var _ OIDC = &oidcAuthenticationServer{}
+
+// GetOIDCConf implements ui.OIDCUI.
+func (s *oidcAuthenticationServer) GetOIDCConf() ui.OIDCUIConf {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ui.OIDCUIConf{
+		ButtonText: s.mu.conf.buttonText,
+		AutoLogin:  s.mu.conf.autoLogin,
+		Enabled:    s.mu.conf.isUsable(),
+	}
+}
+
+// reloadConfig takes a new snapshot of the cluster settings. The provider is
+// discovered again on the next login.
+func (s *oidcAuthenticationServer) reloadConfig(ctx context.Context) {
+	conf := makeOIDCAuthenticationConf(&s.st.SV)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.conf = conf
+	s.mu.provider = nil
+	if conf.enabled && !conf.isUsable() {
+		log.Warningf(ctx, "OIDC is enabled but not fully configured; "+
+			"client_id, client_secret, provider_url, redirect_url and claim_json_key must all be set")
+	}
+}
+
+// getProvider returns the current configuration, and the provider, running
+// discovery if needed. An error is returned if OIDC is not usable.
+func (s *oidcAuthenticationServer) getProvider(
+	ctx context.Context,
+) (oidcAuthenticationConf, *oidcProvider, error) {
+	s.mu.Lock()
+	conf, provider := s.mu.conf, s.mu.provider
+	s.mu.Unlock()
+	if !conf.isUsable() {
+		return conf, nil, errors.New("OIDC is not enabled")
+	}
+	if provider != nil {
+		return conf, provider, nil
+	}
+
+	provider, err := s.discoverProvider(ctx, conf)
+	if err != nil {
+		return conf, nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Only cache the provider if the configuration has not changed since.
+	if s.mu.conf.providerURL == conf.providerURL && s.mu.conf.clientID == conf.clientID &&
+		s.mu.conf.clientSecret == conf.clientSecret && s.mu.conf.redirectURL == conf.redirectURL {
+		s.mu.provider = provider
+	}
+	return conf, provider, nil
+}
+
+// oidcDiscoveryDocument contains the fields of the provider's
+// openid-configuration document used by the server.
+type oidcDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func (s *oidcAuthenticationServer) discoverProvider(
+	ctx context.Context, conf oidcAuthenticationConf,
+) (*oidcProvider, error) {
+	providerURL := strings.TrimSuffix(conf.providerURL, "/")
+	var doc oidcDiscoveryDocument
+	if err := s.getJSON(ctx, providerURL+oidcDiscoveryPath, &doc); err != nil {
+		return nil, errors.Wrap(err, "OIDC provider discovery failed")
+	}
+	// The issuer must be the URL the document was retrieved from (OpenID
+	// Connect Discovery 1.0, section 4.3).
+	if strings.TrimSuffix(doc.Issuer, "/") != providerURL {
+		return nil, errors.Newf("OIDC provider issuer %q does not match provider URL %q",
+			doc.Issuer, conf.providerURL)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("OIDC provider discovery document is incomplete")
+	}
+	p := &oidcProvider{
+		issuer: doc.Issuer,
+		oauth2Config: oauth2.Config{
+			ClientID:     conf.clientID,
+			ClientSecret: conf.clientSecret,
+			RedirectURL:  conf.redirectURL,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  doc.AuthorizationEndpoint,
+				TokenURL: doc.TokenEndpoint,
+			},
+			Scopes: conf.scopes,
+		},
+		jwksURL: doc.JWKSURI,
+	}
+	if err := s.refreshKeys(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *oidcAuthenticationServer) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Newf("GET %s: %s", url, resp.Status)
+	}
+	return json.Unmarshal(body, v)
+}
+
+// jsonWebKey is a public key in the JSON Web Key format (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// Elliptic curve keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// publicKey returns the *rsa.PublicKey or *ecdsa.PublicKey described by k.
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Newf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid EC key")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Newf("unsupported key type %q", k.Kty)
+	}
+}
+
+// refreshKeys fetches the signing keys published by the provider.
+func (s *oidcAuthenticationServer) refreshKeys(ctx context.Context, p *oidcProvider) error {
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.getJSON(ctx, p.jwksURL, &keySet); err != nil {
+		return errors.Wrap(err, "unable to fetch OIDC provider keys")
+	}
+	keys := make(map[string]interface{}, len(keySet.Keys))
+	for i := range keySet.Keys {
+		k := &keySet.Keys[i]
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// Providers may publish keys of types we do not use.
+			log.Infof(ctx, "ignoring OIDC provider key %q: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mu.keys = keys
+	return nil
+}
+
+func (p *oidcProvider) getKey(kid string) (interface{}, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if kid == "" && len(p.mu.keys) == 1 {
+		// A token may omit the key ID if the provider has a single key.
+		for _, k := range p.mu.keys {
+			return k, true
+		}
+	}
+	k, ok := p.mu.keys[kid]
+	return k, ok
+}
+
+// oidcSigningMethods are the signing algorithms accepted for ID tokens.
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// verifyIDToken checks the signature and the standard claims of an ID token,
+// and returns its claims.
+func (s *oidcAuthenticationServer) verifyIDToken(
+	ctx context.Context, conf oidcAuthenticationConf, p *oidcProvider, rawIDToken string,
+) (jwt.MapClaims, error) {
+	refreshed := false
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if k, ok := p.getKey(kid); ok {
+			return k, nil
+		}
+		// The provider may have rotated its keys.
+		if !refreshed {
+			refreshed = true
+			if err := s.refreshKeys(ctx, p); err != nil {
+				return nil, err
+			}
+			if k, ok := p.getKey(kid); ok {
+				return k, nil
+			}
+		}
+		return nil, errors.Newf("unknown signing key %q", kid)
+	}
+
+	claims := jwt.MapClaims{}
+	parser := &jwt.Parser{ValidMethods: oidcSigningMethods}
+	if _, err := parser.ParseWithClaims(rawIDToken, claims, keyFunc); err != nil {
+		return nil, errors.Wrap(err, "invalid ID token")
+	}
+	if !claims.VerifyIssuer(p.issuer, true /* req */) {
+		return nil, errors.New("invalid ID token: unexpected issuer")
+	}
+	if !claims.VerifyAudience(conf.clientID, true /* req */) {
+		return nil, errors.New("invalid ID token: unexpected audience")
+	}
+	if !claims.VerifyExpiresAt(timeutil.Now().Unix(), true /* req */) {
+		return nil, errors.New("invalid ID token: expired")
+	}
+	return claims, nil
+}
+
+// extractPrincipals returns the SQL usernames that the claims map to, in
+// order of preference. The claim may be a string or a list of strings.
+func extractPrincipals(conf oidcAuthenticationConf, claims jwt.MapClaims) ([]string, error) {
+	var values []string
+	switch v := claims[conf.claimJSONKey].(type) {
+	case string:
+		values = []string{v}
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+	case nil:
+		return nil, errors.Newf("claim %q not found in ID token", conf.claimJSONKey)
+	default:
+		return nil, errors.Newf("claim %q has unexpected type %T", conf.claimJSONKey, v)
+	}
+
+	var principals []string
+	for _, value := range values {
+		if match := conf.principalRegex.FindStringSubmatch(value); len(match) == 2 && match[1] != "" {
+			principals = append(principals, match[1])
+		}
+	}
+	if len(principals) == 0 {
+		return nil, errors.Newf("claim %q does not match the principal regex", conf.claimJSONKey)
+	}
+	return principals, nil
+}
+
+// handleLogin starts a login by redirecting the browser to the provider.
+func (s *oidcAuthenticationServer) handleLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := s.ambientCtx.AnnotateCtx(r.Context())
+	_, provider, err := s.getProvider(ctx)
+	if err != nil {
+		log.Warningf(ctx, "OIDC login: %v", err)
+		http.Error(w, "OIDC: unable to start login", http.StatusBadRequest)
+		return
+	}
+
+	b := make([]byte, secretLength)
+	if _, err := rand.Read(b); err != nil {
+		log.Errorf(ctx, "OIDC login: %v", err)
+		http.Error(w, "OIDC: unable to start login", http.StatusInternalServerError)
+		return
+	}
+	state := base64.RawURLEncoding.EncodeToString(b)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    state,
+		Path:     oidcCallbackPath,
+		MaxAge:   int(oidcStateTimeout.Seconds()),
+		HttpOnly: true,
+		Secure:   s.secureCookie,
+		// The provider redirects the browser back to the callback with a
+		// top-level GET request, with which lax cookies are sent.
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, provider.oauth2Config.AuthCodeURL(state), http.StatusFound)
+}
+
+// handleCallback completes a login: it exchanges the authorization code for
+// an ID token, and creates a web session for the SQL user the token maps to.
+func (s *oidcAuthenticationServer) handleCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := s.ambientCtx.AnnotateCtx(r.Context())
+	fail := func(status int, msg string, err error) {
+		log.Warningf(ctx, "OIDC callback: %s: %v", msg, err)
+		http.Error(w, fmt.Sprintf("OIDC: %s", msg), status)
+	}
+
+	conf, provider, err := s.getProvider(ctx)
+	if err != nil {
+		fail(http.StatusBadRequest, "unable to complete login", err)
+		return
+	}
+
+	// The state cookie can only be used once.
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Path:     oidcCallbackPath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.secureCookie,
+	})
+	stateCookie, err := r.Cookie(oidcStateCookieName)
+	if err != nil {
+		fail(http.StatusBadRequest, "missing state cookie", err)
+		return
+	}
+	state := r.URL.Query().Get("state")
+	if state == "" || !hmac.Equal([]byte(state), []byte(stateCookie.Value)) {
+		fail(http.StatusBadRequest, "state mismatch", errors.New("state does not match cookie"))
+		return
+	}
+	if e := r.URL.Query().Get("error"); e != "" {
+		fail(http.StatusBadRequest, "login failed",
+			errors.Newf("%s: %s", e, r.URL.Query().Get("error_description")))
+		return
+	}
+
+	token, err := provider.oauth2Config.Exchange(
+		context.WithValue(ctx, oauth2.HTTPClient, s.httpClient), r.URL.Query().Get("code"))
+	if err != nil {
+		fail(http.StatusInternalServerError, "failed to exchange code for token", err)
+		return
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		fail(http.StatusInternalServerError, "failed to extract ID token from the token response",
+			errors.New("missing id_token"))
+		return
+	}
+	claims, err := s.verifyIDToken(ctx, conf, provider, rawIDToken)
+	if err != nil {
+		fail(http.StatusInternalServerError, "failed to verify ID token", err)
+		return
+	}
+	principals, err := extractPrincipals(conf, claims)
+	if err != nil {
+		fail(http.StatusInternalServerError, "failed to extract principal from ID token", err)
+		return
+	}
+
+	for _, principal := range principals {
+		cookie, err := s.userLoginFromSSO(ctx, principal)
+		if err != nil {
+			log.Infof(ctx, "OIDC callback: unable to log in as %q: %v", principal, err)
+			continue
+		}
+		http.SetCookie(w, cookie)
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+	fail(http.StatusForbidden, "user does not exist or cannot log in to the DB Console",
+		errors.Newf("no usable SQL user among %q", principals))
+}
+
+// ConfigureOIDC is called during server startup to set up OIDC login for the
+// DB Console. It registers the login and callback HTTP endpoints, and keeps
+// track of the OIDC cluster settings. userLoginFromSSO is used to create a
+// web session for the SQL user found in the ID token.
+func ConfigureOIDC(
+	ctx context.Context,
+	st *cluster.Settings,
+	handleHTTP func(pattern string, handler http.Handler),
+	userLoginFromSSO func(ctx context.Context, username string) (*http.Cookie, error),
+	ambientCtx log.AmbientContext,
+	secureCookie bool,
+) (OIDC, error) {
+	s := &oidcAuthenticationServer{
+		st:               st,
+		ambientCtx:       ambientCtx,
+		userLoginFromSSO: userLoginFromSSO,
+		secureCookie:     secureCookie,
+		httpClient:       &http.Client{Timeout: oidcHTTPTimeout},
+	}
+	s.reloadConfig(ctx)
+	for _, setting := range []settings.NonMaskedSetting{
+		oidcEnabled, oidcClientID, oidcClientSecret, oidcProviderURL, oidcRedirectURL,
+		oidcScopes, oidcClaimJSONKey, oidcPrincipalRegex, oidcButtonText, oidcAutoLogin,
+	} {
+		setting.SetOnChange(&st.SV, s.reloadConfig)
+	}
+
+	handleHTTP(oidcLoginPath, http.HandlerFunc(s.handleLogin))
+	handleHTTP(oidcCallbackPath, http.HandlerFunc(s.handleCallback))
+	return s, nil
+}
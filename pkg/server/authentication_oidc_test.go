@@ -0,0 +1,279 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOIDCProvider is an in-process OpenID Connect identity provider. Its
+// authorization endpoint immediately redirects back to the client, as if the
+// user had logged in, and its token endpoint issues an ID token with the
+// given claims.
+type fakeOIDCProvider struct {
+	*httptest.Server
+	t            *testing.T
+	key          *rsa.PrivateKey
+	clientID     string
+	clientSecret string
+	claims       jwt.MapClaims
+}
+
+const fakeOIDCCode = "fake-authorization-code"
+
+func newFakeOIDCProvider(t *testing.T, clientID, clientSecret string) *fakeOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p := &fakeOIDCProvider{t: t, key: key, clientID: clientID, clientSecret: clientSecret}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		p.writeJSON(w, map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		p.writeJSON(w, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("client_id") != p.clientID || q.Get("response_type") != "code" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		redirect, err := url.Parse(q.Get("redirect_uri"))
+		require.NoError(t, err)
+		rq := redirect.Query()
+		rq.Set("code", fakeOIDCCode)
+		rq.Set("state", q.Get("state"))
+		redirect.RawQuery = rq.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		id, secret, ok := r.BasicAuth()
+		if !ok {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if id != p.clientID || secret != p.clientSecret || r.PostForm.Get("code") != fakeOIDCCode {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, p.claims)
+		token.Header["kid"] = "key1"
+		idToken, err := token.SignedString(p.key)
+		require.NoError(t, err)
+		p.writeJSON(w, map[string]interface{}{
+			"access_token": "fake-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+func (p *fakeOIDCProvider) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(p.t, json.NewEncoder(w).Encode(v))
+}
+
+func (p *fakeOIDCProvider) setClaims(email interface{}) {
+	now := timeutil.Now()
+	p.claims = jwt.MapClaims{
+		"iss":   p.URL,
+		"aud":   p.clientID,
+		"sub":   "1234",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"email": email,
+	}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Settings: st})
+	defer s.Stopper().Stop(ctx)
+	ts := s.(*TestServer)
+
+	_, err := db.Exec("CREATE USER testuser")
+	require.NoError(t, err)
+
+	provider := newFakeOIDCProvider(t, "fake-client", "fake-secret")
+	defer provider.Close()
+
+	adminURL, err := url.Parse(ts.AdminURL())
+	require.NoError(t, err)
+	newClient := func() http.Client {
+		client, err := ts.GetHTTPClient()
+		require.NoError(t, err)
+		client.Jar, err = cookiejar.New(nil)
+		require.NoError(t, err)
+		return client
+	}
+	login := func(client http.Client) *http.Response {
+		resp, err := client.Get(ts.AdminURL() + oidcLoginPath)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp
+	}
+	sessionCookie := func(client http.Client) *http.Cookie {
+		for _, c := range client.Jar.Cookies(adminURL) {
+			if c.Name == SessionCookieName {
+				return c
+			}
+		}
+		return nil
+	}
+
+	// OIDC is disabled by default.
+	client := newClient()
+	require.Equal(t, http.StatusBadRequest, login(client).StatusCode)
+
+	sv := &st.SV
+	oidcClientID.Override(ctx, sv, "fake-client")
+	oidcClientSecret.Override(ctx, sv, "fake-secret")
+	oidcProviderURL.Override(ctx, sv, provider.URL)
+	oidcRedirectURL.Override(ctx, sv, ts.AdminURL()+oidcCallbackPath)
+	oidcClaimJSONKey.Override(ctx, sv, "email")
+	oidcPrincipalRegex.Override(ctx, sv, "^([^@]+)@example.com$")
+	oidcEnabled.Override(ctx, sv, true)
+
+	t.Run("success", func(t *testing.T) {
+		provider.setClaims("testuser@example.com")
+		client := newClient()
+		resp := login(client)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "/", resp.Request.URL.Path)
+
+		// The login created a web session for the user.
+		cookie := sessionCookie(client)
+		require.NotNil(t, cookie)
+		session, err := decodeSessionCookie(cookie)
+		require.NoError(t, err)
+		var username string
+		require.NoError(t, db.QueryRow(
+			`SELECT username FROM system.web_sessions WHERE id = $1`, session.ID,
+		).Scan(&username))
+		require.Equal(t, "testuser", username)
+	})
+
+	t.Run("multiple values", func(t *testing.T) {
+		provider.setClaims([]string{"other@elsewhere.com", "nobody@example.com", "testuser@example.com"})
+		client := newClient()
+		require.Equal(t, http.StatusOK, login(client).StatusCode)
+		require.NotNil(t, sessionCookie(client))
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		provider.setClaims("nobody@example.com")
+		client := newClient()
+		require.Equal(t, http.StatusForbidden, login(client).StatusCode)
+		require.Nil(t, sessionCookie(client))
+	})
+
+	t.Run("no matching principal", func(t *testing.T) {
+		provider.setClaims("testuser@elsewhere.com")
+		client := newClient()
+		require.Equal(t, http.StatusInternalServerError, login(client).StatusCode)
+		require.Nil(t, sessionCookie(client))
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		provider.setClaims("testuser@example.com")
+		provider.claims["aud"] = "other-client"
+		client := newClient()
+		require.Equal(t, http.StatusInternalServerError, login(client).StatusCode)
+		require.Nil(t, sessionCookie(client))
+	})
+
+	t.Run("state mismatch", func(t *testing.T) {
+		// A callback that was not initiated by this browser is rejected.
+		client := newClient()
+		resp, err := client.Get(ts.AdminURL() + oidcCallbackPath + "?code=" + fakeOIDCCode + "&state=foo")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Nil(t, sessionCookie(client))
+	})
+
+}
+
+func TestOIDCUIConf(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	sv := &st.SV
+	handlers := map[string]http.Handler{}
+	oidc, err := ConfigureOIDC(ctx, st,
+		func(pattern string, handler http.Handler) { handlers[pattern] = handler },
+		nil /* userLoginFromSSO */, log.AmbientContext{}, false /* secureCookie */)
+	require.NoError(t, err)
+	require.Contains(t, handlers, oidcLoginPath)
+	require.Contains(t, handlers, oidcCallbackPath)
+
+	conf := oidc.GetOIDCConf()
+	require.False(t, conf.Enabled)
+	require.Equal(t, "Log in with your OIDC provider", conf.ButtonText)
+
+	// Enabling OIDC is not sufficient; the provider must be configured too.
+	oidcEnabled.Override(ctx, sv, true)
+	require.False(t, oidc.GetOIDCConf().Enabled)
+
+	oidcClientID.Override(ctx, sv, "fake-client")
+	oidcClientSecret.Override(ctx, sv, "fake-secret")
+	oidcProviderURL.Override(ctx, sv, "https://idp.example.com")
+	oidcClaimJSONKey.Override(ctx, sv, "email")
+	oidcButtonText.Override(ctx, sv, "Log in with Fake")
+	oidcAutoLogin.Override(ctx, sv, true)
+	conf = oidc.GetOIDCConf()
+	require.True(t, conf.Enabled)
+	require.True(t, conf.AutoLogin)
+	require.Equal(t, "Log in with Fake", conf.ButtonText)
+}
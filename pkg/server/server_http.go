@@ -99,8 +99,8 @@ func (s *httpServer) setupRoutes(
 	// OIDC Configuration must happen prior to the UI Handler being defined below so that we have
 	// the system settings initialized for it to pick up from the oidcAuthenticationServer.
 	oidc, err := ConfigureOIDC(
-		ctx, s.cfg.Settings, s.cfg.Locality,
-		s.mux.Handle, authnServer.UserLoginFromSSO, s.cfg.AmbientCtx, s.cfg.ClusterIDContainer.Get(),
+		ctx, s.cfg.Settings, s.mux.Handle, authnServer.UserLoginFromSSO, s.cfg.AmbientCtx,
+		!s.cfg.DisableTLSForHTTP,
 	)
 	if err != nil {
 		return err
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/blobs"
 	"github.com/cockroachdb/cockroach/pkg/blobs/blobspb"
 	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -71,6 +72,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/flowinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob/gcjobnotifier"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
@@ -397,6 +399,14 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 	tracingService := service.New(cfg.Tracer)
 	tracingservicepb.RegisterTracingServer(cfg.grpcServer, tracingService)
 
+	// Arrays with multiple dimensions or a non-default lower bound can only be
+	// encoded once all the nodes of the cluster are able to decode them.
+	setArrayDimsEncoding := func(_ context.Context, cv clusterversion.ClusterVersion) {
+		tree.SetArrayDimsEncodingEnabled(cv.IsActive(clusterversion.MultidimensionalArrays))
+	}
+	setArrayDimsEncoding(ctx, cfg.Settings.Version.ActiveVersionOrEmpty(ctx))
+	cfg.Settings.Version.SetOnChange(setArrayDimsEncoding)
+
 	sqllivenessKnobs, _ := cfg.TestingKnobs.SQLLivenessKnobs.(*sqlliveness.TestingKnobs)
 	cfg.sqlLivenessProvider = slprovider.New(
 		cfg.AmbientCtx,
@@ -549,6 +559,9 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 	cfg.registry.AddMetricStruct(hydratedTablesCache.Metrics())
 
 	gcJobNotifier := gcjobnotifier.New(cfg.Settings, cfg.systemConfigWatcher, codec, cfg.stopper)
+	notificationRegistry := notify.NewRegistry(
+		codec, cfg.db, cfg.circularInternalExecutor, cfg.Settings, cfg.rangeFeedFactory,
+	)
 
 	var compactEngineSpanFunc tree.CompactEngineSpanFunc
 	if !codec.ForSystemTenant() {
@@ -791,6 +804,7 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		ExternalIODirConfig:        cfg.ExternalIODirConfig,
 		GCJobNotifier:              gcJobNotifier,
 		RangeFeedFactory:           cfg.rangeFeedFactory,
+		NotificationRegistry:       notificationRegistry,
 		CollectionFactory:          collectionFactory,
 		SystemTableIDResolver:      descs.MakeSystemTableIDResolver(collectionFactory, cfg.circularInternalExecutor, cfg.db),
 	}
@@ -1294,6 +1308,12 @@ func (s *SQLServer) preStart(
 
 	log.Infof(ctx, "done ensuring all necessary startup migrations have run")
 
+	// Start delivering the notifications sent with NOTIFY. This requires the
+	// system.notifications table, which is created by a startup migration.
+	if err := s.execCfg.NotificationRegistry.Start(ctx, stopper); err != nil {
+		return errors.Wrap(err, "starting notification registry")
+	}
+
 	// Delete all orphaned table leases created by a prior instance of this
 	// node. This also uses SQL.
 	s.leaseMgr.DeleteOrphanedLeases(ctx, orphanedLeasesTimeThresholdNanos)
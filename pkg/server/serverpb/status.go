@@ -61,10 +61,11 @@ func MakeOptionalNodesStatusServer(s NodesStatusServer) OptionalNodesStatusServe
 }
 
 // NodesStatusServer is an endpoint that allows the SQL subsystem
-// to observe node descriptors.
+// to observe node descriptors and store details.
 // It is unavailable to tenants.
 type NodesStatusServer interface {
 	ListNodesInternal(context.Context, *NodesRequest) (*NodesResponse, error)
+	Stores(context.Context, *StoresRequest) (*StoresResponse, error)
 }
 
 // RegionsServer is the subset of the serverpb.StatusInterface that is used
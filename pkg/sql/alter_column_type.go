@@ -52,6 +52,10 @@ var alterColTypeInCombinationNotSupportedErr = unimplemented.NewWithIssuef(
 	49351, "ALTER COLUMN TYPE cannot be used in combination "+
 		"with other ALTER TABLE commands")
 
+var alterColTypeDomainNotSupportedErr = unimplemented.New(
+	"alter column type domain",
+	"ALTER COLUMN TYPE is not supported for columns declared with a domain")
+
 // AlterColumnType takes an AlterTableAlterColumnType, determines
 // which conversion to use and applies the type conversion.
 func AlterColumnType(
@@ -81,6 +85,9 @@ func AlterColumnType(
 	if err != nil {
 		return err
 	}
+	if col.GetType().IsDomain() || typ.IsDomain() {
+		return alterColTypeDomainNotSupportedErr
+	}
 
 	// Special handling for STRING COLLATE xy to verify that we recognize the language.
 	if t.Collation != "" {
@@ -218,6 +225,14 @@ func alterColumnTypeGeneral(
 		}
 	}
 
+	// Disallow ALTER COLUMN TYPE general for columns that have an exclusion
+	// constraint.
+	for i := range tableDesc.ExclusionConstraints {
+		if descpb.ColumnIDs(tableDesc.ExclusionConstraints[i].ColumnIDs).Contains(col.GetID()) {
+			return colWithConstraintNotSupportedErr
+		}
+	}
+
 	// Disallow ALTER COLUMN TYPE general for columns that have a foreign key
 	// constraint.
 	for _, fk := range tableDesc.AllActiveAndInactiveForeignKeys() {
@@ -33,7 +33,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/errors"
@@ -186,15 +185,37 @@ func (p *planner) AlterDatabaseAddRegion(
 	return &alterDatabaseAddRegionNode{n: n, desc: dbDesc}, nil
 }
 
-// GetMultiRegionEnumAddValuePlacementCCL is the public hook point for the
-// CCL-licensed code to determine the placement for a new region inside
-// a region enum.
-var GetMultiRegionEnumAddValuePlacementCCL = func(
-	execCfg *ExecutorConfig, typeDesc *typedesc.Mutable, region tree.Name,
-) (tree.AlterTypeAddValue, error) {
-	return tree.AlterTypeAddValue{}, sqlerrors.NewCCLRequiredError(
-		errors.New("adding regions to a multi-region database requires a CCL binary"),
+// getMultiRegionEnumAddValuePlacement returns the placement of a new region
+// inside the region enum, which keeps its values sorted.
+func getMultiRegionEnumAddValuePlacement(
+	typeDesc *typedesc.Mutable, region tree.Name,
+) tree.AlterTypeAddValue {
+	// Find the location in the enum where we should insert the new value. We
+	// must search for the location (and not append to the end), as we want to
+	// keep the values in sorted order.
+	loc := sort.Search(
+		len(typeDesc.EnumMembers),
+		func(i int) bool {
+			return string(region) < typeDesc.EnumMembers[i].LogicalRepresentation
+		},
 	)
+
+	// If the above search couldn't find a value greater than the region being
+	// added, add the new region at the end of the enum.
+	before := true
+	if loc == len(typeDesc.EnumMembers) {
+		before = false
+		loc = len(typeDesc.EnumMembers) - 1
+	}
+
+	return tree.AlterTypeAddValue{
+		IfNotExists: false,
+		NewVal:      tree.EnumValue(region),
+		Placement: &tree.AlterTypeAddValuePlacement{
+			Before:      before,
+			ExistingVal: tree.EnumValue(typeDesc.EnumMembers[loc].LogicalRepresentation),
+		},
+	}
 }
 
 func (n *alterDatabaseAddRegionNode) startExec(params runParams) error {
@@ -224,14 +245,7 @@ func (n *alterDatabaseAddRegionNode) startExec(params runParams) error {
 		return err
 	}
 
-	placement, err := GetMultiRegionEnumAddValuePlacementCCL(
-		params.p.ExecCfg(),
-		typeDesc,
-		n.n.Region,
-	)
-	if err != nil {
-		return err
-	}
+	placement := getMultiRegionEnumAddValuePlacement(typeDesc, n.n.Region)
 
 	// Add the new region value to the enum. This function adds the value to the enum and
 	// persists the new value to the supplied type descriptor.
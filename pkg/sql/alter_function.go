@@ -0,0 +1,316 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/errors"
+)
+
+type alterFunctionOptionsNode struct {
+	n      *tree.AlterFunctionOptions
+	fnDesc *funcdesc.Mutable
+}
+
+type alterFunctionRenameNode struct {
+	n      *tree.AlterFunctionRename
+	fnDesc *funcdesc.Mutable
+}
+
+type alterFunctionSetSchemaNode struct {
+	n      *tree.AlterFunctionSetSchema
+	fnDesc *funcdesc.Mutable
+}
+
+type alterFunctionSetOwnerNode struct {
+	n      *tree.AlterFunctionSetOwner
+	fnDesc *funcdesc.Mutable
+}
+
+// Use to satisfy the linter.
+var _ planNode = &alterFunctionOptionsNode{n: nil}
+var _ planNode = &alterFunctionRenameNode{n: nil}
+var _ planNode = &alterFunctionSetSchemaNode{n: nil}
+var _ planNode = &alterFunctionSetOwnerNode{n: nil}
+
+// AlterFunctionOptions alters the volatility, the null input behavior or the
+// leakproof property of a user-defined function.
+// Privileges: ownership of the function.
+func (p *planner) AlterFunctionOptions(
+	ctx context.Context, n *tree.AlterFunctionOptions,
+) (planNode, error) {
+	fnDesc, err := p.resolveFunctionForAlter(ctx, &n.Function, true /* checkOwnership */)
+	if err != nil {
+		return nil, err
+	}
+	return &alterFunctionOptionsNode{n: n, fnDesc: fnDesc}, nil
+}
+
+func (n *alterFunctionOptionsNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeAlterCounter("function"))
+	if err := setFuncOptions(n.fnDesc, n.n.Options); err != nil {
+		return err
+	}
+	if err := params.p.writeFuncSchemaChange(
+		params.ctx, n.fnDesc, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+	return validateDescriptor(params.ctx, params.p, n.fnDesc)
+}
+
+func (n *alterFunctionOptionsNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *alterFunctionOptionsNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *alterFunctionOptionsNode) Close(ctx context.Context)           {}
+func (n *alterFunctionOptionsNode) ReadingOwnWrites()                   {}
+
+// AlterFunctionRename renames a user-defined function.
+// Privileges: ownership of the function.
+func (p *planner) AlterFunctionRename(
+	ctx context.Context, n *tree.AlterFunctionRename,
+) (planNode, error) {
+	fnDesc, err := p.resolveFunctionForAlter(ctx, &n.Function, true /* checkOwnership */)
+	if err != nil {
+		return nil, err
+	}
+	return &alterFunctionRenameNode{n: n, fnDesc: fnDesc}, nil
+}
+
+func (n *alterFunctionRenameNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeAlterCounter("function"))
+	newName := string(n.n.NewName)
+	if newName == n.fnDesc.GetName() {
+		return nil
+	}
+	scDesc, err := params.p.getMutableFunctionSchema(params.ctx, n.fnDesc.GetParentSchemaID())
+	if err != nil {
+		return err
+	}
+	if err := checkFunctionSignatureIsFree(scDesc, newName, n.fnDesc); err != nil {
+		return err
+	}
+
+	scDesc.RemoveFunction(n.fnDesc.GetName(), n.fnDesc.GetID())
+	n.fnDesc.SetName(newName)
+	scDesc.AddFunction(newName, n.fnDesc.ToOverload())
+	if err := params.p.writeSchemaDescChange(
+		params.ctx, scDesc,
+		fmt.Sprintf("updating schema %q for rename of function %q", scDesc.GetName(), newName),
+	); err != nil {
+		return err
+	}
+	if err := params.p.writeFuncSchemaChange(
+		params.ctx, n.fnDesc, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+	return validateDescriptor(params.ctx, params.p, n.fnDesc)
+}
+
+func (n *alterFunctionRenameNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *alterFunctionRenameNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *alterFunctionRenameNode) Close(ctx context.Context)           {}
+func (n *alterFunctionRenameNode) ReadingOwnWrites()                   {}
+
+// AlterFunctionSetSchema moves a user-defined function to another schema of
+// the same database.
+// Privileges: ownership of the function and CREATE on the new schema.
+func (p *planner) AlterFunctionSetSchema(
+	ctx context.Context, n *tree.AlterFunctionSetSchema,
+) (planNode, error) {
+	fnDesc, err := p.resolveFunctionForAlter(ctx, &n.Function, true /* checkOwnership */)
+	if err != nil {
+		return nil, err
+	}
+	return &alterFunctionSetSchemaNode{n: n, fnDesc: fnDesc}, nil
+}
+
+func (n *alterFunctionSetSchemaNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeAlterCounter("function"))
+	ctx, p := params.ctx, params.p
+	_, dbDesc, err := p.Descriptors().GetImmutableDatabaseByID(ctx, p.txn, n.fnDesc.GetParentID(),
+		tree.DatabaseLookupFlags{Required: true})
+	if err != nil {
+		return err
+	}
+	sc, err := p.Descriptors().GetSchemaByName(ctx, p.txn, dbDesc, string(n.n.NewSchemaName),
+		tree.SchemaLookupFlags{Required: true, RequireMutable: true})
+	if err != nil {
+		return err
+	}
+	if sc.SchemaKind() != catalog.SchemaUserDefined {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot move function to schema %q", sc.GetName())
+	}
+	if sc.GetID() == n.fnDesc.GetParentSchemaID() {
+		return nil
+	}
+	newScDesc, ok := sc.(*schemadesc.Mutable)
+	if !ok {
+		return errors.AssertionFailedf("expected schema descriptor, got %T", sc)
+	}
+	if err := p.CheckPrivilege(ctx, newScDesc, privilege.CREATE); err != nil {
+		return err
+	}
+	if err := checkFunctionSignatureIsFree(newScDesc, n.fnDesc.GetName(), n.fnDesc); err != nil {
+		return err
+	}
+	oldScDesc, err := p.getMutableFunctionSchema(ctx, n.fnDesc.GetParentSchemaID())
+	if err != nil {
+		return err
+	}
+
+	oldScDesc.RemoveFunction(n.fnDesc.GetName(), n.fnDesc.GetID())
+	n.fnDesc.SetParentSchemaID(newScDesc.GetID())
+	newScDesc.AddFunction(n.fnDesc.GetName(), n.fnDesc.ToOverload())
+	for _, scDesc := range []*schemadesc.Mutable{oldScDesc, newScDesc} {
+		if err := p.writeSchemaDescChange(
+			ctx, scDesc,
+			fmt.Sprintf("updating schema %q for move of function %q", scDesc.GetName(), n.fnDesc.GetName()),
+		); err != nil {
+			return err
+		}
+	}
+	if err := p.writeFuncSchemaChange(
+		ctx, n.fnDesc, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+	return validateDescriptor(ctx, p, n.fnDesc)
+}
+
+func (n *alterFunctionSetSchemaNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *alterFunctionSetSchemaNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *alterFunctionSetSchemaNode) Close(ctx context.Context)           {}
+func (n *alterFunctionSetSchemaNode) ReadingOwnWrites()                   {}
+
+// AlterFunctionSetOwner changes the owner of a user-defined function.
+// Privileges: ownership of the function, membership of the new owning role
+// and CREATE on the schema of the function.
+func (p *planner) AlterFunctionSetOwner(
+	ctx context.Context, n *tree.AlterFunctionSetOwner,
+) (planNode, error) {
+	// The ownership of the function is checked by checkCanAlterToNewOwner.
+	fnDesc, err := p.resolveFunctionForAlter(ctx, &n.Function, false /* checkOwnership */)
+	if err != nil {
+		return nil, err
+	}
+	return &alterFunctionSetOwnerNode{n: n, fnDesc: fnDesc}, nil
+}
+
+func (n *alterFunctionSetOwnerNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeAlterCounter("function"))
+	ctx, p := params.ctx, params.p
+	newOwner, err := n.n.NewOwner.ToSQLUsername(p.SessionData(), security.UsernameValidation)
+	if err != nil {
+		return err
+	}
+	if err := p.checkCanAlterToNewOwner(ctx, n.fnDesc, newOwner); err != nil {
+		return err
+	}
+	scDesc, err := p.Descriptors().GetImmutableSchemaByID(ctx, p.txn, n.fnDesc.GetParentSchemaID(),
+		tree.SchemaLookupFlags{Required: true})
+	if err != nil {
+		return err
+	}
+	if err := p.CheckPrivilege(ctx, scDesc, privilege.CREATE); err != nil {
+		return err
+	}
+
+	// If the owner we want to set to is the current owner, do a no-op.
+	privs := n.fnDesc.GetPrivileges()
+	if newOwner == privs.Owner() {
+		return nil
+	}
+	privs.SetOwner(newOwner)
+	return p.writeFuncSchemaChange(ctx, n.fnDesc, tree.AsStringWithFQNames(n.n, params.Ann()))
+}
+
+func (n *alterFunctionSetOwnerNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *alterFunctionSetOwnerNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *alterFunctionSetOwnerNode) Close(ctx context.Context)           {}
+func (n *alterFunctionSetOwnerNode) ReadingOwnWrites()                   {}
+
+// resolveFunctionForAlter resolves the function altered by an ALTER FUNCTION
+// statement and, if requested, checks that the current user owns it.
+func (p *planner) resolveFunctionForAlter(
+	ctx context.Context, fnObj *tree.FuncObj, checkOwnership bool,
+) (*funcdesc.Mutable, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"ALTER FUNCTION",
+	); err != nil {
+		return nil, err
+	}
+	fnDesc, err := p.resolveMutableFunction(ctx, fnObj, true /* required */)
+	if err != nil {
+		return nil, err
+	}
+	if checkOwnership {
+		if err := p.canDropFunction(ctx, fnDesc); err != nil {
+			return nil, err
+		}
+	}
+	return fnDesc, nil
+}
+
+// getMutableFunctionSchema returns the mutable descriptor of the schema with
+// the given ID, which holds a function.
+func (p *planner) getMutableFunctionSchema(
+	ctx context.Context, id descpb.ID,
+) (*schemadesc.Mutable, error) {
+	mutSchema, err := p.Descriptors().GetMutableDescriptorByID(ctx, p.txn, id)
+	if err != nil {
+		return nil, err
+	}
+	scDesc, ok := mutSchema.(*schemadesc.Mutable)
+	if !ok {
+		return nil, errors.AssertionFailedf("expected schema descriptor, got %T", mutSchema)
+	}
+	return scDesc, nil
+}
+
+// checkFunctionSignatureIsFree returns an error if the schema already holds a
+// function with the given name and the same argument types as fnDesc.
+func checkFunctionSignatureIsFree(
+	scDesc catalog.SchemaDescriptor, name string, fnDesc *funcdesc.Mutable,
+) error {
+	fn, ok := scDesc.GetFunction(name)
+	if !ok {
+		return nil
+	}
+	argTypes := fnDesc.ToOverload().ArgTypes
+	for _, o := range fn.Overloads {
+		if o.ID != fnDesc.GetID() && overloadArgsMatch(o.ArgTypes, argTypes) {
+			fnName := tree.MakeFunctionNameFromPrefix(tree.ObjectNamePrefix{
+				SchemaName:     tree.Name(scDesc.GetName()),
+				ExplicitSchema: true,
+			}, tree.Name(name))
+			return sqlerrors.NewFunctionAlreadyExistsError(&fnName)
+		}
+	}
+	return nil
+}
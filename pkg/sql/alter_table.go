@@ -98,6 +98,15 @@ func (p *planner) AlterTable(ctx context.Context, n *tree.AlterTable) (planNode,
 			tree.Name(tableDesc.GetName()), tree.Name(tableDesc.GetName()))
 	}
 
+	// Columns added with a domain type use the default of the domain.
+	for _, cmd := range n.Cmds {
+		if addCol, ok := cmd.(*tree.AlterTableAddColumn); ok {
+			if err := p.addDomainDefault(ctx, addCol.ColumnDef); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	n.HoistAddColumnConstraints()
 
 	// See if there's any "inject statistics" in the query and type check the
@@ -217,6 +226,10 @@ func (n *alterTableNode) startExec(params runParams) error {
 					continue
 				}
 
+				if err := checkUniqueIndexNotDeferrable(d); err != nil {
+					return err
+				}
+
 				if d.PrimaryKey {
 					// Translate this operation into an ALTER PRIMARY KEY command.
 					alterPK := &tree.AlterTableAlterPrimaryKey{
@@ -438,6 +451,15 @@ func (n *alterTableNode) startExec(params runParams) error {
 				// 	return err
 				// }
 
+			case *tree.ExclusionConstraintTableDef:
+				if err := addExclusionConstraintTableDef(
+					params.ctx, d, n.tableDesc, *tn, NonEmptyTable, t.ValidationBehavior,
+					params.p.SemaCtx(), params.p.ExecCfg().Settings,
+				); err != nil {
+					return err
+				}
+				descriptorChanged = true
+
 			default:
 				return errors.AssertionFailedf(
 					"unsupported constraint: %T", t.ConstraintDef)
@@ -581,6 +603,30 @@ func (n *alterTableNode) startExec(params runParams) error {
 				}
 				foundFk.Validity = descpb.ConstraintValidity_Validated
 
+			case descpb.ConstraintTypeExclusion:
+				var foundExclusion *descpb.ExclusionConstraint
+				for i := range n.tableDesc.ExclusionConstraints {
+					ec := &n.tableDesc.ExclusionConstraints[i]
+					// If the constraint is still being validated, don't allow
+					// VALIDATE CONSTRAINT to run.
+					if ec.Name == name && ec.Validity != descpb.ConstraintValidity_Validating {
+						foundExclusion = ec
+						break
+					}
+				}
+				if foundExclusion == nil {
+					return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+						"constraint %q in the middle of being added, try again later", t.Constraint)
+				}
+				if err := validateExclusionConstraintInTxn(
+					params.ctx, params.ExecCfg().InternalExecutorFactory(
+						params.ctx, params.SessionData(),
+					), n.tableDesc, params.EvalContext().Txn, params.p.User(), name,
+				); err != nil {
+					return err
+				}
+				foundExclusion.Validity = descpb.ConstraintValidity_Validated
+
 			case descpb.ConstraintTypeUnique:
 				if constraint.Index == nil {
 					var foundUnique *descpb.UniqueWithoutIndexConstraint
@@ -614,8 +660,8 @@ func (n *alterTableNode) startExec(params runParams) error {
 
 			default:
 				return pgerror.Newf(pgcode.WrongObjectType,
-					"constraint %q of relation %q is not a foreign key, check, or unique without index"+
-						" constraint", tree.ErrString(&t.Constraint), tree.ErrString(n.n.Table))
+					"constraint %q of relation %q is not a foreign key, check, unique without index,"+
+						" or exclusion constraint", tree.ErrString(&t.Constraint), tree.ErrString(n.n.Table))
 			}
 			descriptorChanged = true
 
@@ -1420,6 +1466,9 @@ func validateConstraintNameIsNotUsed(
 	case *tree.ForeignKeyConstraintTableDef:
 		name = d.Name
 		hasIfNotExists = d.IfNotExists
+	case *tree.ExclusionConstraintTableDef:
+		name = d.Name
+		hasIfNotExists = d.IfNotExists
 	case *tree.UniqueConstraintTableDef:
 		name = d.Name
 		hasIfNotExists = d.IfNotExists
@@ -1596,6 +1645,11 @@ func dropColumnImpl(
 		)
 	}
 
+	// You can't drop a column referenced by a trigger.
+	if err := checkColumnNotReferencedByTriggers(tableDesc, t.Column, "drop"); err != nil {
+		return nil, err
+	}
+
 	// If the dropped column uses a sequence, remove references to it from that sequence.
 	if colToDrop.NumUsesSequences() > 0 {
 		if err := params.p.removeSequenceDependencies(params.ctx, tableDesc, colToDrop); err != nil {
@@ -1632,6 +1686,17 @@ func dropColumnImpl(
 		if err != nil {
 			return nil, err
 		}
+		if droppedFn, isFn, err := params.p.maybeDropDependentFunction(
+			params.ctx, ref.ID,
+			fmt.Sprintf("removing function dependent on column %q which is being dropped", colToDrop.ColName()),
+		); err != nil {
+			return nil, err
+		} else if isFn {
+			if droppedFn != "" {
+				droppedViews = append(droppedViews, droppedFn)
+			}
+			continue
+		}
 		viewDesc, err := params.p.getViewDescForCascade(
 			params.ctx, "column", string(t.Column), tableDesc.ParentID, ref.ID, t.DropBehavior,
 		)
@@ -1788,6 +1853,33 @@ func dropColumnImpl(
 	}
 	tableDesc.UniqueWithoutIndexConstraints = tableDesc.UniqueWithoutIndexConstraints[:sliceIdx]
 
+	// Drop exclusion constraints that reference the column.
+	sliceIdx = 0
+	for i := range tableDesc.ExclusionConstraints {
+		ec := &tableDesc.ExclusionConstraints[i]
+		if ec.IsPartial() {
+			expr, err := parser.ParseExpr(ec.Predicate)
+			if err != nil {
+				return nil, err
+			}
+			colIDs, err := schemaexpr.ExtractColumnIDs(tableDesc, expr)
+			if err != nil {
+				return nil, err
+			}
+			if colIDs.Contains(colToDrop.GetID()) {
+				return nil, pgerror.Newf(pgcode.InvalidColumnReference,
+					"column %q cannot be dropped because it is referenced by exclusion constraint %q",
+					colToDrop.GetName(), ec.Name)
+			}
+		}
+		if descpb.ColumnIDs(ec.ColumnIDs).Contains(colToDrop.GetID()) {
+			continue
+		}
+		tableDesc.ExclusionConstraints[sliceIdx] = *ec
+		sliceIdx++
+	}
+	tableDesc.ExclusionConstraints = tableDesc.ExclusionConstraints[:sliceIdx]
+
 	// Drop check constraints which reference the column.
 	constraintsToDrop := make([]string, 0, len(tableDesc.Checks))
 	constraintInfo, err := tableDesc.GetConstraintInfo()
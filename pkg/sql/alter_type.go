@@ -82,6 +82,21 @@ func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, e
 			"%q is a table's record type and cannot be modified",
 			tree.AsStringWithFQNames(n.Type, &p.semaCtx.Annotations),
 		)
+	case descpb.TypeDescriptor_COMPOSITE:
+		switch n.Cmd.(type) {
+		case *tree.AlterTypeAddValue, *tree.AlterTypeRenameValue, *tree.AlterTypeDropValue:
+			return nil, pgerror.Newf(
+				pgcode.WrongObjectType,
+				"%q is not an enum",
+				tree.AsStringWithFQNames(n.Type, &p.semaCtx.Annotations),
+			)
+		}
+	case descpb.TypeDescriptor_DOMAIN:
+		return nil, pgerror.Newf(
+			pgcode.WrongObjectType,
+			"%q is a domain and cannot be modified",
+			tree.AsStringWithFQNames(n.Type, &p.semaCtx.Annotations),
+		)
 	}
 
 	return &alterTypeNode{
@@ -269,6 +269,7 @@ func (sc *SchemaChanger) runBackfill(ctx context.Context) error {
 				isValidating := c.IsCheck() && c.Check().Validity == descpb.ConstraintValidity_Validating ||
 					c.IsForeignKey() && c.ForeignKey().Validity == descpb.ConstraintValidity_Validating ||
 					c.IsUniqueWithoutIndex() && c.UniqueWithoutIndex().Validity == descpb.ConstraintValidity_Validating ||
+					c.IsExclusion() && c.Exclusion().Validity == descpb.ConstraintValidity_Validating ||
 					c.IsNotNull()
 				isSkippingValidation, err := shouldSkipConstraintValidation(tableDesc, c)
 				if err != nil {
@@ -497,6 +498,26 @@ func (sc *SchemaChanger) dropConstraints(
 						constraint.ConstraintToUpdateDesc(),
 					)
 				}
+			} else if constraint.IsExclusion() {
+				found := false
+				for j, c := range scTable.ExclusionConstraints {
+					if c.Name == constraint.GetName() {
+						scTable.ExclusionConstraints = append(
+							scTable.ExclusionConstraints[:j],
+							scTable.ExclusionConstraints[j+1:]...,
+						)
+						found = true
+						break
+					}
+				}
+				if !found {
+					log.VEventf(
+						ctx, 2,
+						"backfiller tried to drop constraint %+v but it was not found, "+
+							"presumably due to a retry or rollback",
+						constraint.ConstraintToUpdateDesc(),
+					)
+				}
 			}
 		}
 		if err := descsCol.WriteDescToBatch(
@@ -651,6 +672,28 @@ func (sc *SchemaChanger) addConstraints(
 					scTable.UniqueWithoutIndexConstraints = append(scTable.UniqueWithoutIndexConstraints,
 						constraint.UniqueWithoutIndex())
 				}
+			} else if constraint.IsExclusion() {
+				found := false
+				for j := range scTable.ExclusionConstraints {
+					c := &scTable.ExclusionConstraints[j]
+					if c.Name == constraint.GetName() {
+						log.VEventf(
+							ctx, 2,
+							"backfiller tried to add constraint %+v but found existing constraint %+v, "+
+								"presumably due to a retry or rollback",
+							constraint.ConstraintToUpdateDesc(), c,
+						)
+						// Ensure the constraint on the descriptor is set to Validating, in
+						// case we're in the middle of rolling back DROP CONSTRAINT
+						c.Validity = descpb.ConstraintValidity_Validating
+						found = true
+						break
+					}
+				}
+				if !found {
+					scTable.ExclusionConstraints = append(scTable.ExclusionConstraints,
+						constraint.Exclusion())
+				}
 			}
 		}
 		if err := descsCol.WriteDescToBatch(
@@ -754,6 +797,12 @@ func (sc *SchemaChanger) validateConstraints(
 					); err != nil {
 						return err
 					}
+				} else if c.IsExclusion() {
+					if err := validateExclusionConstraintInTxn(
+						ctx, sc.ieFactory(ctx, evalCtx.SessionData()), desc, txn, evalCtx.SessionData().User(), c.GetName(),
+					); err != nil {
+						return err
+					}
 				} else if c.IsNotNull() {
 					if err := validateCheckInTxn(
 						ctx, &semaCtx, sc.ieFactory, evalCtx.SessionData(), desc, txn, c.Check().Expr,
@@ -2321,6 +2370,16 @@ func runSchemaChangesInTxn(
 							break
 						}
 					}
+				} else if c.IsExclusion() {
+					for i := range tableDesc.ExclusionConstraints {
+						if tableDesc.ExclusionConstraints[i].Name == c.GetName() {
+							tableDesc.ExclusionConstraints = append(
+								tableDesc.ExclusionConstraints[:i],
+								tableDesc.ExclusionConstraints[i+1:]...,
+							)
+							break
+						}
+					}
 				} else {
 					return errors.AssertionFailedf("unsupported constraint type: %d", c.ConstraintToUpdateDesc().ConstraintType)
 				}
@@ -2384,6 +2443,16 @@ func runSchemaChangesInTxn(
 				}
 				uwi.Validity = descpb.ConstraintValidity_Validated
 			}
+		} else if c.IsExclusion() {
+			ec := &c.ConstraintToUpdateDesc().ExclusionConstraint
+			if ec.Validity == descpb.ConstraintValidity_Validating {
+				if err := validateExclusionConstraintInTxn(
+					ctx, planner.ExecCfg().InternalExecutor, tableDesc, planner.txn, planner.User(), c.GetName(),
+				); err != nil {
+					return err
+				}
+				ec.Validity = descpb.ConstraintValidity_Validated
+			}
 		} else {
 			return errors.AssertionFailedf("unsupported constraint type: %d", c.ConstraintToUpdateDesc().ConstraintType)
 		}
@@ -2428,6 +2497,10 @@ func runSchemaChangesInTxn(
 			tableDesc.UniqueWithoutIndexConstraints = append(
 				tableDesc.UniqueWithoutIndexConstraints, c.ConstraintToUpdateDesc().UniqueWithoutIndexConstraint,
 			)
+		} else if c.IsExclusion() {
+			tableDesc.ExclusionConstraints = append(
+				tableDesc.ExclusionConstraints, c.ConstraintToUpdateDesc().ExclusionConstraint,
+			)
 		} else {
 			return errors.AssertionFailedf("unsupported constraint type: %d", c.ConstraintToUpdateDesc().ConstraintType)
 		}
@@ -2523,7 +2596,7 @@ func validateFkInTxn(
 
 	ie := ief(ctx, sd)
 	return ie.WithSyntheticDescriptors(syntheticDescs, func() error {
-		return validateForeignKey(ctx, srcTable, targetTable, fk, ie, txn)
+		return validateForeignKey(ctx, srcTable, targetTable, fk, "" /* filter */, ie, txn)
 	})
 }
 
@@ -2579,6 +2652,44 @@ func validateUniqueWithoutIndexConstraintInTxn(
 	})
 }
 
+// validateExclusionConstraintInTxn validates an exclusion constraint
+// within the provided transaction. If the provided table descriptor version
+// is newer than the cluster version, it will be used in the InternalExecutor
+// that performs the validation query.
+//
+// It operates entirely on the current goroutine and is thus able to
+// reuse an existing kv.Txn safely.
+func validateExclusionConstraintInTxn(
+	ctx context.Context,
+	ie sqlutil.InternalExecutor,
+	tableDesc *tabledesc.Mutable,
+	txn *kv.Txn,
+	user security.SQLUsername,
+	constraintName string,
+) error {
+	var syntheticDescs []catalog.Descriptor
+	if tableDesc.Version > tableDesc.ClusterVersion().Version {
+		syntheticDescs = append(syntheticDescs, tableDesc)
+	}
+
+	var ec *descpb.ExclusionConstraint
+	for _, def := range tableDesc.AllActiveAndInactiveExclusionConstraints() {
+		if def.Name == constraintName {
+			ec = def
+			break
+		}
+	}
+	if ec == nil {
+		return errors.AssertionFailedf("exclusion constraint %s does not exist", constraintName)
+	}
+
+	return ie.WithSyntheticDescriptors(syntheticDescs, func() error {
+		return validateExclusionConstraint(
+			ctx, tableDesc, ec, ie, txn, user, false, /* preExisting */
+		)
+	})
+}
+
 // columnBackfillInTxn backfills columns for all mutation columns in
 // the mutation list.
 //
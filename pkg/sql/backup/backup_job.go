@@ -0,0 +1,377 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/backup/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// backupTargetFileSize is the target size of the SSTs written by BACKUP.
+var backupTargetFileSize = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"bulkio.backup.file_size",
+	"target size for individual data files produced during BACKUP",
+	128<<20,
+)
+
+// backupCheckpointInterval is the minimum amount of time between the
+// checkpoints written by a running BACKUP.
+var backupCheckpointInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"bulkio.backup.checkpoint_interval",
+	"the minimum time between writing progress checkpoints during a BACKUP",
+	time.Minute,
+	settings.NonNegativeDuration,
+)
+
+type backupResumer struct {
+	job      *jobs.Job
+	settings *cluster.Settings
+	res      roachpb.RowCount
+}
+
+var _ jobs.Resumer = &backupResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	details := b.job.Details().(jobspb.BackupDetails)
+
+	store, err := storageFromURI(ctx, execCfg, details.URI, p.User())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var prev *backuppb.BackupManifest
+	if n := len(details.IncrementalFrom); n > 0 {
+		if prev, err = readManifestFromURI(ctx, execCfg, details.IncrementalFrom[n-1], p.User()); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := makeBackupManifest(execCfg, details, prev)
+	if err != nil {
+		return err
+	}
+	if err := b.exportSpans(ctx, execCfg, store, details, &manifest, prev); err != nil {
+		return err
+	}
+
+	// The manifest is written last: its presence is what marks the layer as
+	// complete.
+	if err := writeProto(ctx, store, backupManifestName, &manifest); err != nil {
+		return errors.Wrap(err, "writing backup manifest")
+	}
+	if err := store.Delete(ctx, backupCheckpointName); err != nil {
+		log.Warningf(ctx, "failed to remove backup checkpoint: %v", err)
+	}
+	if details.CollectionURI != "" && len(details.IncrementalFrom) == 0 {
+		collection, err := storageFromURI(ctx, execCfg, details.CollectionURI, p.User())
+		if err != nil {
+			return err
+		}
+		defer collection.Close()
+		if err := writeLatest(ctx, collection, details.Destination.Subdir); err != nil {
+			return errors.Wrap(err, "writing LATEST file")
+		}
+	}
+
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		return releaseProtectedTimestamp(ctx, txn, execCfg.ProtectedTimestampProvider, details.ProtectedTimestampRecord)
+	}); err != nil {
+		log.Errorf(ctx, "failed to release protected timestamp: %v", err)
+	}
+
+	b.res = countRows(manifest.EntryCounts, manifest.Descriptors)
+	return nil
+}
+
+// makeBackupManifest returns the manifest of the layer described by details,
+// before any data has been exported.
+func makeBackupManifest(
+	execCfg *sql.ExecutorConfig, details jobspb.BackupDetails, prev *backuppb.BackupManifest,
+) (backuppb.BackupManifest, error) {
+	m := backuppb.BackupManifest{
+		StartTime:         details.StartTime,
+		EndTime:           details.EndTime,
+		Descriptors:       details.ResolvedTargets,
+		CompleteDbs:       details.ResolvedCompleteDbs,
+		RevisionHistory:   details.RevisionHistory,
+		RevisionStartTime: details.StartTime,
+		ClusterID:         execCfg.LogicalClusterID(),
+		FormatVersion:     backupFormatVersion,
+	}
+	if details.FullCluster {
+		m.DescriptorCoverage = tree.AllDescriptors
+	}
+	for i := range details.ResolvedTargets {
+		desc := descbuilder.NewBuilder(&details.ResolvedTargets[i]).BuildImmutable()
+		tbl, ok := desc.(catalog.TableDescriptor)
+		if !ok || tbl.IsView() || tbl.IsVirtualTable() {
+			continue
+		}
+		span := tbl.TableSpan(execCfg.Codec)
+		m.Spans = append(m.Spans, span)
+		if prev != nil && !containsSpan(prev.Spans, span) {
+			m.IntroducedSpans = append(m.IntroducedSpans, span)
+		}
+	}
+	return m, nil
+}
+
+// containsSpan returns whether spans contains an entry equal to span.
+func containsSpan(spans []roachpb.Span, span roachpb.Span) bool {
+	for _, s := range spans {
+		if s.Equal(span) {
+			return true
+		}
+	}
+	return false
+}
+
+// exportSpans exports the data in each of the manifest's spans to store,
+// recording the written files in the manifest. Progress is periodically
+// checkpointed so that a resumed job does not need to export spans again.
+func (b *backupResumer) exportSpans(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	store cloud.ExternalStorage,
+	details jobspb.BackupDetails,
+	m *backuppb.BackupManifest,
+	prev *backuppb.BackupManifest,
+) error {
+	checkpoint, err := readCheckpoint(ctx, store)
+	if err != nil {
+		return err
+	}
+	m.Files = checkpoint.Files
+	if !checkpoint.RevisionStartTime.IsEmpty() {
+		m.RevisionStartTime = checkpoint.RevisionStartTime
+	}
+	completed := checkpoint.CompletedSpans
+
+	lastCheckpoint := timeutil.Now()
+	for i, span := range m.Spans {
+		if containsSpan(completed, span) {
+			continue
+		}
+		startTime := details.StartTime
+		if prev != nil && containsSpan(m.IntroducedSpans, span) {
+			// Spans which were not part of the previous layer are backed up in
+			// their entirety.
+			startTime = hlc.Timestamp{}
+		}
+		if err := b.exportSpan(ctx, execCfg, store, details, m, span, startTime); err != nil {
+			return err
+		}
+		completed = append(completed, span)
+
+		if timeutil.Since(lastCheckpoint) < backupCheckpointInterval.Get(&execCfg.Settings.SV) {
+			continue
+		}
+		lastCheckpoint = timeutil.Now()
+		if err := writeProto(ctx, store, backupCheckpointName, &backuppb.BackupCheckpoint{
+			CompletedSpans:    completed,
+			Files:             m.Files,
+			RevisionStartTime: m.RevisionStartTime,
+		}); err != nil {
+			return errors.Wrap(err, "writing backup checkpoint")
+		}
+		fraction := float32(i+1) / float32(len(m.Spans))
+		if err := b.job.FractionProgressed(ctx, nil /* txn */, jobs.FractionUpdater(fraction)); err != nil {
+			return err
+		}
+	}
+	for _, f := range m.Files {
+		m.EntryCounts.Add(f.EntryCounts)
+	}
+	return nil
+}
+
+// exportSpan exports the data in span to store, paginating the requests so
+// that each response holds a bounded amount of data.
+func (b *backupResumer) exportSpan(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	store cloud.ExternalStorage,
+	details jobspb.BackupDetails,
+	m *backuppb.BackupManifest,
+	span roachpb.Span,
+	startTime hlc.Timestamp,
+) error {
+	filter := roachpb.MVCCFilter_Latest
+	if details.RevisionHistory {
+		filter = roachpb.MVCCFilter_All
+	}
+	targetSize := backupTargetFileSize.Get(&execCfg.Settings.SV)
+	for span.Key != nil {
+		req := &roachpb.ExportRequest{
+			RequestHeader:  roachpb.RequestHeaderFromSpan(span),
+			StartTime:      startTime,
+			MVCCFilter:     filter,
+			ReturnSST:      true,
+			TargetFileSize: targetSize,
+		}
+		header := roachpb.Header{Timestamp: details.EndTime, TargetBytes: targetSize}
+		raw, pErr := kv.SendWrappedWith(ctx, execCfg.DB.NonTransactionalSender(), header, req)
+		if pErr != nil {
+			return errors.Wrapf(pErr.GoError(), "exporting %s", span)
+		}
+		resp := raw.(*roachpb.ExportResponse)
+		if details.RevisionHistory && m.RevisionStartTime.Less(resp.StartTime) {
+			// Revisions below the GC threshold of any span are not included in
+			// the backup, so the layer can only be restored as of times above it.
+			m.RevisionStartTime = resp.StartTime
+		}
+		for _, file := range resp.Files {
+			name := fmt.Sprintf("%s/%d-%d.sst", dataDirName, b.job.ID(), len(m.Files))
+			if err := cloud.WriteFile(ctx, store, name, bytes.NewReader(file.SST)); err != nil {
+				return errors.Wrapf(err, "writing %s", name)
+			}
+			m.Files = append(m.Files, backuppb.BackupManifest_File{
+				Span:        file.Span,
+				Path:        name,
+				EntryCounts: file.Exported,
+			})
+		}
+		if resp.ResumeSpan == nil {
+			break
+		}
+		span = *resp.ResumeSpan
+	}
+	return nil
+}
+
+// readCheckpoint reads the checkpoint left behind by a previous attempt at
+// running the backup, if any.
+func readCheckpoint(
+	ctx context.Context, store cloud.ExternalStorage,
+) (backuppb.BackupCheckpoint, error) {
+	var checkpoint backuppb.BackupCheckpoint
+	buf, err := readFile(ctx, store, backupCheckpointName)
+	if err != nil {
+		if errors.Is(err, cloud.ErrFileDoesNotExist) {
+			return checkpoint, nil
+		}
+		return checkpoint, errors.Wrap(err, "reading backup checkpoint")
+	}
+	if err := protoutil.Unmarshal(buf, &checkpoint); err != nil {
+		return checkpoint, errors.Wrap(err, "decoding backup checkpoint")
+	}
+	return checkpoint, nil
+}
+
+// countRows derives the number of rows and index entries from the entry
+// counts of a BulkOpSummary, using the primary indexes of the given tables.
+func countRows(summary roachpb.BulkOpSummary, descs []descpb.Descriptor) roachpb.RowCount {
+	pkIDs := make(map[uint64]struct{})
+	for i := range descs {
+		if tbl, ok := descbuilder.NewBuilder(&descs[i]).BuildImmutable().(catalog.TableDescriptor); ok {
+			pkIDs[roachpb.BulkOpSummaryID(uint64(tbl.GetID()), uint64(tbl.GetPrimaryIndexID()))] = struct{}{}
+		}
+	}
+	res := roachpb.RowCount{DataSize: summary.DataSize}
+	for id, count := range summary.EntryCounts {
+		if _, ok := pkIDs[id]; ok {
+			res.Rows += count
+		} else {
+			res.IndexEntries += count
+		}
+	}
+	return res
+}
+
+// releaseProtectedTimestamp releases the protected timestamp record with the
+// given ID, if any.
+func releaseProtectedTimestamp(
+	ctx context.Context, txn *kv.Txn, pts protectedts.Storage, ptsID *uuid.UUID,
+) error {
+	// If the job doesn't have a protected timestamp then there's nothing to do.
+	if ptsID == nil {
+		return nil
+	}
+	err := pts.Release(ctx, txn, *ptsID)
+	if errors.Is(err, protectedts.ErrNotExists) {
+		// No reason to return an error which might cause problems if it doesn't
+		// seem to exist.
+		log.Warningf(ctx, "failed to release protected which seems not to exist: %v", err)
+		err = nil
+	}
+	return err
+}
+
+// ReportResults implements JobResultsReporter interface.
+func (b *backupResumer) ReportResults(ctx context.Context, resultsCh chan<- tree.Datums) error {
+	select {
+	case resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(b.job.ID())),
+		tree.NewDString(string(jobs.StatusSucceeded)),
+		tree.NewDFloat(tree.DFloat(1.0)),
+		tree.NewDInt(tree.DInt(b.res.Rows)),
+		tree.NewDInt(tree.DInt(b.res.IndexEntries)),
+		tree.NewDInt(tree.DInt(b.res.DataSize)),
+	}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (b *backupResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	details := b.job.Details().(jobspb.BackupDetails)
+	// Files written so far are left in place; they are not referenced by any
+	// manifest and a later backup to the same destination overwrites them.
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		return releaseProtectedTimestamp(ctx, txn, execCfg.ProtectedTimestampProvider, details.ProtectedTimestampRecord)
+	})
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeBackup,
+		func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
+			return &backupResumer{
+				job:      job,
+				settings: settings,
+			}
+		},
+		jobs.UsesTenantCostControl,
+	)
+}
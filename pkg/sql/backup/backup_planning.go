@@ -0,0 +1,487 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobsprotectedts"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/backup/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/nstree"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// featureBackupEnabled is used to enable and disable the BACKUP feature.
+var featureBackupEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"feature.backup.enabled",
+	"set to true to enable backups, false to disable; default is true",
+	featureflag.FeatureFlagEnabledDefault,
+).WithPublic()
+
+// checkUnsupportedBackupOptions returns an error if the statement uses
+// any BACKUP option which is not supported by this implementation.
+func checkUnsupportedBackupOptions(backupStmt *tree.Backup) error {
+	if backupStmt.Options.EncryptionPassphrase != nil || backupStmt.Options.EncryptionKMSURI != nil {
+		return pgerror.New(pgcode.FeatureNotSupported, "encrypted backups are not supported")
+	}
+	if backupStmt.Options.IncrementalStorage != nil {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"the incremental_location option is not supported")
+	}
+	if len(backupStmt.To) != 1 {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"partitioned (locality-aware) backups are not supported")
+	}
+	if backupStmt.Targets != nil && backupStmt.Targets.TenantID.Specified {
+		return pgerror.New(pgcode.FeatureNotSupported, "backing up tenants is not supported")
+	}
+	return nil
+}
+
+// checkStorageAccess ensures that the user may access each of the given
+// URIs. URIs which rely on implicit credentials or access the node's local
+// resources require the admin role.
+func checkStorageAccess(ctx context.Context, p sql.PlanHookState, op string, uris []string) error {
+	if p.ExecCfg().ExternalIODirConfig.EnableNonAdminImplicitAndArbitraryOutbound {
+		return nil
+	}
+	for _, uri := range uris {
+		conf, err := cloud.ExternalStorageConfFromURI(uri, p.User())
+		if err != nil {
+			return err
+		}
+		if !conf.AccessIsWithExplicitAuth() {
+			if err := p.RequireAdminRole(ctx,
+				fmt.Sprintf("%s using the specified %s URI", op, conf.Provider.String())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backupDestination describes where a new backup layer is written and which
+// layers, if any, it builds upon.
+type backupDestination struct {
+	// uri is the location the new layer is written to.
+	uri string
+	// collectionURI is the collection the layer is written to, if the backup
+	// was created with BACKUP INTO.
+	collectionURI string
+	// subdir is the subdirectory of the full backup within the collection.
+	subdir string
+	// prev is the chain of layers the new layer is an incremental on top of.
+	// It is empty for full backups.
+	prev resolvedBackupChain
+}
+
+// resolveBackupDestination determines where the layer created by backupStmt
+// is written to and resolves the layers it builds upon.
+func resolveBackupDestination(
+	ctx context.Context,
+	p sql.PlanHookState,
+	backupStmt *tree.Backup,
+	to string,
+	subdir string,
+	incrementalFrom []string,
+	endTime hlc.Timestamp,
+) (backupDestination, error) {
+	execCfg := p.ExecCfg()
+	dest := backupDestination{uri: to}
+
+	if backupStmt.Nested {
+		dest.collectionURI = to
+		switch {
+		case backupStmt.AppendToLatest:
+			store, err := storageFromURI(ctx, execCfg, to, p.User())
+			if err != nil {
+				return dest, err
+			}
+			defer store.Close()
+			if subdir, err = readLatest(ctx, store); err != nil {
+				return dest, err
+			}
+		case subdir == "":
+			// BACKUP INTO without a subdirectory creates a new full backup.
+			dest.subdir = fullBackupSubdir(endTime.GoTime())
+			uri, err := appendPaths(to, dest.subdir)
+			if err != nil {
+				return dest, err
+			}
+			dest.uri = uri
+			return dest, checkDestinationEmpty(ctx, execCfg, p, dest.uri)
+		}
+		dest.subdir = subdir
+		fullURI, err := appendPaths(to, subdir)
+		if err != nil {
+			return dest, err
+		}
+		if dest.prev, err = resolveBackupChain(ctx, execCfg, p.User(), fullURI, nil); err != nil {
+			return dest, err
+		}
+		if dest.uri, err = appendPaths(fullURI, incrementalBackupSubdir(endTime.GoTime())); err != nil {
+			return dest, err
+		}
+		return dest, checkDestinationEmpty(ctx, execCfg, p, dest.uri)
+	}
+
+	if len(incrementalFrom) > 0 {
+		var err error
+		dest.prev, err = resolveBackupChain(ctx, execCfg, p.User(), incrementalFrom[0], incrementalFrom[1:])
+		if err != nil {
+			return dest, err
+		}
+	}
+	return dest, checkDestinationEmpty(ctx, execCfg, p, dest.uri)
+}
+
+// checkDestinationEmpty returns an error if uri already holds a backup.
+func checkDestinationEmpty(
+	ctx context.Context, execCfg *sql.ExecutorConfig, p sql.PlanHookState, uri string,
+) error {
+	store, err := storageFromURI(ctx, execCfg, uri, p.User())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	exists, err := containsManifest(ctx, store)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return pgerror.Newf(pgcode.FileAlreadyExists,
+			"%s already contains a %s file", redactURI(uri), backupManifestName)
+	}
+	return nil
+}
+
+// backupJobDescription returns a description of the backup with any
+// credentials redacted from its URIs.
+func backupJobDescription(
+	p sql.PlanHookState, orig *tree.Backup, to string, subdir string, incrementalFrom []string,
+) string {
+	stmt := *orig
+	stmt.To = tree.StringOrPlaceholderOptList{tree.NewDString(redactURI(to))}
+	stmt.IncrementalFrom = nil
+	for _, uri := range incrementalFrom {
+		stmt.IncrementalFrom = append(stmt.IncrementalFrom, tree.NewDString(redactURI(uri)))
+	}
+	if stmt.Nested {
+		stmt.AppendToLatest = false
+		stmt.Subdir = tree.NewDString(subdir)
+	}
+	ann := p.ExtendedEvalContext().Annotations
+	return tree.AsStringWithFQNames(&stmt, ann)
+}
+
+// getAllDescriptorsAt returns all descriptors in the cluster as of ts, or as
+// of the current time if ts is empty.
+func getAllDescriptorsAt(
+	ctx context.Context, execCfg *sql.ExecutorConfig, ts hlc.Timestamp,
+) (nstree.Catalog, error) {
+	var all nstree.Catalog
+	err := sql.DescsTxn(ctx, execCfg, func(ctx context.Context, txn *kv.Txn, col *descs.Collection) error {
+		if !ts.IsEmpty() {
+			if err := txn.SetFixedTimestamp(ctx, ts); err != nil {
+				return err
+			}
+		}
+		var err error
+		all, err = col.GetAllDescriptors(ctx, txn)
+		return err
+	})
+	return all, err
+}
+
+// checkBackupPrivileges ensures the user may back up the resolved targets.
+// Full cluster backups require the admin role, other backups require the
+// SELECT privilege on every table and CONNECT on every requested database.
+func checkBackupPrivileges(
+	ctx context.Context, p sql.PlanHookState, backupStmt *tree.Backup, targets resolvedTargets,
+) error {
+	if backupStmt.Coverage() == tree.AllDescriptors {
+		return p.RequireAdminRole(ctx, "BACKUP of the full cluster")
+	}
+	for _, db := range targets.requestedDBs {
+		if err := p.CheckPrivilege(ctx, db, privilege.CONNECT); err != nil {
+			return err
+		}
+	}
+	for _, desc := range targets.descs {
+		if tbl, ok := desc.(catalog.TableDescriptor); ok {
+			if err := p.CheckPrivilege(ctx, tbl, privilege.SELECT); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkIncrementalCompatible ensures that a new layer ending at endTime may be
+// taken on top of prev.
+func checkIncrementalCompatible(
+	backupStmt *tree.Backup, prev *backuppb.BackupManifest, endTime hlc.Timestamp,
+) error {
+	if prev.DescriptorCoverage != backupStmt.Coverage() {
+		return pgerror.New(pgcode.InvalidParameterValue,
+			"a full cluster backup and a backup of specific targets cannot be combined "+
+				"into one incremental chain")
+	}
+	if !prev.EndTime.Less(endTime) {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"incremental backup at %s must be after the previous backup, which ends at %s",
+			endTime, prev.EndTime)
+	}
+	return nil
+}
+
+func backupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	backupStmt, ok := stmt.(*tree.Backup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureBackupEnabled,
+		"BACKUP",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	if err := checkUnsupportedBackupOptions(backupStmt); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	toFn, err := p.TypeAsString(ctx, backupStmt.To[0], "BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	incrementalFromFn, err := p.TypeAsStringArray(ctx, backupStmt.IncrementalFrom, "BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	var subdirFn func() (string, error)
+	if backupStmt.Subdir != nil {
+		if subdirFn, err = p.TypeAsString(ctx, backupStmt.Subdir, "BACKUP"); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	isDetached := backupStmt.Options.Detached
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if !(p.ExtendedEvalContext().TxnIsSingleStmt || isDetached) {
+			return errors.Errorf("BACKUP cannot be used inside a multi-statement transaction without DETACHED option")
+		}
+
+		to, err := toFn()
+		if err != nil {
+			return err
+		}
+		incrementalFrom, err := incrementalFromFn()
+		if err != nil {
+			return err
+		}
+		var subdir string
+		if subdirFn != nil {
+			if subdir, err = subdirFn(); err != nil {
+				return err
+			}
+			if subdir == latestKeyword {
+				backupStmt.AppendToLatest = true
+				subdir = ""
+			}
+		}
+		if err := checkStorageAccess(ctx, p, "BACKUP", append([]string{to}, incrementalFrom...)); err != nil {
+			return err
+		}
+
+		endTime := p.ExecCfg().Clock.Now()
+		if backupStmt.AsOf.Expr != nil {
+			asOf, err := p.EvalAsOfTimestamp(ctx, backupStmt.AsOf)
+			if err != nil {
+				return err
+			}
+			endTime = asOf.Timestamp
+		}
+
+		dest, err := resolveBackupDestination(ctx, p, backupStmt, to, subdir, incrementalFrom, endTime)
+		if err != nil {
+			return err
+		}
+		var startTime hlc.Timestamp
+		if n := len(dest.prev.manifests); n > 0 {
+			prev := dest.prev.manifests[n-1]
+			if err := checkIncrementalCompatible(backupStmt, prev, endTime); err != nil {
+				return err
+			}
+			startTime = prev.EndTime
+		}
+
+		all, err := getAllDescriptorsAt(ctx, p.ExecCfg(), endTime)
+		if err != nil {
+			return err
+		}
+		idx := makeDescriptorIndexFromCatalog(all)
+		targets, err := resolveTargets(&idx, backupStmt.Targets,
+			p.SessionData().Database, p.SessionData().SearchPath)
+		if err != nil {
+			return err
+		}
+		if len(targets.descs) == 0 {
+			return pgerror.New(pgcode.InvalidParameterValue, "no descriptors selected for backup")
+		}
+		if err := checkBackupPrivileges(ctx, p, backupStmt, targets); err != nil {
+			return err
+		}
+
+		details := jobspb.BackupDetails{
+			StartTime:       startTime,
+			EndTime:         endTime,
+			URI:             dest.uri,
+			CollectionURI:   dest.collectionURI,
+			Destination:     jobspb.BackupDetails_Destination{To: []string{to}, Subdir: dest.subdir},
+			RevisionHistory: backupStmt.Options.CaptureRevisionHistory,
+			FullCluster:     backupStmt.Coverage() == tree.AllDescriptors,
+			Detached:        isDetached,
+		}
+		if len(dest.prev.uris) > 0 {
+			details.IncrementalFrom = dest.prev.uris
+			details.Destination.Exists = true
+		}
+		var tableIDs descpb.IDs
+		for _, desc := range targets.descs {
+			details.ResolvedTargets = append(details.ResolvedTargets, *desc.DescriptorProto())
+			if _, ok := desc.(catalog.TableDescriptor); ok {
+				tableIDs = append(tableIDs, desc.GetID())
+			}
+		}
+		details.ResolvedCompleteDbs = targets.completeDBs
+		for _, db := range targets.requestedDBs {
+			details.RequestedTargets = append(details.RequestedTargets, *db.DescriptorProto())
+		}
+
+		// Protect the data being backed up from garbage collection until the
+		// backup completes. Incremental backups need the revisions since the
+		// previous layer, full backups only need the state as of endTime unless
+		// revision history was requested.
+		protectTS := endTime
+		if !startTime.IsEmpty() {
+			protectTS = startTime
+		}
+		ptsID := uuid.MakeV4()
+		details.ProtectedTimestampRecord = &ptsID
+
+		jr := jobs.Record{
+			Description: backupJobDescription(p, backupStmt, to, dest.subdir, incrementalFrom),
+			Username:    p.User(),
+			Details:     details,
+			Progress:    jobspb.BackupProgress{},
+		}
+		protect := func(ctx context.Context, txn *kv.Txn, jobID jobspb.JobID) error {
+			target := ptpb.MakeSchemaObjectsTarget(tableIDs)
+			if details.FullCluster {
+				target = ptpb.MakeClusterTarget()
+			}
+			rec := jobsprotectedts.MakeRecord(ptsID, int64(jobID), protectTS,
+				nil /* deprecatedSpans */, jobsprotectedts.Jobs, target)
+			return p.ExecCfg().ProtectedTimestampProvider.Protect(ctx, txn, rec)
+		}
+
+		if isDetached {
+			// When running inside an explicit transaction, we simply create the job
+			// record. We do not wait for the job to finish.
+			jobID := p.ExecCfg().JobRegistry.MakeJobID()
+			txn := p.ExtendedEvalContext().Txn
+			if _, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(ctx, jr, jobID, txn); err != nil {
+				return err
+			}
+			if err := protect(ctx, txn, jobID); err != nil {
+				return err
+			}
+			resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+			return nil
+		}
+
+		// We create the job record in the planner's transaction to ensure that
+		// the job record creation happens transactionally.
+		plannerTxn := p.ExtendedEvalContext().Txn
+
+		var sj *jobs.StartableJob
+		if err := func() (err error) {
+			defer func() {
+				if err == nil || sj == nil {
+					return
+				}
+				if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+					log.Errorf(ctx, "failed to cleanup job: %v", cleanupErr)
+				}
+			}()
+			jobID := p.ExecCfg().JobRegistry.MakeJobID()
+			if err := p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(ctx, &sj, jobID, plannerTxn, jr); err != nil {
+				return err
+			}
+			if err := protect(ctx, plannerTxn, jobID); err != nil {
+				return err
+			}
+			// We commit the transaction here so that the job can be started. This
+			// is safe because we're in an implicit transaction.
+			return plannerTxn.Commit(ctx)
+		}(); err != nil {
+			return err
+		}
+
+		if err := sj.Start(ctx); err != nil {
+			return err
+		}
+		if err := sj.AwaitCompletion(ctx); err != nil {
+			return err
+		}
+		return sj.ReportExecutionResults(ctx, resultsCh)
+	}
+
+	if isDetached {
+		return fn, jobs.DetachedJobExecutionResultHeader, nil, false, nil
+	}
+	return fn, jobs.BulkJobExecutionResultHeader, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook("backup", backupPlanHook)
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func startBackupTestServer(t *testing.T) (*sqlutils.SQLRunner, func()) {
+	dir, cleanupDir := testutils.TempDir(t)
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE data`)
+	sqlDB.Exec(t, `CREATE TABLE data.kv (k INT PRIMARY KEY, v STRING)`)
+	sqlDB.Exec(t, `INSERT INTO data.kv SELECT i, 'v' || i::STRING FROM generate_series(1, 100) AS g(i)`)
+	return sqlDB, func() {
+		s.Stopper().Stop(context.Background())
+		cleanupDir()
+	}
+}
+
+func TestBackupRestoreDatabase(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sqlDB, cleanup := startBackupTestServer(t)
+	defer cleanup()
+
+	sqlDB.Exec(t, `CREATE VIEW data.v AS SELECT k FROM data.kv WHERE k > 50`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO 'nodelocal://0/db'`)
+
+	sqlDB.ExpectErr(t, `database "data" already exists`,
+		`RESTORE DATABASE data FROM 'nodelocal://0/db'`)
+	sqlDB.ExpectErr(t, `already contains a BACKUP_MANIFEST file`,
+		`BACKUP DATABASE data TO 'nodelocal://0/db'`)
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM 'nodelocal://0/db' WITH new_db_name = 'data2'`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*), sum(k) FROM data2.kv`, [][]string{{"100", "5050"}})
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data2.v`, [][]string{{"50"}})
+
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM 'nodelocal://0/db'`)
+	sqlDB.CheckQueryResults(t, `SELECT v FROM data.kv WHERE k = 42`, [][]string{{"v42"}})
+}
+
+func TestBackupRestoreTable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sqlDB, cleanup := startBackupTestServer(t)
+	defer cleanup()
+
+	sqlDB.Exec(t, `BACKUP TABLE data.kv TO 'userfile:///kv'`)
+
+	sqlDB.ExpectErr(t, `relation "kv" already exists`,
+		`RESTORE TABLE data.kv FROM 'userfile:///kv'`)
+
+	sqlDB.Exec(t, `CREATE DATABASE other`)
+	sqlDB.Exec(t, `RESTORE TABLE data.kv FROM 'userfile:///kv' WITH into_db = 'other'`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM other.kv`, [][]string{{"100"}})
+
+	// The restored table is independent from the original one.
+	sqlDB.Exec(t, `DELETE FROM data.kv WHERE k > 10`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM other.kv`, [][]string{{"100"}})
+}
+
+func TestBackupRestoreFunctions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sqlDB, cleanup := startBackupTestServer(t)
+	defer cleanup()
+
+	sqlDB.Exec(t, `CREATE SCHEMA data.sc`)
+	sqlDB.Exec(t, `CREATE TYPE data.sc.color AS ENUM ('red', 'green')`)
+	sqlDB.Exec(t, `CREATE FUNCTION data.sc.lookup(x INT) RETURNS STRING LANGUAGE SQL
+		AS 'SELECT v FROM data.public.kv WHERE k = x'`)
+	sqlDB.Exec(t, `CREATE FUNCTION data.sc.paint(c data.sc.color) RETURNS STRING LANGUAGE SQL
+		AS 'SELECT c::STRING'`)
+	sqlDB.Exec(t, `CREATE FUNCTION data.public.non_negative(x INT) RETURNS BOOL LANGUAGE SQL
+		AS 'SELECT x >= 0'`)
+	sqlDB.Exec(t, `CREATE TABLE data.balances (id INT PRIMARY KEY, balance INT)`)
+	sqlDB.Exec(t, `CREATE TRIGGER check_balance BEFORE INSERT ON data.balances
+		FOR EACH ROW EXECUTE FUNCTION data.public.non_negative(new.balance)`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO 'nodelocal://0/funcs'`)
+
+	// Tables with triggers cannot be restored without their functions.
+	sqlDB.Exec(t, `CREATE DATABASE other`)
+	sqlDB.ExpectErr(t, `cannot restore table "balances" without the function \d+ executed by trigger "check_balance"`,
+		`RESTORE TABLE data.balances FROM 'nodelocal://0/funcs' WITH into_db = 'other'`)
+
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM 'nodelocal://0/funcs'`)
+
+	sqlDB.CheckQueryResults(t, `SELECT data.sc.lookup(42), data.sc.paint('green')`,
+		[][]string{{"v42", "green"}})
+	sqlDB.Exec(t, `INSERT INTO data.balances VALUES (1, 10), (2, -10)`)
+	sqlDB.CheckQueryResults(t, `SELECT id FROM data.balances`, [][]string{{"1"}})
+
+	// The function mapping of the restored schema refers to the restored
+	// function descriptors, which refer to the restored relations and types.
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM crdb_internal.invalid_objects`, [][]string{{"0"}})
+	sqlDB.ExpectErr(t, `cannot drop relation "kv" because function "data.sc.lookup" depends on it`,
+		`DROP TABLE data.kv`)
+	sqlDB.ExpectErr(t, `cannot drop type "color" because function "data.sc.paint" depends on it`,
+		`DROP TYPE data.sc.color`)
+	sqlDB.Exec(t, `DROP FUNCTION data.sc.lookup`)
+	sqlDB.Exec(t, `DROP TABLE data.kv`)
+}
+
+func TestBackupRestoreIncremental(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sqlDB, cleanup := startBackupTestServer(t)
+	defer cleanup()
+
+	const collection = `'nodelocal://0/collection'`
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO `+collection)
+
+	sqlDB.Exec(t, `DELETE FROM data.kv WHERE k > 50`)
+	sqlDB.Exec(t, `UPDATE data.kv SET v = 'updated' WHERE k = 1`)
+	sqlDB.Exec(t, `CREATE TABLE data.added (x INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `INSERT INTO data.added VALUES (1), (2)`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN `+collection)
+
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM [SHOW BACKUPS IN `+collection+`]`, [][]string{{"1"}})
+	sqlDB.CheckQueryResults(t,
+		`SELECT backup_type, count(*) FROM [SHOW BACKUP LATEST IN `+collection+`]
+		 WHERE object_type = 'table' GROUP BY backup_type ORDER BY backup_type`,
+		[][]string{{"full", "1"}, {"incremental", "2"}},
+	)
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM LATEST IN `+collection+` WITH new_db_name = 'restored'`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM restored.kv`, [][]string{{"50"}})
+	sqlDB.CheckQueryResults(t, `SELECT v FROM restored.kv WHERE k = 1`, [][]string{{"updated"}})
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM restored.added`, [][]string{{"2"}})
+}
+
+func TestBackupRestoreRevisionHistory(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sqlDB, cleanup := startBackupTestServer(t)
+	defer cleanup()
+
+	var beforeDelete string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&beforeDelete)
+	sqlDB.Exec(t, `DELETE FROM data.kv WHERE k > 10`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO 'nodelocal://0/revs' WITH revision_history`)
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM 'nodelocal://0/revs'
+		AS OF SYSTEM TIME `+beforeDelete+` WITH new_db_name = 'before'`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM before.kv`, [][]string{{"100"}})
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM 'nodelocal://0/revs' WITH new_db_name = 'after'`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM after.kv`, [][]string{{"10"}})
+}
+
+func TestShowBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sqlDB, cleanup := startBackupTestServer(t)
+	defer cleanup()
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO 'nodelocal://0/show'`)
+
+	sqlDB.CheckQueryResults(t,
+		`SELECT database_name, parent_schema_name, object_name, object_type, backup_type, rows
+		 FROM [SHOW BACKUP 'nodelocal://0/show'] WHERE object_type IN ('database', 'table')
+		 ORDER BY object_type`,
+		[][]string{
+			{"NULL", "NULL", "data", "database", "full", "NULL"},
+			{"data", "public", "kv", "table", "full", "100"},
+		},
+	)
+
+	var createStmt string
+	sqlDB.QueryRow(t, `SELECT create_statement FROM [SHOW BACKUP SCHEMAS 'nodelocal://0/show']
+		WHERE object_name = 'kv'`).Scan(&createStmt)
+	require.Contains(t, createStmt, "CREATE TABLE public.kv")
+
+	var files, size int
+	sqlDB.QueryRow(t, `SELECT count(*), sum(size_bytes) FROM [SHOW BACKUP FILES 'nodelocal://0/show']`).Scan(&files, &size)
+	require.Less(t, 0, files)
+	require.Less(t, 0, size)
+}
+
+func TestBackupRestorePrivileges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sqlDB, cleanup := startBackupTestServer(t)
+	defer cleanup()
+
+	sqlDB.Exec(t, `CREATE USER testuser`)
+	sqlDB.Exec(t, `SET ROLE testuser`)
+	sqlDB.ExpectErr(t, `only users with the admin role are allowed to BACKUP using the specified nodelocal URI`,
+		`BACKUP DATABASE data TO 'nodelocal://0/db'`)
+	sqlDB.ExpectErr(t, `only users with the admin role are allowed to RESTORE using the specified nodelocal URI`,
+		`RESTORE DATABASE data FROM 'nodelocal://0/db'`)
+	sqlDB.Exec(t, `RESET ROLE`)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING feature.backup.enabled = false`)
+	sqlDB.ExpectErr(t, `feature BACKUP was disabled by the database administrator`,
+		`BACKUP DATABASE data TO 'userfile:///db'`)
+}
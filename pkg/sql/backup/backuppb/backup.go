@@ -0,0 +1,32 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backuppb
+
+import "github.com/cockroachdb/cockroach/pkg/util/hlc"
+
+// IsIncremental returns whether the manifest describes an incremental layer on
+// top of a previous backup.
+func (m *BackupManifest) IsIncremental() bool {
+	return !m.StartTime.IsEmpty()
+}
+
+// CanRestoreAsOf returns whether this layer can be used to restore the data it
+// holds as of ts. A layer without revision history only holds the state as of
+// its EndTime.
+func (m *BackupManifest) CanRestoreAsOf(ts hlc.Timestamp) bool {
+	if ts == m.EndTime {
+		return true
+	}
+	if !m.RevisionHistory || m.EndTime.Less(ts) {
+		return false
+	}
+	return m.RevisionStartTime.LessEq(ts)
+}
@@ -0,0 +1,309 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/backup/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/ioctx"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// backupManifestName is the name of the file holding the BackupManifest of
+	// a single backup layer.
+	backupManifestName = "BACKUP_MANIFEST"
+	// backupCheckpointName is the name of the file holding the BackupCheckpoint
+	// of an in-progress backup layer.
+	backupCheckpointName = "BACKUP-CHECKPOINT"
+	// latestFileName is the name of the file in a backup collection which holds
+	// the subdirectory of the most recent full backup in that collection.
+	latestFileName = "LATEST"
+	// dataDirName is the directory below a backup layer in which SSTs are
+	// written.
+	dataDirName = "data"
+
+	// dateBasedIntoFolderName is the format used for the subdirectory of a
+	// full backup created with BACKUP INTO.
+	dateBasedIntoFolderName = "/2006/01/02-150405.00"
+	// dateBasedIncFolderName is the format used for the subdirectory, relative
+	// to its full backup, of an incremental backup created with BACKUP INTO
+	// LATEST.
+	dateBasedIncFolderName = "/20060102/150405.00"
+
+	// backupFormatVersion is written to every manifest and bumped whenever the
+	// backup layout changes incompatibly.
+	backupFormatVersion = 1
+
+	// latestKeyword is the magic value which may be passed instead of an
+	// explicit subdirectory to refer to the latest backup in a collection.
+	latestKeyword = "LATEST"
+)
+
+// errBackupNotFound is returned when a destination does not contain a backup.
+var errBackupNotFound = pgerror.New(pgcode.UndefinedFile, "backup manifest not found")
+
+// appendPaths returns uri with each of tails appended to its path.
+func appendPaths(uri string, tails ...string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	for _, tail := range tails {
+		parsed.Path = cloud.JoinPathPreservingTrailingSlash(parsed.Path, tail)
+	}
+	return parsed.String(), nil
+}
+
+// storageFromURI opens the ExternalStorage for the given URI on behalf of user.
+func storageFromURI(
+	ctx context.Context, execCfg *sql.ExecutorConfig, uri string, user security.SQLUsername,
+) (cloud.ExternalStorage, error) {
+	return execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, uri, user)
+}
+
+// readFile reads the named file from store in its entirety.
+func readFile(ctx context.Context, store cloud.ExternalStorage, name string) ([]byte, error) {
+	r, err := store.ReadFile(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close(ctx)
+	return ioctx.ReadAll(ctx, r)
+}
+
+// writeProto marshals msg and writes it to the named file in store.
+func writeProto(
+	ctx context.Context, store cloud.ExternalStorage, name string, msg protoutil.Message,
+) error {
+	buf, err := protoutil.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return cloud.WriteFile(ctx, store, name, bytes.NewReader(buf))
+}
+
+// readManifest reads the BackupManifest stored in store.
+func readManifest(
+	ctx context.Context, store cloud.ExternalStorage,
+) (*backuppb.BackupManifest, error) {
+	buf, err := readFile(ctx, store, backupManifestName)
+	if err != nil {
+		if errors.Is(err, cloud.ErrFileDoesNotExist) {
+			return nil, errBackupNotFound
+		}
+		return nil, errors.Wrapf(err, "reading backup manifest")
+	}
+	var m backuppb.BackupManifest
+	if err := protoutil.Unmarshal(buf, &m); err != nil {
+		return nil, errors.Wrapf(err, "decoding backup manifest")
+	}
+	if m.FormatVersion > backupFormatVersion {
+		return nil, errors.Errorf(
+			"backup was written with format version %d, which is newer than the supported version %d",
+			m.FormatVersion, backupFormatVersion,
+		)
+	}
+	return &m, nil
+}
+
+// readManifestFromURI opens the storage at uri and reads its manifest.
+func readManifestFromURI(
+	ctx context.Context, execCfg *sql.ExecutorConfig, uri string, user security.SQLUsername,
+) (*backuppb.BackupManifest, error) {
+	store, err := storageFromURI(ctx, execCfg, uri, user)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return readManifest(ctx, store)
+}
+
+// containsManifest returns whether store already holds a completed backup.
+func containsManifest(ctx context.Context, store cloud.ExternalStorage) (bool, error) {
+	r, err := store.ReadFile(ctx, backupManifestName)
+	if err != nil {
+		if errors.Is(err, cloud.ErrFileDoesNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	r.Close(ctx)
+	return true, nil
+}
+
+// readLatest returns the subdirectory of the latest full backup in the
+// collection stored in store.
+func readLatest(ctx context.Context, store cloud.ExternalStorage) (string, error) {
+	buf, err := readFile(ctx, store, latestFileName)
+	if err != nil {
+		if errors.Is(err, cloud.ErrFileDoesNotExist) {
+			return "", pgerror.Wrapf(err, pgcode.UndefinedFile,
+				"path does not contain a completed latest backup")
+		}
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// writeLatest records subdir as the latest full backup in the collection
+// stored in store.
+func writeLatest(ctx context.Context, store cloud.ExternalStorage, subdir string) error {
+	return cloud.WriteFile(ctx, store, latestFileName, strings.NewReader(subdir))
+}
+
+// fullBackupSubdir returns the subdirectory within a collection that a full
+// backup started at t is written to.
+func fullBackupSubdir(t time.Time) string {
+	return t.Format(dateBasedIntoFolderName)
+}
+
+// incrementalBackupSubdir returns the subdirectory, relative to its full
+// backup, that an incremental backup started at t is written to.
+func incrementalBackupSubdir(t time.Time) string {
+	return t.Format(dateBasedIncFolderName)
+}
+
+// findIncrementalSubdirs lists the incremental layers stored below the full
+// backup in store, returned as paths relative to the full backup in the order
+// in which they were taken.
+func findIncrementalSubdirs(ctx context.Context, store cloud.ExternalStorage) ([]string, error) {
+	var subdirs []string
+	suffix := "/" + backupManifestName
+	if err := store.List(ctx, "", "", func(name string) error {
+		name = strings.TrimPrefix(name, "/")
+		if !strings.HasSuffix(name, suffix) {
+			return nil
+		}
+		subdirs = append(subdirs, "/"+strings.TrimSuffix(name, suffix))
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "listing incremental backups")
+	}
+	// The date based naming of incremental directories means lexical order is
+	// also chronological order.
+	sort.Strings(subdirs)
+	return subdirs, nil
+}
+
+// listFullBackupSubdirs lists the full backups stored in a collection.
+func listFullBackupSubdirs(ctx context.Context, store cloud.ExternalStorage) ([]string, error) {
+	var subdirs []string
+	suffix := "/" + backupManifestName
+	if err := store.List(ctx, "", "", func(name string) error {
+		name = strings.TrimPrefix(name, "/")
+		if !strings.HasSuffix(name, suffix) {
+			return nil
+		}
+		dir := "/" + strings.TrimSuffix(name, suffix)
+		// Full backups created with BACKUP INTO live exactly at the depth of the
+		// date based folder name; anything deeper is an incremental layer.
+		if strings.Count(dir, "/") != strings.Count(dateBasedIntoFolderName, "/") {
+			return nil
+		}
+		subdirs = append(subdirs, dir)
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "listing backups in collection")
+	}
+	sort.Strings(subdirs)
+	return subdirs, nil
+}
+
+// resolvedBackupChain describes a full backup and the incremental layers taken
+// on top of it, in the order in which they were taken.
+type resolvedBackupChain struct {
+	uris      []string
+	manifests []*backuppb.BackupManifest
+}
+
+// resolveBackupChain resolves the chain of manifests stored at the given full
+// backup URI. Explicitly provided incremental URIs are used as-is; otherwise
+// the incremental layers stored below the full backup are discovered.
+func resolveBackupChain(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user security.SQLUsername,
+	fullURI string,
+	explicitIncrementals []string,
+) (resolvedBackupChain, error) {
+	var chain resolvedBackupChain
+	store, err := storageFromURI(ctx, execCfg, fullURI, user)
+	if err != nil {
+		return chain, err
+	}
+	defer store.Close()
+
+	full, err := readManifest(ctx, store)
+	if err != nil {
+		return chain, errors.Wrapf(err, "reading backup at %s", redactURI(fullURI))
+	}
+	chain.uris = append(chain.uris, fullURI)
+	chain.manifests = append(chain.manifests, full)
+
+	incURIs := explicitIncrementals
+	if incURIs == nil {
+		subdirs, err := findIncrementalSubdirs(ctx, store)
+		if err != nil {
+			return chain, err
+		}
+		for _, subdir := range subdirs {
+			uri, err := appendPaths(fullURI, subdir)
+			if err != nil {
+				return chain, err
+			}
+			incURIs = append(incURIs, uri)
+		}
+	}
+	for _, uri := range incURIs {
+		m, err := readManifestFromURI(ctx, execCfg, uri, user)
+		if err != nil {
+			return chain, errors.Wrapf(err, "reading incremental backup at %s", redactURI(uri))
+		}
+		chain.uris = append(chain.uris, uri)
+		chain.manifests = append(chain.manifests, m)
+	}
+
+	// Check that the layers form a contiguous chain.
+	for i := 1; i < len(chain.manifests); i++ {
+		prev, cur := chain.manifests[i-1], chain.manifests[i]
+		if cur.StartTime != prev.EndTime {
+			return chain, errors.Errorf(
+				"backups are not contiguous: layer %d ends at %s but layer %d starts at %s",
+				i-1, prev.EndTime, i, cur.StartTime,
+			)
+		}
+	}
+	return chain, nil
+}
+
+// redactURI strips any credentials from uri so that it can be included in
+// job descriptions and error messages.
+func redactURI(uri string) string {
+	sanitized, err := cloud.SanitizeExternalStorageURI(uri, nil /* extraParams */)
+	if err != nil {
+		return path.Base(uri)
+	}
+	return sanitized
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/backup/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// keyRewriter rewrites the keys of a table in a backup to the keys of the
+// table it is restored as.
+type keyRewriter struct {
+	codec     keys.SQLCodec
+	oldID     descpb.ID
+	newPrefix roachpb.Key
+}
+
+func makeKeyRewriter(codec keys.SQLCodec, oldID, newID descpb.ID) keyRewriter {
+	return keyRewriter{
+		codec:     codec,
+		oldID:     oldID,
+		newPrefix: codec.TablePrefix(uint32(newID)),
+	}
+}
+
+// rewriteKey returns key with its table prefix replaced by the prefix of the
+// restored table.
+func (kr *keyRewriter) rewriteKey(key roachpb.Key) (roachpb.Key, error) {
+	rest, id, err := kr.codec.DecodeTablePrefix(key)
+	if err != nil {
+		return nil, err
+	}
+	if descpb.ID(id) != kr.oldID {
+		return nil, errors.AssertionFailedf("key %s does not belong to table %d", key, kr.oldID)
+	}
+	newKey := make(roachpb.Key, 0, len(kr.newPrefix)+len(rest))
+	newKey = append(newKey, kr.newPrefix...)
+	return append(newKey, rest...), nil
+}
+
+// restoreLayer is a single layer of the backup chain being restored.
+type restoreLayer struct {
+	store    cloud.ExternalStorage
+	manifest *backuppb.BackupManifest
+}
+
+// layerIterator iterates over the keys within a span of a single backup
+// layer, opening the layer's files one at a time as it advances.
+type layerIterator struct {
+	store cloud.ExternalStorage
+	span  roachpb.Span
+	// files are the remaining files of the layer overlapping span, ordered by
+	// their start key. The files of a layer do not overlap each other.
+	files []backuppb.BackupManifest_File
+	iter  storage.SimpleMVCCIterator
+}
+
+func newLayerIterator(layer restoreLayer, span roachpb.Span) *layerIterator {
+	it := &layerIterator{store: layer.store, span: span}
+	for _, f := range layer.manifest.Files {
+		if f.Span.Overlaps(span) {
+			it.files = append(it.files, f)
+		}
+	}
+	sort.Slice(it.files, func(i, j int) bool {
+		return it.files[i].Span.Key.Compare(it.files[j].Span.Key) < 0
+	})
+	return it
+}
+
+// valid returns whether the iterator is positioned at a key within its span,
+// opening the next file of the layer if the current one is exhausted.
+func (it *layerIterator) valid(ctx context.Context) (bool, error) {
+	for {
+		if it.iter != nil {
+			ok, err := it.iter.Valid()
+			if err != nil {
+				return false, err
+			}
+			if ok && it.iter.UnsafeKey().Key.Compare(it.span.EndKey) < 0 {
+				return true, nil
+			}
+			it.iter.Close()
+			it.iter = nil
+		}
+		if len(it.files) == 0 {
+			return false, nil
+		}
+		f := it.files[0]
+		it.files = it.files[1:]
+		buf, err := readFile(ctx, it.store, f.Path)
+		if err != nil {
+			return false, errors.Wrapf(err, "reading %s", f.Path)
+		}
+		if it.iter, err = storage.NewMemSSTIterator(buf, false /* verify */); err != nil {
+			return false, err
+		}
+		it.iter.SeekGE(storage.MVCCKey{Key: it.span.Key})
+	}
+}
+
+func (it *layerIterator) close() {
+	if it.iter != nil {
+		it.iter.Close()
+		it.iter = nil
+	}
+}
+
+// mvccKeyAdder is implemented by the SSTBatcher the restored data is added to.
+type mvccKeyAdder interface {
+	AddMVCCKey(ctx context.Context, key storage.MVCCKey, value []byte) error
+}
+
+// restoreSpan merges the layers of a backup within span and adds the
+// resulting keys, rewritten by kr, to adder. For every key the newest revision
+// at or below endTime across all layers is restored; keys whose newest
+// revision is a deletion are skipped.
+func restoreSpan(
+	ctx context.Context,
+	layers []restoreLayer,
+	span roachpb.Span,
+	endTime hlc.Timestamp,
+	kr *keyRewriter,
+	adder mvccKeyAdder,
+) error {
+	iters := make([]*layerIterator, len(layers))
+	for i := range layers {
+		iters[i] = newLayerIterator(layers[i], span)
+	}
+	defer func() {
+		for _, it := range iters {
+			it.close()
+		}
+	}()
+
+	var lastKey roachpb.Key
+	for {
+		// Layers are merged in MVCCKey order, which places the revisions of a
+		// key from newest to oldest; the first revision at or below endTime is
+		// the one to restore.
+		var next *layerIterator
+		for _, it := range iters {
+			ok, err := it.valid(ctx)
+			if err != nil {
+				return err
+			}
+			if ok && (next == nil || it.iter.UnsafeKey().Less(next.iter.UnsafeKey())) {
+				next = it
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		key := next.iter.UnsafeKey()
+		if key.Timestamp.LessEq(endTime) && !key.Key.Equal(lastKey) {
+			lastKey = append(lastKey[:0], key.Key...)
+			if value := next.iter.UnsafeValue(); len(value) > 0 {
+				newKey, err := kr.rewriteKey(key.Key)
+				if err != nil {
+					return err
+				}
+				// The value checksum covers the key, so it has to be recomputed.
+				v := roachpb.Value{RawBytes: append([]byte(nil), value...)}
+				v.ClearChecksum()
+				v.InitChecksum(newKey)
+				if err := adder.AddMVCCKey(ctx, storage.MVCCKey{Key: newKey, Timestamp: key.Timestamp}, v.RawBytes); err != nil {
+					return err
+				}
+			}
+		}
+		next.iter.Next()
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/blobs"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/nodelocal"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/backup/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRewriter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	codec := keys.SystemSQLCodec
+	kr := makeKeyRewriter(codec, 52, 107)
+
+	oldKey := encoding.EncodeUvarintAscending(codec.IndexPrefix(52, 1), 5)
+	newKey, err := kr.rewriteKey(oldKey)
+	require.NoError(t, err)
+	require.Equal(t, roachpb.Key(encoding.EncodeUvarintAscending(codec.IndexPrefix(107, 1), 5)), newKey)
+
+	_, err = kr.rewriteKey(codec.IndexPrefix(53, 1))
+	require.Error(t, err)
+}
+
+// mvccKVs is an mvccKeyAdder collecting the keys added to it.
+type mvccKVs []storage.MVCCKeyValue
+
+func (kvs *mvccKVs) AddMVCCKey(_ context.Context, key storage.MVCCKey, value []byte) error {
+	*kvs = append(*kvs, storage.MVCCKeyValue{Key: key, Value: value})
+	return nil
+}
+
+func TestRestoreSpan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	st := cluster.MakeTestingClusterSettings()
+	store, err := nodelocal.TestingMakeLocalStorage(ctx, roachpb.ExternalStorage_LocalFilePath{Path: "/"},
+		st, blobs.TestBlobServiceClient(dir), base.ExternalIODirConfig{})
+	require.NoError(t, err)
+	defer store.Close()
+
+	codec := keys.SystemSQLCodec
+	const oldID, newID = descpb.ID(52), descpb.ID(107)
+	key := func(id descpb.ID, k uint64) roachpb.Key {
+		return encoding.EncodeUvarintAscending(codec.IndexPrefix(uint32(id), 1), k)
+	}
+	ts := func(wall int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wall} }
+	type kv struct {
+		k     uint64
+		ts    int64
+		value string // empty for a deletion
+	}
+	writeLayer := func(name string, kvs []kv) restoreLayer {
+		var f storage.MemFile
+		w := storage.MakeIngestionSSTWriter(ctx, st, &f)
+		defer w.Close()
+		for _, e := range kvs {
+			var raw []byte
+			if e.value != "" {
+				v := roachpb.MakeValueFromString(e.value)
+				v.InitChecksum(key(oldID, e.k))
+				raw = v.RawBytes
+			}
+			require.NoError(t, w.PutMVCC(storage.MVCCKey{Key: key(oldID, e.k), Timestamp: ts(e.ts)}, raw))
+		}
+		require.NoError(t, w.Finish())
+		require.NoError(t, cloud.WriteFile(ctx, store, name, &f))
+		return restoreLayer{
+			store: store,
+			manifest: &backuppb.BackupManifest{Files: []backuppb.BackupManifest_File{{
+				Span: roachpb.Span{Key: key(oldID, 0), EndKey: key(oldID, 100)},
+				Path: name,
+			}}},
+		}
+	}
+
+	layers := []restoreLayer{
+		// The full backup, with revision history.
+		writeLayer("full.sst", []kv{
+			{k: 1, ts: 2, value: "a2"},
+			{k: 1, ts: 1, value: "a1"},
+			{k: 2, ts: 1, value: "b1"},
+			{k: 3, ts: 1, value: "c1"},
+		}),
+		// An incremental backup updating and deleting keys.
+		writeLayer("inc.sst", []kv{
+			{k: 1, ts: 5, value: "a5"},
+			{k: 2, ts: 4},
+			{k: 4, ts: 6, value: "d6"},
+		}),
+	}
+
+	for _, tc := range []struct {
+		endTime  int64
+		expected map[uint64]string
+	}{
+		{endTime: 1, expected: map[uint64]string{1: "a1", 2: "b1", 3: "c1"}},
+		{endTime: 3, expected: map[uint64]string{1: "a2", 2: "b1", 3: "c1"}},
+		{endTime: 4, expected: map[uint64]string{1: "a2", 3: "c1"}},
+		{endTime: 10, expected: map[uint64]string{1: "a5", 3: "c1", 4: "d6"}},
+	} {
+		t.Run(fmt.Sprint(tc.endTime), func(t *testing.T) {
+			kr := makeKeyRewriter(codec, oldID, newID)
+			var added mvccKVs
+			span := roachpb.Span{Key: key(oldID, 0), EndKey: key(oldID, 100)}
+			require.NoError(t, restoreSpan(ctx, layers, span, ts(tc.endTime), &kr, &added))
+
+			actual := make(map[uint64]string)
+			for _, e := range added {
+				prefix := codec.IndexPrefix(uint32(newID), 1)
+				require.True(t, bytes.HasPrefix(e.Key.Key, prefix), "unexpected key %s", e.Key)
+				_, k, err := encoding.DecodeUvarintAscending(e.Key.Key[len(prefix):])
+				require.NoError(t, err)
+				v := roachpb.Value{RawBytes: e.Value}
+				require.NoError(t, v.Verify(e.Key.Key))
+				s, err := v.GetBytes()
+				require.NoError(t, err)
+				actual[k] = string(s)
+			}
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,481 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/bulk"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/ingesting"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+type restoreResumer struct {
+	job      *jobs.Job
+	settings *cluster.Settings
+	res      roachpb.RowCount
+}
+
+var _ jobs.Resumer = &restoreResumer{}
+
+// restoredDescriptors are the descriptors created by a restore, as stored in
+// its job details.
+type restoredDescriptors struct {
+	databases []*dbdesc.Mutable
+	schemas   []*schemadesc.Mutable
+	tables    []*tabledesc.Mutable
+	types     []*typedesc.Mutable
+	functions []*funcdesc.Mutable
+}
+
+func makeRestoredDescriptors(details jobspb.RestoreDetails) restoredDescriptors {
+	var res restoredDescriptors
+	for _, desc := range details.DatabaseDescs {
+		res.databases = append(res.databases, dbdesc.NewBuilder(desc).BuildCreatedMutableDatabase())
+	}
+	for _, desc := range details.SchemaDescs {
+		res.schemas = append(res.schemas, schemadesc.NewBuilder(desc).BuildCreatedMutableSchema())
+	}
+	for _, desc := range details.TableDescs {
+		res.tables = append(res.tables, tabledesc.NewBuilder(desc).BuildCreatedMutableTable())
+	}
+	for _, desc := range details.TypeDescs {
+		res.types = append(res.types, typedesc.NewBuilder(desc).BuildCreatedMutableType())
+	}
+	for _, desc := range details.FunctionDescs {
+		res.functions = append(res.functions, funcdesc.NewBuilder(desc).BuildCreatedMutableFunction())
+	}
+	return res
+}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *restoreResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	details := r.job.Details().(jobspb.RestoreDetails)
+
+	if !details.PrepareCompleted {
+		if err := r.writeDescriptors(ctx, execCfg, p.User(), details); err != nil {
+			return err
+		}
+		details = r.job.Details().(jobspb.RestoreDetails)
+	}
+	if err := r.restoreData(ctx, execCfg, p.User(), details); err != nil {
+		return err
+	}
+	if !details.DescriptorsPublished {
+		if err := r.publishDescriptors(ctx, execCfg, details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDescriptors writes the OFFLINE descriptors of the restored objects.
+func (r *restoreResumer) writeDescriptors(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user security.SQLUsername,
+	details jobspb.RestoreDetails,
+) error {
+	return sql.DescsTxn(ctx, execCfg, func(ctx context.Context, txn *kv.Txn, descsCol *descs.Collection) error {
+		restored := makeRestoredDescriptors(details)
+		if details.DescriptorCoverage == tree.AllDescriptors {
+			if err := dropEmptyDatabases(ctx, txn, descsCol, execCfg.Codec, restored.databases); err != nil {
+				return err
+			}
+		}
+		databases := make([]catalog.DatabaseDescriptor, len(restored.databases))
+		for i, desc := range restored.databases {
+			databases[i] = desc
+		}
+		schemas := make([]catalog.SchemaDescriptor, len(restored.schemas))
+		for i, desc := range restored.schemas {
+			schemas[i] = desc
+		}
+		tables := make([]catalog.TableDescriptor, len(restored.tables))
+		for i, desc := range restored.tables {
+			tables[i] = desc
+		}
+		types := make([]catalog.TypeDescriptor, len(restored.types))
+		for i, desc := range restored.types {
+			types[i] = desc
+		}
+		functions := make([]catalog.FunctionDescriptor, len(restored.functions))
+		for i, desc := range restored.functions {
+			functions[i] = desc
+		}
+		// The privileges of the restored descriptors are always reset, since the
+		// users and roles of the backed up cluster are not restored.
+		if err := ingesting.WriteDescriptors(
+			ctx, execCfg.Codec, txn, user, descsCol, databases, schemas, tables, types, functions,
+			tree.RequestedDescriptors, nil /* extra */, "", /* inheritParentName */
+		); err != nil {
+			return err
+		}
+		details.PrepareCompleted = true
+		return r.job.SetDetails(ctx, txn, details)
+	})
+}
+
+// dropEmptyDatabases removes the existing, empty databases which are replaced
+// by the databases restored by a cluster restore. Whether they are empty was
+// checked during planning, and is checked again here.
+func dropEmptyDatabases(
+	ctx context.Context,
+	txn *kv.Txn,
+	descsCol *descs.Collection,
+	codec keys.SQLCodec,
+	restoring []*dbdesc.Mutable,
+) error {
+	all, err := descsCol.GetAllDescriptors(ctx, txn)
+	if err != nil {
+		return err
+	}
+	current := makeDescriptorIndexFromCatalog(all)
+	b := txn.NewBatch()
+	for _, db := range restoring {
+		existing := current.lookupDatabase(db.GetName())
+		if existing == nil {
+			continue
+		}
+		if !isEmptyDatabase(&current, existing) {
+			return errors.Errorf("database %q already exists", db.GetName())
+		}
+		if err := existing.ForEachNonDroppedSchema(func(id descpb.ID, name string) error {
+			b.Del(catalogkeys.MakeSchemaNameKey(codec, existing.GetID(), name))
+			if id != keys.PublicSchemaID {
+				b.Del(catalogkeys.MakeDescMetadataKey(codec, id))
+				descsCol.AddDeletedDescriptor(id)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if !existing.HasPublicSchemaWithDescriptor() {
+			b.Del(catalogkeys.MakeSchemaNameKey(codec, existing.GetID(), tree.PublicSchema))
+		}
+		b.Del(catalogkeys.EncodeNameKey(codec, existing))
+		b.Del(catalogkeys.MakeDescMetadataKey(codec, existing.GetID()))
+		descsCol.AddDeletedDescriptor(existing.GetID())
+	}
+	return txn.Run(ctx, b)
+}
+
+// restoreSpanEntry is the span of a table in the backup along with the key
+// rewriter for the table it is restored as.
+type restoreSpanEntry struct {
+	span roachpb.Span
+	kr   keyRewriter
+}
+
+// makeRestoreSpans returns the spans of the restored tables in the backup,
+// ordered by key.
+func makeRestoreSpans(codec keys.SQLCodec, details jobspb.RestoreDetails) []restoreSpanEntry {
+	oldIDs := make(map[descpb.ID]descpb.ID, len(details.DescriptorRewrites))
+	for oldID, rw := range details.DescriptorRewrites {
+		oldIDs[rw.ID] = oldID
+	}
+	var entries []restoreSpanEntry
+	for _, tbl := range details.TableDescs {
+		if tbl.IsView() {
+			continue
+		}
+		oldID := oldIDs[tbl.ID]
+		prefix := codec.TablePrefix(uint32(oldID))
+		entries = append(entries, restoreSpanEntry{
+			span: roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()},
+			kr:   makeKeyRewriter(codec, oldID, tbl.ID),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].span.Key.Compare(entries[j].span.Key) < 0
+	})
+	return entries
+}
+
+// restoreData restores the data of the restored tables. Progress is recorded
+// after each table so that a resumed job does not restore it again.
+func (r *restoreResumer) restoreData(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user security.SQLUsername,
+	details jobspb.RestoreDetails,
+) error {
+	layers := make([]restoreLayer, 0, len(details.URIs))
+	defer func() {
+		for _, l := range layers {
+			l.store.Close()
+		}
+	}()
+	for _, uri := range details.URIs {
+		store, err := storageFromURI(ctx, execCfg, uri, user)
+		if err != nil {
+			return err
+		}
+		m, err := readManifest(ctx, store)
+		if err != nil {
+			store.Close()
+			return errors.Wrapf(err, "reading backup at %s", redactURI(uri))
+		}
+		layers = append(layers, restoreLayer{store: store, manifest: m})
+	}
+
+	batcher, err := bulk.MakeSSTBatcher(
+		ctx, "restore", execCfg.DB, execCfg.Settings, hlc.Timestamp{}, /* disallowShadowingBelow */
+		true /* writeAtBatchTs */, false, /* scatterSplitRanges */
+		execCfg.DistSQLSrv.BackupMonitor.MakeBoundAccount(), execCfg.DistSQLSrv.BulkSenderLimiter,
+	)
+	if err != nil {
+		return err
+	}
+	defer batcher.Close(ctx)
+
+	entries := makeRestoreSpans(execCfg.Codec, details)
+	highWater := r.job.Progress().GetRestore().HighWater
+	for i := range entries {
+		entry := &entries[i]
+		if highWater != nil && bytes.Compare(entry.span.EndKey, highWater) <= 0 {
+			continue
+		}
+		if err := restoreSpan(ctx, layers, entry.span, details.EndTime, &entry.kr, batcher); err != nil {
+			return errors.Wrapf(err, "restoring %s", entry.span)
+		}
+		if err := batcher.Flush(ctx); err != nil {
+			return err
+		}
+		highWater = entry.span.EndKey
+		fraction := float32(i+1) / float32(len(entries))
+		if err := r.job.FractionProgressed(ctx, nil, /* txn */
+			func(ctx context.Context, details jobspb.ProgressDetails) float32 {
+				details.(*jobspb.Progress_Restore).Restore.HighWater = highWater
+				return fraction
+			},
+		); err != nil {
+			return err
+		}
+	}
+	r.res = countRows(batcher.GetSummary(), restoredTableDescriptors(details))
+	return nil
+}
+
+func restoredTableDescriptors(details jobspb.RestoreDetails) []descpb.Descriptor {
+	descs := make([]descpb.Descriptor, len(details.TableDescs))
+	for i, tbl := range details.TableDescs {
+		descs[i] = *tabledesc.NewBuilder(tbl).BuildImmutable().DescriptorProto()
+	}
+	return descs
+}
+
+// publishDescriptors makes the restored descriptors public.
+func (r *restoreResumer) publishDescriptors(
+	ctx context.Context, execCfg *sql.ExecutorConfig, details jobspb.RestoreDetails,
+) error {
+	return sql.DescsTxn(ctx, execCfg, func(ctx context.Context, txn *kv.Txn, descsCol *descs.Collection) error {
+		b := txn.NewBatch()
+		for _, id := range restoredDescriptorIDs(details) {
+			desc, err := descsCol.GetMutableDescriptorByID(ctx, txn, id)
+			if err != nil {
+				return err
+			}
+			desc.SetPublic()
+			if err := descsCol.WriteDescToBatch(ctx, false /* kvTrace */, desc, b); err != nil {
+				return err
+			}
+		}
+		if err := txn.Run(ctx, b); err != nil {
+			return err
+		}
+		details.DescriptorsPublished = true
+		return r.job.SetDetails(ctx, txn, details)
+	})
+}
+
+// restoredDescriptorIDs returns the IDs of the descriptors created by the
+// restore.
+func restoredDescriptorIDs(details jobspb.RestoreDetails) []descpb.ID {
+	var ids []descpb.ID
+	for _, desc := range details.DatabaseDescs {
+		ids = append(ids, desc.ID)
+	}
+	for _, desc := range details.SchemaDescs {
+		ids = append(ids, desc.ID)
+	}
+	for _, desc := range details.TableDescs {
+		ids = append(ids, desc.ID)
+	}
+	for _, desc := range details.TypeDescs {
+		ids = append(ids, desc.ID)
+	}
+	for _, desc := range details.FunctionDescs {
+		ids = append(ids, desc.ID)
+	}
+	return ids
+}
+
+// ReportResults implements JobResultsReporter interface.
+func (r *restoreResumer) ReportResults(ctx context.Context, resultsCh chan<- tree.Datums) error {
+	select {
+	case resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(r.job.ID())),
+		tree.NewDString(string(jobs.StatusSucceeded)),
+		tree.NewDFloat(tree.DFloat(1.0)),
+		tree.NewDInt(tree.DInt(r.res.Rows)),
+		tree.NewDInt(tree.DInt(r.res.IndexEntries)),
+		tree.NewDInt(tree.DInt(r.res.DataSize)),
+	}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. It removes the
+// descriptors written by the restore and queues the GC of any data restored
+// so far.
+func (r *restoreResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	details := r.job.Details().(jobspb.RestoreDetails)
+
+	// If the descriptors were never written there is nothing to clean up.
+	if !details.PrepareCompleted {
+		return nil
+	}
+	return sql.DescsTxn(ctx, execCfg, func(ctx context.Context, txn *kv.Txn, descsCol *descs.Collection) error {
+		return r.dropDescriptors(ctx, txn, descsCol, execCfg, details)
+	})
+}
+
+// dropDescriptors removes the descriptors and namespace entries created by
+// the restore.
+func (r *restoreResumer) dropDescriptors(
+	ctx context.Context,
+	txn *kv.Txn,
+	descsCol *descs.Collection,
+	execCfg *sql.ExecutorConfig,
+	details jobspb.RestoreDetails,
+) error {
+	const dropTime = int64(1)
+	codec := execCfg.Codec
+	b := txn.NewBatch()
+
+	tablesToGC := make([]descpb.ID, 0, len(details.TableDescs))
+	toWrite := make([]*tabledesc.Mutable, 0, len(details.TableDescs))
+	for _, tbl := range details.TableDescs {
+		desc, err := descsCol.GetMutableTableVersionByID(ctx, tbl.ID, txn)
+		if err != nil {
+			return err
+		}
+		desc.SetDropped()
+		// The restored data was never visible to users, so it can be cleaned up
+		// as soon as possible.
+		desc.DropTime = dropTime
+		b.Del(catalogkeys.EncodeNameKey(codec, desc))
+		tablesToGC = append(tablesToGC, desc.ID)
+		descsCol.AddDeletedDescriptor(desc.GetID())
+		toWrite = append(toWrite, desc)
+	}
+	for _, desc := range toWrite {
+		if err := descsCol.WriteDescToBatch(ctx, false /* kvTrace */, desc, b); err != nil {
+			return err
+		}
+	}
+
+	// Functions, types, schemas and databases hold no data, so they are removed
+	// directly. Functions have no namespace entry.
+	restored := makeRestoredDescriptors(details)
+	var nameKeys []catalog.NameKey
+	var ids []descpb.ID
+	for _, desc := range restored.functions {
+		ids = append(ids, desc.GetID())
+	}
+	for _, desc := range restored.types {
+		nameKeys = append(nameKeys, desc)
+		ids = append(ids, desc.GetID())
+	}
+	for _, desc := range restored.schemas {
+		nameKeys = append(nameKeys, desc)
+		ids = append(ids, desc.GetID())
+	}
+	for _, desc := range restored.databases {
+		nameKeys = append(nameKeys, desc)
+		ids = append(ids, desc.GetID())
+		if !desc.HasPublicSchemaWithDescriptor() {
+			b.Del(catalogkeys.MakeSchemaNameKey(codec, desc.GetID(), tree.PublicSchema))
+		}
+	}
+	for _, key := range nameKeys {
+		b.Del(catalogkeys.EncodeNameKey(codec, key))
+	}
+	for _, id := range ids {
+		b.Del(catalogkeys.MakeDescMetadataKey(codec, id))
+		descsCol.AddDeletedDescriptor(id)
+	}
+
+	if len(tablesToGC) > 0 {
+		gcDetails := jobspb.SchemaChangeGCDetails{}
+		for _, tableID := range tablesToGC {
+			gcDetails.Tables = append(gcDetails.Tables, jobspb.SchemaChangeGCDetails_DroppedID{
+				ID:       tableID,
+				DropTime: dropTime,
+			})
+		}
+		gcJobRecord := jobs.Record{
+			Description:   fmt.Sprintf("GC for %s", r.job.Payload().Description),
+			Username:      r.job.Payload().UsernameProto.Decode(),
+			DescriptorIDs: tablesToGC,
+			Details:       gcDetails,
+			Progress:      jobspb.SchemaChangeGCProgress{},
+			NonCancelable: true,
+		}
+		if _, err := execCfg.JobRegistry.CreateJobWithTxn(
+			ctx, gcJobRecord, execCfg.JobRegistry.MakeJobID(), txn); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(txn.Run(ctx, b), "rolling back restored descriptors")
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeRestore,
+		func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
+			return &restoreResumer{
+				job:      job,
+				settings: settings,
+			}
+		},
+		jobs.UsesTenantCostControl,
+	)
+}
@@ -0,0 +1,803 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descidgen"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/rewrite"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	restoreOptIntoDB                    = "into_db"
+	restoreOptNewDBName                 = "new_db_name"
+	restoreOptSkipMissingFKs            = "skip_missing_foreign_keys"
+	restoreOptSkipMissingSequences      = "skip_missing_sequences"
+	restoreOptSkipMissingSequenceOwners = "skip_missing_sequence_owners"
+	restoreOptSkipMissingViews          = "skip_missing_views"
+)
+
+// featureRestoreEnabled is used to enable and disable the RESTORE feature.
+var featureRestoreEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"feature.restore.enabled",
+	"set to true to enable restore, false to disable; default is true",
+	featureflag.FeatureFlagEnabledDefault,
+).WithPublic()
+
+// restoreOptions are the evaluated options of a RESTORE statement.
+type restoreOptions struct {
+	intoDB                    string
+	newDBName                 string
+	skipMissingFKs            bool
+	skipMissingSequences      bool
+	skipMissingSequenceOwners bool
+	skipMissingViews          bool
+}
+
+// checkUnsupportedRestoreOptions returns an error if the statement uses any
+// RESTORE option which is not supported by this implementation.
+func checkUnsupportedRestoreOptions(restoreStmt *tree.Restore) error {
+	opts := &restoreStmt.Options
+	if opts.EncryptionPassphrase != nil || opts.DecryptionKMSURI != nil {
+		return pgerror.New(pgcode.FeatureNotSupported, "encrypted backups are not supported")
+	}
+	if opts.IncrementalStorage != nil {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"the incremental_location option is not supported")
+	}
+	if opts.AsTenant != nil || restoreStmt.Targets.TenantID.Specified {
+		return pgerror.New(pgcode.FeatureNotSupported, "restoring tenants is not supported")
+	}
+	if opts.DebugPauseOn != nil {
+		return pgerror.New(pgcode.FeatureNotSupported, "the debug_pause_on option is not supported")
+	}
+	for _, layer := range restoreStmt.From {
+		if len(layer) != 1 {
+			return pgerror.New(pgcode.FeatureNotSupported,
+				"partitioned (locality-aware) backups are not supported")
+		}
+	}
+	if restoreStmt.Subdir != nil && len(restoreStmt.From) != 1 {
+		return pgerror.New(pgcode.InvalidParameterValue,
+			"RESTORE FROM ... IN can only be used with a single collection")
+	}
+	isDatabaseRestore := restoreStmt.Targets.Databases != nil
+	if opts.IntoDB != nil && (isDatabaseRestore || restoreStmt.DescriptorCoverage == tree.AllDescriptors) {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"the %s option can only be used when restoring tables", restoreOptIntoDB)
+	}
+	if opts.NewDBName != nil && (!isDatabaseRestore || len(restoreStmt.Targets.Databases) != 1) {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"the %s option can only be used when restoring a single database", restoreOptNewDBName)
+	}
+	return nil
+}
+
+// trimChainAsOf drops the layers of chain which were taken after asOf, and
+// ensures that the remaining layers can be restored as of asOf.
+func trimChainAsOf(chain resolvedBackupChain, asOf hlc.Timestamp) (resolvedBackupChain, error) {
+	var trimmed resolvedBackupChain
+	for i, m := range chain.manifests {
+		if i > 0 && !m.StartTime.Less(asOf) {
+			break
+		}
+		trimmed.uris = append(trimmed.uris, chain.uris[i])
+		trimmed.manifests = append(trimmed.manifests, m)
+	}
+	if last := trimmed.manifests[len(trimmed.manifests)-1]; !last.CanRestoreAsOf(asOf) {
+		return trimmed, pgerror.Newf(pgcode.InvalidParameterValue,
+			"invalid RESTORE timestamp: restoring to arbitrary time requires that BACKUP for requested time be created with '%s' option",
+			"revision_history")
+	}
+	return trimmed, nil
+}
+
+// checkMissingDependencies ensures that every descriptor referenced by the
+// restored tables is restored as well, unless the corresponding skip_missing_*
+// option was specified. Views referencing missing tables are dropped from
+// targets when skip_missing_views is set.
+func checkMissingDependencies(targets *resolvedTargets, opts restoreOptions) error {
+	restored := make(map[descpb.ID]bool, len(targets.descs))
+	for _, desc := range targets.descs {
+		restored[desc.GetID()] = true
+	}
+
+	// Drop views with missing dependencies until no more are found, since
+	// dropping a view may leave views which depend on it with a missing
+	// dependency.
+	for changed := true; changed; {
+		changed = false
+		for _, desc := range targets.descs {
+			tbl, ok := desc.(catalog.TableDescriptor)
+			if !ok || !tbl.IsView() || !restored[tbl.GetID()] {
+				continue
+			}
+			for _, dep := range tbl.TableDesc().DependsOn {
+				if restored[dep] {
+					continue
+				}
+				if !opts.skipMissingViews {
+					return errors.Errorf(
+						"cannot restore view %q without restoring referenced table %d (or %q option)",
+						tbl.GetName(), dep, restoreOptSkipMissingViews)
+				}
+				restored[tbl.GetID()] = false
+				changed = true
+				break
+			}
+		}
+	}
+	filtered := targets.descs[:0]
+	for _, desc := range targets.descs {
+		if restored[desc.GetID()] {
+			filtered = append(filtered, desc)
+		}
+	}
+	targets.descs = filtered
+
+	for _, desc := range targets.descs {
+		tbl, ok := desc.(catalog.TableDescriptor)
+		if !ok {
+			continue
+		}
+		for _, fk := range tbl.TableDesc().OutboundFKs {
+			if !restored[fk.ReferencedTableID] && !opts.skipMissingFKs {
+				return errors.Errorf(
+					"cannot restore table %q without referenced table %d (or %q option)",
+					tbl.GetName(), fk.ReferencedTableID, restoreOptSkipMissingFKs)
+			}
+		}
+		for i := range tbl.TableDesc().Columns {
+			col := &tbl.TableDesc().Columns[i]
+			for _, seqID := range col.UsesSequenceIds {
+				if !restored[seqID] && !opts.skipMissingSequences {
+					return errors.Errorf(
+						"cannot restore table %q without referenced sequence %d (or %q option)",
+						tbl.GetName(), seqID, restoreOptSkipMissingSequences)
+				}
+			}
+			for _, seqID := range col.OwnsSequenceIds {
+				if !restored[seqID] && !opts.skipMissingSequenceOwners {
+					return errors.Errorf(
+						"cannot restore table %q without referenced sequence %d (or %q option)",
+						tbl.GetName(), seqID, restoreOptSkipMissingSequenceOwners)
+				}
+			}
+		}
+		for _, trigger := range tbl.TableDesc().Triggers {
+			if !restored[trigger.FunctionID] {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"cannot restore table %q without the function %d executed by trigger %q; "+
+						"tables with triggers can only be restored along with their database",
+					tbl.GetName(), trigger.FunctionID, trigger.Name)
+			}
+		}
+		if tbl.IsSequence() && tbl.GetSequenceOpts().HasOwner() {
+			owner := tbl.GetSequenceOpts().SequenceOwner.OwnerTableID
+			if !restored[owner] && !opts.skipMissingSequenceOwners {
+				return errors.Errorf(
+					"cannot restore sequence %q without referenced owner table %d (or %q option)",
+					tbl.GetName(), owner, restoreOptSkipMissingSequenceOwners)
+			}
+		}
+	}
+	return nil
+}
+
+// isEmptyDatabase returns whether db contains no objects other than its
+// public schema.
+func isEmptyDatabase(idx *descriptorIndex, db catalog.DatabaseDescriptor) bool {
+	for _, desc := range idx.objectsInDatabase(db.GetID()) {
+		if sc, ok := desc.(catalog.SchemaDescriptor); ok && sc.GetName() == tree.PublicSchema {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// allocateDescriptorRewrites determines the IDs and parents of the restored
+// descriptors. Databases, and everything in them, are restored with new IDs
+// when they are restored in their entirety. Otherwise the restored tables are
+// placed in existing databases and schemas of the same name, or in the
+// database named by the into_db option.
+//
+// A cluster restore replaces existing databases of the same name if they are
+// empty, such as the default databases of a freshly created cluster.
+func allocateDescriptorRewrites(
+	ctx context.Context,
+	p sql.PlanHookState,
+	current *descriptorIndex,
+	targets resolvedTargets,
+	coverage tree.DescriptorCoverage,
+	opts restoreOptions,
+) (jobspb.DescRewriteMap, error) {
+	execCfg := p.ExecCfg()
+	rewrites := make(jobspb.DescRewriteMap)
+	newID := func() (descpb.ID, error) {
+		return descidgen.GenerateUniqueDescID(ctx, execCfg.DB, execCfg.Codec)
+	}
+
+	if coverage == tree.AllDescriptors || len(targets.requestedDBs) > 0 {
+		for _, desc := range targets.descs {
+			db, ok := desc.(catalog.DatabaseDescriptor)
+			if !ok {
+				continue
+			}
+			name := db.GetName()
+			if opts.newDBName != "" {
+				name = opts.newDBName
+			}
+			if existing := current.lookupDatabase(name); existing != nil {
+				if coverage != tree.AllDescriptors || !isEmptyDatabase(current, existing) {
+					return nil, pgerror.Newf(pgcode.DuplicateDatabase, "database %q already exists", name)
+				}
+			}
+			id, err := newID()
+			if err != nil {
+				return nil, err
+			}
+			rewrites[db.GetID()] = &jobspb.DescriptorRewrite{ID: id, NewDBName: opts.newDBName}
+		}
+		for _, desc := range targets.descs {
+			if _, ok := desc.(catalog.DatabaseDescriptor); ok {
+				continue
+			}
+			id, err := newID()
+			if err != nil {
+				return nil, err
+			}
+			rewrites[desc.GetID()] = &jobspb.DescriptorRewrite{
+				ID:       id,
+				ParentID: rewrites[desc.GetParentID()].ID,
+			}
+		}
+		for _, desc := range targets.descs {
+			if _, ok := desc.(catalog.SchemaDescriptor); ok {
+				continue
+			}
+			if parentSchemaID := desc.GetParentSchemaID(); parentSchemaID != descpb.InvalidID {
+				// Objects in the public schema of databases without a descriptor
+				// backed public schema keep referring to keys.PublicSchemaID.
+				rewrites[desc.GetID()].ParentSchemaID = parentSchemaID
+				if rw, ok := rewrites[parentSchemaID]; ok {
+					rewrites[desc.GetID()].ParentSchemaID = rw.ID
+				}
+			}
+		}
+		return rewrites, nil
+	}
+
+	// Restoring individual tables into existing databases.
+	for _, desc := range targets.descs {
+		switch desc := desc.(type) {
+		case catalog.DatabaseDescriptor:
+			name := desc.GetName()
+			if opts.intoDB != "" {
+				name = opts.intoDB
+			}
+			existing := current.lookupDatabase(name)
+			if existing == nil {
+				return nil, pgerror.Newf(pgcode.UndefinedDatabase,
+					"a database named %q needs to exist to restore tables into it", name)
+			}
+			if err := p.CheckPrivilege(ctx, existing, privilege.CREATE); err != nil {
+				return nil, err
+			}
+			rewrites[desc.GetID()] = &jobspb.DescriptorRewrite{ID: existing.GetID(), ToExisting: true}
+		case catalog.TypeDescriptor:
+			return nil, unimplementedRestoreOfTypes(desc)
+		}
+	}
+	for _, desc := range targets.descs {
+		sc, ok := desc.(catalog.SchemaDescriptor)
+		if !ok {
+			continue
+		}
+		parent := current.byID[rewrites[sc.GetParentID()].ID].(catalog.DatabaseDescriptor)
+		id, ok := current.lookupSchemaID(parent, sc.GetName())
+		if !ok {
+			return nil, sqlerrors.NewUndefinedSchemaError(sc.GetName())
+		}
+		rewrites[sc.GetID()] = &jobspb.DescriptorRewrite{
+			ID: id, ParentID: parent.GetID(), ToExisting: true,
+		}
+	}
+	for _, desc := range targets.descs {
+		tbl, ok := desc.(catalog.TableDescriptor)
+		if !ok {
+			continue
+		}
+		parent := current.byID[rewrites[tbl.GetParentID()].ID].(catalog.DatabaseDescriptor)
+		var schemaID descpb.ID
+		if rw, ok := rewrites[tbl.GetParentSchemaID()]; ok {
+			schemaID = rw.ID
+		} else if schemaID, ok = current.lookupSchemaID(parent, tree.PublicSchema); !ok {
+			return nil, sqlerrors.NewUndefinedSchemaError(tree.PublicSchema)
+		}
+		if current.lookupTable(parent.GetID(), schemaID, tbl.GetName()) != nil {
+			tn := tree.MakeTableNameWithSchema(
+				tree.Name(parent.GetName()),
+				tree.Name(current.schemaName(parent, schemaID)),
+				tree.Name(tbl.GetName()),
+			)
+			return nil, sqlerrors.NewRelationAlreadyExistsError(tn.FQString())
+		}
+		id, err := newID()
+		if err != nil {
+			return nil, err
+		}
+		rewrites[tbl.GetID()] = &jobspb.DescriptorRewrite{
+			ID: id, ParentID: parent.GetID(), ParentSchemaID: schemaID,
+		}
+	}
+	return rewrites, nil
+}
+
+func unimplementedRestoreOfTypes(typ catalog.TypeDescriptor) error {
+	return pgerror.Newf(pgcode.FeatureNotSupported,
+		"restoring tables which reference the user-defined type %q is only supported "+
+			"when restoring the entire database", typ.GetName())
+}
+
+// checkDescriptorsSupported returns an error if the descriptors to restore
+// contain state which cannot be read by nodes running a version before the one
+// which introduced it, until the upgrade to that version is finalized.
+func checkDescriptorsSupported(
+	ctx context.Context, st *cluster.Settings, targets *resolvedTargets,
+) error {
+	for _, desc := range targets.descs {
+		switch desc := desc.(type) {
+		case catalog.FunctionDescriptor:
+			if !st.Version.IsActive(ctx, clusterversion.UserDefinedFunctions) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"cannot restore function %q until upgrade to version %s is finalized",
+					desc.GetName(), clusterversion.UserDefinedFunctions.String())
+			}
+		case catalog.TypeDescriptor:
+			switch desc.GetKind() {
+			case descpb.TypeDescriptor_DOMAIN, descpb.TypeDescriptor_COMPOSITE:
+				if !st.Version.IsActive(ctx, clusterversion.DomainsAndCompositeTypes) {
+					return pgerror.Newf(pgcode.FeatureNotSupported,
+						"cannot restore type %q until upgrade to version %s is finalized",
+						desc.GetName(), clusterversion.DomainsAndCompositeTypes.String())
+				}
+			}
+		case catalog.TableDescriptor:
+			if len(desc.TableDesc().Triggers) > 0 && !st.Version.IsActive(ctx, clusterversion.RowLevelTriggers) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"cannot restore table %q with triggers until upgrade to version %s is finalized",
+					desc.GetName(), clusterversion.RowLevelTriggers.String())
+			}
+			if hasDeferrableConstraints(desc) && !st.Version.IsActive(ctx, clusterversion.DeferrableConstraints) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"cannot restore table %q with deferrable constraints until upgrade to version %s is finalized",
+					desc.GetName(), clusterversion.DeferrableConstraints.String())
+			}
+			if len(desc.TableDesc().ExclusionConstraints) > 0 &&
+				!st.Version.IsActive(ctx, clusterversion.ExclusionConstraints) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"cannot restore table %q with exclusion constraints until upgrade to version %s is finalized",
+					desc.GetName(), clusterversion.ExclusionConstraints.String())
+			}
+		}
+	}
+	return nil
+}
+
+// hasDeferrableConstraints returns whether the table has a DEFERRABLE foreign
+// key or unique constraint.
+func hasDeferrableConstraints(desc catalog.TableDescriptor) bool {
+	for i := range desc.TableDesc().OutboundFKs {
+		if desc.TableDesc().OutboundFKs[i].Deferrable {
+			return true
+		}
+	}
+	for i := range desc.TableDesc().UniqueWithoutIndexConstraints {
+		if desc.TableDesc().UniqueWithoutIndexConstraints[i].Deferrable {
+			return true
+		}
+	}
+	return false
+}
+
+// makeRestoreDetails rewrites the restored descriptors according to rewrites
+// and returns the details of the restore job. The rewritten descriptors are
+// written in the OFFLINE state and only made public once their data has been
+// restored.
+func makeRestoreDetails(
+	targets resolvedTargets,
+	rewrites jobspb.DescRewriteMap,
+	chain resolvedBackupChain,
+	endTime hlc.Timestamp,
+	coverage tree.DescriptorCoverage,
+	opts restoreOptions,
+) (jobspb.RestoreDetails, error) {
+	var databases []*dbdesc.Mutable
+	var schemas []*schemadesc.Mutable
+	var tables []*tabledesc.Mutable
+	var types []*typedesc.Mutable
+	var functions []*funcdesc.Mutable
+	for _, desc := range targets.descs {
+		if rewrites[desc.GetID()].ToExisting {
+			continue
+		}
+		switch desc := desc.(type) {
+		case catalog.DatabaseDescriptor:
+			databases = append(databases, dbdesc.NewBuilder(desc.DatabaseDesc()).BuildCreatedMutableDatabase())
+		case catalog.SchemaDescriptor:
+			schemas = append(schemas, schemadesc.NewBuilder(desc.SchemaDesc()).BuildCreatedMutableSchema())
+		case catalog.TableDescriptor:
+			tables = append(tables, tabledesc.NewBuilder(desc.TableDesc()).BuildCreatedMutableTable())
+		case catalog.TypeDescriptor:
+			types = append(types, typedesc.NewBuilder(desc.TypeDesc()).BuildCreatedMutableType())
+		case catalog.FunctionDescriptor:
+			functions = append(functions, funcdesc.NewBuilder(desc.FuncDesc()).BuildCreatedMutableFunction())
+		}
+	}
+	if err := rewrite.DatabaseDescs(databases, rewrites); err != nil {
+		return jobspb.RestoreDetails{}, err
+	}
+	if err := rewrite.SchemaDescs(schemas, rewrites); err != nil {
+		return jobspb.RestoreDetails{}, err
+	}
+	if err := rewrite.TypeDescs(types, rewrites); err != nil {
+		return jobspb.RestoreDetails{}, err
+	}
+	if err := rewrite.TableDescs(tables, rewrites, opts.intoDB); err != nil {
+		return jobspb.RestoreDetails{}, err
+	}
+	if err := rewrite.FunctionDescs(functions, rewrites); err != nil {
+		return jobspb.RestoreDetails{}, err
+	}
+
+	const offlineReason = "restoring"
+	details := jobspb.RestoreDetails{
+		EndTime:            endTime,
+		DescriptorRewrites: rewrites,
+		URIs:               chain.uris,
+		OverrideDB:         opts.intoDB,
+		DescriptorCoverage: coverage,
+	}
+	for _, desc := range databases {
+		desc.SetOffline(offlineReason)
+		details.DatabaseDescs = append(details.DatabaseDescs, desc.DatabaseDesc())
+	}
+	for _, desc := range schemas {
+		desc.SetOffline(offlineReason)
+		details.SchemaDescs = append(details.SchemaDescs, desc.SchemaDesc())
+	}
+	for _, desc := range tables {
+		desc.SetOffline(offlineReason)
+		details.TableDescs = append(details.TableDescs, desc.TableDesc())
+	}
+	for _, desc := range types {
+		desc.SetOffline(offlineReason)
+		details.TypeDescs = append(details.TypeDescs, desc.TypeDesc())
+	}
+	for _, desc := range functions {
+		desc.SetOffline(offlineReason)
+		details.FunctionDescs = append(details.FunctionDescs, desc.FuncDesc())
+	}
+	return details, nil
+}
+
+// restoreJobDescription returns a description of the restore with any
+// credentials redacted from its URIs.
+func restoreJobDescription(p sql.PlanHookState, orig *tree.Restore, from []string) string {
+	stmt := *orig
+	stmt.From = make([]tree.StringOrPlaceholderOptList, len(from))
+	for i, uri := range from {
+		stmt.From[i] = tree.StringOrPlaceholderOptList{tree.NewDString(redactURI(uri))}
+	}
+	ann := p.ExtendedEvalContext().Annotations
+	return tree.AsStringWithFQNames(&stmt, ann)
+}
+
+func restorePlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	restoreStmt, ok := stmt.(*tree.Restore)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureRestoreEnabled,
+		"RESTORE",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	if err := checkUnsupportedRestoreOptions(restoreStmt); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fromFns := make([]func() (string, error), len(restoreStmt.From))
+	for i := range restoreStmt.From {
+		var err error
+		if fromFns[i], err = p.TypeAsString(ctx, restoreStmt.From[i][0], "RESTORE"); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	var subdirFn, intoDBFn, newDBNameFn func() (string, error)
+	for _, opt := range []struct {
+		expr tree.Expr
+		fn   *func() (string, error)
+	}{
+		{restoreStmt.Subdir, &subdirFn},
+		{restoreStmt.Options.IntoDB, &intoDBFn},
+		{restoreStmt.Options.NewDBName, &newDBNameFn},
+	} {
+		if opt.expr == nil {
+			continue
+		}
+		var err error
+		if *opt.fn, err = p.TypeAsString(ctx, opt.expr, "RESTORE"); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	isDetached := restoreStmt.Options.Detached
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if !(p.ExtendedEvalContext().TxnIsSingleStmt || isDetached) {
+			return errors.Errorf("RESTORE cannot be used inside a multi-statement transaction without DETACHED option")
+		}
+
+		from := make([]string, len(fromFns))
+		for i, fromFn := range fromFns {
+			var err error
+			if from[i], err = fromFn(); err != nil {
+				return err
+			}
+		}
+		opts := restoreOptions{
+			skipMissingFKs:            restoreStmt.Options.SkipMissingFKs,
+			skipMissingSequences:      restoreStmt.Options.SkipMissingSequences,
+			skipMissingSequenceOwners: restoreStmt.Options.SkipMissingSequenceOwners,
+			skipMissingViews:          restoreStmt.Options.SkipMissingViews,
+		}
+		if intoDBFn != nil {
+			var err error
+			if opts.intoDB, err = intoDBFn(); err != nil {
+				return err
+			}
+		}
+		if newDBNameFn != nil {
+			var err error
+			if opts.newDBName, err = newDBNameFn(); err != nil {
+				return err
+			}
+		}
+		if err := checkStorageAccess(ctx, p, "RESTORE", from); err != nil {
+			return err
+		}
+
+		execCfg := p.ExecCfg()
+		var chain resolvedBackupChain
+		if subdirFn != nil {
+			subdir, err := subdirFn()
+			if err != nil {
+				return err
+			}
+			if subdir == latestKeyword {
+				store, err := storageFromURI(ctx, execCfg, from[0], p.User())
+				if err != nil {
+					return err
+				}
+				subdir, err = readLatest(ctx, store)
+				store.Close()
+				if err != nil {
+					return err
+				}
+			}
+			fullURI, err := appendPaths(from[0], subdir)
+			if err != nil {
+				return err
+			}
+			if chain, err = resolveBackupChain(ctx, execCfg, p.User(), fullURI, nil); err != nil {
+				return err
+			}
+		} else {
+			// A single URI may contain incremental layers below it; when
+			// several are given, they are the explicit layers of the chain.
+			var incrementals []string
+			if len(from) > 1 {
+				incrementals = from[1:]
+			}
+			var err error
+			if chain, err = resolveBackupChain(ctx, execCfg, p.User(), from[0], incrementals); err != nil {
+				return err
+			}
+		}
+
+		endTime := chain.manifests[len(chain.manifests)-1].EndTime
+		if restoreStmt.AsOf.Expr != nil {
+			asOf, err := p.EvalAsOfTimestamp(ctx, restoreStmt.AsOf)
+			if err != nil {
+				return err
+			}
+			endTime = asOf.Timestamp
+			if chain, err = trimChainAsOf(chain, endTime); err != nil {
+				return err
+			}
+		}
+		last := chain.manifests[len(chain.manifests)-1]
+
+		backupDescs := make([]catalog.Descriptor, len(last.Descriptors))
+		for i := range last.Descriptors {
+			backupDescs[i] = descbuilder.NewBuilder(&last.Descriptors[i]).BuildImmutable()
+			if endTime.Less(backupDescs[i].GetModificationTime()) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"%q was modified after %s; restoring it as of that time is not supported",
+					backupDescs[i].GetName(), endTime)
+			}
+		}
+		backupIdx := makeDescriptorIndex(backupDescs)
+
+		coverage := restoreStmt.DescriptorCoverage
+		var targets resolvedTargets
+		var err error
+		if coverage == tree.AllDescriptors {
+			if last.DescriptorCoverage != tree.AllDescriptors {
+				return pgerror.New(pgcode.InvalidParameterValue,
+					"full cluster RESTORE can only be used on full cluster BACKUP files")
+			}
+			if err := p.RequireAdminRole(ctx, "RESTORE of the full cluster"); err != nil {
+				return err
+			}
+			targets, err = resolveTargets(&backupIdx, nil /* targets */, "", p.SessionData().SearchPath)
+		} else {
+			targets, err = resolveTargets(&backupIdx, &restoreStmt.Targets,
+				p.SessionData().Database, p.SessionData().SearchPath)
+		}
+		if err != nil {
+			return err
+		}
+		for _, db := range targets.requestedDBs {
+			complete := false
+			for _, id := range last.CompleteDbs {
+				complete = complete || id == db.GetID()
+			}
+			if !complete {
+				return errors.Errorf("database %q does not exist in the backup", db.GetName())
+			}
+		}
+		if len(targets.requestedDBs) > 0 {
+			if ok, err := p.HasRoleOption(ctx, roleoption.CREATEDB); err != nil {
+				return err
+			} else if !ok {
+				return pgerror.Newf(pgcode.InsufficientPrivilege,
+					"only users with the CREATEDB privilege can restore databases")
+			}
+		}
+		if err := checkMissingDependencies(&targets, opts); err != nil {
+			return err
+		}
+		if err := checkDescriptorsSupported(ctx, execCfg.Settings, &targets); err != nil {
+			return err
+		}
+
+		all, err := getAllDescriptorsAt(ctx, execCfg, hlc.Timestamp{})
+		if err != nil {
+			return err
+		}
+		currentIdx := makeDescriptorIndexFromCatalog(all)
+		rewrites, err := allocateDescriptorRewrites(ctx, p, &currentIdx, targets, coverage, opts)
+		if err != nil {
+			return err
+		}
+		details, err := makeRestoreDetails(targets, rewrites, chain, endTime, coverage, opts)
+		if err != nil {
+			return err
+		}
+
+		jr := jobs.Record{
+			Description: restoreJobDescription(p, restoreStmt, from),
+			Username:    p.User(),
+			Details:     details,
+			Progress:    jobspb.RestoreProgress{},
+		}
+		for id, rw := range rewrites {
+			if !rw.ToExisting {
+				jr.DescriptorIDs = append(jr.DescriptorIDs, rewrites[id].ID)
+			}
+		}
+
+		if isDetached {
+			// When running inside an explicit transaction, we simply create the job
+			// record. We do not wait for the job to finish.
+			jobID := p.ExecCfg().JobRegistry.MakeJobID()
+			_, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(
+				ctx, jr, jobID, p.ExtendedEvalContext().Txn)
+			if err != nil {
+				return err
+			}
+			resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+			return nil
+		}
+
+		// We create the job record in the planner's transaction to ensure that
+		// the job record creation happens transactionally.
+		plannerTxn := p.ExtendedEvalContext().Txn
+
+		var sj *jobs.StartableJob
+		if err := func() (err error) {
+			defer func() {
+				if err == nil || sj == nil {
+					return
+				}
+				if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+					log.Errorf(ctx, "failed to cleanup job: %v", cleanupErr)
+				}
+			}()
+			jobID := p.ExecCfg().JobRegistry.MakeJobID()
+			if err := p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(ctx, &sj, jobID, plannerTxn, jr); err != nil {
+				return err
+			}
+			// We commit the transaction here so that the job can be started. This
+			// is safe because we're in an implicit transaction.
+			return plannerTxn.Commit(ctx)
+		}(); err != nil {
+			return err
+		}
+
+		if err := sj.Start(ctx); err != nil {
+			return err
+		}
+		if err := sj.AwaitCompletion(ctx); err != nil {
+			return err
+		}
+		return sj.ReportExecutionResults(ctx, resultsCh)
+	}
+
+	if isDetached {
+		return fn, jobs.DetachedJobExecutionResultHeader, nil, false, nil
+	}
+	return fn, jobs.BulkJobExecutionResultHeader, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook("restore", restorePlanHook)
+}
@@ -0,0 +1,345 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/backup/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+var showBackupsInHeader = colinfo.ResultColumns{
+	{Name: "path", Typ: types.String},
+}
+
+var showBackupHeader = colinfo.ResultColumns{
+	{Name: "database_name", Typ: types.String},
+	{Name: "parent_schema_name", Typ: types.String},
+	{Name: "object_name", Typ: types.String},
+	{Name: "object_type", Typ: types.String},
+	{Name: "backup_type", Typ: types.String},
+	{Name: "start_time", Typ: types.Timestamp},
+	{Name: "end_time", Typ: types.Timestamp},
+	{Name: "size_bytes", Typ: types.Int},
+	{Name: "rows", Typ: types.Int},
+	{Name: "is_full_cluster", Typ: types.Bool},
+}
+
+var showBackupSchemasHeader = append(showBackupHeader[:len(showBackupHeader):len(showBackupHeader)],
+	colinfo.ResultColumn{Name: "create_statement", Typ: types.String},
+)
+
+var showBackupFilesHeader = colinfo.ResultColumns{
+	{Name: "path", Typ: types.String},
+	{Name: "backup_type", Typ: types.String},
+	{Name: "start_pretty", Typ: types.String},
+	{Name: "end_pretty", Typ: types.String},
+	{Name: "size_bytes", Typ: types.Int},
+	{Name: "rows", Typ: types.Int},
+}
+
+var showBackupRangesHeader = colinfo.ResultColumns{
+	{Name: "start_pretty", Typ: types.String},
+	{Name: "end_pretty", Typ: types.String},
+	{Name: "start_key", Typ: types.Bytes},
+	{Name: "end_key", Typ: types.Bytes},
+}
+
+func backupType(m *backuppb.BackupManifest) tree.Datum {
+	if m.IsIncremental() {
+		return tree.NewDString("incremental")
+	}
+	return tree.NewDString("full")
+}
+
+func timestampDatum(ts hlc.Timestamp) tree.Datum {
+	if ts.IsEmpty() {
+		return tree.DNull
+	}
+	return tree.MustMakeDTimestamp(ts.GoTime(), time.Nanosecond)
+}
+
+// tableSummary sums the size and row counts of the files of m which hold
+// data of tbl.
+func tableSummary(
+	m *backuppb.BackupManifest, tbl catalog.TableDescriptor, span roachpb.Span,
+) (size, rows int64) {
+	pkID := roachpb.BulkOpSummaryID(uint64(tbl.GetID()), uint64(tbl.GetPrimaryIndexID()))
+	for _, f := range m.Files {
+		if !span.ContainsKey(f.Span.Key) {
+			continue
+		}
+		size += f.EntryCounts.DataSize
+		rows += f.EntryCounts.EntryCounts[pkID]
+	}
+	return size, rows
+}
+
+// showBackupRows returns the rows of SHOW BACKUP [SCHEMAS] for a backup
+// chain.
+func showBackupRows(
+	ctx context.Context, p sql.PlanHookState, chain resolvedBackupChain, withSchemas bool,
+) ([]tree.Datums, error) {
+	var rows []tree.Datums
+	for _, m := range chain.manifests {
+		descs := make([]catalog.Descriptor, len(m.Descriptors))
+		for i := range m.Descriptors {
+			descs[i] = descbuilder.NewBuilder(&m.Descriptors[i]).BuildImmutable()
+		}
+		idx := makeDescriptorIndex(descs)
+		for _, desc := range idx.ordered {
+			dbName, schemaName := tree.DNull, tree.DNull
+			var db catalog.DatabaseDescriptor
+			if parent, ok := idx.byID[desc.GetParentID()].(catalog.DatabaseDescriptor); ok {
+				db = parent
+				dbName = tree.NewDString(db.GetName())
+			}
+			var objectType string
+			size, numRows := tree.DNull, tree.DNull
+			createStmt := tree.DNull
+			switch desc := desc.(type) {
+			case catalog.DatabaseDescriptor:
+				objectType = "database"
+			case catalog.SchemaDescriptor:
+				objectType = "schema"
+			case catalog.TypeDescriptor:
+				objectType = "type"
+				schemaName = tree.NewDString(idx.schemaName(db, desc.GetParentSchemaID()))
+			case catalog.TableDescriptor:
+				objectType = "table"
+				schemaName = tree.NewDString(idx.schemaName(db, desc.GetParentSchemaID()))
+				s, r := tableSummary(m, desc, desc.TableSpan(p.ExecCfg().Codec))
+				size, numRows = tree.NewDInt(tree.DInt(s)), tree.NewDInt(tree.DInt(r))
+				if withSchemas {
+					var dbPrefix string
+					if db != nil {
+						dbPrefix = db.GetName()
+					}
+					stmt, err := p.ShowCreate(ctx, dbPrefix, m.Descriptors, desc, sql.ShowCreateDisplayOptions{
+						FKDisplayMode:  sql.OmitMissingFKClausesFromCreate,
+						IgnoreComments: true,
+					})
+					if err != nil {
+						return nil, err
+					}
+					createStmt = tree.NewDString(stmt)
+				}
+			default:
+				continue
+			}
+			row := tree.Datums{
+				dbName,
+				schemaName,
+				tree.NewDString(desc.GetName()),
+				tree.NewDString(objectType),
+				backupType(m),
+				timestampDatum(m.StartTime),
+				timestampDatum(m.EndTime),
+				size,
+				numRows,
+				tree.MakeDBool(m.DescriptorCoverage == tree.AllDescriptors),
+			}
+			if withSchemas {
+				row = append(row, createStmt)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// showBackupFilesRows returns the rows of SHOW BACKUP FILES for a backup
+// chain.
+func showBackupFilesRows(chain resolvedBackupChain) []tree.Datums {
+	var rows []tree.Datums
+	for i, m := range chain.manifests {
+		for _, f := range m.Files {
+			var numRows int64
+			for _, count := range f.EntryCounts.EntryCounts {
+				numRows += count
+			}
+			path, err := appendPaths(redactURI(chain.uris[i]), f.Path)
+			if err != nil {
+				path = f.Path
+			}
+			rows = append(rows, tree.Datums{
+				tree.NewDString(path),
+				backupType(m),
+				tree.NewDString(f.Span.Key.String()),
+				tree.NewDString(f.Span.EndKey.String()),
+				tree.NewDInt(tree.DInt(f.EntryCounts.DataSize)),
+				tree.NewDInt(tree.DInt(numRows)),
+			})
+		}
+	}
+	return rows
+}
+
+// showBackupRangesRows returns the rows of SHOW BACKUP RANGES for a backup
+// chain.
+func showBackupRangesRows(chain resolvedBackupChain) []tree.Datums {
+	var rows []tree.Datums
+	for _, m := range chain.manifests {
+		for _, span := range m.Spans {
+			rows = append(rows, tree.Datums{
+				tree.NewDString(span.Key.String()),
+				tree.NewDString(span.EndKey.String()),
+				tree.NewDBytes(tree.DBytes(span.Key)),
+				tree.NewDBytes(tree.DBytes(span.EndKey)),
+			})
+		}
+	}
+	return rows
+}
+
+func showBackupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	backup, ok := stmt.(*tree.ShowBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+	if len(backup.Options) > 0 {
+		return nil, nil, nil, false, pgerror.New(pgcode.FeatureNotSupported,
+			"SHOW BACKUP options are not supported")
+	}
+
+	var collectionFn, pathFn func() (string, error)
+	var err error
+	if backup.InCollection != nil {
+		if collectionFn, err = p.TypeAsString(ctx, backup.InCollection, "SHOW BACKUP"); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	if backup.Path != nil {
+		if pathFn, err = p.TypeAsString(ctx, backup.Path, "SHOW BACKUP"); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	// SHOW BACKUPS IN lists the full backups in a collection.
+	if backup.Path == nil {
+		fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+			collection, err := collectionFn()
+			if err != nil {
+				return err
+			}
+			if err := checkStorageAccess(ctx, p, "SHOW BACKUPS", []string{collection}); err != nil {
+				return err
+			}
+			store, err := storageFromURI(ctx, p.ExecCfg(), collection, p.User())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			subdirs, err := listFullBackupSubdirs(ctx, store)
+			if err != nil {
+				return err
+			}
+			for _, subdir := range subdirs {
+				select {
+				case resultsCh <- tree.Datums{tree.NewDString(subdir)}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		}
+		return fn, showBackupsInHeader, nil, false, nil
+	}
+
+	var header colinfo.ResultColumns
+	switch backup.Details {
+	case tree.BackupRangeDetails:
+		header = showBackupRangesHeader
+	case tree.BackupFileDetails:
+		header = showBackupFilesHeader
+	case tree.BackupSchemaDetails:
+		header = showBackupSchemasHeader
+	default:
+		header = showBackupHeader
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		uri, err := pathFn()
+		if err != nil {
+			return err
+		}
+		if collectionFn != nil {
+			collection, err := collectionFn()
+			if err != nil {
+				return err
+			}
+			if uri == latestKeyword {
+				store, err := storageFromURI(ctx, p.ExecCfg(), collection, p.User())
+				if err != nil {
+					return err
+				}
+				uri, err = readLatest(ctx, store)
+				store.Close()
+				if err != nil {
+					return err
+				}
+			}
+			if uri, err = appendPaths(collection, uri); err != nil {
+				return err
+			}
+		}
+		if err := checkStorageAccess(ctx, p, "SHOW BACKUP", []string{uri}); err != nil {
+			return err
+		}
+		chain, err := resolveBackupChain(ctx, p.ExecCfg(), p.User(), uri, nil)
+		if err != nil {
+			return err
+		}
+
+		var rows []tree.Datums
+		switch backup.Details {
+		case tree.BackupRangeDetails:
+			rows = showBackupRangesRows(chain)
+		case tree.BackupFileDetails:
+			rows = showBackupFilesRows(chain)
+		default:
+			rows, err = showBackupRows(ctx, p, chain, backup.Details == tree.BackupSchemaDetails)
+			if err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			select {
+			case resultsCh <- row:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+	return fn, header, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook("show backup", showBackupPlanHook)
+}
@@ -0,0 +1,338 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package backup
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/nstree"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/errors"
+)
+
+// descriptorIndex provides name based lookups over a fixed set of
+// descriptors, such as all descriptors in the cluster as of the end time of a
+// backup, or all descriptors stored in a backup manifest.
+type descriptorIndex struct {
+	byID map[descpb.ID]catalog.Descriptor
+	// ordered holds all descriptors sorted by ID.
+	ordered []catalog.Descriptor
+}
+
+func makeDescriptorIndex(descs []catalog.Descriptor) descriptorIndex {
+	idx := descriptorIndex{byID: make(map[descpb.ID]catalog.Descriptor, len(descs))}
+	for _, desc := range descs {
+		idx.byID[desc.GetID()] = desc
+		idx.ordered = append(idx.ordered, desc)
+	}
+	sort.Slice(idx.ordered, func(i, j int) bool {
+		return idx.ordered[i].GetID() < idx.ordered[j].GetID()
+	})
+	return idx
+}
+
+func makeDescriptorIndexFromCatalog(c nstree.Catalog) descriptorIndex {
+	var descs []catalog.Descriptor
+	_ = c.ForEachDescriptorEntry(func(desc catalog.Descriptor) error {
+		descs = append(descs, desc)
+		return nil
+	})
+	return makeDescriptorIndex(descs)
+}
+
+// lookupDatabase returns the public database with the given name, if any.
+func (idx *descriptorIndex) lookupDatabase(name string) catalog.DatabaseDescriptor {
+	for _, desc := range idx.ordered {
+		if db, ok := desc.(catalog.DatabaseDescriptor); ok && db.Public() && db.GetName() == name {
+			return db
+		}
+	}
+	return nil
+}
+
+// lookupSchemaID returns the ID of the named schema in db. The public schema
+// of databases which do not have a descriptor-backed public schema resolves to
+// keys.PublicSchemaID.
+func (idx *descriptorIndex) lookupSchemaID(
+	db catalog.DatabaseDescriptor, name string,
+) (descpb.ID, bool) {
+	if id := db.GetSchemaID(name); id != descpb.InvalidID {
+		if sc, ok := idx.byID[id]; ok && !sc.Public() {
+			return descpb.InvalidID, false
+		}
+		return id, true
+	}
+	if name == tree.PublicSchema {
+		return keys.PublicSchemaID, true
+	}
+	return descpb.InvalidID, false
+}
+
+// lookupTable returns the public table with the given name in the given
+// database and schema, if any.
+func (idx *descriptorIndex) lookupTable(
+	dbID, schemaID descpb.ID, name string,
+) catalog.TableDescriptor {
+	for _, desc := range idx.ordered {
+		tbl, ok := desc.(catalog.TableDescriptor)
+		if !ok || !tbl.Public() || tbl.IsTemporary() {
+			continue
+		}
+		if tbl.GetParentID() == dbID && tbl.GetParentSchemaID() == schemaID && tbl.GetName() == name {
+			return tbl
+		}
+	}
+	return nil
+}
+
+// schemaName returns the name of the schema with the given ID in db.
+func (idx *descriptorIndex) schemaName(db catalog.DatabaseDescriptor, id descpb.ID) string {
+	if id == keys.PublicSchemaID {
+		return tree.PublicSchema
+	}
+	if sc, ok := idx.byID[id]; ok {
+		return sc.GetName()
+	}
+	if db != nil {
+		return db.GetNonDroppedSchemaName(id)
+	}
+	return ""
+}
+
+// objectsInDatabase returns the public schemas, types and tables in db.
+func (idx *descriptorIndex) objectsInDatabase(dbID descpb.ID) []catalog.Descriptor {
+	var res []catalog.Descriptor
+	for _, desc := range idx.ordered {
+		if desc.GetParentID() != dbID || !desc.Public() {
+			continue
+		}
+		if tbl, ok := desc.(catalog.TableDescriptor); ok && (tbl.IsTemporary() || tbl.IsVirtualTable()) {
+			continue
+		}
+		if sc, ok := desc.(catalog.SchemaDescriptor); ok && sc.SchemaKind() != catalog.SchemaUserDefined &&
+			sc.GetName() != tree.PublicSchema {
+			continue
+		}
+		res = append(res, desc)
+	}
+	return res
+}
+
+// resolvedTargets are the descriptors selected by the targets of a BACKUP or
+// RESTORE statement.
+type resolvedTargets struct {
+	// descs contains every selected descriptor, along with the databases and
+	// schemas containing selected tables, sorted by ID.
+	descs []catalog.Descriptor
+	// completeDBs are the databases which were selected in their entirety.
+	completeDBs []descpb.ID
+	// requestedDBs are the databases explicitly named by a DATABASE target.
+	requestedDBs []catalog.DatabaseDescriptor
+}
+
+// resolveTargets resolves targets against the descriptors in idx. currentDB
+// and searchPath are used to qualify table names which do not explicitly name
+// a database or schema. A nil targets list selects every user database.
+func resolveTargets(
+	idx *descriptorIndex, targets *tree.TargetList, currentDB string, searchPath sessiondata.SearchPath,
+) (resolvedTargets, error) {
+	var res resolvedTargets
+	selected := make(map[descpb.ID]catalog.Descriptor)
+	addDesc := func(desc catalog.Descriptor) {
+		selected[desc.GetID()] = desc
+	}
+	addDatabase := func(db catalog.DatabaseDescriptor) {
+		addDesc(db)
+		res.completeDBs = append(res.completeDBs, db.GetID())
+		for _, desc := range idx.objectsInDatabase(db.GetID()) {
+			addDesc(desc)
+		}
+	}
+	addTable := func(db catalog.DatabaseDescriptor, tbl catalog.TableDescriptor) error {
+		addDesc(db)
+		if sc, ok := idx.byID[tbl.GetParentSchemaID()]; ok {
+			addDesc(sc)
+		}
+		addDesc(tbl)
+		_, typeIDs, err := tbl.GetAllReferencedTypeIDs(db, func(id descpb.ID) (catalog.TypeDescriptor, error) {
+			desc, ok := idx.byID[id]
+			if !ok {
+				return nil, errors.AssertionFailedf("type %d referenced by %q not found", id, tbl.GetName())
+			}
+			typ, ok := desc.(catalog.TypeDescriptor)
+			if !ok {
+				return nil, errors.AssertionFailedf("descriptor %d referenced by %q is not a type", id, tbl.GetName())
+			}
+			return typ, nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, id := range typeIDs {
+			addDesc(idx.byID[id])
+		}
+		return nil
+	}
+
+	switch {
+	case targets == nil:
+		for _, desc := range idx.ordered {
+			db, ok := desc.(catalog.DatabaseDescriptor)
+			if !ok || !db.Public() || db.GetID() == keys.SystemDatabaseID {
+				continue
+			}
+			addDatabase(db)
+		}
+
+	case targets.Databases != nil:
+		for _, name := range targets.Databases {
+			db := idx.lookupDatabase(string(name))
+			if db == nil {
+				return res, sqlerrors.NewUndefinedDatabaseError(string(name))
+			}
+			if db.GetID() == keys.SystemDatabaseID {
+				return res, pgerror.New(pgcode.FeatureNotSupported,
+					"backing up the system database is not supported")
+			}
+			addDatabase(db)
+			res.requestedDBs = append(res.requestedDBs, db)
+		}
+
+	case targets.Tables != nil:
+		for _, pattern := range targets.Tables {
+			pattern, err := pattern.NormalizeTablePattern()
+			if err != nil {
+				return res, err
+			}
+			switch p := pattern.(type) {
+			case *tree.TableName:
+				db, tbl, err := idx.resolveTableName(p, currentDB, searchPath)
+				if err != nil {
+					return res, err
+				}
+				if err := addTable(db, tbl); err != nil {
+					return res, err
+				}
+			case *tree.AllTablesSelector:
+				db, schemaID, err := idx.resolvePrefix(&p.ObjectNamePrefix, currentDB)
+				if err != nil {
+					return res, err
+				}
+				for _, desc := range idx.objectsInDatabase(db.GetID()) {
+					tbl, ok := desc.(catalog.TableDescriptor)
+					if !ok || (schemaID != descpb.InvalidID && tbl.GetParentSchemaID() != schemaID) {
+						continue
+					}
+					if err := addTable(db, tbl); err != nil {
+						return res, err
+					}
+				}
+			default:
+				return res, errors.AssertionFailedf("unknown table pattern %T", pattern)
+			}
+		}
+
+	default:
+		return res, pgerror.New(pgcode.FeatureNotSupported,
+			"only DATABASE and TABLE targets are supported")
+	}
+
+	for _, desc := range selected {
+		if desc.GetParentID() == keys.SystemDatabaseID || desc.GetID() == keys.SystemDatabaseID {
+			return res, pgerror.Newf(pgcode.FeatureNotSupported,
+				"system table %q cannot be backed up", desc.GetName())
+		}
+		res.descs = append(res.descs, desc)
+	}
+	sort.Slice(res.descs, func(i, j int) bool { return res.descs[i].GetID() < res.descs[j].GetID() })
+	return res, nil
+}
+
+// resolveTableName resolves a possibly partially qualified table name.
+func (idx *descriptorIndex) resolveTableName(
+	tn *tree.TableName, currentDB string, searchPath sessiondata.SearchPath,
+) (catalog.DatabaseDescriptor, catalog.TableDescriptor, error) {
+	tryResolve := func(dbName, schemaName string) (catalog.DatabaseDescriptor, catalog.TableDescriptor) {
+		db := idx.lookupDatabase(dbName)
+		if db == nil {
+			return nil, nil
+		}
+		schemaID, ok := idx.lookupSchemaID(db, schemaName)
+		if !ok {
+			return nil, nil
+		}
+		if tbl := idx.lookupTable(db.GetID(), schemaID, tn.Table()); tbl != nil {
+			return db, tbl
+		}
+		return nil, nil
+	}
+
+	switch {
+	case tn.ExplicitCatalog:
+		if db, tbl := tryResolve(string(tn.CatalogName), string(tn.SchemaName)); tbl != nil {
+			return db, tbl, nil
+		}
+	case tn.ExplicitSchema:
+		// A two-part name may either be schema.table in the current database or
+		// database.table in the public schema of another database.
+		if db, tbl := tryResolve(currentDB, string(tn.SchemaName)); tbl != nil {
+			return db, tbl, nil
+		}
+		if db, tbl := tryResolve(string(tn.SchemaName), tree.PublicSchema); tbl != nil {
+			return db, tbl, nil
+		}
+	default:
+		iter := searchPath.IterWithoutImplicitPGSchemas()
+		for schemaName, ok := iter.Next(); ok; schemaName, ok = iter.Next() {
+			if db, tbl := tryResolve(currentDB, schemaName); tbl != nil {
+				return db, tbl, nil
+			}
+		}
+	}
+	return nil, nil, sqlerrors.NewUndefinedRelationError(tn)
+}
+
+// resolvePrefix resolves the database and, if one was given, the schema of a
+// db.* or db.schema.* target.
+func (idx *descriptorIndex) resolvePrefix(
+	prefix *tree.ObjectNamePrefix, currentDB string,
+) (catalog.DatabaseDescriptor, descpb.ID, error) {
+	dbName := currentDB
+	if prefix.ExplicitCatalog {
+		dbName = string(prefix.CatalogName)
+	}
+	if prefix.ExplicitSchema && !prefix.ExplicitCatalog {
+		// A single qualifier may name either a database or a schema in the current
+		// database; prefer the database like name resolution elsewhere.
+		if db := idx.lookupDatabase(string(prefix.SchemaName)); db != nil {
+			return db, descpb.InvalidID, nil
+		}
+	}
+	db := idx.lookupDatabase(dbName)
+	if db == nil {
+		return nil, descpb.InvalidID, sqlerrors.NewUndefinedDatabaseError(dbName)
+	}
+	if !prefix.ExplicitSchema {
+		return db, descpb.InvalidID, nil
+	}
+	schemaID, ok := idx.lookupSchemaID(db, string(prefix.SchemaName))
+	if !ok {
+		return nil, descpb.InvalidID, sqlerrors.NewUndefinedSchemaError(string(prefix.SchemaName))
+	}
+	return db, schemaID, nil
+}
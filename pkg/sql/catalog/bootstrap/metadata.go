@@ -414,6 +414,7 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 
 	target.AddDescriptorForSystemTenant(systemschema.TenantSettingsTable)
 	target.AddDescriptorForNonSystemTenant(systemschema.SpanCountTable)
+	target.AddDescriptor(systemschema.NotificationsTable)
 
 	// Adding a new system table? It should be added here to the metadata schema,
 	// and also created as a migration for older clusters.
@@ -64,6 +64,7 @@ var (
 		catconstants.SpanConfigurationsTableName,
 		catconstants.TenantSettingsTableName,
 		catconstants.SpanCountTableName,
+		catconstants.NotificationsTableName,
 	}
 
 	// RestoreCopySystemTablePrefix is the prefix of the table name that we give
@@ -108,6 +108,19 @@ func ValidateColumnDefType(t *types.T) error {
 		types.GeographyFamily, types.GeometryFamily, types.EnumFamily, types.Box2DFamily:
 		// These types are OK.
 
+	case types.TupleFamily:
+		// Only user defined composite types may be used for columns, and only
+		// if all of their fields could be.
+		if !t.IsCompositeType() {
+			return pgerror.Newf(pgcode.InvalidTableDefinition,
+				"value type %s cannot be used for table columns", t.String())
+		}
+		for _, elem := range t.TupleContents() {
+			if err := ValidateColumnDefType(elem); err != nil {
+				return err
+			}
+		}
+
 	default:
 		return pgerror.Newf(pgcode.InvalidTableDefinition,
 			"value type %s cannot be used for table columns", t.String())
@@ -68,6 +68,29 @@ var ForeignKeyReferenceActionValue = [...]catpb.ForeignKeyAction{
 	tree.Cascade:    catpb.ForeignKeyAction_CASCADE,
 }
 
+// ConstraintDeferrability returns the deferrability of a constraint given the
+// values of its Deferrable and InitiallyDeferred fields.
+func ConstraintDeferrability(deferrable, initiallyDeferred bool) tree.ConstraintDeferrability {
+	switch {
+	case !deferrable:
+		return tree.NotDeferrableConstraint
+	case initiallyDeferred:
+		return tree.DeferrableInitiallyDeferred
+	default:
+		return tree.DeferrableInitiallyImmediate
+	}
+}
+
+// Deferrability returns the deferrability of the foreign key constraint.
+func (fk *ForeignKeyConstraint) Deferrability() tree.ConstraintDeferrability {
+	return ConstraintDeferrability(fk.Deferrable, fk.InitiallyDeferred)
+}
+
+// Deferrability returns the deferrability of the unique constraint.
+func (u *UniqueWithoutIndexConstraint) Deferrability() tree.ConstraintDeferrability {
+	return ConstraintDeferrability(u.Deferrable, u.InitiallyDeferred)
+}
+
 // ConstraintType is used to identify the type of a constraint.
 type ConstraintType string
 
@@ -80,6 +103,8 @@ const (
 	ConstraintTypeUnique ConstraintType = "UNIQUE"
 	// ConstraintTypeCheck identifies a CHECK constraint.
 	ConstraintTypeCheck ConstraintType = "CHECK"
+	// ConstraintTypeExclusion identifies an EXCLUDE constraint.
+	ConstraintTypeExclusion ConstraintType = "EXCLUDE"
 )
 
 // ConstraintDetail describes a constraint.
@@ -105,4 +130,19 @@ type ConstraintDetail struct {
 
 	// Only populated for Check Constraints.
 	CheckConstraint *TableDescriptor_CheckConstraint
+
+	// Only populated for Exclusion Constraints.
+	ExclusionConstraint *ExclusionConstraint
+}
+
+// Deferrability returns the deferrability of the constraint. Only foreign key
+// constraints and unique constraints without an index can be deferrable.
+func (c ConstraintDetail) Deferrability() tree.ConstraintDeferrability {
+	switch {
+	case c.FK != nil:
+		return c.FK.Deferrability()
+	case c.UniqueWithoutIndexConstraint != nil:
+		return c.UniqueWithoutIndexConstraint.Deferrability()
+	}
+	return tree.NotDeferrableConstraint
 }
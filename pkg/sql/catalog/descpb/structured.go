@@ -360,6 +360,12 @@ func (u *UniqueWithoutIndexConstraint) IsPartial() bool {
 	return u.Predicate != ""
 }
 
+// IsPartial returns true if the constraint only applies to the rows which
+// satisfy its predicate.
+func (c *ExclusionConstraint) IsPartial() bool {
+	return c.Predicate != ""
+}
+
 // GetParentID implements the catalog.NameKeyHaver interface.
 func (ni NameInfo) GetParentID() ID {
 	return ni.ParentID
@@ -45,6 +45,9 @@ const (
 
 	// Schema is for schema descriptors.
 	Schema = "schema"
+
+	// Function is for function descriptors.
+	Function = "function"
 )
 
 // MutationPublicationFilter is used by MakeFirstMutationPublic to filter the
@@ -625,6 +628,19 @@ type TableDescriptor interface {
 	// "inactive" ones queued in the mutations list.
 	AllActiveAndInactiveUniqueWithoutIndexConstraints() []*descpb.UniqueWithoutIndexConstraint
 
+	// GetExclusionConstraints returns the EXCLUDE constraints defined on this
+	// table.
+	GetExclusionConstraints() []descpb.ExclusionConstraint
+	// AllActiveAndInactiveExclusionConstraints returns all EXCLUDE constraints,
+	// including both "active" ones on the table descriptor which are being
+	// enforced for all writes, and "inactive" ones queued in the mutations list.
+	AllActiveAndInactiveExclusionConstraints() []*descpb.ExclusionConstraint
+
+	// GetTriggers returns the row-level triggers defined on this table.
+	GetTriggers() []descpb.TriggerDescriptor
+	// FindTriggerByName returns the trigger with the given name, if any.
+	FindTriggerByName(name string) (*descpb.TriggerDescriptor, bool)
+
 	// ForeachOutboundFK calls f for every outbound foreign key in desc until an
 	// error is returned.
 	ForeachOutboundFK(f func(fk *descpb.ForeignKeyConstraint) error) error
@@ -789,6 +805,40 @@ type TypeDescriptor interface {
 	GetReferencingDescriptorID(refOrdinal int) descpb.ID
 }
 
+// FunctionDescriptor is an interface around the function descriptor types.
+type FunctionDescriptor interface {
+	Descriptor
+	// FuncDesc returns the backing descriptor for this function.
+	FuncDesc() *descpb.FunctionDescriptor
+	// GetArgs returns the input arguments of the function.
+	GetArgs() []descpb.FunctionDescriptor_Argument
+	// GetReturnType returns the return type of the function.
+	GetReturnType() descpb.FunctionDescriptor_ReturnType
+	// GetLang returns the language the function body is written in.
+	GetLang() descpb.FunctionDescriptor_Language
+	// GetFunctionBody returns the body of the function.
+	GetFunctionBody() string
+	// GetVolatility returns the volatility of the function.
+	GetVolatility() descpb.FunctionDescriptor_Volatility
+	// GetLeakProof returns true if the function is leakproof.
+	GetLeakProof() bool
+	// GetNullInputBehavior returns how the function behaves on NULL input.
+	GetNullInputBehavior() descpb.FunctionDescriptor_NullInputBehavior
+	// GetDependsOn returns the IDs of the relations the function depends on.
+	GetDependsOn() []descpb.ID
+	// GetDependsOnTypes returns the IDs of the types the function depends on.
+	GetDependsOnTypes() []descpb.ID
+	// GetDependedOnBy returns the IDs of the tables with triggers which execute
+	// the function.
+	GetDependedOnBy() []descpb.ID
+	// ToFuncObj returns the signature of the function as it is referenced in
+	// statements like DROP FUNCTION.
+	ToFuncObj() tree.FuncObj
+	// ToOverload returns the signature of the function as it is stored in the
+	// function mapping of its parent schema.
+	ToOverload() descpb.SchemaDescriptor_FunctionOverload
+}
+
 // TypeDescriptorResolver is an interface used during hydration of type
 // metadata in types.T's. It is similar to tree.TypeReferenceResolver, except
 // that it has the power to return TypeDescriptor, rather than only a
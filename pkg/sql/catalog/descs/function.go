@@ -0,0 +1,139 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package descs
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// GetImmutableFunctionByID returns an immutable function descriptor with
+// properties according to the provided lookup flags. RequireMutable is ignored.
+// Required is ignored, and an error is always returned if no descriptor with
+// the ID exists.
+func (tc *Collection) GetImmutableFunctionByID(
+	ctx context.Context, txn *kv.Txn, fnID descpb.ID, flags tree.ObjectLookupFlags,
+) (catalog.FunctionDescriptor, error) {
+	flags.RequireMutable = false
+	return tc.getFunctionByID(ctx, txn, fnID, flags)
+}
+
+// GetMutableFunctionByID returns a mutable function descriptor with
+// properties according to the provided lookup flags. RequireMutable is ignored.
+// Required is ignored, and an error is always returned if no descriptor with
+// the ID exists.
+func (tc *Collection) GetMutableFunctionByID(
+	ctx context.Context, txn *kv.Txn, fnID descpb.ID, flags tree.ObjectLookupFlags,
+) (*funcdesc.Mutable, error) {
+	flags.RequireMutable = true
+	desc, err := tc.getFunctionByID(ctx, txn, fnID, flags)
+	if err != nil {
+		return nil, err
+	}
+	mut, ok := desc.(*funcdesc.Mutable)
+	if !ok {
+		return nil, errors.AssertionFailedf(
+			"unhandled function descriptor type %T during GetMutableFunctionByID", desc)
+	}
+	return mut, nil
+}
+
+func (tc *Collection) getFunctionByID(
+	ctx context.Context, txn *kv.Txn, fnID descpb.ID, flags tree.ObjectLookupFlags,
+) (catalog.FunctionDescriptor, error) {
+	descs, err := tc.getDescriptorsByID(ctx, txn, flags.CommonLookupFlags, fnID)
+	if err != nil {
+		if errors.Is(err, catalog.ErrDescriptorNotFound) {
+			return nil, pgerror.Newf(
+				pgcode.UndefinedFunction, "function with ID %d does not exist", fnID)
+		}
+		return nil, err
+	}
+	fn, ok := descs[0].(catalog.FunctionDescriptor)
+	if !ok {
+		return nil, pgerror.Newf(
+			pgcode.UndefinedFunction, "function with ID %d does not exist", fnID)
+	}
+	return tc.hydrateTypesInFunctionDesc(ctx, txn, fn)
+}
+
+// hydrateTypesInFunctionDesc installs user defined type metadata in the
+// argument and return types of the function. Immutable descriptors are copied
+// before hydration since they may be shared. Dropped functions do not get
+// hydrated.
+func (tc *Collection) hydrateTypesInFunctionDesc(
+	ctx context.Context, txn *kv.Txn, desc catalog.FunctionDescriptor,
+) (catalog.FunctionDescriptor, error) {
+	if desc.Dropped() {
+		return desc, nil
+	}
+	needsHydration := func(typ *types.T) bool {
+		return typ != nil && typ.UserDefined() && !typ.IsHydrated()
+	}
+	hydrate := func(fn *descpb.FunctionDescriptor) error {
+		getType := typedesc.TypeLookupFunc(func(
+			ctx context.Context, id descpb.ID,
+		) (tree.TypeName, catalog.TypeDescriptor, error) {
+			desc, err := tc.GetImmutableTypeByID(ctx, txn, id, tree.ObjectLookupFlags{})
+			if err != nil {
+				return tree.TypeName{}, nil, err
+			}
+			_, dbDesc, err := tc.GetImmutableDatabaseByID(ctx, txn, desc.GetParentID(),
+				tree.DatabaseLookupFlags{Required: true})
+			if err != nil {
+				return tree.TypeName{}, nil, err
+			}
+			sc, err := tc.GetImmutableSchemaByID(
+				ctx, txn, desc.GetParentSchemaID(), tree.SchemaLookupFlags{Required: true})
+			if err != nil {
+				return tree.TypeName{}, nil, err
+			}
+			name := tree.MakeQualifiedTypeName(dbDesc.GetName(), sc.GetName(), desc.GetName())
+			return name, desc, nil
+		})
+		for i := range fn.Args {
+			if err := typedesc.EnsureTypeIsHydrated(ctx, fn.Args[i].Type, getType); err != nil {
+				return err
+			}
+		}
+		return typedesc.EnsureTypeIsHydrated(ctx, fn.ReturnType.Type, getType)
+	}
+
+	switch t := desc.(type) {
+	case *funcdesc.Mutable:
+		// It is safe to hydrate directly into Mutable since it is not shared.
+		return desc, hydrate(t.FuncDesc())
+	default:
+		anyUserDefined := needsHydration(desc.GetReturnType().Type)
+		for _, arg := range desc.GetArgs() {
+			anyUserDefined = anyUserDefined || needsHydration(arg.Type)
+		}
+		if !anyUserDefined {
+			return desc, nil
+		}
+		// Make a copy of the underlying descriptor before hydration.
+		mut := desc.NewBuilder().BuildExistingMutable().(*funcdesc.Mutable)
+		if err := hydrate(mut.FuncDesc()); err != nil {
+			return nil, err
+		}
+		return mut.ImmutableCopy().(catalog.FunctionDescriptor), nil
+	}
+}
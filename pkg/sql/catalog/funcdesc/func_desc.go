@@ -0,0 +1,653 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package funcdesc contains the concrete implementations of
+// catalog.FunctionDescriptor.
+package funcdesc
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catprivilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/oidext"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
+	"github.com/lib/pq/oid"
+)
+
+var _ catalog.FunctionDescriptor = (*immutable)(nil)
+var _ catalog.FunctionDescriptor = (*Mutable)(nil)
+var _ catalog.MutableDescriptor = (*Mutable)(nil)
+
+// immutable wraps a Function descriptor and provides methods on it.
+type immutable struct {
+	descpb.FunctionDescriptor
+
+	// isUncommittedVersion is set to true if this descriptor was created from
+	// a copy of a Mutable with an uncommitted version.
+	isUncommittedVersion bool
+
+	// changes represents how the descriptor was changed after
+	// RunPostDeserializationChanges.
+	changes catalog.PostDeserializationChanges
+}
+
+// Mutable is a mutable reference to a FunctionDescriptor.
+type Mutable struct {
+	immutable
+
+	// ClusterVersion represents the version of the function descriptor read
+	// from the store.
+	ClusterVersion *immutable
+}
+
+var _ redact.SafeMessager = (*immutable)(nil)
+
+// NewMutableFunctionDescriptor returns a Mutable for a new function with the
+// given name, parent and signature. The function body and options are left
+// unset and must be filled in by the caller.
+func NewMutableFunctionDescriptor(
+	id descpb.ID,
+	parentID descpb.ID,
+	parentSchemaID descpb.ID,
+	name string,
+	args []descpb.FunctionDescriptor_Argument,
+	returnType descpb.FunctionDescriptor_ReturnType,
+	privs *catpb.PrivilegeDescriptor,
+) Mutable {
+	return Mutable{
+		immutable: immutable{
+			FunctionDescriptor: descpb.FunctionDescriptor{
+				Name:           name,
+				ID:             id,
+				ParentID:       parentID,
+				ParentSchemaID: parentSchemaID,
+				Args:           args,
+				ReturnType:     returnType,
+				Lang:           descpb.FunctionDescriptor_SQL,
+				Volatility:     descpb.FunctionDescriptor_VOLATILE,
+				Privileges:     privs,
+				Version:        1,
+			},
+		},
+	}
+}
+
+// SafeMessage makes immutable a SafeMessager.
+func (desc *immutable) SafeMessage() string {
+	return formatSafeMessage("funcdesc.immutable", desc)
+}
+
+// SafeMessage makes Mutable a SafeMessager.
+func (desc *Mutable) SafeMessage() string {
+	return formatSafeMessage("funcdesc.Mutable", desc)
+}
+
+func formatSafeMessage(typeName string, desc catalog.FunctionDescriptor) string {
+	var buf redact.StringBuilder
+	buf.Printf(typeName + ": {")
+	catalog.FormatSafeDescriptorProperties(&buf, desc)
+	buf.Printf("}")
+	return buf.String()
+}
+
+// GetDrainingNames implements the Descriptor interface. Functions are not
+// present in the namespace table and so never have draining names.
+func (desc *immutable) GetDrainingNames() []descpb.NameInfo {
+	return nil
+}
+
+// IsUncommittedVersion implements the Descriptor interface.
+func (desc *immutable) IsUncommittedVersion() bool {
+	return desc.isUncommittedVersion
+}
+
+// GetAuditMode implements the DescriptorProto interface.
+func (desc *immutable) GetAuditMode() descpb.TableDescriptor_AuditMode {
+	return descpb.TableDescriptor_DISABLED
+}
+
+// DescriptorType implements the DescriptorProto interface.
+func (desc *immutable) DescriptorType() catalog.DescriptorType {
+	return catalog.Function
+}
+
+// FuncDesc implements the FunctionDescriptor interface.
+func (desc *immutable) FuncDesc() *descpb.FunctionDescriptor {
+	return &desc.FunctionDescriptor
+}
+
+// Public implements the Descriptor interface.
+func (desc *immutable) Public() bool {
+	return desc.State == descpb.DescriptorState_PUBLIC
+}
+
+// Adding implements the Descriptor interface.
+func (desc *immutable) Adding() bool {
+	return false
+}
+
+// Offline implements the Descriptor interface.
+func (desc *immutable) Offline() bool {
+	return desc.State == descpb.DescriptorState_OFFLINE
+}
+
+// Dropped implements the Descriptor interface.
+func (desc *immutable) Dropped() bool {
+	return desc.State == descpb.DescriptorState_DROP
+}
+
+// DescriptorProto wraps a FunctionDescriptor in a Descriptor.
+func (desc *immutable) DescriptorProto() *descpb.Descriptor {
+	return &descpb.Descriptor{
+		Union: &descpb.Descriptor_Function{
+			Function: &desc.FunctionDescriptor,
+		},
+	}
+}
+
+// ByteSize implements the Descriptor interface.
+func (desc *immutable) ByteSize() int64 {
+	return int64(desc.Size())
+}
+
+// NewBuilder implements the catalog.Descriptor interface.
+//
+// It overrides the wrapper's implementation to deal with the fact that
+// mutable has overridden the definition of IsUncommittedVersion.
+func (desc *Mutable) NewBuilder() catalog.DescriptorBuilder {
+	return newBuilder(desc.FuncDesc(), desc.IsUncommittedVersion(), desc.changes)
+}
+
+// NewBuilder implements the catalog.Descriptor interface.
+func (desc *immutable) NewBuilder() catalog.DescriptorBuilder {
+	return newBuilder(desc.FuncDesc(), desc.IsUncommittedVersion(), desc.changes)
+}
+
+// GetReferencedDescIDs returns the IDs of all descriptors referenced by
+// this descriptor, including itself.
+func (desc *immutable) GetReferencedDescIDs() (catalog.DescriptorIDSet, error) {
+	ret := catalog.MakeDescriptorIDSet(desc.GetID(), desc.GetParentID(), desc.GetParentSchemaID())
+	for _, id := range desc.DependsOn {
+		ret.Add(id)
+	}
+	for _, id := range desc.DependsOnTypes {
+		ret.Add(id)
+	}
+	for _, id := range desc.DependedOnBy {
+		ret.Add(id)
+	}
+	return ret, nil
+}
+
+// ValidateSelf implements the catalog.Descriptor interface.
+func (desc *immutable) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
+	vea.Report(catalog.ValidateName(desc.GetName(), "function"))
+	if desc.GetID() == descpb.InvalidID {
+		vea.Report(errors.AssertionFailedf("invalid ID %d", desc.GetID()))
+	}
+	if desc.GetParentID() == descpb.InvalidID {
+		vea.Report(errors.AssertionFailedf("invalid parentID %d", desc.GetParentID()))
+	}
+	if desc.GetParentSchemaID() == descpb.InvalidID {
+		vea.Report(errors.AssertionFailedf("invalid parentSchemaID %d", desc.GetParentSchemaID()))
+	}
+
+	// Validate the privilege descriptor.
+	if desc.Privileges == nil {
+		vea.Report(errors.AssertionFailedf("privileges not set"))
+	} else {
+		vea.Report(catprivilege.Validate(*desc.Privileges, desc, privilege.Function))
+	}
+
+	// Validate the signature.
+	if desc.ReturnType.Type == nil {
+		vea.Report(errors.AssertionFailedf("return type not set"))
+	}
+	for i, arg := range desc.Args {
+		if arg.Type == nil {
+			vea.Report(errors.AssertionFailedf("type not set for arg %d", i))
+		}
+	}
+
+	if desc.LeakProof && desc.Volatility != descpb.FunctionDescriptor_IMMUTABLE {
+		vea.Report(errors.AssertionFailedf(
+			"leakproof is set for non-immutable function"))
+	}
+
+	for i, id := range desc.DependsOn {
+		if id == descpb.InvalidID {
+			vea.Report(errors.AssertionFailedf("invalid relation id %d in depends-on references #%d", id, i))
+		}
+	}
+	for i, id := range desc.DependsOnTypes {
+		if id == descpb.InvalidID {
+			vea.Report(errors.AssertionFailedf("invalid type id %d in depends-on-types references #%d", id, i))
+		}
+	}
+	for i, id := range desc.DependedOnBy {
+		if id == descpb.InvalidID {
+			vea.Report(errors.AssertionFailedf("invalid relation id %d in depended-on-by references #%d", id, i))
+		}
+	}
+}
+
+// ValidateCrossReferences implements the catalog.Descriptor interface.
+func (desc *immutable) ValidateCrossReferences(
+	vea catalog.ValidationErrorAccumulator, vdg catalog.ValidationDescGetter,
+) {
+	// Validate that parent database exists.
+	dbDesc, err := vdg.GetDatabaseDescriptor(desc.GetParentID())
+	if err != nil {
+		vea.Report(err)
+	} else if dbDesc.Dropped() && !desc.Dropped() {
+		vea.Report(errors.AssertionFailedf("parent database %q (%d) is dropped",
+			dbDesc.GetName(), dbDesc.GetID()))
+	}
+
+	// Validate that parent schema exists and that it references this function
+	// unless the function is being dropped.
+	scDesc, err := vdg.GetSchemaDescriptor(desc.GetParentSchemaID())
+	if err != nil {
+		vea.Report(err)
+	} else if !desc.Dropped() {
+		if scDesc.GetParentID() != desc.GetParentID() {
+			vea.Report(errors.AssertionFailedf("parent schema %d is in different database %d",
+				desc.GetParentSchemaID(), scDesc.GetParentID()))
+		}
+		vea.Report(desc.validateInSchemaFunctionMapping(scDesc))
+	}
+
+	if desc.Dropped() {
+		return
+	}
+
+	for _, id := range desc.DependsOn {
+		vea.Report(desc.validateOutboundTableRef(id, vdg))
+	}
+	for _, id := range desc.DependsOnTypes {
+		vea.Report(desc.validateOutboundTypeRef(id, vdg))
+	}
+	for _, id := range desc.DependedOnBy {
+		vea.Report(desc.validateInboundTableRef(id, vdg))
+	}
+}
+
+func (desc *immutable) validateInSchemaFunctionMapping(scDesc catalog.SchemaDescriptor) error {
+	fn, ok := scDesc.GetFunction(desc.GetName())
+	if ok {
+		for _, o := range fn.Overloads {
+			if o.ID == desc.GetID() {
+				return nil
+			}
+		}
+	}
+	return errors.AssertionFailedf("function does not exist in schema %q (%d)",
+		scDesc.GetName(), scDesc.GetID())
+}
+
+func (desc *immutable) validateOutboundTableRef(id descpb.ID, vdg catalog.ValidationDescGetter) error {
+	referencedTable, err := vdg.GetTableDescriptor(id)
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "invalid depends-on relation back reference")
+	}
+	if referencedTable.Dropped() {
+		return errors.AssertionFailedf("depends-on relation %q (%d) is dropped",
+			referencedTable.GetName(), referencedTable.GetID())
+	}
+	for _, by := range referencedTable.TableDesc().DependedOnBy {
+		if by.ID == desc.GetID() {
+			return nil
+		}
+	}
+	return errors.AssertionFailedf("depends-on relation %q (%d) has no corresponding depended-on-by back reference",
+		referencedTable.GetName(), referencedTable.GetID())
+}
+
+func (desc *immutable) validateOutboundTypeRef(id descpb.ID, vdg catalog.ValidationDescGetter) error {
+	typ, err := vdg.GetTypeDescriptor(id)
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "invalid depends-on type back reference")
+	}
+	if typ.Dropped() {
+		return errors.AssertionFailedf("depends-on type %q (%d) is dropped",
+			typ.GetName(), typ.GetID())
+	}
+	for _, refID := range typ.TypeDesc().ReferencingDescriptorIDs {
+		if refID == desc.GetID() {
+			return nil
+		}
+	}
+	return errors.AssertionFailedf("depends-on type %q (%d) has no corresponding referencing-descriptor back references",
+		typ.GetName(), typ.GetID())
+}
+
+func (desc *immutable) validateInboundTableRef(id descpb.ID, vdg catalog.ValidationDescGetter) error {
+	tbl, err := vdg.GetTableDescriptor(id)
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "invalid depended-on-by relation back reference")
+	}
+	if tbl.Dropped() {
+		return errors.AssertionFailedf("depended-on-by relation %q (%d) is dropped",
+			tbl.GetName(), tbl.GetID())
+	}
+	for _, t := range tbl.GetTriggers() {
+		if t.FunctionID == desc.GetID() {
+			return nil
+		}
+	}
+	return errors.AssertionFailedf("depended-on-by relation %q (%d) has no trigger executing the function",
+		tbl.GetName(), tbl.GetID())
+}
+
+// ValidateTxnCommit implements the catalog.Descriptor interface.
+func (desc *immutable) ValidateTxnCommit(
+	_ catalog.ValidationErrorAccumulator, _ catalog.ValidationDescGetter,
+) {
+	// No-op.
+}
+
+// GetPostDeserializationChanges implements the Descriptor interface.
+func (desc *immutable) GetPostDeserializationChanges() catalog.PostDeserializationChanges {
+	return desc.changes
+}
+
+// HasConcurrentSchemaChanges implements catalog.Descriptor.
+func (desc *immutable) HasConcurrentSchemaChanges() bool {
+	return desc.DeclarativeSchemaChangerState != nil &&
+		desc.DeclarativeSchemaChangerState.JobID != catpb.InvalidJobID
+}
+
+// ToFuncObj implements the FunctionDescriptor interface.
+func (desc *immutable) ToFuncObj() tree.FuncObj {
+	ret := tree.FuncObj{
+		FuncName: tree.MakeFunctionNameFromPrefix(tree.ObjectNamePrefix{}, tree.Name(desc.Name)),
+		Args:     make(tree.FuncArgs, len(desc.Args)),
+	}
+	for i := range desc.Args {
+		ret.Args[i] = tree.FuncArg{
+			Name: tree.Name(desc.Args[i].Name),
+			Type: desc.Args[i].Type,
+		}
+	}
+	return ret
+}
+
+// ToOverload implements the FunctionDescriptor interface.
+func (desc *immutable) ToOverload() descpb.SchemaDescriptor_FunctionOverload {
+	ret := descpb.SchemaDescriptor_FunctionOverload{
+		ID:         desc.GetID(),
+		ReturnType: desc.ReturnType.Type,
+		ReturnSet:  desc.ReturnType.ReturnSet,
+	}
+	for _, arg := range desc.Args {
+		ret.ArgTypes = append(ret.ArgTypes, arg.Type)
+	}
+	return ret
+}
+
+// MaybeIncrementVersion implements the MutableDescriptor interface.
+func (desc *Mutable) MaybeIncrementVersion() {
+	// Already incremented, no-op.
+	if desc.ClusterVersion == nil || desc.Version == desc.ClusterVersion.Version+1 {
+		return
+	}
+	desc.Version++
+	desc.ModificationTime = hlc.Timestamp{}
+}
+
+// SetDrainingNames implements the MutableDescriptor interface.
+//
+// Deprecated: Do not use.
+func (desc *Mutable) SetDrainingNames(_ []descpb.NameInfo) {}
+
+// AddDrainingName implements the MutableDescriptor interface.
+//
+// Deprecated: Do not use.
+func (desc *Mutable) AddDrainingName(_ descpb.NameInfo) {}
+
+// OriginalName implements the MutableDescriptor interface.
+func (desc *Mutable) OriginalName() string {
+	if desc.ClusterVersion == nil {
+		return ""
+	}
+	return desc.ClusterVersion.Name
+}
+
+// OriginalID implements the MutableDescriptor interface.
+func (desc *Mutable) OriginalID() descpb.ID {
+	if desc.ClusterVersion == nil {
+		return descpb.InvalidID
+	}
+	return desc.ClusterVersion.ID
+}
+
+// OriginalVersion implements the MutableDescriptor interface.
+func (desc *Mutable) OriginalVersion() descpb.DescriptorVersion {
+	if desc.ClusterVersion == nil {
+		return 0
+	}
+	return desc.ClusterVersion.Version
+}
+
+// ImmutableCopy implements the MutableDescriptor interface.
+func (desc *Mutable) ImmutableCopy() catalog.Descriptor {
+	return desc.NewBuilder().BuildImmutable()
+}
+
+// IsNew implements the MutableDescriptor interface.
+func (desc *Mutable) IsNew() bool {
+	return desc.ClusterVersion == nil
+}
+
+// SetPublic implements the MutableDescriptor interface.
+func (desc *Mutable) SetPublic() {
+	desc.State = descpb.DescriptorState_PUBLIC
+	desc.OfflineReason = ""
+}
+
+// SetDropped implements the MutableDescriptor interface.
+func (desc *Mutable) SetDropped() {
+	desc.State = descpb.DescriptorState_DROP
+	desc.OfflineReason = ""
+}
+
+// SetOffline implements the MutableDescriptor interface.
+func (desc *Mutable) SetOffline(reason string) {
+	desc.State = descpb.DescriptorState_OFFLINE
+	desc.OfflineReason = reason
+}
+
+// IsUncommittedVersion implements the Descriptor interface.
+func (desc *Mutable) IsUncommittedVersion() bool {
+	return desc.IsNew() || desc.GetVersion() != desc.ClusterVersion.GetVersion()
+}
+
+// SetDeclarativeSchemaChangerState is part of the catalog.MutableDescriptor
+// interface.
+func (desc *Mutable) SetDeclarativeSchemaChangerState(state *scpb.DescriptorState) {
+	desc.DeclarativeSchemaChangerState = state
+}
+
+// SetName sets the name of the function.
+func (desc *Mutable) SetName(name string) {
+	desc.Name = name
+}
+
+// SetParentSchemaID sets the parent schema ID of the function.
+func (desc *Mutable) SetParentSchemaID(id descpb.ID) {
+	desc.ParentSchemaID = id
+}
+
+// SetFuncBody sets the function body.
+func (desc *Mutable) SetFuncBody(body string) {
+	desc.FunctionBody = body
+}
+
+// SetVolatility sets the volatility attribute.
+func (desc *Mutable) SetVolatility(v descpb.FunctionDescriptor_Volatility) {
+	desc.Volatility = v
+}
+
+// SetLeakProof sets the leakproof attribute.
+func (desc *Mutable) SetLeakProof(v bool) {
+	desc.LeakProof = v
+}
+
+// SetNullInputBehavior sets the NullInputBehavior attribute.
+func (desc *Mutable) SetNullInputBehavior(v descpb.FunctionDescriptor_NullInputBehavior) {
+	desc.NullInputBehavior = v
+}
+
+// SetLang sets the function language.
+func (desc *Mutable) SetLang(v descpb.FunctionDescriptor_Language) {
+	desc.Lang = v
+}
+
+// SetDependsOn replaces the IDs of the relations the function depends on.
+func (desc *Mutable) SetDependsOn(ids []descpb.ID) {
+	desc.DependsOn = ids
+}
+
+// SetDependsOnTypes replaces the IDs of the types the function depends on.
+func (desc *Mutable) SetDependsOnTypes(ids []descpb.ID) {
+	desc.DependsOnTypes = ids
+}
+
+// RemoveDependsOn removes the given relation ID from the function's
+// depends-on references.
+func (desc *Mutable) RemoveDependsOn(id descpb.ID) {
+	desc.DependsOn = removeID(desc.DependsOn, id)
+}
+
+// RemoveDependsOnType removes the given type ID from the function's
+// depends-on-types references.
+func (desc *Mutable) RemoveDependsOnType(id descpb.ID) {
+	desc.DependsOnTypes = removeID(desc.DependsOnTypes, id)
+}
+
+// AddDependedOnBy adds the given table ID to the function's depended-on-by
+// references, unless it is already present.
+func (desc *Mutable) AddDependedOnBy(id descpb.ID) {
+	for _, i := range desc.DependedOnBy {
+		if i == id {
+			return
+		}
+	}
+	desc.DependedOnBy = append(desc.DependedOnBy, id)
+}
+
+// RemoveDependedOnBy removes the given table ID from the function's
+// depended-on-by references.
+func (desc *Mutable) RemoveDependedOnBy(id descpb.ID) {
+	desc.DependedOnBy = removeID(desc.DependedOnBy, id)
+}
+
+func removeID(ids []descpb.ID, id descpb.ID) []descpb.ID {
+	ret := ids[:0]
+	for _, i := range ids {
+		if i != id {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// FuncIDToOID converts a function descriptor ID into a function OID. The
+// scheme is the same as for user-defined types: the ID is offset by
+// oidext.CockroachPredefinedOIDMax so that it never collides with the OIDs of
+// builtin functions.
+func FuncIDToOID(id descpb.ID) oid.Oid {
+	return oid.Oid(id) + oidext.CockroachPredefinedOIDMax
+}
+
+// UserDefinedFunctionOIDToID converts a user-defined function OID into a
+// function descriptor ID. An error is returned if the OID is not in the range
+// of user-defined OIDs.
+func UserDefinedFunctionOIDToID(oid oid.Oid) (descpb.ID, error) {
+	if !IsOIDUserDefinedFunc(oid) {
+		return 0, pgerror.Newf(pgcode.Internal,
+			"user-defined function OID %d should be greater than predefined max %d",
+			oid, oidext.CockroachPredefinedOIDMax)
+	}
+	return descpb.ID(oid) - oidext.CockroachPredefinedOIDMax, nil
+}
+
+// IsOIDUserDefinedFunc returns true if the OID refers to a user-defined
+// function.
+func IsOIDUserDefinedFunc(oid oid.Oid) bool {
+	return descpb.ID(oid) > oidext.CockroachPredefinedOIDMax
+}
+
+// VolatilityToProto converts the volatility of a CREATE FUNCTION statement
+// into its descriptor representation.
+func VolatilityToProto(v tree.FunctionVolatility) (descpb.FunctionDescriptor_Volatility, error) {
+	switch v {
+	case tree.FunctionImmutable:
+		return descpb.FunctionDescriptor_IMMUTABLE, nil
+	case tree.FunctionStable:
+		return descpb.FunctionDescriptor_STABLE, nil
+	case tree.FunctionVolatile:
+		return descpb.FunctionDescriptor_VOLATILE, nil
+	}
+	return -1, pgerror.Newf(pgcode.InvalidParameterValue, "unknown volatility")
+}
+
+// NullInputBehaviorToProto converts the null input behavior of a CREATE
+// FUNCTION statement into its descriptor representation.
+func NullInputBehaviorToProto(
+	v tree.FunctionNullInputBehavior,
+) (descpb.FunctionDescriptor_NullInputBehavior, error) {
+	switch v {
+	case tree.FunctionCalledOnNullInput:
+		return descpb.FunctionDescriptor_CALLED_ON_NULL_INPUT, nil
+	case tree.FunctionReturnsNullOnNullInput:
+		return descpb.FunctionDescriptor_RETURNS_NULL_ON_NULL_INPUT, nil
+	case tree.FunctionStrict:
+		return descpb.FunctionDescriptor_STRICT, nil
+	}
+	return -1, pgerror.Newf(pgcode.InvalidParameterValue, "unknown null input behavior")
+}
+
+// FunctionLangToProto converts the language of a CREATE FUNCTION statement
+// into its descriptor representation.
+func FunctionLangToProto(v tree.FunctionLanguage) (descpb.FunctionDescriptor_Language, error) {
+	switch v {
+	case tree.FunctionLangSQL:
+		return descpb.FunctionDescriptor_SQL, nil
+	}
+	return -1, pgerror.Newf(pgcode.UndefinedObject, "unknown function language")
+}
+
+// VolatilityFromProto converts the volatility of a function descriptor into
+// the volatility used for overload resolution and optimization.
+func VolatilityFromProto(
+	v descpb.FunctionDescriptor_Volatility, leakProof bool,
+) (tree.Volatility, error) {
+	switch v {
+	case descpb.FunctionDescriptor_IMMUTABLE:
+		if leakProof {
+			return tree.VolatilityLeakProof, nil
+		}
+		return tree.VolatilityImmutable, nil
+	case descpb.FunctionDescriptor_STABLE:
+		return tree.VolatilityStable, nil
+	case descpb.FunctionDescriptor_VOLATILE:
+		return tree.VolatilityVolatile, nil
+	}
+	return 0, errors.AssertionFailedf("unknown volatility %s", v)
+}
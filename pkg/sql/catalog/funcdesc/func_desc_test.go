@@ -0,0 +1,239 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package funcdesc_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/nstree"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	dbID     = 100
+	schemaID = 101
+	tableID  = 102
+	typeID   = 103
+	funcID   = 104
+)
+
+func validFunctionDesc() descpb.FunctionDescriptor {
+	return descpb.FunctionDescriptor{
+		Name:           "f",
+		ID:             funcID,
+		ParentID:       dbID,
+		ParentSchemaID: schemaID,
+		Privileges:     catpb.NewBasePrivilegeDescriptor(security.AdminRoleName()),
+		Args: []descpb.FunctionDescriptor_Argument{
+			{Name: "a", Type: types.Int},
+		},
+		ReturnType:     descpb.FunctionDescriptor_ReturnType{Type: types.Int},
+		FunctionBody:   "SELECT a",
+		Volatility:     descpb.FunctionDescriptor_IMMUTABLE,
+		DependsOn:      []descpb.ID{tableID},
+		DependsOnTypes: []descpb.ID{typeID},
+	}
+}
+
+func TestValidateFuncDesc(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		err string
+		// desc modifies the otherwise valid function descriptor.
+		desc func(desc *descpb.FunctionDescriptor)
+		// others modifies the otherwise valid descriptors referenced by the
+		// function.
+		others func(
+			db *descpb.DatabaseDescriptor,
+			sc *descpb.SchemaDescriptor,
+			tbl *descpb.TableDescriptor,
+			typ *descpb.TypeDescriptor,
+		)
+	}{
+		{ // 0
+			err: ``,
+		},
+		{ // 1
+			err: `return type not set`,
+			desc: func(desc *descpb.FunctionDescriptor) {
+				desc.ReturnType.Type = nil
+			},
+		},
+		{ // 2
+			err: `type not set for arg 0`,
+			desc: func(desc *descpb.FunctionDescriptor) {
+				desc.Args[0].Type = nil
+			},
+		},
+		{ // 3
+			err: `leakproof is set for non-immutable function`,
+			desc: func(desc *descpb.FunctionDescriptor) {
+				desc.LeakProof = true
+				desc.Volatility = descpb.FunctionDescriptor_STABLE
+			},
+		},
+		{ // 4
+			err: `referenced schema ID 500: referenced descriptor not found`,
+			desc: func(desc *descpb.FunctionDescriptor) {
+				desc.ParentSchemaID = 500
+			},
+		},
+		{ // 5
+			err: `function does not exist in schema "sc" (101)`,
+			others: func(
+				_ *descpb.DatabaseDescriptor, sc *descpb.SchemaDescriptor,
+				_ *descpb.TableDescriptor, _ *descpb.TypeDescriptor,
+			) {
+				sc.Functions = nil
+			},
+		},
+		{ // 6
+			err: `depends-on relation "t" (102) has no corresponding depended-on-by back reference`,
+			others: func(
+				_ *descpb.DatabaseDescriptor, _ *descpb.SchemaDescriptor,
+				tbl *descpb.TableDescriptor, _ *descpb.TypeDescriptor,
+			) {
+				tbl.DependedOnBy = nil
+			},
+		},
+		{ // 7
+			err: `depends-on relation "t" (102) is dropped`,
+			others: func(
+				_ *descpb.DatabaseDescriptor, _ *descpb.SchemaDescriptor,
+				tbl *descpb.TableDescriptor, _ *descpb.TypeDescriptor,
+			) {
+				tbl.State = descpb.DescriptorState_DROP
+			},
+		},
+		{ // 8
+			err: `depends-on type "typ" (103) has no corresponding referencing-descriptor back references`,
+			others: func(
+				_ *descpb.DatabaseDescriptor, _ *descpb.SchemaDescriptor,
+				_ *descpb.TableDescriptor, typ *descpb.TypeDescriptor,
+			) {
+				typ.ReferencingDescriptorIDs = nil
+			},
+		},
+		{ // 9
+			err: ``,
+			desc: func(desc *descpb.FunctionDescriptor) {
+				// Dropped functions are expected to be unlinked from their
+				// dependencies and their parent schema.
+				desc.State = descpb.DescriptorState_DROP
+			},
+			others: func(
+				_ *descpb.DatabaseDescriptor, sc *descpb.SchemaDescriptor,
+				tbl *descpb.TableDescriptor, typ *descpb.TypeDescriptor,
+			) {
+				sc.Functions = nil
+				tbl.DependedOnBy = nil
+				typ.ReferencingDescriptorIDs = nil
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			privs := catpb.NewBasePrivilegeDescriptor(security.AdminRoleName())
+			fnProto := validFunctionDesc()
+			db := descpb.DatabaseDescriptor{
+				Name:       "db",
+				ID:         dbID,
+				Privileges: privs,
+				Schemas: map[string]descpb.DatabaseDescriptor_SchemaInfo{
+					"sc": {ID: schemaID},
+				},
+			}
+			sc := descpb.SchemaDescriptor{
+				Name:       "sc",
+				ID:         schemaID,
+				ParentID:   dbID,
+				Privileges: privs,
+			}
+			tbl := descpb.TableDescriptor{
+				Name:                    "t",
+				ID:                      tableID,
+				ParentID:                dbID,
+				UnexposedParentSchemaID: schemaID,
+				Privileges:              privs,
+				DependedOnBy:            []descpb.TableDescriptor_Reference{{ID: funcID}},
+			}
+			typ := descpb.TypeDescriptor{
+				Name:                     "typ",
+				ID:                       typeID,
+				ParentID:                 dbID,
+				ParentSchemaID:           schemaID,
+				Kind:                     descpb.TypeDescriptor_ENUM,
+				Privileges:               privs,
+				ReferencingDescriptorIDs: []descpb.ID{funcID},
+			}
+			if test.desc != nil {
+				test.desc(&fnProto)
+			}
+			fnDesc := funcdesc.NewBuilder(&fnProto).BuildImmutableFunction()
+			sc.Functions = map[string]descpb.SchemaDescriptor_Function{
+				"f": {Name: "f", Overloads: []descpb.SchemaDescriptor_FunctionOverload{fnDesc.ToOverload()}},
+			}
+			if test.others != nil {
+				test.others(&db, &sc, &tbl, &typ)
+			}
+
+			var cb nstree.MutableCatalog
+			cb.UpsertDescriptorEntry(fnDesc)
+			cb.UpsertDescriptorEntry(dbdesc.NewBuilder(&db).BuildImmutable())
+			cb.UpsertDescriptorEntry(schemadesc.NewBuilder(&sc).BuildImmutable())
+			cb.UpsertDescriptorEntry(tabledesc.NewBuilder(&tbl).BuildImmutable())
+			cb.UpsertDescriptorEntry(typedesc.NewBuilder(&typ).BuildImmutable())
+
+			results := cb.Validate(ctx, clusterversion.TestingClusterVersion, catalog.NoValidationTelemetry, catalog.ValidationLevelCrossReferences, fnDesc)
+			err := results.CombinedError()
+			if test.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			expectedErr := fmt.Sprintf("%s %q (%d): %s", fnDesc.DescriptorType(), fnDesc.GetName(), fnDesc.GetID(), test.err)
+			require.EqualError(t, err, expectedErr)
+		})
+	}
+}
+
+func TestFuncIDToOID(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, id := range []descpb.ID{1, funcID, 1 << 20} {
+		o := funcdesc.FuncIDToOID(id)
+		require.True(t, funcdesc.IsOIDUserDefinedFunc(o))
+		got, err := funcdesc.UserDefinedFunctionOIDToID(o)
+		require.NoError(t, err)
+		require.Equal(t, id, got)
+	}
+	_, err := funcdesc.UserDefinedFunctionOIDToID(1)
+	require.Error(t, err)
+}
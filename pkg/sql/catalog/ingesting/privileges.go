@@ -78,6 +78,12 @@ func GetIngestingDescriptorPrivileges(
 		if descCoverage == tree.RequestedDescriptors {
 			updatedPrivileges = catpb.NewBasePrivilegeDescriptor(user)
 		}
+	case catalog.FunctionDescriptor:
+		// Likewise for functions, which any user may execute by default.
+		if descCoverage == tree.RequestedDescriptors {
+			updatedPrivileges = catpb.NewBasePrivilegeDescriptor(user)
+			updatedPrivileges.Grant(security.PublicRoleName(), privilege.List{privilege.EXECUTE}, false /* withGrantOption */)
+		}
 	case catalog.DatabaseDescriptor:
 		// If the ingestion is not a cluster restore we cannot know that the users
 		// on the ingesting cluster match the ones that were on the cluster that was
@@ -177,6 +177,8 @@ func wrapError(expectedType catalog.DescriptorType, id descpb.ID, err error) err
 		return catalog.WrapSchemaDescRefErr(id, err)
 	case catalog.Type:
 		return catalog.WrapTypeDescRefErr(id, err)
+	case catalog.Function:
+		return catalog.WrapFunctionDescRefErr(id, err)
 	}
 	return errors.Wrapf(err, "referenced descriptor ID %d", id)
 }
@@ -225,6 +227,9 @@ func requiredError(expectedType catalog.DescriptorType, id descpb.ID) (err error
 		err = sqlerrors.NewUndefinedSchemaError(fmt.Sprintf("[%d]", id))
 	case catalog.Type:
 		err = sqlerrors.NewUndefinedTypeError(tree.NewUnqualifiedTypeName(fmt.Sprintf("[%d]", id)))
+	case catalog.Function:
+		fn := tree.MakeFunctionNameFromPrefix(tree.ObjectNamePrefix{}, tree.Name(fmt.Sprintf("[%d]", id)))
+		err = sqlerrors.NewUndefinedFunctionError(&fn)
 	default:
 		err = errors.Errorf("failed to find descriptor [%d]", id)
 	}
@@ -218,6 +218,8 @@ func (vea *validationErrorAccumulator) decorate(err error) error {
 			err = errors.Wrapf(err, catalog.Schema+" %q (%d)", name, id)
 		case catalog.Type:
 			err = errors.Wrapf(err, catalog.Type+" %q (%d)", name, id)
+		case catalog.Function:
+			err = errors.Wrapf(err, catalog.Function+" %q (%d)", name, id)
 		default:
 			return err
 		}
@@ -301,6 +303,17 @@ func (vdg *validationDescGetterImpl) GetTypeDescriptor(
 	return descriptor, err
 }
 
+// GetFunctionDescriptor implements the ValidationDescGetter interface.
+func (vdg *validationDescGetterImpl) GetFunctionDescriptor(
+	id descpb.ID,
+) (catalog.FunctionDescriptor, error) {
+	desc, found := vdg.descriptors[id]
+	if !found || desc == nil {
+		return nil, catalog.WrapFunctionDescRefErr(id, catalog.ErrReferencedDescriptorNotFound)
+	}
+	return catalog.AsFunctionDescriptor(desc)
+}
+
 func (vdg *validationDescGetterImpl) addNamespaceEntries(
 	ctx context.Context, descriptors []catalog.Descriptor, vd ValidationDereferencer,
 ) error {
@@ -309,6 +322,10 @@ func (vdg *validationDescGetterImpl) addNamespaceEntries(
 		if desc == nil {
 			continue
 		}
+		// Functions are not referenced in the namespace table.
+		if desc.DescriptorType() == catalog.Function {
+			continue
+		}
 		reqs = append(reqs, descpb.NameInfo{
 			ParentID:       desc.GetParentID(),
 			ParentSchemaID: desc.GetParentSchemaID(),
@@ -425,6 +442,11 @@ func validateNamespace(
 	if desc.GetID() == keys.NamespaceTableID || desc.GetID() == keys.DeprecatedNamespaceTableID {
 		return
 	}
+	// Functions have no namespace entries, they are looked up through the
+	// function mapping of their parent schema instead.
+	if desc.DescriptorType() == catalog.Function {
+		return
+	}
 
 	key := descpb.NameInfo{
 		ParentID:       desc.GetParentID(),
@@ -36,10 +36,12 @@ type EntryIterator func(entry catalog.NameEntry) error
 // tree with the same name or id, it will be removed.
 func (dt *Map) Upsert(d catalog.NameEntry) {
 	dt.maybeInitialize()
-	if replaced := dt.byName.upsert(d); replaced != nil {
-		dt.byID.delete(replaced.GetID())
+	if hasName(d) {
+		if replaced := dt.byName.upsert(d); replaced != nil {
+			dt.byID.delete(replaced.GetID())
+		}
 	}
-	if replaced := dt.byID.upsert(d); replaced != nil {
+	if replaced := dt.byID.upsert(d); replaced != nil && hasName(replaced) {
 		dt.byName.delete(replaced)
 	}
 }
@@ -49,7 +51,9 @@ func (dt *Map) Upsert(d catalog.NameEntry) {
 func (dt *Map) Remove(id descpb.ID) catalog.NameEntry {
 	dt.maybeInitialize()
 	if d := dt.byID.delete(id); d != nil {
-		dt.byName.delete(d)
+		if hasName(d) {
+			dt.byName.delete(d)
+		}
 		return d
 	}
 	return nil
@@ -120,3 +124,11 @@ func (dt *Map) maybeInitialize() {
 		byID:   byIDMap{t: btreeSyncPool.Get().(*btree.BTree)},
 	}
 }
+
+// hasName returns false for entries which are not indexed by name. Functions
+// have no namespace entry and may share their name with other objects in
+// their schema, including other overloads of the same function.
+func hasName(d catalog.NameEntry) bool {
+	_, isFunction := d.(catalog.FunctionDescriptor)
+	return !isFunction
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
@@ -160,6 +161,18 @@ func TableDescs(
 				table.DependedOnBy = append(table.DependedOnBy, ref)
 			}
 		}
+		for i := range table.Triggers {
+			trigger := &table.Triggers[i]
+			if rw, ok := descriptorRewrites[trigger.FunctionID]; ok {
+				trigger.FunctionID = rw.ID
+			} else {
+				// Tables with triggers executing functions which are not restored
+				// should have caused an error in checkMissingDependencies().
+				return errors.AssertionFailedf(
+					"cannot restore %q because the function %d executed by trigger %q was not found",
+					table.Name, trigger.FunctionID, trigger.Name)
+			}
+		}
 
 		if table.IsSequence() && table.SequenceOpts.HasOwner() {
 			if ownerRewrite, ok := descriptorRewrites[table.SequenceOpts.SequenceOwner.OwnerTableID]; ok {
@@ -373,7 +386,8 @@ func rewriteIDsInTypesT(typ *types.T, descriptorRewrites jobspb.DescRewriteMap)
 	if rw, ok := descriptorRewrites[tid]; ok {
 		newOID = typedesc.TypeIDToOID(rw.ID)
 	}
-	if typ.Family() != types.ArrayFamily {
+	// Domains do not have array types.
+	if typ.Family() != types.ArrayFamily && !typ.IsDomain() {
 		tid, err = typedesc.GetUserDefinedArrayTypeDescID(typ)
 		if err != nil {
 			return err
@@ -389,6 +403,14 @@ func rewriteIDsInTypesT(typ *types.T, descriptorRewrites jobspb.DescRewriteMap)
 			return err
 		}
 	}
+	// Likewise for the element types of a composite type.
+	if typ.Family() == types.TupleFamily {
+		for _, elem := range typ.TupleContents() {
+			if err := rewriteIDsInTypesT(elem, descriptorRewrites); err != nil {
+				return err
+			}
+		}
+	}
 
 	return nil
 }
@@ -428,6 +450,22 @@ func TypeDescs(types []*typedesc.Mutable, descriptorRewrites jobspb.DescRewriteM
 			if err := rewriteIDsInTypesT(typ.Alias, descriptorRewrites); err != nil {
 				return err
 			}
+		case descpb.TypeDescriptor_COMPOSITE:
+			if rw, ok := descriptorRewrites[typ.ArrayTypeID]; ok {
+				typ.ArrayTypeID = rw.ID
+			}
+			for i := range typ.Composite.Elements {
+				if err := rewriteIDsInTypesT(typ.Composite.Elements[i].ElementType, descriptorRewrites); err != nil {
+					return err
+				}
+			}
+		case descpb.TypeDescriptor_DOMAIN:
+			if err := rewriteIDsInTypesT(typ.Domain.BaseType, descriptorRewrites); err != nil {
+				return err
+			}
+			if rw, ok := descriptorRewrites[typ.Domain.BaseDomainID]; ok {
+				typ.Domain.BaseDomainID = rw.ID
+			}
 		default:
 			return errors.AssertionFailedf("unknown type kind %s", t.String())
 		}
@@ -453,6 +491,93 @@ func SchemaDescs(schemas []*schemadesc.Mutable, descriptorRewrites jobspb.DescRe
 		if err := rewriteSchemaChangerState(sc, descriptorRewrites); err != nil {
 			return err
 		}
+
+		// Rewrite the function mapping of the schema. Overloads whose function
+		// is not restored are dropped from it.
+		newFunctions := make(map[string]descpb.SchemaDescriptor_Function, len(sc.Functions))
+		for name, fn := range sc.Functions {
+			var overloads []descpb.SchemaDescriptor_FunctionOverload
+			for _, o := range fn.Overloads {
+				rw, ok := descriptorRewrites[o.ID]
+				if !ok {
+					continue
+				}
+				o.ID = rw.ID
+				for _, typ := range o.ArgTypes {
+					if err := rewriteIDsInTypesT(typ, descriptorRewrites); err != nil {
+						return err
+					}
+				}
+				if err := rewriteIDsInTypesT(o.ReturnType, descriptorRewrites); err != nil {
+					return err
+				}
+				overloads = append(overloads, o)
+			}
+			if len(overloads) > 0 {
+				fn.Overloads = overloads
+				newFunctions[name] = fn
+			}
+		}
+		sc.Functions = newFunctions
+	}
+	return nil
+}
+
+// FunctionDescs rewrites all ID's in the input slice of FunctionDescriptors
+// using the input ID rewrite mapping.
+func FunctionDescs(functions []*funcdesc.Mutable, descriptorRewrites jobspb.DescRewriteMap) error {
+	for _, fn := range functions {
+		rewrite, ok := descriptorRewrites[fn.ID]
+		if !ok {
+			return errors.Errorf("missing rewrite for function %d", fn.ID)
+		}
+		// Reset the version and modification time on this new descriptor.
+		fn.Version = 1
+		fn.ModificationTime = hlc.Timestamp{}
+
+		if err := rewriteSchemaChangerState(fn, descriptorRewrites); err != nil {
+			return err
+		}
+
+		fn.ID = rewrite.ID
+		fn.ParentID = rewrite.ParentID
+		fn.ParentSchemaID = rewrite.ParentSchemaID
+
+		for i := range fn.Args {
+			if err := rewriteIDsInTypesT(fn.Args[i].Type, descriptorRewrites); err != nil {
+				return err
+			}
+		}
+		if err := rewriteIDsInTypesT(fn.ReturnType.Type, descriptorRewrites); err != nil {
+			return err
+		}
+		for i, dep := range fn.DependsOn {
+			depRewrite, ok := descriptorRewrites[dep]
+			if !ok {
+				return errors.AssertionFailedf(
+					"cannot restore function %q because referenced relation %d was not found",
+					fn.Name, dep)
+			}
+			fn.DependsOn[i] = depRewrite.ID
+		}
+		for i, dep := range fn.DependsOnTypes {
+			depRewrite, ok := descriptorRewrites[dep]
+			if !ok {
+				return errors.AssertionFailedf(
+					"cannot restore function %q because referenced type %d was not found",
+					fn.Name, dep)
+			}
+			fn.DependsOnTypes[i] = depRewrite.ID
+		}
+		// Unlike the relations the function depends on, the tables whose
+		// triggers execute it need not be restored along with it.
+		origRefs := fn.DependedOnBy
+		fn.DependedOnBy = nil
+		for _, ref := range origRefs {
+			if refRewrite, ok := descriptorRewrites[ref]; ok {
+				fn.DependedOnBy = append(fn.DependedOnBy, refRewrite.ID)
+			}
+		}
 	}
 	return nil
 }
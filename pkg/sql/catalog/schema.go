@@ -26,6 +26,15 @@ type SchemaDescriptor interface {
 	// GetDefaultPrivilegeDescriptor returns the default privileges for this
 	// database.
 	GetDefaultPrivilegeDescriptor() DefaultPrivilegeDescriptor
+
+	// GetFunction returns the overloads of the user-defined function with the
+	// given name in this schema, if any.
+	GetFunction(name string) (descpb.SchemaDescriptor_Function, bool)
+
+	// ForEachFunctionOverload iterates over the overloads of all the
+	// user-defined functions in this schema, ordered by function name.
+	// iterutil.StopIteration is supported.
+	ForEachFunctionOverload(f func(overload descpb.SchemaDescriptor_FunctionOverload) error) error
 }
 
 // ResolvedSchemaKind is an enum that represents what kind of schema
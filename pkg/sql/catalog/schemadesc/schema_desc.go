@@ -12,6 +12,7 @@ package schemadesc
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -25,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/iterutil"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
 )
@@ -189,6 +191,21 @@ func (desc *immutable) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 		// Validate the default privilege descriptor.
 		vea.Report(catprivilege.ValidateDefaultPrivileges(*desc.GetDefaultPrivileges()))
 	}
+
+	// Validate the function mapping.
+	for name, fn := range desc.Functions {
+		if name != fn.Name {
+			vea.Report(errors.AssertionFailedf("function %q is mapped under name %q", fn.Name, name))
+		}
+		if len(fn.Overloads) == 0 {
+			vea.Report(errors.AssertionFailedf("function %q has no overloads", name))
+		}
+		for _, o := range fn.Overloads {
+			if o.ID == descpb.InvalidID {
+				vea.Report(errors.AssertionFailedf("function %q has an overload with invalid ID", name))
+			}
+		}
+	}
 }
 
 // GetReferencedDescIDs returns the IDs of all descriptors referenced by
@@ -371,6 +388,68 @@ func (desc *Mutable) SetDeclarativeSchemaChangerState(state *scpb.DescriptorStat
 	desc.DeclarativeSchemaChangerState = state
 }
 
+// GetFunction implements the catalog.SchemaDescriptor interface.
+func (desc *immutable) GetFunction(name string) (descpb.SchemaDescriptor_Function, bool) {
+	fn, ok := desc.Functions[name]
+	return fn, ok
+}
+
+// ForEachFunctionOverload implements the catalog.SchemaDescriptor interface.
+func (desc *immutable) ForEachFunctionOverload(
+	f func(overload descpb.SchemaDescriptor_FunctionOverload) error,
+) error {
+	names := make([]string, 0, len(desc.Functions))
+	for name := range desc.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, o := range desc.Functions[name].Overloads {
+			if err := f(o); err != nil {
+				if iterutil.Done(err) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AddFunction adds an overload of the function with the given name to the
+// schema's function mapping.
+func (desc *Mutable) AddFunction(name string, overload descpb.SchemaDescriptor_FunctionOverload) {
+	if desc.Functions == nil {
+		desc.Functions = make(map[string]descpb.SchemaDescriptor_Function)
+	}
+	fn := desc.Functions[name]
+	fn.Name = name
+	fn.Overloads = append(fn.Overloads, overload)
+	desc.Functions[name] = fn
+}
+
+// RemoveFunction removes the overload with the given function ID from the
+// schema's function mapping. The function entry is removed entirely once it
+// no longer has any overloads.
+func (desc *Mutable) RemoveFunction(name string, id descpb.ID) {
+	fn, ok := desc.Functions[name]
+	if !ok {
+		return
+	}
+	overloads := fn.Overloads[:0]
+	for _, o := range fn.Overloads {
+		if o.ID != id {
+			overloads = append(overloads, o)
+		}
+	}
+	if len(overloads) == 0 {
+		delete(desc.Functions, name)
+		return
+	}
+	fn.Overloads = overloads
+	desc.Functions[name] = fn
+}
+
 // IsSchemaNameValid returns whether the input name is valid for a user defined
 // schema.
 func IsSchemaNameValid(name string) error {
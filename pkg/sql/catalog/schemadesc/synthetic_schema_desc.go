@@ -126,3 +126,16 @@ func (p synthetic) HasConcurrentSchemaChanges() bool {
 func (p synthetic) GetDefaultPrivilegeDescriptor() catalog.DefaultPrivilegeDescriptor {
 	return catprivilege.MakeDefaultPrivileges(catprivilege.MakeDefaultPrivilegeDescriptor(catpb.DefaultPrivilegeDescriptor_SCHEMA))
 }
+
+// GetFunction implements the catalog.SchemaDescriptor interface. Synthetic
+// schemas never contain user-defined functions.
+func (p synthetic) GetFunction(name string) (descpb.SchemaDescriptor_Function, bool) {
+	return descpb.SchemaDescriptor_Function{}, false
+}
+
+// ForEachFunctionOverload implements the catalog.SchemaDescriptor interface.
+func (p synthetic) ForEachFunctionOverload(
+	f func(overload descpb.SchemaDescriptor_FunctionOverload) error,
+) error {
+	return nil
+}
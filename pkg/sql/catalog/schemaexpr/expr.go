@@ -407,8 +407,9 @@ func SanitizeVarFreeExpr(
 	// which uses the properties field.
 	defer semaCtx.Properties.Restore(semaCtx.Properties)
 
-	// Ensure that the expression doesn't contain special functions.
-	flags := tree.RejectSpecial
+	// Ensure that the expression doesn't contain special functions or calls
+	// to user-defined functions.
+	flags := tree.RejectSpecial | tree.RejectUDFs
 
 	switch maxVolatility {
 	case tree.VolatilityImmutable:
@@ -395,6 +395,10 @@ type ConstraintToUpdate interface {
 	// without index constraint.
 	IsUniqueWithoutIndex() bool
 
+	// IsExclusion returns true iff this is an update for an exclusion
+	// constraint.
+	IsExclusion() bool
+
 	// Check returns the underlying check constraint, if there is one.
 	Check() descpb.TableDescriptor_CheckConstraint
 
@@ -408,6 +412,9 @@ type ConstraintToUpdate interface {
 	// there is one.
 	UniqueWithoutIndex() descpb.UniqueWithoutIndexConstraint
 
+	// Exclusion returns the underlying exclusion constraint, if there is one.
+	Exclusion() descpb.ExclusionConstraint
+
 	// GetConstraintID returns the ID for the constraint.
 	GetConstraintID() descpb.ConstraintID
 }
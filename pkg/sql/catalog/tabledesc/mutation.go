@@ -144,6 +144,16 @@ func (c constraintToUpdate) UniqueWithoutIndex() descpb.UniqueWithoutIndexConstr
 	return c.desc.UniqueWithoutIndexConstraint
 }
 
+// IsExclusion returns true iff this is an update for an exclusion constraint.
+func (c constraintToUpdate) IsExclusion() bool {
+	return c.desc.ConstraintType == descpb.ConstraintToUpdate_EXCLUSION
+}
+
+// Exclusion returns the underlying exclusion constraint, if there is one.
+func (c constraintToUpdate) Exclusion() descpb.ExclusionConstraint {
+	return c.desc.ExclusionConstraint
+}
+
 // GetConstraintID returns the ID for the constraint.
 func (c constraintToUpdate) GetConstraintID() descpb.ConstraintID {
 	switch c.desc.ConstraintType {
@@ -155,6 +165,8 @@ func (c constraintToUpdate) GetConstraintID() descpb.ConstraintID {
 		return 0
 	case descpb.ConstraintToUpdate_UNIQUE_WITHOUT_INDEX:
 		return c.UniqueWithoutIndex().ConstraintID
+	case descpb.ConstraintToUpdate_EXCLUSION:
+		return c.Exclusion().ConstraintID
 	}
 	panic("unknown constraint type")
 }
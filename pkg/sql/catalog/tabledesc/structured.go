@@ -275,6 +275,48 @@ func (desc *wrapper) AllActiveAndInactiveUniqueWithoutIndexConstraints() []*desc
 	return ucs
 }
 
+// AllActiveAndInactiveExclusionConstraints implements the TableDescriptor
+// interface.
+func (desc *wrapper) AllActiveAndInactiveExclusionConstraints() []*descpb.ExclusionConstraint {
+	ecs := make([]*descpb.ExclusionConstraint, 0, len(desc.ExclusionConstraints))
+	for i := range desc.ExclusionConstraints {
+		ec := &desc.ExclusionConstraints[i]
+		// As for unique without index constraints, constraints which are being
+		// validated are present both on the table descriptor and in the
+		// mutations list.
+		if ec.Validity != descpb.ConstraintValidity_Validating {
+			ecs = append(ecs, ec)
+		}
+	}
+	for i := range desc.Mutations {
+		if c := desc.Mutations[i].GetConstraint(); c != nil &&
+			c.ConstraintType == descpb.ConstraintToUpdate_EXCLUSION {
+			ecs = append(ecs, &c.ExclusionConstraint)
+		}
+	}
+	return ecs
+}
+
+// FindTriggerByName implements the TableDescriptor interface.
+func (desc *wrapper) FindTriggerByName(name string) (*descpb.TriggerDescriptor, bool) {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].Name == name {
+			return &desc.Triggers[i], true
+		}
+	}
+	return nil, false
+}
+
+// RemoveTrigger removes the trigger with the given name, if any.
+func (desc *Mutable) RemoveTrigger(name string) {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].Name == name {
+			desc.Triggers = append(desc.Triggers[:i], desc.Triggers[i+1:]...)
+			return
+		}
+	}
+}
+
 // AllActiveAndInactiveForeignKeys implements the TableDescriptor interface.
 func (desc *wrapper) AllActiveAndInactiveForeignKeys() []*descpb.ForeignKeyConstraint {
 	fks := make([]*descpb.ForeignKeyConstraint, 0, len(desc.OutboundFKs))
@@ -1408,6 +1450,23 @@ func (desc *Mutable) DropConstraint(
 			}
 		}
 
+	case descpb.ConstraintTypeExclusion:
+		if detail.ExclusionConstraint.Validity == descpb.ConstraintValidity_Validating {
+			return unimplemented.NewWithIssueDetailf(42844,
+				"drop-constraint-exclusion-validating",
+				"constraint %q in the middle of being added, try again later", name)
+		}
+		// EXCLUDE constraints are only enforced by the checks planned for
+		// mutations, so they can be dropped immediately.
+		for i := range desc.ExclusionConstraints {
+			if desc.ExclusionConstraints[i].Name == name {
+				desc.ExclusionConstraints = append(
+					desc.ExclusionConstraints[:i], desc.ExclusionConstraints[i+1:]...,
+				)
+				return nil
+			}
+		}
+
 	case descpb.ConstraintTypeFK:
 		if detail.FK.Validity == descpb.ConstraintValidity_Validating {
 			return unimplemented.NewWithIssueDetailf(42844,
@@ -1543,6 +1602,16 @@ func (desc *Mutable) RenameConstraint(
 		detail.CheckConstraint.Name = newName
 		return nil
 
+	case descpb.ConstraintTypeExclusion:
+		if detail.ExclusionConstraint.Validity == descpb.ConstraintValidity_Validating {
+			return unimplemented.NewWithIssueDetailf(42844,
+				"rename-constraint-exclusion-mutation",
+				"constraint %q in the middle of being added, try again later",
+				tree.ErrNameStringP(&detail.ExclusionConstraint.Name))
+		}
+		detail.ExclusionConstraint.Name = newName
+		return nil
+
 	default:
 		return unimplemented.Newf(fmt.Sprintf("rename-constraint-%s", detail.Kind),
 			"constraint %q has unsupported type", tree.ErrNameString(oldName))
@@ -1681,6 +1750,26 @@ func (desc *Mutable) MakeMutationComplete(m descpb.DescriptorMutation) error {
 						t.Constraint.UniqueWithoutIndexConstraint.Validity,
 					)
 				}
+			case descpb.ConstraintToUpdate_EXCLUSION:
+				switch t.Constraint.ExclusionConstraint.Validity {
+				case descpb.ConstraintValidity_Validating:
+					// Constraint already added, just mark it as Validated.
+					for i := range desc.ExclusionConstraints {
+						ec := &desc.ExclusionConstraints[i]
+						if ec.Name == t.Constraint.Name {
+							ec.Validity = descpb.ConstraintValidity_Validated
+							break
+						}
+					}
+				case descpb.ConstraintValidity_Unvalidated:
+					desc.ExclusionConstraints = append(
+						desc.ExclusionConstraints, t.Constraint.ExclusionConstraint,
+					)
+				default:
+					return errors.AssertionFailedf("invalid constraint validity state: %d",
+						t.Constraint.ExclusionConstraint.Validity,
+					)
+				}
 			case descpb.ConstraintToUpdate_NOT_NULL:
 				// Remove the dummy check constraint that was in place during
 				// validation.
@@ -1952,6 +2041,24 @@ func (desc *Mutable) AddUniqueWithoutIndexMutation(
 	desc.addMutation(m)
 }
 
+// AddExclusionMutation adds an exclusion constraint mutation to
+// desc.Mutations.
+func (desc *Mutable) AddExclusionMutation(
+	ec *descpb.ExclusionConstraint, direction descpb.DescriptorMutation_Direction,
+) {
+	m := descpb.DescriptorMutation{
+		Descriptor_: &descpb.DescriptorMutation_Constraint{
+			Constraint: &descpb.ConstraintToUpdate{
+				ConstraintType:      descpb.ConstraintToUpdate_EXCLUSION,
+				Name:                ec.Name,
+				ExclusionConstraint: *ec,
+			},
+		},
+		Direction: direction,
+	}
+	desc.addMutation(m)
+}
+
 // MakeNotNullCheckConstraint creates a dummy check constraint equivalent to a
 // NOT NULL constraint on a column, so that NOT NULL constraints can be added
 // and dropped correctly in the schema changer. This function mutates inuseNames
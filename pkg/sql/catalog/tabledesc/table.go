@@ -437,6 +437,27 @@ func (desc *wrapper) collectConstraintInfo(
 		}
 		info[c.Name] = detail
 	}
+
+	for _, ec := range desc.AllActiveAndInactiveExclusionConstraints() {
+		if _, ok := info[ec.Name]; ok {
+			return nil, pgerror.Newf(pgcode.DuplicateObject,
+				"duplicate constraint name: %q", ec.Name)
+		}
+		detail := descpb.ConstraintDetail{
+			Kind:         descpb.ConstraintTypeExclusion,
+			ConstraintID: ec.ConstraintID,
+		}
+		// Constraints in the Validating state are considered Unvalidated for this
+		// purpose.
+		detail.Unvalidated = ec.Validity != descpb.ConstraintValidity_Validated
+		var err error
+		detail.Columns, err = desc.NamesForColumnIDs(ec.ColumnIDs)
+		if err != nil {
+			return nil, err
+		}
+		detail.ExclusionConstraint = ec
+		info[ec.Name] = detail
+	}
 	return info, nil
 }
 
@@ -632,6 +653,15 @@ func RenameColumnInTable(
 		}
 	}
 
+	// Rename the column in the predicates of EXCLUDE constraints.
+	for i := range tableDesc.ExclusionConstraints {
+		if ec := &tableDesc.ExclusionConstraints[i]; ec.IsPartial() {
+			if err := renameInExpr(&ec.Predicate); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Rename the column in computed columns.
 	for i := range tableDesc.Columns {
 		if otherCol := &tableDesc.Columns[i]; otherCol.IsComputed() {
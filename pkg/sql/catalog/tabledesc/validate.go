@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
@@ -120,6 +121,10 @@ func (desc *wrapper) GetReferencedDescIDs() (catalog.DescriptorIDSet, error) {
 	for _, ref := range desc.GetDependedOnBy() {
 		ids.Add(ref.ID)
 	}
+	// Add the functions executed by triggers.
+	for i := range desc.Triggers {
+		ids.Add(desc.Triggers[i].FunctionID)
+	}
 	// Add sequence dependencies
 	return ids, nil
 }
@@ -185,9 +190,18 @@ func (desc *wrapper) ValidateCrossReferences(
 	}
 
 	for _, by := range desc.DependedOnBy {
+		// Functions depending on this relation also hold back-references here.
+		if fn, err := vdg.GetFunctionDescriptor(by.ID); err == nil {
+			vea.Report(desc.validateInboundFunctionRef(fn))
+			continue
+		}
 		vea.Report(desc.validateInboundTableRef(by, vdg))
 	}
 
+	for i := range desc.Triggers {
+		vea.Report(desc.validateTriggerFunctionRef(&desc.Triggers[i], vdg))
+	}
+
 	// For row-level TTL, only ascending PKs are permitted.
 	if desc.HasRowLevelTTL() {
 		pk := desc.GetPrimaryIndex()
@@ -347,6 +361,40 @@ func (desc *wrapper) validateInboundTableRef(
 		backReferencedTable.GetName(), by.ID)
 }
 
+func (desc *wrapper) validateInboundFunctionRef(fn catalog.FunctionDescriptor) error {
+	if fn.Dropped() {
+		return errors.AssertionFailedf("depended-on-by function %q (%d) is dropped",
+			fn.GetName(), fn.GetID())
+	}
+	for _, id := range fn.GetDependsOn() {
+		if id == desc.GetID() {
+			return nil
+		}
+	}
+	return errors.AssertionFailedf("depended-on-by function %q (%d) has no corresponding depends-on forward reference",
+		fn.GetName(), fn.GetID())
+}
+
+func (desc *wrapper) validateTriggerFunctionRef(
+	trigger *descpb.TriggerDescriptor, vdg catalog.ValidationDescGetter,
+) error {
+	fn, err := vdg.GetFunctionDescriptor(trigger.FunctionID)
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "invalid function reference of trigger %q", trigger.Name)
+	}
+	if fn.Dropped() {
+		return errors.AssertionFailedf("function %q (%d) of trigger %q is dropped",
+			fn.GetName(), fn.GetID(), trigger.Name)
+	}
+	for _, id := range fn.GetDependedOnBy() {
+		if id == desc.GetID() {
+			return nil
+		}
+	}
+	return errors.AssertionFailedf("function %q (%d) of trigger %q has no corresponding depended-on-by back reference",
+		fn.GetName(), fn.GetID(), trigger.Name)
+}
+
 func (desc *wrapper) validateOutboundFK(
 	fk *descpb.ForeignKeyConstraint, vdg catalog.ValidationDescGetter,
 ) error {
@@ -615,6 +663,8 @@ func (desc *wrapper) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 			desc.validateColumnFamilies(columnIDs),
 			desc.validateCheckConstraints(columnIDs),
 			desc.validateUniqueWithoutIndexConstraints(columnIDs),
+			desc.validateExclusionConstraints(columnIDs),
+			desc.validateTriggers(),
 			desc.validateTableIndexes(columnNames, vea),
 			desc.validatePartitioning(),
 		}
@@ -1049,6 +1099,89 @@ func (desc *wrapper) validateUniqueWithoutIndexConstraints(
 	return nil
 }
 
+// validateExclusionConstraints validates that EXCLUDE constraints are well
+// formed. Checks include validating the column IDs, the operators and the
+// predicate of each constraint.
+func (desc *wrapper) validateExclusionConstraints(
+	columnIDs map[descpb.ColumnID]*descpb.ColumnDescriptor,
+) error {
+	for _, c := range desc.AllActiveAndInactiveExclusionConstraints() {
+		if err := catalog.ValidateName(c.Name, "exclusion constraint"); err != nil {
+			return err
+		}
+
+		// Verify that the table ID is valid.
+		if c.TableID != desc.ID {
+			return errors.Newf(
+				"TableID mismatch for exclusion constraint %q: \"%d\" doesn't match descriptor: \"%d\"",
+				c.Name, c.TableID, desc.ID,
+			)
+		}
+
+		if len(c.ColumnIDs) == 0 || len(c.ColumnIDs) != len(c.Operators) {
+			return errors.Newf(
+				"exclusion constraint %q has %d columns and %d operators",
+				c.Name, len(c.ColumnIDs), len(c.Operators),
+			)
+		}
+		for j, colID := range c.ColumnIDs {
+			if _, ok := columnIDs[colID]; !ok {
+				return errors.Newf(
+					"exclusion constraint %q contains unknown column \"%d\"", c.Name, colID,
+				)
+			}
+			if _, ok := treecmp.LookupComparisonOperatorSymbol(c.Operators[j]); !ok {
+				return errors.Newf(
+					"exclusion constraint %q contains unknown operator %q", c.Name, c.Operators[j],
+				)
+			}
+		}
+
+		if c.IsPartial() {
+			expr, err := parser.ParseExpr(c.Predicate)
+			if err != nil {
+				return err
+			}
+			valid, err := schemaexpr.HasValidColumnReferences(desc, expr)
+			if err != nil {
+				return err
+			}
+			if !valid {
+				return errors.Newf(
+					"exclusion constraint %q refers to unknown columns in predicate: %s",
+					c.Name,
+					c.Predicate,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTriggers validates that triggers are well formed. Checks include
+// validating the trigger names, events and function references.
+func (desc *wrapper) validateTriggers() error {
+	names := make(map[string]struct{}, len(desc.Triggers))
+	for i := range desc.Triggers {
+		t := &desc.Triggers[i]
+		if err := catalog.ValidateName(t.Name, "trigger"); err != nil {
+			return err
+		}
+		if _, ok := names[t.Name]; ok {
+			return errors.Newf("duplicate trigger name: %q", t.Name)
+		}
+		names[t.Name] = struct{}{}
+		if !t.OnInsert && !t.OnUpdate && !t.OnDelete {
+			return errors.Newf("trigger %q does not fire on any event", t.Name)
+		}
+		if t.FunctionID == descpb.InvalidID {
+			return errors.Newf("invalid function ID %d in trigger %q", t.FunctionID, t.Name)
+		}
+	}
+	return nil
+}
+
 // validateTableIndexes validates that indexes are well formed. Checks include
 // validating the columns involved in the index, verifying the index names and
 // IDs are unique, and the family of the primary key is 0. This does not check
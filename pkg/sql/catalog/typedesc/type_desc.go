@@ -555,6 +555,34 @@ func (desc *immutable) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 		if desc.GetArrayTypeID() != descpb.InvalidID {
 			vea.Report(errors.AssertionFailedf("ALIAS type desc has array type ID %d", desc.GetArrayTypeID()))
 		}
+	case descpb.TypeDescriptor_COMPOSITE:
+		if desc.RegionConfig != nil {
+			vea.Report(errors.AssertionFailedf("found region config on %s type desc", desc.Kind.String()))
+		}
+		if desc.Composite == nil {
+			vea.Report(errors.AssertionFailedf("COMPOSITE type desc has nil composite"))
+			break
+		}
+		labels := make(map[string]struct{}, len(desc.Composite.Elements))
+		for _, e := range desc.Composite.Elements {
+			if e.ElementType == nil {
+				vea.Report(errors.AssertionFailedf("composite element %q has nil type", e.ElementLabel))
+			}
+			if _, ok := labels[e.ElementLabel]; ok {
+				vea.Report(errors.AssertionFailedf("duplicate composite element label %q", e.ElementLabel))
+			}
+			labels[e.ElementLabel] = struct{}{}
+		}
+	case descpb.TypeDescriptor_DOMAIN:
+		if desc.RegionConfig != nil {
+			vea.Report(errors.AssertionFailedf("found region config on %s type desc", desc.Kind.String()))
+		}
+		if desc.Domain == nil || desc.Domain.BaseType == nil {
+			vea.Report(errors.AssertionFailedf("DOMAIN type desc has nil base type"))
+		}
+		if desc.GetArrayTypeID() != descpb.InvalidID {
+			vea.Report(errors.AssertionFailedf("DOMAIN type desc has array type ID %d", desc.GetArrayTypeID()))
+		}
 	case descpb.TypeDescriptor_TABLE_IMPLICIT_RECORD_TYPE:
 		vea.Report(errors.AssertionFailedf("invalid type descriptor: kind %s should never be serialized or validated", desc.Kind.String()))
 	default:
@@ -620,6 +648,9 @@ func (desc *immutable) GetReferencedDescIDs() (catalog.DescriptorIDSet, error) {
 	for id := range children {
 		ids.Add(id)
 	}
+	if desc.Domain != nil && desc.Domain.BaseDomainID != descpb.InvalidID {
+		ids.Add(desc.Domain.BaseDomainID)
+	}
 	return ids, nil
 }
 
@@ -657,7 +688,7 @@ func (desc *immutable) ValidateCrossReferences(
 
 	// Validate that the referenced types exist.
 	switch desc.GetKind() {
-	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
+	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM, descpb.TypeDescriptor_COMPOSITE:
 		// Ensure that the referenced array type exists.
 		if typ, err := vdg.GetTypeDescriptor(desc.GetArrayTypeID()); err != nil {
 			vea.Report(errors.Wrapf(err, "arrayTypeID %d does not exist for %q", desc.GetArrayTypeID(), desc.GetKind()))
@@ -677,9 +708,38 @@ func (desc *immutable) ValidateCrossReferences(
 			}
 		}
 	}
+	if desc.Composite != nil {
+		for _, e := range desc.Composite.Elements {
+			desc.validateReferencedType(e.ElementType, "element", vea, vdg)
+		}
+	}
+	if desc.Domain != nil {
+		desc.validateReferencedType(desc.Domain.BaseType, "base", vea, vdg)
+		if id := desc.Domain.BaseDomainID; id != descpb.InvalidID {
+			if baseDomain, err := vdg.GetTypeDescriptor(id); err != nil {
+				vea.Report(errors.Wrapf(err, "base domain %d does not exist", id))
+			} else if baseDomain.Dropped() {
+				vea.Report(errors.AssertionFailedf("base domain %q (%d) is dropped", baseDomain.GetName(), id))
+			}
+		}
+	}
 
 	// Validate that all of the referencing descriptors exist.
 	for _, id := range desc.GetReferencingDescriptorIDs() {
+		if fnDesc, err := vdg.GetFunctionDescriptor(id); err == nil {
+			if fnDesc.Dropped() {
+				vea.Report(errors.AssertionFailedf(
+					"referencing function %d was dropped without dependency unlinking", id))
+			}
+			continue
+		}
+		if typDesc, err := vdg.GetTypeDescriptor(id); err == nil {
+			if typDesc.Dropped() {
+				vea.Report(errors.AssertionFailedf(
+					"referencing type %d was dropped without dependency unlinking", id))
+			}
+			continue
+		}
 		tableDesc, err := vdg.GetTableDescriptor(id)
 		if err != nil {
 			vea.Report(err)
@@ -692,6 +752,29 @@ func (desc *immutable) ValidateCrossReferences(
 	}
 }
 
+// validateReferencedType checks that the user defined type referenced by a
+// composite element or a domain base type exists and is not dropped.
+func (desc *immutable) validateReferencedType(
+	typ *types.T,
+	what string,
+	vea catalog.ValidationErrorAccumulator,
+	vdg catalog.ValidationDescGetter,
+) {
+	if typ == nil || !typ.UserDefined() {
+		return
+	}
+	id, err := GetUserDefinedTypeDescID(typ)
+	if err != nil {
+		vea.Report(err)
+		return
+	}
+	if refTyp, err := vdg.GetTypeDescriptor(id); err != nil {
+		vea.Report(errors.Wrapf(err, "%s type %d does not exist", what, id))
+	} else if refTyp.Dropped() {
+		vea.Report(errors.AssertionFailedf("%s type %q (%d) is dropped", what, refTyp.GetName(), refTyp.GetID()))
+	}
+}
+
 func (desc *immutable) validateMultiRegion(
 	dbDesc catalog.DatabaseDescriptor, vea catalog.ValidationErrorAccumulator,
 ) {
@@ -798,6 +881,26 @@ func (desc *immutable) MakeTypesT(
 			return nil, err
 		}
 		return desc.Alias, nil
+	case descpb.TypeDescriptor_COMPOSITE:
+		contents := make([]*types.T, len(desc.Composite.Elements))
+		labels := make([]string, len(desc.Composite.Elements))
+		for i, e := range desc.Composite.Elements {
+			contents[i] = e.ElementType
+			labels[i] = e.ElementLabel
+		}
+		typ := types.MakeCompositeType(
+			TypeIDToOID(desc.GetID()), TypeIDToOID(desc.ArrayTypeID), contents, labels,
+		)
+		if err := desc.HydrateTypeInfoWithName(ctx, typ, name, res); err != nil {
+			return nil, err
+		}
+		return typ, nil
+	case descpb.TypeDescriptor_DOMAIN:
+		typ := types.MakeDomainType(TypeIDToOID(desc.GetID()), desc.Domain.BaseType)
+		if err := desc.HydrateTypeInfoWithName(ctx, typ, name, res); err != nil {
+			return nil, err
+		}
+		return typ, nil
 	default:
 		return nil, errors.AssertionFailedf("unknown type kind %s", t.String())
 	}
@@ -807,7 +910,7 @@ func (desc *immutable) MakeTypesT(
 func EnsureTypeIsHydrated(
 	ctx context.Context, t *types.T, res catalog.TypeDescriptorResolver,
 ) error {
-	if t.Family() == types.TupleFamily {
+	if t.Family() == types.TupleFamily && !t.IsCompositeType() {
 		for _, typ := range t.TupleContents() {
 			if err := EnsureTypeIsHydrated(ctx, typ, res); err != nil {
 				return err
@@ -859,6 +962,7 @@ func (desc *immutable) HydrateTypeInfoWithName(
 		return nil
 	}
 	var enumData *types.EnumMetadata
+	var domainData *types.DomainMetadata
 	switch desc.Kind {
 	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
 		if typ.Family() != types.EnumFamily {
@@ -886,6 +990,26 @@ func (desc *immutable) HydrateTypeInfoWithName(
 				return errors.AssertionFailedf("unhandled alias type family %s", typ.Family())
 			}
 		}
+	case descpb.TypeDescriptor_COMPOSITE:
+		if !typ.IsCompositeType() {
+			return errors.New("cannot hydrate a non-composite type with a composite type descriptor")
+		}
+		for _, elem := range typ.TupleContents() {
+			if err := EnsureTypeIsHydrated(ctx, elem, res); err != nil {
+				return err
+			}
+		}
+	case descpb.TypeDescriptor_DOMAIN:
+		if !typ.IsDomain() {
+			return errors.New("cannot hydrate a non-domain type with a domain type descriptor")
+		}
+		domainData = &types.DomainMetadata{
+			NotNull: desc.Domain.NotNull,
+			Checks:  make([]types.DomainCheck, len(desc.Domain.Checks)),
+		}
+		for i, check := range desc.Domain.Checks {
+			domainData.Checks[i] = types.DomainCheck{Name: check.Name, Expr: check.Expr}
+		}
 	default:
 		return errors.AssertionFailedf("unknown type descriptor kind %s", desc.Kind)
 	}
@@ -900,8 +1024,9 @@ func (desc *immutable) HydrateTypeInfoWithName(
 			Schema:         name.Schema(),
 			Name:           name.Object(),
 		},
-		Version:  uint32(desc.Version),
-		EnumData: enumData,
+		Version:    uint32(desc.Version),
+		EnumData:   enumData,
+		DomainData: domainData,
 	}
 	return nil
 }
@@ -1013,7 +1138,8 @@ func (desc *immutable) GetIDClosure() (map[descpb.ID]struct{}, error) {
 	ret := make(map[descpb.ID]struct{})
 	// Collect the descriptor's own ID.
 	ret[desc.ID] = struct{}{}
-	if desc.Kind == descpb.TypeDescriptor_ALIAS {
+	switch desc.Kind {
+	case descpb.TypeDescriptor_ALIAS:
 		// If this descriptor is an alias for another type, then get collect the
 		// closure for alias.
 		children, err := GetTypeDescriptorClosure(desc.Alias)
@@ -1023,7 +1149,16 @@ func (desc *immutable) GetIDClosure() (map[descpb.ID]struct{}, error) {
 		for id := range children {
 			ret[id] = struct{}{}
 		}
-	} else {
+	case descpb.TypeDescriptor_DOMAIN:
+		// A domain has no array type, but references its base type.
+		children, err := GetTypeDescriptorClosure(desc.Domain.BaseType)
+		if err != nil {
+			return nil, err
+		}
+		for id := range children {
+			ret[id] = struct{}{}
+		}
+	default:
 		// Otherwise, take the array type ID.
 		ret[desc.ArrayTypeID] = struct{}{}
 	}
@@ -1044,6 +1179,10 @@ func GetTypeDescriptorClosure(typ *types.T) (map[descpb.ID]struct{}, error) {
 	ret := map[descpb.ID]struct{}{
 		id: {},
 	}
+	if typ.IsDomain() {
+		// Domains have no array type, and their base type is builtin.
+		return ret, nil
+	}
 	switch typ.Family() {
 	case types.ArrayFamily:
 		// If we have an array type, then collect all types in the contents.
@@ -1055,6 +1194,14 @@ func GetTypeDescriptorClosure(typ *types.T) (map[descpb.ID]struct{}, error) {
 			ret[id] = struct{}{}
 		}
 	case types.TupleFamily:
+		// If we have a composite type, collect its array type.
+		if typ.IsCompositeType() {
+			id, err := GetUserDefinedArrayTypeDescID(typ)
+			if err != nil {
+				return nil, err
+			}
+			ret[id] = struct{}{}
+		}
 		// If we have a tuple type, collect all types in the contents.
 		for _, elt := range typ.TupleContents() {
 			children, err := GetTypeDescriptorClosure(elt)
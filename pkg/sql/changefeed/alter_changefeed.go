@@ -0,0 +1,236 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// alterChangefeedImmutableOptions are the options which cannot be changed by
+// ALTER CHANGEFEED, as they only apply when a changefeed starts.
+var alterChangefeedImmutableOptions = map[string]struct{}{
+	optCursor:        {},
+	optInitialScan:   {},
+	optNoInitialScan: {},
+}
+
+// alterChangefeedDetails applies the commands of an ALTER CHANGEFEED statement
+// to the details of a changefeed job.
+func alterChangefeedDetails(
+	ctx context.Context,
+	p sql.PlanHookState,
+	details jobspb.ChangefeedDetails,
+	cmds tree.AlterChangefeedCmds,
+) (jobspb.ChangefeedDetails, error) {
+	opts := make(map[string]string, len(details.Opts))
+	for k, v := range details.Opts {
+		opts[k] = v
+	}
+	tables := make(map[descpb.ID]jobspb.ChangefeedTargetTable, len(details.Tables))
+	for id, table := range details.Tables {
+		tables[id] = table
+	}
+	specs := append([]jobspb.ChangefeedTargetSpecification(nil), details.TargetSpecifications...)
+
+	for _, cmd := range cmds {
+		switch cmd := cmd.(type) {
+		case *tree.AlterChangefeedAddTarget:
+			if len(cmd.Options) > 0 {
+				return details, pgerror.New(pgcode.FeatureNotSupported,
+					"ALTER CHANGEFEED ADD does not support options: added tables are watched "+
+						"from the high-water mark of the changefeed, without an initial scan")
+			}
+			added, addedSpecs, err := resolveChangefeedTargets(ctx, p, cmd.Targets)
+			if err != nil {
+				return details, err
+			}
+			for _, spec := range addedSpecs {
+				if _, ok := tables[spec.TableID]; ok {
+					return details, pgerror.Newf(pgcode.DuplicateObject,
+						"target %q already watched by changefeed", spec.StatementTimeName)
+				}
+				tables[spec.TableID] = added[spec.TableID]
+				specs = append(specs, spec)
+			}
+
+		case *tree.AlterChangefeedDropTarget:
+			dropped, _, err := resolveChangefeedTargets(ctx, p, cmd.Targets)
+			if err != nil {
+				return details, err
+			}
+			for id, table := range dropped {
+				if _, ok := tables[id]; !ok {
+					return details, pgerror.Newf(pgcode.InvalidParameterValue,
+						"target %q is not watched by changefeed", table.StatementTimeName)
+				}
+				delete(tables, id)
+			}
+			remaining := specs[:0]
+			for _, spec := range specs {
+				if _, ok := dropped[spec.TableID]; !ok {
+					remaining = append(remaining, spec)
+				}
+			}
+			specs = remaining
+			if len(specs) == 0 {
+				return details, pgerror.New(pgcode.InvalidParameterValue,
+					"cannot drop all targets of a changefeed")
+			}
+
+		case *tree.AlterChangefeedSetOptions:
+			optsFn, err := p.TypeAsStringOpts(ctx, cmd.Options, changefeedOptionExpectValues)
+			if err != nil {
+				return details, err
+			}
+			set, err := optsFn()
+			if err != nil {
+				return details, err
+			}
+			for k, v := range set {
+				if _, ok := alterChangefeedImmutableOptions[k]; ok {
+					return details, pgerror.Newf(pgcode.InvalidParameterValue,
+						"cannot alter option %q", k)
+				}
+				opts[k] = v
+			}
+
+		case *tree.AlterChangefeedUnsetOptions:
+			for _, name := range cmd.Options {
+				k := string(name)
+				if _, ok := changefeedOptionExpectValues[k]; !ok {
+					return details, pgerror.Newf(pgcode.InvalidParameterValue, "invalid option %q", k)
+				}
+				if _, ok := alterChangefeedImmutableOptions[k]; ok {
+					return details, pgerror.Newf(pgcode.InvalidParameterValue,
+						"cannot alter option %q", k)
+				}
+				if k == optKeyInValue || (k == optTopicInValue && isWebhookSinkURI(details.SinkURI)) {
+					return details, pgerror.Newf(pgcode.InvalidParameterValue,
+						"option %q is required by the sink of the changefeed", k)
+				}
+				delete(opts, k)
+			}
+
+		default:
+			return details, errors.AssertionFailedf("unknown ALTER CHANGEFEED command %T", cmd)
+		}
+	}
+
+	if _, err := parseChangefeedOptions(opts); err != nil {
+		return details, err
+	}
+	details.Opts = opts
+	details.Tables = tables
+	details.TargetSpecifications = specs
+	return details, nil
+}
+
+func alterChangefeedPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	alterStmt, ok := stmt.(*tree.AlterChangefeed)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureChangefeedEnabled,
+		"ALTER CHANGEFEED",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	typedJobID, err := tree.TypeCheckAndRequire(ctx, alterStmt.Jobs, p.SemaCtx(), types.Int, "ALTER CHANGEFEED")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		d, err := typedJobID.Eval(&p.ExtendedEvalContext().EvalContext)
+		if err != nil {
+			return err
+		}
+		if d == tree.DNull {
+			return pgerror.New(pgcode.InvalidParameterValue, "job ID must not be NULL")
+		}
+		jobID := jobspb.JobID(tree.MustBeDInt(d))
+
+		txn := p.ExtendedEvalContext().Txn
+		job, err := p.ExecCfg().JobRegistry.LoadJobWithTxn(ctx, jobID, txn)
+		if err != nil {
+			return err
+		}
+		details, ok := job.Details().(jobspb.ChangefeedDetails)
+		if !ok {
+			return pgerror.Newf(pgcode.InvalidParameterValue, "job %d is not a changefeed job", jobID)
+		}
+		if payload := job.Payload(); payload.UsernameProto.Decode() != p.User() {
+			isAdmin, err := p.HasAdminRole(ctx)
+			if err != nil {
+				return err
+			}
+			if !isAdmin {
+				return pgerror.Newf(pgcode.InsufficientPrivilege,
+					"only admins or the owner of job %d may alter it", jobID)
+			}
+		}
+
+		newDetails, err := alterChangefeedDetails(ctx, p, details, alterStmt.Cmds)
+		if err != nil {
+			return err
+		}
+		description, err := changefeedJobDescription(newDetails)
+		if err != nil {
+			return err
+		}
+		if err := p.ExecCfg().JobRegistry.UpdateJobWithTxn(ctx, jobID, txn, true, /* useReadLock */
+			func(txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+				// A running changefeed only reads its details when it starts.
+				if md.Status != jobs.StatusPaused {
+					return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+						"job %d is not paused", jobID)
+				}
+				md.Payload.Details = jobspb.WrapPayloadDetails(newDetails)
+				md.Payload.Description = description
+				md.Payload.DescriptorIDs = changefeedDescriptorIDs(newDetails)
+				ju.UpdatePayload(md.Payload)
+				return nil
+			}); err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+		return nil
+	}
+	return fn, jobs.DetachedJobExecutionResultHeader, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook("alter changefeed", alterChangefeedPlanHook)
+}
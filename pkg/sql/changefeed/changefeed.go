@@ -0,0 +1,210 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// changefeedEventBufferSize is the number of rangefeed events buffered between
+// the rangefeed and the goroutine emitting them to the sink.
+const changefeedEventBufferSize = 256
+
+// changefeedEvent is either a row change received from the rangefeed or an
+// advance of the rangefeed's frontier.
+type changefeedEvent struct {
+	value    *roachpb.RangeFeedValue
+	resolved hlc.Timestamp
+}
+
+// changefeed streams the changes to the primary indexes of a set of tables
+// to a sink.
+type changefeed struct {
+	execCfg *sql.ExecutorConfig
+	details jobspb.ChangefeedDetails
+	opts    changefeedOptions
+	sink    sink
+	// onResolved, if set, is called after a resolved timestamp has been
+	// reached and all the rows at or below it have been flushed to the sink.
+	onResolved func(ctx context.Context, resolved hlc.Timestamp) error
+
+	decoder      *rowDecoder
+	encoder      *jsonEncoder
+	lastResolved time.Time
+}
+
+func newChangefeed(
+	execCfg *sql.ExecutorConfig,
+	details jobspb.ChangefeedDetails,
+	opts changefeedOptions,
+	sink sink,
+) *changefeed {
+	return &changefeed{
+		execCfg: execCfg,
+		details: details,
+		opts:    opts,
+		sink:    sink,
+		decoder: makeRowDecoder(execCfg.Codec, execCfg.LeaseManager),
+		encoder: makeJSONEncoder(opts),
+	}
+}
+
+// spans returns the primary index spans of the watched tables.
+func (cf *changefeed) spans() []roachpb.Span {
+	spans := make([]roachpb.Span, 0, len(cf.details.TargetSpecifications))
+	for _, target := range cf.details.TargetSpecifications {
+		// The primary index may change with schema changes, so watch the whole
+		// table and ignore the KVs of other indexes when decoding.
+		prefix := cf.execCfg.Codec.TablePrefix(uint32(target.TableID))
+		spans = append(spans, roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()})
+	}
+	return spans
+}
+
+// run runs the changefeed from its statement time until ctx is canceled or an
+// error occurs.
+func (cf *changefeed) run(ctx context.Context) error {
+	events := make(chan changefeedEvent, changefeedEventBufferSize)
+	errCh := make(chan error, 1)
+	push := func(ctx context.Context, ev changefeedEvent) {
+		select {
+		case events <- ev:
+		case <-ctx.Done():
+		}
+	}
+
+	opts := []rangefeed.Option{
+		rangefeed.WithDiff(cf.opts.diff),
+		rangefeed.WithOnFrontierAdvance(func(ctx context.Context, frontier hlc.Timestamp) {
+			push(ctx, changefeedEvent{resolved: frontier})
+		}),
+		rangefeed.WithOnInternalError(func(ctx context.Context, err error) {
+			select {
+			case errCh <- err:
+			default:
+			}
+		}),
+	}
+	if cf.opts.initialScan {
+		opts = append(opts,
+			rangefeed.WithInitialScan(nil),
+			rangefeed.WithRowTimestampInInitialScan(true),
+		)
+	}
+	feed, err := cf.execCfg.RangeFeedFactory.RangeFeed(ctx, "changefeed", cf.spans(),
+		cf.details.StatementTime,
+		func(ctx context.Context, value *roachpb.RangeFeedValue) {
+			v := *value
+			push(ctx, changefeedEvent{value: &v})
+		},
+		opts...,
+	)
+	if err != nil {
+		return err
+	}
+	defer feed.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errCh:
+			return err
+		case ev := <-events:
+			if ev.value != nil {
+				err = cf.emitRow(ctx, ev.value)
+			} else if err = cf.checkTargets(ctx, ev.resolved); err == nil {
+				err = cf.maybeEmitResolved(ctx, ev.resolved)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// emitRow decodes, encodes and emits a row change.
+func (cf *changefeed) emitRow(ctx context.Context, value *roachpb.RangeFeedValue) error {
+	ts := value.Value.Timestamp
+	r, ok, err := cf.decoder.decode(ctx, value.Key, value.Value, ts)
+	if err != nil || !ok {
+		return err
+	}
+	var prev decodedRow
+	// Rows of the initial scan have no previous value, even though the
+	// rangefeed reports their value as the previous one.
+	if cf.opts.diff && value.PrevValue.IsPresent() && cf.details.StatementTime.Less(ts) {
+		if prev, _, err = cf.decoder.decode(ctx, value.Key, value.PrevValue, ts.Prev()); err != nil {
+			return err
+		}
+	}
+	table := cf.tableName(r.table.GetID())
+	key, err := cf.encoder.encodeKey(r)
+	if err != nil {
+		return err
+	}
+	payload, err := cf.encoder.encodeValue(table, r, prev)
+	if err != nil {
+		return err
+	}
+	return cf.sink.emitRow(ctx, table, key, payload, ts)
+}
+
+// tableName returns the name of a watched table at the time the changefeed
+// was created, which is used in its messages even if the table is renamed.
+func (cf *changefeed) tableName(id descpb.ID) string {
+	return cf.details.Tables[id].StatementTimeName
+}
+
+// checkTargets returns an error if any of the watched tables can no longer be
+// watched as of ts, e.g. because it was dropped.
+func (cf *changefeed) checkTargets(ctx context.Context, ts hlc.Timestamp) error {
+	for _, target := range cf.details.TargetSpecifications {
+		if _, err := cf.decoder.tableAt(ctx, target.TableID, ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maybeEmitResolved flushes the sink and emits a resolved timestamp, unless
+// one was emitted more recently than the configured minimum interval.
+func (cf *changefeed) maybeEmitResolved(ctx context.Context, resolved hlc.Timestamp) error {
+	if cf.onResolved == nil && !cf.opts.resolved {
+		return nil
+	}
+	now := timeutil.Now()
+	if now.Sub(cf.lastResolved) < cf.opts.minResolvedInterval {
+		return nil
+	}
+	if err := cf.sink.flush(ctx); err != nil {
+		return err
+	}
+	if cf.opts.resolved {
+		if err := cf.sink.emitResolved(ctx, cf.encoder.encodeResolved(resolved), resolved); err != nil {
+			return err
+		}
+	}
+	cf.lastResolved = now
+	if cf.onResolved != nil {
+		return cf.onResolved(ctx, resolved)
+	}
+	return nil
+}
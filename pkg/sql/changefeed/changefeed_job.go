@@ -0,0 +1,117 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// checkpointFrequency controls how often a changefeed job checkpoints its
+// high-water mark.
+var checkpointFrequency = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"changefeed.frontier_checkpoint_frequency",
+	"controls the frequency with which changefeed jobs checkpoint their high-water mark; "+
+		"the changes after the last checkpoint are emitted again when a job is resumed",
+	10*time.Second,
+	settings.NonNegativeDuration,
+)
+
+// changefeedResumer runs a changefeed with a sink as a job.
+type changefeedResumer struct {
+	job      *jobs.Job
+	settings *cluster.Settings
+
+	lastCheckpoint time.Time
+}
+
+var _ jobs.Resumer = (*changefeedResumer)(nil)
+
+// Resume is part of the jobs.Resumer interface.
+func (r *changefeedResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	details := r.job.Details().(jobspb.ChangefeedDetails)
+	opts, err := parseChangefeedOptions(details.Opts)
+	if err != nil {
+		return err
+	}
+	// A resumed job picks up from its last checkpoint, after which its initial
+	// scan, if any, has completed.
+	progress := r.job.Progress()
+	if highWater := progress.GetHighWater(); highWater != nil && !highWater.IsEmpty() {
+		details.StatementTime = *highWater
+		opts.initialScan = false
+	}
+
+	s, err := makeSink(ctx, execCfg, details.SinkURI, r.job.ID(), p.User())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := s.close(); err != nil {
+			log.Warningf(ctx, "failed to close changefeed sink: %v", err)
+		}
+	}()
+
+	cf := newChangefeed(execCfg, details, opts, s)
+	cf.onResolved = r.checkpoint
+	return cf.run(ctx)
+}
+
+// checkpoint records resolved as the high-water mark of the job, unless a
+// checkpoint was recorded more recently than checkpointFrequency. All the rows
+// changed at or before resolved have been delivered to the sink.
+func (r *changefeedResumer) checkpoint(ctx context.Context, resolved hlc.Timestamp) error {
+	now := timeutil.Now()
+	if now.Sub(r.lastCheckpoint) < checkpointFrequency.Get(&r.settings.SV) {
+		return nil
+	}
+	if err := r.job.Update(ctx, nil /* txn */, func(
+		txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater,
+	) error {
+		return jobs.UpdateHighwaterProgressed(resolved, md, ju)
+	}); err != nil {
+		return err
+	}
+	r.lastCheckpoint = now
+	return nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. A changefeed leaves
+// nothing to clean up: the messages it delivered stay with their recipients.
+func (r *changefeedResumer) OnFailOrCancel(context.Context, interface{}) error {
+	return nil
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeChangefeed,
+		func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
+			return &changefeedResumer{
+				job:      job,
+				settings: settings,
+			}
+		},
+		jobs.UsesTenantCostControl,
+	)
+}
@@ -0,0 +1,277 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed_test
+
+import (
+	"bufio"
+	gosql "database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func startChangefeedJobTestServer(t *testing.T) (string, *sqlutils.SQLRunner, func()) {
+	dir, cleanupDir := testutils.TempDir(t)
+	_, sqlDB, cleanup := startChangefeedTestServer(t, base.TestServerArgs{
+		ExternalIODir: dir,
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+		},
+	})
+	sqlDB.Exec(t, `SET CLUSTER SETTING changefeed.frontier_checkpoint_frequency = '10ms'`)
+	return dir, sqlDB, func() {
+		cleanup()
+		cleanupDir()
+	}
+}
+
+// fileSinkContents returns the rows written by a file sink to dir, and the
+// number of resolved timestamp files.
+func fileSinkContents(t *testing.T, dir string) (rows map[string]struct{}, resolved int) {
+	rows = make(map[string]struct{})
+	require.NoError(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if strings.HasSuffix(path, ".RESOLVED") {
+			resolved++
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			rows[scanner.Text()] = struct{}{}
+		}
+		return scanner.Err()
+	}))
+	return rows, resolved
+}
+
+// waitForRows waits until all the expected rows have been delivered at least
+// once.
+func waitForRows(t *testing.T, delivered func() map[string]struct{}, expected ...string) {
+	testutils.SucceedsSoon(t, func() error {
+		rows := delivered()
+		for _, row := range expected {
+			if _, ok := rows[row]; !ok {
+				return errors.Errorf("row %s not delivered, got %v", row, rows)
+			}
+		}
+		return nil
+	})
+}
+
+func TestChangefeedJobFileSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, sqlDB, cleanup := startChangefeedJobTestServer(t)
+	defer cleanup()
+
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'one')`)
+
+	var jobID jobspb.JobID
+	sqlDB.QueryRow(t, `CREATE CHANGEFEED FOR foo INTO 'nodelocal://0/feed' WITH resolved`).Scan(&jobID)
+	delivered := func() map[string]struct{} {
+		rows, _ := fileSinkContents(t, filepath.Join(dir, "feed"))
+		return rows
+	}
+	waitForRows(t, delivered, `{"after": {"a": 1, "b": "one"}, "key": [1]}`)
+
+	testutils.SucceedsSoon(t, func() error {
+		var highWater gosql.NullString
+		sqlDB.QueryRow(t,
+			`SELECT high_water_timestamp FROM crdb_internal.jobs WHERE job_id = $1`, jobID,
+		).Scan(&highWater)
+		if !highWater.Valid {
+			return errors.New("no high-water mark checkpointed")
+		}
+		if _, resolved := fileSinkContents(t, filepath.Join(dir, "feed")); resolved == 0 {
+			return errors.New("no resolved timestamp written")
+		}
+		return nil
+	})
+
+	// Changes made while the job is paused are delivered once it resumes.
+	sqlDB.Exec(t, `PAUSE JOB $1`, jobID)
+	jobutils.WaitForJobToPause(t, sqlDB, jobID)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'two')`)
+	sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+	sqlDB.Exec(t, `RESUME JOB $1`, jobID)
+	waitForRows(t, delivered,
+		`{"after": {"a": 2, "b": "two"}, "key": [2]}`,
+		`{"after": null, "key": [1]}`,
+	)
+
+	sqlDB.Exec(t, `CANCEL JOB $1`, jobID)
+	jobutils.WaitForJobToCancel(t, sqlDB, jobID)
+}
+
+// webhookServer is a webhook endpoint recording the messages it receives.
+type webhookServer struct {
+	*httptest.Server
+	mu struct {
+		syncutil.Mutex
+		// failures is the number of requests left to fail.
+		failures int
+		rows     map[string]struct{}
+	}
+}
+
+func startWebhookServer(failures int) *webhookServer {
+	s := &webhookServer{}
+	s.mu.failures = failures
+	s.mu.rows = make(map[string]struct{})
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.mu.failures > 0 {
+			s.mu.failures--
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var batch struct {
+			Payload []json.RawMessage `json:"payload"`
+			Length  int               `json:"length"`
+		}
+		if err := json.Unmarshal(body, &batch); err != nil || batch.Length != len(batch.Payload) {
+			http.Error(w, fmt.Sprintf("invalid batch %q", body), http.StatusBadRequest)
+			return
+		}
+		for _, msg := range batch.Payload {
+			s.mu.rows[string(msg)] = struct{}{}
+		}
+	}))
+	return s
+}
+
+// sinkURI returns the URI of a changefeed sink delivering to the server.
+func (s *webhookServer) sinkURI() string {
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+	return fmt.Sprintf("webhook-%s/hook?ca_cert=%s",
+		s.URL, url.QueryEscape(base64.StdEncoding.EncodeToString(cert)))
+}
+
+func (s *webhookServer) rows() map[string]struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := make(map[string]struct{}, len(s.mu.rows))
+	for row := range s.mu.rows {
+		rows[row] = struct{}{}
+	}
+	return rows
+}
+
+func TestChangefeedJobWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	_, sqlDB, cleanup := startChangefeedJobTestServer(t)
+	defer cleanup()
+
+	// The first requests fail, and are retried until they succeed.
+	srv := startWebhookServer(3 /* failures */)
+	defer srv.Close()
+
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'one')`)
+
+	var jobID jobspb.JobID
+	sqlDB.QueryRow(t, `CREATE CHANGEFEED FOR foo INTO $1`, srv.sinkURI()).Scan(&jobID)
+	waitForRows(t, srv.rows, `{"after": {"a": 1, "b": "one"}, "key": [1], "topic": "foo"}`)
+
+	sqlDB.Exec(t, `UPSERT INTO foo VALUES (1, 'uno'), (2, 'two')`)
+	waitForRows(t, srv.rows,
+		`{"after": {"a": 1, "b": "uno"}, "key": [1], "topic": "foo"}`,
+		`{"after": {"a": 2, "b": "two"}, "key": [2], "topic": "foo"}`,
+	)
+
+	sqlDB.Exec(t, `CANCEL JOB $1`, jobID)
+	jobutils.WaitForJobToCancel(t, sqlDB, jobID)
+
+	sqlDB.ExpectErr(t, `webhook sinks require webhook-https URIs`,
+		`CREATE CHANGEFEED FOR foo INTO 'webhook-http://localhost/hook'`)
+	sqlDB.ExpectErr(t, `ca_cert must be base64 encoded`,
+		`CREATE CHANGEFEED FOR foo INTO 'webhook-https://localhost/hook?ca_cert=***'`)
+}
+
+func TestAlterChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, sqlDB, cleanup := startChangefeedJobTestServer(t)
+	defer cleanup()
+
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `CREATE TABLE bar (b INT PRIMARY KEY)`)
+
+	var jobID jobspb.JobID
+	sqlDB.QueryRow(t, `CREATE CHANGEFEED FOR foo INTO 'nodelocal://0/feed'`).Scan(&jobID)
+	sqlDB.ExpectErr(t, fmt.Sprintf(`job %d is not paused`, jobID),
+		`ALTER CHANGEFEED $1 ADD bar`, jobID)
+
+	sqlDB.Exec(t, `PAUSE JOB $1`, jobID)
+	jobutils.WaitForJobToPause(t, sqlDB, jobID)
+
+	sqlDB.ExpectErr(t, `cannot alter option "initial_scan"`,
+		`ALTER CHANGEFEED $1 SET initial_scan`, jobID)
+	sqlDB.ExpectErr(t, `option "key_in_value" is required by the sink of the changefeed`,
+		`ALTER CHANGEFEED $1 UNSET key_in_value`, jobID)
+	sqlDB.ExpectErr(t, `cannot drop all targets of a changefeed`,
+		`ALTER CHANGEFEED $1 DROP foo`, jobID)
+	sqlDB.ExpectErr(t, `target "foo" already watched by changefeed`,
+		`ALTER CHANGEFEED $1 ADD foo`, jobID)
+
+	sqlDB.Exec(t, `ALTER CHANGEFEED $1 ADD bar SET updated`, jobID)
+	sqlDB.CheckQueryResults(t,
+		`SELECT description FROM [SHOW JOBS] WHERE job_id = $1`,
+		[][]string{{`CREATE CHANGEFEED FOR TABLE foo, TABLE bar INTO 'nodelocal://0/feed' WITH key_in_value, updated`}},
+	)
+	sqlDB.Exec(t, `ALTER CHANGEFEED $1 DROP foo UNSET updated`, jobID)
+
+	sqlDB.Exec(t, `RESUME JOB $1`, jobID)
+	sqlDB.Exec(t, `INSERT INTO bar VALUES (1)`)
+	waitForRows(t, func() map[string]struct{} {
+		rows, _ := fileSinkContents(t, filepath.Join(dir, "feed"))
+		return rows
+	}, `{"after": {"b": 1}, "key": [1]}`)
+}
@@ -0,0 +1,400 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/docs"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// featureChangefeedEnabled is used to enable and disable the CHANGEFEED
+// feature.
+var featureChangefeedEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"feature.changefeed.enabled",
+	"set to true to enable changefeeds, false to disable; default is true",
+	featureflag.FeatureFlagEnabledDefault,
+).WithPublic()
+
+const (
+	optUpdated       = "updated"
+	optResolved      = "resolved"
+	optDiff          = "diff"
+	optFormat        = "format"
+	optCursor        = "cursor"
+	optInitialScan   = "initial_scan"
+	optNoInitialScan = "no_initial_scan"
+	optKeyInValue    = "key_in_value"
+	optTopicInValue  = "topic_in_value"
+
+	optFormatJSON = "json"
+)
+
+var changefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
+	optUpdated:       sql.KVStringOptRequireNoValue,
+	optResolved:      sql.KVStringOptAny,
+	optDiff:          sql.KVStringOptRequireNoValue,
+	optFormat:        sql.KVStringOptRequireValue,
+	optCursor:        sql.KVStringOptRequireValue,
+	optInitialScan:   sql.KVStringOptRequireNoValue,
+	optNoInitialScan: sql.KVStringOptRequireNoValue,
+	optKeyInValue:    sql.KVStringOptRequireNoValue,
+	optTopicInValue:  sql.KVStringOptRequireNoValue,
+}
+
+// changefeedOptions are the parsed options of a changefeed.
+type changefeedOptions struct {
+	updated bool
+	diff    bool
+	// resolved is set if resolved timestamps are emitted, at most once per
+	// minResolvedInterval.
+	resolved            bool
+	minResolvedInterval time.Duration
+	initialScan         bool
+	keyInValue          bool
+	topicInValue        bool
+}
+
+// parseChangefeedOptions parses the options of a changefeed, as stored in its
+// details.
+func parseChangefeedOptions(opts map[string]string) (changefeedOptions, error) {
+	var res changefeedOptions
+	_, res.updated = opts[optUpdated]
+	_, res.diff = opts[optDiff]
+	_, res.keyInValue = opts[optKeyInValue]
+	_, res.topicInValue = opts[optTopicInValue]
+	var interval string
+	if interval, res.resolved = opts[optResolved]; res.resolved && interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return res, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+				"invalid duration value for %s: %q", optResolved, interval)
+		}
+		if d < 0 {
+			return res, pgerror.Newf(pgcode.InvalidParameterValue,
+				"negative durations are not accepted: %s='%s'", optResolved, interval)
+		}
+		res.minResolvedInterval = d
+	}
+	if format, ok := opts[optFormat]; ok && format != optFormatJSON {
+		return res, pgerror.Newf(pgcode.InvalidParameterValue,
+			"unknown %s: %s", optFormat, format)
+	}
+	_, initialScan := opts[optInitialScan]
+	_, noInitialScan := opts[optNoInitialScan]
+	if initialScan && noInitialScan {
+		return res, pgerror.Newf(pgcode.InvalidParameterValue,
+			"cannot specify both %s and %s", optInitialScan, optNoInitialScan)
+	}
+	// A changefeed starting from a cursor only emits the changes after it,
+	// unless an initial scan is explicitly requested.
+	_, hasCursor := opts[optCursor]
+	res.initialScan = initialScan || !(noInitialScan || hasCursor)
+	return res, nil
+}
+
+// resolveChangefeedTargets resolves the tables watched by a changefeed and
+// checks that the user may watch them.
+func resolveChangefeedTargets(
+	ctx context.Context, p sql.PlanHookState, targets tree.ChangefeedTargets,
+) (map[descpb.ID]jobspb.ChangefeedTargetTable, []jobspb.ChangefeedTargetSpecification, error) {
+	tables := make(map[descpb.ID]jobspb.ChangefeedTargetTable, len(targets))
+	var specs []jobspb.ChangefeedTargetSpecification
+	for _, target := range targets {
+		if target.FamilyName != "" {
+			return nil, nil, pgerror.New(pgcode.FeatureNotSupported,
+				"CHANGEFEED targeting column families is not supported")
+		}
+		pattern, err := target.TableName.NormalizeTablePattern()
+		if err != nil {
+			return nil, nil, err
+		}
+		tn, ok := pattern.(*tree.TableName)
+		if !ok {
+			return nil, nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"CHANGEFEED cannot target %s: wildcards are not supported", tree.AsString(pattern))
+		}
+		_, table, err := resolver.ResolveExistingTableObject(ctx, p, tn, tree.ObjectLookupFlagsWithRequired())
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := validateTable(table); err != nil {
+			return nil, nil, err
+		}
+		if err := p.CheckPrivilege(ctx, table, privilege.SELECT); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := tables[table.GetID()]; ok {
+			continue
+		}
+		tables[table.GetID()] = jobspb.ChangefeedTargetTable{StatementTimeName: table.GetName()}
+		specs = append(specs, jobspb.ChangefeedTargetSpecification{
+			Type:              jobspb.ChangefeedTargetSpecification_PRIMARY_FAMILY_ONLY,
+			TableID:           table.GetID(),
+			StatementTimeName: table.GetName(),
+		})
+	}
+	return tables, specs, nil
+}
+
+// makeChangefeedDetails resolves the targets and options of a changefeed.
+func makeChangefeedDetails(
+	ctx context.Context,
+	p sql.PlanHookState,
+	stmt *tree.CreateChangefeed,
+	opts map[string]string,
+	sinkURI string,
+) (jobspb.ChangefeedDetails, changefeedOptions, error) {
+	if !kvserver.RangefeedEnabled.Get(&p.ExecCfg().Settings.SV) {
+		return jobspb.ChangefeedDetails{}, changefeedOptions{}, errors.Errorf(
+			"rangefeeds require the kv.rangefeed.enabled setting. See %s",
+			docs.URL(`change-data-capture.html#enable-rangefeeds-to-reduce-latency`))
+	}
+	// Messages delivered to a sink rather than over the SQL connection carry
+	// their key in their value, as files and webhook requests have no separate
+	// key. Webhook messages from all the tables are batched together, so they
+	// also carry their table.
+	if sinkURI != "" {
+		opts[optKeyInValue] = ""
+		if isWebhookSinkURI(sinkURI) {
+			opts[optTopicInValue] = ""
+		}
+	}
+	parsed, err := parseChangefeedOptions(opts)
+	if err != nil {
+		return jobspb.ChangefeedDetails{}, changefeedOptions{}, err
+	}
+
+	statementTime := p.ExecCfg().Clock.Now()
+	if cursor, ok := opts[optCursor]; ok {
+		asOf := tree.AsOfClause{Expr: tree.NewStrVal(cursor)}
+		ts, err := p.EvalAsOfTimestamp(ctx, asOf)
+		if err != nil {
+			return jobspb.ChangefeedDetails{}, changefeedOptions{}, err
+		}
+		statementTime = ts.Timestamp
+	}
+
+	tables, specs, err := resolveChangefeedTargets(ctx, p, stmt.Targets)
+	if err != nil {
+		return jobspb.ChangefeedDetails{}, changefeedOptions{}, err
+	}
+	return jobspb.ChangefeedDetails{
+		Tables:               tables,
+		SinkURI:              sinkURI,
+		Opts:                 opts,
+		StatementTime:        statementTime,
+		TargetSpecifications: specs,
+	}, parsed, nil
+}
+
+// changefeedJobDescription returns the description of a changefeed job,
+// which is the CREATE CHANGEFEED statement it runs with the secrets of its sink
+// URI redacted.
+func changefeedJobDescription(details jobspb.ChangefeedDetails) (string, error) {
+	sinkURI, err := cloud.SanitizeExternalStorageURI(details.SinkURI, nil /* extraParams */)
+	if err != nil {
+		return "", err
+	}
+	stmt := &tree.CreateChangefeed{SinkURI: tree.NewDString(sinkURI)}
+	for _, target := range details.TargetSpecifications {
+		stmt.Targets = append(stmt.Targets, tree.ChangefeedTarget{
+			TableName: tree.NewUnqualifiedTableName(tree.Name(target.StatementTimeName)),
+		})
+	}
+	keys := make([]string, 0, len(details.Opts))
+	for k := range details.Opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		opt := tree.KVOption{Key: tree.Name(k)}
+		if v := details.Opts[k]; v != "" {
+			opt.Value = tree.NewDString(v)
+		}
+		stmt.Options = append(stmt.Options, opt)
+	}
+	return tree.AsString(stmt), nil
+}
+
+// createChangefeedJob creates the job running a changefeed with a sink and
+// writes its ID to resultsCh. In an implicit transaction, the job is started
+// right away; otherwise it is adopted once the transaction commits.
+func createChangefeedJob(
+	ctx context.Context,
+	p sql.PlanHookState,
+	details jobspb.ChangefeedDetails,
+	resultsCh chan<- tree.Datums,
+) error {
+	// Check that the sink URI is valid, and that the user may write to it,
+	// before creating the job.
+	s, err := makeSink(ctx, p.ExecCfg(), details.SinkURI, 0 /* jobID */, p.User())
+	if err != nil {
+		return err
+	}
+	if err := s.close(); err != nil {
+		return err
+	}
+
+	description, err := changefeedJobDescription(details)
+	if err != nil {
+		return err
+	}
+	jr := jobs.Record{
+		Description:   description,
+		Username:      p.User(),
+		DescriptorIDs: changefeedDescriptorIDs(details),
+		Details:       details,
+		Progress:      jobspb.ChangefeedProgress{},
+	}
+	registry := p.ExecCfg().JobRegistry
+	jobID := registry.MakeJobID()
+
+	if !p.ExtendedEvalContext().TxnImplicit {
+		if _, err := registry.CreateAdoptableJobWithTxn(ctx, jr, jobID, p.ExtendedEvalContext().Txn); err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+		return nil
+	}
+
+	plannerTxn := p.ExtendedEvalContext().Txn
+	var sj *jobs.StartableJob
+	if err := func() (err error) {
+		defer func() {
+			if err == nil || sj == nil {
+				return
+			}
+			if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+				log.Errorf(ctx, "failed to cleanup job: %v", cleanupErr)
+			}
+		}()
+		if err := registry.CreateStartableJobWithTxn(ctx, &sj, jobID, plannerTxn, jr); err != nil {
+			return err
+		}
+		// We commit the transaction here so that the job can be started. This
+		// is safe because we're in an implicit transaction.
+		return plannerTxn.Commit(ctx)
+	}(); err != nil {
+		return err
+	}
+	if err := sj.Start(ctx); err != nil {
+		return err
+	}
+	resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+	return nil
+}
+
+// changefeedDescriptorIDs returns the IDs of the tables watched by a
+// changefeed.
+func changefeedDescriptorIDs(details jobspb.ChangefeedDetails) descpb.IDs {
+	ids := make(descpb.IDs, 0, len(details.TargetSpecifications))
+	for _, target := range details.TargetSpecifications {
+		ids = append(ids, target.TableID)
+	}
+	return ids
+}
+
+func changefeedPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	changefeedStmt, ok := stmt.(*tree.CreateChangefeed)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureChangefeedEnabled,
+		"CHANGEFEED",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	var sinkURIFn func() (string, error)
+	if changefeedStmt.SinkURI != nil {
+		var err error
+		sinkURIFn, err = p.TypeAsString(ctx, changefeedStmt.SinkURI, "CHANGEFEED")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	optsFn, err := p.TypeAsStringOpts(ctx, changefeedStmt.Options, changefeedOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		if sinkURIFn != nil {
+			sinkURI, err := sinkURIFn()
+			if err != nil {
+				return err
+			}
+			if sinkURI == "" {
+				return pgerror.New(pgcode.InvalidParameterValue, "sink URI must not be empty")
+			}
+			details, _, err := makeChangefeedDetails(ctx, p, changefeedStmt, opts, sinkURI)
+			if err != nil {
+				return err
+			}
+			return createChangefeedJob(ctx, p, details, resultsCh)
+		}
+
+		if !p.ExtendedEvalContext().TxnImplicit {
+			return errors.Errorf("%s cannot be used inside a transaction", stmt.StatementTag())
+		}
+		details, parsed, err := makeChangefeedDetails(ctx, p, changefeedStmt, opts, "" /* sinkURI */)
+		if err != nil {
+			return err
+		}
+
+		cf := newChangefeed(p.ExecCfg(), details, parsed, &sinklessSink{resultsCh: resultsCh})
+		return cf.run(ctx)
+	}
+	if sinkURIFn != nil {
+		return fn, jobs.DetachedJobExecutionResultHeader, nil, false, nil
+	}
+	// Changefeed messages must reach the client as soon as they are emitted.
+	return fn, sinklessResultColumns, nil, true /* avoidBuffering */, nil
+}
+
+func init() {
+	sql.AddPlanHook("changefeed", changefeedPlanHook)
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// sinklessFeed reads the messages of a sinkless changefeed.
+type sinklessFeed struct {
+	cancel func()
+	rows   *gosql.Rows
+}
+
+type feedMessage struct {
+	table      gosql.NullString
+	key, value []byte
+}
+
+func startSinklessFeed(t *testing.T, db *gosql.DB, stmt string) *sinklessFeed {
+	ctx, cancel := context.WithCancel(context.Background())
+	rows, err := db.QueryContext(ctx, stmt)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	return &sinklessFeed{cancel: cancel, rows: rows}
+}
+
+func (f *sinklessFeed) next(t *testing.T) feedMessage {
+	if !f.rows.Next() {
+		t.Fatalf("changefeed ended: %v", f.rows.Err())
+	}
+	var m feedMessage
+	require.NoError(t, f.rows.Scan(&m.table, &m.key, &m.value))
+	return m
+}
+
+// nextRows returns the next n row messages as "table: key -> value" strings,
+// skipping resolved timestamps.
+func (f *sinklessFeed) nextRows(t *testing.T, n int) []string {
+	var res []string
+	for len(res) < n {
+		m := f.next(t)
+		if !m.table.Valid {
+			continue
+		}
+		res = append(res, m.table.String+": "+string(m.key)+" -> "+string(m.value))
+	}
+	return res
+}
+
+func (f *sinklessFeed) close() {
+	f.cancel()
+	_ = f.rows.Close()
+}
+
+func startChangefeedTestServer(
+	t *testing.T, args base.TestServerArgs,
+) (*gosql.DB, *sqlutils.SQLRunner, func()) {
+	s, db, _ := serverutils.StartServer(t, args)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	return db, sqlDB, func() { s.Stopper().Stop(context.Background()) }
+}
+
+func TestSinklessChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	db, sqlDB, cleanup := startChangefeedTestServer(t, base.TestServerArgs{})
+	defer cleanup()
+
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'one'), (2, 'two')`)
+
+	feed := startSinklessFeed(t, db, `EXPERIMENTAL CHANGEFEED FOR foo`)
+	defer feed.close()
+
+	// The initial scan may emit the rows in any order.
+	require.ElementsMatch(t, []string{
+		`foo: [1] -> {"after": {"a": 1, "b": "one"}}`,
+		`foo: [2] -> {"after": {"a": 2, "b": "two"}}`,
+	}, feed.nextRows(t, 2))
+
+	sqlDB.Exec(t, `UPSERT INTO foo VALUES (1, 'uno')`)
+	require.Equal(t, []string{`foo: [1] -> {"after": {"a": 1, "b": "uno"}}`}, feed.nextRows(t, 1))
+	sqlDB.Exec(t, `DELETE FROM foo WHERE a = 2`)
+	require.Equal(t, []string{`foo: [2] -> {"after": null}`}, feed.nextRows(t, 1))
+}
+
+func TestSinklessChangefeedOptions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	db, sqlDB, cleanup := startChangefeedTestServer(t, base.TestServerArgs{})
+	defer cleanup()
+
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'one')`)
+
+	feed := startSinklessFeed(t, db,
+		`EXPERIMENTAL CHANGEFEED FOR foo WITH updated, diff, resolved, no_initial_scan`)
+	defer feed.close()
+
+	sqlDB.Exec(t, `UPDATE foo SET b = 'uno' WHERE a = 1`)
+	var value map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(feed.nextRows(t, 1)[0][len(`foo: [1] -> `):]), &value))
+	require.Equal(t, map[string]interface{}{"a": 1.0, "b": "uno"}, value["after"])
+	require.Equal(t, map[string]interface{}{"a": 1.0, "b": "one"}, value["before"])
+	require.NotEmpty(t, value["updated"])
+
+	// Resolved timestamps are emitted with NULL table and key.
+	for {
+		m := feed.next(t)
+		if m.table.Valid {
+			continue
+		}
+		require.Nil(t, m.key)
+		var resolved map[string]string
+		require.NoError(t, json.Unmarshal(m.value, &resolved))
+		require.NotEmpty(t, resolved["resolved"])
+		break
+	}
+}
+
+func TestSinklessChangefeedErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	_, sqlDB, cleanup := startChangefeedTestServer(t, base.TestServerArgs{})
+	defer cleanup()
+
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `CREATE TABLE fams (a INT PRIMARY KEY, b STRING, FAMILY (a), FAMILY (b))`)
+	sqlDB.Exec(t, `CREATE VIEW v AS SELECT a FROM foo`)
+
+	sqlDB.ExpectErr(t, `relation "missing" does not exist`,
+		`EXPERIMENTAL CHANGEFEED FOR missing`)
+	sqlDB.ExpectErr(t, `CHANGEFEED cannot target views: v`,
+		`EXPERIMENTAL CHANGEFEED FOR v`)
+	sqlDB.ExpectErr(t, `multiple column families`,
+		`EXPERIMENTAL CHANGEFEED FOR fams`)
+	sqlDB.ExpectErr(t, `unknown format: avro`,
+		`EXPERIMENTAL CHANGEFEED FOR foo WITH format = 'avro'`)
+	sqlDB.ExpectErr(t, `cannot specify both initial_scan and no_initial_scan`,
+		`EXPERIMENTAL CHANGEFEED FOR foo WITH initial_scan, no_initial_scan`)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = false`)
+	sqlDB.ExpectErr(t, `rangefeeds require the kv.rangefeed.enabled setting`,
+		`EXPERIMENTAL CHANGEFEED FOR foo`)
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/lease"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// decodedRow is a row change decoded from a KV of a table's primary index.
+type decodedRow struct {
+	table catalog.TableDescriptor
+	// datums holds the values of the public columns of table, in the order of
+	// table.PublicColumns(). Only the primary key columns are set for a
+	// deleted row.
+	datums  tree.Datums
+	deleted bool
+	updated hlc.Timestamp
+}
+
+// validateTable returns an error if a changefeed cannot watch the table.
+func validateTable(table catalog.TableDescriptor) error {
+	switch {
+	case table.IsView():
+		return pgerror.Newf(pgcode.WrongObjectType, "CHANGEFEED cannot target views: %s", table.GetName())
+	case table.IsSequence():
+		return pgerror.Newf(pgcode.WrongObjectType, "CHANGEFEED cannot target sequences: %s", table.GetName())
+	case table.IsVirtualTable():
+		return pgerror.Newf(pgcode.WrongObjectType, "CHANGEFEED cannot target virtual tables: %s", table.GetName())
+	}
+	if table.Dropped() {
+		return errors.Errorf("table %q was dropped", table.GetName())
+	}
+	if table.Offline() {
+		return errors.Errorf("table %q is offline", table.GetName())
+	}
+	if len(table.GetFamilies()) != 1 {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"CHANGEFEED targets table %q with multiple column families, which is not supported",
+			table.GetName())
+	}
+	return nil
+}
+
+// fetcherKey identifies a version of a table descriptor.
+type fetcherKey struct {
+	id      descpb.ID
+	version descpb.DescriptorVersion
+}
+
+// rowDecoder decodes the KVs received by a changefeed into rows, using the
+// version of the table descriptor which was current when each KV was written.
+type rowDecoder struct {
+	codec    keys.SQLCodec
+	leaseMgr *lease.Manager
+	alloc    tree.DatumAlloc
+	fetchers map[fetcherKey]*row.Fetcher
+}
+
+func makeRowDecoder(codec keys.SQLCodec, leaseMgr *lease.Manager) *rowDecoder {
+	return &rowDecoder{
+		codec:    codec,
+		leaseMgr: leaseMgr,
+		fetchers: make(map[fetcherKey]*row.Fetcher),
+	}
+}
+
+// tableAt returns the descriptor of the table with the given ID at ts.
+func (d *rowDecoder) tableAt(
+	ctx context.Context, id descpb.ID, ts hlc.Timestamp,
+) (catalog.TableDescriptor, error) {
+	leased, err := d.leaseMgr.Acquire(ctx, ts, id)
+	if err != nil {
+		return nil, err
+	}
+	// Descriptors are immutable, so it's safe to keep using this version after
+	// the lease is released.
+	desc := leased.Underlying()
+	leased.Release(ctx)
+	table, ok := desc.(catalog.TableDescriptor)
+	if !ok {
+		return nil, errors.AssertionFailedf("descriptor %d is not a table", id)
+	}
+	if err := validateTable(table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// fetcherFor returns a row.Fetcher decoding the primary index of table.
+func (d *rowDecoder) fetcherFor(
+	ctx context.Context, table catalog.TableDescriptor,
+) (*row.Fetcher, error) {
+	key := fetcherKey{id: table.GetID(), version: table.GetVersion()}
+	if rf, ok := d.fetchers[key]; ok {
+		return rf, nil
+	}
+	cols := table.PublicColumns()
+	colIDs := make([]descpb.ColumnID, len(cols))
+	for i, col := range cols {
+		colIDs[i] = col.GetID()
+	}
+	var spec descpb.IndexFetchSpec
+	if err := rowenc.InitIndexFetchSpec(&spec, d.codec, table, table.GetPrimaryIndex(), colIDs); err != nil {
+		return nil, err
+	}
+	var rf row.Fetcher
+	if err := rf.Init(
+		ctx,
+		false, /* reverse */
+		descpb.ScanLockingStrength_FOR_NONE,
+		descpb.ScanLockingWaitPolicy_BLOCK,
+		0, /* lockTimeout */
+		&d.alloc,
+		nil, /* memMonitor */
+		&spec,
+	); err != nil {
+		return nil, err
+	}
+	d.fetchers[key] = &rf
+	return &rf, nil
+}
+
+// decode decodes a KV of a table as of ts. It returns false if the KV doesn't
+// belong to the primary index of the table.
+func (d *rowDecoder) decode(
+	ctx context.Context, key roachpb.Key, value roachpb.Value, ts hlc.Timestamp,
+) (_ decodedRow, ok bool, _ error) {
+	_, tableID, indexID, err := d.codec.DecodeIndexPrefix(key)
+	if err != nil {
+		return decodedRow{}, false, err
+	}
+	table, err := d.tableAt(ctx, descpb.ID(tableID), ts)
+	if err != nil {
+		return decodedRow{}, false, err
+	}
+	if descpb.IndexID(indexID) != table.GetPrimaryIndexID() {
+		return decodedRow{}, false, nil
+	}
+	rf, err := d.fetcherFor(ctx, table)
+	if err != nil {
+		return decodedRow{}, false, err
+	}
+	kv := roachpb.KeyValue{Key: key, Value: value}
+	if err := rf.StartScanFrom(ctx, &row.SpanKVFetcher{KVs: []roachpb.KeyValue{kv}}, false /* traceKV */); err != nil {
+		return decodedRow{}, false, err
+	}
+	datums, err := rf.NextRowDecoded(ctx)
+	if err != nil {
+		return decodedRow{}, false, err
+	}
+	if datums == nil {
+		return decodedRow{}, false, errors.AssertionFailedf("no row decoded from key %s", key)
+	}
+	return decodedRow{
+		table:   table,
+		datums:  append(tree.Datums(nil), datums...),
+		deleted: rf.RowIsDeleted(),
+		updated: ts,
+	}, true, nil
+}
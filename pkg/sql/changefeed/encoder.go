@@ -0,0 +1,136 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+)
+
+// jsonEncoder encodes the messages of a changefeed as JSON.
+//
+// The key of a row is a JSON array of its primary key values. Its value is an
+// object whose "after" field holds the row, or null if the row was deleted,
+// with the optional "updated" and "before" fields holding the timestamp of the
+// change and the previous value of the row respectively, and the optional
+// "key" and "topic" fields repeating the key and table of the row. Resolved
+// timestamps are encoded as an object with a single "resolved" field.
+type jsonEncoder struct {
+	updated, diff            bool
+	keyInValue, topicInValue bool
+	buf                      bytes.Buffer
+}
+
+func makeJSONEncoder(opts changefeedOptions) *jsonEncoder {
+	return &jsonEncoder{
+		updated:      opts.updated,
+		diff:         opts.diff,
+		keyInValue:   opts.keyInValue,
+		topicInValue: opts.topicInValue,
+	}
+}
+
+func datumAsJSON(d tree.Datum) (json.JSON, error) {
+	return tree.AsJSON(d, sessiondatapb.DataConversionConfig{}, time.UTC)
+}
+
+func (e *jsonEncoder) encode(j json.JSON) []byte {
+	e.buf.Reset()
+	j.Format(&e.buf)
+	return append([]byte(nil), e.buf.Bytes()...)
+}
+
+// keyAsJSON returns the primary key of a row as a JSON array.
+func keyAsJSON(r decodedRow) (json.JSON, error) {
+	ords := catalog.ColumnIDToOrdinalMap(r.table.PublicColumns())
+	pk := r.table.GetPrimaryIndex()
+	b := json.NewArrayBuilder(pk.NumKeyColumns())
+	for i := 0; i < pk.NumKeyColumns(); i++ {
+		j, err := datumAsJSON(r.datums[ords.GetDefault(pk.GetKeyColumnID(i))])
+		if err != nil {
+			return nil, err
+		}
+		b.Add(j)
+	}
+	return b.Build(), nil
+}
+
+// encodeKey encodes the primary key of a row.
+func (e *jsonEncoder) encodeKey(r decodedRow) ([]byte, error) {
+	key, err := keyAsJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	return e.encode(key), nil
+}
+
+// rowAsJSON returns a row as a JSON object keyed by column name, or null for a
+// deleted row.
+func rowAsJSON(r decodedRow) (json.JSON, error) {
+	if r.deleted || r.datums == nil {
+		return json.NullJSONValue, nil
+	}
+	cols := r.table.PublicColumns()
+	b := json.NewObjectBuilder(len(cols))
+	for i, col := range cols {
+		j, err := datumAsJSON(r.datums[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Add(col.GetName(), j)
+	}
+	return b.Build(), nil
+}
+
+// encodeValue encodes a row change of the named table; prev is the previous
+// value of the row, which is only used with the diff option.
+func (e *jsonEncoder) encodeValue(topic string, r, prev decodedRow) ([]byte, error) {
+	b := json.NewObjectBuilder(5)
+	after, err := rowAsJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	b.Add("after", after)
+	if e.diff {
+		before, err := rowAsJSON(prev)
+		if err != nil {
+			return nil, err
+		}
+		b.Add("before", before)
+	}
+	if e.updated {
+		b.Add("updated", json.FromString(r.updated.AsOfSystemTime()))
+	}
+	if e.keyInValue {
+		key, err := keyAsJSON(r)
+		if err != nil {
+			return nil, err
+		}
+		b.Add("key", key)
+	}
+	if e.topicInValue {
+		b.Add("topic", json.FromString(topic))
+	}
+	return e.encode(b.Build()), nil
+}
+
+// encodeResolved encodes a resolved timestamp.
+func (e *jsonEncoder) encodeResolved(resolved hlc.Timestamp) []byte {
+	b := json.NewObjectBuilder(1)
+	b.Add("resolved", json.FromString(resolved.AsOfSystemTime()))
+	return e.encode(b.Build())
+}
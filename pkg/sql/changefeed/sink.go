@@ -0,0 +1,115 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// sink is the destination of the messages emitted by a changefeed.
+type sink interface {
+	// emitRow emits an encoded row change of the named table. The row may be
+	// buffered until the next call to flush.
+	emitRow(ctx context.Context, table string, key, value []byte, updated hlc.Timestamp) error
+	// emitResolved emits an encoded resolved timestamp message. All the rows
+	// emitted before it must be flushed first.
+	emitResolved(ctx context.Context, payload []byte, resolved hlc.Timestamp) error
+	// flush blocks until all the rows emitted so far have been delivered.
+	flush(ctx context.Context) error
+	// close releases the resources held by the sink.
+	close() error
+}
+
+// makeSink returns the sink of a changefeed job with the given sink URI.
+// Webhook sinks are selected by the webhook-https scheme; any other URI is
+// treated as an external storage URI which files are written to.
+func makeSink(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	sinkURI string,
+	jobID jobspb.JobID,
+	user security.SQLUsername,
+) (sink, error) {
+	u, err := url.Parse(sinkURI)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue, "invalid sink URI %q", sinkURI)
+	}
+	switch u.Scheme {
+	case sinkSchemeWebhookHTTPS:
+		return makeWebhookSink(u, execCfg.Settings)
+	case sinkSchemeWebhookHTTP:
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"webhook sinks require %s URIs", sinkSchemeWebhookHTTPS)
+	case "":
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue, "no scheme found for sink URI %q", sinkURI)
+	default:
+		return makeFileSink(ctx, execCfg, sinkURI, jobID, user)
+	}
+}
+
+// sinklessResultColumns are the columns returned by a changefeed without a
+// sink, which streams its messages back over the SQL connection.
+var sinklessResultColumns = colinfo.ResultColumns{
+	{Name: "table", Typ: types.String},
+	{Name: "key", Typ: types.Bytes},
+	{Name: "value", Typ: types.Bytes},
+}
+
+// sinklessSink is the sink of a changefeed without a sink URI. It sends every
+// message as a row of the statement's results.
+type sinklessSink struct {
+	resultsCh chan<- tree.Datums
+}
+
+var _ sink = (*sinklessSink)(nil)
+
+func (s *sinklessSink) send(ctx context.Context, row tree.Datums) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.resultsCh <- row:
+		return nil
+	}
+}
+
+// emitRow implements the sink interface.
+func (s *sinklessSink) emitRow(
+	ctx context.Context, table string, key, value []byte, _ hlc.Timestamp,
+) error {
+	return s.send(ctx, tree.Datums{
+		tree.NewDString(table),
+		tree.NewDBytes(tree.DBytes(key)),
+		tree.NewDBytes(tree.DBytes(value)),
+	})
+}
+
+// emitResolved implements the sink interface.
+func (s *sinklessSink) emitResolved(ctx context.Context, payload []byte, _ hlc.Timestamp) error {
+	return s.send(ctx, tree.Datums{tree.DNull, tree.DNull, tree.NewDBytes(tree.DBytes(payload))})
+}
+
+// flush implements the sink interface. Rows are handed over to the
+// connection as soon as they are emitted.
+func (s *sinklessSink) flush(context.Context) error { return nil }
+
+// close implements the sink interface.
+func (s *sinklessSink) close() error { return nil }
@@ -0,0 +1,153 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// fileSinkTargetSize is the size above which the buffered rows of a file sink
+// are written out without waiting for the next flush.
+const fileSinkTargetSize = 16 << 20
+
+// fileSinkBuffer holds the rows of a table not yet written out.
+type fileSinkBuffer struct {
+	buf bytes.Buffer
+	// oldest is the timestamp of the oldest row in buf.
+	oldest hlc.Timestamp
+}
+
+// fileSink writes the messages of a changefeed as files to an external
+// storage.
+//
+// Rows are written as newline-delimited JSON values, one file per table and
+// flush, named
+//
+//	<date>/<timestamp>-<job ID>-<session>-<seq>-<table>.ndjson
+//
+// where timestamp is the oldest update in the file, session identifies the
+// run of the job which wrote it and seq orders its files. Resolved timestamps
+// are written as <date>/<timestamp>.RESOLVED files: every file of a row
+// updated at or before a resolved timestamp sorts before its RESOLVED file.
+// Files are written by a job after it restarts from its last checkpoint, so a
+// row may appear in several files.
+type fileSink struct {
+	store   cloud.ExternalStorage
+	jobID   jobspb.JobID
+	session string
+	seq     int
+	size    int
+	buffers map[string]*fileSinkBuffer
+}
+
+var _ sink = (*fileSink)(nil)
+
+func makeFileSink(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	sinkURI string,
+	jobID jobspb.JobID,
+	user security.SQLUsername,
+) (*fileSink, error) {
+	store, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, sinkURI, user)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{
+		store:   store,
+		jobID:   jobID,
+		session: uuid.MakeV4().Short(),
+		buffers: make(map[string]*fileSinkBuffer),
+	}, nil
+}
+
+// fileSinkTimestamp formats ts so that timestamps sort lexicographically.
+func fileSinkTimestamp(ts hlc.Timestamp) string {
+	t := timeutil.Unix(0, ts.WallTime)
+	return fmt.Sprintf("%s%09d%010d", t.Format("20060102150405"), t.Nanosecond(), ts.Logical)
+}
+
+// fileSinkDir returns the directory of the files of ts.
+func fileSinkDir(ts hlc.Timestamp) string {
+	return timeutil.Unix(0, ts.WallTime).Format("2006-01-02")
+}
+
+// emitRow implements the sink interface. The value of the row holds its key,
+// which is therefore ignored.
+func (s *fileSink) emitRow(
+	ctx context.Context, table string, _, value []byte, updated hlc.Timestamp,
+) error {
+	b, ok := s.buffers[table]
+	if !ok {
+		b = &fileSinkBuffer{}
+		s.buffers[table] = b
+	}
+	if b.buf.Len() == 0 || updated.Less(b.oldest) {
+		b.oldest = updated
+	}
+	b.buf.Write(value)
+	b.buf.WriteByte('\n')
+	s.size += len(value) + 1
+	if s.size >= fileSinkTargetSize {
+		return s.flush(ctx)
+	}
+	return nil
+}
+
+// emitResolved implements the sink interface.
+func (s *fileSink) emitResolved(ctx context.Context, payload []byte, resolved hlc.Timestamp) error {
+	if err := s.flush(ctx); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s/%s.RESOLVED", fileSinkDir(resolved), fileSinkTimestamp(resolved))
+	return cloud.WriteFile(ctx, s.store, name, bytes.NewReader(payload))
+}
+
+// flush implements the sink interface.
+func (s *fileSink) flush(ctx context.Context) error {
+	tables := make([]string, 0, len(s.buffers))
+	for table, b := range s.buffers {
+		if b.buf.Len() > 0 {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		b := s.buffers[table]
+		name := fmt.Sprintf("%s/%s-%d-%s-%d-%s.ndjson",
+			fileSinkDir(b.oldest), fileSinkTimestamp(b.oldest), s.jobID, s.session, s.seq,
+			url.PathEscape(table))
+		if err := cloud.WriteFile(ctx, s.store, name, bytes.NewReader(b.buf.Bytes())); err != nil {
+			return err
+		}
+		s.seq++
+		b.buf.Reset()
+	}
+	s.size = 0
+	return nil
+}
+
+// close implements the sink interface.
+func (s *fileSink) close() error {
+	return s.store.Close()
+}
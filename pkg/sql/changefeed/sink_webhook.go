@@ -0,0 +1,218 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package changefeed
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	sinkSchemeWebhookHTTPS = "webhook-https"
+	sinkSchemeWebhookHTTP  = "webhook-http"
+
+	// sinkParamCACert is a base64-encoded PEM certificate used to verify the
+	// certificate of the webhook endpoint.
+	sinkParamCACert = "ca_cert"
+	// sinkParamSkipTLSVerify disables the verification of the certificate of
+	// the webhook endpoint.
+	sinkParamSkipTLSVerify = "insecure_tls_skip_verify"
+)
+
+// isWebhookSinkURI returns whether sinkURI is the URI of a webhook sink.
+func isWebhookSinkURI(sinkURI string) bool {
+	return strings.HasPrefix(sinkURI, sinkSchemeWebhookHTTPS+"://")
+}
+
+// webhookMaxBatchSize is the maximum number of messages sent in a single
+// webhook request.
+const webhookMaxBatchSize = 1000
+
+// retryableWebhookError is an error of a webhook request which may succeed if
+// retried, e.g. a connection failure or a 5xx response.
+type retryableWebhookError struct {
+	cause error
+}
+
+func (e *retryableWebhookError) Error() string {
+	return fmt.Sprintf("retryable webhook error: %s", e.cause)
+}
+
+// webhookSink POSTs the messages of a changefeed to an HTTPS endpoint.
+//
+// Row messages are sent in batches, as a JSON object with a "payload" field
+// holding the array of messages and a "length" field holding their number.
+// Resolved timestamps are sent as a request of their own. Requests are
+// retried until they succeed or the retries are exhausted, in which case the
+// job is restarted from its last checkpoint: every message is delivered at
+// least once.
+type webhookSink struct {
+	url       string
+	client    *http.Client
+	retryOpts retry.Options
+	batch     [][]byte
+}
+
+var _ sink = (*webhookSink)(nil)
+
+func makeWebhookSink(u *url.URL, settings *cluster.Settings) (*webhookSink, error) {
+	dest := *u
+	dest.Scheme = "https"
+	params := dest.Query()
+	caCert := params.Get(sinkParamCACert)
+	skipVerify := false
+	if v := params.Get(sinkParamSkipTLSVerify); v != "" {
+		var err error
+		if skipVerify, err = strconv.ParseBool(v); err != nil {
+			return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+				"invalid value for %s: %q", sinkParamSkipTLSVerify, v)
+		}
+	}
+	params.Del(sinkParamCACert)
+	params.Del(sinkParamSkipTLSVerify)
+	dest.RawQuery = params.Encode()
+
+	client, err := cloud.MakeHTTPClient(settings)
+	if err != nil {
+		return nil, err
+	}
+	if caCert != "" || skipVerify {
+		tlsConf := &tls.Config{InsecureSkipVerify: skipVerify}
+		if caCert != "" {
+			pem, err := base64.StdEncoding.DecodeString(caCert)
+			if err != nil {
+				return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+					"%s must be base64 encoded", sinkParamCACert)
+			}
+			roots, err := x509.SystemCertPool()
+			if err != nil {
+				return nil, errors.Wrap(err, "could not load system root CA pool")
+			}
+			if !roots.AppendCertsFromPEM(pem) {
+				return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+					"failed to parse certificate from %s", sinkParamCACert)
+			}
+			tlsConf.RootCAs = roots
+		}
+		client.Transport.(*http.Transport).TLSClientConfig = tlsConf
+	}
+	return &webhookSink{
+		url:       dest.String(),
+		client:    client,
+		retryOpts: cloud.HTTPRetryOptions,
+	}, nil
+}
+
+// emitRow implements the sink interface. The value of the row holds its key
+// and table, which are therefore ignored.
+func (s *webhookSink) emitRow(
+	ctx context.Context, _ string, _, value []byte, _ hlc.Timestamp,
+) error {
+	s.batch = append(s.batch, value)
+	if len(s.batch) >= webhookMaxBatchSize {
+		return s.flush(ctx)
+	}
+	return nil
+}
+
+// emitResolved implements the sink interface.
+func (s *webhookSink) emitResolved(ctx context.Context, payload []byte, _ hlc.Timestamp) error {
+	if err := s.flush(ctx); err != nil {
+		return err
+	}
+	return s.send(ctx, payload)
+}
+
+// flush implements the sink interface.
+func (s *webhookSink) flush(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`{"payload":[`)
+	for i, msg := range s.batch {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(msg)
+	}
+	fmt.Fprintf(&buf, `],"length":%d}`, len(s.batch))
+	if err := s.send(ctx, buf.Bytes()); err != nil {
+		return err
+	}
+	s.batch = s.batch[:0]
+	return nil
+}
+
+// send POSTs body to the endpoint, retrying transient failures.
+func (s *webhookSink) send(ctx context.Context, body []byte) error {
+	var err error
+	for r := retry.StartWithCtx(ctx, s.retryOpts); r.Next(); {
+		err = s.post(ctx, body)
+		if err == nil || !errors.HasType(err, (*retryableWebhookError)(nil)) {
+			return err
+		}
+		log.VEventf(ctx, 1, "webhook request failed, retrying: %v", err)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	// The endpoint may still recover: restart the job from its last checkpoint
+	// rather than failing it.
+	return jobs.MarkAsRetryJobError(err)
+}
+
+func (s *webhookSink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "error constructing webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &retryableWebhookError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	err = errors.Errorf("error response from webhook: %s %q", resp.Status, respBody)
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return &retryableWebhookError{err}
+	}
+	return err
+}
+
+// close implements the sink interface.
+func (s *webhookSink) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -230,20 +230,35 @@ func nonMatchingRowQuery(
 	), originColNames, nil
 }
 
+// filterValidationQuery restricts a validation query, which must not limit its
+// results, to the rows which satisfy filter, and returns at most one of them.
+// The filter can refer to the columns returned by the query.
+func filterValidationQuery(query string, filter string) string {
+	if filter == "" {
+		return query
+	}
+	return fmt.Sprintf(`SELECT * FROM (%s) WHERE %s LIMIT 1`, query, filter)
+}
+
 // validateForeignKey verifies that all the rows in the srcTable
 // have a matching row in their referenced table.
 //
 // It operates entirely on the current goroutine and is thus able to
 // reuse an existing kv.Txn safely.
+//
+// If filter is not empty, only the rows of srcTable which satisfy it are
+// validated. The filter can refer to the columns of the foreign key.
 func validateForeignKey(
 	ctx context.Context,
-	srcTable *tabledesc.Mutable,
+	srcTable catalog.TableDescriptor,
 	targetTable catalog.TableDescriptor,
 	fk *descpb.ForeignKeyConstraint,
+	filter string,
 	ie sqlutil.InternalExecutor,
 	txn *kv.Txn,
 ) error {
 	nCols := len(fk.OriginColumnIDs)
+	limitResults := filter == ""
 
 	referencedColumnNames, err := targetTable.NamesForColumnIDs(fk.ReferencedColumnIDs)
 	if err != nil {
@@ -254,16 +269,15 @@ func validateForeignKey(
 	// null and non-null values exist.
 	// (The matching options only matter for FKs with more than one column.)
 	if nCols > 1 && fk.Match == descpb.ForeignKeyReference_FULL {
-		query, colNames, err := matchFullUnacceptableKeyQuery(
-			srcTable, fk, true, /* limitResults */
-		)
+		query, colNames, err := matchFullUnacceptableKeyQuery(srcTable, fk, limitResults)
 		if err != nil {
 			return err
 		}
+		query = filterValidationQuery(query, filter)
 
 		log.Infof(ctx, "validating MATCH FULL FK %q (%q [%v] -> %q [%v]) with query %q",
 			fk.Name,
-			srcTable.Name, colNames,
+			srcTable.GetName(), colNames,
 			targetTable.GetName(), referencedColumnNames,
 			query,
 		)
@@ -280,17 +294,15 @@ func validateForeignKey(
 			), fk.Name)
 		}
 	}
-	query, colNames, err := nonMatchingRowQuery(
-		srcTable, fk, targetTable,
-		true, /* limitResults */
-	)
+	query, colNames, err := nonMatchingRowQuery(srcTable, fk, targetTable, limitResults)
 	if err != nil {
 		return err
 	}
+	query = filterValidationQuery(query, filter)
 
 	log.Infof(ctx, "validating FK %q (%q [%v] -> %q [%v]) with query %q",
 		fk.Name,
-		srcTable.Name, colNames, targetTable.GetName(), referencedColumnNames,
+		srcTable.GetName(), colNames, targetTable.GetName(), referencedColumnNames,
 		query,
 	)
 
@@ -302,7 +314,7 @@ func validateForeignKey(
 	if values.Len() > 0 {
 		return pgerror.WithConstraintName(pgerror.Newf(pgcode.ForeignKeyViolation,
 			"foreign key violation: %q row %s has no match in %q",
-			srcTable.Name, formatValues(colNames, values), targetTable.GetName()), fk.Name)
+			srcTable.GetName(), formatValues(colNames, values), targetTable.GetName()), fk.Name)
 	}
 	return nil
 }
@@ -612,6 +624,138 @@ func validateUniqueConstraint(
 	return nil
 }
 
+// conflictingRowQuery generates and returns a SELECT query that returns the
+// columns of two distinct rows of srcTbl that conflict according to the given
+// exclusion constraint. The columns of the first row are followed by the
+// columns of the second row.
+func conflictingRowQuery(
+	srcTbl catalog.TableDescriptor, ec *descpb.ExclusionConstraint,
+) (sql string, colNames []string, _ error) {
+	colNames, err := srcTbl.NamesForColumnIDs(ec.ColumnIDs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Collect the constraint columns and the primary key columns, which are
+	// used to prevent rows from conflicting with themselves.
+	var srcCols []string
+	seen := make(map[string]bool)
+	addCol := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			srcCols = append(srcCols, tree.NameString(name))
+		}
+	}
+	for _, name := range colNames {
+		addCol(name)
+	}
+	pkIndex := srcTbl.GetPrimaryIndex()
+	pkCols := make([]string, pkIndex.NumKeyColumns())
+	for i := range pkCols {
+		name := pkIndex.GetKeyColumnName(i)
+		addCol(name)
+		pkCols[i] = tree.NameString(name)
+	}
+
+	src := fmt.Sprintf("SELECT %s FROM [%d AS tbl]", strings.Join(srcCols, ", "), srcTbl.GetID())
+	if ec.Predicate != "" {
+		src = fmt.Sprintf("%s WHERE (%s)", src, ec.Predicate)
+	}
+
+	outCols := make([]string, 0, 2*len(colNames))
+	for _, side := range []string{"a", "b"} {
+		for _, name := range colNames {
+			outCols = append(outCols, fmt.Sprintf("%s.%s", side, tree.NameString(name)))
+		}
+	}
+	on := make([]string, 0, len(colNames)+1)
+	for i, name := range colNames {
+		on = append(on, fmt.Sprintf(
+			"a.%[1]s %[2]s b.%[1]s", tree.NameString(name), ec.Operators[i],
+		))
+	}
+	aPK := make([]string, len(pkCols))
+	bPK := make([]string, len(pkCols))
+	for i, name := range pkCols {
+		aPK[i] = "a." + name
+		bPK[i] = "b." + name
+	}
+	on = append(on, fmt.Sprintf(
+		"(%s) != (%s)", strings.Join(aPK, ", "), strings.Join(bPK, ", "),
+	))
+
+	return fmt.Sprintf(
+		`SELECT %[1]s FROM (%[2]s) AS a JOIN (%[2]s) AS b ON %[3]s LIMIT 1`,
+		strings.Join(outCols, ", "), // 1
+		src,                         // 2
+		strings.Join(on, " AND "),   // 3
+	), colNames, nil
+}
+
+// validateExclusionConstraint verifies that no two rows in the srcTable
+// conflict according to the given exclusion constraint.
+//
+// It operates entirely on the current goroutine and is thus able to
+// reuse an existing kv.Txn safely.
+//
+// preExisting indicates whether this constraint already exists, and therefore
+// informs the error message that gets produced.
+func validateExclusionConstraint(
+	ctx context.Context,
+	srcTable catalog.TableDescriptor,
+	ec *descpb.ExclusionConstraint,
+	ie sqlutil.InternalExecutor,
+	txn *kv.Txn,
+	user security.SQLUsername,
+	preExisting bool,
+) error {
+	query, colNames, err := conflictingRowQuery(srcTable, ec)
+	if err != nil {
+		return err
+	}
+
+	log.Infof(ctx, "validating exclusion constraint %q (%q [%v]) with query %q",
+		ec.Name,
+		srcTable.GetName(),
+		colNames,
+		query,
+	)
+
+	sessionDataOverride := sessiondata.NoSessionDataOverride
+	sessionDataOverride.User = user
+	values, err := ie.QueryRowEx(ctx, "validate exclusion constraint", txn, sessionDataOverride, query)
+	if err != nil {
+		return err
+	}
+	if values.Len() > 0 {
+		valuesStr := make([]string, len(values))
+		for i := range values {
+			valuesStr[i] = values[i].String()
+		}
+		n := len(colNames)
+		cols := strings.Join(colNames, ", ")
+		// Note: this error message mirrors the message produced by Postgres
+		// when it fails to add an exclusion constraint due to conflicting keys.
+		errMsg := "could not create exclusion constraint"
+		if preExisting {
+			errMsg = "failed to validate exclusion constraint"
+		}
+		return errors.WithDetail(
+			pgerror.WithConstraintName(
+				pgerror.Newf(
+					pgcode.ExclusionViolation, "%s %q", errMsg, ec.Name,
+				),
+				ec.Name,
+			),
+			fmt.Sprintf(
+				"Key (%s)=(%s) conflicts with key (%s)=(%s).",
+				cols, strings.Join(valuesStr[:n], ", "), cols, strings.Join(valuesStr[n:], ", "),
+			),
+		)
+	}
+	return nil
+}
+
 // ValidateTTLScheduledJobsInCurrentDB is part of the EvalPlanner interface.
 func (p *planner) ValidateTTLScheduledJobsInCurrentDB(ctx context.Context) error {
 	dbName := p.CurrentDatabase()
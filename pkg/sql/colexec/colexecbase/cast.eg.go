@@ -81,6 +81,15 @@ func GetCastOperator(
 		outputIdx:                resultIdx,
 		evalCtx:                  evalCtx,
 	}
+	if toType.IsDomain() {
+		// Casts to a domain must check the constraints of the domain, which is
+		// left to the row-by-row engine.
+		return nil, errors.Errorf(
+			"unhandled cast %s -> %s",
+			fromType.SQLStringForError(),
+			toType.SQLStringForError(),
+		)
+	}
 	if fromType.Family() == types.UnknownFamily {
 		return &castOpNullAny{castOpBase: base}, nil
 	}
@@ -457,6 +466,9 @@ func GetCastOperator(
 }
 
 func IsCastSupported(fromType, toType *types.T) bool {
+	if toType.IsDomain() {
+		return false
+	}
 	if fromType.Family() == types.UnknownFamily {
 		return true
 	}
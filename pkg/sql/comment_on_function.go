@@ -0,0 +1,71 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+type commentOnFunctionNode struct {
+	n               *tree.CommentOnFunction
+	fnDesc          catalog.FunctionDescriptor
+	metadataUpdater scexec.DescriptorMetadataUpdater
+}
+
+// CommentOnFunction adds a comment on a user-defined function.
+// Privileges: ownership of the function.
+func (p *planner) CommentOnFunction(
+	ctx context.Context, n *tree.CommentOnFunction,
+) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"COMMENT ON FUNCTION",
+	); err != nil {
+		return nil, err
+	}
+
+	fnDesc, err := p.resolveMutableFunction(ctx, &n.Function, true /* required */)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.canDropFunction(ctx, fnDesc); err != nil {
+		return nil, err
+	}
+
+	return &commentOnFunctionNode{
+		n:      n,
+		fnDesc: fnDesc,
+		metadataUpdater: p.execCfg.DescMetadaUpdaterFactory.NewMetadataUpdater(
+			ctx,
+			p.txn,
+			p.SessionData(),
+		),
+	}, nil
+}
+
+func (n *commentOnFunctionNode) startExec(params runParams) error {
+	if n.n.Comment != nil {
+		return n.metadataUpdater.UpsertDescriptorComment(
+			int64(n.fnDesc.GetID()), 0, keys.FunctionCommentType, *n.n.Comment)
+	}
+	return n.metadataUpdater.DeleteDescriptorComment(
+		int64(n.fnDesc.GetID()), 0, keys.FunctionCommentType)
+}
+
+func (n *commentOnFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (n *commentOnFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *commentOnFunctionNode) Close(context.Context)        {}
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/multitenant"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -38,8 +39,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/contention/txnidcache"
 	"github.com/cockroachdb/cockroach/pkg/sql/execstats"
 	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scrun"
@@ -1021,6 +1024,7 @@ func (s *Server) newConnExecutorWithTxn(
 		txn.ReadTimestamp().GoTime(),
 		nil, /* historicalTimestamp */
 		roachpb.UnspecifiedUserPriority,
+		txn.IsoLevel(),
 		tree.ReadWrite,
 		txn,
 		ex.transitionCtx,
@@ -1167,6 +1171,11 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		ex.eventLog = nil
 	}
 
+	if ex.listener != nil {
+		ex.listener.Close()
+		ex.listener = nil
+	}
+
 	// Stop idle timer if the connExecutor is closed to ensure cancel session
 	// is not called.
 	ex.mu.IdleInSessionTimeout.Stop()
@@ -1343,7 +1352,8 @@ type connExecutor struct {
 		// sqlCursors contains the list of SQL CURSORs the session currently has
 		// access to.
 		// Cursors are bound to an explicit transaction and they're all destroyed
-		// once the transaction finishes.
+		// once the transaction finishes, except for WITH HOLD cursors, which
+		// survive the commit of their transaction.
 		sqlCursors cursorMap
 
 		// shouldExecuteOnTxnFinish indicates that ex.onTxnFinish will be called
@@ -1420,6 +1430,14 @@ type connExecutor struct {
 		// createdSequences keeps track of sequences created in the current transaction.
 		// The map key is the sequence descpb.ID.
 		createdSequences map[descpb.ID]struct{}
+
+		// deferredConstraints tracks the checks of DEFERRABLE constraints that
+		// have been deferred until the end of the transaction.
+		deferredConstraints deferredConstraintsState
+
+		// notifications tracks the LISTEN and UNLISTEN statements and the
+		// notifications of the current transaction.
+		notifications notificationsState
 	}
 
 	// sessionDataStack contains the user-configurable connection variables.
@@ -1528,6 +1546,10 @@ type connExecutor struct {
 	// txnIDCacheWriter is used to write txnidcache.ResolvedTxnID to the
 	// Transaction ID Cache.
 	txnIDCacheWriter txnidcache.Writer
+
+	// listener receives the notifications sent on the channels the session is
+	// listening on. It is created by the first LISTEN statement which commits.
+	listener *notify.Listener
 }
 
 // ctxHolder contains a connection's context and, while session tracing is
@@ -1812,11 +1834,27 @@ func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) err
 		delete(ex.extraTxnState.prepStmtsNamespace.portals, name)
 	}
 
-	// Close all cursors.
-	ex.extraTxnState.sqlCursors.closeAll()
+	// Close all cursors, except for the WITH HOLD cursors of a committed
+	// transaction.
+	ex.extraTxnState.sqlCursors.closeTxnCursors(ev.eventType == txnCommit)
 
 	ex.extraTxnState.createdSequences = make(map[descpb.ID]struct{})
 
+	ex.extraTxnState.deferredConstraints.reset()
+
+	if ev.eventType == txnCommit && len(ex.extraTxnState.notifications.ops) > 0 {
+		if ex.listener == nil {
+			connCtx := ex.ctxHolder.connCtx
+			ex.listener = ex.server.cfg.NotificationRegistry.NewListener(func() {
+				// The buffer might be closed if the session is shutting down, in
+				// which case the notifications don't matter anymore.
+				_ = ex.stmtBuf.Push(connCtx, DeliverNotifications{})
+			})
+		}
+		ex.extraTxnState.notifications.apply(ex.listener)
+	}
+	ex.extraTxnState.notifications.reset()
+
 	switch ev.eventType {
 	case txnCommit, txnRollback:
 		for name, p := range ex.extraTxnState.prepStmtsNamespaceAtTxnRewindPos.portals {
@@ -2013,7 +2051,7 @@ func (ex *connExecutor) execCmd() (retErr error) {
 				tcmd.AST,
 				NeedRowDesc,
 				pos,
-				nil, /* formatCodes */
+				ex.cursorFormatCodes(tcmd.AST),
 				ex.sessionData().DataConversionConfig,
 				ex.sessionData().GetLocation(),
 				0,  /* limit */
@@ -2194,6 +2232,15 @@ func (ex *connExecutor) execCmd() (retErr error) {
 	case Flush:
 		// Closing the res will flush the connection's buffer.
 		res = ex.clientComm.CreateFlushResult(pos)
+	case DeliverNotifications:
+		// Like Postgres, we only send notifications in between transactions. If
+		// we're in a transaction, the notifications are sent once a Sync is
+		// processed outside of a transaction.
+		notifyRes := ex.clientComm.CreateNotificationResult(pos)
+		res = notifyRes
+		if ex.idleConn() {
+			ex.bufferNotifications(ctx, notifyRes)
+		}
 	default:
 		panic(errors.AssertionFailedf("unsupported command type: %T", cmd))
 	}
@@ -2253,6 +2300,11 @@ func (ex *connExecutor) execCmd() (retErr error) {
 				res.SetError(pe.errorCause())
 			}
 		}
+		if _, ok := cmd.(Sync); ok && ex.idleConn() {
+			// Send the notifications which arrived during the batch that was just
+			// completed.
+			ex.bufferNotifications(ctx, res.(SyncResult))
+		}
 		res.Close(ctx, stateToTxnStatusIndicator(ex.machine.CurState()))
 	} else {
 		res.Discard()
@@ -2409,6 +2461,8 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 				canAdvance = true
 			case Flush:
 				canAdvance = true
+			case DeliverNotifications:
+				canAdvance = true
 			default:
 				panic(errors.AssertionFailedf("unsupported cmd: %T", cmd))
 			}
@@ -2626,8 +2680,12 @@ func (ex *connExecutor) execCopyIn(
 }
 
 // stmtHasNoData returns true if describing a result of the input statement
-// type should return NoData.
+// type should return NoData. COPY TO returns rows, but they are described by
+// the CopyOutResponse sent upon execution.
 func stmtHasNoData(stmt tree.Statement) bool {
+	if _, ok := stmt.(*tree.CopyTo); ok {
+		return true
+	}
 	return stmt == nil || stmt.StatementReturnType() != tree.Rows
 }
 
@@ -2765,9 +2823,11 @@ func (ex *connExecutor) setTransactionModes(
 			return err
 		}
 	}
-	if modes.Isolation != tree.UnspecifiedIsolation && modes.Isolation != tree.SerializableIsolation {
-		return errors.AssertionFailedf(
-			"unknown isolation level: %s", errors.Safe(modes.Isolation))
+	if modes.Isolation != tree.UnspecifiedIsolation {
+		level := ex.txnIsolationLevelWithSessionDefault(ctx, modes.Isolation)
+		if err := ex.state.setIsolationLevel(level); err != nil {
+			return err
+		}
 	}
 	rwMode := modes.ReadWriteMode
 	if modes.AsOf.Expr != nil && asOfTs.IsEmpty() {
@@ -2811,6 +2871,47 @@ func (ex *connExecutor) txnPriorityWithSessionDefault(mode tree.UserPriority) ro
 	return txnPriorityToProto(mode)
 }
 
+func txnIsoLevelToKV(level tree.IsolationLevel) isolation.Level {
+	var ret isolation.Level
+	switch level {
+	case tree.UnspecifiedIsolation, tree.SerializableIsolation:
+		ret = isolation.Serializable
+	case tree.ReadCommittedIsolation:
+		ret = isolation.ReadCommitted
+	default:
+		log.Fatalf(context.Background(), "unknown isolation level: %s", level)
+	}
+	return ret
+}
+
+func txnIsoLevelFromKV(level isolation.Level) tree.IsolationLevel {
+	if level == isolation.ReadCommitted {
+		return tree.ReadCommittedIsolation
+	}
+	return tree.SerializableIsolation
+}
+
+// txnIsolationLevelWithSessionDefault returns the KV isolation level of a
+// transaction started with the given isolation level. READ COMMITTED is
+// upgraded to SERIALIZABLE unless it is enabled by the cluster setting and the
+// upgrade to the version which introduced it is finalized.
+func (ex *connExecutor) txnIsolationLevelWithSessionDefault(
+	ctx context.Context, level tree.IsolationLevel,
+) isolation.Level {
+	if level == tree.UnspecifiedIsolation {
+		level = tree.IsolationLevel(ex.sessionData().DefaultTxnIsolationLevel)
+	}
+	ret := txnIsoLevelToKV(level)
+	if ret == isolation.ReadCommitted {
+		st := ex.server.cfg.Settings
+		if !allowReadCommittedIsolation.Get(&st.SV) ||
+			!st.Version.IsActive(ctx, clusterversion.ReadCommittedIsolation) {
+			ret = isolation.Serializable
+		}
+	}
+	return ret
+}
+
 // QualityOfService returns the QoSLevel session setting if the session
 // settings are populated, otherwise the default QoSLevel.
 func (ex *connExecutor) QualityOfService() sessiondatapb.QoSLevel {
@@ -2965,7 +3066,10 @@ func (ex *connExecutor) initPlanner(ctx context.Context, p *planner) {
 	p.noticeSender = nil
 	p.preparedStatements = ex.getPrepStmtsAccessor()
 	p.sqlCursors = ex.getCursorAccessor()
+	p.sessionMonitor = ex.sessionMon
 	p.createdSequences = ex.getCreatedSequencesAccessor()
+	p.deferredConstraints = ex.getDeferredConstraintsAccessor()
+	p.notifications = ex.getNotificationsAccessor()
 
 	p.queryCacheSession.Init()
 	p.optPlanningCtx.init(p)
@@ -2991,6 +3095,7 @@ func (ex *connExecutor) resetPlanner(
 	p.semaCtx.SearchPath = ex.sessionData().SearchPath
 	p.semaCtx.Annotations = nil
 	p.semaCtx.TypeResolver = p
+	p.semaCtx.FunctionResolver = p
 	p.semaCtx.TableNameResolver = p
 	p.semaCtx.DateStyle = ex.sessionData().GetDateStyle()
 	p.semaCtx.IntervalStyle = ex.sessionData().GetIntervalStyle()
@@ -3344,12 +3449,60 @@ func (ex *connExecutor) getCursorAccessor() sqlCursors {
 	}
 }
 
+// cursorFormatCodes returns the format codes to encode the results of the given
+// statement with when it is executed through the simple protocol. FETCH from a
+// BINARY cursor returns its rows in binary format; all other statements use the
+// text format. With the extended protocol, the client chooses the format codes
+// regardless of the cursor's declaration.
+func (ex *connExecutor) cursorFormatCodes(stmt tree.Statement) []pgwirebase.FormatCode {
+	fetch, ok := stmt.(*tree.FetchCursor)
+	if !ok {
+		return nil
+	}
+	cursor := ex.extraTxnState.sqlCursors.getCursor(fetch.Name)
+	if cursor == nil || !cursor.binary {
+		return nil
+	}
+	formatCodes := make([]pgwirebase.FormatCode, len(cursor.cols))
+	for i := range formatCodes {
+		formatCodes[i] = pgwirebase.FormatBinary
+	}
+	return formatCodes
+}
+
 func (ex *connExecutor) getCreatedSequencesAccessor() createdSequences {
 	return connExCreatedSequencesAccessor{
 		ex: ex,
 	}
 }
 
+func (ex *connExecutor) getDeferredConstraintsAccessor() deferredConstraints {
+	return connExDeferredConstraintsAccessor{
+		ex: ex,
+	}
+}
+
+func (ex *connExecutor) getNotificationsAccessor() notifications {
+	// Notifications are only delivered to the sessions of client connections.
+	if ex.executorType == executorTypeInternal || ex.server.cfg.NotificationRegistry == nil {
+		return emptyNotifications{}
+	}
+	return connExNotificationsAccessor{
+		ex: ex,
+	}
+}
+
+// bufferNotifications buffers the notifications queued for the session into
+// the given result.
+func (ex *connExecutor) bufferNotifications(ctx context.Context, res NotificationSender) {
+	if ex.listener == nil {
+		return
+	}
+	for _, n := range ex.listener.Drain(ctx) {
+		res.BufferNotification(n)
+	}
+}
+
 // sessionEventf logs a message to the session event log (if any).
 func (ex *connExecutor) sessionEventf(ctx context.Context, format string, args ...interface{}) {
 	if log.ExpensiveLogEnabled(ctx, 2) {
@@ -3564,7 +3717,7 @@ func (sc *StatementCounters) incrementCount(ex *connExecutor, stmt tree.Statemen
 		} else {
 			sc.RollbackToSavepointCount.Inc()
 		}
-	case *tree.CopyFrom:
+	case *tree.CopyFrom, *tree.CopyTo:
 		sc.CopyCount.Inc()
 	default:
 		if tree.CanModifySchema(stmt) {
@@ -702,7 +702,7 @@ func (ex *connExecutor) execStmtInOpenState(
 	// well as in-between very stage of cascading actions.
 	// This TODO can be removed when the cascading code is reorganized
 	// accordingly and the missing call to Step() is introduced.
-	if err := ex.state.mu.txn.Step(ctx); err != nil {
+	if err := ex.state.mu.txn.Step(ctx, false /* allowReadTimestampStep */); err != nil {
 		return makeErrEvent(err)
 	}
 
@@ -749,9 +749,15 @@ func (ex *connExecutor) execStmtInOpenState(
 		stmtCtx = ctx
 	}
 
-	if err := ex.dispatchToExecutionEngine(stmtCtx, p, res); err != nil {
+	var dispatchErr error
+	if ex.executorType != executorTypeInternal && ex.state.mu.txn.IsoLevel().PerStatementReadSnapshot() {
+		dispatchErr = ex.dispatchReadCommittedStmtToExecutionEngine(stmtCtx, p, res)
+	} else {
+		dispatchErr = ex.dispatchToExecutionEngine(stmtCtx, p, res)
+	}
+	if dispatchErr != nil {
 		stmtThresholdSpan.Finish()
-		return nil, nil, err
+		return nil, nil, dispatchErr
 	}
 
 	if stmtThresholdSpan != nil {
@@ -1006,6 +1012,13 @@ func (ex *connExecutor) commitSQLTransactionInternal(ctx context.Context) error
 	ctx, sp := tracing.EnsureChildSpan(ctx, ex.server.cfg.AmbientCtx.Tracer, "commit sql txn")
 	defer sp.Finish()
 
+	// Validate the DEFERRABLE constraints whose deferred checks failed.
+	if err := ex.planner.validateDeferredConstraints(
+		ctx, ex.state.mu.txn, ex.getDeferredConstraintsAccessor().takePending(),
+	); err != nil {
+		return err
+	}
+
 	if err := ex.createJobs(ctx); err != nil {
 		return err
 	}
@@ -1024,6 +1037,12 @@ func (ex *connExecutor) commitSQLTransactionInternal(ctx context.Context) error
 		return err
 	}
 
+	// WITH HOLD cursors need to read all of their rows before the transaction
+	// goes away.
+	if err := ex.extraTxnState.sqlCursors.persistHoldCursors(ctx, &ex.planner); err != nil {
+		return err
+	}
+
 	if err := ex.state.mu.txn.Commit(ctx); err != nil {
 		return err
 	}
@@ -1073,6 +1092,78 @@ func (ex *connExecutor) rollbackSQLTransaction(
 	return eventTxnFinishAborted{}, nil
 }
 
+// dispatchReadCommittedStmtToExecutionEngine executes a statement of a
+// transaction that uses a per-statement read snapshot (i.e. a READ COMMITTED
+// transaction). The statement reads at a new snapshot, established when it
+// starts. If it encounters a retryable error that can be handled without
+// restarting the transaction, its effects are rolled back to a savepoint
+// created when it started and it is retried at a higher timestamp, up to
+// max_retries_for_read_committed times. The statement can only be retried if
+// none of its results have been delivered to the client yet; otherwise, or once
+// the retries are exhausted, the retryable error is left in res for the
+// connection's state machine to handle, like for any other transaction.
+//
+// The returned error has the same meaning as for dispatchToExecutionEngine.
+func (ex *connExecutor) dispatchReadCommittedStmtToExecutionEngine(
+	ctx context.Context, p *planner, res RestrictedCommandResult,
+) error {
+	txn := ex.state.mu.txn
+	maxRetries := int(ex.sessionData().MaxRetriesForReadCommitted)
+	for attemptNum := 0; ; attemptNum++ {
+		// Establish a new read snapshot for the statement.
+		if err := txn.Step(ctx, true /* allowReadTimestampStep */); err != nil {
+			res.SetError(err)
+			return nil
+		}
+		token, err := txn.CreateSavepoint(ctx)
+		if err != nil {
+			res.SetError(err)
+			return nil
+		}
+		// The notifications sent and the LISTEN and UNLISTEN statements executed
+		// by the statement are discarded along with its writes if it is retried.
+		sp := savepoint{
+			kvToken:          token,
+			numListenOps:     len(ex.extraTxnState.notifications.ops),
+			numNotifications: len(ex.extraTxnState.notifications.sent),
+		}
+		bufferPos := res.BufferedResultsLen()
+		if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
+			return err
+		}
+
+		maybeRetryableErr := res.Err()
+		if maybeRetryableErr == nil {
+			return nil
+		}
+		var retryErr *roachpb.TransactionRetryWithProtoRefreshError
+		if !errors.As(maybeRetryableErr, &retryErr) || !retryErr.PartialRetry {
+			return nil
+		}
+		if attemptNum >= maxRetries {
+			log.VEventf(ctx, 2, "not retrying statement after %d attempts: %v",
+				attemptNum+1, maybeRetryableErr)
+			return nil
+		}
+		if !res.TruncateBufferedResults(bufferPos) {
+			log.VEventf(ctx, 2, "cannot retry statement whose results were delivered: %v",
+				maybeRetryableErr)
+			return nil
+		}
+		log.VEventf(ctx, 2, "retrying statement after: %v", maybeRetryableErr)
+		if err := txn.PrepareForPartialRetry(ctx); err != nil {
+			res.SetError(err)
+			return nil
+		}
+		if err := txn.RollbackToSavepoint(ctx, sp.kvToken); err != nil {
+			res.SetError(err)
+			return nil
+		}
+		ex.extraTxnState.notifications.rollbackTo(&sp)
+		res.SetError(nil)
+	}
+}
+
 // dispatchToExecutionEngine executes the statement, writes the result to res
 // and returns an event for the connection's state machine.
 //
@@ -1656,6 +1747,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventStartExplicitTxn,
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(s.Modes.UserPriority),
+				ex.txnIsolationLevelWithSessionDefault(ctx, s.Modes.Isolation),
 				mode,
 				sqlTs,
 				historicalTs,
@@ -1677,6 +1769,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventStartImplicitTxn,
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(tree.UnspecifiedUserPriority),
+				ex.txnIsolationLevelWithSessionDefault(ctx, tree.UnspecifiedIsolation),
 				mode,
 				sqlTs,
 				historicalTs,
@@ -1707,6 +1800,7 @@ func (ex *connExecutor) beginImplicitTxn(
 	return eventStartImplicitTxn,
 		makeEventTxnStartPayload(
 			ex.txnPriorityWithSessionDefault(tree.UnspecifiedUserPriority),
+			ex.txnIsolationLevelWithSessionDefault(ctx, tree.UnspecifiedIsolation),
 			mode,
 			sqlTs,
 			historicalTs,
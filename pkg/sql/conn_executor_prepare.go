@@ -149,9 +149,11 @@ func (ex *connExecutor) addPreparedStmt(
 	copy(prepared.InferredTypes, rawTypeHints)
 	for i, it := range prepared.InferredTypes {
 		// If the client did not provide an OID type hint, then infer the OID.
+		// Placeholders of a domain type are reported, and decoded, as values of
+		// the base type; they are checked against the domain when evaluated.
 		if it == 0 || it == oid.T_unknown {
 			if t, ok := prepared.ValueType(tree.PlaceholderIdx(i)); ok {
-				prepared.InferredTypes[i] = t.Oid()
+				prepared.InferredTypes[i] = t.DomainBaseType().Oid()
 			}
 		}
 	}
@@ -105,6 +105,9 @@ func (ex *connExecutor) execSavepointInOpenState(
 		commitOnRelease: commitOnRelease,
 		kvToken:         token,
 		numDDL:          ex.extraTxnState.numDDL,
+
+		numListenOps:     len(ex.extraTxnState.notifications.ops),
+		numNotifications: len(ex.extraTxnState.notifications.sent),
 	}
 	savepoints.push(sp)
 	ex.sessionDataStack.PushTopClone()
@@ -207,6 +210,7 @@ func (ex *connExecutor) execRollbackToSavepointInOpenState(
 	if err := ex.popSavepointsToIdx(s, idx); err != nil {
 		return ex.makeErrEvent(err, s)
 	}
+	ex.extraTxnState.notifications.rollbackTo(entry)
 
 	if entry.kvToken.Initial() {
 		return eventTxnRestart{}, nil
@@ -283,6 +287,7 @@ func (ex *connExecutor) execRollbackToSavepointInAbortedState(
 	if err := ex.popSavepointsToIdx(s, idx); err != nil {
 		return ex.makeErrEvent(err, s)
 	}
+	ex.extraTxnState.notifications.rollbackTo(entry)
 
 	if err := ex.state.mu.txn.RollbackToSavepoint(ctx, entry.kvToken); err != nil {
 		return ex.makeErrEvent(err, s)
@@ -351,6 +356,12 @@ type savepoint struct {
 	// more DDL statements were executed since the savepoint's creation.
 	// TODO(knz): support partial DDL cancellation in pending txns.
 	numDDL int
+
+	// The number of LISTEN/UNLISTEN statements executed and notifications sent
+	// by the transaction at the time the savepoint was created. The ones which
+	// came later are discarded when rolling back to the savepoint.
+	numListenOps     int
+	numNotifications int
 }
 
 type savepointStack []savepoint
@@ -19,6 +19,7 @@ package sql
 import (
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
@@ -112,6 +113,9 @@ type eventTxnStartPayload struct {
 	tranCtx transitionCtx
 
 	pri roachpb.UserPriority
+	// isoLevel is the isolation level of the transaction that is started by
+	// this event.
+	isoLevel isolation.Level
 	// txnSQLTimestamp is the timestamp that statements executed in the
 	// transaction that is started by this event will report for now(),
 	// current_timestamp(), transaction_timestamp().
@@ -126,6 +130,7 @@ type eventTxnStartPayload struct {
 // makeEventTxnStartPayload creates an eventTxnStartPayload.
 func makeEventTxnStartPayload(
 	pri roachpb.UserPriority,
+	isoLevel isolation.Level,
 	readOnly tree.ReadWriteMode,
 	txnSQLTimestamp time.Time,
 	historicalTimestamp *hlc.Timestamp,
@@ -134,6 +139,7 @@ func makeEventTxnStartPayload(
 ) eventTxnStartPayload {
 	return eventTxnStartPayload{
 		pri:                 pri,
+		isoLevel:            isoLevel,
 		readOnly:            readOnly,
 		txnSQLTimestamp:     txnSQLTimestamp,
 		historicalTimestamp: historicalTimestamp,
@@ -504,6 +510,7 @@ func noTxnToOpen(args fsm.Args) error {
 		payload.txnSQLTimestamp,
 		payload.historicalTimestamp,
 		payload.pri,
+		payload.isoLevel,
 		payload.readOnly,
 		nil, /* txn */
 		payload.tranCtx,
@@ -19,6 +19,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
@@ -359,6 +360,23 @@ func (DrainRequest) String() string {
 
 var _ Command = DrainRequest{}
 
+// DeliverNotifications is pushed by the session's notify.Listener when
+// notifications are queued for the session. The notifications are sent to the
+// client if the connection is idle; otherwise they are sent at the next Sync.
+//
+// DeliverNotifications commands don't produce results other than the
+// notifications.
+type DeliverNotifications struct{}
+
+// command implements the Command interface.
+func (DeliverNotifications) command() string { return "deliver notifications" }
+
+func (DeliverNotifications) String() string {
+	return "DeliverNotifications"
+}
+
+var _ Command = DeliverNotifications{}
+
 // SendError is a command that, upon execution, send a specific error to the
 // client. This is used by pgwire to schedule errors to be sent at an
 // appropriate time.
@@ -631,6 +649,9 @@ type ClientComm interface {
 	CreateCopyInResult(pos CmdPos) CopyInResult
 	// CreateDrainResult creates a result for a Drain command.
 	CreateDrainResult(pos CmdPos) DrainResult
+	// CreateNotificationResult creates a result for a DeliverNotifications
+	// command.
+	CreateNotificationResult(pos CmdPos) NotificationResult
 
 	// lockCommunication ensures that no further results are delivered to the
 	// client. The returned ClientLock can be queried to see what results have
@@ -753,6 +774,19 @@ type RestrictedCommandResult interface {
 	// to this CommandResult, will be flushed immediately to the client.
 	// This is currently used for sinkless changefeeds.
 	DisableBuffering()
+
+	// BufferedResultsLen returns the length of the results that have been
+	// buffered for the client so far. It can be passed to
+	// TruncateBufferedResults in order to discard the results produced after
+	// this call.
+	BufferedResultsLen() int
+
+	// TruncateBufferedResults discards the results buffered after the given
+	// length, which must have been obtained from BufferedResultsLen before
+	// any results were added to this CommandResult, and resets the number of
+	// rows affected. It returns false if the results can't be discarded
+	// because some of them have already been delivered to the client.
+	TruncateBufferedResults(idx int) bool
 }
 
 // DescribeResult represents the result of a Describe command (for either
@@ -798,6 +832,7 @@ type DeleteResult interface {
 // flushed.
 type SyncResult interface {
 	ResultBase
+	NotificationSender
 }
 
 // FlushResult represents the result of a Flush command. When this result is
@@ -812,6 +847,21 @@ type DrainResult interface {
 	ResultBase
 }
 
+// NotificationResult represents the result of a DeliverNotifications command.
+// When closed, the buffered notifications are sent to the client and flushed.
+type NotificationResult interface {
+	ResultBase
+	NotificationSender
+}
+
+// NotificationSender is implemented by the results which can carry
+// asynchronous notifications to the client.
+type NotificationSender interface {
+	// BufferNotification buffers a notification, which is sent to the client
+	// when the result is closed.
+	BufferNotification(n notify.Notification)
+}
+
 // EmptyQueryResult represents the result of an empty query (a query
 // representing a blank string).
 type EmptyQueryResult interface {
@@ -921,6 +971,11 @@ func (r *streamingCommandResult) BufferNotice(notice pgnotice.Notice) {
 	panic("unimplemented")
 }
 
+// BufferNotification is part of the NotificationSender interface.
+func (r *streamingCommandResult) BufferNotification(n notify.Notification) {
+	panic("unimplemented")
+}
+
 // ResetStmtType is part of the RestrictedCommandResult interface.
 func (r *streamingCommandResult) ResetStmtType(stmt tree.Statement) {
 	panic("unimplemented")
@@ -952,6 +1007,17 @@ func (r *streamingCommandResult) DisableBuffering() {
 	panic("cannot disable buffering here")
 }
 
+// BufferedResultsLen is part of the RestrictedCommandResult interface.
+func (r *streamingCommandResult) BufferedResultsLen() int {
+	// Results are never buffered; they are sent on the channel right away.
+	return 0
+}
+
+// TruncateBufferedResults is part of the RestrictedCommandResult interface.
+func (r *streamingCommandResult) TruncateBufferedResults(idx int) bool {
+	return false
+}
+
 // SetError is part of the RestrictedCommandResult interface.
 func (r *streamingCommandResult) SetError(err error) {
 	r.err = err
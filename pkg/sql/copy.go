@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -37,6 +38,13 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
 )
 
 type copyMachineInterface interface {
@@ -65,6 +73,7 @@ type copyMachine struct {
 	expectedHiddenColumnIdxs []int
 	format                   tree.CopyFormat
 	csvEscape                rune
+	csvQuote                 byte
 	delimiter                byte
 	// textDelim is delimiter converted to a []byte so that we don't have to do that per row.
 	textDelim   []byte
@@ -74,8 +83,22 @@ type copyMachine struct {
 	// NULL. The spec says this is only supported for CSV, and also must specify
 	// which columns it applies to.
 	forceNotNull bool
-	csvInput     bytes.Buffer
-	csvReader    *csv.Reader
+	// csvForceNotNull and csvForceNull hold the FORCE_NOT_NULL and FORCE_NULL
+	// options of CSV input, indexed by result column. The former disables
+	// converting unquoted values matching the null string to NULL, the latter
+	// enables converting quoted values matching the null string to NULL.
+	csvForceNotNull []bool
+	csvForceNull    []bool
+	// skipHeader is set while the header line of the input, if any, has not
+	// been skipped yet.
+	skipHeader bool
+	csvInput   bytes.Buffer
+	csvReader  *csv.Reader
+	// decoder transcodes the input into UTF-8 if the ENCODING option names
+	// another encoding. undecoded holds an incomplete multi-byte character
+	// between protocol messages.
+	decoder   *encoding.Decoder
+	undecoded []byte
 	// buf is used to parse input data into rows. It also accumulates a partial
 	// row between protocol messages.
 	buf bytes.Buffer
@@ -125,10 +148,11 @@ func newCopyMachine(
 		copyFromAST: n,
 		// TODO(georgiah): Currently, insertRows depends on Table and Columns,
 		//  but that dependency can be removed by refactoring it.
-		table:   &n.Table,
-		columns: n.Columns,
-		format:  n.Options.CopyFormat,
-		txnOpt:  txnOpt,
+		table:    &n.Table,
+		columns:  n.Columns,
+		format:   n.Options.CopyFormat,
+		csvQuote: '"',
+		txnOpt:   txnOpt,
 		// The planner will be prepared before use.
 		p:              planner{execCfg: execCfg, alloc: &tree.DatumAlloc{}},
 		execInsertPlan: execInsertPlan,
@@ -199,6 +223,69 @@ func newCopyMachine(
 
 		c.csvEscape, _ = utf8.DecodeRuneInString(s)
 	}
+	if n.Options.Quote != nil {
+		s := n.Options.Quote.RawString()
+		if c.format != tree.CopyFormatCSV {
+			return nil, pgerror.Newf(
+				pgcode.FeatureNotSupported,
+				"QUOTE can only be specified for CSV",
+			)
+		}
+		if len(s) != 1 {
+			return nil, pgerror.Newf(
+				pgcode.FeatureNotSupported,
+				"QUOTE must be a single-byte character",
+			)
+		}
+		if s[0] == c.delimiter {
+			return nil, pgerror.Newf(
+				pgcode.InvalidParameterValue,
+				"COPY delimiter and quote must be different",
+			)
+		}
+		c.csvQuote = s[0]
+	}
+	if n.Options.Header {
+		if c.format == tree.CopyFormatBinary {
+			return nil, pgerror.Newf(
+				pgcode.FeatureNotSupported,
+				"HEADER unsupported in BINARY format",
+			)
+		}
+		c.skipHeader = true
+	}
+	if n.Options.Oids {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported, "COPY with OIDS is not supported")
+	}
+	// FREEZE is only a performance hint in Postgres, and is accepted as a no-op.
+	if n.Options.ForceQuote != nil || n.Options.ForceQuoteAll {
+		return nil, pgerror.Newf(
+			pgcode.FeatureNotSupported,
+			"FORCE QUOTE only available using COPY TO",
+		)
+	}
+	if (n.Options.ForceNotNull != nil || n.Options.ForceNull != nil) &&
+		c.format != tree.CopyFormatCSV {
+		return nil, pgerror.Newf(
+			pgcode.FeatureNotSupported,
+			"FORCE NOT NULL and FORCE NULL can only be specified for CSV",
+		)
+	}
+	if n.Options.Encoding != nil {
+		if c.format == tree.CopyFormatBinary {
+			return nil, pgerror.Newf(
+				pgcode.FeatureNotSupported,
+				"ENCODING unsupported in BINARY format",
+			)
+		}
+		enc, err := copyEncoding(n.Options.Encoding.RawString())
+		if err != nil {
+			return nil, err
+		}
+		if enc != nil {
+			c.decoder = enc.NewDecoder()
+		}
+	}
 
 	flags := tree.ObjectLookupFlagsWithRequiredTableKind(tree.ResolveRequireTableDesc)
 	_, tableDesc, err := resolver.ResolveExistingTableObject(ctx, &c.p, &n.Table, flags)
@@ -222,6 +309,16 @@ func newCopyMachine(
 			PGAttributeNum: col.GetPGAttributeNum(),
 		}
 	}
+	if c.csvForceNotNull, err = c.resolveForceColumns(
+		"FORCE_NOT_NULL", n.Options.ForceNotNull,
+	); err != nil {
+		return nil, err
+	}
+	if c.csvForceNull, err = c.resolveForceColumns(
+		"FORCE_NULL", n.Options.ForceNull,
+	); err != nil {
+		return nil, err
+	}
 	// If there are no column specifiers and we expect non-visible columns
 	// to have field data then we have to populate the expectedHiddenColumnIdxs
 	// field with the columns indexes we expect to be hidden.
@@ -238,6 +335,109 @@ func newCopyMachine(
 	return c, nil
 }
 
+// resolveForceColumns maps the columns named by a FORCE_NOT_NULL or
+// FORCE_NULL option to the result columns of the COPY. It returns nil if no
+// columns are named.
+func (c *copyMachine) resolveForceColumns(opt string, names tree.NameList) ([]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	force := make([]bool, len(c.resultColumns))
+	for _, name := range names {
+		found := false
+		for i := range c.resultColumns {
+			if c.resultColumns[i].Name == string(name) {
+				force[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, pgerror.Newf(pgcode.InvalidColumnReference,
+				"%s column %q not referenced by COPY", opt, string(name))
+		}
+	}
+	return force, nil
+}
+
+// copyEncoding returns the encoding named by the ENCODING option of COPY, or
+// nil if the input is already UTF-8 and needs no transcoding. The names and
+// their aliases are those recognized by Postgres.
+func copyEncoding(name string) (encoding.Encoding, error) {
+	switch builtins.CleanEncodingName(name) {
+	case "utf8", "unicode", "cp65001", "sqlascii":
+		return nil, nil
+	case "latin1", "iso88591", "cp28591":
+		return charmap.ISO8859_1, nil
+	case "latin2", "iso88592":
+		return charmap.ISO8859_2, nil
+	case "latin3", "iso88593":
+		return charmap.ISO8859_3, nil
+	case "latin4", "iso88594":
+		return charmap.ISO8859_4, nil
+	case "iso88595":
+		return charmap.ISO8859_5, nil
+	case "iso88596":
+		return charmap.ISO8859_6, nil
+	case "iso88597":
+		return charmap.ISO8859_7, nil
+	case "iso88598":
+		return charmap.ISO8859_8, nil
+	case "latin5", "iso88599":
+		return charmap.ISO8859_9, nil
+	case "latin6", "iso885910":
+		return charmap.ISO8859_10, nil
+	case "latin7", "iso885913":
+		return charmap.ISO8859_13, nil
+	case "latin8", "iso885914":
+		return charmap.ISO8859_14, nil
+	case "latin9", "iso885915":
+		return charmap.ISO8859_15, nil
+	case "latin10", "iso885916":
+		return charmap.ISO8859_16, nil
+	case "win866", "alt":
+		return charmap.CodePage866, nil
+	case "win874":
+		return charmap.Windows874, nil
+	case "win1250":
+		return charmap.Windows1250, nil
+	case "win1251", "win":
+		return charmap.Windows1251, nil
+	case "win1252":
+		return charmap.Windows1252, nil
+	case "win1253":
+		return charmap.Windows1253, nil
+	case "win1254":
+		return charmap.Windows1254, nil
+	case "win1255":
+		return charmap.Windows1255, nil
+	case "win1256":
+		return charmap.Windows1256, nil
+	case "win1257":
+		return charmap.Windows1257, nil
+	case "win1258":
+		return charmap.Windows1258, nil
+	case "koi8", "koi8r":
+		return charmap.KOI8R, nil
+	case "koi8u":
+		return charmap.KOI8U, nil
+	case "eucjp":
+		return japanese.EUCJP, nil
+	case "sjis", "shiftjis", "mskanji", "win932":
+		return japanese.ShiftJIS, nil
+	case "euckr", "uhc", "win949":
+		return korean.EUCKR, nil
+	case "gbk", "win936":
+		return simplifiedchinese.GBK, nil
+	case "gb18030":
+		return simplifiedchinese.GB18030, nil
+	case "big5", "win950":
+		return traditionalchinese.Big5, nil
+	}
+	return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+		"%q is not a valid encoding name", name)
+}
+
 func (c *copyMachine) numInsertedRows() int {
 	if c == nil {
 		return 0
@@ -292,10 +492,18 @@ func (c *copyMachine) run(ctx context.Context) error {
 		c.csvReader.Comma = rune(c.delimiter)
 		c.csvReader.ReuseRecord = true
 		c.csvReader.FieldsPerRecord = len(c.resultColumns) + len(c.expectedHiddenColumnIdxs)
+		c.csvReader.Quote = rune(c.csvQuote)
+		// Like in Postgres, the escape character defaults to the quote
+		// character.
+		c.csvReader.Escape = rune(c.csvQuote)
 		if c.csvEscape != 0 {
 			c.csvReader.Escape = c.csvEscape
 		}
 	}
+	if c.decoder != nil {
+		c.decoder.Reset()
+		c.undecoded = nil
+	}
 
 Loop:
 	for {
@@ -391,6 +599,12 @@ func (c *copyMachine) processCopyData(ctx context.Context, data string, final bo
 			return err
 		}
 	}
+	if c.decoder != nil {
+		var err error
+		if data, err = c.decodeCopyData(data, final); err != nil {
+			return err
+		}
+	}
 	c.buf.WriteString(data)
 	var readFn func(ctx context.Context, final bool) (brk bool, err error)
 	switch c.format {
@@ -419,6 +633,34 @@ func (c *copyMachine) processCopyData(ctx context.Context, data string, final bo
 	return c.processRows(ctx)
 }
 
+// decodeCopyData transcodes data from the encoding of the ENCODING option into
+// UTF-8. A multi-byte character split across protocol messages is kept in
+// c.undecoded until the rest of it arrives.
+func (c *copyMachine) decodeCopyData(data string, final bool) (string, error) {
+	src := append(c.undecoded, data...)
+	c.undecoded = nil
+	var out strings.Builder
+	out.Grow(len(src))
+	var dst [4096]byte
+	for {
+		nDst, nSrc, err := c.decoder.Transform(dst[:], src, final)
+		out.Write(dst[:nDst])
+		src = src[nSrc:]
+		switch err {
+		case nil:
+			return out.String(), nil
+		case transform.ErrShortDst:
+			continue
+		case transform.ErrShortSrc:
+			c.undecoded = append(c.undecoded, src...)
+			return out.String(), nil
+		default:
+			return "", pgerror.Wrap(err, pgcode.CharacterNotInRepertoire,
+				"could not decode COPY data")
+		}
+	}
+}
+
 func (c *copyMachine) readTextData(ctx context.Context, final bool) (brk bool, err error) {
 	line, err := c.buf.ReadBytes(lineDelim)
 	if err != nil {
@@ -440,6 +682,10 @@ func (c *copyMachine) readTextData(ctx context.Context, final bool) (brk bool, e
 	if c.buf.Len() == 0 && bytes.Equal(line, []byte(`\.`)) {
 		return true, nil
 	}
+	if c.skipHeader {
+		c.skipHeader = false
+		return false, nil
+	}
 	err = c.readTextTuple(ctx, line)
 	return false, err
 }
@@ -471,22 +717,18 @@ func (c *copyMachine) readCSVData(ctx context.Context, final bool) (brk bool, er
 
 		// Now we need to calculate if we are have reached the end of the quote.
 		// If so, break out.
-		if c.csvEscape == 0 {
-			// CSV escape is not specified and hence defaults to '"'.¥
+		if c.csvEscape == 0 || c.csvEscape == rune(c.csvQuote) {
+			// CSV escape is not specified and hence defaults to the QUOTE char.
 			// At this point, we know fullLine ends in '\n'. Keep track of the total
 			// number of QUOTE chars in fullLine -- if it is even, then it means that
 			// the quotes are balanced and '\n' is not in a quoted field.
-			// Currently, the QUOTE char and ESCAPE char are both always equal to '"'
-			// and are not configurable. As per the COPY spec, any appearance of the
-			// QUOTE or ESCAPE characters in an actual value must be preceded by an
-			// ESCAPE character. This means that an escaped '"' also results in an even
-			// number of '"' characters.
-			// This branch is kept in the interests of "backporting safely" - this
-			// was the old code. Users who use COPY ... ESCAPE will be the only
-			// ones hitting the new code below.
-			quoteCharsSeen += bytes.Count(line, []byte{'"'})
+			// As per the COPY spec, any appearance of the QUOTE or ESCAPE characters
+			// in an actual value must be preceded by an ESCAPE character. Since the
+			// QUOTE char and ESCAPE char are equal here, an escaped QUOTE char also
+			// results in an even number of QUOTE chars.
+			quoteCharsSeen += bytes.Count(line, []byte{c.csvQuote})
 		} else {
-			// Otherwise, we have to do a manual count of double quotes and
+			// Otherwise, we have to do a manual count of quotes and
 			// ignore any escape characters preceding quotes for counting.
 			// For example, if the escape character is '\', we should ignore
 			// the intermediate quotes in a string such as `"start"\"\"end"`.
@@ -496,7 +738,7 @@ func (c *copyMachine) readCSVData(ctx context.Context, final bool) (brk bool, er
 					skipNextChar = false
 					continue
 				}
-				if ch == '"' {
+				if ch == c.csvQuote {
 					quoteCharsSeen++
 				}
 				if rune(ch) == c.csvEscape {
@@ -516,6 +758,12 @@ func (c *copyMachine) readCSVData(ctx context.Context, final bool) (brk bool, er
 	if len(record) == 1 && !record[0].Quoted && record[0].Val == endOfData && c.buf.Len() == 0 {
 		return true, nil
 	}
+	// The header is skipped before checking for errors, since it need not have
+	// the same number of fields as the data.
+	if c.skipHeader {
+		c.skipHeader = false
+		return false, nil
+	}
 	if err != nil {
 		return false, pgerror.Wrap(err, pgcode.BadCopyFileFormat,
 			"read CSV record")
@@ -546,9 +794,11 @@ func (c *copyMachine) readCSVTuple(ctx context.Context, record []csv.Record) err
 	record = c.maybeIgnoreHiddenColumnsStr(record)
 	exprs := make(tree.Exprs, len(record))
 	for i, s := range record {
-		// NB: When we implement FORCE_NULL, then quoted values also are allowed
-		// to be treated as NULL.
-		if !s.Quoted && s.Val == c.null {
+		// Unquoted values matching the null string are NULL unless FORCE_NOT_NULL
+		// is set for the column; quoted values only if FORCE_NULL is set.
+		forceNotNull := c.csvForceNotNull != nil && c.csvForceNotNull[i]
+		forceNull := c.csvForceNull != nil && c.csvForceNull[i]
+		if s.Val == c.null && ((!s.Quoted && !forceNotNull) || (s.Quoted && forceNull)) {
 			exprs[i] = tree.DNull
 			continue
 		}
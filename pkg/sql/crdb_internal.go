@@ -50,6 +50,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -65,6 +66,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/persistedsqlstats/sqlstatsutil"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/sslocal"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
@@ -2423,6 +2425,52 @@ func writeCreateTypeDescRow(
 			tree.NewDString(tree.AsString(node)),      // create_statement
 			enumLabelsDatum,
 		)
+	case descpb.TypeDescriptor_COMPOSITE, descpb.TypeDescriptor_DOMAIN:
+		name, err := tree.NewUnresolvedObjectName(2, [3]string{typeDesc.GetName(), sc}, 0)
+		if err != nil {
+			return false, err
+		}
+		node := &tree.CreateType{TypeName: name}
+		if composite := typeDesc.TypeDesc().Composite; composite != nil {
+			node.Variety = tree.Composite
+			for _, e := range composite.Elements {
+				node.CompositeTypeList = append(node.CompositeTypeList, tree.CompositeTypeElem{
+					Label: tree.Name(e.ElementLabel),
+					Type:  makeCreateTypeStmtTypeRef(e.ElementType),
+				})
+			}
+		} else {
+			domain := typeDesc.TypeDesc().Domain
+			node.Variety = tree.Domain
+			node.Domain = &tree.DomainDef{
+				BaseType: makeCreateTypeStmtTypeRef(domain.BaseType),
+				NotNull:  domain.NotNull,
+			}
+			if domain.DefaultExpr != nil {
+				if node.Domain.DefaultExpr, err = parser.ParseExpr(*domain.DefaultExpr); err != nil {
+					return false, err
+				}
+			}
+			for _, check := range domain.Checks {
+				expr, err := parser.ParseExpr(check.Expr)
+				if err != nil {
+					return false, err
+				}
+				node.Domain.Checks = append(node.Domain.Checks, tree.DomainCheck{
+					Name: tree.Name(check.Name),
+					Expr: expr,
+				})
+			}
+		}
+		return true, addRow(
+			tree.NewDInt(tree.DInt(db.GetID())),       // database_id
+			tree.NewDString(db.GetName()),             // database_name
+			tree.NewDString(sc),                       // schema_name
+			tree.NewDInt(tree.DInt(typeDesc.GetID())), // descriptor_id
+			tree.NewDString(typeDesc.GetName()),       // descriptor_name
+			tree.NewDString(tree.AsString(node)),      // create_statement
+			tree.DNull,                                // enum_members
+		)
 	case descpb.TypeDescriptor_MULTIREGION_ENUM:
 		// Multi-region enums are created implicitly, so we don't have create
 		// statements for them.
@@ -2436,6 +2484,16 @@ func writeCreateTypeDescRow(
 	}
 }
 
+// makeCreateTypeStmtTypeRef returns a reference to typ for use in a CREATE
+// statement. The user defined types referenced by type descriptors are not
+// hydrated, so they are referenced by OID.
+func makeCreateTypeStmtTypeRef(typ *types.T) tree.ResolvableTypeReference {
+	if typ.UserDefined() {
+		return &tree.OIDTypeReference{OID: typ.Oid()}
+	}
+	return typ
+}
+
 var crdbInternalCreateTypeStmtsTable = virtualSchemaTable{
 	comment: "CREATE statements for all user defined types accessible by the current user in current database (KV scan)",
 	schema: `
@@ -4443,7 +4501,8 @@ CREATE TABLE crdb_internal.kv_store_status (
   bytes_per_replica  JSON NOT NULL,
   writes_per_replica JSON NOT NULL,
   metrics            JSON NOT NULL,
-  properties         JSON NOT NULL
+  properties         JSON NOT NULL,
+  encryption_status  JSON
 )
 	`,
 	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
@@ -4461,6 +4520,10 @@ CREATE TABLE crdb_internal.kv_store_status (
 		}
 
 		for _, n := range response.Nodes {
+			encryptionStatuses, err := getEncryptionStatusesForNode(ctx, ss, &n)
+			if err != nil {
+				return err
+			}
 			for _, s := range n.StoreStatuses {
 				attrs := json.NewArrayBuilder(len(s.Desc.Attrs.Attrs))
 				for _, a := range s.Desc.Attrs.Attrs {
@@ -4533,6 +4596,11 @@ CREATE TABLE crdb_internal.kv_store_status (
 					return err
 				}
 
+				encryptionStatus := tree.DNull
+				if status, ok := encryptionStatuses[s.Desc.StoreID]; ok {
+					encryptionStatus = tree.NewDJSON(status)
+				}
+
 				if err := addRow(
 					tree.NewDInt(tree.DInt(s.Desc.Node.NodeID)),
 					tree.NewDInt(tree.DInt(s.Desc.StoreID)),
@@ -4548,6 +4616,7 @@ CREATE TABLE crdb_internal.kv_store_status (
 					tree.NewDJSON(writesPerReplica),
 					tree.NewDJSON(metrics.Build()),
 					tree.NewDJSON(properties.Build()),
+					encryptionStatus,
 				); err != nil {
 					return err
 				}
@@ -4557,6 +4626,56 @@ CREATE TABLE crdb_internal.kv_store_status (
 	},
 }
 
+// getEncryptionStatusesForNode returns the encryption status of the encrypted
+// stores of the given node, keyed by store ID. The node is only contacted if
+// at least one of its stores is encrypted.
+func getEncryptionStatusesForNode(
+	ctx context.Context, ss serverpb.NodesStatusServer, n *statuspb.NodeStatus,
+) (map[roachpb.StoreID]json.JSON, error) {
+	encrypted := false
+	for _, s := range n.StoreStatuses {
+		encrypted = encrypted || s.Desc.Properties.Encrypted
+	}
+	if !encrypted {
+		return nil, nil
+	}
+
+	response, err := ss.Stores(ctx, &serverpb.StoresRequest{NodeId: n.Desc.NodeID.String()})
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[roachpb.StoreID]json.JSON, len(response.Stores))
+	for _, store := range response.Stores {
+		if len(store.EncryptionStatus) == 0 {
+			continue
+		}
+		var status encryptionpb.EncryptionStatus
+		if err := protoutil.Unmarshal(store.EncryptionStatus, &status); err != nil {
+			return nil, err
+		}
+		b := json.NewObjectBuilder(2)
+		b.Add("active_store_key", encryptionKeyInfoToJSON(status.ActiveStoreKey))
+		b.Add("active_data_key", encryptionKeyInfoToJSON(status.ActiveDataKey))
+		statuses[store.StoreID] = b.Build()
+	}
+	return statuses, nil
+}
+
+// encryptionKeyInfoToJSON returns the non-sensitive fields of an encryption
+// key description as JSON.
+func encryptionKeyInfoToJSON(info *encryptionpb.KeyInfo) json.JSON {
+	if info == nil {
+		return json.NullJSONValue
+	}
+	b := json.NewObjectBuilder(5)
+	b.Add("key_id", json.FromString(info.KeyId))
+	b.Add("encryption_type", json.FromString(info.EncryptionType.String()))
+	b.Add("creation_time", json.FromInt64(info.CreationTime))
+	b.Add("source", json.FromString(info.Source))
+	b.Add("was_exposed", json.FromBool(info.WasExposed))
+	return b.Build()
+}
+
 // crdbInternalPredefinedComments exposes the predefined
 // comments for virtual tables. This is used by SHOW TABLES WITH COMMENT
 // as fall-back when system.comments is silent.
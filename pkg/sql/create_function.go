@@ -0,0 +1,349 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descidgen"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// createFunctionNode represents a CREATE FUNCTION statement.
+type createFunctionNode struct {
+	// cf is the CREATE FUNCTION statement. The function name is fully
+	// qualified and the argument and return types are resolved.
+	cf     *tree.CreateFunction
+	dbDesc catalog.DatabaseDescriptor
+	scDesc catalog.SchemaDescriptor
+
+	// planDeps tracks which tables and views the body of the function depends
+	// on. This is collected during the construction of the logical plan of the
+	// body.
+	planDeps planDependencies
+
+	// typeDeps tracks which types the function depends on, including the
+	// types of its arguments and of its result.
+	typeDeps typeDependencies
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE FUNCTION performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createFunctionNode) ReadingOwnWrites() {}
+
+func (n *createFunctionNode) startExec(params runParams) error {
+	// Nodes running an older version cannot read function descriptors.
+	if !params.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.UserDefinedFunctions) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"user-defined functions are not supported until upgrade to version %s is finalized",
+			clusterversion.UserDefinedFunctions.String())
+	}
+	if n.cf.Replace {
+		telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("or_replace_function"))
+	} else {
+		telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("function"))
+	}
+
+	if n.scDesc.SchemaKind() != catalog.SchemaUserDefined {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot create function in schema %q", n.scDesc.GetName())
+	}
+	log.VEventf(params.ctx, 2, "dependencies for function %s:\n%s",
+		n.cf.FuncName.Object(), n.planDeps.String())
+	for _, dep := range n.planDeps {
+		if dbID := dep.desc.GetParentID(); dbID != n.dbDesc.GetID() && dbID != keys.SystemDatabaseID {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"the function cannot refer to other databases")
+		}
+		if dep.desc.IsTemporary() {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"the function cannot refer to temporary relation %q", dep.desc.GetName())
+		}
+	}
+
+	argTypes := make([]*types.T, len(n.cf.Args))
+	for i := range n.cf.Args {
+		argTypes[i] = n.cf.Args[i].Type.(*types.T)
+	}
+	var existing *funcdesc.Mutable
+	if fn, ok := n.scDesc.GetFunction(n.cf.FuncName.Object()); ok {
+		for _, o := range fn.Overloads {
+			if !overloadArgsMatch(o.ArgTypes, argTypes) {
+				continue
+			}
+			if !n.cf.Replace {
+				return sqlerrors.NewFunctionAlreadyExistsError(&n.cf.FuncName)
+			}
+			var err error
+			existing, err = params.p.Descriptors().GetMutableFunctionByID(
+				params.ctx, params.p.txn, o.ID, tree.ObjectLookupFlagsWithRequired(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if existing != nil {
+		return n.replaceFunction(params, existing)
+	}
+	return n.createNewFunction(params)
+}
+
+func (n *createFunctionNode) createNewFunction(params runParams) error {
+	id, err := descidgen.GenerateUniqueDescID(params.ctx, params.p.ExecCfg().DB, params.p.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+
+	args := make([]descpb.FunctionDescriptor_Argument, len(n.cf.Args))
+	for i := range n.cf.Args {
+		args[i] = descpb.FunctionDescriptor_Argument{
+			Name: string(n.cf.Args[i].Name),
+			Type: n.cf.Args[i].Type.(*types.T),
+		}
+	}
+	// As in Postgres, any user may execute a new function.
+	privs := catpb.NewBasePrivilegeDescriptor(params.SessionData().User())
+	privs.Grant(security.PublicRoleName(), privilege.List{privilege.EXECUTE}, false /* withGrantOption */)
+
+	newDesc := funcdesc.NewMutableFunctionDescriptor(
+		id,
+		n.dbDesc.GetID(),
+		n.scDesc.GetID(),
+		n.cf.FuncName.Object(),
+		args,
+		descpb.FunctionDescriptor_ReturnType{
+			Type:      n.cf.ReturnType.Type.(*types.T),
+			ReturnSet: n.cf.ReturnType.IsSet,
+		},
+		privs,
+	)
+	if err := setFuncOptions(&newDesc, n.cf.Options); err != nil {
+		return err
+	}
+	n.setDependencies(&newDesc)
+
+	if err := params.p.writeFuncDesc(params.ctx, &newDesc); err != nil {
+		return err
+	}
+
+	// Register the new overload in the function mapping of the schema.
+	mutSchema, err := params.p.Descriptors().GetMutableDescriptorByID(
+		params.ctx, params.p.txn, n.scDesc.GetID(),
+	)
+	if err != nil {
+		return err
+	}
+	scDesc, ok := mutSchema.(*schemadesc.Mutable)
+	if !ok {
+		return errors.AssertionFailedf("expected schema descriptor, got %T", mutSchema)
+	}
+	scDesc.AddFunction(newDesc.GetName(), newDesc.ToOverload())
+	if err := params.p.writeSchemaDescChange(
+		params.ctx, scDesc,
+		fmt.Sprintf("updating schema %q for creation of function %q", scDesc.GetName(), newDesc.GetName()),
+	); err != nil {
+		return err
+	}
+
+	if err := n.addBackReferences(params, &newDesc); err != nil {
+		return err
+	}
+	return validateDescriptor(params.ctx, params.p, &newDesc)
+}
+
+// replaceFunction replaces the definition of an existing function with the
+// same signature, as in CREATE OR REPLACE FUNCTION.
+func (n *createFunctionNode) replaceFunction(params runParams, desc *funcdesc.Mutable) error {
+	if hasOwnership, err := params.p.HasOwnership(params.ctx, desc); err != nil {
+		return err
+	} else if !hasOwnership {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"must be owner of function %s", desc.GetName())
+	}
+	if retType := n.cf.ReturnType.Type.(*types.T); !retType.Identical(desc.ReturnType.Type) ||
+		n.cf.ReturnType.IsSet != desc.ReturnType.ReturnSet {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.InvalidFunctionDefinition, "cannot change return type of existing function"),
+			"Use DROP FUNCTION "+desc.GetName()+" first.",
+		)
+	}
+	for i := range n.cf.Args {
+		if name := string(n.cf.Args[i].Name); name != desc.Args[i].Name {
+			return errors.WithHint(
+				pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"cannot change name of input parameter %q", desc.Args[i].Name),
+				"Use DROP FUNCTION "+desc.GetName()+" first.",
+			)
+		}
+	}
+
+	// Options which are not specified are reset to their defaults.
+	desc.SetVolatility(descpb.FunctionDescriptor_VOLATILE)
+	desc.SetLeakProof(false)
+	desc.SetNullInputBehavior(descpb.FunctionDescriptor_CALLED_ON_NULL_INPUT)
+	if err := setFuncOptions(desc, n.cf.Options); err != nil {
+		return err
+	}
+
+	// Replace the references of the function with those of the new body.
+	jobDesc := fmt.Sprintf("updating references for replacement of function %q", desc.GetName())
+	if err := params.p.removeFuncBackReferences(params.ctx, desc, jobDesc); err != nil {
+		return err
+	}
+	n.setDependencies(desc)
+	if err := params.p.writeFuncSchemaChange(
+		params.ctx, desc, tree.AsStringWithFQNames(n.cf, params.Ann()),
+	); err != nil {
+		return err
+	}
+	if err := n.addBackReferences(params, desc); err != nil {
+		return err
+	}
+	return validateDescriptor(params.ctx, params.p, desc)
+}
+
+// setDependencies records the relations and types the function depends on in
+// its descriptor.
+func (n *createFunctionNode) setDependencies(desc *funcdesc.Mutable) {
+	dependsOn := catalog.DescriptorIDSet{}
+	for id := range n.planDeps {
+		dependsOn.Add(id)
+	}
+	desc.SetDependsOn(dependsOn.Ordered())
+	dependsOnTypes := catalog.DescriptorIDSet{}
+	for id := range n.typeDeps {
+		dependsOnTypes.Add(id)
+	}
+	desc.SetDependsOnTypes(dependsOnTypes.Ordered())
+}
+
+// addBackReferences persists the back-references to the function in all the
+// relations and types it depends on.
+func (n *createFunctionNode) addBackReferences(params runParams, desc *funcdesc.Mutable) error {
+	for id, updated := range n.planDeps {
+		backRefMutable, err := params.p.Descriptors().GetMutableTableVersionByID(params.ctx, id, params.p.txn)
+		if err != nil {
+			return err
+		}
+		for _, dep := range updated.deps {
+			// As for views, the ID of the function was not known when the
+			// dependencies were collected.
+			dep.ID = desc.GetID()
+			backRefMutable.DependedOnBy = append(backRefMutable.DependedOnBy, dep)
+		}
+		if err := params.p.writeSchemaChange(
+			params.ctx,
+			backRefMutable,
+			descpb.InvalidMutationID,
+			fmt.Sprintf("updating function reference %q in table %s(%d)", desc.GetName(),
+				updated.desc.GetName(), updated.desc.GetID(),
+			),
+		); err != nil {
+			return err
+		}
+	}
+
+	for id := range n.typeDeps {
+		jobDesc := fmt.Sprintf("updating type back reference %d for function %d", id, desc.GetID())
+		if err := params.p.addTypeBackReference(params.ctx, id, desc.GetID(), jobDesc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFuncBackReferences removes the back-references to the function from
+// all the relations and types it depends on.
+func (p *planner) removeFuncBackReferences(
+	ctx context.Context, desc *funcdesc.Mutable, jobDesc string,
+) error {
+	for _, id := range desc.GetDependsOn() {
+		backRefMutable, err := p.Descriptors().GetMutableTableVersionByID(ctx, id, p.txn)
+		if err != nil {
+			return err
+		}
+		if backRefMutable.Dropped() {
+			// The relation is being dropped along with the function.
+			continue
+		}
+		backRefMutable.DependedOnBy = removeMatchingReferences(backRefMutable.DependedOnBy, desc.GetID())
+		if err := p.writeSchemaChange(
+			ctx, backRefMutable, descpb.InvalidMutationID, jobDesc,
+		); err != nil {
+			return err
+		}
+	}
+	return p.removeTypeBackReferences(ctx, desc.GetDependsOnTypes(), desc.GetID(), jobDesc)
+}
+
+// setFuncOptions sets the options of a CREATE FUNCTION or ALTER FUNCTION
+// statement in the function descriptor.
+func setFuncOptions(desc *funcdesc.Mutable, options tree.FunctionOptions) error {
+	for _, option := range options {
+		switch t := option.(type) {
+		case tree.FunctionVolatility:
+			v, err := funcdesc.VolatilityToProto(t)
+			if err != nil {
+				return err
+			}
+			desc.SetVolatility(v)
+		case tree.FunctionLeakproof:
+			desc.SetLeakProof(bool(t))
+		case tree.FunctionNullInputBehavior:
+			v, err := funcdesc.NullInputBehaviorToProto(t)
+			if err != nil {
+				return err
+			}
+			desc.SetNullInputBehavior(v)
+		case tree.FunctionLanguage:
+			v, err := funcdesc.FunctionLangToProto(t)
+			if err != nil {
+				return err
+			}
+			desc.SetLang(v)
+		case tree.FunctionBodyStr:
+			desc.SetFuncBody(string(t))
+		default:
+			return errors.AssertionFailedf("unexpected function option %T", option)
+		}
+	}
+	if desc.GetLeakProof() && desc.GetVolatility() != descpb.FunctionDescriptor_IMMUTABLE {
+		return pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"cannot set leakproof on function with non-immutable volatility: %s",
+			desc.GetVolatility().String())
+	}
+	return nil
+}
+
+func (*createFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (*createFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createFunctionNode) Close(context.Context)        {}
@@ -651,6 +651,7 @@ func addUniqueWithoutIndexColumnTableDef(
 		string(d.Unique.ConstraintName),
 		[]string{string(d.Name)},
 		"", /* predicate */
+		tree.NotDeferrableConstraint,
 		ts,
 		validationBehavior,
 	); err != nil {
@@ -706,18 +707,55 @@ func addUniqueWithoutIndexTableDef(
 	for i := range colNames {
 		colNames[i] = string(d.Columns[i].Column)
 	}
+	if err := checkDeferrableConstraintsSupported(ctx, evalCtx.Settings, d.Deferrable); err != nil {
+		return err
+	}
 	if err := ResolveUniqueWithoutIndexConstraint(
-		ctx, desc, string(d.Name), colNames, predicate, ts, validationBehavior,
+		ctx, desc, string(d.Name), colNames, predicate, d.Deferrable, ts, validationBehavior,
 	); err != nil {
 		return err
 	}
 	return nil
 }
 
+// checkUniqueIndexNotDeferrable returns an error if the given unique
+// constraint, which is enforced by an index, is marked DEFERRABLE. Uniqueness
+// enforced by an index is checked as each row is written, so only UNIQUE
+// WITHOUT INDEX constraints can be deferred until the end of the transaction.
+func checkUniqueIndexNotDeferrable(d *tree.UniqueConstraintTableDef) error {
+	if d.Deferrable == tree.NotDeferrableConstraint {
+		return nil
+	}
+	return errors.WithHint(
+		pgerror.New(pgcode.FeatureNotSupported,
+			"unique constraints with an index cannot be marked DEFERRABLE"),
+		"use UNIQUE WITHOUT INDEX to create a deferrable unique constraint",
+	)
+}
+
+// checkDeferrableConstraintsSupported returns an error if a constraint is
+// marked DEFERRABLE before the upgrade to the version which introduced
+// deferrable constraints is finalized, since nodes running an older version
+// would check the constraint immediately.
+func checkDeferrableConstraintsSupported(
+	ctx context.Context, st *cluster.Settings, deferrability tree.ConstraintDeferrability,
+) error {
+	if deferrability == tree.NotDeferrableConstraint ||
+		st.Version.IsActive(ctx, clusterversion.DeferrableConstraints) {
+		return nil
+	}
+	return pgerror.Newf(pgcode.FeatureNotSupported,
+		"deferrable constraints are not supported until upgrade to version %s is finalized",
+		clusterversion.DeferrableConstraints.String())
+}
+
 // ResolveUniqueWithoutIndexConstraint looks up the columns mentioned in a
 // UNIQUE WITHOUT INDEX constraint and adds metadata representing that
 // constraint to the descriptor.
 //
+// The passed deferrability determines whether the constraint can be checked at
+// the end of the transaction rather than at the end of each statement.
+//
 // The passed validationBehavior is used to determine whether or not preexisting
 // entries in the table need to be validated against the unique constraint being
 // added. This only applies for existing tables, not new tables.
@@ -727,6 +765,7 @@ func ResolveUniqueWithoutIndexConstraint(
 	constraintName string,
 	colNames []string,
 	predicate string,
+	deferrability tree.ConstraintDeferrability,
 	ts TableState,
 	validationBehavior tree.ValidationBehavior,
 ) error {
@@ -780,12 +819,14 @@ func ResolveUniqueWithoutIndexConstraint(
 	}
 
 	uc := descpb.UniqueWithoutIndexConstraint{
-		Name:         constraintName,
-		TableID:      tbl.ID,
-		ColumnIDs:    columnIDs,
-		Predicate:    predicate,
-		Validity:     validity,
-		ConstraintID: tbl.NextConstraintID,
+		Name:              constraintName,
+		TableID:           tbl.ID,
+		ColumnIDs:         columnIDs,
+		Predicate:         predicate,
+		Validity:          validity,
+		ConstraintID:      tbl.NextConstraintID,
+		Deferrable:        deferrability != tree.NotDeferrableConstraint,
+		InitiallyDeferred: deferrability == tree.DeferrableInitiallyDeferred,
 	}
 	tbl.NextConstraintID++
 	if ts == NewTable {
@@ -797,6 +838,144 @@ func ResolveUniqueWithoutIndexConstraint(
 	return nil
 }
 
+// addExclusionConstraintTableDef looks up the columns and operators mentioned
+// in an EXCLUDE constraint and adds metadata representing that constraint to
+// the given table descriptor.
+//
+// Exclusion constraints are enforced by the optimizer with checks that run
+// after each mutation. For existing tables, the constraint is added as a
+// mutation, and the schema changer validates the existing rows against it
+// unless validationBehavior is ValidationSkip.
+func addExclusionConstraintTableDef(
+	ctx context.Context,
+	d *tree.ExclusionConstraintTableDef,
+	desc *tabledesc.Mutable,
+	tn tree.TableName,
+	ts TableState,
+	validationBehavior tree.ValidationBehavior,
+	semaCtx *tree.SemaContext,
+	st *cluster.Settings,
+) error {
+	// Nodes running an older version ignore the exclusion constraints of table
+	// descriptors, and so would not enforce them.
+	if !st.Version.IsActive(ctx, clusterversion.ExclusionConstraints) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"exclusion constraints are not supported until upgrade to version %s is finalized",
+			clusterversion.ExclusionConstraints.String())
+	}
+	var colSet catalog.TableColSet
+	colNames := make([]string, len(d.Elems))
+	columnIDs := make(descpb.ColumnIDs, len(d.Elems))
+	operators := make([]string, len(d.Elems))
+	for i := range d.Elems {
+		elem := &d.Elems[i]
+		if elem.Expr != nil {
+			return unimplemented.NewWithIssuef(46657,
+				"expressions in exclusion constraints are not supported")
+		}
+		col, err := desc.FindActiveOrNewColumnByName(elem.Column)
+		if err != nil {
+			return err
+		}
+		if colSet.Contains(col.GetID()) {
+			return pgerror.Newf(pgcode.DuplicateColumn,
+				"column %q appears twice in exclusion constraint", col.GetName())
+		}
+		colSet.Add(col.GetID())
+
+		// Only commutative operators guarantee that the constraint holds no
+		// matter which of two conflicting rows is written last.
+		op := elem.Operator
+		switch op.Symbol {
+		case treecmp.EQ, treecmp.NE, treecmp.Overlaps:
+		default:
+			return errors.WithDetail(
+				pgerror.Newf(pgcode.WrongObjectType, "operator %s is not commutative", op),
+				"Only commutative operators can be used in exclusion constraints.",
+			)
+		}
+		typ := col.GetType()
+		fOp, _, _, _, _ := tree.FoldComparisonExpr(op, nil, nil)
+		if _, ok := tree.CmpOps[fOp.Symbol].LookupImpl(typ, typ); !ok {
+			return pgerror.Newf(pgcode.UndefinedFunction,
+				"operator does not exist: %s %s %s", typ.SQLString(), op, typ.SQLString())
+		}
+
+		colNames[i] = col.GetName()
+		columnIDs[i] = col.GetID()
+		operators[i] = op.String()
+	}
+
+	// If there is a predicate, validate it.
+	var predicate string
+	if d.Predicate != nil {
+		var err error
+		predicate, _, _, err = schemaexpr.DequalifyAndValidateExpr(
+			ctx,
+			desc,
+			d.Predicate,
+			types.Bool,
+			"exclusion constraint predicate",
+			semaCtx,
+			tree.VolatilityImmutable,
+			&tn,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Verify we are not writing a constraint over the same name.
+	constraintName := string(d.Name)
+	constraintInfo, err := desc.GetConstraintInfo()
+	if err != nil {
+		return err
+	}
+	if constraintName == "" {
+		constraintName = tabledesc.GenerateUniqueName(
+			fmt.Sprintf("excl_%s", strings.Join(colNames, "_")),
+			func(p string) bool {
+				_, ok := constraintInfo[p]
+				return ok
+			},
+		)
+	} else if _, ok := constraintInfo[constraintName]; ok {
+		return pgerror.Newf(pgcode.DuplicateObject, "duplicate constraint name: %q", constraintName)
+	}
+
+	accessMethod := string(d.AccessMethod)
+	if accessMethod == "" {
+		accessMethod = "btree"
+	}
+
+	validity := descpb.ConstraintValidity_Validated
+	if ts != NewTable {
+		if validationBehavior == tree.ValidationSkip {
+			validity = descpb.ConstraintValidity_Unvalidated
+		} else {
+			validity = descpb.ConstraintValidity_Validating
+		}
+	}
+
+	ec := descpb.ExclusionConstraint{
+		Name:         constraintName,
+		TableID:      desc.ID,
+		ColumnIDs:    columnIDs,
+		Operators:    operators,
+		Predicate:    predicate,
+		AccessMethod: accessMethod,
+		Validity:     validity,
+		ConstraintID: desc.NextConstraintID,
+	}
+	desc.NextConstraintID++
+	if ts == NewTable {
+		desc.ExclusionConstraints = append(desc.ExclusionConstraints, ec)
+	} else {
+		desc.AddExclusionMutation(&ec, descpb.DescriptorMutation_ADD)
+	}
+	return nil
+}
+
 // ResolveFK looks up the tables and columns mentioned in a `REFERENCES`
 // constraint and adds metadata representing that constraint to the descriptor.
 // It may, in doing so, add to or alter descriptors in the passed in `backrefs`
@@ -835,6 +1014,9 @@ func ResolveFK(
 	validationBehavior tree.ValidationBehavior,
 	evalCtx *tree.EvalContext,
 ) error {
+	if err := checkDeferrableConstraintsSupported(ctx, evalCtx.Settings, d.Deferrable); err != nil {
+		return err
+	}
 	var originColSet catalog.TableColSet
 	originCols := make([]catalog.Column, len(d.FromCols))
 	for i, fromCol := range d.FromCols {
@@ -1041,6 +1223,8 @@ func ResolveFK(
 		OnUpdate:            descpb.ForeignKeyReferenceActionValue[d.Actions.Update],
 		Match:               descpb.CompositeKeyMatchMethodValue[d.Match],
 		ConstraintID:        tbl.NextConstraintID,
+		Deferrable:          d.Deferrable != tree.NotDeferrableConstraint,
+		InitiallyDeferred:   d.Deferrable == tree.DeferrableInitiallyDeferred,
 	}
 	tbl.NextConstraintID++
 	if ts == NewTable {
@@ -1073,11 +1257,10 @@ func CreatePartitioning(
 				"cannot alter to PARTITION BY NOTHING if the object has implicit column partitioning",
 			)
 		}
-		// No CCL necessary if we're looking at PARTITION BY NOTHING - we can
-		// set the partitioning to nothing.
+		// PARTITION BY NOTHING: we can set the partitioning to nothing.
 		return nil, newPartitioning, nil
 	}
-	return CreatePartitioningCCL(
+	return createPartitioning(
 		ctx,
 		st,
 		evalCtx,
@@ -1090,23 +1273,6 @@ func CreatePartitioning(
 	)
 }
 
-// CreatePartitioningCCL is the public hook point for the CCL-licensed
-// partitioning creation code.
-var CreatePartitioningCCL = func(
-	ctx context.Context,
-	st *cluster.Settings,
-	evalCtx *tree.EvalContext,
-	columnLookupFn func(tree.Name) (catalog.Column, error),
-	oldNumImplicitColumns int,
-	oldKeyColumnNames []string,
-	partBy *tree.PartitionBy,
-	allowedNewColumnNames []tree.Name,
-	allowImplicitPartitioning bool,
-) (newImplicitCols []catalog.Column, newPartitioning catpb.PartitioningDescriptor, err error) {
-	return nil, catpb.PartitioningDescriptor{}, sqlerrors.NewCCLRequiredError(errors.New(
-		"creating or manipulating partitions requires a CCL binary"))
-}
-
 func getFinalSourceQuery(
 	params runParams, source *tree.Select, evalCtx *tree.EvalContext,
 ) (string, error) {
@@ -1867,6 +2033,9 @@ func NewTableDesc(
 				// We will add the unique constraint below.
 				break
 			}
+			if err := checkUniqueIndexNotDeferrable(d); err != nil {
+				return nil, err
+			}
 			// If the index is named, ensure that the name is unique. Unnamed
 			// indexes will be given a unique auto-generated name later on when
 			// AllocateIDs is called.
@@ -1975,7 +2144,8 @@ func NewTableDesc(
 					return nil, err
 				}
 			}
-		case *tree.CheckConstraintTableDef, *tree.ForeignKeyConstraintTableDef, *tree.FamilyTableDef:
+		case *tree.CheckConstraintTableDef, *tree.ForeignKeyConstraintTableDef, *tree.FamilyTableDef,
+			*tree.ExclusionConstraintTableDef:
 			// pass, handled below.
 
 		default:
@@ -2175,6 +2345,13 @@ func NewTableDesc(
 				return nil, err
 			}
 
+		case *tree.ExclusionConstraintTableDef:
+			if err := addExclusionConstraintTableDef(
+				ctx, d, &desc, n.Table, NewTable, tree.ValidationDefault, semaCtx, st,
+			); err != nil {
+				return nil, err
+			}
+
 		default:
 			return nil, errors.Errorf("unsupported table def: %T", def)
 		}
@@ -2296,6 +2473,15 @@ func newTableDesc(
 		n.Defs = newDefs
 	}
 
+	// Columns declared with a domain type use the default of the domain.
+	for _, def := range n.Defs {
+		if d, ok := def.(*tree.ColumnTableDef); ok {
+			if err := params.p.addDomainDefault(params.ctx, d); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Process any SERIAL columns to remove the SERIAL type, as required by
 	// NewTableDesc.
 	colNameToOwnedSeq, err := createSequencesForSerialColumns(
@@ -0,0 +1,279 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+type createTriggerNode struct {
+	n         *tree.CreateTrigger
+	tableDesc *tabledesc.Mutable
+	fnDesc    *funcdesc.Mutable
+	trigger   descpb.TriggerDescriptor
+}
+
+// Use to satisfy the linter.
+var _ planNode = &createTriggerNode{n: nil}
+
+// CreateTrigger creates a row-level trigger on a table.
+// Privileges: CREATE on the table and EXECUTE on the function.
+func (p *planner) CreateTrigger(ctx context.Context, n *tree.CreateTrigger) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"CREATE TRIGGER",
+	); err != nil {
+		return nil, err
+	}
+	// Nodes running an older version ignore the triggers of table descriptors.
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.RowLevelTriggers) {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"triggers are not supported until upgrade to version %s is finalized",
+			clusterversion.RowLevelTriggers.String())
+	}
+
+	_, tableDesc, err := p.ResolveMutableTableDescriptor(
+		ctx, &n.Table, true /* required */, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	if _, ok := tableDesc.FindTriggerByName(string(n.Name)); ok {
+		return nil, pgerror.Newf(pgcode.DuplicateObject,
+			"trigger %q for relation %q already exists", n.Name, tableDesc.GetName())
+	}
+
+	trigger := descpb.TriggerDescriptor{Name: string(n.Name)}
+	switch n.ActionTime {
+	case tree.TriggerActionTimeBefore:
+		trigger.ActionTime = descpb.TriggerDescriptor_BEFORE
+	case tree.TriggerActionTimeAfter:
+		trigger.ActionTime = descpb.TriggerDescriptor_AFTER
+	default:
+		return nil, errors.AssertionFailedf("unknown trigger action time %s", n.ActionTime)
+	}
+	for _, e := range n.Events {
+		var on *bool
+		switch e {
+		case tree.TriggerEventInsert:
+			on = &trigger.OnInsert
+		case tree.TriggerEventUpdate:
+			on = &trigger.OnUpdate
+		case tree.TriggerEventDelete:
+			on = &trigger.OnDelete
+		default:
+			return nil, errors.AssertionFailedf("unknown trigger event %s", e)
+		}
+		if *on {
+			return nil, pgerror.New(pgcode.Syntax, "duplicate trigger events specified")
+		}
+		*on = true
+	}
+
+	// Type check the condition and the function call, which also checks that
+	// the current user can execute the function.
+	if n.When != nil {
+		when, err := p.typeCheckTriggerExpr(ctx, tableDesc, n.When, types.Bool, "WHEN")
+		if err != nil {
+			return nil, err
+		}
+		if !when.ResolvedType().Equivalent(types.Bool) && when.ResolvedType().Family() != types.UnknownFamily {
+			return nil, pgerror.Newf(pgcode.DatatypeMismatch,
+				"argument of WHEN must be type bool, not type %s", when.ResolvedType())
+		}
+		trigger.When = tree.Serialize(n.When)
+	}
+	call := &tree.FuncExpr{
+		Func:  tree.ResolvableFunctionReference{FunctionReference: n.FuncName.ToUnresolvedObjectName().ToUnresolvedName()},
+		Exprs: n.Args,
+	}
+	typedCall, err := p.typeCheckTriggerExpr(ctx, tableDesc, call, types.Any, "trigger arguments")
+	if err != nil {
+		return nil, err
+	}
+	overload := typedCall.(*tree.FuncExpr).ResolvedOverload()
+	if !overload.IsUDF {
+		return nil, pgerror.Newf(pgcode.WrongObjectType,
+			"function %s is not a user-defined function", &n.FuncName)
+	}
+	retType := types.Bool
+	if trigger.ActionTime == descpb.TriggerDescriptor_AFTER {
+		retType = types.Void
+	}
+	if typ := typedCall.ResolvedType(); !typ.Identical(retType) {
+		return nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function %s must return type %s to be used in %s triggers",
+			&n.FuncName, retType.SQLString(), n.ActionTime)
+	}
+	fnID, err := funcdesc.UserDefinedFunctionOIDToID(overload.Oid)
+	if err != nil {
+		return nil, err
+	}
+	fnDesc, err := p.Descriptors().GetMutableFunctionByID(ctx, p.txn, fnID, tree.ObjectLookupFlagsWithRequired())
+	if err != nil {
+		return nil, err
+	}
+	trigger.FunctionID = fnID
+	for _, arg := range n.Args {
+		trigger.Args = append(trigger.Args, tree.Serialize(arg))
+	}
+
+	return &createTriggerNode{n: n, tableDesc: tableDesc, fnDesc: fnDesc, trigger: trigger}, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE TRIGGER performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createTriggerNode) ReadingOwnWrites() {}
+
+func (n *createTriggerNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("trigger"))
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+
+	n.tableDesc.Triggers = append(n.tableDesc.Triggers, n.trigger)
+	if err := validateDescriptor(params.ctx, params.p, n.tableDesc); err != nil {
+		return err
+	}
+	if err := params.p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID, jobDesc,
+	); err != nil {
+		return err
+	}
+
+	n.fnDesc.AddDependedOnBy(n.tableDesc.GetID())
+	return params.p.writeFuncSchemaChange(params.ctx, n.fnDesc, jobDesc)
+}
+
+func (n *createTriggerNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *createTriggerNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *createTriggerNode) Close(ctx context.Context)           {}
+
+// typeCheckTriggerExpr type checks an expression of a trigger of the given
+// table. The columns of the OLD and NEW rows are the only column references
+// allowed in the expression; they are replaced with NULLs of the column type
+// for the purpose of type checking.
+func (p *planner) typeCheckTriggerExpr(
+	ctx context.Context,
+	tableDesc catalog.TableDescriptor,
+	expr tree.Expr,
+	desired *types.T,
+	context string,
+) (tree.TypedExpr, error) {
+	replaced, err := replaceTriggerColumnRefs(expr, func(_ bool, colName tree.Name) (tree.Expr, error) {
+		col, err := tableDesc.FindColumnWithName(colName)
+		if err != nil {
+			return nil, err
+		}
+		if col.IsInaccessible() {
+			return nil, pgerror.Newf(pgcode.UndefinedColumn,
+				"column %q is inaccessible and cannot be referenced by a trigger", colName)
+		}
+		return &tree.CastExpr{Expr: tree.DNull, Type: col.GetType(), SyntaxMode: tree.CastShort}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer p.semaCtx.Properties.Restore(p.semaCtx.Properties)
+	p.semaCtx.Properties.Require(context, tree.RejectSpecial|tree.RejectSubqueries)
+	return tree.TypeCheck(ctx, replaced, &p.semaCtx, desired)
+}
+
+// replaceTriggerColumnRefs calls fn on each reference to a column of the OLD
+// or NEW row in the given trigger expression, and replaces the reference with
+// the result. An error is returned for any other column reference.
+func replaceTriggerColumnRefs(
+	expr tree.Expr, fn func(isNew bool, colName tree.Name) (tree.Expr, error),
+) (tree.Expr, error) {
+	return tree.SimpleVisit(expr, func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		v, ok := expr.(tree.VarName)
+		if !ok {
+			return true, expr, nil
+		}
+		vn, err := v.NormalizeVarName()
+		if err != nil {
+			return false, nil, err
+		}
+		if c, ok := vn.(*tree.ColumnItem); ok && c.TableName != nil && c.TableName.NumParts == 1 {
+			switch c.TableName.Parts[0] {
+			case "old", "new":
+				newExpr, err = fn(c.TableName.Parts[0] == "new", c.ColumnName)
+				return false, newExpr, err
+			}
+		}
+		return false, nil, pgerror.Newf(pgcode.InvalidColumnReference,
+			"column reference %q in a trigger must be qualified with OLD or NEW", tree.ErrString(v))
+	})
+}
+
+// triggerReferencesColumn returns true if an expression of the trigger refers
+// to the column with the given name.
+func triggerReferencesColumn(trigger *descpb.TriggerDescriptor, colName tree.Name) (bool, error) {
+	exprs := trigger.Args
+	if trigger.When != "" {
+		exprs = append(exprs[:len(exprs):len(exprs)], trigger.When)
+	}
+	found := false
+	for _, s := range exprs {
+		expr, err := parser.ParseExpr(s)
+		if err != nil {
+			return false, err
+		}
+		if _, err := replaceTriggerColumnRefs(expr, func(_ bool, name tree.Name) (tree.Expr, error) {
+			found = found || name == colName
+			return tree.DNull, nil
+		}); err != nil {
+			return false, err
+		}
+	}
+	return found, nil
+}
+
+// checkColumnNotReferencedByTriggers returns an error if a trigger of the
+// table refers to the given column.
+func checkColumnNotReferencedByTriggers(
+	tableDesc catalog.TableDescriptor, colName tree.Name, op string,
+) error {
+	for i := range tableDesc.GetTriggers() {
+		trigger := &tableDesc.GetTriggers()[i]
+		found, err := triggerReferencesColumn(trigger, colName)
+		if err != nil {
+			return err
+		}
+		if found {
+			return errors.WithHintf(
+				sqlerrors.NewDependentObjectErrorf("cannot %s column %q because trigger %q on table %q depends on it",
+					op, colName, trigger.Name, tableDesc.GetName()),
+				"you can drop trigger %s instead.", fmt.Sprintf("%q", trigger.Name))
+		}
+	}
+	return nil
+}
@@ -14,11 +14,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catprivilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descidgen"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
@@ -64,6 +67,18 @@ func (p *planner) CreateType(ctx context.Context, n *tree.CreateType) (planNode,
 	); err != nil {
 		return nil, err
 	}
+	// Nodes running an older version cannot read the descriptors of domains
+	// and composite types.
+	if (n.Variety == tree.Domain || n.Variety == tree.Composite) &&
+		!p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.DomainsAndCompositeTypes) {
+		what := "composite types"
+		if n.Variety == tree.Domain {
+			what = "domains"
+		}
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s are not supported until upgrade to version %s is finalized",
+			what, clusterversion.DomainsAndCompositeTypes.String())
+	}
 
 	// Resolve the desired new type name.
 	typeName, db, err := resolveNewTypeName(p.RunParams(ctx), n.TypeName)
@@ -107,6 +122,10 @@ func (n *createTypeNode) startExec(params runParams) error {
 	switch n.n.Variety {
 	case tree.Enum:
 		return params.p.createUserDefinedEnum(params, n)
+	case tree.Composite:
+		return params.p.createUserDefinedComposite(params, n)
+	case tree.Domain:
+		return params.p.createUserDefinedDomain(params, n)
 	default:
 		return unimplemented.NewWithIssue(25123, "CREATE TYPE")
 	}
@@ -208,7 +227,7 @@ func findFreeArrayTypeName(
 }
 
 // CreateEnumArrayTypeDesc creates a type descriptor for the array of the
-// given enum.
+// given enum or composite type.
 func CreateEnumArrayTypeDesc(
 	params runParams,
 	typDesc *typedesc.Mutable,
@@ -223,6 +242,16 @@ func CreateEnumArrayTypeDesc(
 	switch t := typDesc.Kind; t {
 	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
 		elemTyp = types.MakeEnum(typedesc.TypeIDToOID(typDesc.GetID()), typedesc.TypeIDToOID(id))
+	case descpb.TypeDescriptor_COMPOSITE:
+		contents := make([]*types.T, len(typDesc.Composite.Elements))
+		labels := make([]string, len(typDesc.Composite.Elements))
+		for i, e := range typDesc.Composite.Elements {
+			contents[i] = e.ElementType
+			labels[i] = e.ElementLabel
+		}
+		elemTyp = types.MakeCompositeType(
+			typedesc.TypeIDToOID(typDesc.GetID()), typedesc.TypeIDToOID(id), contents, labels,
+		)
 	default:
 		return nil, errors.AssertionFailedf("cannot make array type for kind %s", t.String())
 	}
@@ -430,6 +459,174 @@ func (p *planner) createEnumWithID(
 		})
 }
 
+func (p *planner) createUserDefinedComposite(params runParams, n *createTypeNode) error {
+	schema, err := getCreateTypeParams(params, n.typeName, n.dbDesc)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the types of the fields, which must all be usable as column
+	// types since composite types may themselves be used for columns.
+	elements := make([]descpb.TypeDescriptor_Composite_CompositeElement, len(n.n.CompositeTypeList))
+	seenLabels := make(map[tree.Name]struct{})
+	for i, elem := range n.n.CompositeTypeList {
+		if _, ok := seenLabels[elem.Label]; ok {
+			return pgerror.Newf(pgcode.DuplicateColumn,
+				"column %q specified more than once", elem.Label)
+		}
+		seenLabels[elem.Label] = struct{}{}
+		typ, err := tree.ResolveType(params.ctx, elem.Type, p.semaCtx.GetTypeResolver())
+		if err != nil {
+			return err
+		}
+		if err := colinfo.ValidateColumnDefType(typ); err != nil {
+			return err
+		}
+		elements[i] = descpb.TypeDescriptor_Composite_CompositeElement{
+			ElementType:  typ,
+			ElementLabel: string(elem.Label),
+		}
+	}
+
+	id, err := descidgen.GenerateUniqueDescID(params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+	typeDesc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name:           n.typeName.Type(),
+		ID:             id,
+		ParentID:       n.dbDesc.GetID(),
+		ParentSchemaID: schema.GetID(),
+		Kind:           descpb.TypeDescriptor_COMPOSITE,
+		Composite:      &descpb.TypeDescriptor_Composite{Elements: elements},
+		Version:        1,
+		Privileges:     p.newTypePrivileges(params, n.dbDesc, schema),
+	}).BuildCreatedMutableType()
+
+	// Create the implicit array type for this type before finishing the type.
+	arrayTypeID, err := p.createArrayType(params, n.typeName, typeDesc, n.dbDesc, schema.GetID())
+	if err != nil {
+		return err
+	}
+	typeDesc.ArrayTypeID = arrayTypeID
+
+	if err := p.createDescriptorWithID(
+		params.ctx,
+		catalogkeys.MakeObjectNameKey(params.ExecCfg().Codec, n.dbDesc.GetID(), schema.GetID(), n.typeName.Type()),
+		id,
+		typeDesc,
+		n.typeName.String(),
+	); err != nil {
+		return err
+	}
+
+	// Add back-references from the user defined field types to the new type.
+	refTypes := make([]*types.T, len(elements))
+	for i := range elements {
+		refTypes[i] = elements[i].ElementType
+	}
+	if err := p.addBackRefsFromTypes(params.ctx, refTypes, id); err != nil {
+		return err
+	}
+
+	return p.logEvent(params.ctx,
+		typeDesc.GetID(),
+		&eventpb.CreateType{
+			TypeName: n.typeName.FQString(),
+		})
+}
+
+func (p *planner) createUserDefinedDomain(params runParams, n *createTypeNode) error {
+	schema, err := getCreateTypeParams(params, n.typeName, n.dbDesc)
+	if err != nil {
+		return err
+	}
+
+	domain, err := p.makeDomain(params.ctx, n.typeName.Type(), n.n.Domain)
+	if err != nil {
+		return err
+	}
+
+	id, err := descidgen.GenerateUniqueDescID(params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+	// Domains do not get an implicit array type; arrays of a domain are arrays
+	// of its base type.
+	typeDesc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name:           n.typeName.Type(),
+		ID:             id,
+		ParentID:       n.dbDesc.GetID(),
+		ParentSchemaID: schema.GetID(),
+		Kind:           descpb.TypeDescriptor_DOMAIN,
+		Domain:         domain,
+		Version:        1,
+		Privileges:     p.newTypePrivileges(params, n.dbDesc, schema),
+	}).BuildCreatedMutableType()
+
+	if err := p.createDescriptorWithID(
+		params.ctx,
+		catalogkeys.MakeObjectNameKey(params.ExecCfg().Codec, n.dbDesc.GetID(), schema.GetID(), n.typeName.Type()),
+		id,
+		typeDesc,
+		n.typeName.String(),
+	); err != nil {
+		return err
+	}
+
+	// Add a back-reference from the domain the new domain was declared over, if
+	// any. The base type of a domain is always a builtin type.
+	if baseDomainID := domain.BaseDomainID; baseDomainID != descpb.InvalidID {
+		jobDesc := fmt.Sprintf("updating type back reference %d for domain %d", baseDomainID, id)
+		if err := p.addTypeBackReference(params.ctx, baseDomainID, id, jobDesc); err != nil {
+			return err
+		}
+	}
+
+	return p.logEvent(params.ctx,
+		typeDesc.GetID(),
+		&eventpb.CreateType{
+			TypeName: n.typeName.FQString(),
+		})
+}
+
+// newTypePrivileges returns the privileges of a new type created in the
+// given database and schema.
+func (p *planner) newTypePrivileges(
+	params runParams, dbDesc catalog.DatabaseDescriptor, schema catalog.SchemaDescriptor,
+) *catpb.PrivilegeDescriptor {
+	return catprivilege.CreatePrivilegesFromDefaultPrivileges(
+		dbDesc.GetDefaultPrivilegeDescriptor(),
+		schema.GetDefaultPrivilegeDescriptor(),
+		dbDesc.GetID(),
+		params.SessionData().User(),
+		tree.Types,
+		dbDesc.GetPrivileges(),
+	)
+}
+
+// addBackRefsFromTypes adds a back-reference to ref in all of the user defined
+// types referenced by typs.
+func (p *planner) addBackRefsFromTypes(ctx context.Context, typs []*types.T, ref descpb.ID) error {
+	var ids catalog.DescriptorIDSet
+	for _, typ := range typs {
+		children, err := typedesc.GetTypeDescriptorClosure(typ)
+		if err != nil {
+			return err
+		}
+		for id := range children {
+			ids.Add(id)
+		}
+	}
+	for _, id := range ids.Ordered() {
+		jobDesc := fmt.Sprintf("updating type back reference %d for type %d", id, ref)
+		if err := p.addTypeBackReference(ctx, id, ref, jobDesc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (n *createTypeNode) Next(params runParams) (bool, error) { return false, nil }
 func (n *createTypeNode) Values() tree.Datums                 { return tree.Datums{} }
 func (n *createTypeNode) Close(ctx context.Context)           {}
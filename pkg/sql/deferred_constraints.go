@@ -0,0 +1,370 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
+)
+
+// deferredConstraints gives the planner access to the DEFERRABLE constraints
+// of the current transaction whose checks have been deferred until commit.
+type deferredConstraints interface {
+	// isDeferred returns whether the checks for the given constraint are
+	// currently deferred until the end of the transaction.
+	isDeferred(c *exec.DeferrableConstraint) bool
+	// addPending records that a deferred check for the given constraint found
+	// violations, so that the constraint is validated at the end of the
+	// transaction. keys contains the values of the constraint columns of the
+	// violating rows; if it is nil, the whole table is validated.
+	addPending(c *exec.DeferrableConstraint, keys []tree.Datums)
+	// setConstraints changes whether the checks for the named constraints (or
+	// all constraints, if names is empty) are deferred. It returns the pending
+	// constraints which are no longer deferred; these must be validated
+	// immediately.
+	setConstraints(names tree.NameList, deferred bool) []pendingConstraint
+	// takePending returns all pending constraints and clears them.
+	takePending() []pendingConstraint
+}
+
+// pendingConstraint is a deferred constraint which was possibly violated by
+// the statements of the transaction.
+type pendingConstraint struct {
+	exec.DeferrableConstraint
+	// keys contains the values of the constraint columns of the rows which
+	// violated the constraint when they were checked. Only the rows with these
+	// values are validated at the end of the transaction.
+	keys []tree.Datums
+	// allRows is set if the violating rows are not known, in which case the
+	// constraint is validated against all the rows of the table.
+	allRows bool
+}
+
+// deferredConstraintsState is the per-transaction state backing
+// deferredConstraints. It is stored in the connExecutor's extraTxnState.
+type deferredConstraintsState struct {
+	// all is the mode set by the last SET CONSTRAINTS ALL statement, or nil if
+	// the constraints use their initial mode.
+	all *bool
+	// byName contains the modes set by SET CONSTRAINTS for specific constraint
+	// names since the last SET CONSTRAINTS ALL statement.
+	byName map[string]bool
+	// pending contains the constraints which were possibly violated by a
+	// statement while deferred.
+	pending []pendingConstraint
+}
+
+func (s *deferredConstraintsState) reset() {
+	*s = deferredConstraintsState{}
+}
+
+type connExDeferredConstraintsAccessor struct {
+	ex *connExecutor
+}
+
+var _ deferredConstraints = connExDeferredConstraintsAccessor{}
+
+func (c connExDeferredConstraintsAccessor) isDeferred(d *exec.DeferrableConstraint) bool {
+	s := &c.ex.extraTxnState.deferredConstraints
+	if deferred, ok := s.byName[d.Name]; ok {
+		return deferred
+	}
+	if s.all != nil {
+		return *s.all
+	}
+	return d.InitiallyDeferred
+}
+
+func (c connExDeferredConstraintsAccessor) addPending(
+	d *exec.DeferrableConstraint, keys []tree.Datums,
+) {
+	s := &c.ex.extraTxnState.deferredConstraints
+	for i := range s.pending {
+		pc := &s.pending[i]
+		if pc.TableID != d.TableID || pc.Name != d.Name {
+			continue
+		}
+		if keys == nil {
+			pc.allRows = true
+			pc.keys = nil
+		} else if !pc.allRows {
+			pc.keys = append(pc.keys, keys...)
+		}
+		return
+	}
+	s.pending = append(s.pending, pendingConstraint{
+		DeferrableConstraint: *d,
+		keys:                 keys,
+		allRows:              keys == nil,
+	})
+}
+
+func (c connExDeferredConstraintsAccessor) setConstraints(
+	names tree.NameList, deferred bool,
+) []pendingConstraint {
+	s := &c.ex.extraTxnState.deferredConstraints
+	if len(names) == 0 {
+		s.all = &deferred
+		s.byName = nil
+	} else {
+		if s.byName == nil {
+			s.byName = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			s.byName[string(name)] = deferred
+		}
+	}
+	var immediate []pendingConstraint
+	remaining := s.pending[:0]
+	for _, d := range s.pending {
+		if c.isDeferred(&d.DeferrableConstraint) {
+			remaining = append(remaining, d)
+		} else {
+			immediate = append(immediate, d)
+		}
+	}
+	s.pending = remaining
+	return immediate
+}
+
+func (c connExDeferredConstraintsAccessor) takePending() []pendingConstraint {
+	s := &c.ex.extraTxnState.deferredConstraints
+	pending := s.pending
+	s.pending = nil
+	return pending
+}
+
+// emptyDeferredConstraints is the default impl used by the planner when the
+// connExecutor is not available. Checks are never deferred.
+type emptyDeferredConstraints struct{}
+
+var _ deferredConstraints = emptyDeferredConstraints{}
+
+func (emptyDeferredConstraints) isDeferred(*exec.DeferrableConstraint) bool {
+	return false
+}
+
+func (emptyDeferredConstraints) addPending(*exec.DeferrableConstraint, []tree.Datums) {}
+
+func (emptyDeferredConstraints) setConstraints(tree.NameList, bool) []pendingConstraint {
+	return nil
+}
+
+func (emptyDeferredConstraints) takePending() []pendingConstraint {
+	return nil
+}
+
+// isDeferredCheckViolation returns true if err is the error returned by the
+// check query of a deferrable constraint when it finds a violation.
+func isDeferredCheckViolation(err error) bool {
+	code := pgerror.GetPGCode(err)
+	return code == pgcode.ForeignKeyViolation || code == pgcode.UniqueViolation
+}
+
+// validateDeferredConstraints validates the given constraints, which were
+// possibly violated while their checks were deferred. Only the rows with the
+// keys which violated a constraint are validated, unless these keys are not
+// known.
+func (p *planner) validateDeferredConstraints(
+	ctx context.Context, txn *kv.Txn, constraints []pendingConstraint,
+) error {
+	if len(constraints) == 0 {
+		return nil
+	}
+	// Step the transaction so that the validation queries observe the writes of
+	// all the statements in the transaction.
+	_ = txn.ConfigureStepping(ctx, kv.SteppingEnabled)
+	if err := txn.Step(ctx, false /* allowReadTimestampStep */); err != nil {
+		return err
+	}
+	ie := p.ExecCfg().InternalExecutorFactory(ctx, p.SessionData())
+	for i := range constraints {
+		c := &constraints[i]
+		tbl, err := p.Descriptors().GetImmutableTableByID(
+			ctx, txn, descpb.ID(c.TableID), tree.ObjectLookupFlagsWithRequired(),
+		)
+		if err != nil {
+			return err
+		}
+		// Inject the tables into the internal executor, since they may have been
+		// modified by this transaction.
+		syntheticDescs := []catalog.Descriptor{tbl}
+		var validate func() error
+		for _, fk := range tbl.AllActiveAndInactiveForeignKeys() {
+			if fk.Name != c.Name {
+				continue
+			}
+			fk := fk
+			colNames, err := tbl.NamesForColumnIDs(fk.OriginColumnIDs)
+			if err != nil {
+				return err
+			}
+			targetTbl := tbl
+			if fk.ReferencedTableID != tbl.GetID() {
+				targetTbl, err = p.Descriptors().GetImmutableTableByID(
+					ctx, txn, fk.ReferencedTableID, tree.ObjectLookupFlagsWithRequired(),
+				)
+				if err != nil {
+					return err
+				}
+				syntheticDescs = append(syntheticDescs, targetTbl)
+			}
+			validate = func() error {
+				return forEachKeyFilter(c, colNames, func(filter string) error {
+					return validateForeignKey(ctx, tbl, targetTbl, fk, filter, ie, txn)
+				})
+			}
+		}
+		for _, uc := range tbl.AllActiveAndInactiveUniqueWithoutIndexConstraints() {
+			if uc.Name != c.Name {
+				continue
+			}
+			uc := uc
+			colNames, err := tbl.NamesForColumnIDs(uc.ColumnIDs)
+			if err != nil {
+				return err
+			}
+			validate = func() error {
+				return forEachKeyFilter(c, colNames, func(filter string) error {
+					pred := uc.Predicate
+					if filter != "" && pred != "" {
+						pred = fmt.Sprintf("(%s) AND (%s)", pred, filter)
+					} else if filter != "" {
+						pred = filter
+					}
+					return validateUniqueConstraint(
+						ctx, tbl, uc.Name, uc.ColumnIDs, pred, ie, txn, p.User(),
+						true, /* preExisting */
+					)
+				})
+			}
+		}
+		if validate == nil {
+			// The constraint was dropped after it was deferred.
+			continue
+		}
+		if err := ie.WithSyntheticDescriptors(syntheticDescs, validate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deferredKeysBatchSize is the maximum number of keys of a pending constraint
+// which are validated by a single query.
+const deferredKeysBatchSize = 1000
+
+// forEachKeyFilter calls fn with filters which together select the rows of
+// the table of the pending constraint that have one of its keys in the given
+// columns. If the keys are not known, fn is called once with an empty filter,
+// which selects all the rows.
+func forEachKeyFilter(c *pendingConstraint, colNames []string, fn func(filter string) error) error {
+	if c.allRows {
+		return fn("" /* filter */)
+	}
+	seen := make(map[string]struct{}, len(c.keys))
+	var buf strings.Builder
+	n := 0
+	for _, key := range c.keys {
+		// Keys can only contain NULLs for MATCH FULL foreign keys, in which case
+		// the row is a violation whether or not a referenced row exists.
+		var cond strings.Builder
+		for i, d := range key {
+			if i > 0 {
+				cond.WriteString(" AND ")
+			}
+			cond.WriteString(tree.NameString(colNames[i]))
+			if d == tree.DNull {
+				cond.WriteString(" IS NULL")
+			} else {
+				cond.WriteString(" = ")
+				cond.WriteString(tree.AsStringWithFlags(d, tree.FmtParsable))
+			}
+		}
+		if _, ok := seen[cond.String()]; ok {
+			continue
+		}
+		seen[cond.String()] = struct{}{}
+		if n > 0 {
+			buf.WriteString(" OR ")
+		}
+		fmt.Fprintf(&buf, "(%s)", cond.String())
+		if n++; n == deferredKeysBatchSize {
+			if err := fn(buf.String()); err != nil {
+				return err
+			}
+			buf.Reset()
+			n = 0
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return fn(buf.String())
+}
+
+// checkSetConstraintsNames returns an error if any of the given names does not
+// refer to a DEFERRABLE constraint of a table in the current database.
+func (p *planner) checkSetConstraintsNames(ctx context.Context, names tree.NameList) error {
+	if len(names) == 0 {
+		return nil
+	}
+	db, err := p.Descriptors().GetImmutableDatabaseByName(
+		ctx, p.Txn(), p.CurrentDatabase(), tree.DatabaseLookupFlags{Required: true},
+	)
+	if err != nil {
+		return err
+	}
+	tableDescs, err := p.Descriptors().GetAllTableDescriptorsInDatabase(ctx, p.Txn(), db.GetID())
+	if err != nil {
+		return err
+	}
+	found := make(map[string]descpb.ConstraintDetail, len(names))
+	for _, tbl := range tableDescs {
+		if !tbl.IsTable() {
+			continue
+		}
+		info, err := tbl.GetConstraintInfo()
+		if err != nil {
+			return err
+		}
+		for name, detail := range info {
+			if prev, ok := found[name]; !ok || prev.Deferrability() == tree.NotDeferrableConstraint {
+				found[name] = detail
+			}
+		}
+	}
+	for _, name := range names {
+		detail, ok := found[string(name)]
+		if !ok {
+			return pgerror.Newf(pgcode.UndefinedObject,
+				"constraint %q does not exist", tree.ErrString(&name))
+		}
+		if detail.Deferrability() == tree.NotDeferrableConstraint {
+			return errors.WithHint(
+				pgerror.Newf(pgcode.WrongObjectType,
+					"constraint %q is not deferrable", tree.ErrString(&name)),
+				"only foreign key and UNIQUE WITHOUT INDEX constraints can be DEFERRABLE",
+			)
+		}
+	}
+	return nil
+}
@@ -51,7 +51,7 @@ type deleteRun struct {
 
 	// partialIndexDelValsOffset is the offset of partial index delete
 	// indicators in the source values. It is equal to the number of fetched
-	// columns.
+	// columns plus the number of passthrough columns.
 	partialIndexDelValsOffset int
 
 	// rowIdxToRetIdx is the mapping from the columns returned by the deleter
@@ -60,6 +60,11 @@ type deleteRun struct {
 	// of the mutation. Otherwise, the value at the i-th index refers to the
 	// index of the resultRowBuffer where the i-th column is to be returned.
 	rowIdxToRetIdx []int
+
+	// numPassthrough is the number of columns in addition to the set of
+	// columns of the target table being returned, that we must pass through
+	// from the input node.
+	numPassthrough int
 }
 
 var _ mutationPlanNode = &deleteNode{}
@@ -164,12 +169,14 @@ func (d *deleteNode) processSourceRow(params runParams, sourceVals tree.Datums)
 		if err != nil {
 			return err
 		}
-
-		// Truncate sourceVals so that it no longer includes partial index
-		// predicate values.
-		sourceVals = sourceVals[:d.run.partialIndexDelValsOffset]
 	}
 
+	// Split off the passthrough values and any partial index predicate values
+	// so that sourceVals only includes the fetched columns.
+	numFetchCols := d.run.partialIndexDelValsOffset - d.run.numPassthrough
+	passthroughValues := sourceVals[numFetchCols:d.run.partialIndexDelValsOffset]
+	sourceVals = sourceVals[:numFetchCols]
+
 	// Queue the deletion in the KV batch.
 	if err := d.run.td.row(params.ctx, sourceVals, pm, d.run.traceKV); err != nil {
 		return err
@@ -184,12 +191,24 @@ func (d *deleteNode) processSourceRow(params runParams, sourceVals tree.Datums)
 		// d.run.rows.NumCols() is guaranteed to only contain the requested
 		// public columns.
 		resultValues := make(tree.Datums, d.run.td.rows.NumCols())
+		largestRetIdx := -1
 		for i, retIdx := range d.run.rowIdxToRetIdx {
 			if retIdx >= 0 {
+				if retIdx >= largestRetIdx {
+					largestRetIdx = retIdx
+				}
 				resultValues[retIdx] = sourceVals[i]
 			}
 		}
 
+		// At this point we've extracted all the RETURNING values that are part
+		// of the target table. We must now extract the columns in the RETURNING
+		// clause that refer to other tables (from the USING clause of the delete).
+		for i := 0; i < d.run.numPassthrough; i++ {
+			largestRetIdx++
+			resultValues[largestRetIdx] = passthroughValues[i]
+		}
+
 		if _, err := d.run.td.rows.AddRow(params.ctx, resultValues); err != nil {
 			return err
 		}
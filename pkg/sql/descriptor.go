@@ -12,6 +12,7 @@ package sql
 
 import (
 	"context"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -54,6 +55,7 @@ var (
 	errNoSchema          = pgerror.Newf(pgcode.InvalidName, "no schema specified")
 	errNoTable           = pgerror.New(pgcode.InvalidName, "no table specified")
 	errNoType            = pgerror.New(pgcode.InvalidName, "no type specified")
+	errNoFunction        = pgerror.New(pgcode.InvalidName, "no function specified")
 	errNoMatch           = pgerror.New(pgcode.UndefinedObject, "no object matched")
 )
 
@@ -333,20 +335,87 @@ func (p *planner) checkRegionIsCurrentlyActive(ctx context.Context, region catpb
 	return CheckClusterRegionIsLive(liveRegions, region)
 }
 
-// InitializeMultiRegionMetadataCCL is the public hook point for the
-// CCL-licensed multi-region initialization code.
-var InitializeMultiRegionMetadataCCL = func(
+// initializeMultiRegionMetadata validates the regions of a multi-region
+// database and returns its region config. The ID of the region enum is
+// allocated here as well.
+func initializeMultiRegionMetadata(
 	ctx context.Context,
 	execCfg *ExecutorConfig,
-	liveClusterRegions LiveClusterRegions,
-	survivalGoal tree.SurvivalGoal,
+	liveRegions LiveClusterRegions,
+	goal tree.SurvivalGoal,
 	primaryRegion catpb.RegionName,
 	regions []tree.Name,
 	dataPlacement tree.DataPlacement,
 ) (*multiregion.RegionConfig, error) {
-	return nil, sqlerrors.NewCCLRequiredError(
-		errors.New("creating multi-region databases requires a CCL binary"),
+	survivalGoal, err := TranslateSurvivalGoal(goal)
+	if err != nil {
+		return nil, err
+	}
+	placement, err := TranslateDataPlacement(dataPlacement)
+	if err != nil {
+		return nil, err
+	}
+
+	if primaryRegion != catpb.RegionName(tree.PrimaryRegionNotSpecifiedName) {
+		if err := CheckClusterRegionIsLive(liveRegions, primaryRegion); err != nil {
+			return nil, err
+		}
+	}
+	regionNames := make(catpb.RegionNames, 0, len(regions)+1)
+	seenRegions := make(map[catpb.RegionName]struct{}, len(regions)+1)
+	if len(regions) > 0 {
+		if primaryRegion == catpb.RegionName(tree.PrimaryRegionNotSpecifiedName) {
+			return nil, pgerror.Newf(
+				pgcode.InvalidDatabaseDefinition,
+				"PRIMARY REGION must be specified if REGIONS are specified",
+			)
+		}
+		for _, r := range regions {
+			region := catpb.RegionName(r)
+			if err := CheckClusterRegionIsLive(liveRegions, region); err != nil {
+				return nil, err
+			}
+
+			if _, ok := seenRegions[region]; ok {
+				return nil, pgerror.Newf(
+					pgcode.InvalidName,
+					"region %q defined multiple times",
+					region,
+				)
+			}
+			seenRegions[region] = struct{}{}
+			regionNames = append(regionNames, region)
+		}
+	}
+	// If PRIMARY REGION is not in REGIONS, add it implicitly.
+	if _, ok := seenRegions[primaryRegion]; !ok {
+		regionNames = append(regionNames, primaryRegion)
+	}
+
+	sort.SliceStable(regionNames, func(i, j int) bool {
+		return regionNames[i] < regionNames[j]
+	})
+
+	// Generate a unique ID for the multi-region enum type descriptor here as
+	// well.
+	regionEnumID, err := descidgen.GenerateUniqueDescID(ctx, execCfg.DB, execCfg.Codec)
+	if err != nil {
+		return nil, err
+	}
+	regionConfig := multiregion.MakeRegionConfig(
+		regionNames,
+		primaryRegion,
+		survivalGoal,
+		regionEnumID,
+		placement,
+		nil, /* superRegions */
+		descpb.ZoneConfigExtensions{},
 	)
+	if err := multiregion.ValidateRegionConfig(regionConfig); err != nil {
+		return nil, err
+	}
+
+	return &regionConfig, nil
 }
 
 // DefaultPrimaryRegionClusterSettingName is the name of the cluster setting that returns
@@ -424,7 +493,7 @@ func (p *planner) maybeInitializeMultiRegionMetadata(
 		return nil, err
 	}
 
-	regionConfig, err := InitializeMultiRegionMetadataCCL(
+	regionConfig, err := initializeMultiRegionMetadata(
 		ctx,
 		p.ExecCfg(),
 		liveRegions,
@@ -1497,7 +1497,7 @@ func (dsp *DistSQLPlanner) PlanAndRunCascadesAndChecks(
 		// those fall back to legacy cascades code, it will disable stepping. So we
 		// have to reenable stepping each time.
 		_ = planner.Txn().ConfigureStepping(ctx, kv.SteppingEnabled)
-		if err := planner.Txn().Step(ctx); err != nil {
+		if err := planner.Txn().Step(ctx, false /* allowReadTimestampStep */); err != nil {
 			recv.SetError(err)
 			return false
 		}
@@ -1567,13 +1567,27 @@ func (dsp *DistSQLPlanner) PlanAndRunCascadesAndChecks(
 	// those fall back to legacy cascades code, it will disable stepping. So we
 	// have to reenable stepping each time.
 	_ = planner.Txn().ConfigureStepping(ctx, kv.SteppingEnabled)
-	if err := planner.Txn().Step(ctx); err != nil {
+	if err := planner.Txn().Step(ctx, false /* allowReadTimestampStep */); err != nil {
 		recv.SetError(err)
 		return false
 	}
 
 	for i := range plan.checkPlans {
 		log.VEventf(ctx, 2, "executing check query %d out of %d", i+1, len(plan.checkPlans))
+		// A violation of a deferred constraint is not an error until the end of
+		// the transaction. The check records the keys of the violating rows
+		// instead, so that only those are validated again at that point.
+		d := plan.checkPlans[i].deferrable
+		deferred := d != nil && planner.deferredConstraints.isDeferred(d)
+		var violations []tree.Datums
+		collecting := false
+		if n, ok := plan.checkPlans[i].plan.planNode.(*errorIfRowsNode); ok && deferred {
+			keyVals := plan.checkPlans[i].keyVals
+			n.onRow = func(row tree.Datums) {
+				violations = append(violations, keyVals(row))
+			}
+			collecting = true
+		}
 		if err := dsp.planAndRunPostquery(
 			ctx,
 			plan.checkPlans[i].plan,
@@ -1581,9 +1595,20 @@ func (dsp *DistSQLPlanner) PlanAndRunCascadesAndChecks(
 			evalCtxFactory(),
 			recv,
 		); err != nil {
+			if deferred && !collecting && isDeferredCheckViolation(err) {
+				// The violating rows are not known, so the constraint is validated
+				// against the whole table.
+				log.VEventf(ctx, 2, "deferring check for constraint %s", d.Name)
+				planner.deferredConstraints.addPending(d, nil /* keys */)
+				continue
+			}
 			recv.SetError(err)
 			return false
 		}
+		if len(violations) > 0 {
+			log.VEventf(ctx, 2, "deferring check for constraint %s on %d keys", d.Name, len(violations))
+			planner.deferredConstraints.addPending(d, violations)
+		}
 	}
 
 	return true
@@ -846,7 +846,7 @@ func (e *distSQLSpecExecFactory) ConstructPlan(
 	root exec.Node,
 	subqueries []exec.Subquery,
 	cascades []exec.Cascade,
-	checks []exec.Node,
+	checks []exec.Check,
 	rootRowCount int64,
 ) (exec.Plan, error) {
 	if len(subqueries) != 0 {
@@ -985,6 +985,7 @@ func (e *distSQLSpecExecFactory) ConstructDelete(
 	table cat.Table,
 	fetchCols exec.TableColumnOrdinalSet,
 	returnCols exec.TableColumnOrdinalSet,
+	passthrough colinfo.ResultColumns,
 	autoCommit bool,
 ) (exec.Node, error) {
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: delete")
@@ -1026,6 +1027,12 @@ func (e *distSQLSpecExecFactory) ConstructCreateView(
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: create view")
 }
 
+func (e *distSQLSpecExecFactory) ConstructCreateFunction(
+	schema cat.Schema, cf *tree.CreateFunction, deps opt.ViewDeps, typeDeps opt.ViewTypeDeps,
+) (exec.Node, error) {
+	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: create function")
+}
+
 func (e *distSQLSpecExecFactory) ConstructSequenceSelect(sequence cat.Sequence) (exec.Node, error) {
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: sequence select")
 }
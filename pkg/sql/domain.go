@@ -0,0 +1,243 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// A domain is a user defined type over a builtin base type, with an optional
+// default and constraints. A domain has its own types.T, which is represented
+// like its base type (see types.MakeDomainType). The constraints of a domain
+// are checked whenever a value is cast to it, which includes the assignment
+// casts of mutations, and the default of a domain is copied onto the columns
+// declared with it.
+
+// resolveDomainType returns the descriptor of the domain that ref names, or
+// nil if ref does not name a domain.
+func (p *planner) resolveDomainType(
+	ctx context.Context, ref tree.ResolvableTypeReference,
+) (catalog.TypeDescriptor, error) {
+	name, ok := ref.(*tree.UnresolvedObjectName)
+	if !ok {
+		return nil, nil
+	}
+	lookupFlags := tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{Required: false},
+		DesiredObjectKind: tree.TypeObject,
+	}
+	desc, _, err := resolver.ResolveExistingObject(ctx, p, name, lookupFlags)
+	if err != nil || desc == nil {
+		return nil, err
+	}
+	typDesc, ok := desc.(catalog.TypeDescriptor)
+	if !ok || typDesc.GetKind() != descpb.TypeDescriptor_DOMAIN {
+		return nil, nil
+	}
+	return typDesc, nil
+}
+
+// makeDomain validates the definition of a new domain with the given name and
+// returns its descriptor representation. If the domain is declared over
+// another domain, it inherits that domain's default and constraints.
+func (p *planner) makeDomain(
+	ctx context.Context, name string, def *tree.DomainDef,
+) (*descpb.TypeDescriptor_Domain, error) {
+	typ, err := tree.ResolveType(ctx, def.BaseType, p.semaCtx.GetTypeResolver())
+	if err != nil {
+		return nil, err
+	}
+	if typ.UserDefined() && !typ.IsDomain() {
+		return nil, unimplemented.New("domain base type",
+			"domains over user defined types other than domains are not supported")
+	}
+	if err := colinfo.ValidateColumnDefType(typ); err != nil {
+		return nil, err
+	}
+	domain := &descpb.TypeDescriptor_Domain{BaseType: typ.DomainBaseType()}
+
+	baseDomain, err := p.resolveDomainType(ctx, def.BaseType)
+	if err != nil {
+		return nil, err
+	}
+	if baseDomain != nil {
+		base := baseDomain.TypeDesc().Domain
+		domain.DefaultExpr = base.DefaultExpr
+		domain.NotNull = base.NotNull
+		domain.Checks = append(domain.Checks, base.Checks...)
+		domain.BaseDomainID = baseDomain.GetID()
+	}
+
+	if def.DefaultExpr != nil {
+		typedExpr, err := schemaexpr.SanitizeVarFreeExpr(
+			ctx, def.DefaultExpr, domain.BaseType, "DEFAULT", &p.semaCtx, tree.VolatilityVolatile,
+		)
+		if err != nil {
+			return nil, err
+		}
+		domain.DefaultExpr = nil
+		if typedExpr != tree.DNull {
+			s := tree.Serialize(typedExpr)
+			domain.DefaultExpr = &s
+		}
+	}
+	domain.NotNull = domain.NotNull || def.NotNull
+
+	for _, check := range def.Checks {
+		// Type check the expression with VALUE standing in for a value of the
+		// base type. The expression is stored as written, since VALUE is
+		// replaced by the value being checked whenever the domain is used.
+		expr, err := replaceDomainValue(check.Expr, tree.NewTypedCastExpr(tree.DNull, domain.BaseType))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := schemaexpr.SanitizeVarFreeExpr(
+			ctx, expr, types.Bool, "CHECK", &p.semaCtx, tree.VolatilityVolatile,
+		); err != nil {
+			return nil, err
+		}
+		checkName := string(check.Name)
+		if checkName == "" {
+			checkName = makeDomainCheckName(name, domain.Checks)
+		} else if domainHasCheck(domain.Checks, checkName) {
+			return nil, pgerror.Newf(pgcode.DuplicateObject,
+				"constraint %q for domain %q already exists", checkName, name)
+		}
+		domain.Checks = append(domain.Checks, descpb.TypeDescriptor_Domain_DomainCheck{
+			Name: checkName,
+			Expr: tree.Serialize(check.Expr),
+		})
+	}
+	return domain, nil
+}
+
+// makeDomainCheckName returns a name for an unnamed CHECK constraint of a
+// domain which does not conflict with the names of its other constraints,
+// following the naming scheme of Postgres.
+func makeDomainCheckName(domainName string, checks []descpb.TypeDescriptor_Domain_DomainCheck) string {
+	name := domainName + "_check"
+	for i := 1; domainHasCheck(checks, name); i++ {
+		name = fmt.Sprintf("%s_check%d", domainName, i)
+	}
+	return name
+}
+
+func domainHasCheck(checks []descpb.TypeDescriptor_Domain_DomainCheck, name string) bool {
+	for _, check := range checks {
+		if check.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addDomainDefault sets the default of a column definition whose type is a
+// domain to the default of the domain, unless the column has its own default.
+// Since domains cannot be altered, this is equivalent to using the default of
+// the domain when the column has no default.
+func (p *planner) addDomainDefault(ctx context.Context, d *tree.ColumnTableDef) error {
+	if d.HasDefaultExpr() || d.GeneratedIdentity.IsGeneratedAsIdentity || d.IsComputed() {
+		return nil
+	}
+	typDesc, err := p.resolveDomainType(ctx, d.Type)
+	if err != nil || typDesc == nil {
+		return err
+	}
+	if defaultExpr := typDesc.TypeDesc().Domain.DefaultExpr; defaultExpr != nil {
+		expr, err := parser.ParseExpr(*defaultExpr)
+		if err != nil {
+			return err
+		}
+		d.DefaultExpr.Expr = expr
+	}
+	return nil
+}
+
+// replaceDomainValue returns a copy of the domain constraint expression expr
+// in which every reference to VALUE is replaced with repl.
+func replaceDomainValue(expr tree.Expr, repl tree.Expr) (tree.Expr, error) {
+	return tree.SimpleVisit(expr, func(e tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		if n, ok := e.(*tree.UnresolvedName); ok && n.NumParts == 1 && n.Parts[0] == "value" {
+			return false, repl, nil
+		}
+		return true, e, nil
+	})
+}
+
+// checkDomainConstraints implements tree.CheckDomainConstraints.
+func checkDomainConstraints(evalCtx *tree.EvalContext, typ *types.T, d tree.Datum) error {
+	domain := typ.TypeMeta.DomainData
+	if domain == nil {
+		return errors.AssertionFailedf("domain %s is not hydrated", typ.SQLString())
+	}
+	if d == tree.DNull {
+		// NULL values satisfy the CHECK constraints of a domain.
+		return checkDomainNotNull(typ)
+	}
+	semaCtx := tree.MakeSemaContext()
+	if evalCtx.Planner != nil {
+		semaCtx.TypeResolver = evalCtx.Planner
+	}
+	for _, check := range domain.Checks {
+		expr, err := parser.ParseExpr(check.Expr)
+		if err != nil {
+			return err
+		}
+		if expr, err = replaceDomainValue(expr, d); err != nil {
+			return err
+		}
+		typedExpr, err := tree.TypeCheckAndRequire(evalCtx.Ctx(), expr, &semaCtx, types.Bool, "CHECK")
+		if err != nil {
+			return err
+		}
+		res, err := typedExpr.Eval(evalCtx)
+		if err != nil {
+			return err
+		}
+		if res != tree.DNull && !bool(tree.MustBeDBool(res)) {
+			return pgerror.Newf(pgcode.CheckViolation,
+				"value for domain %s violates check constraint %q", typ.Name(), check.Name)
+		}
+	}
+	return nil
+}
+
+// checkDomainNotNull returns an error if the domain typ does not allow NULL
+// values.
+func checkDomainNotNull(typ *types.T) error {
+	domain := typ.TypeMeta.DomainData
+	if domain == nil {
+		return errors.AssertionFailedf("domain %s is not hydrated", typ.SQLString())
+	}
+	if domain.NotNull {
+		return pgerror.Newf(pgcode.NotNullViolation,
+			"domain %s does not allow null values", typ.Name())
+	}
+	return nil
+}
+
+func init() {
+	tree.CheckDomainConstraints = checkDomainConstraints
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -36,6 +37,7 @@ type dropCascadeState struct {
 	toDeleteByID            map[descpb.ID]*toDelete
 	allTableObjectsToDelete []*tabledesc.Mutable
 	typesToDelete           []*typedesc.Mutable
+	functionsToDelete       []*funcdesc.Mutable
 
 	droppedNames []string
 }
@@ -66,6 +68,23 @@ func (d *dropCascadeState) collectObjectsInSchema(
 	for i := range names {
 		d.objectNamesToDelete = append(d.objectNamesToDelete, &names[i])
 	}
+	// Functions do not have namespace entries, so they are collected from the
+	// schema descriptor instead.
+	if err := schema.ForEachFunctionOverload(func(overload descpb.SchemaDescriptor_FunctionOverload) error {
+		fnDesc, err := p.Descriptors().GetMutableFunctionByID(ctx, p.txn, overload.ID, tree.ObjectLookupFlags{
+			CommonLookupFlags: tree.CommonLookupFlags{
+				Required:       true,
+				IncludeOffline: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		d.functionsToDelete = append(d.functionsToDelete, fnDesc)
+		return nil
+	}); err != nil {
+		return err
+	}
 	d.schemasToDelete = append(d.schemasToDelete, schemaWithDbDesc{schema: schema, dbDesc: db})
 	return nil
 }
@@ -76,6 +95,11 @@ func (d *dropCascadeState) collectObjectsInSchema(
 func (d *dropCascadeState) resolveCollectedObjects(
 	ctx context.Context, p *planner, db *dbdesc.Mutable,
 ) error {
+	for _, fnDesc := range d.functionsToDelete {
+		if err := p.canDropFunction(ctx, fnDesc); err != nil {
+			return err
+		}
+	}
 	d.td = make([]toDelete, 0, len(d.objectNamesToDelete))
 	// Resolve each of the collected names.
 	for i := range d.objectNamesToDelete {
@@ -187,7 +211,26 @@ func (d *dropCascadeState) resolveCollectedObjects(
 	return nil
 }
 
+// isEmpty returns true if no object was collected in the schemas to delete.
+func (d *dropCascadeState) isEmpty() bool {
+	return len(d.objectNamesToDelete) == 0 && len(d.functionsToDelete) == 0
+}
+
 func (d *dropCascadeState) dropAllCollectedObjects(ctx context.Context, p *planner) error {
+	// Delete all of the collected functions first, since they may depend on the
+	// tables and types below but nothing can depend on them. The schemas they
+	// belong to are dropped as well, so their function mappings are left as is.
+	for _, fnDesc := range d.functionsToDelete {
+		fnName, err := p.getQualifiedFunctionName(ctx, fnDesc)
+		if err != nil {
+			return err
+		}
+		if err := p.markFunctionDropped(ctx, fnDesc, ""); err != nil {
+			return err
+		}
+		d.droppedNames = append(d.droppedNames, fnName.FQString())
+	}
+
 	// Delete all of the collected tables.
 	for _, toDel := range d.td {
 		desc := toDel.desc
@@ -230,6 +273,16 @@ func (d *dropCascadeState) canDropType(
 		if _, exists := d.toDeleteByID[id]; exists {
 			continue
 		}
+		fnDesc, err := p.getDependentFunction(ctx, id)
+		if err != nil {
+			return err
+		}
+		if fnDesc != nil {
+			if fnDesc.Dropped() {
+				continue
+			}
+			return p.dependentFunctionError(ctx, "type", typ.Name, fnDesc, "drop")
+		}
 		referencedButNotDropping = append(referencedButNotDropping, id)
 	}
 	if len(referencedButNotDropping) == 0 {
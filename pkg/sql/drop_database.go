@@ -95,7 +95,7 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 		}
 	}
 
-	if len(d.objectNamesToDelete) > 0 {
+	if !d.isEmpty() {
 		switch n.DropBehavior {
 		case tree.DropRestrict:
 			return nil, pgerror.Newf(pgcode.DependentObjectsStillExist,
@@ -300,6 +300,12 @@ func (p *planner) accumulateCascadingViews(
 ) error {
 	visited[desc.ID] = struct{}{}
 	for _, ref := range desc.DependedOnBy {
+		// Functions are dropped along with the schemas which contain them.
+		if fnDesc, err := p.getDependentFunction(ctx, ref.ID); err != nil {
+			return err
+		} else if fnDesc != nil {
+			continue
+		}
 		dependentDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, ref.ID, p.txn)
 		if err != nil {
 			return err
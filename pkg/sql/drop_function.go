@@ -0,0 +1,243 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/errors"
+)
+
+type dropFunctionNode struct {
+	n      *tree.DropFunction
+	toDrop []*funcdesc.Mutable
+}
+
+// Use to satisfy the linter.
+var _ planNode = &dropFunctionNode{n: nil}
+
+// DropFunction drops user-defined functions.
+// Privileges: ownership of the functions.
+func (p *planner) DropFunction(ctx context.Context, n *tree.DropFunction) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"DROP FUNCTION",
+	); err != nil {
+		return nil, err
+	}
+
+	node := &dropFunctionNode{n: n}
+	seen := catalog.DescriptorIDSet{}
+	for i := range n.Functions {
+		fnDesc, err := p.resolveMutableFunction(ctx, &n.Functions[i], !n.IfExists)
+		if err != nil {
+			return nil, err
+		}
+		if fnDesc == nil || seen.Contains(fnDesc.GetID()) {
+			continue
+		}
+		if err := p.canDropFunction(ctx, fnDesc); err != nil {
+			return nil, err
+		}
+		if err := p.canDropTriggersOfFunction(ctx, fnDesc, n.DropBehavior); err != nil {
+			return nil, err
+		}
+		seen.Add(fnDesc.GetID())
+		node.toDrop = append(node.toDrop, fnDesc)
+	}
+	// The only objects which can reference functions are the triggers
+	// executing them, which are dropped along with the functions when
+	// n.DropBehavior is CASCADE.
+	return node, nil
+}
+
+func (n *dropFunctionNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("function"))
+	for _, fnDesc := range n.toDrop {
+		if err := params.p.dropFunctionImpl(
+			params.ctx, fnDesc, tree.AsStringWithFQNames(n.n, params.Ann()),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *dropFunctionNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *dropFunctionNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *dropFunctionNode) Close(ctx context.Context)           {}
+func (n *dropFunctionNode) ReadingOwnWrites()                   {}
+
+// canDropFunction returns an error if the current user cannot drop the
+// function. Only the owner of a function can drop it.
+func (p *planner) canDropFunction(ctx context.Context, fnDesc catalog.FunctionDescriptor) error {
+	hasOwnership, err := p.HasOwnership(ctx, fnDesc)
+	if err != nil {
+		return err
+	}
+	if !hasOwnership {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"must be owner of function %s", fnDesc.GetName())
+	}
+	return nil
+}
+
+// dropFunctionImpl unlinks the function from its parent schema and marks it as
+// dropped. The descriptor itself is deleted by the queued schema change job.
+func (p *planner) dropFunctionImpl(
+	ctx context.Context, fnDesc *funcdesc.Mutable, jobDesc string,
+) error {
+	scDesc, err := p.getMutableFunctionSchema(ctx, fnDesc.GetParentSchemaID())
+	if err != nil {
+		return err
+	}
+	// The schema is not updated if it is being dropped along with the function.
+	if !scDesc.Dropped() {
+		scDesc.RemoveFunction(fnDesc.GetName(), fnDesc.GetID())
+		if err := p.writeSchemaDescChange(
+			ctx, scDesc,
+			fmt.Sprintf("updating schema %q for drop of function %q", scDesc.GetName(), fnDesc.GetName()),
+		); err != nil {
+			return err
+		}
+	}
+	return p.markFunctionDropped(ctx, fnDesc, jobDesc)
+}
+
+// markFunctionDropped removes the back-references of the function and marks it
+// as dropped, without updating its parent schema.
+func (p *planner) markFunctionDropped(
+	ctx context.Context, fnDesc *funcdesc.Mutable, jobDesc string,
+) error {
+	if fnDesc.Dropped() {
+		return errors.AssertionFailedf("function %q (%d) is already being dropped",
+			fnDesc.GetName(), fnDesc.GetID())
+	}
+	if err := p.dropTriggersOfFunction(ctx, fnDesc, jobDesc); err != nil {
+		return err
+	}
+	if err := p.removeFuncBackReferences(ctx, fnDesc, jobDesc); err != nil {
+		return err
+	}
+	if err := p.removeFunctionComment(ctx, fnDesc.GetID()); err != nil {
+		return err
+	}
+	fnDesc.SetDropped()
+	return p.writeFuncSchemaChange(ctx, fnDesc, jobDesc)
+}
+
+// getDependentFunction returns the function with the given ID, which holds a
+// back-reference from a relation or a type, or nil if the back-reference is
+// held by another kind of descriptor, such as a view.
+func (p *planner) getDependentFunction(
+	ctx context.Context, id descpb.ID,
+) (*funcdesc.Mutable, error) {
+	desc, err := p.Descriptors().GetImmutableDescriptorByID(ctx, p.txn, id, tree.CommonLookupFlags{
+		Required:       true,
+		AvoidLeased:    true,
+		IncludeOffline: true,
+		IncludeDropped: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if desc.DescriptorType() != catalog.Function {
+		return nil, nil
+	}
+	return p.Descriptors().GetMutableFunctionByID(ctx, p.txn, id, tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{
+			Required:       true,
+			IncludeDropped: true,
+		},
+	})
+}
+
+// canRemoveDependentFunction returns an error if the function, which depends
+// on the given object, cannot be dropped along with it.
+func (p *planner) canRemoveDependentFunction(
+	ctx context.Context,
+	typeName, objName string,
+	fnDesc *funcdesc.Mutable,
+	behavior tree.DropBehavior,
+) error {
+	if behavior != tree.DropCascade {
+		return p.dependentFunctionError(ctx, typeName, objName, fnDesc, "drop")
+	}
+	if err := p.canDropFunction(ctx, fnDesc); err != nil {
+		return err
+	}
+	return p.canDropTriggersOfFunction(ctx, fnDesc, behavior)
+}
+
+// dependentFunctionError returns an error stating that the operation on the
+// given object is not allowed because the function depends on it.
+func (p *planner) dependentFunctionError(
+	ctx context.Context, typeName, objName string, fnDesc catalog.FunctionDescriptor, op string,
+) error {
+	fnName, err := p.getQualifiedFunctionName(ctx, fnDesc)
+	if err != nil {
+		return err
+	}
+	return errors.WithHintf(
+		sqlerrors.NewDependentObjectErrorf("cannot %s %s %q because function %q depends on it",
+			op, typeName, objName, fnName.FQString()),
+		"you can drop %s instead.", fnName.FQString())
+}
+
+// maybeDropDependentFunction drops the function with the given ID if the
+// depended-on-by reference with that ID is held by a function rather than by
+// a view. It returns the name of the dropped function, if any.
+func (p *planner) maybeDropDependentFunction(
+	ctx context.Context, id descpb.ID, jobDesc string,
+) (droppedName string, isFunction bool, _ error) {
+	fnDesc, err := p.getDependentFunction(ctx, id)
+	if err != nil || fnDesc == nil {
+		return "", false, err
+	}
+	if fnDesc.Dropped() {
+		return "", true, nil
+	}
+	fnName, err := p.getQualifiedFunctionName(ctx, fnDesc)
+	if err != nil {
+		return "", true, err
+	}
+	if err := p.dropFunctionImpl(ctx, fnDesc, jobDesc); err != nil {
+		return "", true, err
+	}
+	return fnName.FQString(), true, nil
+}
+
+func (p *planner) removeFunctionComment(ctx context.Context, fnID descpb.ID) error {
+	_, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.ExecEx(
+		ctx,
+		"delete-function-comment",
+		p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		"DELETE FROM system.comments WHERE type=$1 AND object_id=$2 AND sub_id=0",
+		keys.FunctionCommentType,
+		fnID)
+
+	return err
+}
@@ -268,11 +268,30 @@ func (p *planner) dropTableImpl(
 		}
 	}
 
+	// Remove the back-references from the functions executed by the triggers
+	// of the table.
+	if err := p.removeTableTriggerBackReferences(
+		ctx, tableDesc, "updating functions for drop of table triggers",
+	); err != nil {
+		return droppedViews, err
+	}
+	tableDesc.Triggers = nil
+
 	// Drop all views that depend on this table, assuming that we wouldn't have
 	// made it to this point if `cascade` wasn't enabled.
 	// Copy out the set of dependencies as it may be overwritten in the loop.
 	dependedOnBy := append([]descpb.TableDescriptor_Reference(nil), tableDesc.DependedOnBy...)
 	for _, ref := range dependedOnBy {
+		if droppedFn, isFn, err := p.maybeDropDependentFunction(
+			ctx, ref.ID, "dropping dependent function",
+		); err != nil {
+			return droppedViews, err
+		} else if isFn {
+			if droppedFn != "" {
+				droppedViews = append(droppedViews, droppedFn)
+			}
+			continue
+		}
 		viewDesc, err := p.getViewDescForCascade(
 			ctx, string(tableDesc.DescriptorType()), tableDesc.Name, tableDesc.ParentID, ref.ID, tree.DropCascade,
 		)
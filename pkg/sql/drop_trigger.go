@@ -0,0 +1,219 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/errors"
+)
+
+type dropTriggerNode struct {
+	n         *tree.DropTrigger
+	tableDesc *tabledesc.Mutable
+}
+
+// Use to satisfy the linter.
+var _ planNode = &dropTriggerNode{n: nil}
+
+// DropTrigger drops a trigger of a table.
+// Privileges: CREATE on the table.
+func (p *planner) DropTrigger(ctx context.Context, n *tree.DropTrigger) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"DROP TRIGGER",
+	); err != nil {
+		return nil, err
+	}
+
+	_, tableDesc, err := p.ResolveMutableTableDescriptor(
+		ctx, &n.Table, !n.IfExists, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		return newZeroNode(nil /* columns */), nil
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	if _, ok := tableDesc.FindTriggerByName(string(n.Name)); !ok {
+		if n.IfExists {
+			return newZeroNode(nil /* columns */), nil
+		}
+		return nil, pgerror.Newf(pgcode.UndefinedObject,
+			"trigger %q for table %q does not exist", n.Name, tableDesc.GetName())
+	}
+	// Since triggers cannot be referenced by other objects, there is nothing
+	// to cascade to and n.DropBehavior can be ignored.
+	return &dropTriggerNode{n: n, tableDesc: tableDesc}, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because DROP TRIGGER performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *dropTriggerNode) ReadingOwnWrites() {}
+
+func (n *dropTriggerNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("trigger"))
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+
+	trigger, _ := n.tableDesc.FindTriggerByName(string(n.n.Name))
+	fnID := trigger.FunctionID
+	n.tableDesc.RemoveTrigger(trigger.Name)
+	if err := params.p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID, jobDesc,
+	); err != nil {
+		return err
+	}
+	return params.p.maybeRemoveTriggerFunctionBackReference(params.ctx, n.tableDesc, fnID, jobDesc)
+}
+
+func (n *dropTriggerNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *dropTriggerNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *dropTriggerNode) Close(ctx context.Context)           {}
+
+// maybeRemoveTriggerFunctionBackReference removes the back-reference from the
+// function with the given ID to the table, unless the function is still
+// executed by another trigger of the table.
+func (p *planner) maybeRemoveTriggerFunctionBackReference(
+	ctx context.Context, tableDesc catalog.TableDescriptor, fnID descpb.ID, jobDesc string,
+) error {
+	for i := range tableDesc.GetTriggers() {
+		if tableDesc.GetTriggers()[i].FunctionID == fnID {
+			return nil
+		}
+	}
+	fnDesc, err := p.Descriptors().GetMutableFunctionByID(ctx, p.txn, fnID, tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{
+			Required:       true,
+			IncludeDropped: true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if fnDesc.Dropped() {
+		// The function is being dropped along with the trigger.
+		return nil
+	}
+	fnDesc.RemoveDependedOnBy(tableDesc.GetID())
+	return p.writeFuncSchemaChange(ctx, fnDesc, jobDesc)
+}
+
+// removeTableTriggerBackReferences removes the back-references from the
+// functions executed by the triggers of the table, which is being dropped.
+func (p *planner) removeTableTriggerBackReferences(
+	ctx context.Context, tableDesc catalog.TableDescriptor, jobDesc string,
+) error {
+	seen := catalog.DescriptorIDSet{}
+	for i := range tableDesc.GetTriggers() {
+		fnID := tableDesc.GetTriggers()[i].FunctionID
+		if seen.Contains(fnID) {
+			continue
+		}
+		seen.Add(fnID)
+		fnDesc, err := p.Descriptors().GetMutableFunctionByID(ctx, p.txn, fnID, tree.ObjectLookupFlags{
+			CommonLookupFlags: tree.CommonLookupFlags{
+				Required:       true,
+				IncludeDropped: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if fnDesc.Dropped() {
+			// The function is being dropped along with the table.
+			continue
+		}
+		fnDesc.RemoveDependedOnBy(tableDesc.GetID())
+		if err := p.writeFuncSchemaChange(ctx, fnDesc, jobDesc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canDropTriggersOfFunction returns an error if the function, which is being
+// dropped, is executed by triggers which cannot be dropped along with it.
+func (p *planner) canDropTriggersOfFunction(
+	ctx context.Context, fnDesc catalog.FunctionDescriptor, behavior tree.DropBehavior,
+) error {
+	for _, id := range fnDesc.GetDependedOnBy() {
+		tableDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, id, p.txn)
+		if err != nil {
+			return err
+		}
+		if behavior != tree.DropCascade {
+			for i := range tableDesc.GetTriggers() {
+				trigger := &tableDesc.GetTriggers()[i]
+				if trigger.FunctionID != fnDesc.GetID() {
+					continue
+				}
+				return errors.WithHintf(
+					sqlerrors.NewDependentObjectErrorf(
+						"cannot drop function %q because trigger %q on table %q depends on it",
+						fnDesc.GetName(), trigger.Name, tableDesc.GetName()),
+					"use CASCADE to drop the trigger along with the function.")
+			}
+		}
+		if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropTriggersOfFunction drops the triggers which execute the function, which
+// is being dropped.
+func (p *planner) dropTriggersOfFunction(
+	ctx context.Context, fnDesc *funcdesc.Mutable, jobDesc string,
+) error {
+	for _, id := range fnDesc.GetDependedOnBy() {
+		tableDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, id, p.txn)
+		if err != nil {
+			return err
+		}
+		if tableDesc.Dropped() {
+			// The table is being dropped along with the function.
+			continue
+		}
+		var toRemove []string
+		for i := range tableDesc.Triggers {
+			if tableDesc.Triggers[i].FunctionID == fnDesc.GetID() {
+				toRemove = append(toRemove, tableDesc.Triggers[i].Name)
+			}
+		}
+		for _, name := range toRemove {
+			tableDesc.RemoveTrigger(name)
+		}
+		if err := p.writeSchemaChange(
+			ctx, tableDesc, descpb.InvalidMutationID, jobDesc,
+		); err != nil {
+			return err
+		}
+	}
+	fnDesc.DependedOnBy = nil
+	return nil
+}
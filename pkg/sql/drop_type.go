@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/errors"
@@ -65,6 +66,9 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 		if _, ok := node.toDrop[typeDesc.ID]; ok {
 			continue
 		}
+		if n.Domain && typeDesc.Kind != descpb.TypeDescriptor_DOMAIN {
+			return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not a domain", name)
+		}
 		switch typeDesc.Kind {
 		case descpb.TypeDescriptor_ALIAS:
 			// The implicit array types are not directly droppable.
@@ -90,6 +94,13 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 				"cannot drop type %q because table %q requires it",
 				name, name,
 			)
+		case descpb.TypeDescriptor_DOMAIN:
+			if !n.Domain {
+				return nil, errors.WithHint(
+					pgerror.Newf(pgcode.WrongObjectType, "%q is a domain", name),
+					"Use DROP DOMAIN to remove a domain.",
+				)
+			}
 		}
 
 		// Check if we can drop the type.
@@ -97,6 +108,12 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 			return nil, err
 		}
 
+		// Domains do not have an array type.
+		if typeDesc.Kind == descpb.TypeDescriptor_DOMAIN {
+			node.toDrop[typeDesc.ID] = typeDesc
+			continue
+		}
+
 		// Get the array type that needs to be dropped as well.
 		mutArrayDesc, err := p.Descriptors().GetMutableTypeVersionByID(ctx, p.txn, typeDesc.ArrayTypeID)
 		if err != nil {
@@ -120,6 +137,22 @@ func (p *planner) canDropTypeDesc(
 		return err
 	}
 	if len(desc.ReferencingDescriptorIDs) > 0 && behavior != tree.DropCascade {
+		for _, id := range desc.ReferencingDescriptorIDs {
+			if fnDesc, err := p.getDependentFunction(ctx, id); err != nil {
+				return err
+			} else if fnDesc != nil {
+				return p.dependentFunctionError(ctx, "type", desc.Name, fnDesc, "drop")
+			}
+			if typDesc, err := p.getDependentType(ctx, id); err != nil {
+				return err
+			} else if typDesc != nil {
+				return pgerror.Newf(
+					pgcode.DependentObjectsStillExist,
+					"cannot drop type %q because type %q depends on it",
+					desc.Name, typDesc.GetName(),
+				)
+			}
+		}
 		dependentNames, err := p.getFullyQualifiedTableNamesFromIDs(ctx, desc.ReferencingDescriptorIDs)
 		if err != nil {
 			return errors.Wrapf(err, "type %q has dependent objects", desc.Name)
@@ -134,6 +167,57 @@ func (p *planner) canDropTypeDesc(
 	return nil
 }
 
+// getDependentType returns the descriptor of the type with the given ID, or
+// nil if the descriptor is not a type. It is used to find the composite types
+// and domains that depend on a type.
+func (p *planner) getDependentType(
+	ctx context.Context, id descpb.ID,
+) (catalog.TypeDescriptor, error) {
+	desc, err := p.Descriptors().GetImmutableDescriptorByID(ctx, p.txn, id, tree.CommonLookupFlags{
+		Required:       true,
+		AvoidLeased:    true,
+		IncludeOffline: true,
+		IncludeDropped: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	typDesc, ok := desc.(catalog.TypeDescriptor)
+	if !ok {
+		return nil, nil
+	}
+	return typDesc, nil
+}
+
+// getReferencedTypeIDs returns the IDs of the user defined types that the
+// given composite type or domain depends on.
+func getReferencedTypeIDs(desc catalog.TypeDescriptor) ([]descpb.ID, error) {
+	var refTypes []*types.T
+	var ids catalog.DescriptorIDSet
+	switch desc.GetKind() {
+	case descpb.TypeDescriptor_COMPOSITE:
+		for _, e := range desc.TypeDesc().Composite.Elements {
+			refTypes = append(refTypes, e.ElementType)
+		}
+	case descpb.TypeDescriptor_DOMAIN:
+		// The base type of a domain is always a builtin type, so a domain can
+		// only depend on the domain it was declared over.
+		if baseDomainID := desc.TypeDesc().Domain.BaseDomainID; baseDomainID != descpb.InvalidID {
+			ids.Add(baseDomainID)
+		}
+	}
+	for _, typ := range refTypes {
+		children, err := typedesc.GetTypeDescriptorClosure(typ)
+		if err != nil {
+			return nil, err
+		}
+		for id := range children {
+			ids.Add(id)
+		}
+	}
+	return ids.Ordered(), nil
+}
+
 func (n *dropTypeNode) startExec(params runParams) error {
 	for _, typeDesc := range n.toDrop {
 		typeFQName, err := getTypeNameFromTypeDescriptor(
@@ -143,6 +227,18 @@ func (n *dropTypeNode) startExec(params runParams) error {
 		if err != nil {
 			return err
 		}
+		// Remove the back-references from the types that a composite type or
+		// domain depends on.
+		refTypeIDs, err := getReferencedTypeIDs(typeDesc)
+		if err != nil {
+			return err
+		}
+		if len(refTypeIDs) > 0 {
+			jobDesc := fmt.Sprintf("updating type back references %v for type %d", refTypeIDs, typeDesc.ID)
+			if err := params.p.removeTypeBackReferences(params.ctx, refTypeIDs, typeDesc.ID, jobDesc); err != nil {
+				return err
+			}
+		}
 		err = params.p.dropTypeImpl(params.ctx, typeDesc, "dropping type "+typeFQName.FQString(), true /* queueJob */)
 		if err != nil {
 			return err
@@ -163,6 +163,11 @@ func (p *planner) canRemoveDependentViewGeneric(
 	ref descpb.TableDescriptor_Reference,
 	behavior tree.DropBehavior,
 ) error {
+	if fnDesc, err := p.getDependentFunction(ctx, ref.ID); err != nil {
+		return err
+	} else if fnDesc != nil {
+		return p.canRemoveDependentFunction(ctx, typeName, objName, fnDesc, behavior)
+	}
 	viewDesc, err := p.getViewDescForCascade(ctx, typeName, objName, parentID, ref.ID, behavior)
 	if err != nil {
 		return err
@@ -239,6 +244,16 @@ func (p *planner) dropViewImpl(
 	if behavior == tree.DropCascade {
 		dependedOnBy := append([]descpb.TableDescriptor_Reference(nil), viewDesc.DependedOnBy...)
 		for _, ref := range dependedOnBy {
+			if droppedFn, isFn, err := p.maybeDropDependentFunction(
+				ctx, ref.ID, "dropping dependent function",
+			); err != nil {
+				return cascadeDroppedViews, err
+			} else if isFn {
+				if droppedFn != "" {
+					cascadeDroppedViews = append(cascadeDroppedViews, droppedFn)
+				}
+				continue
+			}
 			dependentDesc, err := p.getViewDescForCascade(
 				ctx, string(viewDesc.DescriptorType()), viewDesc.Name, viewDesc.ParentID, ref.ID, behavior,
 			)
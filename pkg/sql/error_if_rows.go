@@ -26,6 +26,12 @@ type errorIfRowsNode struct {
 	// produced.
 	mkErr exec.MkErrFn
 
+	// onRow, if set, is called with each row produced by the wrapped node
+	// instead of returning an error. It is used by the checks of deferred
+	// constraints, whose violations are only reported if they still exist at
+	// the end of the transaction.
+	onRow func(tree.Datums)
+
 	nexted bool
 }
 
@@ -39,14 +45,16 @@ func (n *errorIfRowsNode) Next(params runParams) (bool, error) {
 	}
 	n.nexted = true
 
-	ok, err := n.plan.Next(params)
-	if err != nil {
-		return false, err
-	}
-	if ok {
-		return false, n.mkErr(n.plan.Values())
+	for {
+		ok, err := n.plan.Next(params)
+		if err != nil || !ok {
+			return false, err
+		}
+		if n.onRow == nil {
+			return false, n.mkErr(n.plan.Values())
+		}
+		n.onRow(n.plan.Values())
 	}
-	return false, nil
 }
 
 func (n *errorIfRowsNode) Values() tree.Datums {
@@ -66,6 +66,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob/gcjobnotifier"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -192,6 +193,14 @@ var allowCrossDatabaseSeqReferences = settings.RegisterBoolSetting(
 	false,
 ).WithPublic()
 
+var allowReadCommittedIsolation = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.txn.read_committed_isolation.enabled",
+	"if true, transactions may run under READ COMMITTED isolation; otherwise, "+
+		"they are upgraded to SERIALIZABLE isolation",
+	false,
+)
+
 // SecondaryTenantsZoneConfigsEnabledSettingName controls if secondary tenants
 // are allowed to set zone configurations. It has no effect for the system
 // tenant.
@@ -1254,6 +1263,11 @@ type ExecutorConfig struct {
 
 	RangeFeedFactory *rangefeed.Factory
 
+	// NotificationRegistry delivers the notifications sent with NOTIFY to the
+	// sessions which executed LISTEN. It is nil if LISTEN is not supported,
+	// e.g. in tests which don't start a full server.
+	NotificationRegistry *notify.Registry
+
 	// VersionUpgradeHook is called after validating a `SET CLUSTER SETTING
 	// version` but before executing it. It can carry out arbitrary migrations
 	// that allow us to eventually remove legacy code.
@@ -2933,6 +2947,10 @@ func (m *sessionDataMutator) SetDefaultTransactionPriority(val tree.UserPriority
 	m.data.DefaultTxnPriority = int64(val)
 }
 
+func (m *sessionDataMutator) SetDefaultTransactionIsolationLevel(val tree.IsolationLevel) {
+	m.data.DefaultTxnIsolationLevel = int64(val)
+}
+
 func (m *sessionDataMutator) SetDefaultTransactionReadOnly(val bool) {
 	m.data.DefaultTxnReadOnly = val
 }
@@ -3306,6 +3324,10 @@ func (m *sessionDataMutator) SetPreparedStatementsCacheSize(val int64) {
 	m.data.PreparedStatementsCacheSize = val
 }
 
+func (m *sessionDataMutator) SetMaxRetriesForReadCommitted(val int32) {
+	m.data.MaxRetriesForReadCommitted = val
+}
+
 // Utility functions related to scrubbing sensitive information on SQL Stats.
 
 // quantizeCounts ensures that the Count field in the
@@ -288,6 +288,16 @@ func (*DummyEvalPlanner) RepairTTLScheduledJobForTable(ctx context.Context, tabl
 	return errors.WithStack(errEvalPlanner)
 }
 
+// Notify is part of the EvalPlanner interface.
+func (*DummyEvalPlanner) Notify(ctx context.Context, channel, payload string) error {
+	return errors.WithStack(errEvalPlanner)
+}
+
+// ListeningChannels is part of the EvalPlanner interface.
+func (*DummyEvalPlanner) ListeningChannels() []string {
+	return nil
+}
+
 // ExecutorConfig is part of the EvalPlanner interface.
 func (*DummyEvalPlanner) ExecutorConfig() interface{} {
 	return nil
@@ -0,0 +1,138 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvfollowerreads"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan/replicaoracle"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// followerReadOracle is a replicaoracle.Oracle which plans the reads of
+// transactions whose timestamp is likely to be closed on the closest replica,
+// and all others on the leaseholder.
+type followerReadOracle struct {
+	st         *cluster.Settings
+	clock      *hlc.Clock
+	closest    replicaoracle.Oracle
+	binPacking replicaoracle.Oracle
+}
+
+func newFollowerReadOracle(cfg replicaoracle.Config) replicaoracle.Oracle {
+	return &followerReadOracle{
+		st:         cfg.Settings,
+		clock:      cfg.RPCContext.Clock,
+		closest:    replicaoracle.NewOracle(replicaoracle.ClosestChoice, cfg),
+		binPacking: replicaoracle.NewOracle(replicaoracle.BinPackingChoice, cfg),
+	}
+}
+
+// ChoosePreferredReplica is part of the replicaoracle.Oracle interface.
+func (o *followerReadOracle) ChoosePreferredReplica(
+	ctx context.Context,
+	txn *kv.Txn,
+	desc *roachpb.RangeDescriptor,
+	leaseholder *roachpb.ReplicaDescriptor,
+	ctPolicy roachpb.RangeClosedTimestampPolicy,
+	queryState replicaoracle.QueryState,
+) (roachpb.ReplicaDescriptor, error) {
+	var oracle replicaoracle.Oracle
+	if o.useClosestOracle(txn, ctPolicy) {
+		oracle = o.closest
+	} else {
+		oracle = o.binPacking
+	}
+	return oracle.ChoosePreferredReplica(ctx, txn, desc, leaseholder, ctPolicy, queryState)
+}
+
+func (o *followerReadOracle) useClosestOracle(
+	txn *kv.Txn, ctPolicy roachpb.RangeClosedTimestampPolicy,
+) bool {
+	// NOTE: this logic is almost identical to the one the DistSender uses to
+	// route a BatchRequest (see kvcoord.CanSendToFollower), except that it
+	// operates on a *kv.Txn instead of a roachpb.BatchRequest. As a result, the
+	// function does not check BatchCanBeEvaluatedOnFollower. This is because we
+	// assume that if a request is going to be executed in a distributed DistSQL
+	// flow (which is why it is consulting a replicaoracle.Oracle), then all of
+	// the individual BatchRequests that it send will be eligible to be served
+	// on follower replicas as follower reads.
+	//
+	// If we were to get this assumption wrong, the flow might be planned on a
+	// node with a follower replica, but individual BatchRequests would still be
+	// sent to the correct replicas once CanSendToFollower is checked for each
+	// BatchRequests in the DistSender. This would hurt performance, but would
+	// not violate correctness.
+	return txn != nil &&
+		kvfollowerreads.ClosedTimestampLikelySufficient(o.st, o.clock, ctPolicy, txn.RequiredFrontier()) &&
+		kvfollowerreads.Enabled(o.st)
+}
+
+// followerReadOraclePolicy is a leaseholder choosing policy that detects
+// whether a query can be used with a follower read.
+var followerReadOraclePolicy = replicaoracle.RegisterPolicy(newFollowerReadOracle)
+
+// evalFollowerReadOffset implements builtins.EvalFollowerReadOffset.
+func evalFollowerReadOffset(_ uuid.UUID, st *cluster.Settings) (time.Duration, error) {
+	// NOTE: we assume that at least some of the ranges being queried use a
+	// LAG_BY_CLUSTER_SETTING closed timestamp policy. Otherwise, there would
+	// be no reason to use AS OF SYSTEM TIME follower_read_timestamp().
+	return kvfollowerreads.FollowerReadLag(st), nil
+}
+
+// evalMaxStaleness implements the with_max_staleness builtin: it returns the
+// oldest timestamp a bounded staleness read may be served at.
+func evalMaxStaleness(ctx *tree.EvalContext, d duration.Duration) (time.Time, error) {
+	if d.Compare(duration.FromInt64(0)) < 0 {
+		return time.Time{}, pgerror.Newf(
+			pgcode.InvalidParameterValue,
+			"interval duration for %s must be greater or equal to 0",
+			tree.WithMaxStalenessFunctionName,
+		)
+	}
+	return duration.Add(ctx.GetStmtTimestamp(), d.Mul(-1)), nil
+}
+
+// evalMinTimestamp implements the with_min_timestamp builtin.
+func evalMinTimestamp(ctx *tree.EvalContext, t time.Time) (time.Time, error) {
+	t = t.Round(time.Microsecond)
+	if stmtTimestamp := ctx.GetStmtTimestamp().Round(time.Microsecond); t.After(stmtTimestamp) {
+		return time.Time{}, errors.WithDetailf(
+			pgerror.Newf(
+				pgcode.InvalidParameterValue,
+				"minimum timestamp for %s must be less than or equal to statement_timestamp()",
+				tree.WithMinTimestampFunctionName,
+			),
+			"statement timestamp: %d, min_timestamp: %d",
+			stmtTimestamp.UnixNano(),
+			t.UnixNano(),
+		)
+	}
+	return t, nil
+}
+
+func init() {
+	builtins.EvalFollowerReadOffset = evalFollowerReadOffset
+	builtins.WithMinTimestamp = evalMinTimestamp
+	builtins.WithMaxStaleness = evalMaxStaleness
+}
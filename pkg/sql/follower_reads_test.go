@@ -0,0 +1,102 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowerReadTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 1, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '3s'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.side_transport_interval = '200ms'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.propagation_slack = '1s'`)
+	sqlDB.CheckQueryResults(t,
+		`SELECT statement_timestamp() - follower_read_timestamp()`,
+		[][]string{{"00:00:04.2"}},
+	)
+}
+
+func TestFollowerReadsServedByFollower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 3, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+	})
+	defer tc.Stopper().Stop(ctx)
+
+	n1 := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	n1.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	n1.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.side_transport_interval = '10ms'`)
+	n1.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.propagation_slack = '10ms'`)
+	n1.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	n1.Exec(t, `INSERT INTO t SELECT i, i FROM generate_series(1, 10) AS g(i)`)
+	n1.Exec(t, `ALTER TABLE t SPLIT AT VALUES (0)`)
+	n1.Exec(t, `ALTER TABLE t EXPERIMENTAL_RELOCATE VALUES (ARRAY[1, 2, 3], 0)`)
+	n1.Exec(t, `ALTER TABLE t EXPERIMENTAL_RELOCATE LEASE VALUES (1, 0)`)
+
+	followerReads := func() int64 {
+		var count int64
+		require.NoError(t, tc.Server(2).GetStores().(*kvserver.Stores).VisitStores(func(s *kvserver.Store) error {
+			count += s.Metrics().FollowerReadsCount.Count()
+			return nil
+		}))
+		return count
+	}
+
+	// Node 3 holds a follower replica of the table, and serves the reads it
+	// gets below the closed timestamp from it.
+	n3 := tc.ServerConn(2)
+	for _, query := range []string{
+		`SELECT count(*) FROM t AS OF SYSTEM TIME follower_read_timestamp()`,
+		`SELECT count(*) FROM t AS OF SYSTEM TIME with_max_staleness('1h')`,
+	} {
+		before := followerReads()
+		testutils.SucceedsSoon(t, func() error {
+			var count int
+			if err := n3.QueryRow(query).Scan(&count); err != nil {
+				return err
+			}
+			if count != 10 {
+				return errors.Errorf("expected 10 rows, got %d", count)
+			}
+			if followerReads() == before {
+				return errors.Errorf("%s not served by a follower", query)
+			}
+			return nil
+		})
+	}
+
+	n1.ExpectErr(t, `interval duration for with_max_staleness must be greater or equal to 0`,
+		`SELECT * FROM t AS OF SYSTEM TIME with_max_staleness('-1s')`)
+	n1.ExpectErr(t, `minimum timestamp for with_min_timestamp must be less than or equal to statement_timestamp\(\)`,
+		`SELECT * FROM t AS OF SYSTEM TIME with_min_timestamp(statement_timestamp() + '1h')`)
+}
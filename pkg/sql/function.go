@@ -0,0 +1,359 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+)
+
+var _ tree.FunctionReferenceResolver = (*planner)(nil)
+
+// ResolveFunction implements the tree.FunctionReferenceResolver interface.
+// The overloads of the builtin function with the given name, if any, are
+// merged with the overloads of the user-defined functions with that name that
+// are visible on the search path and that the current user can execute.
+func (p *planner) ResolveFunction(
+	ctx context.Context, name *tree.UnresolvedName, path sessiondata.SearchPath,
+) (*tree.FunctionDefinition, error) {
+	builtinDef, builtinErr := name.ResolveFunction(path)
+	if builtinDef != nil && builtinDef.Class != tree.NormalClass {
+		// Aggregate, window and generator builtins cannot be overloaded by
+		// user-defined functions.
+		return builtinDef, nil
+	}
+	if builtinErr != nil && pgerror.GetPGCode(builtinErr) != pgcode.UndefinedFunction {
+		return nil, builtinErr
+	}
+	udfs, privErr, err := p.getUDFOverloads(ctx, name, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(udfs) == 0 {
+		if builtinDef == nil && privErr != nil {
+			return nil, privErr
+		}
+		return builtinDef, builtinErr
+	}
+	return tree.NewFunctionDefinitionWithUDFs(name.Parts[0], builtinDef, udfs), nil
+}
+
+// ResolveFunctionByOID implements the tree.FunctionReferenceResolver
+// interface.
+func (p *planner) ResolveFunctionByOID(
+	ctx context.Context, oid oid.Oid,
+) (*tree.FunctionDefinition, error) {
+	id, err := funcdesc.UserDefinedFunctionOIDToID(oid)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := p.Descriptors().GetImmutableFunctionByID(
+		ctx, p.txn, id, tree.ObjectLookupFlagsWithRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	overload, err := makeUDFOverload(desc)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewFunctionDefinitionWithUDFs(desc.GetName(), nil /* def */, []*tree.Overload{overload}), nil
+}
+
+// getUDFOverloads returns the overloads of the user-defined functions with the
+// given name which are visible on the search path, or in the schema the name
+// is qualified with. Functions which the current user is not allowed to execute
+// are skipped; in that case, the privilege error is returned as well so that
+// it can be reported if the name does not resolve to any other function.
+func (p *planner) getUDFOverloads(
+	ctx context.Context, name *tree.UnresolvedName, path sessiondata.SearchPath,
+) (overloads []*tree.Overload, privErr error, _ error) {
+	if p.txn == nil {
+		return nil, nil, nil
+	}
+	err := p.forEachFunctionSchema(ctx, name.Parts[2], name.Parts[1], path,
+		func(sc catalog.SchemaDescriptor) error {
+			fn, ok := sc.GetFunction(name.Parts[0])
+			if !ok {
+				return nil
+			}
+			if err := p.canResolveDescUnderSchema(ctx, sc, nil /* desc */); err != nil {
+				privErr = err
+				return nil
+			}
+			for _, o := range fn.Overloads {
+				desc, err := p.Descriptors().GetImmutableFunctionByID(
+					ctx, p.txn, o.ID, tree.ObjectLookupFlagsWithRequired(),
+				)
+				if err != nil {
+					return err
+				}
+				if err := p.CheckPrivilege(ctx, desc, privilege.EXECUTE); err != nil {
+					privErr = err
+					continue
+				}
+				overload, err := makeUDFOverload(desc)
+				if err != nil {
+					return err
+				}
+				overloads = append(overloads, overload)
+			}
+			return nil
+		})
+	return overloads, privErr, err
+}
+
+// forEachFunctionSchema calls fn on each schema that may hold a function with
+// the given catalog and schema prefix. If the schema is not specified, all the
+// schemas of the search path are visited, in order. Schemas which cannot hold
+// user-defined functions, such as temporary schemas, are skipped.
+func (p *planner) forEachFunctionSchema(
+	ctx context.Context,
+	dbName, scName string,
+	path sessiondata.SearchPath,
+	fn func(sc catalog.SchemaDescriptor) error,
+) error {
+	if dbName == "" {
+		dbName = p.CurrentDatabase()
+	}
+	if dbName == "" {
+		return nil
+	}
+	db, err := p.Descriptors().GetImmutableDatabaseByName(
+		ctx, p.txn, dbName, tree.DatabaseLookupFlags{AvoidSynthetic: true},
+	)
+	if err != nil || db == nil {
+		return err
+	}
+	visit := func(scName string) error {
+		if strings.HasPrefix(scName, catconstants.PgTempSchemaName) {
+			return nil
+		}
+		sc, err := p.Descriptors().GetImmutableSchemaByName(
+			ctx, p.txn, db, scName, tree.SchemaLookupFlags{},
+		)
+		if err != nil || sc == nil || sc.SchemaKind() != catalog.SchemaUserDefined {
+			return err
+		}
+		return fn(sc)
+	}
+	if scName != "" {
+		return visit(scName)
+	}
+	iter := path.IterWithoutImplicitPGSchemas()
+	for scName, ok := iter.Next(); ok; scName, ok = iter.Next() {
+		if err := visit(scName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// makeUDFOverload returns the overload used to resolve calls to the given
+// user-defined function. The overload is never evaluated: calls to it are
+// planned by the optimizer from the function body.
+func makeUDFOverload(fn catalog.FunctionDescriptor) (*tree.Overload, error) {
+	volatility, err := funcdesc.VolatilityFromProto(fn.GetVolatility(), fn.GetLeakProof())
+	if err != nil {
+		return nil, err
+	}
+	args := fn.GetArgs()
+	argTypes := make(tree.ArgTypes, len(args))
+	for i := range args {
+		argTypes[i].Name = args[i].Name
+		argTypes[i].Typ = args[i].Type
+	}
+	name := fn.GetName()
+	return &tree.Overload{
+		Types:      argTypes,
+		ReturnType: tree.FixedReturnType(fn.GetReturnType().Type),
+		Volatility: volatility,
+		Fn: func(*tree.EvalContext, tree.Datums) (tree.Datum, error) {
+			return nil, errors.AssertionFailedf(
+				"user-defined function %s cannot be evaluated outside of the optimizer", name)
+		},
+		Oid:               funcdesc.FuncIDToOID(fn.GetID()),
+		DistsqlBlocklist:  true,
+		IsUDF:             true,
+		Body:              fn.GetFunctionBody(),
+		CalledOnNullInput: fn.GetNullInputBehavior() == descpb.FunctionDescriptor_CALLED_ON_NULL_INPUT,
+		ReturnSet:         fn.GetReturnType().ReturnSet,
+	}, nil
+}
+
+// resolveMutableFunction resolves a function referenced by a statement like
+// DROP FUNCTION or ALTER FUNCTION. If the argument types are not specified, the
+// function name must be unique in the schema it is found in. If required is
+// false and no function matches, nil is returned.
+func (p *planner) resolveMutableFunction(
+	ctx context.Context, fnObj *tree.FuncObj, required bool,
+) (*funcdesc.Mutable, error) {
+	var argTypes []*types.T
+	if fnObj.Args != nil {
+		argTypes = make([]*types.T, len(fnObj.Args))
+		for i := range fnObj.Args {
+			typ, err := tree.ResolveType(ctx, fnObj.Args[i].Type, p.semaCtx.GetTypeResolver())
+			if err != nil {
+				return nil, err
+			}
+			argTypes[i] = typ
+		}
+	}
+
+	name := &fnObj.FuncName
+	var matches []descpb.ID
+	if err := p.forEachFunctionSchema(
+		ctx, name.Catalog(), name.Schema(), p.CurrentSearchPath(),
+		func(sc catalog.SchemaDescriptor) error {
+			if len(matches) > 0 {
+				// The function was found in a previous schema on the search path.
+				return nil
+			}
+			fn, ok := sc.GetFunction(name.Object())
+			if !ok {
+				return nil
+			}
+			for _, o := range fn.Overloads {
+				if argTypes == nil || overloadArgsMatch(o.ArgTypes, argTypes) {
+					matches = append(matches, o.ID)
+				}
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	switch len(matches) {
+	case 0:
+		if !required {
+			return nil, nil
+		}
+		return nil, sqlerrors.NewUndefinedFunctionError(fnObj)
+	case 1:
+		return p.Descriptors().GetMutableFunctionByID(
+			ctx, p.txn, matches[0], tree.ObjectLookupFlagsWithRequired(),
+		)
+	default:
+		return nil, errors.WithHint(
+			pgerror.Newf(pgcode.AmbiguousFunction, "function name %q is not unique", name.Object()),
+			"Specify the argument list to select the function unambiguously.",
+		)
+	}
+}
+
+// overloadArgsMatch returns true if the argument types of a function overload
+// are equivalent to the given types.
+func overloadArgsMatch(overloadTypes, argTypes []*types.T) bool {
+	if len(overloadTypes) != len(argTypes) {
+		return false
+	}
+	for i := range overloadTypes {
+		if !overloadTypes[i].Equivalent(argTypes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeFuncSchemaChange writes the function descriptor and queues a job which
+// waits for the new version of the descriptor to be leased, and deletes the
+// descriptor if the function was dropped.
+func (p *planner) writeFuncSchemaChange(
+	ctx context.Context, funcDesc *funcdesc.Mutable, jobDesc string,
+) error {
+	record, recordExists := p.extendedEvalCtx.SchemaChangeJobRecords[funcDesc.ID]
+	if recordExists {
+		// Update it.
+		record.AppendDescription(jobDesc)
+		log.Infof(ctx, "job %d: updated job's specification for change on function %d", record.JobID, funcDesc.ID)
+	} else {
+		// Or, create a new job.
+		jobRecord := jobs.Record{
+			JobID:         p.extendedEvalCtx.ExecCfg.JobRegistry.MakeJobID(),
+			Description:   jobDesc,
+			Username:      p.User(),
+			DescriptorIDs: descpb.IDs{funcDesc.ID},
+			Details: jobspb.SchemaChangeDetails{
+				DescID: funcDesc.ID,
+				// The version distinction for database jobs doesn't matter for
+				// function jobs.
+				FormatVersion: jobspb.DatabaseJobFormatVersion,
+			},
+			Progress:      jobspb.SchemaChangeProgress{},
+			NonCancelable: true,
+		}
+		p.extendedEvalCtx.SchemaChangeJobRecords[funcDesc.ID] = &jobRecord
+		log.Infof(ctx, "queued new schema change job %d for function %d", jobRecord.JobID, funcDesc.ID)
+	}
+	return p.writeFuncDesc(ctx, funcDesc)
+}
+
+func (p *planner) writeFuncDesc(ctx context.Context, funcDesc *funcdesc.Mutable) error {
+	b := p.txn.NewBatch()
+	if err := p.Descriptors().WriteDescToBatch(
+		ctx, p.extendedEvalCtx.Tracing.KVTracingEnabled(), funcDesc, b,
+	); err != nil {
+		return err
+	}
+	return p.txn.Run(ctx, b)
+}
+
+// getQualifiedFunctionName returns the database-qualified name of the
+// function represented by the provided descriptor.
+func (p *planner) getQualifiedFunctionName(
+	ctx context.Context, fnDesc catalog.FunctionDescriptor,
+) (*tree.FunctionName, error) {
+	_, dbDesc, err := p.Descriptors().GetImmutableDatabaseByID(ctx, p.txn, fnDesc.GetParentID(),
+		tree.DatabaseLookupFlags{
+			Required:       true,
+			IncludeOffline: true,
+			IncludeDropped: true,
+			AvoidLeased:    true,
+		})
+	if err != nil {
+		return nil, err
+	}
+	scDesc, err := p.Descriptors().GetImmutableSchemaByID(ctx, p.txn, fnDesc.GetParentSchemaID(),
+		tree.SchemaLookupFlags{
+			Required:       true,
+			IncludeOffline: true,
+			IncludeDropped: true,
+			AvoidLeased:    true,
+		})
+	if err != nil {
+		return nil, err
+	}
+	fnName := tree.MakeFunctionNameFromPrefix(tree.ObjectNamePrefix{
+		CatalogName:     tree.Name(dbDesc.GetName()),
+		SchemaName:      tree.Name(scDesc.GetName()),
+		ExplicitCatalog: true,
+		ExplicitSchema:  true,
+	}, tree.Name(fnDesc.GetName()))
+	return &fnName, nil
+}
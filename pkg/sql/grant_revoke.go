@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catprivilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -341,6 +342,12 @@ func (n *changePrivilegesNode) startExec(params runParams) error {
 						SchemaName:                     d.Name, // FIXME
 					}})
 			}
+		case *funcdesc.Mutable:
+			if err := p.writeFuncSchemaChange(
+				ctx, d, fmt.Sprintf("updating privileges for function %d", d.ID),
+			); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -378,6 +385,9 @@ func getGrantOnObject(targets tree.TargetList, incIAMFunc func(on string)) privi
 	case targets.Types != nil:
 		incIAMFunc(sqltelemetry.OnType)
 		return privilege.Type
+	case targets.Functions != nil:
+		incIAMFunc(sqltelemetry.OnFunction)
+		return privilege.Function
 	default:
 		incIAMFunc(sqltelemetry.OnTable)
 		return privilege.Table
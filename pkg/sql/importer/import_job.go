@@ -589,7 +589,8 @@ func prepareNewTablesForIngestion(
 	// imported data.
 	if err := ingesting.WriteDescriptors(ctx, p.ExecCfg().Codec, txn, p.User(), descsCol,
 		nil /* databases */, nil, /* schemas */
-		tableDescs, nil, tree.RequestedDescriptors, seqValKVs, "" /* inheritParentName */); err != nil {
+		tableDescs, nil /* types */, nil /* functions */, tree.RequestedDescriptors,
+		seqValKVs, "" /* inheritParentName */); err != nil {
 		return nil, errors.Wrapf(err, "creating importTables")
 	}
 
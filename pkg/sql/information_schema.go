@@ -474,7 +474,17 @@ https://www.postgresql.org/docs/9.5/infoschema-columns.html`,
 				// type is a user defined type, then we should fill this value based on
 				// the schema it is under.
 				udtSchema := pgCatalogNameDString
-				typeMetaName := column.GetType().TypeMeta.Name
+				udtType := column.GetType()
+				// Columns of a domain type report the domain in the domain_*
+				// columns, and its base type in the udt_* columns.
+				var domainCatalog, domainSchema, domainName tree.Datum = tree.DNull, tree.DNull, tree.DNull
+				if udtType.IsDomain() && udtType.TypeMeta.Name != nil {
+					domainCatalog = dbNameStr
+					domainSchema = tree.NewDString(udtType.TypeMeta.Name.Schema)
+					domainName = tree.NewDString(udtType.TypeMeta.Name.Name)
+					udtType = udtType.DomainBaseType()
+				}
+				typeMetaName := udtType.TypeMeta.Name
 				if typeMetaName != nil {
 					udtSchema = tree.NewDString(typeMetaName.Schema)
 				}
@@ -503,18 +513,18 @@ https://www.postgresql.org/docs/9.5/infoschema-columns.html`,
 					collationCatalog,                                          // collation_catalog
 					collationSchema,                                           // collation_schema
 					collationName,                                             // collation_name
-					tree.DNull,                                                // domain_catalog
-					tree.DNull,                                                // domain_schema
-					tree.DNull,                                                // domain_name
+					domainCatalog,                                             // domain_catalog
+					domainSchema,                                              // domain_schema
+					domainName,                                                // domain_name
 					dbNameStr,                                                 // udt_catalog
 					udtSchema,                                                 // udt_schema
-					tree.NewDString(column.GetType().PGName()), // udt_name
-					tree.DNull, // scope_catalog
-					tree.DNull, // scope_schema
-					tree.DNull, // scope_name
-					tree.DNull, // maximum_cardinality
-					tree.DNull, // dtd_identifier
-					tree.DNull, // is_self_referencing
+					tree.NewDString(udtType.PGName()),                         // udt_name
+					tree.DNull,                                                // scope_catalog
+					tree.DNull,                                                // scope_schema
+					tree.DNull,                                                // scope_name
+					tree.DNull,                                                // maximum_cardinality
+					tree.DNull,                                                // dtd_identifier
+					tree.DNull,                                                // is_self_referencing
 					yesOrNoDatum(column.IsGeneratedAsIdentity()), // is_identity
 					colGeneratedAsIdentity,                       // identity_generation
 					// TODO(janexing): parse the GeneratedAsIdentitySequenceOption to
@@ -735,6 +745,10 @@ https://www.postgresql.org/docs/9.5/infoschema-constraint-column-usage.html`,
 			dbNameStr := tree.NewDString(db.GetName())
 
 			for conName, con := range conInfo {
+				// Like Postgres, exclusion constraints are not listed.
+				if con.Kind == descpb.ConstraintTypeExclusion {
+					continue
+				}
 				conTable := table
 				conCols := con.Columns
 				conNameStr := tree.NewDString(conName)
@@ -1274,6 +1288,13 @@ https://www.postgresql.org/docs/9.5/infoschema-table-constraints.html`,
 				tbNameStr := tree.NewDString(table.GetName())
 
 				for conName, c := range conInfo {
+					// Like Postgres, exclusion constraints are not listed.
+					if c.Kind == descpb.ConstraintTypeExclusion {
+						continue
+					}
+					deferrability := c.Deferrability()
+					deferrable := deferrability != tree.NotDeferrableConstraint
+					initiallyDeferred := deferrability == tree.DeferrableInitiallyDeferred
 					if err := addRow(
 						dbNameStr,                       // constraint_catalog
 						scNameStr,                       // constraint_schema
@@ -1282,8 +1303,8 @@ https://www.postgresql.org/docs/9.5/infoschema-table-constraints.html`,
 						scNameStr,                       // table_schema
 						tbNameStr,                       // table_name
 						tree.NewDString(string(c.Kind)), // constraint_type
-						yesOrNoDatum(false),             // is_deferrable
-						yesOrNoDatum(false),             // initially_deferred
+						yesOrNoDatum(deferrable),        // is_deferrable
+						yesOrNoDatum(initiallyDeferred), // initially_deferred
 					); err != nil {
 						return err
 					}
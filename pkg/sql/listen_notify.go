@@ -0,0 +1,244 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+)
+
+const (
+	// maxChannelNameLength is the maximum length of a notification channel
+	// name. It matches the maximum identifier length in Postgres.
+	maxChannelNameLength = 63
+	// maxPayloadLength is the maximum length of a notification payload. It
+	// matches the limit in Postgres.
+	maxPayloadLength = 7999
+)
+
+// notifications gives the planner access to the LISTEN, UNLISTEN and NOTIFY
+// state of the current transaction. LISTEN and UNLISTEN only take effect when
+// the transaction commits.
+type notifications interface {
+	// listen records that the session starts listening on the given channel
+	// when the transaction commits.
+	listen(channel string) error
+	// unlisten records that the session stops listening on the given channel
+	// (or all channels, if all is set) when the transaction commits.
+	unlisten(channel string, all bool) error
+	// addNotification records that the given notification is sent by the
+	// transaction. It returns false if an identical notification was already
+	// sent, in which case the notification must not be sent again.
+	addNotification(n notify.Notification) bool
+	// listeningChannels returns the channels the session is listening on.
+	listeningChannels() []string
+}
+
+// listenOp is a LISTEN or UNLISTEN statement whose effect is delayed until
+// the end of the transaction.
+type listenOp struct {
+	channel string
+	listen  bool
+	all     bool
+}
+
+// notificationsState is the per-transaction state backing notifications. It
+// is stored in the connExecutor's extraTxnState.
+type notificationsState struct {
+	ops []listenOp
+	// sent contains the notifications sent by the transaction, along with the
+	// order in which they were sent.
+	sent map[notify.Notification]int
+}
+
+func (s *notificationsState) reset() {
+	*s = notificationsState{}
+}
+
+// rollbackTo discards the operations and notifications which were recorded
+// after the given savepoint was created.
+func (s *notificationsState) rollbackTo(sp *savepoint) {
+	if len(s.ops) > sp.numListenOps {
+		s.ops = s.ops[:sp.numListenOps]
+	}
+	for n, i := range s.sent {
+		if i >= sp.numNotifications {
+			delete(s.sent, n)
+		}
+	}
+}
+
+// apply applies the LISTEN and UNLISTEN operations of a committed transaction
+// to the listener.
+func (s *notificationsState) apply(l *notify.Listener) {
+	for _, op := range s.ops {
+		switch {
+		case op.all:
+			l.UnlistenAll()
+		case op.listen:
+			l.Listen(op.channel)
+		default:
+			l.Unlisten(op.channel)
+		}
+	}
+}
+
+type connExNotificationsAccessor struct {
+	ex *connExecutor
+}
+
+var _ notifications = connExNotificationsAccessor{}
+
+func (c connExNotificationsAccessor) listen(channel string) error {
+	s := &c.ex.extraTxnState.notifications
+	s.ops = append(s.ops, listenOp{channel: channel, listen: true})
+	return nil
+}
+
+func (c connExNotificationsAccessor) unlisten(channel string, all bool) error {
+	s := &c.ex.extraTxnState.notifications
+	s.ops = append(s.ops, listenOp{channel: channel, all: all})
+	return nil
+}
+
+func (c connExNotificationsAccessor) addNotification(n notify.Notification) bool {
+	s := &c.ex.extraTxnState.notifications
+	if _, ok := s.sent[n]; ok {
+		return false
+	}
+	if s.sent == nil {
+		s.sent = make(map[notify.Notification]int)
+	}
+	s.sent[n] = len(s.sent)
+	return true
+}
+
+func (c connExNotificationsAccessor) listeningChannels() []string {
+	if c.ex.listener == nil {
+		return nil
+	}
+	return c.ex.listener.Channels()
+}
+
+// emptyNotifications is the default impl used by the planner when the
+// connExecutor is not available, or when notifications cannot be delivered
+// to the session. Notifications can still be sent.
+type emptyNotifications struct{}
+
+var _ notifications = emptyNotifications{}
+
+func (emptyNotifications) listen(string) error {
+	return pgerror.New(pgcode.FeatureNotSupported, "LISTEN is not supported in this context")
+}
+
+func (emptyNotifications) unlisten(string, bool) error {
+	return nil
+}
+
+func (emptyNotifications) addNotification(notify.Notification) bool {
+	return true
+}
+
+func (emptyNotifications) listeningChannels() []string {
+	return nil
+}
+
+// Listen implements the LISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-listen.html for details.
+func (p *planner) Listen(ctx context.Context, n *tree.Listen) (planNode, error) {
+	channel := string(n.ChannelName)
+	if err := checkChannelName(channel); err != nil {
+		return nil, err
+	}
+	return &delayedNode{
+		name: n.String(),
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			return newZeroNode(nil /* columns */), p.notifications.listen(channel)
+		},
+	}, nil
+}
+
+// Unlisten implements the UNLISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-unlisten.html for details.
+func (p *planner) Unlisten(ctx context.Context, n *tree.Unlisten) (planNode, error) {
+	return &delayedNode{
+		name: n.String(),
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			return newZeroNode(nil /* columns */), p.notifications.unlisten(string(n.ChannelName), n.All)
+		},
+	}, nil
+}
+
+// NotifyStmt implements the NOTIFY statement.
+// See https://www.postgresql.org/docs/current/sql-notify.html for details.
+func (p *planner) NotifyStmt(ctx context.Context, n *tree.Notify) (planNode, error) {
+	var payload string
+	if n.Payload != nil {
+		payload = n.Payload.RawString()
+	}
+	return &delayedNode{
+		name: n.String(),
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			return newZeroNode(nil /* columns */), p.Notify(ctx, string(n.ChannelName), payload)
+		},
+	}, nil
+}
+
+// Notify is part of the tree.EvalPlanner interface.
+func (p *planner) Notify(ctx context.Context, channel, payload string) error {
+	if err := checkChannelName(channel); err != nil {
+		return err
+	}
+	if len(payload) > maxPayloadLength {
+		return pgerror.New(pgcode.InvalidParameterValue, "payload string too long")
+	}
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.NotificationsTable) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"NOTIFY is not supported until upgrade to version %s is finalized",
+			clusterversion.NotificationsTable.String())
+	}
+	pid := p.ExtendedEvalContext().QueryCancelKey.GetPGBackendPID()
+	n := notify.Notification{Channel: channel, Payload: payload, PID: pid}
+	if !p.notifications.addNotification(n) {
+		// Like Postgres, identical notifications sent by the same transaction
+		// are only delivered once.
+		return nil
+	}
+	_, err := p.ExecCfg().InternalExecutor.ExecEx(
+		ctx, "notify", p.Txn(),
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`INSERT INTO system.notifications (channel, payload, pid) VALUES ($1, $2, $3)`,
+		channel, payload, int64(pid),
+	)
+	return err
+}
+
+// ListeningChannels is part of the tree.EvalPlanner interface.
+func (p *planner) ListeningChannels() []string {
+	return p.notifications.listeningChannels()
+}
+
+func checkChannelName(channel string) error {
+	if channel == "" {
+		return pgerror.New(pgcode.InvalidParameterValue, "channel name cannot be empty")
+	}
+	if len(channel) > maxChannelNameLength {
+		return pgerror.New(pgcode.InvalidParameterValue, "channel name too long")
+	}
+	return nil
+}
@@ -236,6 +236,10 @@ import (
 //    that depend on the state of the statement should be run with the "retry"
 //    option to ensure deterministic test results.
 //
+//  - statement async <name> error <regexp>
+//    Like "statement async <name>", but expects the statement to fail with an
+//    error that matches the given regexp once it is completed.
+//
 //  - awaitstatement <name>
 //    Completes a pending statement with the provided name, validating its
 //    results as expected per the given options to "statement async <name>...".
@@ -2893,6 +2897,11 @@ func (t *logicTest) processSubtest(
 				stmt.statementName = fields[2]
 				copy(fields[1:], fields[3:])
 				fields = fields[:len(fields)-2]
+				// Parse "statement async <name> error <regexp>".
+				if m := errorRE.FindStringSubmatch(strings.Join(fields, " ")); m != nil {
+					stmt.expectErrCode = m[1]
+					stmt.expectErr = m[2]
+				}
 			}
 			if len(fields) >= 3 && fields[1] == "count" {
 				n, err := strconv.ParseInt(fields[2], 10, 64)
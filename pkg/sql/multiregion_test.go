@@ -0,0 +1,87 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// TestMultiRegionDatabaseWithLocalityTaggedNodes sets up a cluster whose nodes
+// are tagged with one region each and checks that multi-region databases and
+// tables can be created and altered on it.
+func TestMultiRegionDatabaseWithLocalityTaggedNodes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	regions := []string{"rack-a", "rack-b", "rack-c"}
+	serverArgs := make(map[int]base.TestServerArgs)
+	for i, region := range regions {
+		serverArgs[i] = base.TestServerArgs{
+			Locality: roachpb.Locality{Tiers: []roachpb.Tier{{Key: "region", Value: region}}},
+		}
+	}
+	tc := testcluster.StartTestCluster(t, len(regions), base.TestClusterArgs{
+		ServerArgsPerNode: serverArgs,
+	})
+	defer tc.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	sqlDB.CheckQueryResults(t,
+		`SELECT region FROM [SHOW REGIONS FROM CLUSTER] ORDER BY region`,
+		[][]string{{"rack-a"}, {"rack-b"}, {"rack-c"}},
+	)
+
+	sqlDB.Exec(t, `CREATE DATABASE db PRIMARY REGION "rack-b" REGIONS "rack-c"`)
+	sqlDB.Exec(t, `ALTER DATABASE db ADD REGION "rack-a"`)
+	sqlDB.CheckQueryResults(t,
+		`SELECT region, "primary" FROM [SHOW REGIONS FROM DATABASE db] ORDER BY region`,
+		[][]string{{"rack-a", "false"}, {"rack-b", "true"}, {"rack-c", "false"}},
+	)
+	sqlDB.CheckQueryResults(t,
+		`SELECT values FROM [SHOW ENUMS FROM db.public]`,
+		[][]string{{"{rack-a,rack-b,rack-c}"}},
+	)
+
+	sqlDB.Exec(t, `ALTER DATABASE db SURVIVE REGION FAILURE`)
+	sqlDB.CheckQueryResults(t,
+		`SELECT survival_goal FROM [SHOW DATABASES] WHERE database_name = 'db'`,
+		[][]string{{"region"}},
+	)
+
+	sqlDB.Exec(t, `CREATE TABLE db.rbr (k INT PRIMARY KEY) LOCALITY REGIONAL BY ROW`)
+	sqlDB.Exec(t, `INSERT INTO db.rbr (crdb_region, k) VALUES ('rack-a', 1), ('rack-c', 2)`)
+	sqlDB.CheckQueryResults(t,
+		`SELECT crdb_region, k FROM db.rbr ORDER BY k`,
+		[][]string{{"rack-a", "1"}, {"rack-c", "2"}},
+	)
+	sqlDB.CheckQueryResults(t,
+		`SELECT partition_name FROM [SHOW PARTITIONS FROM TABLE db.rbr] ORDER BY 1`,
+		[][]string{{"rack-a"}, {"rack-b"}, {"rack-c"}},
+	)
+
+	sqlDB.Exec(t, `CREATE TABLE db.global (k INT PRIMARY KEY) LOCALITY GLOBAL`)
+	sqlDB.CheckQueryResults(t,
+		`SELECT locality FROM [SHOW TABLES FROM db] WHERE table_name = 'global'`,
+		[][]string{{"GLOBAL"}},
+	)
+
+	sqlDB.ExpectErr(t, `region "rack-d" does not exist`,
+		`CREATE DATABASE bad PRIMARY REGION "rack-d"`)
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package notify
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// maxQueuedNotifications is the maximum number of notifications which can be
+// waiting to be delivered to a session. Further notifications are dropped
+// until the session consumes its queue.
+const maxQueuedNotifications = 10000
+
+// Notification is an asynchronous notification sent with NOTIFY or
+// pg_notify().
+type Notification struct {
+	// Channel is the name of the channel the notification was sent on.
+	Channel string
+	// Payload is the (possibly empty) payload of the notification.
+	Payload string
+	// PID is the backend process ID of the session which sent the
+	// notification.
+	PID uint32
+}
+
+// Listener receives the notifications sent on the channels a session is
+// listening on. The channels are changed by the session when its LISTEN and
+// UNLISTEN statements commit. Notifications are queued by the Registry until
+// the session takes them with Drain.
+type Listener struct {
+	registry *Registry
+	// wake is called when the queue of notifications becomes non-empty.
+	wake func()
+
+	mu struct {
+		syncutil.Mutex
+		channels map[string]struct{}
+		queue    []Notification
+		// dropped is the number of notifications which were dropped since the
+		// last Drain because the queue was full.
+		dropped int
+	}
+}
+
+// Listen adds the given channel to the channels the listener is listening on.
+func (l *Listener) Listen(channel string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.channels[channel] = struct{}{}
+}
+
+// Unlisten removes the given channel from the channels the listener is
+// listening on.
+func (l *Listener) Unlisten(channel string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.mu.channels, channel)
+}
+
+// UnlistenAll stops listening on all channels.
+func (l *Listener) UnlistenAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for channel := range l.mu.channels {
+		delete(l.mu.channels, channel)
+	}
+}
+
+// Channels returns the channels the listener is listening on, in no
+// particular order.
+func (l *Listener) Channels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	channels := make([]string, 0, len(l.mu.channels))
+	for channel := range l.mu.channels {
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// Drain returns the queued notifications, in the order in which they were
+// received, and empties the queue.
+func (l *Listener) Drain(ctx context.Context) []Notification {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.dropped > 0 {
+		log.Warningf(ctx, "dropped %d notifications because the queue of the session was full",
+			l.mu.dropped)
+		l.mu.dropped = 0
+	}
+	queue := l.mu.queue
+	l.mu.queue = nil
+	return queue
+}
+
+// Close unregisters the listener. No more notifications are queued after
+// Close returns.
+func (l *Listener) Close() {
+	l.registry.removeListener(l)
+}
+
+// maybeEnqueue queues the notification if the listener is listening on its
+// channel.
+func (l *Listener) maybeEnqueue(n Notification) {
+	l.mu.Lock()
+	if _, ok := l.mu.channels[n.Channel]; !ok {
+		l.mu.Unlock()
+		return
+	}
+	if len(l.mu.queue) >= maxQueuedNotifications {
+		l.mu.dropped++
+		l.mu.Unlock()
+		return
+	}
+	l.mu.queue = append(l.mu.queue, n)
+	wasEmpty := len(l.mu.queue) == 1
+	l.mu.Unlock()
+	if wasEmpty && l.wake != nil {
+		l.wake()
+	}
+}
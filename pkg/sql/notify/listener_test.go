@@ -0,0 +1,94 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package notify
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	r := NewRegistry(keys.SystemSQLCodec, nil /* db */, nil /* ie */, nil /* st */, nil /* rangeFeedFactory */)
+
+	var wakeA, wakeB int
+	a := r.NewListener(func() { wakeA++ })
+	b := r.NewListener(func() { wakeB++ })
+	a.Listen("foo")
+	a.Listen("bar")
+	b.Listen("bar")
+
+	sorted := func(s []string) []string {
+		sort.Strings(s)
+		return s
+	}
+	require.Equal(t, []string{"bar", "foo"}, sorted(a.Channels()))
+	require.Equal(t, []string{"bar"}, b.Channels())
+
+	foo := Notification{Channel: "foo", Payload: "1", PID: 10}
+	bar := Notification{Channel: "bar", Payload: "2", PID: 20}
+	baz := Notification{Channel: "baz", PID: 30}
+	r.deliver(1, foo)
+	r.deliver(2, bar)
+	r.deliver(3, baz)
+	// Duplicates emitted by the rangefeed are ignored.
+	r.deliver(1, foo)
+
+	require.Equal(t, 1, wakeA)
+	require.Equal(t, 1, wakeB)
+	require.Equal(t, []Notification{foo, bar}, a.Drain(ctx))
+	require.Equal(t, []Notification{bar}, b.Drain(ctx))
+	require.Empty(t, a.Drain(ctx))
+
+	// The listener is woken up again once its queue becomes non-empty.
+	a.Unlisten("bar")
+	r.deliver(4, bar)
+	r.deliver(5, foo)
+	require.Equal(t, 2, wakeA)
+	require.Equal(t, 2, wakeB)
+	require.Equal(t, []Notification{foo}, a.Drain(ctx))
+
+	// Closed listeners no longer receive notifications.
+	b.Close()
+	a.UnlistenAll()
+	require.Empty(t, a.Channels())
+	b.Drain(ctx)
+	r.deliver(6, bar)
+	r.deliver(7, foo)
+	require.Empty(t, a.Drain(ctx))
+	require.Empty(t, b.Drain(ctx))
+	require.Equal(t, 2, wakeB)
+}
+
+func TestListenerQueueLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	r := NewRegistry(keys.SystemSQLCodec, nil /* db */, nil /* ie */, nil /* st */, nil /* rangeFeedFactory */)
+	l := r.NewListener(nil /* wake */)
+	l.Listen("foo")
+	for i := 0; i < maxQueuedNotifications+10; i++ {
+		r.deliver(int64(i), Notification{Channel: "foo"})
+	}
+	require.Len(t, l.Drain(ctx), maxQueuedNotifications)
+	r.deliver(-1, Notification{Channel: "foo"})
+	require.Len(t, l.Drain(ctx), 1)
+}
@@ -0,0 +1,259 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package notify implements the delivery of the asynchronous notifications
+// sent with NOTIFY to the sessions which executed LISTEN.
+//
+// NOTIFY inserts a row into system.notifications as part of the sending
+// transaction, so a notification is only visible once that transaction
+// commits. Every node runs a Registry, which watches the table with a
+// rangefeed and hands the new rows to the Listeners of its sessions. Rows
+// are deleted once they are older than sql.notifications.retention.
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/valueside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// Retention is the amount of time for which the rows of
+// system.notifications are kept after being inserted.
+var Retention = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.notifications.retention",
+	"amount of time after which sent notifications are deleted from system.notifications",
+	time.Minute,
+	settings.PositiveDuration,
+)
+
+// cleanupInterval is the interval at which the Registry deletes the expired
+// rows of system.notifications.
+const cleanupInterval = 30 * time.Second
+
+// Registry delivers the notifications inserted into system.notifications to
+// the Listeners of the sessions on the local node.
+type Registry struct {
+	codec            keys.SQLCodec
+	db               *kv.DB
+	ie               sqlutil.InternalExecutor
+	st               *cluster.Settings
+	rangeFeedFactory *rangefeed.Factory
+
+	// The following fields are only used by the rangefeed handler, which is
+	// never called concurrently.
+	alloc   tree.DatumAlloc
+	columns []catalog.Column
+	decoder valueside.Decoder
+
+	mu struct {
+		syncutil.Mutex
+		listeners map[*Listener]struct{}
+		// seen contains the IDs of the notifications which have been
+		// delivered, along with the time at which they were received. It is
+		// used to ignore the values which the rangefeed emits more than once.
+		seen map[int64]time.Time
+	}
+}
+
+// NewRegistry creates a Registry. Start needs to be called before
+// notifications are delivered.
+func NewRegistry(
+	codec keys.SQLCodec,
+	db *kv.DB,
+	ie sqlutil.InternalExecutor,
+	st *cluster.Settings,
+	rangeFeedFactory *rangefeed.Factory,
+) *Registry {
+	columns := systemschema.NotificationsTable.PublicColumns()
+	r := &Registry{
+		codec:            codec,
+		db:               db,
+		ie:               ie,
+		st:               st,
+		rangeFeedFactory: rangeFeedFactory,
+		columns:          columns,
+		decoder:          valueside.MakeDecoder(columns),
+	}
+	r.mu.listeners = make(map[*Listener]struct{})
+	r.mu.seen = make(map[int64]time.Time)
+	return r
+}
+
+// Start starts watching system.notifications and the periodic deletion of
+// its expired rows.
+func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) error {
+	tablePrefix := r.codec.TablePrefix(keys.NotificationsTableID)
+	tableSpan := roachpb.Span{
+		Key:    tablePrefix,
+		EndKey: tablePrefix.PrefixEnd(),
+	}
+	// The rangefeed stops on server shutdown, we don't need to close it
+	// ourselves.
+	if _, err := r.rangeFeedFactory.RangeFeed(
+		ctx,
+		"notifications",
+		[]roachpb.Span{tableSpan},
+		r.db.Clock().Now(),
+		r.handleEvent,
+	); err != nil {
+		return err
+	}
+	return stopper.RunAsyncTask(ctx, "notifications-cleanup", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(cleanupInterval)
+			select {
+			case <-timer.C:
+				timer.Read = true
+				if err := r.deleteExpired(ctx); err != nil && ctx.Err() == nil {
+					log.Warningf(ctx, "failed to delete expired notifications: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// NewListener registers a new Listener which does not listen on any channel
+// yet. wake is called whenever the queue of notifications of the listener
+// becomes non-empty; it must not block.
+func (r *Registry) NewListener(wake func()) *Listener {
+	l := &Listener{registry: r, wake: wake}
+	l.mu.channels = make(map[string]struct{})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.listeners[l] = struct{}{}
+	return l
+}
+
+func (r *Registry) removeListener(l *Listener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.mu.listeners, l)
+}
+
+// deliver hands the notification with the given ID to the listeners of the
+// channel, unless it has already been delivered.
+func (r *Registry) deliver(id int64, n Notification) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.mu.seen[id]; ok {
+		return
+	}
+	r.mu.seen[id] = timeutil.Now()
+	for l := range r.mu.listeners {
+		l.maybeEnqueue(n)
+	}
+}
+
+func (r *Registry) handleEvent(ctx context.Context, kv *roachpb.RangeFeedValue) {
+	if !kv.Value.IsPresent() {
+		// The row was deleted.
+		return
+	}
+	id, n, err := r.decodeRow(kv)
+	if err != nil {
+		log.Warningf(ctx, "failed to decode notification %v: %v", kv.Key, err)
+		return
+	}
+	r.deliver(id, n)
+}
+
+// decodeRow decodes a row of system.notifications.
+func (r *Registry) decodeRow(kv *roachpb.RangeFeedValue) (int64, Notification, error) {
+	var id int64
+	{
+		types := []*types.T{r.columns[0].GetType()}
+		idRow := make([]rowenc.EncDatum, 1)
+		if _, _, err := rowenc.DecodeIndexKey(r.codec, types, idRow, nil, kv.Key); err != nil {
+			return 0, Notification{}, errors.Wrap(err, "failed to decode key")
+		}
+		if err := idRow[0].EnsureDecoded(types[0], &r.alloc); err != nil {
+			return 0, Notification{}, err
+		}
+		id = int64(tree.MustBeDInt(idRow[0].Datum))
+	}
+
+	// The rest of the columns are stored as a family.
+	bytes, err := kv.Value.GetTuple()
+	if err != nil {
+		return 0, Notification{}, err
+	}
+	datums, err := r.decoder.Decode(&r.alloc, bytes)
+	if err != nil {
+		return 0, Notification{}, err
+	}
+	for _, d := range datums[1:4] {
+		if d == tree.DNull {
+			return 0, Notification{}, errors.AssertionFailedf("unexpected NULL in notification")
+		}
+	}
+	return id, Notification{
+		Channel: string(tree.MustBeDString(datums[1])),
+		Payload: string(tree.MustBeDString(datums[2])),
+		PID:     uint32(tree.MustBeDInt(datums[3])),
+	}, nil
+}
+
+// deleteExpired deletes the rows of system.notifications which are older than
+// the retention, and forgets about the notifications which can no longer be
+// emitted by the rangefeed.
+func (r *Registry) deleteExpired(ctx context.Context) error {
+	if !r.st.Version.IsActive(ctx, clusterversion.NotificationsTable) {
+		// The table does not exist yet.
+		return nil
+	}
+	retention := Retention.Get(&r.st.SV)
+	_, err := r.ie.ExecEx(
+		ctx, "delete-expired-notifications", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`DELETE FROM system.notifications WHERE created < now() - $1`,
+		tree.NewDInterval(duration.MakeDuration(retention.Nanoseconds(), 0, 0), types.DefaultIntervalTypeMetadata),
+	)
+	if err != nil {
+		return err
+	}
+	cutoff := timeutil.Now().Add(-2 * retention)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, t := range r.mu.seen {
+		if t.Before(cutoff) {
+			delete(r.mu.seen, id)
+		}
+	}
+	return nil
+}
@@ -103,6 +103,14 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.AlterDatabaseAlterSuperRegion(ctx, n)
 	case *tree.AlterDefaultPrivileges:
 		return p.alterDefaultPrivileges(ctx, n)
+	case *tree.AlterFunctionOptions:
+		return p.AlterFunctionOptions(ctx, n)
+	case *tree.AlterFunctionRename:
+		return p.AlterFunctionRename(ctx, n)
+	case *tree.AlterFunctionSetOwner:
+		return p.AlterFunctionSetOwner(ctx, n)
+	case *tree.AlterFunctionSetSchema:
+		return p.AlterFunctionSetSchema(ctx, n)
 	case *tree.AlterIndex:
 		return p.AlterIndex(ctx, n)
 	case *tree.AlterSchema:
@@ -133,6 +141,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.CommentOnConstraint(ctx, n)
 	case *tree.CommentOnDatabase:
 		return p.CommentOnDatabase(ctx, n)
+	case *tree.CommentOnFunction:
+		return p.CommentOnFunction(ctx, n)
 	case *tree.CommentOnSchema:
 		return p.CommentOnSchema(ctx, n)
 	case *tree.CommentOnIndex:
@@ -151,6 +161,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.CreateRole(ctx, n)
 	case *tree.CreateSequence:
 		return p.CreateSequence(ctx, n)
+	case *tree.CreateTrigger:
+		return p.CreateTrigger(ctx, n)
 	case *tree.CreateExtension:
 		return p.CreateExtension(ctx, n)
 	case *tree.Deallocate:
@@ -161,6 +173,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.Discard(ctx, n)
 	case *tree.DropDatabase:
 		return p.DropDatabase(ctx, n)
+	case *tree.DropFunction:
+		return p.DropFunction(ctx, n)
 	case *tree.DropIndex:
 		return p.DropIndex(ctx, n)
 	case *tree.DropOwnedBy:
@@ -173,6 +187,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.DropSequence(ctx, n)
 	case *tree.DropTable:
 		return p.DropTable(ctx, n)
+	case *tree.DropTrigger:
+		return p.DropTrigger(ctx, n)
 	case *tree.DropType:
 		return p.DropType(ctx, n)
 	case *tree.DropView:
@@ -183,8 +199,12 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.Grant(ctx, n)
 	case *tree.GrantRole:
 		return p.GrantRole(ctx, n)
+	case *tree.Listen:
+		return p.Listen(ctx, n)
 	case *tree.MoveCursor:
 		return p.FetchCursor(ctx, &n.CursorStmt, true /* isMove */)
+	case *tree.Notify:
+		return p.NotifyStmt(ctx, n)
 	case *tree.ReassignOwnedBy:
 		return p.ReassignOwnedBy(ctx, n)
 	case *tree.RefreshMaterializedView:
@@ -209,6 +229,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.Scrub(ctx, n)
 	case *tree.SetClusterSetting:
 		return p.SetClusterSetting(ctx, n)
+	case *tree.SetConstraints:
+		return p.SetConstraints(ctx, n)
 	case *tree.SetZoneConfig:
 		return p.SetZoneConfig(ctx, n)
 	case *tree.SetVar:
@@ -239,6 +261,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.ShowFingerprints(ctx, n)
 	case *tree.Truncate:
 		return p.Truncate(ctx, n)
+	case *tree.Unlisten:
+		return p.Unlisten(ctx, n)
 	case tree.CCLOnlyStatement:
 		plan, err := p.maybePlanHook(ctx, stmt)
 		if plan == nil && err == nil {
@@ -264,6 +288,10 @@ func init() {
 		&tree.AlterDatabaseDropSuperRegion{},
 		&tree.AlterDatabaseAlterSuperRegion{},
 		&tree.AlterDefaultPrivileges{},
+		&tree.AlterFunctionOptions{},
+		&tree.AlterFunctionRename{},
+		&tree.AlterFunctionSetOwner{},
+		&tree.AlterFunctionSetSchema{},
 		&tree.AlterIndex{},
 		&tree.AlterSchema{},
 		&tree.AlterTable{},
@@ -278,6 +306,7 @@ func init() {
 		&tree.CloseCursor{},
 		&tree.CommentOnColumn{},
 		&tree.CommentOnDatabase{},
+		&tree.CommentOnFunction{},
 		&tree.CommentOnSchema{},
 		&tree.CommentOnIndex{},
 		&tree.CommentOnConstraint{},
@@ -287,24 +316,29 @@ func init() {
 		&tree.CreateIndex{},
 		&tree.CreateSchema{},
 		&tree.CreateSequence{},
+		&tree.CreateTrigger{},
 		&tree.CreateType{},
 		&tree.CreateRole{},
 		&tree.Deallocate{},
 		&tree.DeclareCursor{},
 		&tree.Discard{},
 		&tree.DropDatabase{},
+		&tree.DropFunction{},
 		&tree.DropIndex{},
 		&tree.DropOwnedBy{},
 		&tree.DropRole{},
 		&tree.DropSchema{},
 		&tree.DropSequence{},
 		&tree.DropTable{},
+		&tree.DropTrigger{},
 		&tree.DropType{},
 		&tree.DropView{},
 		&tree.FetchCursor{},
 		&tree.Grant{},
 		&tree.GrantRole{},
+		&tree.Listen{},
 		&tree.MoveCursor{},
+		&tree.Notify{},
 		&tree.ReassignOwnedBy{},
 		&tree.RefreshMaterializedView{},
 		&tree.RenameColumn{},
@@ -317,6 +351,7 @@ func init() {
 		&tree.Scatter{},
 		&tree.Scrub{},
 		&tree.SetClusterSetting{},
+		&tree.SetConstraints{},
 		&tree.SetZoneConfig{},
 		&tree.SetVar{},
 		&tree.SetTransaction{},
@@ -332,6 +367,7 @@ func init() {
 		&tree.ShowFingerprints{},
 		&tree.ShowVar{},
 		&tree.Truncate{},
+		&tree.Unlisten{},
 
 		// CCL statements (without Export which has an optimizer operator).
 		&tree.AlterBackup{},
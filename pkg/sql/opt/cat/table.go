@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/lib/pq/oid"
 )
 
 // Table is an interface to a database table, exposing only the information
@@ -143,6 +145,21 @@ type Table interface {
 	// IsPartitionAllBy returns true if this is a PARTITION ALL BY table. This
 	// includes REGIONAL BY ROW tables.
 	IsPartitionAllBy() bool
+
+	// TriggerCount returns the number of row-level triggers defined on the
+	// table.
+	TriggerCount() int
+
+	// Trigger returns the ith trigger, where i < TriggerCount.
+	Trigger(i int) Trigger
+
+	// ExclusionConstraintCount returns the number of EXCLUDE constraints
+	// defined on the table.
+	ExclusionConstraintCount() int
+
+	// ExclusionConstraint returns the ith EXCLUDE constraint, where
+	// i < ExclusionConstraintCount.
+	ExclusionConstraint(i int) ExclusionConstraint
 }
 
 // CheckConstraint contains the SQL text and the validity status for a check
@@ -157,6 +174,44 @@ type CheckConstraint struct {
 	Validated  bool
 }
 
+// Trigger describes a row-level trigger on a table, which executes a
+// user-defined function for each row modified by the statements listed in
+// Events. For example:
+//
+//   CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW EXECUTE FUNCTION f(NEW.x)
+//
+// The arguments of the function and the When condition are SQL expressions
+// which can refer to the columns of the modified row as OLD.<col> and
+// NEW.<col>.
+type Trigger struct {
+	Name        string
+	ActionTime  tree.TriggerActionTime
+	Events      tree.TriggerEvents
+	FunctionOID oid.Oid
+	Args        []string
+	When        string
+}
+
+// ExclusionConstraint describes an EXCLUDE constraint on a table, which
+// guarantees that no two rows match each other on all of its columns, as
+// compared by the operator of each column. For example, this constraint
+// ensures that no two reservations of the same sled have overlapping slots:
+//
+//   CREATE TABLE r (sled INT, slots INT[], EXCLUDE (sled WITH =, slots WITH &&))
+//
+// Rows for which a comparison returns NULL, or which do not satisfy the
+// Predicate (if any), never conflict.
+type ExclusionConstraint struct {
+	Name string
+	// ColumnOrdinals are the table ordinals of the columns of the constraint,
+	// which are compared using the corresponding Operators.
+	ColumnOrdinals []int
+	Operators      []treecmp.ComparisonOperator
+	// Predicate is the SQL text of the WHERE clause, or empty if there is none.
+	Predicate string
+	Validated bool
+}
+
 // TableStatistic is an interface to a table statistic. Each statistic is
 // associated with a set of columns.
 type TableStatistic interface {
@@ -260,6 +315,12 @@ type ForeignKeyConstraint interface {
 	// UpdateReferenceAction returns the action to be performed if the foreign key
 	// constraint would be violated by an update.
 	UpdateReferenceAction() tree.ReferenceAction
+
+	// Deferrability returns whether the checks for the foreign key constraint
+	// can be deferred until the end of the transaction, and whether they are
+	// deferred by default. The optimizer does not rely on a deferrable
+	// constraint holding, since it can be violated within a transaction.
+	Deferrability() tree.ConstraintDeferrability
 }
 
 // UniqueConstraint represents a uniqueness constraint. UniqueConstraints may
@@ -299,6 +360,11 @@ type UniqueConstraint interface {
 	// needs to be enforced on new mutations.
 	Validated() bool
 
+	// Deferrability returns whether the checks for the unique constraint can be
+	// deferred until the end of the transaction, and whether they are deferred
+	// by default. Only unique constraints without an index can be deferrable.
+	Deferrability() tree.ConstraintDeferrability
+
 	// UniquenessGuaranteedByAnotherIndex returns true when WithoutIndex() returns
 	// true and the uniqueness of the constraint is guaranteed by another index.
 	// When true, the optimizer will always consider the constraint to be
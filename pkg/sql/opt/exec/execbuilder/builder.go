@@ -54,7 +54,7 @@ type Builder struct {
 
 	// checks accumulates check queries that are run after the main query and
 	// any cascades.
-	checks []exec.Node
+	checks []exec.Check
 
 	// nameGen is used to generate names for the tables that will be created for
 	// each relational subexpression when evalCtx.SessionData.SaveTablesPrefix is
@@ -84,6 +84,12 @@ type Builder struct {
 	// by scans. See forUpdateLocking.
 	forceForUpdateLocking bool
 
+	// lockMutationInput is set when building the plan of a FK cascade in a
+	// transaction with an isolation level weaker than serializable. It ensures
+	// that a FOR UPDATE row-level locking mode is used by the initial row scan
+	// of the cascade. See shouldApplyImplicitLockingToMutationInput.
+	lockMutationInput bool
+
 	// -- output --
 
 	// IsDDL is set to true if the statement contains DDL.
@@ -294,6 +294,7 @@ func (cb *cascadeBuilder) planCascade(
 
 	// 5. Execbuild the optimized expression.
 	eb := New(execFactory, &o, factory.Memo(), cb.b.catalog, optimizedExpr, evalCtx, allowAutoCommit)
+	eb.lockMutationInput = eb.weakIsolation()
 	if bufferRef != nil {
 		// Set up the With binding.
 		eb.addBuiltWithExpr(cascadeInputWithID, bufferColMap, bufferRef)
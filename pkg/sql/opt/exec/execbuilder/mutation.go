@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
 )
 
@@ -156,10 +157,18 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 		return execPlan{}, false, nil
 	}
 
+	// The fast path performs the FK checks with lookups which do not lock the
+	// referenced rows, so it cannot be used under isolation levels weaker than
+	// serializable (see buildCheckQuery).
+	if len(ins.FKChecks) > 0 && b.weakIsolation() {
+		return execPlan{}, false, nil
+	}
+
 	md := b.mem.Metadata()
 	tab := md.Table(ins.Table)
 
 	//  - there are no self-referencing foreign keys;
+	//  - there are no deferrable foreign keys;
 	//  - all FK checks can be performed using direct lookups into unique indexes.
 	fkChecks := make([]exec.InsertFastPathFKCheck, len(ins.FKChecks))
 	for i := range ins.FKChecks {
@@ -168,6 +177,11 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 			// Self-referencing FK.
 			return execPlan{}, false, nil
 		}
+		if c.Deferrable {
+			// The fast path cannot defer the check until the end of the
+			// transaction.
+			return execPlan{}, false, nil
+		}
 		fk := tab.OutboundForeignKey(c.FKOrdinal)
 		lookupJoin, isLookupJoin := c.Check.(*memo.LookupJoinExpr)
 		if !isLookupJoin || lookupJoin.JoinType != opt.AntiJoinOp {
@@ -490,8 +504,15 @@ func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
 	//
 	// TODO(andyk): Using ensureColumns here can result in an extra Render.
 	// Upgrade execution engine to not require this.
-	colList := make(opt.ColList, 0, len(del.FetchCols)+len(del.PartialIndexDelCols))
+	colList := make(opt.ColList, 0, len(del.FetchCols)+len(del.PassthroughCols)+len(del.PartialIndexDelCols))
 	colList = appendColsWhenPresent(colList, del.FetchCols)
+	// The RETURNING clause of the Delete can refer to the columns
+	// in any of the USING tables. As a result, the Delete may need
+	// to passthrough those columns so the projection above can use
+	// them.
+	if del.NeedResults() {
+		colList = append(colList, del.PassthroughCols...)
+	}
 	colList = appendColsWhenPresent(colList, del.PartialIndexDelCols)
 
 	input, err := b.buildMutationInput(del, del.Input, colList, &del.MutationPrivate)
@@ -504,11 +525,22 @@ func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
 	tab := md.Table(del.Table)
 	fetchColOrds := ordinalSetFromColList(del.FetchCols)
 	returnColOrds := ordinalSetFromColList(del.ReturnCols)
+
+	// Construct the result columns for the passthrough set.
+	var passthroughCols colinfo.ResultColumns
+	if del.NeedResults() {
+		for _, passthroughCol := range del.PassthroughCols {
+			colMeta := b.mem.Metadata().ColumnMeta(passthroughCol)
+			passthroughCols = append(passthroughCols, colinfo.ResultColumn{Name: colMeta.Alias, Typ: colMeta.Type})
+		}
+	}
+
 	node, err := b.factory.ConstructDelete(
 		input.root,
 		tab,
 		fetchColOrds,
 		returnColOrds,
+		passthroughCols,
 		b.allowAutoCommit && len(del.FKChecks) == 0 && len(del.FKCascades) == 0,
 	)
 	if err != nil {
@@ -677,7 +709,7 @@ func mutationOutputColMap(mutation memo.RelExpr) opt.ColMap {
 }
 
 // buildUniqueChecks builds uniqueness check queries. These check queries are
-// used to enforce UNIQUE WITHOUT INDEX constraints.
+// used to enforce UNIQUE WITHOUT INDEX and EXCLUDE constraints.
 //
 // The checks consist of queries that will only return rows if a constraint is
 // violated. Those queries are each wrapped in an ErrorIfRows operator, which
@@ -687,23 +719,41 @@ func (b *Builder) buildUniqueChecks(checks memo.UniqueChecksExpr) error {
 	for i := range checks {
 		c := &checks[i]
 		// Construct the query that returns uniqueness violations.
-		query, err := b.buildRelational(c.Check)
+		query, err := b.buildCheckQuery(c.Check)
 		if err != nil {
 			return err
 		}
-		// Wrap the query in an error node.
-		mkErr := func(row tree.Datums) error {
+		keyVals := func(row tree.Datums) tree.Datums {
 			keyVals := make(tree.Datums, len(c.KeyCols))
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
+			return keyVals
+		}
+		// Wrap the query in an error node.
+		mkErr := func(row tree.Datums) error {
+			keyVals := keyVals(row)
+			if c.Exclusion {
+				return mkExclusionCheckErr(md, c, keyVals)
+			}
 			return mkUniqueCheckErr(md, c, keyVals)
 		}
 		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
 		if err != nil {
 			return err
 		}
-		b.checks = append(b.checks, node)
+		check := exec.Check{Node: node}
+		if c.Deferrable {
+			tab := md.Table(c.Table)
+			uc := tab.Unique(c.CheckOrdinal)
+			check.Deferrable = &exec.DeferrableConstraint{
+				TableID:           tab.ID(),
+				Name:              uc.Name(),
+				InitiallyDeferred: uc.Deferrability() == tree.DeferrableInitiallyDeferred,
+			}
+			check.KeyVals = keyVals
+		}
+		b.checks = append(b.checks, check)
 	}
 	return nil
 }
@@ -713,27 +763,93 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 	for i := range checks {
 		c := &checks[i]
 		// Construct the query that returns FK violations.
-		query, err := b.buildRelational(c.Check)
+		query, err := b.buildCheckQuery(c.Check)
 		if err != nil {
 			return err
 		}
-		// Wrap the query in an error node.
-		mkErr := func(row tree.Datums) error {
+		keyVals := func(row tree.Datums) tree.Datums {
 			keyVals := make(tree.Datums, len(c.KeyCols))
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
-			return mkFKCheckErr(md, c, keyVals)
+			return keyVals
+		}
+		// Wrap the query in an error node.
+		mkErr := func(row tree.Datums) error {
+			return mkFKCheckErr(md, c, keyVals(row))
 		}
 		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
 		if err != nil {
 			return err
 		}
-		b.checks = append(b.checks, node)
+		check := exec.Check{Node: node}
+		if c.Deferrable {
+			var fk cat.ForeignKeyConstraint
+			if c.FKOutbound {
+				fk = md.Table(c.OriginTable).OutboundForeignKey(c.FKOrdinal)
+			} else {
+				fk = md.Table(c.ReferencedTable).InboundForeignKey(c.FKOrdinal)
+			}
+			check.Deferrable = &exec.DeferrableConstraint{
+				TableID:           fk.OriginTableID(),
+				Name:              fk.Name(),
+				InitiallyDeferred: fk.Deferrability() == tree.DeferrableInitiallyDeferred,
+			}
+			check.KeyVals = keyVals
+		}
+		b.checks = append(b.checks, check)
 	}
 	return nil
 }
 
+// buildCheckQuery builds the query of a FK, uniqueness or exclusion check.
+//
+// The check reads at the snapshot of the statement. Under isolation levels
+// weaker than serializable, the transaction may commit without refreshing its
+// reads, so two concurrent transactions could each write rows which together
+// violate the constraint without either check observing the write of the other
+// transaction. To prevent this, all the rows read by the check are locked with
+// the FOR UPDATE locking mode, since shared locks are not yet implemented. A
+// locking read waits for the uncommitted writes of other transactions and
+// fails if it encounters a write committed after the snapshot of the statement,
+// in which case the statement is retried at a new snapshot. Checks using
+// operators which cannot lock the rows they read are rejected.
+func (b *Builder) buildCheckQuery(check memo.RelExpr) (execPlan, error) {
+	if !b.weakIsolation() {
+		return b.buildRelational(check)
+	}
+	if !canLockCheckRows(check) {
+		return execPlan{}, unimplemented.New("weak-isolation-checks",
+			"the constraint checks of this statement cannot lock the rows they read, "+
+				"which is required under isolation levels weaker than SERIALIZABLE")
+	}
+	// Re-entrance is not possible because checks are never nested.
+	b.forceForUpdateLocking = true
+	defer func() { b.forceForUpdateLocking = false }()
+	return b.buildRelational(check)
+}
+
+// canLockCheckRows returns false if the given check query contains an
+// operator which does not support row-level locking of the rows it reads.
+func canLockCheckRows(e opt.Expr) bool {
+	switch e.(type) {
+	case *memo.InvertedJoinExpr, *memo.ZigzagJoinExpr:
+		return false
+	}
+	for i, n := 0, e.ChildCount(); i < n; i++ {
+		if !canLockCheckRows(e.Child(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// weakIsolation returns true if the statement is executed by a transaction
+// with an isolation level weaker than serializable.
+func (b *Builder) weakIsolation() bool {
+	return b.evalCtx != nil && b.evalCtx.TxnIsoLevel().ToleratesWriteSkew()
+}
+
 // mkUniqueCheckErr generates a user-friendly error describing a uniqueness
 // violation. The keyVals are the values that correspond to the
 // cat.UniqueConstraint columns.
@@ -776,6 +892,45 @@ func mkUniqueCheckErr(md *opt.Metadata, c *memo.UniqueChecksItem, keyVals tree.D
 	)
 }
 
+// mkExclusionCheckErr generates a user-friendly error describing an exclusion
+// constraint violation. The keyVals are the values that correspond to the
+// cat.ExclusionConstraint columns.
+func mkExclusionCheckErr(md *opt.Metadata, c *memo.UniqueChecksItem, keyVals tree.Datums) error {
+	tabMeta := md.TableMeta(c.Table)
+	ec := tabMeta.Table.ExclusionConstraint(c.CheckOrdinal)
+	var msg, details bytes.Buffer
+
+	// Generate an error of the form:
+	//   ERROR:  conflicting key value violates exclusion constraint "foo"
+	//   DETAIL: Key (a, b)=(1, {2}) conflicts with existing key.
+	msg.WriteString("conflicting key value violates exclusion constraint ")
+	lexbase.EncodeEscapedSQLIdent(&msg, ec.Name)
+
+	details.WriteString("Key (")
+	for i, ord := range ec.ColumnOrdinals {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		details.WriteString(string(tabMeta.Table.Column(ord).ColName()))
+	}
+	details.WriteString(")=(")
+	for i, d := range keyVals {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		details.WriteString(d.String())
+	}
+	details.WriteString(") conflicts with existing key.")
+
+	return errors.WithDetail(
+		pgerror.WithConstraintName(
+			pgerror.Newf(pgcode.ExclusionViolation, "%s", msg.String()),
+			ec.Name,
+		),
+		details.String(),
+	)
+}
+
 // mkFKCheckErr generates a user-friendly error describing a foreign key
 // violation. The keyVals are the values that correspond to the
 // cat.ForeignKeyConstraint columns.
@@ -945,6 +1100,12 @@ var forUpdateLocking = &tree.LockingItem{Strength: tree.ForUpdate}
 // builder should apply a FOR UPDATE row-level locking mode to the initial row
 // scan of a mutation expression.
 func (b *Builder) shouldApplyImplicitLockingToMutationInput(mutExpr memo.RelExpr) bool {
+	if b.lockMutationInput {
+		// Under isolation levels weaker than serializable, a cascade must lock
+		// the rows it reads so that it does not miss rows which reference the
+		// mutated rows and were added concurrently.
+		return true
+	}
 	switch t := mutExpr.(type) {
 	case *memo.InsertExpr:
 		// Unlike with the other three mutation expressions, it never makes
@@ -295,6 +295,9 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.CreateViewExpr:
 		ep, err = b.buildCreateView(t)
 
+	case *memo.CreateFunctionExpr:
+		ep, err = b.buildCreateFunction(t)
+
 	case *memo.WithExpr:
 		ep, err = b.buildWith(t)
 
@@ -578,6 +581,15 @@ func (b *Builder) scanParams(
 			"cannot execute %s in a read-only transaction", locking.Strength.String())
 	}
 
+	// Raise error if the SKIP LOCKED wait policy is used on a table with
+	// multiple column families. Skipping the locked keys of a row could return
+	// a partial row.
+	if locking != nil && locking.WaitPolicy == tree.LockWaitSkip && tab.FamilyCount() > 1 {
+		return exec.ScanParams{}, opt.ColMap{}, unimplemented.NewWithIssuef(40476,
+			"SKIP LOCKED cannot be used for tables with multiple column families",
+		)
+	}
+
 	needed, outputMap := b.getColumns(scan.Cols, scan.Table)
 
 	// Get the estimated row count from the statistics. When there are no
@@ -11,6 +11,8 @@
 package execbuilder
 
 import (
+	"math"
+
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
@@ -40,24 +42,26 @@ func init() {
 	// the functions depend on scalarBuildFuncMap which in turn depends on the
 	// functions).
 	scalarBuildFuncMap = [opt.NumOperators]buildFunc{
-		opt.VariableOp:       (*Builder).buildVariable,
-		opt.ConstOp:          (*Builder).buildTypedExpr,
-		opt.NullOp:           (*Builder).buildNull,
-		opt.PlaceholderOp:    (*Builder).buildTypedExpr,
-		opt.TupleOp:          (*Builder).buildTuple,
-		opt.FunctionOp:       (*Builder).buildFunction,
-		opt.CaseOp:           (*Builder).buildCase,
-		opt.CastOp:           (*Builder).buildCast,
-		opt.AssignmentCastOp: (*Builder).buildAssignmentCast,
-		opt.CoalesceOp:       (*Builder).buildCoalesce,
-		opt.ColumnAccessOp:   (*Builder).buildColumnAccess,
-		opt.ArrayOp:          (*Builder).buildArray,
-		opt.AnyOp:            (*Builder).buildAny,
-		opt.AnyScalarOp:      (*Builder).buildAnyScalar,
-		opt.IndirectionOp:    (*Builder).buildIndirection,
-		opt.CollateOp:        (*Builder).buildCollate,
-		opt.ArrayFlattenOp:   (*Builder).buildArrayFlatten,
-		opt.IfErrOp:          (*Builder).buildIfErr,
+		opt.VariableOp:         (*Builder).buildVariable,
+		opt.ConstOp:            (*Builder).buildTypedExpr,
+		opt.NullOp:             (*Builder).buildNull,
+		opt.PlaceholderOp:      (*Builder).buildTypedExpr,
+		opt.TupleOp:            (*Builder).buildTuple,
+		opt.FunctionOp:         (*Builder).buildFunction,
+		opt.CaseOp:             (*Builder).buildCase,
+		opt.CastOp:             (*Builder).buildCast,
+		opt.AssignmentCastOp:   (*Builder).buildAssignmentCast,
+		opt.CoalesceOp:         (*Builder).buildCoalesce,
+		opt.ColumnAccessOp:     (*Builder).buildColumnAccess,
+		opt.ArrayOp:            (*Builder).buildArray,
+		opt.AnyOp:              (*Builder).buildAny,
+		opt.AnyScalarOp:        (*Builder).buildAnyScalar,
+		opt.IndirectionOp:      (*Builder).buildIndirection,
+		opt.MultiIndirectionOp: (*Builder).buildMultiIndirection,
+		opt.ArraySliceOp:       (*Builder).buildArraySlice,
+		opt.CollateOp:          (*Builder).buildCollate,
+		opt.ArrayFlattenOp:     (*Builder).buildArrayFlatten,
+		opt.IfErrOp:            (*Builder).buildIfErr,
 
 		// Item operators.
 		opt.ProjectionsItemOp:  (*Builder).buildItem,
@@ -462,6 +466,60 @@ func (b *Builder) buildIndirection(
 	return tree.NewTypedIndirectionExpr(expr, index, scalar.DataType()), nil
 }
 
+func (b *Builder) buildMultiIndirection(
+	ctx *buildScalarCtx, scalar opt.ScalarExpr,
+) (tree.TypedExpr, error) {
+	mi := scalar.(*memo.MultiIndirectionExpr)
+	expr, err := b.buildScalar(ctx, mi.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	subscripts := make(tree.ArraySubscripts, len(mi.Indexes))
+	for i := range mi.Indexes {
+		index, err := b.buildScalar(ctx, mi.Indexes[i])
+		if err != nil {
+			return nil, err
+		}
+		subscripts[i] = &tree.ArraySubscript{Begin: index}
+	}
+	return tree.NewTypedIndirectionExprWithSubscripts(expr, subscripts, scalar.DataType()), nil
+}
+
+func (b *Builder) buildArraySlice(
+	ctx *buildScalarCtx, scalar opt.ScalarExpr,
+) (tree.TypedExpr, error) {
+	slice := scalar.(*memo.ArraySliceExpr)
+	expr, err := b.buildScalar(ctx, slice.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	// buildBound builds a bound of a slice, leaving it out if it is the
+	// sentinel value for an omitted bound.
+	buildBound := func(bound opt.ScalarExpr, omitted tree.DInt) (tree.TypedExpr, error) {
+		if c, ok := bound.(*memo.ConstExpr); ok {
+			if d, ok := c.Value.(*tree.DInt); ok && *d == omitted {
+				return nil, nil
+			}
+		}
+		return b.buildScalar(ctx, bound)
+	}
+	subscripts := make(tree.ArraySubscripts, len(slice.Lower))
+	for i := range slice.Lower {
+		lower, err := buildBound(slice.Lower[i], math.MinInt32)
+		if err != nil {
+			return nil, err
+		}
+		upper, err := buildBound(slice.Upper[i], math.MaxInt32)
+		if err != nil {
+			return nil, err
+		}
+		subscripts[i] = &tree.ArraySubscript{Begin: lower, End: upper, Slice: true}
+	}
+	return tree.NewTypedIndirectionExprWithSubscripts(expr, subscripts, scalar.DataType()), nil
+}
+
 func (b *Builder) buildCollate(ctx *buildScalarCtx, scalar opt.ScalarExpr) (tree.TypedExpr, error) {
 	expr, err := b.buildScalar(ctx, scalar.Child(0).(opt.ScalarExpr))
 	if err != nil {
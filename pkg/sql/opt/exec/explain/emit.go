@@ -286,6 +286,7 @@ var nodeNames = [...]string{
 	createTableOp:          "create table",
 	createTableAsOp:        "create table as",
 	createViewOp:           "create view",
+	createFunctionOp:       "create function",
 	deleteOp:               "delete",
 	deleteRangeOp:          "delete range",
 	distinctOp:             "distinct",
@@ -895,6 +896,7 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 		createTableOp,
 		createTableAsOp,
 		createViewOp,
+		createFunctionOp,
 		sequenceSelectOp,
 		saveTableOp,
 		errorIfRowsOp,
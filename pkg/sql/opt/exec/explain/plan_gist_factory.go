@@ -130,7 +130,7 @@ func (f *PlanGistFactory) ConstructPlan(
 	root exec.Node,
 	subqueries []exec.Subquery,
 	cascades []exec.Cascade,
-	checks []exec.Node,
+	checks []exec.Check,
 	rootRowCount int64,
 ) (exec.Plan, error) {
 	plan, err := f.wrappedFactory.ConstructPlan(root, subqueries, cascades, checks, rootRowCount)
@@ -555,6 +555,22 @@ func (u *unknownTable) IsPartitionAllBy() bool {
 	return false
 }
 
+func (u *unknownTable) TriggerCount() int {
+	return 0
+}
+
+func (u *unknownTable) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("not implemented"))
+}
+
+func (u *unknownTable) ExclusionConstraintCount() int {
+	return 0
+}
+
+func (u *unknownTable) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("not implemented"))
+}
+
 var _ cat.Table = &unknownTable{}
 
 // unknownTable implements the cat.Index interface and is used to represent
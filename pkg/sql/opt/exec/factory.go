@@ -244,6 +244,37 @@ type Cascade struct {
 	) (Plan, error)
 }
 
+// Check describes a check query, which is run after the main query and all
+// cascades. The query doesn't return results but can generate an error (e.g. a
+// foreign key check failure).
+type Check struct {
+	// Node is the root of the check query.
+	Node Node
+
+	// Deferrable is set if the check enforces a DEFERRABLE constraint, in which
+	// case the constraint may be checked at the end of the transaction instead.
+	Deferrable *DeferrableConstraint
+
+	// KeyVals returns the values of the constraint columns, given a row
+	// produced by the check query. It is only set if Deferrable is set; the
+	// values identify the rows to validate at the end of the transaction.
+	KeyVals func(row tree.Datums) tree.Datums
+}
+
+// DeferrableConstraint identifies a DEFERRABLE constraint enforced by a check.
+type DeferrableConstraint struct {
+	// TableID is the table on which the constraint is defined. For foreign key
+	// constraints, this is the origin (referencing) table.
+	TableID cat.StableID
+
+	// Name is the name of the constraint.
+	Name string
+
+	// InitiallyDeferred is true if the constraint is checked at the end of the
+	// transaction unless SET CONSTRAINTS specifies otherwise.
+	InitiallyDeferred bool
+}
+
 // InsertFastPathFKCheck contains information about a foreign key check to be
 // performed by the insert fast-path (see ConstructInsertFastPath). It
 // identifies the index into which we can perform the lookup.
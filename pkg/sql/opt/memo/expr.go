@@ -1029,7 +1029,8 @@ type FKCascades []FKCascade
 // FKCascade stores metadata necessary for building a cascading query.
 // Cascading queries are built as needed, after the original query is executed.
 type FKCascade struct {
-	// FKName is the name of the FK constraint.
+	// FKName is the name of the FK constraint, or the name of the trigger if
+	// the cascade executes an AFTER trigger.
 	FKName string
 
 	// Builder is an object that can be used as the "optbuilder" for the cascading
@@ -1042,7 +1043,8 @@ type FKCascade struct {
 
 	// OldValues are column IDs from the mutation input that correspond to the
 	// old values of the modified rows. The list maps 1-to-1 to foreign key
-	// columns. Empty if the cascade does not require input.
+	// columns (or to the columns referenced by a trigger). Empty if the cascade
+	// does not require input.
 	OldValues opt.ColList
 
 	// NewValues are column IDs from the mutation input that correspond to the
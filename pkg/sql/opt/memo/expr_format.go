@@ -213,7 +213,7 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 		*WindowExpr, *OpaqueRelExpr, *OpaqueMutationExpr, *OpaqueDDLExpr,
 		*AlterTableSplitExpr, *AlterTableUnsplitExpr, *AlterTableUnsplitAllExpr,
 		*AlterTableRelocateExpr, *AlterRangeRelocateExpr, *ControlJobsExpr, *CancelQueriesExpr,
-		*CancelSessionsExpr, *CreateViewExpr, *ExportExpr:
+		*CancelSessionsExpr, *CreateViewExpr, *CreateFunctionExpr, *ExportExpr:
 		fmt.Fprintf(f.Buffer, "%v", e.Op())
 		FormatPrivate(f, e.Private(), required)
 
@@ -670,27 +670,11 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 			f.formatCol(col.Alias, col.ID, opt.ColSet{} /* notNullCols */)
 		}
 		tp.Child(f.Buffer.String())
+		f.formatViewDeps(tp, t.Deps)
 
-		n := tp.Child("dependencies")
-		for _, dep := range t.Deps {
-			f.Buffer.Reset()
-			name := dep.DataSource.Name()
-			f.Buffer.WriteString(name.String())
-			if dep.SpecificIndex {
-				fmt.Fprintf(f.Buffer, "@%s", dep.DataSource.(cat.Table).Index(dep.Index).Name())
-			}
-			colNames, isTable := dep.GetColumnNames()
-			if len(colNames) > 0 {
-				fmt.Fprintf(f.Buffer, " [columns:")
-				for _, colName := range colNames {
-					fmt.Fprintf(f.Buffer, " %s", colName)
-				}
-				fmt.Fprintf(f.Buffer, "]")
-			} else if isTable {
-				fmt.Fprintf(f.Buffer, " [no columns]")
-			}
-			n.Child(f.Buffer.String())
-		}
+	case *CreateFunctionExpr:
+		tp.Child(t.Syntax.String())
+		f.formatViewDeps(tp, t.Deps)
 
 	case *CreateStatisticsExpr:
 		tp.Child(t.Syntax.String())
@@ -922,6 +906,18 @@ func (f *ExprFmtCtx) formatScalarWithLabel(
 
 		return
 
+	case opt.ArraySliceOp:
+		fmt.Fprintf(f.Buffer, "%v", scalar.Op())
+		f.FormatScalarProps(scalar)
+
+		tp = tp.Child(f.Buffer.String())
+
+		f.formatExpr(scalar.Child(0), tp)
+		f.formatExpr(scalar.Child(1), tp.Child("lower"))
+		f.formatExpr(scalar.Child(2), tp.Child("upper"))
+
+		return
+
 	case opt.AggFilterOp:
 		fmt.Fprintf(f.Buffer, "%v", scalar.Op())
 		f.FormatScalarProps(scalar)
@@ -1113,8 +1109,19 @@ func (f *ExprFmtCtx) formatScalarPrivate(scalar opt.ScalarExpr) {
 
 	case *UniqueChecksItem:
 		tab := f.Memo.metadata.TableMeta(t.Table)
-		constraint := tab.Table.Unique(t.CheckOrdinal)
 		fmt.Fprintf(f.Buffer, ": %s(", tab.Alias.ObjectName)
+		if t.Exclusion {
+			constraint := tab.Table.ExclusionConstraint(t.CheckOrdinal)
+			for i, ord := range constraint.ColumnOrdinals {
+				if i > 0 {
+					f.Buffer.WriteByte(',')
+				}
+				f.Buffer.WriteString(string(tab.Table.Column(ord).ColName()))
+			}
+			f.Buffer.WriteByte(')')
+			break
+		}
+		constraint := tab.Table.Unique(t.CheckOrdinal)
 		for i := 0; i < constraint.ColumnCount(); i++ {
 			if i > 0 {
 				f.Buffer.WriteByte(',')
@@ -1388,6 +1395,31 @@ func (f *ExprFmtCtx) formatColSimpleToBuffer(buf *bytes.Buffer, label string, id
 	}
 }
 
+// formatViewDeps adds a "dependencies" child to tp, listing the data sources
+// that a view or function depends on.
+func (f *ExprFmtCtx) formatViewDeps(tp treeprinter.Node, deps opt.ViewDeps) {
+	n := tp.Child("dependencies")
+	for _, dep := range deps {
+		f.Buffer.Reset()
+		name := dep.DataSource.Name()
+		f.Buffer.WriteString(name.String())
+		if dep.SpecificIndex {
+			fmt.Fprintf(f.Buffer, "@%s", dep.DataSource.(cat.Table).Index(dep.Index).Name())
+		}
+		colNames, isTable := dep.GetColumnNames()
+		if len(colNames) > 0 {
+			fmt.Fprintf(f.Buffer, " [columns:")
+			for _, colName := range colNames {
+				fmt.Fprintf(f.Buffer, " %s", colName)
+			}
+			fmt.Fprintf(f.Buffer, "]")
+		} else if isTable {
+			fmt.Fprintf(f.Buffer, " [no columns]")
+		}
+		n.Child(f.Buffer.String())
+	}
+}
+
 // formatCol outputs the specified column into the context's buffer using the
 // following format:
 //   label:id(type)
@@ -1536,6 +1568,10 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 		schema := f.Memo.Metadata().Schema(t.Schema)
 		fmt.Fprintf(f.Buffer, " %s.%s", schema.Name(), t.ViewName)
 
+	case *CreateFunctionPrivate:
+		schema := f.Memo.Metadata().Schema(t.Schema)
+		fmt.Fprintf(f.Buffer, " %s.%s", schema.Name(), t.Syntax.FuncName.Object())
+
 	case *JoinPrivate:
 		// Nothing to show; flags are shown separately.
 
@@ -13,6 +13,7 @@ package memo
 import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/errors"
 )
 
@@ -49,7 +50,10 @@ func CanExtractConstDatum(e opt.Expr) bool {
 				return false
 			}
 		}
-		return true
+		// A multidimensional array can only be built if its sub-arrays have
+		// matching dimensions.
+		_, err := ExtractConstArray(arr.Elems, arr.Typ)
+		return err == nil
 	}
 
 	return false
@@ -81,22 +85,43 @@ func ExtractConstDatum(e opt.Expr) tree.Datum {
 		return tree.NewDTuple(t.Typ, datums...)
 
 	case *ArrayExpr:
-		elementType := t.Typ.ArrayContents()
-		a := tree.NewDArray(elementType)
-		a.Array = make(tree.Datums, len(t.Elems))
-		for i := range a.Array {
-			a.Array[i] = ExtractConstDatum(t.Elems[i])
-			if a.Array[i] == tree.DNull {
-				a.HasNulls = true
-			} else {
-				a.HasNonNulls = true
-			}
+		a, err := ExtractConstArray(t.Elems, t.Typ)
+		if err != nil {
+			panic(errors.NewAssertionErrorWithWrappedErrf(err, "non-const expression: %+v", e))
 		}
 		return a
 	}
 	panic(errors.AssertionFailedf("non-const expression: %+v", e))
 }
 
+// ExtractConstArray returns the array of the given type with the given
+// constant elements. If the elements are themselves arrays, it returns the
+// multidimensional array formed by them, or an error if their dimensions do not
+// match.
+func ExtractConstArray(elems ScalarListExpr, typ *types.T) (*tree.DArray, error) {
+	datums := make(tree.Datums, len(elems))
+	hasSubArrays := false
+	for i := range elems {
+		datums[i] = ExtractConstDatum(elems[i])
+		if elems[i].DataType().Family() == types.ArrayFamily {
+			hasSubArrays = true
+		}
+	}
+	if hasSubArrays {
+		return tree.NewDArrayFromSubArrays(typ.ArrayContents(), datums)
+	}
+	a := tree.NewDArray(typ.ArrayContents())
+	a.Array = datums
+	for i := range a.Array {
+		if a.Array[i] == tree.DNull {
+			a.HasNulls = true
+		} else {
+			a.HasNonNulls = true
+		}
+	}
+	return a, nil
+}
+
 // ExtractAggFunc digs down into the given aggregate expression and returns the
 // aggregate function, skipping past any AggFilter or AggDistinct operators.
 func ExtractAggFunc(e opt.ScalarExpr) opt.ScalarExpr {
@@ -377,6 +377,10 @@ func (h *hasher) HashDatum(val tree.Datum) {
 		// disambiguate.
 		alwaysHashType := len(t.Array) == 0
 		h.hashDatumsWithType(t.Array, t.ResolvedType(), alwaysHashType)
+		for _, dim := range t.Dims {
+			h.HashInt(dim.Length)
+			h.HashInt(dim.LowerBound)
+		}
 	case *tree.DCollatedString:
 		h.HashString(t.Locale)
 		h.HashString(t.Contents)
@@ -855,6 +859,15 @@ func (h *hasher) IsDatumEqual(l, r tree.Datum) bool {
 		if !h.areDatumsWithTypeEqual(lt.Array, rt.Array, ltyp, rtyp) {
 			return false
 		}
+		// Arrays with the same elements but a different shape are different.
+		if len(lt.Dims) != len(rt.Dims) {
+			return false
+		}
+		for i := range lt.Dims {
+			if lt.Dims[i] != rt.Dims[i] {
+				return false
+			}
+		}
 		return len(lt.Array) != 0 || h.IsTypeEqual(ltyp, rtyp)
 	default:
 		h.bytes = encodeDatum(h.bytes[:0], l)
@@ -67,6 +67,14 @@ func TestInterner(t *testing.T) {
 	arr6.Array = tree.Datums{}
 	arr7 := tree.NewDArray(types.String)
 	arr7.Array = tree.Datums{}
+	arr8 := tree.NewDArray(types.Int)
+	arr8.Array = tree.Datums{tree.NewDInt(1), tree.NewDInt(2)}
+	arr9 := tree.NewDArray(types.Int)
+	arr9.Array = tree.Datums{tree.NewDInt(1), tree.NewDInt(2)}
+	arr9.Dims = []tree.ArrayDim{{Length: 2, LowerBound: 0}}
+	arr10 := tree.NewDArray(types.Int)
+	arr10.Array = tree.Datums{tree.NewDInt(1), tree.NewDInt(2)}
+	arr10.Dims = []tree.ArrayDim{{Length: 1, LowerBound: 1}, {Length: 2, LowerBound: 1}}
 
 	dec1, _ := tree.ParseDDecimal("1.0")
 	dec2, _ := tree.ParseDDecimal("1.0")
@@ -275,6 +283,9 @@ func TestInterner(t *testing.T) {
 			{val1: arr4, val2: arr5, equal: false},
 			{val1: arr4, val2: arr6, equal: false},
 			{val1: arr6, val2: arr7, equal: false},
+			{val1: arr8, val2: arr9, equal: false},
+			{val1: arr8, val2: arr10, equal: false},
+			{val1: arr9, val2: arr10, equal: false},
 
 			{val1: dec1, val2: dec2, equal: true},
 			{val1: dec2, val2: dec3, equal: false},
@@ -1483,6 +1483,12 @@ func (b *logicalPropsBuilder) buildCreateViewProps(cv *CreateViewExpr, rel *prop
 	BuildSharedProps(cv, &rel.Shared, b.evalCtx)
 }
 
+func (b *logicalPropsBuilder) buildCreateFunctionProps(
+	cf *CreateFunctionExpr, rel *props.Relational,
+) {
+	BuildSharedProps(cf, &rel.Shared, b.evalCtx)
+}
+
 func (b *logicalPropsBuilder) buildFiltersItemProps(item *FiltersItem, scalar *props.Scalar) {
 	BuildSharedProps(item.Condition, &scalar.Shared, b.evalCtx)
 
@@ -1812,6 +1818,12 @@ func MakeTableFuncDep(md *opt.Metadata, tabID opt.TableID) *props.FuncDepSet {
 			continue
 		}
 
+		if unique.Deferrability() != tree.NotDeferrableConstraint {
+			// A deferrable unique constraint can be violated within a transaction,
+			// so we cannot use it as a key.
+			continue
+		}
+
 		if _, isPartial := unique.Predicate(); isPartial {
 			// Partial constraints cannot be considered while building functional
 			// dependency keys for the table because their keys are only unique
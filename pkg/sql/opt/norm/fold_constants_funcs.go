@@ -177,20 +177,16 @@ func (c *CustomFuncs) IsListOfConstants(elems memo.ScalarListExpr) bool {
 }
 
 // FoldArray evaluates an Array expression with constant inputs. It returns the
-// array as a Const datum with type TArray.
-func (c *CustomFuncs) FoldArray(elems memo.ScalarListExpr, typ *types.T) opt.ScalarExpr {
-	elemType := typ.ArrayContents()
-	a := tree.NewDArray(elemType)
-	a.Array = make(tree.Datums, len(elems))
-	for i := range a.Array {
-		a.Array[i] = memo.ExtractConstDatum(elems[i])
-		if a.Array[i] == tree.DNull {
-			a.HasNulls = true
-		} else {
-			a.HasNonNulls = true
-		}
+// array as a Const datum with type TArray, or ok=false if the evaluation
+// results in an error.
+func (c *CustomFuncs) FoldArray(
+	elems memo.ScalarListExpr, typ *types.T,
+) (_ opt.ScalarExpr, ok bool) {
+	a, err := memo.ExtractConstArray(elems, typ)
+	if err != nil {
+		return nil, false
 	}
-	return c.f.ConstructConst(a, typ)
+	return c.f.ConstructConst(a, typ), true
 }
 
 // IsConstValueOrGroupOfConstValues returns true if the input is a constant,
@@ -510,8 +506,9 @@ func (c *CustomFuncs) FoldIndirection(input, index opt.ScalarExpr) (_ opt.Scalar
 	// Index is 1-based, so convert to 0-based.
 	indexD := memo.ExtractConstDatum(index)
 
-	// Case 1: The input is a static array constructor.
-	if arr, ok := input.(*memo.ArrayExpr); ok {
+	// Case 1: The input is a static array constructor of a one-dimensional
+	// array.
+	if arr, ok := input.(*memo.ArrayExpr); ok && !c.HasSubArrays(arr) {
 		if indexInt, ok := indexD.(*tree.DInt); ok {
 			indexI := int(*indexInt) - 1
 			if indexI >= 0 && indexI < len(arr.Elems) {
@@ -538,6 +535,17 @@ func (c *CustomFuncs) FoldIndirection(input, index opt.ScalarExpr) (_ opt.Scalar
 	return nil, false
 }
 
+// HasSubArrays returns true if the elements of the array constructor are
+// themselves arrays, which makes it construct a multidimensional array.
+func (c *CustomFuncs) HasSubArrays(arr *memo.ArrayExpr) bool {
+	for _, elem := range arr.Elems {
+		if elem.DataType().Family() == types.ArrayFamily {
+			return true
+		}
+	}
+	return false
+}
+
 // FoldColumnAccess tries to evaluate a tuple column access operator with a
 // constant tuple input (though tuple field values do not need to be constant).
 // It returns the referenced tuple field value, or ok=false if folding is not
@@ -15,6 +15,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 )
 
@@ -145,6 +146,27 @@ func (c *CustomFuncs) NeededMutationFetchCols(
 		}
 	}
 
+	// Retain all the FetchCols if the table has AFTER triggers which fire on
+	// the mutation, since the old values of the modified rows are passed to
+	// the triggers (which are built as cascades) after the mutation.
+	if op == opt.UpdateOp || op == opt.DeleteOp {
+		event := tree.TriggerEventUpdate
+		if op == opt.DeleteOp {
+			event = tree.TriggerEventDelete
+		}
+		for i, n := 0, tabMeta.Table.TriggerCount(); i < n; i++ {
+			t := tabMeta.Table.Trigger(i)
+			if t.ActionTime == tree.TriggerActionTimeAfter && t.Events.Contains(event) {
+				for ord, col := range private.FetchCols {
+					if col != 0 {
+						cols.Add(tabMeta.MetaID.ColumnID(ord))
+					}
+				}
+				break
+			}
+		}
+	}
+
 	switch op {
 	case opt.UpdateOp, opt.UpsertOp:
 		// Determine set of target table columns that need to be updated.
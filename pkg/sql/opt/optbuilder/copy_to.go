@@ -0,0 +1,135 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+// buildCopyTo builds a COPY ... TO STDOUT statement. The statement is planned
+// as the query that produces its rows; encoding the rows in the requested COPY
+// format is left to the client connection, which streams them as CopyData
+// messages.
+func (b *Builder) buildCopyTo(copyTo *tree.CopyTo, inScope *scope) (outScope *scope) {
+	if err := checkCopyToOptions(&copyTo.Options); err != nil {
+		panic(err)
+	}
+
+	stmt := copyTo.Statement
+	if stmt == nil {
+		// COPY t (a, b) TO STDOUT is equivalent to COPY (SELECT a, b FROM t) TO
+		// STDOUT. Without a column list, all visible columns are copied.
+		sel := &tree.SelectClause{
+			From: tree.From{Tables: tree.TableExprs{&copyTo.Table}},
+		}
+		if len(copyTo.Columns) == 0 {
+			sel.Exprs = tree.SelectExprs{tree.StarSelectExpr()}
+		} else {
+			sel.Exprs = make(tree.SelectExprs, len(copyTo.Columns))
+			for i, col := range copyTo.Columns {
+				sel.Exprs[i] = tree.SelectExpr{Expr: tree.NewUnresolvedName(string(col))}
+			}
+		}
+		stmt = &tree.Select{Select: sel}
+	}
+	outScope = b.buildStmt(stmt, nil /* desiredTypes */, inScope)
+
+	// FORCE QUOTE refers to the output columns by name.
+	for _, name := range copyTo.Options.ForceQuote {
+		found := false
+		for i := range outScope.cols {
+			if outScope.cols[i].name.MatchesReferenceName(name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			panic(pgerror.Newf(pgcode.InvalidColumnReference,
+				"FORCE_QUOTE column %q not referenced by COPY", string(name)))
+		}
+	}
+	return outScope
+}
+
+// checkCopyToOptions verifies that the options of a COPY TO statement are
+// valid for the requested format. The string options must be constants, since
+// they are interpreted by the client connection rather than evaluated.
+func checkCopyToOptions(opts *tree.CopyOptions) error {
+	if opts.Destination != nil {
+		return pgerror.Newf(pgcode.FeatureNotSupported, "destination is not supported with COPY TO")
+	}
+	switch {
+	case opts.Oids:
+		return pgerror.Newf(pgcode.FeatureNotSupported, "COPY with OIDS is not supported")
+	case opts.Freeze:
+		return pgerror.Newf(pgcode.FeatureNotSupported, "COPY FREEZE only available using COPY FROM")
+	case opts.ForceNotNull != nil:
+		return pgerror.Newf(pgcode.FeatureNotSupported, "FORCE NOT NULL only available using COPY FROM")
+	case opts.ForceNull != nil:
+		return pgerror.Newf(pgcode.FeatureNotSupported, "FORCE NULL only available using COPY FROM")
+	}
+	if opts.CopyFormat == tree.CopyFormatBinary {
+		switch {
+		case opts.Delimiter != nil:
+			return pgerror.Newf(pgcode.FeatureNotSupported, "DELIMITER unsupported in BINARY format")
+		case opts.Null != nil:
+			return pgerror.Newf(pgcode.FeatureNotSupported, "NULL unsupported in BINARY format")
+		case opts.Header:
+			return pgerror.Newf(pgcode.FeatureNotSupported, "HEADER unsupported in BINARY format")
+		}
+	}
+	if opts.Delimiter != nil {
+		delim, ok := opts.Delimiter.(*tree.StrVal)
+		if !ok {
+			return pgerror.Newf(pgcode.FeatureNotSupported, "DELIMITER must be a string constant")
+		}
+		if len(delim.RawString()) != 1 {
+			return pgerror.Newf(pgcode.InvalidParameterValue, "delimiter must be a single-byte character")
+		}
+	}
+	if opts.Null != nil {
+		if _, ok := opts.Null.(*tree.StrVal); !ok {
+			return pgerror.Newf(pgcode.FeatureNotSupported, "NULL must be a string constant")
+		}
+	}
+	if opts.Escape != nil {
+		if opts.CopyFormat != tree.CopyFormatCSV {
+			return pgerror.Newf(pgcode.FeatureNotSupported, "ESCAPE can only be specified for CSV")
+		}
+		if len(opts.Escape.RawString()) != 1 {
+			return pgerror.Newf(pgcode.FeatureNotSupported, "ESCAPE must be a single-byte character")
+		}
+	}
+	if opts.Quote != nil {
+		if opts.CopyFormat != tree.CopyFormatCSV {
+			return pgerror.Newf(pgcode.FeatureNotSupported, "QUOTE can only be specified for CSV")
+		}
+		if len(opts.Quote.RawString()) != 1 {
+			return pgerror.Newf(pgcode.FeatureNotSupported, "QUOTE must be a single-byte character")
+		}
+	}
+	if (opts.ForceQuote != nil || opts.ForceQuoteAll) && opts.CopyFormat != tree.CopyFormatCSV {
+		return pgerror.Newf(pgcode.FeatureNotSupported, "FORCE QUOTE can only be specified for CSV")
+	}
+	if opts.Encoding != nil {
+		switch builtins.CleanEncodingName(opts.Encoding.RawString()) {
+		case "utf8", "unicode", "cp65001":
+		default:
+			return unimplemented.NewWithIssueDetail(41608, "copy-to-encoding",
+				"COPY TO only supports the UTF8 encoding")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (outScope *scope) {
+	b.DisableMemoReuse = true
+	tn := tree.MakeTableNameFromPrefix(cf.FuncName.ObjectNamePrefix, cf.FuncName.ObjectName)
+	sch, resName := b.resolveSchemaForCreate(&tn)
+	schID := b.factory.Metadata().AddSchema(sch)
+	cf.FuncName.ObjectNamePrefix = resName
+	cf.FuncName.ExplicitCatalog = true
+	cf.FuncName.ExplicitSchema = true
+
+	body, hasLang := validateFunctionOptions(cf.Options)
+	if !hasLang {
+		panic(pgerror.New(pgcode.InvalidFunctionDefinition, "no language specified"))
+	}
+	if body == nil {
+		panic(pgerror.New(pgcode.InvalidFunctionDefinition, "no function body specified"))
+	}
+	if cf.ReturnType.IsSet {
+		panic(unimplemented.New("udf-setof", "set-returning user-defined functions are not supported"))
+	}
+
+	// We build the function body to:
+	//  - check the statement semantically,
+	//  - check that it returns a single column of the declared return type, and
+	//  - collect the dependencies of the function in b.viewDeps and
+	//    b.viewTypeDeps.
+	// The result is not otherwise used.
+	b.trackViewDeps = true
+	defer func() {
+		b.trackViewDeps = false
+		b.viewDeps = nil
+		b.viewTypeDeps = util.FastIntSet{}
+	}()
+
+	argScope := b.allocScope()
+	for i := range cf.Args {
+		arg := &cf.Args[i]
+		if arg.Class != tree.FunctionArgIn {
+			panic(unimplemented.New("udf-arg-class",
+				"only IN arguments are supported in user-defined functions"))
+		}
+		typ := b.resolveFunctionType(arg.Type)
+		for j := 0; j < i; j++ {
+			if arg.Name != "" && cf.Args[j].Name == arg.Name {
+				panic(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"parameter name %q used more than once", arg.Name))
+			}
+		}
+		arg.Type = typ
+		// The argument columns are never projected; they only need to be
+		// visible to the body.
+		b.synthesizeColumn(argScope, scopeColName(arg.Name), typ, nil /* expr */, nil /* scalar */)
+	}
+	retType := b.resolveFunctionType(cf.ReturnType.Type)
+	cf.ReturnType.Type = retType
+
+	if retType.Family() == types.VoidFamily {
+		// Functions returning VOID modify data and are executed by triggers.
+		ins := parseUDFMutationBody(cf.FuncName.Object(), string(*body), argScope)
+		b.buildUDFMutationBody(ins, argScope)
+	} else {
+		b.buildUDFBody(cf.FuncName.Object(), string(*body), retType, argScope)
+	}
+
+	outScope = b.allocScope()
+	outScope.expr = b.factory.ConstructCreateFunction(
+		&memo.CreateFunctionPrivate{
+			Schema:   schID,
+			Syntax:   cf,
+			Deps:     b.viewDeps,
+			TypeDeps: b.viewTypeDeps,
+		},
+	)
+	return outScope
+}
+
+// resolveFunctionType resolves the type of an argument or of the result of a
+// user-defined function, adding a type dependency if the type is user defined.
+func (b *Builder) resolveFunctionType(ref tree.ResolvableTypeReference) *types.T {
+	typ, err := tree.ResolveType(b.ctx, ref, b.semaCtx.GetTypeResolver())
+	if err != nil {
+		panic(err)
+	}
+	if typ.UserDefined() {
+		children, err := typedesc.GetTypeDescriptorClosure(typ)
+		if err != nil {
+			panic(err)
+		}
+		for id := range children {
+			b.viewTypeDeps.Add(int(id))
+		}
+	}
+	return typ
+}
+
+// validateFunctionOptions checks that no option of a CREATE FUNCTION
+// statement is specified more than once. It returns the body of the function,
+// if any, and whether the language of the function was specified.
+func validateFunctionOptions(
+	options tree.FunctionOptions,
+) (body *tree.FunctionBodyStr, hasLang bool) {
+	var seenVolatility, seenNullInput, seenLeakproof bool
+	for _, option := range options {
+		var seen *bool
+		switch t := option.(type) {
+		case tree.FunctionVolatility:
+			seen = &seenVolatility
+		case tree.FunctionNullInputBehavior:
+			seen = &seenNullInput
+		case tree.FunctionLeakproof:
+			seen = &seenLeakproof
+		case tree.FunctionLanguage:
+			seen = &hasLang
+		case tree.FunctionBodyStr:
+			if body != nil {
+				panic(pgerror.New(pgcode.Syntax, "conflicting or redundant options"))
+			}
+			body = &t
+			continue
+		}
+		if *seen {
+			panic(pgerror.New(pgcode.Syntax, "conflicting or redundant options"))
+		}
+		*seen = true
+	}
+	return body, hasLang
+}
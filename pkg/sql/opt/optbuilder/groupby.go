@@ -96,6 +96,22 @@ type groupby struct {
 	// projects that expression.
 	groupStrs groupByStrSet
 
+	// groupingSets contains the grouping sets when the GROUP BY clause contains
+	// ROLLUP, CUBE or GROUPING SETS, and is nil otherwise. Each set contains
+	// the IDs of the grouping columns that are part of the set. The grouping
+	// columns that are not part of every set are nulled out for the rows of the
+	// sets which don't include them.
+	groupingSets []opt.ColSet
+
+	// groupingSetCol identifies the grouping set of each row in the input and
+	// the output of the aggregation. Its values are the ordinals of the sets
+	// in groupingSets.
+	groupingSetCol opt.ColumnID
+
+	// groupingSetsRawCols contains the grouping expressions which are nulled
+	// out for some of the grouping sets, before they are nulled out.
+	groupingSetsRawCols []scopeColumn
+
 	// buildingGroupingCols is true while the grouping columns are being built.
 	// It is used to ensure that the builder does not throw a grouping error
 	// prematurely.
@@ -351,9 +367,23 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 		groupingColSet.Add(groupingCols[i].id)
 	}
 
-	// If there are any aggregates that are ordering sensitive, build the
-	// aggregations as window functions over each group.
-	if g.hasNonCommutativeAggregates() {
+	// If there are any aggregates that are ordering sensitive, the aggregations
+	// are built as window functions over each group.
+	asWindow := g.hasNonCommutativeAggregates()
+
+	// If there are grouping sets, each input row is aggregated once for each
+	// grouping set, so the grouping set is also a grouping column. The empty
+	// grouping sets are aggregated separately, unless the aggregations are
+	// built as window functions (see constructGroupingSetsAggregation).
+	separateEmptySets := false
+	if g.groupingSets != nil {
+		separateEmptySets = !asWindow && g.hasEmptyGroupingSet()
+		if b.constructGroupingSetsInput(fromScope, separateEmptySets) {
+			groupingColSet.Add(g.groupingSetCol)
+		}
+	}
+
+	if asWindow {
 		return b.buildAggregationAsWindow(groupingColSet, having, fromScope)
 	}
 
@@ -430,12 +460,21 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	// aggregate arguments, as well as any additional order by columns.
 	b.constructProjectForScope(fromScope, g.aggInScope)
 
-	g.aggOutScope.expr = b.constructGroupBy(
-		g.aggInScope.expr,
-		groupingColSet,
-		aggCols,
-		g.aggInScope.ordering,
-	)
+	if separateEmptySets {
+		g.aggOutScope.expr = b.constructGroupingSetsAggregation(
+			g,
+			groupingColSet,
+			aggCols,
+			g.aggInScope.ordering,
+		)
+	} else {
+		g.aggOutScope.expr = b.constructGroupBy(
+			g.aggInScope.expr,
+			groupingColSet,
+			aggCols,
+			g.aggInScope.ordering,
+		)
+	}
 
 	// Wrap with having filter if it exists.
 	if having != nil {
@@ -507,15 +546,20 @@ func (b *Builder) buildGroupingList(
 	// used in an aggregate function`. The builder cannot know whether there is
 	// a grouping error until the grouping columns are fully built.
 	g.buildingGroupingCols = true
-	for _, e := range groupBy {
-		b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+	if hasGroupingSets(groupBy) {
+		b.buildGroupingSets(groupBy, selects, projectionsScope, fromScope)
+	} else {
+		for _, e := range groupBy {
+			b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+		}
 	}
 	g.buildingGroupingCols = false
 }
 
 // buildGrouping builds a set of memo groups that represent a GROUP BY
 // expression. The expression (or expressions, if we have a star) is added to
-// groupStrs and to the aggInScope.
+// groupStrs and to the aggInScope. Returns the groupStrs keys of the
+// expressions.
 //
 //
 // groupBy          The given GROUP BY expression.
@@ -529,7 +573,7 @@ func (b *Builder) buildGroupingList(
 //                  as the aggregate function arguments.
 func (b *Builder) buildGrouping(
 	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope, aggInScope *scope,
-) {
+) (exprStrs []string) {
 	// Unwrap parenthesized expressions like "((a))" to "a".
 	groupBy = tree.StripParens(groupBy)
 	alias := ""
@@ -623,10 +667,12 @@ func (b *Builder) buildGrouping(
 	exprs = flattenTuples(exprs)
 
 	// Finally, build each of the GROUP BY columns.
+	exprStrs = make([]string, 0, len(exprs))
 	for _, e := range exprs {
 		// If a grouping column has already been added, don't add it again.
 		// GROUP BY a, a is semantically equivalent to GROUP BY a.
 		exprStr := symbolicExprStr(e)
+		exprStrs = append(exprStrs, exprStr)
 		if _, ok := fromScope.groupby.groupStrs[exprStr]; ok {
 			continue
 		}
@@ -638,6 +684,7 @@ func (b *Builder) buildGrouping(
 		b.buildScalar(e, fromScope, aggInScope, col, nil)
 		fromScope.groupby.groupStrs[exprStr] = col
 	}
+	return exprStrs
 }
 
 // buildAggArg builds a scalar expression which is used as an input in some form
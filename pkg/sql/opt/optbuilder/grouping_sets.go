@@ -0,0 +1,554 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// maxGroupingSets is the maximum number of grouping sets in a GROUP BY
+	// clause. This is the same limit as in Postgres.
+	maxGroupingSets = 4096
+
+	// maxCubeElements is the maximum number of elements in a CUBE. This is the
+	// same limit as in Postgres.
+	maxCubeElements = 12
+
+	// maxGroupingArgs is the maximum number of arguments to the GROUPING
+	// function. This is the same limit as in Postgres.
+	maxGroupingArgs = 31
+
+	// groupingFuncName is the name of the GROUPING function.
+	groupingFuncName = "grouping"
+)
+
+var errGroupingArgs = pgerror.New(pgcode.Grouping,
+	"arguments to GROUPING must be grouping expressions of the associated query level")
+
+// hasGroupingSets returns true if the given GROUP BY clause contains ROLLUP,
+// CUBE or GROUPING SETS.
+func hasGroupingSets(groupBy tree.GroupBy) bool {
+	for _, e := range groupBy {
+		if _, ok := tree.StripParens(e).(*tree.GroupingSet); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// groupingSetsBuilder builds the grouping columns of a GROUP BY clause which
+// contains ROLLUP, CUBE or GROUPING SETS, and computes its grouping sets.
+type groupingSetsBuilder struct {
+	b                *Builder
+	selects          tree.SelectExprs
+	projectionsScope *scope
+	fromScope        *scope
+
+	// ordinals maps the groupStrs keys to the ordinals of the corresponding
+	// grouping columns.
+	ordinals map[string]int
+}
+
+// buildGroupingSets builds the grouping columns for a GROUP BY clause which
+// contains ROLLUP, CUBE or GROUPING SETS, and computes its grouping sets. The
+// grouping sets of the clause are the cartesian product of the grouping sets
+// of each of its items. For example:
+//
+//   GROUP BY a, ROLLUP (b, c)
+//
+// has the grouping sets (a, b, c), (a, b) and (a).
+//
+// All the non-empty grouping sets are computed by a single aggregation, rather
+// than by a UNION ALL of an aggregation for each set. Its input is cross joined
+// with the ordinals of the grouping sets (see constructGroupingSetsInput), and
+// each grouping column which is not part of every set is replaced by a column
+// that is NULL for the sets which don't include it:
+//
+//   CASE WHEN grouping_set IN (<sets without col>) THEN NULL ELSE col END
+//
+// The aggregation then groups on these columns and on the grouping set
+// ordinal. The empty grouping sets are computed by separate scalar
+// aggregations (see constructGroupingSetsAggregation).
+func (b *Builder) buildGroupingSets(
+	groupBy tree.GroupBy, selects tree.SelectExprs, projectionsScope, fromScope *scope,
+) {
+	g := fromScope.groupby
+	gb := groupingSetsBuilder{
+		b:                b,
+		selects:          selects,
+		projectionsScope: projectionsScope,
+		fromScope:        fromScope,
+		ordinals:         make(map[string]int),
+	}
+
+	sets := []util.FastIntSet{{}}
+	for _, e := range groupBy {
+		itemSets := gb.buildItem(e)
+		product := make([]util.FastIntSet, 0, len(sets)*len(itemSets))
+		for i := range sets {
+			for j := range itemSets {
+				product = append(product, sets[i].Union(itemSets[j]))
+			}
+		}
+		if len(product) > maxGroupingSets {
+			panic(pgerror.Newf(pgcode.StatementTooComplex,
+				"too many grouping sets present (maximum %d)", maxGroupingSets))
+		}
+		sets = product
+	}
+
+	g.groupingSetCol = b.factory.Metadata().AddColumn("grouping_set", types.Int)
+	groupingSetVar := b.factory.ConstructVariable(g.groupingSetCol)
+	cols := g.groupingCols()
+	g.groupingSets = make([]opt.ColSet, len(sets))
+	for i := range cols {
+		col := &cols[i]
+		var excluded memo.ScalarListExpr
+		for k := range sets {
+			if !sets[k].Contains(i) {
+				excluded = append(excluded, b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(k)), types.Int))
+			}
+		}
+		if len(excluded) > 0 {
+			g.groupingSetsRawCols = append(g.groupingSetsRawCols, *col)
+			tupleTypes := make([]*types.T, len(excluded))
+			for j := range tupleTypes {
+				tupleTypes[j] = types.Int
+			}
+			b.populateSynthesizedColumn(col, b.factory.ConstructCase(
+				memo.TrueSingleton,
+				memo.ScalarListExpr{
+					b.factory.ConstructWhen(
+						b.factory.ConstructIn(
+							groupingSetVar,
+							b.factory.ConstructTuple(excluded, types.MakeTuple(tupleTypes)),
+						),
+						b.factory.ConstructNull(col.typ),
+					),
+				},
+				b.factory.ConstructVariable(col.id),
+			))
+		}
+		for k := range sets {
+			if sets[k].Contains(i) {
+				g.groupingSets[k].Add(col.id)
+			}
+		}
+	}
+
+	// The IDs of the grouping columns may have changed above, so make groupStrs
+	// point to the updated columns.
+	for exprStr, i := range gb.ordinals {
+		g.groupStrs[exprStr] = &cols[i]
+	}
+}
+
+// buildItem builds the grouping columns for an item of a GROUP BY clause, or
+// of GROUPING SETS, and returns its grouping sets.
+func (gb *groupingSetsBuilder) buildItem(e tree.Expr) []util.FastIntSet {
+	gs, ok := tree.StripParens(e).(*tree.GroupingSet)
+	if !ok {
+		return []util.FastIntSet{gb.buildElement(e)}
+	}
+
+	switch gs.Type {
+	case tree.RollupGroupingSet:
+		elems := make([]util.FastIntSet, len(gs.Exprs))
+		for i := range gs.Exprs {
+			elems[i] = gb.buildElement(gs.Exprs[i])
+		}
+		// ROLLUP (e1, ..., en) has the sets (e1, ..., en), ..., (e1), ().
+		sets := make([]util.FastIntSet, 0, len(elems)+1)
+		for i := len(elems); i >= 0; i-- {
+			var set util.FastIntSet
+			for j := 0; j < i; j++ {
+				set.UnionWith(elems[j])
+			}
+			sets = append(sets, set)
+		}
+		return sets
+
+	case tree.CubeGroupingSet:
+		if len(gs.Exprs) > maxCubeElements {
+			panic(pgerror.Newf(pgcode.ProgramLimitExceeded,
+				"CUBE is limited to %d elements", maxCubeElements))
+		}
+		elems := make([]util.FastIntSet, len(gs.Exprs))
+		for i := range gs.Exprs {
+			elems[i] = gb.buildElement(gs.Exprs[i])
+		}
+		// CUBE (e1, ..., en) has all the subsets of (e1, ..., en) as sets,
+		// starting with the largest one.
+		n := len(elems)
+		sets := make([]util.FastIntSet, 0, 1<<n)
+		for mask := 1<<n - 1; mask >= 0; mask-- {
+			var set util.FastIntSet
+			for i := range elems {
+				if mask&(1<<(n-1-i)) != 0 {
+					set.UnionWith(elems[i])
+				}
+			}
+			sets = append(sets, set)
+		}
+		return sets
+
+	default:
+		var sets []util.FastIntSet
+		for _, item := range gs.Exprs {
+			sets = append(sets, gb.buildItem(item)...)
+			if len(sets) > maxGroupingSets {
+				panic(pgerror.Newf(pgcode.StatementTooComplex,
+					"too many grouping sets present (maximum %d)", maxGroupingSets))
+			}
+		}
+		return sets
+	}
+}
+
+// buildElement builds the grouping columns for a grouping element, and returns
+// the ordinals of the columns. A tuple element is a composite element, which
+// is grouped on as a whole; the empty tuple is the empty grouping set.
+func (gb *groupingSetsBuilder) buildElement(e tree.Expr) util.FastIntSet {
+	g := gb.fromScope.groupby
+	exprStrs := gb.b.buildGrouping(e, gb.selects, gb.projectionsScope, gb.fromScope, g.aggInScope)
+	var set util.FastIntSet
+	for _, exprStr := range exprStrs {
+		ord, ok := gb.ordinals[exprStr]
+		if !ok {
+			// buildGrouping adds the new grouping columns in order.
+			ord = len(gb.ordinals)
+			gb.ordinals[exprStr] = ord
+		}
+		set.Add(ord)
+	}
+	return set
+}
+
+// hasEmptyGroupingSet returns true if any of the grouping sets has no columns.
+func (g *groupby) hasEmptyGroupingSet() bool {
+	for k := range g.groupingSets {
+		if g.groupingSets[k].Empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// constructGroupingSetsInput replaces the input of the aggregation with the
+// cross join of the input and the ordinals of the grouping sets, so that each
+// input row is aggregated once for each grouping set. The grouping expressions
+// which are nulled out for some of the sets are projected before the join.
+// See buildGroupingSets.
+//
+// If separateEmptySets is true, the ordinals of the empty grouping sets are
+// left out of the join, since those sets are aggregated separately (see
+// constructGroupingSetsAggregation). constructGroupingSetsInput returns false
+// if there are no sets left, in which case the input is not joined and there
+// is no grouping set column.
+//
+// Note that the join multiplies the number of input rows by the number of
+// grouping sets, which can be up to maxGroupingSets.
+func (b *Builder) constructGroupingSetsInput(fromScope *scope, separateEmptySets bool) bool {
+	g := fromScope.groupby
+
+	rowType := types.MakeTuple([]*types.T{types.Int})
+	rows := make(memo.ScalarListExpr, 0, len(g.groupingSets))
+	for k := range g.groupingSets {
+		if separateEmptySets && g.groupingSets[k].Empty() {
+			continue
+		}
+		rows = append(rows, b.factory.ConstructTuple(
+			memo.ScalarListExpr{b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(k)), types.Int)},
+			rowType,
+		))
+	}
+	if len(rows) == 0 {
+		// All the grouping sets are empty, so there are no grouping columns.
+		return false
+	}
+
+	input := fromScope.expr
+	projections := make(memo.ProjectionsExpr, 0, len(g.groupingSetsRawCols))
+	for i := range g.groupingSetsRawCols {
+		if col := &g.groupingSetsRawCols[i]; col.scalar != nil {
+			projections = append(projections, b.factory.ConstructProjectionsItem(col.scalar, col.id))
+		}
+	}
+	if len(projections) > 0 {
+		input = b.factory.ConstructProject(input, projections, input.Relational().OutputCols)
+	}
+
+	sets := b.factory.ConstructValues(rows, &memo.ValuesPrivate{
+		Cols: opt.ColList{g.groupingSetCol},
+		ID:   b.factory.Metadata().NextUniqueID(),
+	})
+	fromScope.expr = b.factory.ConstructInnerJoin(input, sets, memo.TrueFilter, memo.EmptyJoinPrivate)
+
+	// The grouping set column must be passed through the pre-projection.
+	g.aggInScope.extraCols = append(g.aggInScope.extraCols, scopeColumn{
+		name: scopeColName("grouping_set"),
+		typ:  types.Int,
+		id:   g.groupingSetCol,
+	})
+	return true
+}
+
+// constructGroupingSetsAggregation constructs the aggregation for grouping
+// sets which include one or more empty grouping sets. Unlike the other sets,
+// an empty grouping set produces a row even if the input is empty, so each of
+// them is aggregated by a separate ScalarGroupBy:
+//
+//   project
+//    └── with &1 (grouping_sets)
+//         ├── <pre-projection>
+//         └── union-all
+//              ├── group-by (grouping columns, grouping_set)
+//              │    └── with-scan &1
+//              └── project (NULL grouping columns, grouping_set = <empty set>)
+//                   └── scalar-group-by
+//                        └── select (grouping_set = <a non-empty set>)
+//                             └── with-scan &1
+//
+// The pre-projection has a row for each input row and non-empty set, so the
+// ScalarGroupBy only aggregates the rows of one of these sets. If all the
+// grouping sets are empty, the input is not joined with the sets, and the
+// GroupBy and the Select are omitted.
+//
+// Since the WithScans have new column IDs, the result is projected back onto
+// the grouping columns and the aggregate columns.
+func (b *Builder) constructGroupingSetsAggregation(
+	g *groupby, groupingColSet opt.ColSet, aggCols []scopeColumn, ordering opt.Ordering,
+) memo.RelExpr {
+	f := b.factory
+	md := f.Metadata()
+	input := g.aggInScope.expr
+	withID := f.Memo().NextWithID()
+	md.AddWithBinding(withID, input)
+
+	// scan returns a new WithScan of the pre-projection, and the mapping from
+	// the columns of the pre-projection to its columns.
+	inCols := input.Relational().OutputCols.ToList()
+	scan := func() (memo.RelExpr, opt.ColMap) {
+		var colMap opt.ColMap
+		outCols := make(opt.ColList, len(inCols))
+		for i, id := range inCols {
+			outCols[i] = b.copyColumn(id)
+			colMap.Set(int(id), int(outCols[i]))
+		}
+		return f.ConstructWithScan(&memo.WithScanPrivate{
+			With:    withID,
+			InCols:  inCols,
+			OutCols: outCols,
+			ID:      md.NextUniqueID(),
+		}), colMap
+	}
+
+	// aggregate returns the aggregations and the grouping private of a
+	// GroupBy or ScalarGroupBy over a WithScan, and the new IDs of the
+	// aggregate columns. As in constructGroupBy, the aggregate columns are
+	// deduplicated.
+	aggregate := func(
+		colMap opt.ColMap, groupingCols opt.ColSet,
+	) (memo.AggregationsExpr, *memo.GroupingPrivate, opt.ColList) {
+		aggs := make(memo.AggregationsExpr, 0, len(aggCols))
+		aggOutCols := make(opt.ColList, 0, len(aggCols))
+		var colSet opt.ColSet
+		for i := range aggCols {
+			if id, scalar := aggCols[i].id, aggCols[i].scalar; !colSet.Contains(id) {
+				if scalar == nil {
+					panic(errors.AssertionFailedf("variable as aggregation"))
+				}
+				newID := b.copyColumn(id)
+				aggs = append(aggs, f.ConstructAggregationsItem(f.CustomFuncs().RemapCols(scalar, colMap), newID))
+				aggOutCols = append(aggOutCols, newID)
+				colSet.Add(id)
+			}
+		}
+		newOrdering := make(opt.Ordering, len(ordering))
+		for i := range ordering {
+			newID, _ := colMap.Get(int(ordering[i].ID()))
+			newOrdering[i] = opt.MakeOrderingColumn(opt.ColumnID(newID), ordering[i].Descending())
+		}
+		private := &memo.GroupingPrivate{GroupingCols: groupingCols}
+		private.Ordering.FromOrderingWithOptCols(newOrdering, groupingCols)
+		return aggs, private, aggOutCols
+	}
+
+	// Each branch of the union produces the grouping columns and then the
+	// aggregate columns.
+	groupingColList := groupingColSet.ToList()
+	outCols := append(opt.ColList(nil), groupingColList...)
+	var aggColSet opt.ColSet
+	for i := range aggCols {
+		if id := aggCols[i].id; !aggColSet.Contains(id) {
+			outCols = append(outCols, id)
+			aggColSet.Add(id)
+		}
+	}
+
+	var result memo.RelExpr
+	var resultCols opt.ColList
+	addBranch := func(expr memo.RelExpr, cols opt.ColList) {
+		if result == nil {
+			result, resultCols = expr, cols
+			return
+		}
+		unionCols := make(opt.ColList, len(outCols))
+		for i := range unionCols {
+			unionCols[i] = b.copyColumn(outCols[i])
+		}
+		result = f.ConstructUnionAll(result, expr, &memo.SetPrivate{
+			LeftCols:  resultCols,
+			RightCols: cols,
+			OutCols:   unionCols,
+		})
+		resultCols = unionCols
+	}
+
+	// Aggregate the non-empty grouping sets.
+	nonEmptySet := -1
+	for k := range g.groupingSets {
+		if !g.groupingSets[k].Empty() {
+			nonEmptySet = k
+			break
+		}
+	}
+	if nonEmptySet != -1 {
+		in, colMap := scan()
+		var groupingCols opt.ColSet
+		cols := make(opt.ColList, 0, len(outCols))
+		for _, id := range groupingColList {
+			newID, _ := colMap.Get(int(id))
+			groupingCols.Add(opt.ColumnID(newID))
+			cols = append(cols, opt.ColumnID(newID))
+		}
+		aggs, private, aggOutCols := aggregate(colMap, groupingCols)
+		addBranch(f.ConstructGroupBy(in, aggs, private), append(cols, aggOutCols...))
+	}
+
+	// Aggregate each of the empty grouping sets.
+	for k := range g.groupingSets {
+		if !g.groupingSets[k].Empty() {
+			continue
+		}
+		in, colMap := scan()
+		if nonEmptySet != -1 {
+			setCol, _ := colMap.Get(int(g.groupingSetCol))
+			in = f.ConstructSelect(in, memo.FiltersExpr{f.ConstructFiltersItem(
+				f.ConstructEq(
+					f.ConstructVariable(opt.ColumnID(setCol)),
+					f.ConstructConstVal(tree.NewDInt(tree.DInt(nonEmptySet)), types.Int),
+				),
+			)})
+		}
+		aggs, private, aggOutCols := aggregate(colMap, opt.ColSet{})
+		expr := f.ConstructScalarGroupBy(in, aggs, private)
+
+		// The grouping columns are NULL for the empty grouping set.
+		cols := make(opt.ColList, 0, len(outCols))
+		projections := make(memo.ProjectionsExpr, 0, len(groupingColList))
+		for _, id := range groupingColList {
+			var value opt.ScalarExpr
+			if id == g.groupingSetCol {
+				value = f.ConstructConstVal(tree.NewDInt(tree.DInt(k)), types.Int)
+			} else {
+				value = f.ConstructNull(md.ColumnMeta(id).Type)
+			}
+			newID := b.copyColumn(id)
+			projections = append(projections, f.ConstructProjectionsItem(value, newID))
+			cols = append(cols, newID)
+		}
+		if len(projections) > 0 {
+			expr = f.ConstructProject(expr, projections, aggOutCols.ToSet())
+		}
+		addBranch(expr, append(cols, aggOutCols...))
+	}
+
+	result = f.ConstructWith(input, result, &memo.WithPrivate{ID: withID, Name: "grouping_sets"})
+	projections := make(memo.ProjectionsExpr, len(outCols))
+	for i := range outCols {
+		projections[i] = f.ConstructProjectionsItem(f.ConstructVariable(resultCols[i]), outCols[i])
+	}
+	return f.ConstructProject(result, projections, opt.ColSet{})
+}
+
+// copyColumn adds a new column to the metadata with the same alias and type as
+// the given column.
+func (b *Builder) copyColumn(id opt.ColumnID) opt.ColumnID {
+	md := b.factory.Metadata()
+	col := md.ColumnMeta(id)
+	return md.AddColumn(col.Alias, col.Type)
+}
+
+// buildGroupingFunc builds a call to the GROUPING function. It returns a bit
+// mask of the arguments which are not part of the grouping set of each row;
+// the last argument corresponds to the least significant bit. The arguments
+// must be grouping expressions, and are not evaluated.
+func (b *Builder) buildGroupingFunc(
+	f *tree.FuncExpr, inScope, outScope *scope, outCol *scopeColumn,
+) opt.ScalarExpr {
+	g := inScope.groupby
+	if g == nil || g.groupStrs == nil {
+		panic(errGroupingArgs)
+	}
+	if g.buildingGroupingCols {
+		panic(pgerror.New(pgcode.Grouping, "GROUPING is not allowed in GROUP BY"))
+	}
+	if inScope.inAgg {
+		panic(pgerror.New(pgcode.Grouping, "aggregate function calls cannot contain GROUPING"))
+	}
+	if len(f.Exprs) > maxGroupingArgs {
+		panic(pgerror.Newf(pgcode.TooManyArguments,
+			"GROUPING must have fewer than %d arguments", maxGroupingArgs+1))
+	}
+
+	argCols := make([]opt.ColumnID, len(f.Exprs))
+	for i, e := range f.Exprs {
+		col, ok := g.groupStrs[symbolicExprStr(e.(tree.TypedExpr))]
+		if !ok {
+			panic(errGroupingArgs)
+		}
+		argCols[i] = col.id
+	}
+
+	var out opt.ScalarExpr
+	if g.groupingSets == nil {
+		// Without grouping sets, every grouping expression is part of the single
+		// grouping set.
+		out = b.factory.ConstructConstVal(tree.NewDInt(0), types.Int)
+	} else {
+		whens := make(memo.ScalarListExpr, len(g.groupingSets))
+		for k, set := range g.groupingSets {
+			var mask int64
+			for i, id := range argCols {
+				if !set.Contains(id) {
+					mask |= 1 << (len(argCols) - 1 - i)
+				}
+			}
+			whens[k] = b.factory.ConstructWhen(
+				b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(k)), types.Int),
+				b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(mask)), types.Int),
+			)
+		}
+		out = b.factory.ConstructCase(
+			b.factory.ConstructVariable(g.groupingSetCol), whens, b.factory.ConstructNull(types.Int),
+		)
+	}
+	return b.finishBuildScalar(f, out, inScope, outScope, outCol)
+}
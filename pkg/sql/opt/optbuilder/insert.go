@@ -25,7 +25,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
-	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
 )
 
@@ -222,6 +221,12 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	} else {
 		mb.init(b, "insert", tab, alias)
 	}
+	if ins.OnConflict != nil {
+		mb.checkNoTriggersForUpsert()
+	}
+	// The input of the body of a function executed by an AFTER trigger is
+	// joined with the arguments of the function.
+	mb.triggerArgs, b.triggerArgs = b.triggerArgs, nil
 
 	// Compute target columns in two cases:
 	//
@@ -566,6 +571,7 @@ func (mb *mutationBuilder) buildInputForInsert(inScope *scope, inputRows *tree.S
 			Cols: opt.ColList{},
 			ID:   mb.md.NextUniqueID(),
 		})
+		mb.joinTriggerArgs()
 		return
 	}
 
@@ -597,6 +603,7 @@ func (mb *mutationBuilder) buildInputForInsert(inScope *scope, inputRows *tree.S
 	}
 
 	mb.outScope = mb.b.buildStmt(inputRows, desiredTypes, inScope)
+	mb.joinTriggerArgs()
 
 	if len(mb.targetColList) != 0 {
 		// Target columns already exist, so ensure that the number of input
@@ -670,6 +677,9 @@ func (mb *mutationBuilder) addSynthesizedColsForInsert() {
 // buildInsert constructs an Insert operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
+	// Skip the rows rejected by BEFORE triggers.
+	mb.buildBeforeTriggers(tree.TriggerEventInsert)
+
 	// Disambiguate names so that references in any expressions, such as a
 	// check constraint, refer to the correct columns.
 	mb.disambiguateColumns()
@@ -682,8 +692,12 @@ func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
 
 	mb.buildUniqueChecksForInsert()
 
+	mb.buildExclusionChecks(false /* isUpdate */)
+
 	mb.buildFKChecksForInsert()
 
+	mb.buildAfterTriggers(tree.TriggerEventInsert)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructInsert(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
@@ -739,18 +753,17 @@ func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tr
 // columns to be a "canary column" that can be tested to determine whether a
 // given insert row conflicts with an existing row in the table. If it is null,
 // then there is no conflict.
+//
+// If there are multiple arbiters, the insert rows are left-joined to the
+// target table once per arbiter, and the existing row that is updated is the
+// one found by the first arbiter with a conflict. See
+// buildLeftJoinsForUpsertArbiters for more details.
 func (mb *mutationBuilder) buildInputForUpsert(
 	inScope *scope, onConflict *tree.OnConflict, whereClause *tree.Where,
 ) {
 	// Determine the set of arbiter indexes and constraints to use to check for
 	// conflicts.
 	mb.arbiters = mb.findArbiters(onConflict)
-	// TODO(mgartner): Add support for multiple arbiter indexes or constraints,
-	//  similar to buildInputForDoNothing.
-	if mb.arbiters.Len() > 1 {
-		panic(unimplemented.NewWithIssue(53170,
-			"there are multiple unique or exclusion constraints matching the ON CONFLICT specification"))
-	}
 
 	insertColScope := mb.outScope.replace()
 	insertColScope.appendColumnsFromScope(mb.outScope)
@@ -758,8 +771,7 @@ func (mb *mutationBuilder) buildInputForUpsert(
 	// Ignore any ordering requested by the input.
 	mb.outScope.ordering = nil
 
-	// Create an UpsertDistinctOn and a left-join for the single arbiter.
-	var canaryCol *scopeColumn
+	// Create an UpsertDistinctOn for each arbiter.
 	mb.arbiters.ForEach(func(name string, conflictOrds util.FastIntSet, pred tree.Expr, canaryOrd int) {
 		// If the arbiter has a partial predicate, project a new column that
 		// allows the UpsertDistinctOn to only de-duplicate insert rows that
@@ -781,25 +793,32 @@ func (mb *mutationBuilder) buildInputForUpsert(
 		// distinct groupings, the internal ordering is meaningless (and can
 		// trigger a misleading error in buildDistinctOn if present).
 		mb.buildDistinctOnForArbiter(insertColScope, conflictOrds, partialDistinctCol, duplicateUpsertErrText)
+	})
 
-		// Re-alias all INSERT columns so that they are accessible as if they
-		// were part of a special data source named "crdb_internal.excluded".
-		for i := range mb.outScope.cols {
-			mb.outScope.cols[i].table = excludedTableName
-		}
+	// Re-alias all INSERT columns so that they are accessible as if they were
+	// part of a special data source named "crdb_internal.excluded".
+	for i := range mb.outScope.cols {
+		mb.outScope.cols[i].table = excludedTableName
+	}
 
-		// Create a left-join for the arbiter.
-		mb.buildLeftJoinForUpsertArbiter(
-			inScope, conflictOrds, pred,
-		)
+	var canaryCol *scopeColumn
+	if mb.arbiters.Len() > 1 {
+		canaryCol = mb.buildLeftJoinsForUpsertArbiters(inScope)
+	} else {
+		// Create a left-join for the single arbiter.
+		mb.arbiters.ForEach(func(name string, conflictOrds util.FastIntSet, pred tree.Expr, canaryOrd int) {
+			mb.buildLeftJoinForUpsertArbiter(
+				inScope, conflictOrds, pred,
+			)
 
-		// Record a not-null "canary" column. After the left-join, this will be
-		// null if no conflict has been detected, or not null otherwise. At
-		// least one not-null column must exist, since primary key columns are
-		// not-null.
-		canaryCol = &mb.fetchScope.cols[canaryOrd]
-		mb.canaryColID = canaryCol.id
-	})
+			// Record a not-null "canary" column. After the left-join, this will be
+			// null if no conflict has been detected, or not null otherwise. At
+			// least one not-null column must exist, since primary key columns are
+			// not-null.
+			canaryCol = &mb.fetchScope.cols[canaryOrd]
+		})
+	}
+	mb.canaryColID = canaryCol.id
 
 	// Add a filter from the WHERE clause if one exists.
 	if whereClause != nil {
@@ -905,6 +924,8 @@ func (mb *mutationBuilder) buildUpsert(returning tree.ReturningExprs) {
 
 	mb.buildUniqueChecksForUpsert()
 
+	mb.buildExclusionChecks(false /* isUpdate */)
+
 	mb.buildFKChecksForUpsert()
 
 	private := mb.makeMutationPrivate(returning != nil)
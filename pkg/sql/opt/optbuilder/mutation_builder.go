@@ -182,10 +182,15 @@ type mutationBuilder struct {
 	// checks.
 	withID opt.WithID
 
+	// triggerArgs is set if the mutation is the body of a function executed by
+	// an AFTER trigger; its rows hold the arguments of the function. The input
+	// of the mutation is joined with these rows. See joinTriggerArgs.
+	triggerArgs *scope
+
 	// extraAccessibleCols stores all the columns that are available to the
 	// mutation that are not part of the target table. This is useful for
-	// UPDATE ... FROM queries, as the columns from the FROM tables must be
-	// made accessible to the RETURNING clause.
+	// UPDATE ... FROM and DELETE ... USING queries, as the columns from the
+	// FROM or USING tables must be made accessible to the RETURNING clause.
 	extraAccessibleCols []scopeColumn
 
 	// fkCheckHelper is used to prevent allocating the helper separately.
@@ -348,27 +353,32 @@ func (mb *mutationBuilder) buildInputForUpdate(
 	// Build a distinct on to ensure there is at most one row in the joined output
 	// for every row in the table.
 	if fromClausePresent {
-		var pkCols opt.ColSet
-
-		// We need to ensure that the join has a maximum of one row for every row
-		// in the table and we ensure this by constructing a distinct on the primary
-		// key columns.
-		primaryIndex := mb.tab.Index(cat.PrimaryIndex)
-		for i := 0; i < primaryIndex.KeyColumnCount(); i++ {
-			// If the primary key column is hidden, then we don't need to use it
-			// for the distinct on.
-			// TODO(radu): this logic seems fragile, is it assuming that only an
-			// implicit `rowid` column can be a hidden PK column?
-			if col := primaryIndex.Column(i); col.Visibility() != cat.Hidden {
-				pkCols.Add(mb.fetchColIDs[col.Ordinal()])
-			}
-		}
+		mb.buildDistinctOnPrimaryKey()
+	}
+}
 
-		if !pkCols.Empty() {
-			mb.outScope = mb.b.buildDistinctOn(
-				pkCols, mb.outScope, false /* nullsAreDistinct */, "" /* errorOnDup */)
+// buildDistinctOnPrimaryKey wraps the input expression in a distinct on the
+// primary key columns of the target table. It is used when the target table
+// is joined with other tables (UPDATE ... FROM and DELETE ... USING) to ensure
+// that the join has a maximum of one row for every row in the table.
+func (mb *mutationBuilder) buildDistinctOnPrimaryKey() {
+	var pkCols opt.ColSet
+
+	primaryIndex := mb.tab.Index(cat.PrimaryIndex)
+	for i := 0; i < primaryIndex.KeyColumnCount(); i++ {
+		// If the primary key column is hidden, then we don't need to use it
+		// for the distinct on.
+		// TODO(radu): this logic seems fragile, is it assuming that only an
+		// implicit `rowid` column can be a hidden PK column?
+		if col := primaryIndex.Column(i); col.Visibility() != cat.Hidden {
+			pkCols.Add(mb.fetchColIDs[col.Ordinal()])
 		}
 	}
+
+	if !pkCols.Empty() {
+		mb.outScope = mb.b.buildDistinctOn(
+			pkCols, mb.outScope, false /* nullsAreDistinct */, "" /* errorOnDup */)
+	}
 }
 
 // buildInputForDelete constructs a Select expression from the fields in
@@ -376,14 +386,28 @@ func (mb *mutationBuilder) buildInputForUpdate(
 //
 //   SELECT <cols>
 //   FROM <table>
+//   [, <using-tables>]
 //   WHERE <where>
 //   ORDER BY <order-by>
 //   LIMIT <limit>
 //
 // All columns from the table to update are added to fetchColList.
+// If a USING clause is defined, we build out each of the table
+// expressions required and JOIN them together with the target table, in
+// the same way as the FROM clause of an UPDATE (see buildInputForUpdate).
+// A row of the target table is deleted if it joins with at least one row
+// of the USING tables; a DISTINCT ON the primary key ensures that each
+// row is only deleted once. The columns of the USING tables are stored
+// in the mutation builder so they can be made accessible to the
+// RETURNING clause.
 // TODO(andyk): Do needed column analysis to project fewer columns if possible.
 func (mb *mutationBuilder) buildInputForDelete(
-	inScope *scope, texpr tree.TableExpr, where *tree.Where, limit *tree.Limit, orderBy tree.OrderBy,
+	inScope *scope,
+	texpr tree.TableExpr,
+	using tree.TableExprs,
+	where *tree.Where,
+	limit *tree.Limit,
+	orderBy tree.OrderBy,
 ) {
 	var indexFlags *tree.IndexFlags
 	if source, ok := texpr.(*tree.AliasedTableExpr); ok && source.IndexFlags != nil {
@@ -411,7 +435,37 @@ func (mb *mutationBuilder) buildInputForDelete(
 		noRowLocking,
 		inScope,
 	)
-	mb.outScope = mb.fetchScope
+
+	// Set list of columns that will be fetched by the input expression.
+	mb.setFetchColIDs(mb.fetchScope.cols)
+
+	// If there is a USING clause present, we must join all the tables
+	// together with the table being deleted from.
+	usingClausePresent := len(using) > 0
+	if usingClausePresent {
+		usingScope := mb.b.buildFromTables(using, noRowLocking, inScope)
+
+		// Check that the same table name is not used multiple times.
+		mb.b.validateJoinTableNames(mb.fetchScope, usingScope)
+
+		// The USING table columns can be accessed by the RETURNING clause of the
+		// query and so we have to make them accessible.
+		mb.extraAccessibleCols = usingScope.cols
+
+		// Add the columns in the USING scope.
+		// We create a new scope so that fetchScope is not modified. It will be
+		// used later to build partial index predicate expressions, and we do
+		// not want ambiguities with column names in the USING clause.
+		mb.outScope = mb.fetchScope.replace()
+		mb.outScope.appendColumnsFromScope(mb.fetchScope)
+		mb.outScope.appendColumnsFromScope(usingScope)
+
+		left := mb.fetchScope.expr
+		right := usingScope.expr
+		mb.outScope.expr = mb.b.factory.ConstructInnerJoin(left, right, memo.TrueFilter, memo.EmptyJoinPrivate)
+	} else {
+		mb.outScope = mb.fetchScope
+	}
 
 	// WHERE
 	mb.b.buildWhere(where, mb.outScope)
@@ -430,8 +484,11 @@ func (mb *mutationBuilder) buildInputForDelete(
 
 	mb.outScope = projectionsScope
 
-	// Set list of columns that will be fetched by the input expression.
-	mb.setFetchColIDs(mb.outScope.cols)
+	// Build a distinct on to ensure there is at most one row in the joined output
+	// for every row in the table.
+	if usingClausePresent {
+		mb.buildDistinctOnPrimaryKey()
+	}
 }
 
 // addTargetColsByName adds one target column for each of the names in the given
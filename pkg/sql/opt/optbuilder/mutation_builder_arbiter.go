@@ -81,6 +81,11 @@ func (mb *mutationBuilder) findArbiters(onConflict *tree.OnConflict) arbiterSet
 				if _, partial := constraint.Predicate(); partial {
 					panic(partialIndexArbiterError(onConflict, mb.tab.Name()))
 				}
+				if constraint.Deferrability() != tree.NotDeferrableConstraint {
+					panic(pgerror.New(pgcode.FeatureNotSupported,
+						"ON CONFLICT does not support deferrable unique constraints as arbiters",
+					))
+				}
 				return makeSingleUniqueConstraintArbiterSet(mb, i)
 			}
 		}
@@ -166,7 +171,10 @@ func (mb *mutationBuilder) inferArbitersFromConflictOrds(
 			}
 		}
 		for uc, ucCount := 0, mb.tab.UniqueCount(); uc < ucCount; uc++ {
-			if mb.tab.Unique(uc).WithoutIndex() {
+			// Deferrable unique constraints are never arbiters, since they may be
+			// violated until the end of the transaction.
+			if u := mb.tab.Unique(uc); u.WithoutIndex() &&
+				u.Deferrability() == tree.NotDeferrableConstraint {
 				arbiters.AddUniqueConstraint(uc)
 			}
 		}
@@ -224,6 +232,10 @@ func (mb *mutationBuilder) inferArbitersFromConflictOrds(
 			// Unique constraints with an index were handled above.
 			continue
 		}
+		if uniqueConstraint.Deferrability() != tree.NotDeferrableConstraint {
+			// Deferrable unique constraints cannot be arbiters.
+			continue
+		}
 
 		// Determine whether the conflict columns match the columns in the
 		// unique constraint. If not, the constraint cannot be an arbiter. We
@@ -437,6 +449,101 @@ func (mb *mutationBuilder) buildLeftJoinForUpsertArbiter(
 	)
 }
 
+// buildLeftJoinsForUpsertArbiters builds the input of an INSERT ON CONFLICT DO
+// UPDATE mutation with multiple arbiter indexes or constraints. It left-joins
+// the insert rows to the target table once for each arbiter, and then merges
+// the fetched columns so that each insert row is associated with the existing
+// row found by the first arbiter that detected a conflict. For example:
+//
+//   CREATE TABLE t (a INT PRIMARY KEY, b INT, c INT)
+//   CREATE UNIQUE INDEX i1 ON t (b) WHERE c > 0
+//   CREATE UNIQUE INDEX i2 ON t (b) WHERE c < 10
+//   INSERT INTO t VALUES (1, 2, 3) ON CONFLICT (b) WHERE c > 0 AND c < 10
+//   DO UPDATE SET c = 10
+//
+// Then an input expression roughly equivalent to this would be built:
+//
+//   SELECT
+//     ins_a, ins_b, ins_c,
+//     CASE WHEN f1.a IS NOT NULL THEN f1.a WHEN f2.a IS NOT NULL THEN f2.a END AS a,
+//     CASE WHEN f1.a IS NOT NULL THEN f1.b WHEN f2.a IS NOT NULL THEN f2.b END AS b,
+//     CASE WHEN f1.a IS NOT NULL THEN f1.c WHEN f2.a IS NOT NULL THEN f2.c END AS c
+//   FROM (VALUES (1, 2, 3)) AS ins(ins_a, ins_b, ins_c)
+//   LEFT JOIN t AS f1 ON ins_b = f1.b AND ins_c > 0 AND f1.c > 0
+//   LEFT JOIN t AS f2 ON ins_b = f2.b AND ins_c < 10 AND f2.c < 10
+//
+// The merged columns become the fetch columns of the mutation, and the merged
+// primary key column is the canary column, which is returned.
+//
+// Different insert rows can conflict with the same existing row through
+// different arbiters, for example if each row only satisfies the predicate of
+// one of the partial indexes, so an UpsertDistinctOn on the primary key of the merged
+// columns ensures that no existing row is updated more than once.
+func (mb *mutationBuilder) buildLeftJoinsForUpsertArbiters(inScope *scope) *scopeColumn {
+	insertScope := mb.outScope
+	input := mb.outScope.expr
+
+	// Build a left-join for each arbiter. Each left-join is built in a scope
+	// that contains only the insert columns, so that the partial predicates of
+	// the arbiters never refer to columns fetched for another arbiter.
+	var fetchScopes []*scope
+	var canaryOrds []int
+	mb.arbiters.ForEach(func(name string, conflictOrds util.FastIntSet, pred tree.Expr, canaryOrd int) {
+		mb.outScope = insertScope.replace()
+		mb.outScope.appendColumnsFromScope(insertScope)
+		mb.outScope.expr = input
+		mb.buildLeftJoinForUpsertArbiter(inScope, conflictOrds, pred)
+		input = mb.outScope.expr
+		fetchScopes = append(fetchScopes, mb.fetchScope)
+		canaryOrds = append(canaryOrds, canaryOrd)
+	})
+
+	// Merge the fetched columns with CASE expressions that select the columns
+	// of the first arbiter for which a conflict was detected.
+	projectionScope := insertScope.replace()
+	projectionScope.appendColumnsFromScope(insertScope)
+	mergedCols := make([]scopeColumn, len(fetchScopes[0].cols))
+	for i := range mergedCols {
+		whens := make(memo.ScalarListExpr, len(fetchScopes))
+		for j, fetchScope := range fetchScopes {
+			whens[j] = mb.b.factory.ConstructWhen(
+				mb.b.factory.ConstructIsNot(
+					mb.b.factory.ConstructVariable(fetchScope.cols[canaryOrds[j]].id),
+					memo.NullSingleton,
+				),
+				mb.b.factory.ConstructVariable(fetchScope.cols[i].id),
+			)
+		}
+		col := fetchScopes[0].cols[i]
+		caseExpr := mb.b.factory.ConstructCase(
+			memo.TrueSingleton, whens, mb.b.factory.ConstructNull(col.typ),
+		)
+		mb.b.populateSynthesizedColumn(&col, caseExpr)
+		mergedCols[i] = col
+	}
+	projectionScope.appendColumns(mergedCols)
+	mb.outScope.expr = input
+	mb.b.constructProjectForScope(mb.outScope, projectionScope)
+	mb.outScope = projectionScope
+
+	mb.fetchScope = mb.b.allocScope()
+	mb.fetchScope.appendColumns(mergedCols)
+	mb.setFetchColIDs(mergedCols)
+
+	// Ensure that no existing row is updated more than once. Rows without a
+	// conflict have a NULL primary key and are never de-duplicated.
+	primaryIndex := mb.tab.Index(cat.PrimaryIndex)
+	var pkCols opt.ColSet
+	for i, n := 0, primaryIndex.KeyColumnCount(); i < n; i++ {
+		pkCols.Add(mergedCols[primaryIndex.Column(i).Ordinal()].id)
+	}
+	mb.outScope = mb.b.buildDistinctOn(
+		pkCols, mb.outScope, true /* nullsAreDistinct */, duplicateUpsertErrText,
+	)
+
+	return &mb.fetchScope.cols[findNotNullIndexCol(primaryIndex)]
+}
+
 // buildDistinctOnForDoNothingArbiter adds an UpsertDistinctOn operator for a
 // single arbiter index or constraint.
 //
@@ -0,0 +1,173 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// buildExclusionChecks builds check queries that enforce the EXCLUDE
+// constraints of the table. If isUpdate is true, checks are only built for
+// constraints that reference an updated column.
+//
+// Like uniqueness checks, the checks are run after the mutation and consist of
+// queries that only return rows if a constraint is violated.
+func (mb *mutationBuilder) buildExclusionChecks(isUpdate bool) {
+	for i, n := 0, mb.tab.ExclusionConstraintCount(); i < n; i++ {
+		ec := mb.tab.ExclusionConstraint(i)
+		var pred tree.Expr
+		if ec.Predicate != "" {
+			var err error
+			pred, err = parser.ParseExpr(ec.Predicate)
+			if err != nil {
+				panic(err)
+			}
+		}
+		if isUpdate && !mb.exclusionColsUpdated(&ec, pred) {
+			continue
+		}
+		if check, ok := mb.buildExclusionCheck(i, &ec, pred); ok {
+			mb.uniqueChecks = append(mb.uniqueChecks, check)
+		}
+	}
+}
+
+// exclusionColsUpdated returns true if any of the columns of the given
+// exclusion constraint, or any of the columns referenced by its predicate, are
+// being updated.
+func (mb *mutationBuilder) exclusionColsUpdated(
+	ec *cat.ExclusionConstraint, pred tree.Expr,
+) bool {
+	for _, ord := range ec.ColumnOrdinals {
+		if mb.updateColIDs[ord] != 0 {
+			return true
+		}
+	}
+	if pred != nil {
+		typedPred := mb.fetchScope.resolveAndRequireType(pred, types.Bool)
+		var predCols opt.ColSet
+		mb.b.buildScalar(typedPred, mb.fetchScope, nil, nil, &predCols)
+		for colID, ok := predCols.Next(0); ok; colID, ok = predCols.Next(colID + 1) {
+			ord := mb.md.ColumnMeta(colID).Table.ColumnOrdinal(colID)
+			if mb.updateColIDs[ord] != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildExclusionCheck creates a check for the exclusion constraint with the
+// given ordinal, for rows which are added to the table or updated. The check
+// is a semi-join between the new rows and the existing rows of the table:
+//
+//   (new_a op_a existing_a) AND (new_b op_b existing_b) AND ... AND
+//   (new_pk1 != existing_pk1 OR new_pk2 != existing_pk2 OR ...)
+//
+// No index is built for the constraint, so unless the optimizer can use an
+// existing index on the columns compared with =, the check scans the whole
+// table for each mutation statement.
+//
+// Returns false if no check is needed because one of the new values of the
+// constraint columns is known to always be NULL.
+func (mb *mutationBuilder) buildExclusionCheck(
+	ordinal int, ec *cat.ExclusionConstraint, pred tree.Expr,
+) (_ memo.UniqueChecksItem, ok bool) {
+	f := mb.b.factory
+
+	for _, ord := range ec.ColumnOrdinals {
+		// A NULL never conflicts with anything, so the check is not needed.
+		if memo.OutputColumnIsAlwaysNull(mb.outScope.expr, mb.mapToReturnColID(ord)) {
+			return memo.UniqueChecksItem{}, false
+		}
+	}
+
+	// Build the scan of the existing rows, which is the right side of the
+	// semi-join.
+	tabMeta := mb.b.addTable(mb.tab, tree.NewUnqualifiedTableName(mb.tab.Name()))
+	scanOrdinals := tableOrdinals(tabMeta.Table, columnKinds{
+		includeMutations: false,
+		includeSystem:    false,
+		includeInverted:  false,
+	})
+	scanScope := mb.b.buildScan(
+		tabMeta,
+		scanOrdinals,
+		&tree.IndexFlags{IgnoreUniqueWithoutIndexKeys: true},
+		noRowLocking,
+		mb.b.allocScope(),
+	)
+
+	withScanScope, _ := mb.buildCheckInputScan(
+		checkInputScanNewVals, scanOrdinals, false, /* isFK */
+	)
+
+	filters := make(memo.FiltersExpr, 0, len(ec.ColumnOrdinals)+3)
+	for i, ord := range ec.ColumnOrdinals {
+		newCol, existingCol := &withScanScope.cols[ord], &scanScope.cols[ord]
+		cmp := tree.NewTypedComparisonExpr(ec.Operators[i], newCol, existingCol)
+		filters = append(filters, f.ConstructFiltersItem(
+			mb.b.constructComparison(
+				cmp, f.ConstructVariable(newCol.id), f.ConstructVariable(existingCol.id),
+			),
+		))
+	}
+
+	// If the constraint is partial, only the rows on both sides that satisfy
+	// the predicate can conflict.
+	if pred != nil {
+		typedPred := withScanScope.resolveAndRequireType(pred, types.Bool)
+		withScanPred := mb.b.buildScalar(typedPred, withScanScope, nil, nil, nil)
+		filters = append(filters, f.ConstructFiltersItem(withScanPred))
+
+		typedPred = scanScope.resolveAndRequireType(pred, types.Bool)
+		scanPred := mb.b.buildScalar(typedPred, scanScope, nil, nil, nil)
+		filters = append(filters, f.ConstructFiltersItem(scanPred))
+	}
+
+	// Prevent rows from conflicting with themselves.
+	var pkFilter opt.ScalarExpr
+	primaryOrds := getIndexLaxKeyOrdinals(mb.tab.Index(cat.PrimaryIndex))
+	for i, ok := primaryOrds.Next(0); ok; i, ok = primaryOrds.Next(i + 1) {
+		pkFilterLocal := f.ConstructNe(
+			f.ConstructVariable(withScanScope.cols[i].id),
+			f.ConstructVariable(scanScope.cols[i].id),
+		)
+		if pkFilter == nil {
+			pkFilter = pkFilterLocal
+		} else {
+			pkFilter = f.ConstructOr(pkFilter, pkFilterLocal)
+		}
+	}
+	filters = append(filters, f.ConstructFiltersItem(pkFilter))
+
+	semiJoin := f.ConstructSemiJoin(withScanScope.expr, scanScope.expr, filters, memo.EmptyJoinPrivate)
+
+	// Collect the key columns that will be shown in the error message.
+	keyCols := make(opt.ColList, len(ec.ColumnOrdinals))
+	for i, ord := range ec.ColumnOrdinals {
+		keyCols[i] = withScanScope.cols[ord].id
+	}
+	project := f.ConstructProject(semiJoin, nil /* projections */, keyCols.ToSet())
+
+	return f.ConstructUniqueChecksItem(project, &memo.UniqueChecksItemPrivate{
+		Table:        mb.tabID,
+		CheckOrdinal: ordinal,
+		KeyCols:      keyCols,
+		OpName:       mb.opName,
+		Exclusion:    true,
+	}), true
+}
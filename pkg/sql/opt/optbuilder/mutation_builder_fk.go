@@ -165,7 +165,9 @@ func (mb *mutationBuilder) buildFKChecksAndCascadesForDelete() {
 		}
 
 		withScanScope, _ := mb.buildCheckInputScan(checkInputScanFetchedVals, h.tabOrdinals, true /* isFK */)
-		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(withScanScope.expr, withScanScope.colList()))
+		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(
+			withScanScope.expr, withScanScope.colList(), h.fk.DeleteReferenceAction(),
+		))
 	}
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
@@ -346,7 +348,9 @@ func (mb *mutationBuilder) buildFKChecksForUpdate() {
 			},
 		)
 
-		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(deletedRows, colsForOldRow))
+		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(
+			deletedRows, colsForOldRow, h.fk.UpdateReferenceAction(),
+		))
 	}
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
@@ -449,7 +453,9 @@ func (mb *mutationBuilder) buildFKChecksForUpsert() {
 				OutCols:   colsForOldRow,
 			},
 		)
-		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(deletedRows, oldRowsScope.colList()))
+		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(
+			deletedRows, oldRowsScope.colList(), h.fk.UpdateReferenceAction(),
+		))
 	}
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
@@ -735,15 +741,18 @@ func (h *fkCheckHelper) buildInsertionCheck() memo.FKChecksItem {
 		FKOrdinal:       h.fkOrdinal,
 		KeyCols:         withScanScope.colList(),
 		OpName:          h.mb.opName,
+		Deferrable:      h.fk.Deferrability() != tree.NotDeferrableConstraint,
 	})
 }
 
 // buildDeletionCheck creates a FK check for rows which are removed from a
 // table. deletedRows is used as the input to the deletion check, and deleteCols
 // is a list of the columns for the rows being deleted, containing values for
-// the referenced FK columns in the table we are mutating.
+// the referenced FK columns in the table we are mutating. action is the
+// referential action of the FK for the mutation; the check can only be
+// deferred if it is NO ACTION.
 func (h *fkCheckHelper) buildDeletionCheck(
-	deletedRows memo.RelExpr, deleteCols opt.ColList,
+	deletedRows memo.RelExpr, deleteCols opt.ColList, action tree.ReferenceAction,
 ) memo.FKChecksItem {
 	// Build a semi join, with the referenced FK columns on the left and the
 	// origin columns on the right.
@@ -777,5 +786,6 @@ func (h *fkCheckHelper) buildDeletionCheck(
 		FKOrdinal:       h.fkOrdinal,
 		KeyCols:         deleteCols,
 		OpName:          h.mb.opName,
+		Deferrable:      h.fk.Deferrability() != tree.NotDeferrableConstraint && action == tree.NoAction,
 	})
 }
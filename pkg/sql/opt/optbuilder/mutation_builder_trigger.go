@@ -0,0 +1,463 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains the code which builds the row-level triggers of the
+// target table of a mutation.
+//
+// -- BEFORE triggers --
+//
+// A BEFORE trigger executes a function returning BOOL for each row which is
+// about to be modified; the row is skipped unless the function returns true.
+// Unlike in Postgres, the function cannot change the values of the row. The
+// call is built as a filter on the mutation input, after all the columns of the
+// new row have been synthesized:
+//
+//   update t
+//    └── select
+//         ├── <mutation input>
+//         └── filters
+//              └── CASE WHEN <when> THEN f(<args>) ELSE true END
+//
+// -- AFTER triggers --
+//
+// An AFTER trigger executes a function returning VOID for each row which was
+// modified. The body of such a function is a single INSERT or UPSERT
+// statement. AFTER triggers are executed after the mutation by the same
+// machinery which executes the cascading actions of foreign keys (see
+// afterTriggerBuilder); the trigger query is equivalent to:
+//
+//   INSERT INTO <target> SELECT <values>
+//   FROM (
+//     SELECT <arg1> AS a1, <arg2> AS a2, ...
+//     FROM <buffered mutation input>
+//     WHERE <when>
+//   )
+//
+// In both cases, the arguments of the function and the WHEN condition can refer
+// to the columns of the old and new versions of the row as OLD.<col> and
+// NEW.<col>. OLD is NULL for an INSERT, and NEW is NULL for a DELETE.
+
+// triggers returns the triggers of the target table with the given action time
+// which fire on the given event.
+func (mb *mutationBuilder) triggers(
+	actionTime tree.TriggerActionTime, event tree.TriggerEvent,
+) []cat.Trigger {
+	var res []cat.Trigger
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		if t := mb.tab.Trigger(i); t.ActionTime == actionTime && t.Events.Contains(event) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// checkNoTriggersForUpsert raises an error if the target table has triggers
+// which fire on INSERT or UPDATE, since they are not yet supported by UPSERT
+// and INSERT .. ON CONFLICT statements.
+func (mb *mutationBuilder) checkNoTriggersForUpsert() {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		t := mb.tab.Trigger(i)
+		if t.Events.Contains(tree.TriggerEventInsert) || t.Events.Contains(tree.TriggerEventUpdate) {
+			panic(unimplemented.NewWithIssuef(28296,
+				"UPSERT and INSERT ... ON CONFLICT are not supported on table %q with trigger %q",
+				mb.tab.Name(), t.Name))
+		}
+	}
+}
+
+// triggerRowCols returns the columns of the mutation input which hold the old
+// and new values of the modified row for the given event. Both lists are
+// indexed by table ordinal; a list is nil if the row does not exist for the
+// event.
+func (mb *mutationBuilder) triggerRowCols(event tree.TriggerEvent) (oldCols, newCols opt.ColList) {
+	switch event {
+	case tree.TriggerEventInsert:
+		newCols = mb.insertColIDs
+	case tree.TriggerEventUpdate:
+		oldCols = mb.fetchColIDs
+		newCols = make(opt.ColList, len(mb.fetchColIDs))
+		for i := range newCols {
+			if mb.updateColIDs[i] != 0 {
+				newCols[i] = mb.updateColIDs[i]
+			} else {
+				newCols[i] = mb.fetchColIDs[i]
+			}
+		}
+	case tree.TriggerEventDelete:
+		oldCols = mb.fetchColIDs
+	default:
+		panic(errors.AssertionFailedf("unknown trigger event %s", event))
+	}
+	return oldCols, newCols
+}
+
+// buildBeforeTriggers filters the mutation input with the calls to the
+// functions of the BEFORE triggers which fire on the given event. See the
+// comment at the top of the file.
+func (mb *mutationBuilder) buildBeforeTriggers(event tree.TriggerEvent) {
+	triggers := mb.triggers(tree.TriggerActionTimeBefore, event)
+	if len(triggers) == 0 {
+		return
+	}
+	telemetry.Inc(sqltelemetry.TriggerUseCounter)
+
+	// The function bodies are looked up on every execution, so a memo which
+	// inlines them can become stale without any of its data sources changing.
+	mb.b.DisableMemoReuse = true
+
+	oldCols, newCols := mb.triggerRowCols(event)
+	filters := make(memo.FiltersExpr, len(triggers))
+	for i := range triggers {
+		t := &triggers[i]
+		call := &tree.FuncExpr{
+			Func:  tree.ResolvableFunctionReference{FunctionReference: mb.b.resolveTriggerFunction(t)},
+			Exprs: make(tree.Exprs, len(t.Args)),
+		}
+		for j := range t.Args {
+			call.Exprs[j] = mb.b.parseTriggerExpr(mb.tab, t.Args[j], oldCols, newCols)
+		}
+		var cond tree.Expr = call
+		if t.When != "" {
+			cond = &tree.CaseExpr{
+				Whens: []*tree.When{{
+					Cond: mb.b.parseTriggerExpr(mb.tab, t.When, oldCols, newCols),
+					Val:  call,
+				}},
+				Else: tree.DBoolTrue,
+			}
+		}
+		texpr := mb.outScope.resolveAndRequireType(cond, types.Bool)
+		filters[i] = mb.b.factory.ConstructFiltersItem(
+			mb.b.buildScalar(texpr, mb.outScope, nil /* outScope */, nil /* outCol */, nil /* colRefs */),
+		)
+	}
+	mb.outScope.expr = mb.b.factory.ConstructSelect(mb.outScope.expr, filters)
+}
+
+// buildAfterTriggers adds a cascade to the mutation for each AFTER trigger
+// which fires on the given event. See the comment at the top of the file.
+func (mb *mutationBuilder) buildAfterTriggers(event tree.TriggerEvent) {
+	triggers := mb.triggers(tree.TriggerActionTimeAfter, event)
+	if len(triggers) == 0 {
+		return
+	}
+	telemetry.Inc(sqltelemetry.TriggerUseCounter)
+
+	oldCols, newCols := mb.triggerRowCols(event)
+	for i := range triggers {
+		builder := newAfterTriggerBuilder(mb.tab, triggers[i], oldCols != nil, newCols != nil)
+		oldValues := make(opt.ColList, len(builder.oldOrds))
+		for j, ord := range builder.oldOrds {
+			oldValues[j] = oldCols[ord]
+		}
+		newValues := make(opt.ColList, len(builder.newOrds))
+		for j, ord := range builder.newOrds {
+			newValues[j] = newCols[ord]
+		}
+		mb.ensureWithID()
+		mb.cascades = append(mb.cascades, memo.FKCascade{
+			FKName:    triggers[i].Name,
+			Builder:   builder,
+			WithID:    mb.withID,
+			OldValues: oldValues,
+			NewValues: newValues,
+		})
+	}
+}
+
+// afterTriggerBuilder is a memo.CascadeBuilder implementation for AFTER
+// triggers. It builds the query which executes the function of the trigger for
+// each modified row.
+type afterTriggerBuilder struct {
+	mutatedTable cat.Table
+	trigger      cat.Trigger
+
+	// oldOrds and newOrds are the ordinals of the table columns of the old and
+	// new rows which are referenced by the trigger. They map 1-to-1 to the
+	// oldValues and newValues of the cascade.
+	oldOrds, newOrds []int
+	// hasOld and hasNew are false if the old or the new row, respectively,
+	// does not exist for the event.
+	hasOld, hasNew bool
+}
+
+var _ memo.CascadeBuilder = &afterTriggerBuilder{}
+
+func newAfterTriggerBuilder(
+	mutatedTable cat.Table, trigger cat.Trigger, hasOld, hasNew bool,
+) *afterTriggerBuilder {
+	cb := &afterTriggerBuilder{
+		mutatedTable: mutatedTable,
+		trigger:      trigger,
+		hasOld:       hasOld,
+		hasNew:       hasNew,
+	}
+	var oldOrds, newOrds util.FastIntSet
+	exprs := trigger.Args
+	if trigger.When != "" {
+		exprs = append(exprs[:len(exprs):len(exprs)], trigger.When)
+	}
+	for _, s := range exprs {
+		expr, err := parser.ParseExpr(s)
+		if err != nil {
+			panic(err)
+		}
+		visitTriggerColumnRefs(expr, func(isNew bool, colName tree.Name) tree.Expr {
+			ord := findPublicTableColumnByName(mutatedTable, colName)
+			if ord == -1 {
+				panic(pgerror.Newf(pgcode.UndefinedColumn,
+					"column %q referenced by trigger %q does not exist", colName, trigger.Name))
+			}
+			if isNew && hasNew {
+				newOrds.Add(ord)
+			} else if !isNew && hasOld {
+				oldOrds.Add(ord)
+			}
+			return tree.DNull
+		})
+	}
+	// The trigger is executed once for each modified row, so the cascade input
+	// must have at least one column even if the trigger does not reference any.
+	if oldOrds.Empty() && newOrds.Empty() {
+		if hasNew {
+			newOrds.Add(0)
+		} else {
+			oldOrds.Add(0)
+		}
+	}
+	cb.oldOrds = oldOrds.Ordered()
+	cb.newOrds = newOrds.Ordered()
+	return cb
+}
+
+// Build is part of the memo.CascadeBuilder interface.
+func (cb *afterTriggerBuilder) Build(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	catalog cat.Catalog,
+	factoryI interface{},
+	binding opt.WithID,
+	bindingProps *props.Relational,
+	oldValues, newValues opt.ColList,
+) (_ memo.RelExpr, err error) {
+	return buildCascadeHelper(ctx, semaCtx, evalCtx, catalog, factoryI, func(b *Builder) memo.RelExpr {
+		if len(oldValues) != len(cb.oldOrds) || len(newValues) != len(cb.newOrds) {
+			panic(errors.AssertionFailedf(
+				"expected %d/%d oldValues/newValues columns, got %d/%d",
+				len(cb.oldOrds), len(cb.newOrds), len(oldValues), len(newValues),
+			))
+		}
+		f := b.factory
+		md := f.Metadata()
+
+		// Scan the buffered rows, mapping the columns of the old and new rows
+		// by table ordinal.
+		inCols := append(oldValues[:len(oldValues):len(oldValues)], newValues...)
+		outCols := make(opt.ColList, len(inCols))
+		for i, col := range inCols {
+			colMeta := md.ColumnMeta(col)
+			outCols[i] = md.AddColumn(colMeta.Alias, colMeta.Type)
+		}
+		md.AddWithBinding(binding, f.ConstructFakeRel(&memo.FakeRelPrivate{
+			Props: bindingProps,
+		}))
+		rowScope := b.allocScope()
+		rowScope.expr = f.ConstructWithScan(&memo.WithScanPrivate{
+			With:    binding,
+			InCols:  inCols,
+			OutCols: outCols,
+			ID:      md.NextUniqueID(),
+		})
+		for _, col := range outCols {
+			rowScope.cols = append(rowScope.cols, scopeColumn{
+				name: scopeColName(""),
+				id:   col,
+				typ:  md.ColumnMeta(col).Type,
+			})
+		}
+		var oldCols, newCols opt.ColList
+		if cb.hasOld {
+			oldCols = make(opt.ColList, cb.mutatedTable.ColumnCount())
+			for i, ord := range cb.oldOrds {
+				oldCols[ord] = outCols[i]
+			}
+		}
+		if cb.hasNew {
+			newCols = make(opt.ColList, cb.mutatedTable.ColumnCount())
+			for i, ord := range cb.newOrds {
+				newCols[ord] = outCols[len(cb.oldOrds)+i]
+			}
+		}
+
+		// WHEN
+		if cb.trigger.When != "" {
+			when := b.parseTriggerExpr(cb.mutatedTable, cb.trigger.When, oldCols, newCols)
+			texpr := rowScope.resolveAndRequireType(when, types.Bool)
+			rowScope.expr = f.ConstructSelect(rowScope.expr, memo.FiltersExpr{
+				f.ConstructFiltersItem(
+					b.buildScalar(texpr, rowScope, nil /* outScope */, nil /* outCol */, nil /* colRefs */),
+				),
+			})
+		}
+
+		// Project the arguments of the function.
+		def := b.resolveTriggerFunction(&cb.trigger)
+		o, ok := def.Definition[0].(*tree.Overload)
+		if !ok {
+			panic(errors.AssertionFailedf("unexpected overload type %T", def.Definition[0]))
+		}
+		argTypes, ok := o.Types.(tree.ArgTypes)
+		if !ok || len(argTypes) != len(cb.trigger.Args) {
+			panic(pgerror.Newf(pgcode.UndefinedFunction,
+				"function %s of trigger %q does not accept %d arguments",
+				def.Name, cb.trigger.Name, len(cb.trigger.Args)))
+		}
+		argScope := rowScope.push()
+		for i := range cb.trigger.Args {
+			arg := b.parseTriggerExpr(cb.mutatedTable, cb.trigger.Args[i], oldCols, newCols)
+			texpr := rowScope.resolveAndRequireType(
+				&tree.CastExpr{Expr: arg, Type: argTypes[i].Typ}, argTypes[i].Typ,
+			)
+			col := argScope.addColumn(scopeColName(tree.Name(argTypes[i].Name)), texpr)
+			b.buildScalar(texpr, rowScope, argScope, col, nil /* colRefs */)
+		}
+		b.constructProjectForScope(rowScope, argScope)
+		if !o.CalledOnNullInput && len(argScope.cols) > 0 {
+			filters := make(memo.FiltersExpr, len(argScope.cols))
+			for i := range argScope.cols {
+				col := &argScope.cols[i]
+				filters[i] = f.ConstructFiltersItem(f.ConstructIsNot(
+					f.ConstructVariable(col.id), f.ConstructNull(col.typ),
+				))
+			}
+			argScope.expr = f.ConstructSelect(argScope.expr, filters)
+		}
+
+		// Build the body of the function, which is joined with the arguments by
+		// the mutation builder.
+		ins := parseUDFMutationBody(def.Name, o.Body, argScope)
+		b.triggerArgs = argScope
+		outScope := b.buildUDFMutationBody(ins, argScope)
+		return outScope.expr
+	})
+}
+
+// joinTriggerArgs joins the input of a mutation which is the body of a
+// function executed by an AFTER trigger with the arguments of the function,
+// which the input can refer to as outer columns.
+func (mb *mutationBuilder) joinTriggerArgs() {
+	if mb.triggerArgs == nil {
+		return
+	}
+	mb.outScope.expr = mb.b.factory.ConstructInnerJoinApply(
+		mb.triggerArgs.expr, mb.outScope.expr, memo.TrueFilter, memo.EmptyJoinPrivate,
+	)
+}
+
+// resolveTriggerFunction returns the definition of the function executed by
+// the given trigger.
+func (b *Builder) resolveTriggerFunction(t *cat.Trigger) *tree.FunctionDefinition {
+	if b.semaCtx.FunctionResolver == nil {
+		panic(errors.AssertionFailedf("cannot resolve function of trigger %q", t.Name))
+	}
+	def, err := b.semaCtx.FunctionResolver.ResolveFunctionByOID(b.ctx, t.FunctionOID)
+	if err != nil {
+		panic(err)
+	}
+	return def
+}
+
+// parseTriggerExpr parses an argument or the WHEN condition of a trigger on the
+// given table, and replaces the references to the columns of the OLD and NEW
+// rows with the corresponding columns in oldCols and newCols, which are indexed
+// by table ordinal. A reference to a row which does not exist for the event,
+// as indicated by a nil list, is replaced with NULL.
+func (b *Builder) parseTriggerExpr(
+	tab cat.Table, s string, oldCols, newCols opt.ColList,
+) tree.Expr {
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		panic(err)
+	}
+	md := b.factory.Metadata()
+	return visitTriggerColumnRefs(expr, func(isNew bool, colName tree.Name) tree.Expr {
+		ord := findPublicTableColumnByName(tab, colName)
+		if ord == -1 {
+			panic(pgerror.Newf(pgcode.UndefinedColumn,
+				"column %q referenced by trigger does not exist", colName))
+		}
+		cols := oldCols
+		if isNew {
+			cols = newCols
+		}
+		if cols == nil {
+			return &tree.CastExpr{Expr: tree.DNull, Type: tab.Column(ord).DatumType()}
+		}
+		if cols[ord] == 0 {
+			panic(errors.AssertionFailedf("column %q of trigger row is not available", colName))
+		}
+		return &scopeColumn{
+			name: scopeColName(colName),
+			id:   cols[ord],
+			typ:  md.ColumnMeta(cols[ord]).Type,
+		}
+	})
+}
+
+// visitTriggerColumnRefs replaces each reference to a column of the OLD or NEW
+// row in the given trigger expression with the result of fn.
+func visitTriggerColumnRefs(
+	expr tree.Expr, fn func(isNew bool, colName tree.Name) tree.Expr,
+) tree.Expr {
+	newExpr, err := tree.SimpleVisit(expr, func(expr tree.Expr) (bool, tree.Expr, error) {
+		v, ok := expr.(tree.VarName)
+		if !ok {
+			return true, expr, nil
+		}
+		vn, err := v.NormalizeVarName()
+		if err != nil {
+			return false, nil, err
+		}
+		if c, ok := vn.(*tree.ColumnItem); ok && c.TableName != nil && c.TableName.NumParts == 1 {
+			switch c.TableName.Parts[0] {
+			case "old", "new":
+				return false, fn(c.TableName.Parts[0] == "new", c.ColumnName), nil
+			}
+		}
+		return false, nil, pgerror.Newf(pgcode.InvalidColumnReference,
+			"column reference %q in a trigger must be qualified with OLD or NEW", tree.ErrString(v))
+	})
+	if err != nil {
+		panic(err)
+	}
+	return newExpr
+}
@@ -390,6 +390,7 @@ func (h *uniqueCheckHelper) buildInsertionCheck() memo.UniqueChecksItem {
 		CheckOrdinal: h.uniqueOrdinal,
 		KeyCols:      keyCols,
 		OpName:       h.mb.opName,
+		Deferrable:   h.unique.Deferrability() != tree.NotDeferrableConstraint,
 	})
 }
 
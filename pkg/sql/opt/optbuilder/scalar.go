@@ -13,6 +13,7 @@ package optbuilder
 import (
 	"context"
 	"fmt"
+	"math"
 
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/seqexpr"
@@ -171,19 +172,42 @@ func (b *Builder) buildScalar(
 	case *tree.IndirectionExpr:
 		expr := b.buildScalar(t.Expr.(tree.TypedExpr), inScope, nil, nil, colRefs)
 
-		if len(t.Indirection) != 1 {
-			panic(unimplementedWithIssueDetailf(32552, "ind", "multidimensional indexing is not supported"))
-		}
+		switch {
+		case t.Indirection.IsSlice():
+			lower := make(memo.ScalarListExpr, len(t.Indirection))
+			upper := make(memo.ScalarListExpr, len(t.Indirection))
+			for i, subscript := range t.Indirection {
+				// Omitted bounds extend to the bounds of the array, and a subscript
+				// without a colon is treated as a slice starting at 1.
+				lower[i] = b.factory.ConstructConstVal(tree.NewDInt(math.MinInt32), types.Int)
+				upper[i] = b.factory.ConstructConstVal(tree.NewDInt(math.MaxInt32), types.Int)
+				if !subscript.Slice {
+					lower[i] = b.factory.ConstructConstVal(tree.NewDInt(1), types.Int)
+					upper[i] = b.buildScalar(subscript.Begin.(tree.TypedExpr), inScope, nil, nil, colRefs)
+					continue
+				}
+				if subscript.Begin != nil {
+					lower[i] = b.buildScalar(subscript.Begin.(tree.TypedExpr), inScope, nil, nil, colRefs)
+				}
+				if subscript.End != nil {
+					upper[i] = b.buildScalar(subscript.End.(tree.TypedExpr), inScope, nil, nil, colRefs)
+				}
+			}
+			out = b.factory.ConstructArraySlice(expr, lower, upper)
 
-		subscript := t.Indirection[0]
-		if subscript.Slice {
-			panic(unimplementedWithIssueDetailf(32551, "", "array slicing is not supported"))
-		}
+		case len(t.Indirection) > 1:
+			indexes := make(memo.ScalarListExpr, len(t.Indirection))
+			for i, subscript := range t.Indirection {
+				indexes[i] = b.buildScalar(subscript.Begin.(tree.TypedExpr), inScope, nil, nil, colRefs)
+			}
+			out = b.factory.ConstructMultiIndirection(expr, indexes)
 
-		out = b.factory.ConstructIndirection(
-			expr,
-			b.buildScalar(subscript.Begin.(tree.TypedExpr), inScope, nil, nil, colRefs),
-		)
+		default:
+			out = b.factory.ConstructIndirection(
+				expr,
+				b.buildScalar(t.Indirection[0].Begin.(tree.TypedExpr), inScope, nil, nil, colRefs),
+			)
+		}
 
 	case *tree.IfErrExpr:
 		cond := b.buildScalar(t.Cond.(tree.TypedExpr), inScope, nil, nil, colRefs)
@@ -521,7 +545,7 @@ func (b *Builder) buildFunction(
 		}
 	}
 
-	def, err := f.Func.Resolve(b.semaCtx.SearchPath)
+	def, err := b.semaCtx.ResolveFunctionReference(b.ctx, &f.Func)
 	if err != nil {
 		panic(err)
 	}
@@ -534,11 +558,19 @@ func (b *Builder) buildFunction(
 		panic(errors.AssertionFailedf("window function should have been replaced"))
 	}
 
+	if def.Name == groupingFuncName && !f.ResolvedOverload().IsUDF {
+		return b.buildGroupingFunc(f, inScope, outScope, outCol)
+	}
+
 	args := make(memo.ScalarListExpr, len(f.Exprs))
 	for i, pexpr := range f.Exprs {
 		args[i] = b.buildScalar(pexpr.(tree.TypedExpr), inScope, nil, nil, colRefs)
 	}
 
+	if f.ResolvedOverload().IsUDF {
+		return b.buildUDF(f, def, args, inScope, outScope, outCol)
+	}
+
 	// Construct a private FuncOpDef that refers to a resolved function overload.
 	out = b.factory.ConstructFunction(args, &memo.FunctionPrivate{
 		Name:       def.Name,
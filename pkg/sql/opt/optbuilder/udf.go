@@ -0,0 +1,238 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// buildUDF builds a call to a user-defined SQL function. The body of the
+// function is inlined as a correlated scalar subquery of the form:
+//
+//	(SELECT <body> FROM (SELECT <arg1> AS a1, <arg2> AS a2, ...) LIMIT 1)
+//
+// where the body can refer to the arguments either by name or by position.
+// If the function is strict, the argument row is filtered out when any of the
+// arguments is NULL, so that the subquery evaluates to NULL.
+func (b *Builder) buildUDF(
+	f *tree.FuncExpr,
+	def *tree.FunctionDefinition,
+	args memo.ScalarListExpr,
+	inScope, outScope *scope,
+	outCol *scopeColumn,
+) opt.ScalarExpr {
+	o := f.ResolvedOverload()
+	if b.trackViewDeps {
+		panic(unimplemented.New("udf-dependencies",
+			"user-defined functions cannot be referenced by views or other functions"))
+	}
+	if o.ReturnSet {
+		panic(unimplemented.New("udf-setof", "set-returning user-defined functions are not supported"))
+	}
+
+	// The function body is looked up on every call, so a memo which inlines it
+	// can become stale without any of its data sources changing.
+	b.DisableMemoReuse = true
+
+	// Project the arguments as columns of a single-row input.
+	argTypes, ok := o.Types.(tree.ArgTypes)
+	if !ok {
+		panic(errors.AssertionFailedf("unexpected argument types %T for function %s", o.Types, def.Name))
+	}
+	argScope := b.allocScope()
+	argScope.expr = b.factory.ConstructValues(memo.ScalarListWithEmptyTuple, &memo.ValuesPrivate{
+		Cols: opt.ColList{},
+		ID:   b.factory.Metadata().NextUniqueID(),
+	})
+	projScope := argScope.push()
+	for i := range args {
+		arg, typ := args[i], argTypes[i].Typ
+		// The overload of the function only requires the type of an argument to
+		// be equivalent to the type of its parameter, so an argument passed to a
+		// domain parameter must be cast to the domain to check its constraints.
+		if typ.IsDomain() && !arg.DataType().Identical(typ) {
+			arg = b.factory.ConstructCast(arg, typ)
+		}
+		b.synthesizeColumn(projScope, scopeColName(tree.Name(argTypes[i].Name)), typ, nil /* expr */, arg)
+	}
+	b.constructProjectForScope(argScope, projScope)
+	if !o.CalledOnNullInput && len(args) > 0 {
+		filters := make(memo.FiltersExpr, len(projScope.cols))
+		for i := range projScope.cols {
+			col := &projScope.cols[i]
+			filters[i] = b.factory.ConstructFiltersItem(b.factory.ConstructIsNot(
+				b.factory.ConstructVariable(col.id), b.factory.ConstructNull(col.typ),
+			))
+		}
+		projScope.expr = b.factory.ConstructSelect(projScope.expr, filters)
+	}
+
+	if f.ResolvedType().Family() == types.VoidFamily {
+		panic(unimplemented.New("udf-void",
+			"functions returning void can only be executed by triggers"))
+	}
+
+	sel, bodyScope, bodyCol := b.buildUDFBody(def.Name, o.Body, f.ResolvedType(), projScope)
+
+	// Only the first row returned by the body is the result of the function.
+	input := b.factory.ConstructLimit(
+		bodyScope.expr,
+		b.factory.ConstructConst(tree.NewDInt(1), types.Int),
+		bodyScope.makeOrderingChoice(),
+	)
+	input = b.factory.ConstructInnerJoinApply(projScope.expr, input, memo.TrueFilter, memo.EmptyJoinPrivate)
+	input = b.factory.ConstructProject(input, memo.EmptyProjectionsExpr, opt.MakeColSet(bodyCol))
+	out := b.factory.ConstructSubquery(input, &memo.SubqueryPrivate{
+		OriginalExpr: &tree.Subquery{Select: &tree.ParenSelect{Select: sel}},
+	})
+	if typ := b.factory.Metadata().ColumnMeta(bodyCol).Type; !typ.Identical(f.ResolvedType()) {
+		out = b.factory.ConstructAssignmentCast(out, f.ResolvedType())
+	}
+	return b.finishBuildScalar(f, out, inScope, outScope, outCol)
+}
+
+// buildUDFBody parses and builds the body of the user-defined function with
+// the given name and return type. The arguments of the function must already
+// be columns of argScope, in order. The body can refer to them by name or by
+// position ($1, $2, ...).
+//
+// The parsed body is returned along with the scope it was built in and the
+// column holding the result of the function. The type of the result column
+// is assignment-castable to retType, but not necessarily identical to it.
+func (b *Builder) buildUDFBody(
+	name string, body string, retType *types.T, argScope *scope,
+) (sel *tree.Select, bodyScope *scope, resultCol opt.ColumnID) {
+	stmts, err := parser.Parse(body)
+	if err != nil {
+		panic(pgerror.Wrapf(err, pgcode.Syntax, "failed to parse body of function %q", name))
+	}
+	if len(stmts) > 1 {
+		panic(unimplemented.New("udf-multiple-statements",
+			"user-defined functions with multiple statements are not supported"))
+	}
+	var ok bool
+	if len(stmts) == 1 {
+		sel, ok = stmts[0].AST.(*tree.Select)
+	}
+	if !ok {
+		panic(newReturnTypeMismatchError(retType, "Function's final statement must be SELECT."))
+	}
+
+	sel = replaceUDFArgPlaceholders(sel, argScope).(*tree.Select)
+
+	// The body is not a subquery of any enclosing query, so make sure that
+	// references to the arguments are not collected as outer columns of one.
+	subq := b.subquery
+	b.subquery = nil
+	defer func() { b.subquery = subq }()
+	prevCTEs := b.ctes
+	b.ctes = nil
+	bodyScope = b.buildStmt(sel, []*types.T{retType}, argScope)
+	bodyScope.expr = b.buildWiths(bodyScope.expr, b.ctes)
+	b.ctes = prevCTEs
+
+	cols := bodyScope.makePhysicalProps().Presentation
+	if len(cols) != 1 {
+		panic(newReturnTypeMismatchError(retType, "Final statement must return exactly one column."))
+	}
+	resultCol = cols[0].ID
+	typ := b.factory.Metadata().ColumnMeta(resultCol).Type
+	if !typ.Identical(retType) && !tree.ValidCast(typ, retType, tree.CastContextAssignment) {
+		panic(newReturnTypeMismatchError(retType, "Actual return type is "+typ.SQLString()+"."))
+	}
+	return sel, bodyScope, resultCol
+}
+
+// parseUDFMutationBody parses the body of the user-defined function with the
+// given name which returns VOID. Such a function can only be executed by
+// triggers, and its body must be a single INSERT or UPSERT statement. The
+// arguments of the function must already be columns of argScope, in order;
+// the positional references to them in the body are replaced with the
+// argument columns.
+func parseUDFMutationBody(name string, body string, argScope *scope) *tree.Insert {
+	stmts, err := parser.Parse(body)
+	if err != nil {
+		panic(pgerror.Wrapf(err, pgcode.Syntax, "failed to parse body of function %q", name))
+	}
+	if len(stmts) > 1 {
+		panic(unimplemented.New("udf-multiple-statements",
+			"user-defined functions with multiple statements are not supported"))
+	}
+	var ins *tree.Insert
+	var ok bool
+	if len(stmts) == 1 {
+		ins, ok = stmts[0].AST.(*tree.Insert)
+	}
+	if !ok {
+		panic(unimplemented.New("udf-void",
+			"the body of a function returning void must be a single INSERT or UPSERT statement"))
+	}
+	if ins.With != nil {
+		panic(unimplemented.New("udf-void-with",
+			"WITH clauses are not supported in the body of a function returning void"))
+	}
+	if ins.OnConflict != nil && !ins.OnConflict.IsUpsertAlias() && !ins.OnConflict.DoNothing {
+		panic(unimplemented.New("udf-void-on-conflict",
+			"ON CONFLICT DO UPDATE is not supported in the body of a function returning void"))
+	}
+	if resultsNeeded(ins.Returning) {
+		panic(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"function %q returning void cannot have a RETURNING clause", name))
+	}
+	return replaceUDFArgPlaceholders(ins, argScope).(*tree.Insert)
+}
+
+// buildUDFMutationBody builds the INSERT or UPSERT statement which is the body
+// of a user-defined function returning VOID. See parseUDFMutationBody.
+func (b *Builder) buildUDFMutationBody(ins *tree.Insert, argScope *scope) (bodyScope *scope) {
+	// The body is not a subquery of any enclosing query, so make sure that
+	// references to the arguments are not collected as outer columns of one.
+	subq := b.subquery
+	b.subquery = nil
+	defer func() { b.subquery = subq }()
+	return b.buildInsert(ins, argScope)
+}
+
+// replaceUDFArgPlaceholders replaces the positional references to the
+// arguments of a user-defined function ($1, $2, ...) in the given statement
+// with the argument columns of argScope.
+func replaceUDFArgPlaceholders(stmt tree.Statement, argScope *scope) tree.Statement {
+	newStmt, err := tree.SimpleStmtVisit(stmt, func(expr tree.Expr) (bool, tree.Expr, error) {
+		if p, ok := expr.(*tree.Placeholder); ok {
+			if int(p.Idx) >= len(argScope.cols) {
+				return false, nil, pgerror.Newf(pgcode.UndefinedParameter,
+					"there is no parameter $%d", p.Idx+1)
+			}
+			return false, &argScope.cols[p.Idx], nil
+		}
+		return true, expr, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return newStmt
+}
+
+func newReturnTypeMismatchError(retType *types.T, detail string) error {
+	return errors.WithDetail(
+		pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"return type mismatch in function declared to return %s", retType.SQLString()),
+		detail,
+	)
+}
@@ -327,6 +327,9 @@ func (mb *mutationBuilder) addSynthesizedColsForUpdate() {
 // buildUpdate constructs an Update operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
+	// Skip the rows rejected by BEFORE triggers.
+	mb.buildBeforeTriggers(tree.TriggerEventUpdate)
+
 	// Disambiguate names so that references in any expressions, such as a
 	// check constraint, refer to the correct columns.
 	mb.disambiguateColumns()
@@ -344,8 +347,12 @@ func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
 
 	mb.buildUniqueChecksForUpdate()
 
+	mb.buildExclusionChecks(true /* isUpdate */)
+
 	mb.buildFKChecksForUpdate()
 
+	mb.buildAfterTriggers(tree.TriggerEventUpdate)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	for _, col := range mb.extraAccessibleCols {
 		if col.id != 0 {
@@ -573,6 +573,11 @@ func (b *Builder) resolveTableForMutation(
 		panic(pgerror.Newf(pgcode.WrongObjectType, "cannot mutate materialized view %q", tab.Name()))
 	}
 
+	// Functions which modify data depend on the tables they modify.
+	if b.trackViewDeps {
+		b.viewDeps = append(b.viewDeps, opt.ViewDep{DataSource: tab})
+	}
+
 	return tab, depName, alias, columns
 }
 
@@ -221,7 +221,9 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 		switch def := def.(type) {
 		case *tree.UniqueConstraintTableDef:
 			if def.WithoutIndex {
-				tab.addUniqueConstraint(def.Name, def.Columns, def.Predicate, def.WithoutIndex)
+				tab.addUniqueConstraint(
+					def.Name, def.Columns, def.Predicate, def.WithoutIndex, def.Deferrable,
+				)
 			} else if !def.PrimaryKey {
 				tab.addIndex(&def.IndexTableDef, uniqueIndex)
 			}
@@ -240,6 +242,7 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 						tree.IndexElemList{{Column: def.Name}},
 						nil, /* predicate */
 						def.Unique.WithoutIndex,
+						tree.NotDeferrableConstraint,
 					)
 				} else {
 					tab.addIndex(
@@ -534,13 +537,18 @@ func (tc *Catalog) resolveFK(tab *Table, d *tree.ForeignKeyConstraintTableDef) {
 		matchMethod:              d.Match,
 		deleteAction:             d.Actions.Delete,
 		updateAction:             d.Actions.Update,
+		deferrability:            d.Deferrable,
 	}
 	tab.outboundFKs = append(tab.outboundFKs, fk)
 	targetTable.inboundFKs = append(targetTable.inboundFKs, fk)
 }
 
 func (tt *Table) addUniqueConstraint(
-	name tree.Name, columns tree.IndexElemList, predicate tree.Expr, withoutIndex bool,
+	name tree.Name,
+	columns tree.IndexElemList,
+	predicate tree.Expr,
+	withoutIndex bool,
+	deferrability tree.ConstraintDeferrability,
 ) {
 	// We don't currently use unique constraints with an index (those are already
 	// tracked with unique indexes), so don't bother adding them.
@@ -568,6 +576,7 @@ func (tt *Table) addUniqueConstraint(
 		columnOrdinals: cols,
 		withoutIndex:   withoutIndex,
 		validated:      true,
+		deferrability:  deferrability,
 	}
 	// Add partial unique constraint predicate.
 	if predicate != nil {
@@ -697,7 +706,9 @@ func (tt *Table) addIndexWithVersion(
 ) *Index {
 	// Add a unique constraint if this is a primary or unique index.
 	if typ != nonUniqueIndex {
-		tt.addUniqueConstraint(def.Name, def.Columns, def.Predicate, false /* withoutIndex */)
+		tt.addUniqueConstraint(
+			def.Name, def.Columns, def.Predicate, false /* withoutIndex */, tree.NotDeferrableConstraint,
+		)
 	}
 
 	idx := &Index{
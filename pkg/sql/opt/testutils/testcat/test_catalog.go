@@ -766,6 +766,26 @@ func (tt *Table) IsPartitionAllBy() bool {
 	return false
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (tt *Table) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (tt *Table) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("no triggers"))
+}
+
+// ExclusionConstraintCount is part of the cat.Table interface.
+func (tt *Table) ExclusionConstraintCount() int {
+	return 0
+}
+
+// ExclusionConstraint is part of the cat.Table interface.
+func (tt *Table) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("no exclusion constraints"))
+}
+
 // FindOrdinal returns the ordinal of the column with the given name.
 func (tt *Table) FindOrdinal(name string) int {
 	for i, col := range tt.Columns {
@@ -1155,10 +1175,11 @@ type ForeignKeyConstraint struct {
 	originColumnOrdinals     []int
 	referencedColumnOrdinals []int
 
-	validated    bool
-	matchMethod  tree.CompositeKeyMatchMethod
-	deleteAction tree.ReferenceAction
-	updateAction tree.ReferenceAction
+	validated     bool
+	matchMethod   tree.CompositeKeyMatchMethod
+	deleteAction  tree.ReferenceAction
+	updateAction  tree.ReferenceAction
+	deferrability tree.ConstraintDeferrability
 }
 
 var _ cat.ForeignKeyConstraint = &ForeignKeyConstraint{}
@@ -1226,6 +1247,11 @@ func (fk *ForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction {
 	return fk.updateAction
 }
 
+// Deferrability is part of the cat.ForeignKeyConstraint interface.
+func (fk *ForeignKeyConstraint) Deferrability() tree.ConstraintDeferrability {
+	return fk.deferrability
+}
+
 // UniqueConstraint implements cat.UniqueConstraint. See that interface
 // for more information on the fields.
 type UniqueConstraint struct {
@@ -1235,6 +1261,7 @@ type UniqueConstraint struct {
 	predicate      string
 	withoutIndex   bool
 	validated      bool
+	deferrability  tree.ConstraintDeferrability
 }
 
 var _ cat.UniqueConstraint = &UniqueConstraint{}
@@ -1280,6 +1307,11 @@ func (u *UniqueConstraint) Validated() bool {
 	return u.validated
 }
 
+// Deferrability is part of the cat.UniqueConstraint interface.
+func (u *UniqueConstraint) Deferrability() tree.ConstraintDeferrability {
+	return u.deferrability
+}
+
 // UniquenessGuaranteedByAnotherIndex is part of the cat.UniqueConstraint
 // interface.
 func (u *UniqueConstraint) UniquenessGuaranteedByAnotherIndex() bool {
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
@@ -777,12 +778,13 @@ func newOptTable(
 	for i := range ot.desc.GetUniqueWithoutIndexConstraints() {
 		u := &ot.desc.GetUniqueWithoutIndexConstraints()[i]
 		ot.uniqueConstraints = append(ot.uniqueConstraints, optUniqueConstraint{
-			name:         u.Name,
-			table:        ot.ID(),
-			columns:      u.ColumnIDs,
-			predicate:    u.Predicate,
-			withoutIndex: true,
-			validity:     u.Validity,
+			name:          u.Name,
+			table:         ot.ID(),
+			columns:       u.ColumnIDs,
+			predicate:     u.Predicate,
+			withoutIndex:  true,
+			validity:      u.Validity,
+			deferrability: u.Deferrability(),
 		})
 	}
 
@@ -882,6 +884,7 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrability:     fk.Deferrability(),
 		})
 		return nil
 	})
@@ -896,6 +899,7 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrability:     fk.Deferrability(),
 		})
 		return nil
 	})
@@ -1177,6 +1181,64 @@ func (ot *optTable) IsPartitionAllBy() bool {
 	return ot.desc.IsPartitionAllBy()
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (ot *optTable) TriggerCount() int {
+	return len(ot.desc.GetTriggers())
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optTable) Trigger(i int) cat.Trigger {
+	t := &ot.desc.GetTriggers()[i]
+	trigger := cat.Trigger{
+		Name:        t.Name,
+		ActionTime:  tree.TriggerActionTimeBefore,
+		FunctionOID: funcdesc.FuncIDToOID(t.FunctionID),
+		Args:        t.Args,
+		When:        t.When,
+	}
+	if t.ActionTime == descpb.TriggerDescriptor_AFTER {
+		trigger.ActionTime = tree.TriggerActionTimeAfter
+	}
+	if t.OnInsert {
+		trigger.Events = append(trigger.Events, tree.TriggerEventInsert)
+	}
+	if t.OnUpdate {
+		trigger.Events = append(trigger.Events, tree.TriggerEventUpdate)
+	}
+	if t.OnDelete {
+		trigger.Events = append(trigger.Events, tree.TriggerEventDelete)
+	}
+	return trigger
+}
+
+// ExclusionConstraintCount is part of the cat.Table interface.
+func (ot *optTable) ExclusionConstraintCount() int {
+	return len(ot.desc.GetExclusionConstraints())
+}
+
+// ExclusionConstraint is part of the cat.Table interface.
+func (ot *optTable) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	c := &ot.desc.GetExclusionConstraints()[i]
+	ec := cat.ExclusionConstraint{
+		Name:           c.Name,
+		ColumnOrdinals: make([]int, len(c.ColumnIDs)),
+		Operators:      make([]treecmp.ComparisonOperator, len(c.Operators)),
+		Predicate:      c.Predicate,
+		Validated:      c.Validity == descpb.ConstraintValidity_Validated,
+	}
+	for j, colID := range c.ColumnIDs {
+		ec.ColumnOrdinals[j], _ = ot.lookupColumnOrdinal(colID)
+	}
+	for j, op := range c.Operators {
+		sym, ok := treecmp.LookupComparisonOperatorSymbol(op)
+		if !ok {
+			panic(errors.AssertionFailedf("unknown exclusion operator %q", op))
+		}
+		ec.Operators[j] = treecmp.MakeComparisonOperator(sym)
+	}
+	return ec
+}
+
 // lookupColumnOrdinal returns the ordinal of the column with the given ID. A
 // cache makes the lookup O(1).
 func (ot *optTable) lookupColumnOrdinal(colID descpb.ColumnID) (int, error) {
@@ -1648,8 +1710,9 @@ type optUniqueConstraint struct {
 	columns   []descpb.ColumnID
 	predicate string
 
-	withoutIndex bool
-	validity     descpb.ConstraintValidity
+	withoutIndex  bool
+	validity      descpb.ConstraintValidity
+	deferrability tree.ConstraintDeferrability
 
 	uniquenessGuaranteedByAnotherIndex bool
 }
@@ -1699,6 +1762,11 @@ func (u *optUniqueConstraint) Validated() bool {
 	return u.validity == descpb.ConstraintValidity_Validated
 }
 
+// Deferrability is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) Deferrability() tree.ConstraintDeferrability {
+	return u.deferrability
+}
+
 // UniquenessGuaranteedByAnotherIndex is part of the cat.UniqueConstraint
 // interface. It is a hack to make unique hash sharded index work before issue
 // #75070 is resolved. Be sure to remove `ignoreUniquenessCheck` field from
@@ -1720,10 +1788,11 @@ type optForeignKeyConstraint struct {
 	referencedTable   cat.StableID
 	referencedColumns []descpb.ColumnID
 
-	validity     descpb.ConstraintValidity
-	match        descpb.ForeignKeyReference_Match
-	deleteAction catpb.ForeignKeyAction
-	updateAction catpb.ForeignKeyAction
+	validity      descpb.ConstraintValidity
+	match         descpb.ForeignKeyReference_Match
+	deleteAction  catpb.ForeignKeyAction
+	updateAction  catpb.ForeignKeyAction
+	deferrability tree.ConstraintDeferrability
 }
 
 var _ cat.ForeignKeyConstraint = &optForeignKeyConstraint{}
@@ -1795,6 +1864,11 @@ func (fk *optForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction
 	return descpb.ForeignKeyReferenceActionType[fk.updateAction]
 }
 
+// Deferrability is part of the cat.ForeignKeyConstraint interface.
+func (fk *optForeignKeyConstraint) Deferrability() tree.ConstraintDeferrability {
+	return fk.deferrability
+}
+
 // optVirtualTable is similar to optTable but is used with virtual tables.
 type optVirtualTable struct {
 	desc catalog.TableDescriptor
@@ -2102,6 +2176,26 @@ func (ot *optVirtualTable) IsPartitionAllBy() bool {
 	return false
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (ot *optVirtualTable) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optVirtualTable) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("no triggers"))
+}
+
+// ExclusionConstraintCount is part of the cat.Table interface.
+func (ot *optVirtualTable) ExclusionConstraintCount() int {
+	return 0
+}
+
+// ExclusionConstraint is part of the cat.Table interface.
+func (ot *optVirtualTable) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("no exclusion constraints"))
+}
+
 // CollectTypes is part of the cat.DataSource interface.
 func (ot *optVirtualTable) CollectTypes(ord int) (descpb.IDs, error) {
 	col := ot.desc.AllColumns()[ord]
@@ -1128,7 +1128,7 @@ func (ef *execFactory) ConstructPlan(
 	root exec.Node,
 	subqueries []exec.Subquery,
 	cascades []exec.Cascade,
-	checks []exec.Node,
+	checks []exec.Check,
 	rootRowCount int64,
 ) (exec.Plan, error) {
 	// No need to spool at the root.
@@ -1684,6 +1684,7 @@ func (ef *execFactory) ConstructDelete(
 	table cat.Table,
 	fetchColOrdSet exec.TableColumnOrdinalSet,
 	returnColOrdSet exec.TableColumnOrdinalSet,
+	passthrough colinfo.ResultColumns,
 	autoCommit bool,
 ) (exec.Node, error) {
 	// Derive table and column descriptors.
@@ -1715,7 +1716,8 @@ func (ef *execFactory) ConstructDelete(
 		source: input.(planNode),
 		run: deleteRun{
 			td:                        tableDeleter{rd: rd, alloc: ef.planner.alloc},
-			partialIndexDelValsOffset: len(rd.FetchCols),
+			partialIndexDelValsOffset: len(rd.FetchCols) + len(passthrough),
+			numPassthrough:            len(passthrough),
 		},
 	}
 
@@ -1725,6 +1727,8 @@ func (ef *execFactory) ConstructDelete(
 		// Delete returns the non-mutation columns specified, in the same
 		// order they are defined in the table.
 		del.columns = colinfo.ResultColumnsFromColumns(tabDesc.GetID(), returnCols)
+		// Add the passthrough columns to the returning columns.
+		del.columns = append(del.columns, passthrough...)
 
 		del.run.rowIdxToRetIdx = row.ColMapping(rd.FetchCols, returnCols)
 		del.run.rowsNeeded = true
@@ -1832,6 +1836,56 @@ func (ef *execFactory) ConstructCreateView(
 		return nil, err
 	}
 
+	planDeps, err := makePlanDependencies(deps)
+	if err != nil {
+		return nil, err
+	}
+	typeDepSet := makeTypeDependencies(typeDeps)
+
+	return &createViewNode{
+		viewName:     viewName,
+		ifNotExists:  ifNotExists,
+		replace:      replace,
+		materialized: materialized,
+		persistence:  persistence,
+		viewQuery:    viewQuery,
+		dbDesc:       schema.(*optSchema).database,
+		columns:      columns,
+		planDeps:     planDeps,
+		typeDeps:     typeDepSet,
+	}, nil
+}
+
+// ConstructCreateFunction is part of the exec.Factory interface.
+func (ef *execFactory) ConstructCreateFunction(
+	schema cat.Schema, cf *tree.CreateFunction, deps opt.ViewDeps, typeDeps opt.ViewTypeDeps,
+) (exec.Node, error) {
+
+	if err := checkSchemaChangeEnabled(
+		ef.planner.EvalContext().Context,
+		ef.planner.ExecCfg(),
+		"CREATE FUNCTION",
+	); err != nil {
+		return nil, err
+	}
+
+	planDeps, err := makePlanDependencies(deps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createFunctionNode{
+		cf:       cf,
+		dbDesc:   schema.(*optSchema).database,
+		scDesc:   schema.(*optSchema).schema,
+		planDeps: planDeps,
+		typeDeps: makeTypeDependencies(typeDeps),
+	}, nil
+}
+
+// makePlanDependencies converts the data source dependencies collected by the
+// optimizer for a view or function into planDependencies.
+func makePlanDependencies(deps opt.ViewDeps) (planDependencies, error) {
 	planDeps := make(planDependencies, len(deps))
 	for _, d := range deps {
 		desc, err := getDescForDataSource(d.DataSource)
@@ -1854,24 +1908,17 @@ func (ef *execFactory) ConstructCreateView(
 		entry.deps = append(entry.deps, ref)
 		planDeps[desc.GetID()] = entry
 	}
+	return planDeps, nil
+}
 
+// makeTypeDependencies converts the type dependencies collected by the
+// optimizer for a view or function into typeDependencies.
+func makeTypeDependencies(typeDeps opt.ViewTypeDeps) typeDependencies {
 	typeDepSet := make(typeDependencies, typeDeps.Len())
 	typeDeps.ForEach(func(id int) {
 		typeDepSet[descpb.ID(id)] = struct{}{}
 	})
-
-	return &createViewNode{
-		viewName:     viewName,
-		ifNotExists:  ifNotExists,
-		replace:      replace,
-		materialized: materialized,
-		persistence:  persistence,
-		viewQuery:    viewQuery,
-		dbDesc:       schema.(*optSchema).database,
-		columns:      columns,
-		planDeps:     planDeps,
-		typeDeps:     typeDepSet,
-	}, nil
+	return typeDepSet
 }
 
 // ConstructSequenceSelect is part of the exec.Factory interface.
@@ -57,6 +57,9 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER TENANT ALL SET ??`, `ALTER TENANT`},
 		{`ALTER TENANT ALL RESET ??`, `ALTER TENANT`},
 
+		{`ALTER FUNCTION ??`, `ALTER FUNCTION`},
+		{`ALTER FUNCTION f(int) ??`, `ALTER FUNCTION`},
+
 		{`ALTER TYPE ??`, `ALTER TYPE`},
 		{`ALTER TYPE t ??`, `ALTER TYPE`},
 		{`ALTER TYPE t ADD VALUE ??`, `ALTER TYPE`},
@@ -136,6 +139,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`CREATE EXTENSION ??`, `CREATE EXTENSION`},
 
+		{`CREATE FUNCTION ??`, `CREATE FUNCTION`},
+		{`CREATE OR REPLACE FUNCTION f(??`, `CREATE FUNCTION`},
+
 		{`CREATE USER blih ??`, `CREATE ROLE`},
 		{`CREATE USER blih WITH ??`, `CREATE ROLE`},
 
@@ -161,6 +167,17 @@ func TestContextualHelp(t *testing.T) {
 
 		{`CREATE TYPE blah AS ENUM ??`, `CREATE TYPE`},
 		{`DROP TYPE ??`, `DROP TYPE`},
+		{`CREATE DOMAIN ??`, `CREATE DOMAIN`},
+		{`DROP DOMAIN ??`, `DROP DOMAIN`},
+
+		{`DROP FUNCTION ??`, `DROP FUNCTION`},
+		{`DROP FUNCTION IF EXISTS f ??`, `DROP FUNCTION`},
+
+		{`CREATE TRIGGER ??`, `CREATE TRIGGER`},
+		{`CREATE TRIGGER t BEFORE INSERT ??`, `CREATE TRIGGER`},
+
+		{`DROP TRIGGER ??`, `DROP TRIGGER`},
+		{`DROP TRIGGER IF EXISTS t ON a ??`, `DROP TRIGGER`},
 
 		{`CREATE SCHEMA IF ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA IF NOT ??`, `CREATE SCHEMA`},
@@ -244,6 +261,11 @@ func TestContextualHelp(t *testing.T) {
 		{`MOVE ??`, `MOVE`},
 		{`MOVE 1 ??`, `MOVE`},
 
+		{`LISTEN ??`, `LISTEN`},
+		{`UNLISTEN ??`, `UNLISTEN`},
+		{`NOTIFY ??`, `NOTIFY`},
+		{`NOTIFY foo, ??`, `NOTIFY`},
+
 		{`INSERT INTO ??`, `INSERT`},
 		{`INSERT INTO blah (??`, `<SELECTCLAUSE>`},
 		{`INSERT INTO blah VALUES (1) RETURNING ??`, `INSERT`},
@@ -440,6 +462,9 @@ func TestContextualHelp(t *testing.T) {
 		{`SET LOCAL TIME ??`, `SET LOCAL`},
 		{`SET LOCAL TIME ZONE 'UTC' ??`, `SET LOCAL`},
 
+		{`SET CONSTRAINTS ??`, `SET CONSTRAINTS`},
+		{`SET CONSTRAINTS ALL ??`, `SET CONSTRAINTS`},
+
 		{`SET TRANSACTION ??`, `SET TRANSACTION`},
 		{`SET TRANSACTION ISOLATION LEVEL SNAPSHOT ??`, `SET TRANSACTION`},
 		{`SET TIME ??`, `SET SESSION`},
@@ -389,6 +389,9 @@ func (u *sqlSymUnion) colQualElem() tree.ColumnQualification {
 func (u *sqlSymUnion) colQuals() []tree.NamedColumnQualification {
     return u.val.([]tree.NamedColumnQualification)
 }
+func (u *sqlSymUnion) compositeTypeList() []tree.CompositeTypeElem {
+    return u.val.([]tree.CompositeTypeElem)
+}
 func (u *sqlSymUnion) storageParam() tree.StorageParam {
     return u.val.(tree.StorageParam)
 }
@@ -599,6 +602,12 @@ func (u *sqlSymUnion) idxElem() tree.IndexElem {
 func (u *sqlSymUnion) idxElems() tree.IndexElemList {
     return u.val.(tree.IndexElemList)
 }
+func (u *sqlSymUnion) exclusionElem() tree.ExclusionConstraintElem {
+    return u.val.(tree.ExclusionConstraintElem)
+}
+func (u *sqlSymUnion) exclusionElems() tree.ExclusionConstraintElemList {
+    return u.val.(tree.ExclusionConstraintElemList)
+}
 func (u *sqlSymUnion) dropBehavior() tree.DropBehavior {
     return u.val.(tree.DropBehavior)
 }
@@ -686,6 +695,9 @@ func (u *sqlSymUnion) transactionModes() tree.TransactionModes {
 func (u *sqlSymUnion) compositeKeyMatchMethod() tree.CompositeKeyMatchMethod {
   return u.val.(tree.CompositeKeyMatchMethod)
 }
+func (u *sqlSymUnion) constraintDeferrability() tree.ConstraintDeferrability {
+  return u.val.(tree.ConstraintDeferrability)
+}
 func (u *sqlSymUnion) referenceAction() tree.ReferenceAction {
     return u.val.(tree.ReferenceAction)
 }
@@ -781,6 +793,36 @@ func (u *sqlSymUnion) cursorScrollOption() tree.CursorScrollOption {
 func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
     return u.val.(tree.CursorStmt)
 }
+func (u *sqlSymUnion) functionOptions() tree.FunctionOptions {
+    return u.val.(tree.FunctionOptions)
+}
+func (u *sqlSymUnion) functionOption() tree.FunctionOption {
+    return u.val.(tree.FunctionOption)
+}
+func (u *sqlSymUnion) functionArgs() tree.FuncArgs {
+    return u.val.(tree.FuncArgs)
+}
+func (u *sqlSymUnion) functionArg() tree.FuncArg {
+    return u.val.(tree.FuncArg)
+}
+func (u *sqlSymUnion) functionArgClass() tree.FuncArgClass {
+    return u.val.(tree.FuncArgClass)
+}
+func (u *sqlSymUnion) funcObj() tree.FuncObj {
+    return u.val.(tree.FuncObj)
+}
+func (u *sqlSymUnion) funcObjs() tree.FuncObjs {
+    return u.val.(tree.FuncObjs)
+}
+func (u *sqlSymUnion) triggerActionTime() tree.TriggerActionTime {
+    return u.val.(tree.TriggerActionTime)
+}
+func (u *sqlSymUnion) triggerEvent() tree.TriggerEvent {
+    return u.val.(tree.TriggerEvent)
+}
+func (u *sqlSymUnion) triggerEvents() tree.TriggerEvents {
+    return u.val.(tree.TriggerEvents)
+}
 %}
 
 // NB: the %token definitions must come before the %type definitions in this
@@ -808,7 +850,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %token <str> BUCKET_COUNT
 %token <str> BOOLEAN BOTH BOX2D BUNDLE BY
 
-%token <str> CACHE CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
+%token <str> CACHE CALLED CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK CLOSE
 %token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
 %token <str> COMMITTED COMPACT COMPLETE COMPLETIONS CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
@@ -822,7 +864,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DELIMITER DESC DESTINATION DETACHED
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
-%token <str> ELSE ENCODING ENCRYPTED ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
+%token <str> EACH ELSE ENCODING ENCRYPTED ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
 %token <str> EXISTS EXECUTE EXECUTION EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT EXPERIMENTAL_RELOCATE
@@ -831,7 +873,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %token <str> FAILURE FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH
 %token <str> FILES FILTER
 %token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE FORCE_INDEX FORCE_ZIGZAG
-%token <str> FOREIGN FORWARD FREEZE FROM FULL FUNCTION FUNCTIONS
+%token <str> FOREIGN FORMAT FORWARD FREEZE FROM FULL FUNCTION FUNCTIONS
 
 %token <str> GENERATED GEOGRAPHY GEOMETRY GEOMETRYM GEOMETRYZ GEOMETRYZM
 %token <str> GEOMETRYCOLLECTION GEOMETRYCOLLECTIONM GEOMETRYCOLLECTIONZ GEOMETRYCOLLECTIONZM
@@ -840,10 +882,10 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %token <str> HAVING HASH HEADER HIGH HISTOGRAM HOLD HOUR
 
 %token <str> IDENTITY
-%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMPORT IN INCLUDE
+%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMMUTABLE IMPORT IN INCLUDE
 %token <str> INCLUDING INCREMENT INCREMENTAL INCREMENTAL_LOCATION
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS
-%token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERITS INJECT INITIALLY
+%token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERITS INJECT INITIALLY INPUT
 %token <str> INNER INSENSITIVE INSERT INT INTEGER
 %token <str> INTERSECT INTERVAL INTO INTO_DB INVERTED IS ISERROR ISNULL ISOLATION
 
@@ -852,9 +894,9 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %token <str> KEY KEYS KMS KV
 
 %token <str> LANGUAGE LAST LATERAL LATEST LC_CTYPE LC_COLLATE
-%token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT
+%token <str> LEADING LEAKPROOF LEASE LEAST LEFT LESS LEVEL LIKE LIMIT
 %token <str> LINESTRING LINESTRINGM LINESTRINGZ LINESTRINGZM
-%token <str> LIST LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
+%token <str> LIST LISTEN LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
 
 %token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH MOVE
 %token <str> MULTILINESTRING MULTILINESTRINGM MULTILINESTRINGZ MULTILINESTRINGZM
@@ -863,7 +905,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 
 %token <str> NAN NAME NAMES NATURAL NEVER NEW_DB_NAME NEW_KMS NEXT NO NOCANCELQUERY NOCONTROLCHANGEFEED
 %token <str> NOCONTROLJOB NOCREATEDB NOCREATELOGIN NOCREATEROLE NOLOGIN NOMODIFYCLUSTERSETTING
-%token <str> NOSQLLOGIN NO_INDEX_JOIN NO_ZIGZAG_JOIN NO_FULL_SCAN NONE NONVOTERS NORMAL NOT NOTHING NOTNULL
+%token <str> NOSQLLOGIN NO_INDEX_JOIN NO_ZIGZAG_JOIN NO_FULL_SCAN NONE NONVOTERS NORMAL NOT NOTHING NOTIFY NOTNULL
 %token <str> NOVIEWACTIVITY NOVIEWACTIVITYREDACTED NOVIEWCLUSTERSETTING NOWAIT NULL NULLIF NULLS NUMERIC
 
 %token <str> OF OFF OFFSET OID OIDS OIDVECTOR OLD_KMS ON ONLY OPT OPTION OPTIONS OR
@@ -872,24 +914,24 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACEMENT PLACING
 %token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
 %token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIOR PRIORITY PRIVILEGES
-%token <str> PROCEDURAL PUBLIC PUBLICATION
+%token <str> PROCEDURAL PROCEDURE PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY QUOTE
 
 %token <str> RANGE RANGES READ REAL REASON REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
 %token <str> REGCLASS REGION REGIONAL REGIONS REGNAMESPACE REGPROC REGPROCEDURE REGROLE REGTYPE REINDEX
 %token <str> RELATIVE RELOCATE REMOVE_PATH RENAME REPEATABLE REPLACE REPLICATION
-%token <str> RELEASE RESET RESTORE RESTRICT RESTRICTED RESUME RETURNING RETRY REVISION_HISTORY
+%token <str> RELEASE RESET RESTORE RESTRICT RESTRICTED RESUME RETURNING RETURNS RETRY REVISION_HISTORY
 %token <str> REVOKE RIGHT ROLE ROLES ROLLBACK ROLLUP ROUTINES ROW ROWS RSHIFT RULE RUNNING
 
 %token <str> SAVEPOINT SCANS SCATTER SCHEDULE SCHEDULES SCROLL SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
-%token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
+%token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETOF SETS SETTING SETTINGS
 %token <str> SHARE SHOW SIMILAR SIMPLE SKIP SKIP_LOCALITIES_CHECK SKIP_MISSING_FOREIGN_KEYS
 %token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 %token <str> SQLLOGIN
 
-%token <str> START STATE STATISTICS STATUS STDIN STREAM STRICT STRING STORAGE STORE STORED STORING SUBSTRING SUPER
-%token <str> SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENTS
+%token <str> STABLE START STATE STATISTICS STATUS STDIN STDOUT STREAM STRICT STRING STORAGE STORE STORED STORING SUBSTRING SUPER
+%token <str> SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENT STATEMENTS
 
 %token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TENANTS TESTING_RELOCATE TEXT THEN
 %token <str> TIES TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO THROTTLING TRAILING TRACE
@@ -897,11 +939,11 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %token <str> TRUNCATE TRUSTED TYPE TYPES
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
+%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLISTEN UNLOGGED UNSPLIT
 %token <str> UPDATE UPSERT UNSET UNTIL USE USER USERS USING UUID
 
 %token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED
-%token <str> VIEWCLUSTERSETTING VIRTUAL VISIBLE VOTERS
+%token <str> VIEWCLUSTERSETTING VIRTUAL VISIBLE VOLATILE VOTERS
 
 %token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
 
@@ -956,6 +998,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <tree.Statement> alter_role_stmt
 %type <*tree.SetVar> set_or_reset_clause
 %type <tree.Statement> alter_type_stmt
+%type <tree.Statement> alter_func_stmt
 %type <tree.Statement> alter_schema_stmt
 %type <tree.Statement> alter_unsupported_stmt
 
@@ -1039,6 +1082,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <tree.Statement> comment_stmt
 %type <tree.Statement> commit_stmt
 %type <tree.Statement> copy_from_stmt
+%type <tree.Statement> copy_to_stmt
 
 %type <tree.Statement> create_stmt
 %type <tree.Statement> create_changefeed_stmt create_replication_stream_stmt
@@ -1052,6 +1096,8 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <tree.Statement> create_table_stmt
 %type <tree.Statement> create_table_as_stmt
 %type <tree.Statement> create_view_stmt
+%type <tree.Statement> create_func_stmt
+%type <tree.Statement> create_trigger_stmt
 %type <tree.Statement> create_sequence_stmt
 
 %type <tree.Statement> create_stats_stmt
@@ -1060,8 +1106,12 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <*tree.CreateStatsOptions> create_stats_option
 
 %type <tree.Statement> create_type_stmt
+%type <tree.Statement> create_domain_stmt
 %type <tree.Statement> delete_stmt
 %type <tree.Statement> discard_stmt
+%type <tree.Statement> listen_stmt
+%type <tree.Statement> unlisten_stmt
+%type <tree.Statement> notify_stmt
 
 %type <tree.Statement> drop_stmt
 %type <tree.Statement> drop_ddl_stmt
@@ -1071,7 +1121,10 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <tree.Statement> drop_schema_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_type_stmt
+%type <tree.Statement> drop_domain_stmt
 %type <tree.Statement> drop_view_stmt
+%type <tree.Statement> drop_func_stmt
+%type <tree.Statement> drop_trigger_stmt
 %type <tree.Statement> drop_sequence_stmt
 
 %type <tree.Statement> analyze_stmt
@@ -1109,6 +1162,8 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <tree.Statement> set_session_stmt
 %type <tree.Statement> set_csetting_stmt set_or_reset_csetting_stmt
 %type <tree.Statement> set_transaction_stmt
+%type <tree.Statement> set_constraints_stmt
+%type <bool> constraints_mode
 %type <tree.Statement> set_exprs_internal
 %type <tree.Statement> generic_set
 %type <tree.Statement> set_rest_more
@@ -1188,7 +1243,8 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <*tree.BackupOptions> opt_with_backup_options backup_options backup_options_list
 %type <*tree.RestoreOptions> opt_with_restore_options restore_options restore_options_list
 %type <tree.ShowBackupDetails> show_backup_details
-%type <*tree.CopyOptions> opt_with_copy_options copy_options copy_options_list
+%type <*tree.CopyOptions> opt_with_copy_options copy_options copy_options_list copy_generic_options_list
+%type <*tree.CopyOptions> copy_force_options copy_generic_options
 %type <str> import_format
 %type <str> storage_parameter_key
 %type <tree.NameList> storage_parameter_key_list
@@ -1222,6 +1278,21 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <tree.AlterIndexCmds> alter_index_cmds
 
 %type <tree.DropBehavior> opt_drop_behavior
+%type <tree.TriggerActionTime> trigger_action_time
+%type <tree.TriggerEvent> trigger_event
+%type <tree.TriggerEvents> trigger_event_list
+%type <tree.Expr> opt_trigger_when
+
+%type <bool> opt_or_replace opt_return_set
+%type <str> param_name func_as
+%type <tree.FuncArgs> opt_func_arg_with_default_list func_arg_with_default_list func_args func_args_list
+%type <tree.FuncArg> func_arg_with_default func_arg
+%type <tree.FuncArgClass> func_arg_class
+%type <tree.ResolvableTypeReference> func_return_type func_arg_type
+%type <tree.FunctionOptions> opt_create_func_opt_list create_func_opt_list alter_func_opt_list
+%type <tree.FunctionOption> create_func_opt_item common_func_opt_item
+%type <tree.FuncObj> function_with_argtypes
+%type <tree.FuncObjs> function_with_argtypes_list
 
 %type <tree.ValidationBehavior> opt_validate_behavior
 
@@ -1307,7 +1378,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <*tree.Limit> select_limit opt_select_limit
 %type <tree.TableNames> relation_expr_list
 %type <tree.ReturningClause> returning_clause
-%type <empty> opt_using_clause
+%type <tree.TableExprs> opt_using_clause
 %type <tree.RefreshDataOption> opt_clear_data
 
 %type <[]tree.SequenceOption> sequence_option_list opt_sequence_option_list
@@ -1331,6 +1402,10 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 
 %type <bool> opt_unique opt_concurrently opt_cluster opt_without_index
 %type <bool> opt_index_access_method
+%type <str> opt_exclusion_access_method
+%type <tree.ExclusionConstraintElem> exclusion_elem
+%type <tree.ExclusionConstraintElemList> exclusion_elem_list
+%type <tree.Expr> opt_exclusion_where_clause
 
 %type <*tree.Limit> limit_clause offset_clause opt_limit_clause
 %type <tree.Expr> select_fetch_first_value
@@ -1387,6 +1462,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 
 %type <str> explain_option_name
 %type <[]string> explain_option_list opt_enum_val_list enum_val_list
+%type <[]tree.CompositeTypeElem> opt_composite_type_list composite_type_list
 
 %type <tree.ResolvableTypeReference> typename simple_typename cast_target
 %type <*types.T> const_typename
@@ -1434,6 +1510,7 @@ func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
 %type <tree.NamedColumnQualification> col_qualification create_as_col_qualification
 %type <tree.ColumnQualification> col_qualification_elem create_as_col_qualification_elem
 %type <tree.CompositeKeyMatchMethod> key_match
+%type <tree.ConstraintDeferrability> opt_deferrable
 %type <tree.ReferenceActions> reference_actions
 %type <tree.ReferenceAction> reference_action reference_on_delete reference_on_update
 
@@ -1574,6 +1651,7 @@ stmt:
 | preparable_stmt           // help texts in sub-rule
 | analyze_stmt              // EXTEND WITH HELP: ANALYZE
 | copy_from_stmt
+| copy_to_stmt
 | comment_stmt
 | execute_stmt              // EXTEND WITH HELP: EXECUTE
 | deallocate_stmt           // EXTEND WITH HELP: DEALLOCATE
@@ -1592,6 +1670,9 @@ stmt:
 | declare_cursor_stmt       // EXTEND WITH HELP: DECLARE
 | fetch_cursor_stmt         // EXTEND WITH HELP: FETCH
 | move_cursor_stmt          // EXTEND WITH HELP: MOVE
+| listen_stmt               // EXTEND WITH HELP: LISTEN
+| unlisten_stmt             // EXTEND WITH HELP: UNLISTEN
+| notify_stmt               // EXTEND WITH HELP: NOTIFY
 | reindex_stmt
 | /* EMPTY */
   {
@@ -1618,10 +1699,73 @@ alter_ddl_stmt:
 | alter_partition_stmt          // EXTEND WITH HELP: ALTER PARTITION
 | alter_schema_stmt             // EXTEND WITH HELP: ALTER SCHEMA
 | alter_type_stmt               // EXTEND WITH HELP: ALTER TYPE
+| alter_func_stmt               // EXTEND WITH HELP: ALTER FUNCTION
 | alter_default_privileges_stmt // EXTEND WITH HELP: ALTER DEFAULT PRIVILEGES
 | alter_changefeed_stmt         // EXTEND WITH HELP: ALTER CHANGEFEED
 | alter_backup_stmt             // EXTEND WITH HELP: ALTER BACKUP
 
+// %Help: ALTER FUNCTION - change the definition of a function
+// %Category: DDL
+// %Text:
+// ALTER FUNCTION <name> [ ( [ [ IN ] [ <argname> ] <argtype> [, ...] ] ) ]
+//    action [ ... ] [ RESTRICT ]
+// ALTER FUNCTION <name> [ ( [ [ IN ] [ <argname> ] <argtype> [, ...] ] ) ]
+//    RENAME TO <new_name>
+// ALTER FUNCTION <name> [ ( [ [ IN ] [ <argname> ] <argtype> [, ...] ] ) ]
+//    OWNER TO <new_owner>
+// ALTER FUNCTION <name> [ ( [ [ IN ] [ <argname> ] <argtype> [, ...] ] ) ]
+//    SET SCHEMA <new_schema>
+//
+// where action is one of:
+//    { CALLED ON NULL INPUT | RETURNS NULL ON NULL INPUT | STRICT }
+//    { IMMUTABLE | STABLE | VOLATILE }
+//    [ NOT ] LEAKPROOF
+// %SeeAlso: CREATE FUNCTION, DROP FUNCTION
+alter_func_stmt:
+  ALTER FUNCTION function_with_argtypes alter_func_opt_list opt_restrict
+  {
+    $$.val = &tree.AlterFunctionOptions{
+      Function: $3.funcObj(),
+      Options: $4.functionOptions(),
+    }
+  }
+| ALTER FUNCTION function_with_argtypes RENAME TO name
+  {
+    $$.val = &tree.AlterFunctionRename{
+      Function: $3.funcObj(),
+      NewName: tree.Name($6),
+    }
+  }
+| ALTER FUNCTION function_with_argtypes OWNER TO role_spec
+  {
+    $$.val = &tree.AlterFunctionSetOwner{
+      Function: $3.funcObj(),
+      NewOwner: $6.roleSpec(),
+    }
+  }
+| ALTER FUNCTION function_with_argtypes SET SCHEMA schema_name
+  {
+    $$.val = &tree.AlterFunctionSetSchema{
+      Function: $3.funcObj(),
+      NewSchemaName: tree.Name($6),
+    }
+  }
+| ALTER FUNCTION error // SHOW HELP: ALTER FUNCTION
+
+alter_func_opt_list:
+  common_func_opt_item
+  {
+    $$.val = tree.FunctionOptions{$1.functionOption()}
+  }
+| alter_func_opt_list common_func_opt_item
+  {
+    $$.val = append($1.functionOptions(), $2.functionOption())
+  }
+
+opt_restrict:
+  RESTRICT {}
+| /* EMPTY */ {}
+
 // %Help: ALTER TABLE - change the definition of a table
 // %Category: DDL
 // %Text:
@@ -3296,11 +3440,7 @@ import_format:
   }
 
 alter_unsupported_stmt:
-  ALTER FUNCTION error
-  {
-    return unimplementedWithIssueDetail(sqllex, 17511, "alter function")
-  }
-| ALTER DOMAIN error
+  ALTER DOMAIN error
   {
     return unimplemented(sqllex, "alter domain")
   }
@@ -3483,11 +3623,38 @@ copy_from_stmt:
     return unimplemented(sqllex, "copy from unsupported format")
   }
 
+copy_to_stmt:
+  COPY table_name opt_column_list TO STDOUT opt_with_copy_options
+  {
+    /* FORCE DOC */
+    $$.val = &tree.CopyTo{
+       Table: $2.unresolvedObjectName().ToTableName(),
+       Columns: $3.nameList(),
+       Options: *$6.copyOptions(),
+    }
+  }
+| COPY '(' select_stmt ')' TO STDOUT opt_with_copy_options
+  {
+    /* FORCE DOC */
+    $$.val = &tree.CopyTo{
+       Statement: $3.slct(),
+       Options: *$7.copyOptions(),
+    }
+  }
+| COPY table_name opt_column_list TO error
+  {
+    return unimplemented(sqllex, "copy to unsupported destination")
+  }
+
 opt_with_copy_options:
   opt_with copy_options_list
   {
     $$.val = $2.copyOptions()
   }
+| opt_with '(' copy_generic_options_list ')'
+  {
+    $$.val = $3.copyOptions()
+  }
 | /* EMPTY */
   {
     $$.val = &tree.CopyOptions{}
@@ -3498,12 +3665,85 @@ copy_options_list:
   {
     $$.val = $1.copyOptions()
   }
+| copy_force_options
+  {
+    $$.val = $1.copyOptions()
+  }
 | copy_options_list copy_options
   {
     if err := $1.copyOptions().CombineWith($2.copyOptions()); err != nil {
       return setErr(sqllex, err)
     }
   }
+| copy_options_list copy_force_options
+  {
+    if err := $1.copyOptions().CombineWith($2.copyOptions()); err != nil {
+      return setErr(sqllex, err)
+    }
+  }
+
+copy_generic_options_list:
+  copy_generic_options
+  {
+    $$.val = $1.copyOptions()
+  }
+| copy_generic_options_list ',' copy_generic_options
+  {
+    if err := $1.copyOptions().CombineWith($3.copyOptions()); err != nil {
+      return setErr(sqllex, err)
+    }
+  }
+
+// copy_force_options are the FORCE options of the space-separated option
+// syntax, whose column lists are not parenthesized.
+copy_force_options:
+  FORCE QUOTE '*'
+  {
+    $$.val = &tree.CopyOptions{ForceQuoteAll: true}
+  }
+| FORCE QUOTE name_list
+  {
+    $$.val = &tree.CopyOptions{ForceQuote: $3.nameList()}
+  }
+| FORCE NOT NULL name_list
+  {
+    $$.val = &tree.CopyOptions{ForceNotNull: $4.nameList()}
+  }
+| FORCE NULL name_list
+  {
+    $$.val = &tree.CopyOptions{ForceNull: $3.nameList()}
+  }
+
+// copy_generic_options are the options of the parenthesized, comma-separated
+// option syntax. The FORCE options are spelled force_quote, force_not_null
+// and force_null there, and take a parenthesized column list.
+copy_generic_options:
+  copy_options
+  {
+    $$.val = $1.copyOptions()
+  }
+| IDENT '*'
+  {
+    if $1 != "force_quote" {
+      sqllex.Error(fmt.Sprintf("option %q does not accept *", $1))
+      return 1
+    }
+    $$.val = &tree.CopyOptions{ForceQuoteAll: true}
+  }
+| IDENT '(' name_list ')'
+  {
+    switch $1 {
+    case "force_quote":
+      $$.val = &tree.CopyOptions{ForceQuote: $3.nameList()}
+    case "force_not_null":
+      $$.val = &tree.CopyOptions{ForceNotNull: $3.nameList()}
+    case "force_null":
+      $$.val = &tree.CopyOptions{ForceNull: $3.nameList()}
+    default:
+      sqllex.Error(fmt.Sprintf("unrecognized COPY option %q", $1))
+      return 1
+    }
+  }
 
 copy_options:
   DESTINATION '=' string_or_placeholder
@@ -3518,6 +3758,18 @@ copy_options:
   {
     $$.val = &tree.CopyOptions{CopyFormat: tree.CopyFormatCSV}
   }
+| FORMAT BINARY
+  {
+    $$.val = &tree.CopyOptions{CopyFormat: tree.CopyFormatBinary}
+  }
+| FORMAT CSV
+  {
+    $$.val = &tree.CopyOptions{CopyFormat: tree.CopyFormatCSV}
+  }
+| FORMAT TEXT
+  {
+    $$.val = &tree.CopyOptions{CopyFormat: tree.CopyFormatText}
+  }
 | DELIMITER string_or_placeholder
   {
     $$.val = &tree.CopyOptions{Delimiter: $2.expr()}
@@ -3526,41 +3778,29 @@ copy_options:
   {
     $$.val = &tree.CopyOptions{Null: $2.expr()}
   }
-| OIDS error
+| OIDS
   {
-    return unimplementedWithIssueDetail(sqllex, 41608, "oids")
+    $$.val = &tree.CopyOptions{Oids: true}
   }
-| FREEZE error
+| FREEZE
   {
-    return unimplementedWithIssueDetail(sqllex, 41608, "freeze")
+    $$.val = &tree.CopyOptions{Freeze: true}
   }
-| HEADER error
+| HEADER
   {
-    return unimplementedWithIssueDetail(sqllex, 41608, "header")
+    $$.val = &tree.CopyOptions{Header: true}
   }
 | QUOTE SCONST
   {
-    return unimplementedWithIssueDetail(sqllex, 41608, "quote")
+    $$.val = &tree.CopyOptions{Quote: tree.NewStrVal($2)}
   }
 | ESCAPE SCONST error
   {
     $$.val = &tree.CopyOptions{Escape: tree.NewStrVal($2)}
   }
-| FORCE QUOTE error
-  {
-    return unimplementedWithIssueDetail(sqllex, 41608, "force quote")
-  }
-| FORCE NOT NULL error
-  {
-    return unimplementedWithIssueDetail(sqllex, 41608, "force not null")
-  }
-| FORCE NULL error
-  {
-    return unimplementedWithIssueDetail(sqllex, 41608, "force null")
-  }
-| ENCODING SCONST error
+| ENCODING SCONST
   {
-    return unimplementedWithIssueDetail(sqllex, 41608, "encoding")
+    $$.val = &tree.CopyOptions{Encoding: tree.NewStrVal($2)}
   }
 
 // %Help: CANCEL
@@ -3719,7 +3959,10 @@ comment_stmt:
     $$.val = &tree.CommentOnConstraint{Constraint:tree.Name($4), Table: $6.unresolvedObjectName(), Comment: $8.strPtr()}
   }
 | COMMENT ON EXTENSION error { return unimplementedWithIssueDetail(sqllex, 74777, "comment on extension") }
-| COMMENT ON FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "comment on function") }
+| COMMENT ON FUNCTION function_with_argtypes IS comment_text
+  {
+    $$.val = &tree.CommentOnFunction{Function: $4.funcObj(), Comment: $6.strPtr()}
+  }
 
 comment_text:
   SCONST
@@ -3779,8 +4022,6 @@ create_unsupported:
 | CREATE DEFAULT CONVERSION error { return unimplemented(sqllex, "create def conv") }
 | CREATE FOREIGN TABLE error { return unimplemented(sqllex, "create foreign table") }
 | CREATE FOREIGN DATA error { return unimplemented(sqllex, "create fdw") }
-| CREATE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
-| CREATE OR REPLACE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
 | CREATE opt_or_replace opt_trusted opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "create language " + $6) }
 | CREATE OPERATOR error { return unimplementedWithIssue(sqllex, 65017) }
 | CREATE PUBLICATION error { return unimplemented(sqllex, "create publication") }
@@ -3789,11 +4030,10 @@ create_unsupported:
 | CREATE SUBSCRIPTION error { return unimplemented(sqllex, "create subscription") }
 | CREATE TABLESPACE error { return unimplementedWithIssueDetail(sqllex, 54113, "create tablespace") }
 | CREATE TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "create text") }
-| CREATE TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "create trigger") }
 
 opt_or_replace:
-  OR REPLACE {}
-| /* EMPTY */ {}
+  OR REPLACE { $$.val = true }
+| /* EMPTY */ { $$.val = false }
 
 opt_trusted:
   TRUSTED {}
@@ -3809,12 +4049,10 @@ drop_unsupported:
 | DROP CAST error { return unimplemented(sqllex, "drop cast") }
 | DROP COLLATION error { return unimplemented(sqllex, "drop collation") }
 | DROP CONVERSION error { return unimplemented(sqllex, "drop conversion") }
-| DROP DOMAIN error { return unimplementedWithIssueDetail(sqllex, 27796, "drop") }
 | DROP EXTENSION IF EXISTS name error { return unimplementedWithIssueDetail(sqllex, 74777, "drop extension if exists") }
 | DROP EXTENSION name error { return unimplementedWithIssueDetail(sqllex, 74777, "drop extension") }
 | DROP FOREIGN TABLE error { return unimplemented(sqllex, "drop foreign table") }
 | DROP FOREIGN DATA error { return unimplemented(sqllex, "drop fdw") }
-| DROP FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "drop function") }
 | DROP opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "drop language " + $4) }
 | DROP OPERATOR error { return unimplemented(sqllex, "drop operator") }
 | DROP PUBLICATION error { return unimplemented(sqllex, "drop publication") }
@@ -3822,7 +4060,6 @@ drop_unsupported:
 | DROP SERVER error { return unimplemented(sqllex, "drop server") }
 | DROP SUBSCRIPTION error { return unimplemented(sqllex, "drop subscription") }
 | DROP TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "drop text") }
-| DROP TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "drop") }
 
 create_ddl_stmt:
   create_database_stmt // EXTEND WITH HELP: CREATE DATABASE
@@ -3833,8 +4070,365 @@ create_ddl_stmt:
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
 | CREATE opt_persistence_temp_table TABLE error   // SHOW HELP: CREATE TABLE
 | create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
+| create_domain_stmt   // EXTEND WITH HELP: CREATE DOMAIN
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
+| create_func_stmt     // EXTEND WITH HELP: CREATE FUNCTION
+| create_trigger_stmt  // EXTEND WITH HELP: CREATE TRIGGER
+
+// %Help: CREATE FUNCTION - define a new function
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] FUNCTION
+//    <name> ( [ [ IN ] [ <argname> ] <argtype> [, ...] ] )
+//    RETURNS <rettype>
+//  { LANGUAGE SQL
+//    | { IMMUTABLE | STABLE | VOLATILE }
+//    | [ NOT ] LEAKPROOF
+//    | { CALLED ON NULL INPUT | RETURNS NULL ON NULL INPUT | STRICT }
+//    | AS '<definition>'
+//  } ...
+// %SeeAlso: DROP FUNCTION, ALTER FUNCTION
+create_func_stmt:
+  CREATE opt_or_replace FUNCTION db_object_name '(' opt_func_arg_with_default_list ')' RETURNS opt_return_set func_return_type opt_create_func_opt_list
+  {
+    name := $4.unresolvedObjectName().ToFunctionName()
+    $$.val = &tree.CreateFunction{
+      Replace: $2.bool(),
+      FuncName: name,
+      Args: $6.functionArgs(),
+      ReturnType: tree.FuncReturnType{
+        Type: $10.typeReference(),
+        IsSet: $9.bool(),
+      },
+      Options: $11.functionOptions(),
+    }
+  }
+| CREATE opt_or_replace FUNCTION db_object_name '(' opt_func_arg_with_default_list ')' RETURNS TABLE error
+  {
+    return unimplementedWithIssueDetail(sqllex, 17511, "create function returns table")
+  }
+| CREATE opt_or_replace FUNCTION error // SHOW HELP: CREATE FUNCTION
+
+opt_func_arg_with_default_list:
+  func_arg_with_default_list
+  {
+    $$.val = $1.functionArgs()
+  }
+| /* Empty */
+  {
+    $$.val = tree.FuncArgs{}
+  }
+
+func_arg_with_default_list:
+  func_arg_with_default
+  {
+    $$.val = tree.FuncArgs{$1.functionArg()}
+  }
+| func_arg_with_default_list ',' func_arg_with_default
+  {
+    $$.val = append($1.functionArgs(), $3.functionArg())
+  }
+
+func_arg_with_default:
+  func_arg
+| func_arg DEFAULT error
+  {
+    return unimplementedWithIssueDetail(sqllex, 17511, "function argument default")
+  }
+| func_arg '=' error
+  {
+    return unimplementedWithIssueDetail(sqllex, 17511, "function argument default")
+  }
+
+func_arg:
+  func_arg_class param_name func_arg_type
+  {
+    $$.val = tree.FuncArg{
+      Name: tree.Name($2),
+      Type: $3.typeReference(),
+      Class: $1.functionArgClass(),
+    }
+  }
+| param_name func_arg_class func_arg_type
+  {
+    $$.val = tree.FuncArg{
+      Name: tree.Name($1),
+      Type: $3.typeReference(),
+      Class: $2.functionArgClass(),
+    }
+  }
+| param_name func_arg_type
+  {
+    $$.val = tree.FuncArg{
+      Name: tree.Name($1),
+      Type: $2.typeReference(),
+      Class: tree.FunctionArgIn,
+    }
+  }
+| func_arg_class func_arg_type
+  {
+    $$.val = tree.FuncArg{
+      Type: $2.typeReference(),
+      Class: $1.functionArgClass(),
+    }
+  }
+| func_arg_type
+  {
+    $$.val = tree.FuncArg{
+      Type: $1.typeReference(),
+      Class: tree.FunctionArgIn,
+    }
+  }
+
+func_arg_class:
+  IN
+  {
+    $$.val = tree.FunctionArgIn
+  }
+| OUT
+  {
+    $$.val = tree.FunctionArgOut
+  }
+| IN OUT
+  {
+    $$.val = tree.FunctionArgInOut
+  }
+| VARIADIC
+  {
+    $$.val = tree.FunctionArgVariadic
+  }
+
+func_arg_type:
+  typename
+
+func_return_type:
+  func_arg_type
+
+opt_return_set:
+  SETOF
+  {
+    $$.val = true
+  }
+| /* Empty */
+  {
+    $$.val = false
+  }
+
+opt_create_func_opt_list:
+  create_func_opt_list
+  {
+    $$.val = $1.functionOptions()
+  }
+| /* Empty */
+  {
+    $$.val = tree.FunctionOptions{}
+  }
+
+create_func_opt_list:
+  create_func_opt_item
+  {
+    $$.val = tree.FunctionOptions{$1.functionOption()}
+  }
+| create_func_opt_list create_func_opt_item
+  {
+    $$.val = append($1.functionOptions(), $2.functionOption())
+  }
+
+create_func_opt_item:
+  AS func_as
+  {
+    $$.val = tree.FunctionBodyStr($2)
+  }
+| LANGUAGE non_reserved_word_or_sconst
+  {
+    lang, err := tree.AsFunctionLanguage($2)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = lang
+  }
+| common_func_opt_item
+  {
+    $$.val = $1.functionOption()
+  }
+
+common_func_opt_item:
+  CALLED ON NULL INPUT
+  {
+    $$.val = tree.FunctionCalledOnNullInput
+  }
+| RETURNS NULL ON NULL INPUT
+  {
+    $$.val = tree.FunctionReturnsNullOnNullInput
+  }
+| STRICT
+  {
+    $$.val = tree.FunctionStrict
+  }
+| IMMUTABLE
+  {
+    $$.val = tree.FunctionImmutable
+  }
+| STABLE
+  {
+    $$.val = tree.FunctionStable
+  }
+| VOLATILE
+  {
+    $$.val = tree.FunctionVolatile
+  }
+| LEAKPROOF
+  {
+    $$.val = tree.FunctionLeakproof(true)
+  }
+| NOT LEAKPROOF
+  {
+    $$.val = tree.FunctionLeakproof(false)
+  }
+
+func_as:
+  SCONST
+
+// Names of function arguments.
+param_name:
+  type_function_name
+
+func_args:
+  '(' func_args_list ')'
+  {
+    $$.val = $2.functionArgs()
+  }
+| '(' ')'
+  {
+    $$.val = tree.FuncArgs{}
+  }
+
+func_args_list:
+  func_arg
+  {
+    $$.val = tree.FuncArgs{$1.functionArg()}
+  }
+| func_args_list ',' func_arg
+  {
+    $$.val = append($1.functionArgs(), $3.functionArg())
+  }
+
+function_with_argtypes:
+  db_object_name func_args
+  {
+    name := $1.unresolvedObjectName().ToFunctionName()
+    $$.val = tree.FuncObj{
+      FuncName: name,
+      Args: $2.functionArgs(),
+    }
+  }
+| db_object_name
+  {
+    name := $1.unresolvedObjectName().ToFunctionName()
+    $$.val = tree.FuncObj{
+      FuncName: name,
+    }
+  }
+
+function_with_argtypes_list:
+  function_with_argtypes
+  {
+    $$.val = tree.FuncObjs{$1.funcObj()}
+  }
+| function_with_argtypes_list ',' function_with_argtypes
+  {
+    $$.val = append($1.funcObjs(), $3.funcObj())
+  }
+
+// %Help: CREATE TRIGGER - define a new trigger
+// %Category: DDL
+// %Text:
+// CREATE TRIGGER <name> { BEFORE | AFTER } <event> [ OR ... ]
+//    ON <tablename> FOR EACH ROW
+//    [ WHEN ( <condition> ) ]
+//    EXECUTE { FUNCTION | PROCEDURE } <funcname> ( [ <arguments> ] )
+//
+// Events:
+//    INSERT, UPDATE, DELETE
+//
+// The condition and the arguments can refer to the columns of the modified
+// row as OLD.<colname> and NEW.<colname>.
+// %SeeAlso: DROP TRIGGER, CREATE FUNCTION
+create_trigger_stmt:
+  CREATE TRIGGER name trigger_action_time trigger_event_list ON table_name FOR EACH ROW opt_trigger_when EXECUTE function_or_procedure db_object_name '(' opt_expr_list ')'
+  {
+    $$.val = &tree.CreateTrigger{
+      Name: tree.Name($3),
+      ActionTime: $4.triggerActionTime(),
+      Events: $5.triggerEvents(),
+      Table: $7.unresolvedObjectName().ToTableName(),
+      When: $11.expr(),
+      FuncName: $14.unresolvedObjectName().ToFunctionName(),
+      Args: $16.exprs(),
+    }
+  }
+| CREATE TRIGGER name trigger_action_time trigger_event_list ON table_name FOR EACH STATEMENT error
+  {
+    return unimplementedWithIssueDetail(sqllex, 28296, "statement-level triggers")
+  }
+| CREATE TRIGGER error // SHOW HELP: CREATE TRIGGER
+
+trigger_action_time:
+  BEFORE
+  {
+    $$.val = tree.TriggerActionTimeBefore
+  }
+| AFTER
+  {
+    $$.val = tree.TriggerActionTimeAfter
+  }
+
+trigger_event_list:
+  trigger_event
+  {
+    $$.val = tree.TriggerEvents{$1.triggerEvent()}
+  }
+| trigger_event_list OR trigger_event
+  {
+    $$.val = append($1.triggerEvents(), $3.triggerEvent())
+  }
+
+trigger_event:
+  INSERT
+  {
+    $$.val = tree.TriggerEventInsert
+  }
+| UPDATE
+  {
+    $$.val = tree.TriggerEventUpdate
+  }
+| DELETE
+  {
+    $$.val = tree.TriggerEventDelete
+  }
+| UPDATE OF error
+  {
+    return unimplementedWithIssueDetail(sqllex, 28296, "column-specific update triggers")
+  }
+| TRUNCATE error
+  {
+    return unimplementedWithIssueDetail(sqllex, 28296, "truncate triggers")
+  }
+
+opt_trigger_when:
+  WHEN '(' a_expr ')'
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
+function_or_procedure:
+  FUNCTION {}
+| PROCEDURE {}
 
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
@@ -4070,7 +4664,9 @@ replication_options:
 
 // %Help: DELETE - delete rows from a table
 // %Category: DML
-// %Text: DELETE FROM <tablename> [WHERE <expr>]
+// %Text: DELETE FROM <tablename> [[AS] <name>]
+//               [USING <table_expr> [, ...]]
+//               [WHERE <expr>]
 //               [ORDER BY <exprs...>]
 //               [LIMIT <expr>]
 //               [RETURNING <exprs...>]
@@ -4081,6 +4677,7 @@ delete_stmt:
     $$.val = &tree.Delete{
       With: $1.with(),
       Table: $4.tblExpr(),
+      Using: $5.tblExprs(),
       Where: tree.NewWhere(tree.AstWhere, $6.expr()),
       OrderBy: $7.orderBy(),
       Limit: $8.limit(),
@@ -4090,8 +4687,14 @@ delete_stmt:
 | opt_with_clause DELETE error // SHOW HELP: DELETE
 
 opt_using_clause:
-  USING from_list { return unimplementedWithIssueDetail(sqllex, 40963, "delete using") }
-| /* EMPTY */ { }
+  USING from_list
+  {
+    $$.val = $2.tblExprs()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.TableExprs{}
+  }
 
 
 // %Help: DISCARD - reset the session to its initial state
@@ -4108,6 +4711,47 @@ discard_stmt:
 | DISCARD TEMPORARY { return unimplemented(sqllex, "discard temp") }
 | DISCARD error // SHOW HELP: DISCARD
 
+// %Help: LISTEN - listen for notifications on a channel
+// %Category: Misc
+// %Text: LISTEN <channel>
+// %SeeAlso: NOTIFY, UNLISTEN
+listen_stmt:
+  LISTEN name
+  {
+    $$.val = &tree.Listen{ChannelName: tree.Name($2)}
+  }
+| LISTEN error // SHOW HELP: LISTEN
+
+// %Help: UNLISTEN - stop listening for notifications
+// %Category: Misc
+// %Text: UNLISTEN { <channel> | * }
+// %SeeAlso: LISTEN, NOTIFY
+unlisten_stmt:
+  UNLISTEN name
+  {
+    $$.val = &tree.Unlisten{ChannelName: tree.Name($2)}
+  }
+| UNLISTEN '*'
+  {
+    $$.val = &tree.Unlisten{All: true}
+  }
+| UNLISTEN error // SHOW HELP: UNLISTEN
+
+// %Help: NOTIFY - send a notification on a channel
+// %Category: Misc
+// %Text: NOTIFY <channel> [ , <payload> ]
+// %SeeAlso: LISTEN, UNLISTEN
+notify_stmt:
+  NOTIFY name
+  {
+    $$.val = &tree.Notify{ChannelName: tree.Name($2)}
+  }
+| NOTIFY name ',' SCONST
+  {
+    $$.val = &tree.Notify{ChannelName: tree.Name($2), Payload: tree.NewStrVal($4)}
+  }
+| NOTIFY error // SHOW HELP: NOTIFY
+
 // %Help: DROP
 // %Category: Group
 // %Text:
@@ -4128,6 +4772,9 @@ drop_ddl_stmt:
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 | drop_schema_stmt   // EXTEND WITH HELP: DROP SCHEMA
 | drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
+| drop_domain_stmt   // EXTEND WITH HELP: DROP DOMAIN
+| drop_func_stmt     // EXTEND WITH HELP: DROP FUNCTION
+| drop_trigger_stmt  // EXTEND WITH HELP: DROP TRIGGER
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
@@ -4162,6 +4809,54 @@ drop_view_stmt:
   }
 | DROP VIEW error // SHOW HELP: DROP VIEW
 
+// %Help: DROP FUNCTION - remove a function
+// %Category: DDL
+// %Text:
+// DROP FUNCTION [ IF EXISTS ] <name> [ ( [ [ IN ] [ <argname> ] <argtype> [, ...] ] ) ] [, ...]
+//    [ CASCADE | RESTRICT ]
+// %SeeAlso: CREATE FUNCTION
+drop_func_stmt:
+  DROP FUNCTION function_with_argtypes_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      Functions: $3.funcObjs(),
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP FUNCTION IF EXISTS function_with_argtypes_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      IfExists: true,
+      Functions: $5.funcObjs(),
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP FUNCTION error // SHOW HELP: DROP FUNCTION
+
+// %Help: DROP TRIGGER - remove a trigger
+// %Category: DDL
+// %Text: DROP TRIGGER [ IF EXISTS ] <name> ON <tablename> [ CASCADE | RESTRICT ]
+// %SeeAlso: CREATE TRIGGER
+drop_trigger_stmt:
+  DROP TRIGGER name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($3),
+      Table: $5.unresolvedObjectName().ToTableName(),
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP TRIGGER IF EXISTS name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($5),
+      Table: $7.unresolvedObjectName().ToTableName(),
+      IfExists: true,
+      DropBehavior: $8.dropBehavior(),
+    }
+  }
+| DROP TRIGGER error // SHOW HELP: DROP TRIGGER
+
 // %Help: DROP SEQUENCE - remove a sequence
 // %Category: DDL
 // %Text: DROP SEQUENCE [IF EXISTS] <sequenceName> [, ...] [CASCADE | RESTRICT]
@@ -4262,6 +4957,30 @@ drop_type_stmt:
   }
 | DROP TYPE error // SHOW HELP: DROP TYPE
 
+// %Help: DROP DOMAIN - remove a domain
+// %Category: DDL
+// %Text: DROP DOMAIN [IF EXISTS] <type_name> [, ...] [CASCADE | RESTRICT]
+drop_domain_stmt:
+  DROP DOMAIN type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{
+      Names: $3.unresolvedObjectNames(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+      Domain: true,
+    }
+  }
+| DROP DOMAIN IF EXISTS type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{
+      Names: $5.unresolvedObjectNames(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+      Domain: true,
+    }
+  }
+| DROP DOMAIN error // SHOW HELP: DROP DOMAIN
+
 target_types:
   type_name_list
   {
@@ -4717,6 +5436,17 @@ grant_stmt:
   {
     $$.val = &tree.Grant{Privileges: $2.privilegeList(), Targets: $5.targetList(), Grantees: $7.roleSpecList(), WithGrantOption: $8.bool(),}
   }
+| GRANT privileges ON FUNCTION function_with_argtypes_list TO role_spec_list opt_with_grant_option
+  {
+    $$.val = &tree.Grant{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.funcObjs(),
+      },
+      Grantees: $7.roleSpecList(),
+      WithGrantOption: $8.bool(),
+    }
+  }
 | GRANT privileges ON SCHEMA schema_name_list TO role_spec_list opt_with_grant_option
   {
     $$.val = &tree.Grant{
@@ -4794,6 +5524,28 @@ revoke_stmt:
   {
     $$.val = &tree.Revoke{Privileges: $5.privilegeList(), Targets: $8.targetList(), Grantees: $10.roleSpecList(), GrantOptionFor: true}
   }
+| REVOKE privileges ON FUNCTION function_with_argtypes_list FROM role_spec_list
+  {
+    $$.val = &tree.Revoke{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.funcObjs(),
+      },
+      Grantees: $7.roleSpecList(),
+      GrantOptionFor: false,
+    }
+  }
+| REVOKE GRANT OPTION FOR privileges ON FUNCTION function_with_argtypes_list FROM role_spec_list
+  {
+    $$.val = &tree.Revoke{
+      Privileges: $5.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $8.funcObjs(),
+      },
+      Grantees: $10.roleSpecList(),
+      GrantOptionFor: true,
+    }
+  }
 | REVOKE privileges ON SCHEMA schema_name_list FROM role_spec_list
   {
     $$.val = &tree.Revoke{
@@ -4933,7 +5685,7 @@ use_stmt:
 nonpreparable_set_stmt:
   set_transaction_stmt // EXTEND WITH HELP: SET TRANSACTION
 | set_exprs_internal   { /* SKIP DOC */ }
-| SET CONSTRAINTS error { return unimplemented(sqllex, "set constraints") }
+| set_constraints_stmt // EXTEND WITH HELP: SET CONSTRAINTS
 
 // SET SESSION / SET LOCAL / SET CLUSTER SETTING
 preparable_set_stmt:
@@ -5179,6 +5931,36 @@ set_transaction_stmt:
   }
 | SET SESSION TRANSACTION error // SHOW HELP: SET TRANSACTION
 
+// %Help: SET CONSTRAINTS - defer the checks of constraints
+// %Category: Txn
+// %Text:
+// SET CONSTRAINTS { ALL | <name> [, ...] } { DEFERRED | IMMEDIATE }
+//
+// Only the checks of constraints declared DEFERRABLE can be deferred to the
+// end of the transaction.
+//
+// %SeeAlso: SET TRANSACTION, CREATE TABLE, ALTER TABLE
+set_constraints_stmt:
+  SET CONSTRAINTS ALL constraints_mode
+  {
+    $$.val = &tree.SetConstraints{Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS name_list constraints_mode
+  {
+    $$.val = &tree.SetConstraints{Names: $3.nameList(), Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS error // SHOW HELP: SET CONSTRAINTS
+
+constraints_mode:
+  DEFERRED
+  {
+    $$.val = true
+  }
+| IMMEDIATE
+  {
+    $$.val = false
+  }
+
 generic_set:
   var_name to_or_eq var_list
   {
@@ -5301,11 +6083,11 @@ var_list:
 iso_level:
   READ UNCOMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | READ COMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | SNAPSHOT
   {
@@ -7141,6 +7923,7 @@ alter_schema_stmt:
 //    FOREIGN KEY ( <colnames...> ) REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}]
 //    UNIQUE ( <colnames...> ) [{STORING | INCLUDE | COVERING} ( <colnames...> )]
 //    CHECK ( <expr> )
+//    EXCLUDE [USING <method>] ( <colname> WITH <operator> [, ...] ) [WHERE ( <expr> )]
 //
 // Column qualifiers:
 //   [CONSTRAINT <constraintname>] {NULL | NOT NULL | NOT VISIBLE | UNIQUE | PRIMARY KEY | CHECK (<expr>) | DEFAULT <expr> | ON UPDATE <expr> | GENERATED { ALWAYS | BY DEFAULT } AS IDENTITY [( <opt_sequence_option_list> )]}
@@ -7651,7 +8434,7 @@ col_qualification_elem:
   {
     $$.val = &tree.ColumnOnUpdate{Expr: $3.expr()}
   }
-| REFERENCES table_name opt_name_parens key_match reference_actions
+| REFERENCES table_name opt_name_parens key_match reference_actions opt_deferrable
   {
     name := $2.unresolvedObjectName().ToTableName()
     $$.val = &tree.ColumnFKConstraint{
@@ -7659,6 +8442,7 @@ col_qualification_elem:
       Col: tree.Name($3),
       Actions: $5.referenceActions(),
       Match: $4.compositeKeyMatchMethod(),
+      Deferrable: $6.constraintDeferrability(),
     }
   }
 | generated_as '(' a_expr ')' STORED
@@ -7781,6 +8565,9 @@ table_constraint:
 constraint_elem:
   CHECK '(' a_expr ')' opt_deferrable
   {
+    if $5.constraintDeferrability() != tree.NotDeferrableConstraint {
+      return setErr(sqllex, pgerror.New(pgcode.FeatureNotSupported, "CHECK constraints cannot be marked DEFERRABLE"))
+    }
     $$.val = &tree.CheckConstraintTableDef{
       Expr: $3.expr(),
     }
@@ -7796,6 +8583,7 @@ constraint_elem:
         PartitionByIndex: $7.partitionByIndex(),
         Predicate: $9.expr(),
       },
+      Deferrable: $8.constraintDeferrability(),
     }
   }
 | PRIMARY KEY '(' index_params ')' opt_hash_sharded opt_with_storage_parameter_list
@@ -7819,11 +8607,79 @@ constraint_elem:
       ToCols: $8.nameList(),
       Match: $9.compositeKeyMatchMethod(),
       Actions: $10.referenceActions(),
+      Deferrable: $11.constraintDeferrability(),
     }
   }
-| EXCLUDE USING error
+| EXCLUDE opt_exclusion_access_method '(' exclusion_elem_list ')'
+    opt_exclusion_where_clause opt_deferrable
   {
-    return unimplementedWithIssueDetail(sqllex, 46657, "add constraint exclude using")
+    if $7.constraintDeferrability() != tree.NotDeferrableConstraint {
+      return setErr(sqllex, pgerror.New(pgcode.FeatureNotSupported, "EXCLUDE constraints cannot be marked DEFERRABLE"))
+    }
+    $$.val = &tree.ExclusionConstraintTableDef{
+      AccessMethod: tree.Name($2),
+      Elems: $4.exclusionElems(),
+      Predicate: $6.expr(),
+    }
+  }
+
+opt_exclusion_access_method:
+  USING name
+  {
+    switch $2 {
+      case "btree", "gist":
+        $$ = $2
+      case "hash", "spgist", "brin", "gin":
+        return unimplemented(sqllex, "exclusion constraint using " + $2)
+      default:
+        sqllex.Error("unrecognized access method: " + $2)
+        return 1
+    }
+  }
+| /* EMPTY */
+  {
+    $$ = ""
+  }
+
+exclusion_elem_list:
+  exclusion_elem
+  {
+    $$.val = tree.ExclusionConstraintElemList{$1.exclusionElem()}
+  }
+| exclusion_elem_list ',' exclusion_elem
+  {
+    $$.val = append($1.exclusionElems(), $3.exclusionElem())
+  }
+
+// Each element of an EXCLUDE constraint is compared with the same element of
+// other rows using a comparison operator, which can be written with or without
+// OPERATOR().
+exclusion_elem:
+  index_elem WITH all_op
+  {
+    op, ok := $3.op().(treecmp.ComparisonOperator)
+    if !ok {
+      return setErr(sqllex, pgerror.Newf(pgcode.WrongObjectType, "operator %s is not a comparison operator", $3.op()))
+    }
+    $$.val = tree.ExclusionConstraintElem{IndexElem: $1.idxElem(), Operator: op}
+  }
+| index_elem WITH qual_op
+  {
+    op, ok := $3.op().(treecmp.ComparisonOperator)
+    if !ok {
+      return setErr(sqllex, pgerror.Newf(pgcode.WrongObjectType, "operator %s is not a comparison operator", $3.op()))
+    }
+    $$.val = tree.ExclusionConstraintElem{IndexElem: $1.idxElem(), Operator: op}
+  }
+
+opt_exclusion_where_clause:
+  WHERE '(' a_expr ')'
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
   }
 
 
@@ -7931,13 +8787,15 @@ create_as_col_qualification_elem:
     }
   }
 
+// NOT DEFERRABLE is not supported since it would conflict with NOT VALID in
+// ALTER TABLE ... ADD CONSTRAINT; it is the default anyway.
 opt_deferrable:
-  /* EMPTY */ { /* no error */ }
-| DEFERRABLE { return unimplementedWithIssueDetail(sqllex, 31632, "deferrable") }
-| DEFERRABLE INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "def initially deferred") }
-| DEFERRABLE INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "def initially immediate") }
-| INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "initially deferred") }
-| INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "initially immediate") }
+  /* EMPTY */ { $$.val = tree.NotDeferrableConstraint }
+| DEFERRABLE { $$.val = tree.DeferrableInitiallyImmediate }
+| DEFERRABLE INITIALLY DEFERRED { $$.val = tree.DeferrableInitiallyDeferred }
+| DEFERRABLE INITIALLY IMMEDIATE { $$.val = tree.DeferrableInitiallyImmediate }
+| INITIALLY DEFERRED { $$.val = tree.DeferrableInitiallyDeferred }
+| INITIALLY IMMEDIATE { $$.val = tree.NotDeferrableConstraint }
 
 storing:
   COVERING
@@ -8521,7 +9379,9 @@ opt_view_recursive:
 
 // %Help: CREATE TYPE -- create a type
 // %Category: DDL
-// %Text: CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
+// %Text:
+// CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
+// CREATE TYPE [IF NOT EXISTS] <type_name> AS ( [<field_name> <type> [, ...]] )
 create_type_stmt:
   // Enum types.
   CREATE TYPE type_name AS ENUM '(' opt_enum_val_list ')'
@@ -8541,17 +9401,83 @@ create_type_stmt:
       IfNotExists: true,
     }
   }
+  // Composite types.
+| CREATE TYPE type_name AS '(' opt_composite_type_list ')'
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $3.unresolvedObjectName(),
+      Variety: tree.Composite,
+      CompositeTypeList: $6.compositeTypeList(),
+    }
+  }
+| CREATE TYPE IF NOT EXISTS type_name AS '(' opt_composite_type_list ')'
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $6.unresolvedObjectName(),
+      Variety: tree.Composite,
+      CompositeTypeList: $9.compositeTypeList(),
+      IfNotExists: true,
+    }
+  }
 | CREATE TYPE error // SHOW HELP: CREATE TYPE
-  // Record/Composite types.
-| CREATE TYPE type_name AS '(' error      { return unimplementedWithIssue(sqllex, 27792) }
   // Range types.
 | CREATE TYPE type_name AS RANGE error    { return unimplementedWithIssue(sqllex, 27791) }
   // Base (primitive) types.
 | CREATE TYPE type_name '(' error         { return unimplementedWithIssueDetail(sqllex, 27793, "base") }
   // Shell types, gateway to define base types using the previous syntax.
 | CREATE TYPE type_name                   { return unimplementedWithIssueDetail(sqllex, 27793, "shell") }
-  // Domain types.
-| CREATE DOMAIN type_name error           { return unimplementedWithIssueDetail(sqllex, 27796, "create") }
+
+// %Help: CREATE DOMAIN -- create a domain
+// %Category: DDL
+// %Text:
+// CREATE DOMAIN <type_name> [AS] <type>
+//   [ DEFAULT <expr> | NOT NULL | NULL | [CONSTRAINT <name>] CHECK (<expr>) ] [...]
+create_domain_stmt:
+  CREATE DOMAIN type_name AS typename col_qual_list
+  {
+    domain, err := tree.NewDomainDef($5.typeReference(), $6.colQuals())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = &tree.CreateType{
+      TypeName: $3.unresolvedObjectName(),
+      Variety: tree.Domain,
+      Domain: domain,
+    }
+  }
+| CREATE DOMAIN type_name typename col_qual_list
+  {
+    domain, err := tree.NewDomainDef($4.typeReference(), $5.colQuals())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = &tree.CreateType{
+      TypeName: $3.unresolvedObjectName(),
+      Variety: tree.Domain,
+      Domain: domain,
+    }
+  }
+| CREATE DOMAIN error // SHOW HELP: CREATE DOMAIN
+
+opt_composite_type_list:
+  composite_type_list
+  {
+    $$.val = $1.compositeTypeList()
+  }
+| /* EMPTY */
+  {
+    $$.val = []tree.CompositeTypeElem{}
+  }
+
+composite_type_list:
+  name typename
+  {
+    $$.val = []tree.CompositeTypeElem{{Label: tree.Name($1), Type: $2.typeReference()}}
+  }
+| composite_type_list ',' name typename
+  {
+    $$.val = append($1.compositeTypeList(), tree.CompositeTypeElem{Label: tree.Name($3), Type: $4.typeReference()})
+  }
 
 opt_enum_val_list:
   enum_val_list
@@ -9821,6 +10747,10 @@ on_conflict:
       DoNothing: true,
     }
   }
+| ON CONFLICT DO UPDATE SET set_clause_list opt_where_clause
+  {
+    return setErr(sqllex, pgerror.New(pgcode.Syntax, "ON CONFLICT DO UPDATE requires inference specification or constraint name"))
+  }
 | ON CONFLICT '(' name_list ')' opt_where_clause DO UPDATE SET set_clause_list opt_where_clause
   {
     $$.val = &tree.OnConflict{
@@ -10570,9 +11500,18 @@ group_by_list:
 // rather than reducing the conflicting unreserved_keyword rule.
 group_by_item:
   a_expr { $$.val = $1.expr() }
-| ROLLUP '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "rollup") }
-| CUBE '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "cube") }
-| GROUPING SETS error { return unimplementedWithIssueDetail(sqllex, 46280, "grouping sets") }
+| ROLLUP '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.RollupGroupingSet, Exprs: $3.exprs()}
+  }
+| CUBE '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.CubeGroupingSet, Exprs: $3.exprs()}
+  }
+| GROUPING SETS '(' group_by_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.ExplicitGroupingSets, Exprs: $4.exprs()}
+  }
 
 having_clause:
   HAVING a_expr
@@ -11174,7 +12113,8 @@ typename:
       $$.val = $1.typeReference()
     }
   }
-  // SQL standard syntax, currently only one-dimensional
+  // SQL standard syntax, only one-dimensional. As in Postgres, the declared
+  // bounds and number of dimensions are not enforced.
   // Undocumented but support for potential Postgres compat
 | simple_typename ARRAY '[' ICONST ']' {
     /* SKIP DOC */
@@ -11184,7 +12124,6 @@ typename:
       return setErr(sqllex, err)
     }
   }
-| simple_typename ARRAY '[' ICONST ']' '[' error { return unimplementedWithIssue(sqllex, 32552) }
 | simple_typename ARRAY {
     var err error
     $$.val, err = arrayOf($1.typeReference(), nil)
@@ -11200,20 +12139,16 @@ cast_target:
   }
 
 opt_array_bounds:
-  // TODO(justin): reintroduce multiple array bounds
-  // opt_array_bounds '[' ']' { $$.val = append($1.int32s(), -1) }
-  '[' ']' { $$.val = []int32{-1} }
-| '[' ']' '[' error { return unimplementedWithIssue(sqllex, 32552) }
-| '[' ICONST ']'
+  opt_array_bounds '[' ']' { $$.val = append($1.int32s(), -1) }
+| opt_array_bounds '[' ICONST ']'
   {
     /* SKIP DOC */
-    bound, err := $2.numVal().AsInt32()
+    bound, err := $3.numVal().AsInt32()
     if err != nil {
       return setErr(sqllex, err)
     }
-    $$.val = []int32{bound}
+    $$.val = append($1.int32s(), bound)
   }
-| '[' ICONST ']' '[' error { return unimplementedWithIssue(sqllex, 32552) }
 | /* EMPTY */ { $$.val = []int32(nil) }
 
 // general_type_name is a variant of type_or_function_name but does not
@@ -12491,7 +13426,10 @@ d_expr:
   {
     $$.val = $2.expr()
   }
-| GROUPING '(' expr_list ')' { return unimplemented(sqllex, "d_expr grouping") }
+| GROUPING '(' expr_list ')'
+  {
+    $$.val = &tree.FuncExpr{Func: tree.WrapFunction("grouping"), Exprs: $3.exprs()}
+  }
 
 func_application:
   func_name '(' ')'
@@ -14054,6 +14992,7 @@ unreserved_keyword:
 | BUNDLE
 | BY
 | CACHE
+| CALLED
 | CANCEL
 | CANCELQUERY
 | CASCADE
@@ -14105,6 +15044,7 @@ unreserved_keyword:
 | DOMAIN
 | DOUBLE
 | DROP
+| EACH
 | ENCODING
 | ENCRYPTED
 | ENCRYPTION_PASSPHRASE
@@ -14132,6 +15072,7 @@ unreserved_keyword:
 | FORCE
 | FORCE_INDEX
 | FORCE_ZIGZAG
+| FORMAT
 | FORWARD
 | FREEZE
 | FUNCTION
@@ -14156,6 +15097,7 @@ unreserved_keyword:
 | HOUR
 | IDENTITY
 | IMMEDIATE
+| IMMUTABLE
 | IMPORT
 | INCLUDE
 | INCLUDING
@@ -14165,6 +15107,7 @@ unreserved_keyword:
 | INDEXES
 | INHERITS
 | INJECT
+| INPUT
 | INSERT
 | INTO_DB
 | INVERTED
@@ -14181,6 +15124,7 @@ unreserved_keyword:
 | LATEST
 | LC_COLLATE
 | LC_CTYPE
+| LEAKPROOF
 | LEASE
 | LESS
 | LEVEL
@@ -14189,6 +15133,7 @@ unreserved_keyword:
 | LINESTRINGZ
 | LINESTRINGZM
 | LIST
+| LISTEN
 | LOCAL
 | LOCKED
 | LOGIN
@@ -14238,6 +15183,7 @@ unreserved_keyword:
 | NOMODIFYCLUSTERSETTING
 | NONVOTERS
 | NOSQLLOGIN
+| NOTIFY
 | NOVIEWACTIVITY
 | NOVIEWACTIVITYREDACTED
 | NOVIEWCLUSTERSETTING
@@ -14281,6 +15227,7 @@ unreserved_keyword:
 | PRIOR
 | PRIORITY
 | PRIVILEGES
+| PROCEDURE
 | PUBLIC
 | PUBLICATION
 | QUERIES
@@ -14312,6 +15259,7 @@ unreserved_keyword:
 | RESTRICTED
 | RESUME
 | RETRY
+| RETURNS
 | REVISION_HISTORY
 | REVOKE
 | ROLE
@@ -14357,11 +15305,14 @@ unreserved_keyword:
 | SPLIT
 | SQL
 | SQLLOGIN
+| STABLE
 | START
 | STATE
+| STATEMENT
 | STATEMENTS
 | STATISTICS
 | STDIN
+| STDOUT
 | STORAGE
 | STORE
 | STORED
@@ -14397,6 +15348,7 @@ unreserved_keyword:
 | UNBOUNDED
 | UNCOMMITTED
 | UNKNOWN
+| UNLISTEN
 | UNLOGGED
 | UNSET
 | UNSPLIT
@@ -14414,6 +15366,7 @@ unreserved_keyword:
 | VIEWACTIVITYREDACTED
 | VIEWCLUSTERSETTING
 | VISIBLE
+| VOLATILE
 | VOTERS
 | WITHIN
 | WITHOUT
@@ -14469,6 +15422,7 @@ col_name_keyword:
 | PRECISION
 | REAL
 | ROW
+| SETOF
 | SMALLINT
 | STRING
 | SUBSTRING
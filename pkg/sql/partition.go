@@ -11,11 +11,24 @@
 package sql
 
 import (
+	"context"
+	"strings"
+
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/valueside"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scdeps"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
 )
 
@@ -146,3 +159,369 @@ func partitionTupleToExprs(t *rowenc.PartitionTuple) (tree.Exprs, error) {
 	}
 	return exprs, nil
 }
+
+// valueEncodePartitionTuple typechecks the datums in maybeTuple. It returns the
+// concatenation of these datums, each encoded using the table "value" encoding.
+// The special values of DEFAULT (for list) and MAXVALUE (for range) are encoded
+// as NOT NULL.
+//
+// TODO(dan): The typechecking here should be run during plan construction, so
+// we can support placeholders.
+func valueEncodePartitionTuple(
+	typ tree.PartitionByType, evalCtx *tree.EvalContext, maybeTuple tree.Expr, cols []catalog.Column,
+) ([]byte, error) {
+	// Replace any occurrences of the MINVALUE/MAXVALUE pseudo-names
+	// into MinVal/MaxVal.
+	maybeTuple, _ = tree.WalkExpr(replaceMinMaxValVisitor{}, maybeTuple)
+
+	tuple, ok := maybeTuple.(*tree.Tuple)
+	if !ok {
+		// If we don't already have a tuple, promote whatever we have to a 1-tuple.
+		tuple = &tree.Tuple{Exprs: []tree.Expr{maybeTuple}}
+	}
+
+	if len(tuple.Exprs) != len(cols) {
+		return nil, errors.Errorf("partition has %d columns but %d values were supplied",
+			len(cols), len(tuple.Exprs))
+	}
+
+	var value, scratch []byte
+	for i, expr := range tuple.Exprs {
+		expr = tree.StripParens(expr)
+		switch expr.(type) {
+		case tree.DefaultVal:
+			if typ != tree.PartitionByList {
+				return nil, errors.Errorf("%s cannot be used with PARTITION BY %s", expr, typ)
+			}
+			// NOT NULL is used to signal that a PartitionSpecialValCode follows
+			value = encoding.EncodeNotNullValue(value, encoding.NoColumnID)
+			value = encoding.EncodeNonsortingUvarint(value, uint64(rowenc.PartitionDefaultVal))
+			continue
+		case tree.PartitionMinVal:
+			if typ != tree.PartitionByRange {
+				return nil, errors.Errorf("%s cannot be used with PARTITION BY %s", expr, typ)
+			}
+			// NOT NULL is used to signal that a PartitionSpecialValCode follows
+			value = encoding.EncodeNotNullValue(value, encoding.NoColumnID)
+			value = encoding.EncodeNonsortingUvarint(value, uint64(rowenc.PartitionMinVal))
+			continue
+		case tree.PartitionMaxVal:
+			if typ != tree.PartitionByRange {
+				return nil, errors.Errorf("%s cannot be used with PARTITION BY %s", expr, typ)
+			}
+			// NOT NULL is used to signal that a PartitionSpecialValCode follows
+			value = encoding.EncodeNotNullValue(value, encoding.NoColumnID)
+			value = encoding.EncodeNonsortingUvarint(value, uint64(rowenc.PartitionMaxVal))
+			continue
+		case *tree.Placeholder:
+			return nil, unimplemented.NewWithIssuef(
+				19464, "placeholders are not supported in PARTITION BY")
+		default:
+			// Fall-through.
+		}
+
+		var semaCtx tree.SemaContext
+		typedExpr, err := schemaexpr.SanitizeVarFreeExpr(evalCtx.Context, expr, cols[i].GetType(), "partition",
+			&semaCtx,
+			tree.VolatilityImmutable,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if !tree.IsConst(evalCtx, typedExpr) {
+			return nil, pgerror.Newf(pgcode.Syntax,
+				"%s: partition values must be constant", typedExpr)
+		}
+		datum, err := typedExpr.Eval(evalCtx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "evaluating %s", typedExpr)
+		}
+		if err := colinfo.CheckDatumTypeFitsColumnType(cols[i], datum.ResolvedType()); err != nil {
+			return nil, err
+		}
+		value, err = valueside.Encode(value, valueside.NoColumnID, datum, scratch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// replaceMinMaxValVisitor replaces occurrences of the unqualified
+// identifiers "minvalue" and "maxvalue" in the partitioning
+// (sub-)exprs by the symbolic values tree.PartitionMinVal and
+// tree.PartitionMaxVal.
+type replaceMinMaxValVisitor struct{}
+
+// VisitPre satisfies the tree.Visitor interface.
+func (v replaceMinMaxValVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if t, ok := expr.(*tree.UnresolvedName); ok && t.NumParts == 1 {
+		switch t.Parts[0] {
+		case "minvalue":
+			return false, tree.PartitionMinVal{}
+		case "maxvalue":
+			return false, tree.PartitionMaxVal{}
+		}
+	}
+	return true, expr
+}
+
+// VisitPost satisfies the Visitor interface.
+func (replaceMinMaxValVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// createPartitioningImpl builds the partitioning descriptor of partBy, which
+// partitions the columns of the index starting at colOffset.
+func createPartitioningImpl(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	columnLookupFn func(tree.Name) (catalog.Column, error),
+	newIdxColumnNames []string,
+	partBy *tree.PartitionBy,
+	allowedNewColumnNames []tree.Name,
+	numImplicitColumns int,
+	colOffset int,
+) (catpb.PartitioningDescriptor, error) {
+	partDesc := catpb.PartitioningDescriptor{}
+	if partBy == nil {
+		return partDesc, nil
+	}
+	partDesc.NumColumns = uint32(len(partBy.Fields))
+	partDesc.NumImplicitColumns = uint32(numImplicitColumns)
+
+	partitioningString := func() string {
+		// We don't have the fields for our parent partitions handy, but we can use
+		// the names from the index we're partitioning. They must have matched or we
+		// would have already returned an error.
+		partCols := append([]string(nil), newIdxColumnNames[:colOffset]...)
+		for _, p := range partBy.Fields {
+			partCols = append(partCols, string(p))
+		}
+		return strings.Join(partCols, ", ")
+	}
+
+	var cols []catalog.Column
+	for i := 0; i < len(partBy.Fields); i++ {
+		if colOffset+i >= len(newIdxColumnNames) {
+			return partDesc, pgerror.Newf(pgcode.Syntax,
+				"declared partition columns (%s) exceed the number of columns in index being partitioned (%s)",
+				partitioningString(), strings.Join(newIdxColumnNames, ", "))
+		}
+		// Search by name because some callsites of this method have not
+		// allocated ids yet (so they are still all the 0 value).
+		col, err := findColumnByNameOnTable(
+			columnLookupFn,
+			tree.Name(newIdxColumnNames[colOffset+i]),
+			allowedNewColumnNames,
+		)
+		if err != nil {
+			return partDesc, err
+		}
+		cols = append(cols, col)
+		if string(partBy.Fields[i]) != col.GetName() {
+			// This used to print the first `colOffset + len(partBy.Fields)` fields
+			// but there might not be this many columns in the index. See #37682.
+			n := colOffset + i + 1
+			return partDesc, pgerror.Newf(pgcode.Syntax,
+				"declared partition columns (%s) do not match first %d columns in index being partitioned (%s)",
+				partitioningString(), n, strings.Join(newIdxColumnNames[:n], ", "))
+		}
+	}
+
+	for _, l := range partBy.List {
+		p := catpb.PartitioningDescriptor_List{
+			Name: string(l.Name),
+		}
+		for _, expr := range l.Exprs {
+			encodedTuple, err := valueEncodePartitionTuple(
+				tree.PartitionByList, evalCtx, expr, cols)
+			if err != nil {
+				return partDesc, pgerror.Wrapf(err, pgcode.Syntax, "PARTITION %s", p.Name)
+			}
+			p.Values = append(p.Values, encodedTuple)
+		}
+		if l.Subpartition != nil {
+			if numImplicitColumns > 0 {
+				return catpb.PartitioningDescriptor{}, unimplemented.New(
+					"PARTITION BY SUBPARTITION",
+					"implicit column partitioning on a subpartition is not yet supported",
+				)
+			}
+			newColOffset := colOffset + int(partDesc.NumColumns)
+			subpartitioning, err := createPartitioningImpl(
+				ctx,
+				evalCtx,
+				columnLookupFn,
+				newIdxColumnNames,
+				l.Subpartition,
+				allowedNewColumnNames,
+				0, /* numImplicitColumns */
+				newColOffset,
+			)
+			if err != nil {
+				return partDesc, err
+			}
+			p.Subpartitioning = subpartitioning
+		}
+		partDesc.List = append(partDesc.List, p)
+	}
+
+	for _, r := range partBy.Range {
+		p := catpb.PartitioningDescriptor_Range{
+			Name: string(r.Name),
+		}
+		var err error
+		p.FromInclusive, err = valueEncodePartitionTuple(
+			tree.PartitionByRange, evalCtx, &tree.Tuple{Exprs: r.From}, cols)
+		if err != nil {
+			return partDesc, pgerror.Wrapf(err, pgcode.Syntax, "PARTITION %s", p.Name)
+		}
+		p.ToExclusive, err = valueEncodePartitionTuple(
+			tree.PartitionByRange, evalCtx, &tree.Tuple{Exprs: r.To}, cols)
+		if err != nil {
+			return partDesc, pgerror.Wrapf(err, pgcode.Syntax, "PARTITION %s", p.Name)
+		}
+		if r.Subpartition != nil {
+			return partDesc, errors.Newf("PARTITION %s: cannot subpartition a range partition", p.Name)
+		}
+		partDesc.Range = append(partDesc.Range, p)
+	}
+
+	return partDesc, nil
+}
+
+// collectImplicitPartitionColumns collects implicit partitioning columns.
+func collectImplicitPartitionColumns(
+	columnLookupFn func(tree.Name) (catalog.Column, error),
+	indexFirstColumnName string,
+	partBy *tree.PartitionBy,
+	allowedNewColumnNames []tree.Name,
+) (implicitCols []catalog.Column, _ error) {
+	seenImplicitColumnNames := map[string]struct{}{}
+	// Iterate over each field in the PARTITION BY until it matches the start
+	// of the actual explicitly indexed columns.
+	for _, field := range partBy.Fields {
+		// As soon as the fields match, we have no implicit columns to add.
+		if string(field) == indexFirstColumnName {
+			break
+		}
+
+		col, err := findColumnByNameOnTable(columnLookupFn, field, allowedNewColumnNames)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seenImplicitColumnNames[col.GetName()]; ok {
+			return nil, pgerror.Newf(
+				pgcode.InvalidObjectDefinition,
+				`found multiple definitions in partition using column "%s"`,
+				col.GetName(),
+			)
+		}
+		seenImplicitColumnNames[col.GetName()] = struct{}{}
+		implicitCols = append(implicitCols, col)
+	}
+
+	return implicitCols, nil
+}
+
+// findColumnByNameOnTable finds the given column from the table.
+// By default we only allow public columns on PARTITION BY clauses.
+// However, any columns appearing as allowedNewColumnNames is also
+// permitted provided the caller will ensure this column is backfilled
+// before the partitioning is active.
+func findColumnByNameOnTable(
+	columnLookupFn func(tree.Name) (catalog.Column, error),
+	col tree.Name,
+	allowedNewColumnNames []tree.Name,
+) (catalog.Column, error) {
+	ret, err := columnLookupFn(col)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Public() {
+		return ret, nil
+	}
+	for _, allowedNewColName := range allowedNewColumnNames {
+		if allowedNewColName == col {
+			return ret, nil
+		}
+	}
+	return nil, colinfo.NewUndefinedColumnError(string(col))
+}
+
+// createPartitioning constructs the partitioning descriptor for an index that
+// is partitioned into ranges, each addressable by zone configs. When
+// allowImplicitPartitioning is set, the leading PARTITION BY columns which are
+// not part of the index are implicitly prepended to its key columns.
+func createPartitioning(
+	ctx context.Context,
+	_ *cluster.Settings,
+	evalCtx *tree.EvalContext,
+	columnLookupFn func(tree.Name) (catalog.Column, error),
+	oldNumImplicitColumns int,
+	oldKeyColumnNames []string,
+	partBy *tree.PartitionBy,
+	allowedNewColumnNames []tree.Name,
+	allowImplicitPartitioning bool,
+) (newImplicitCols []catalog.Column, newPartitioning catpb.PartitioningDescriptor, err error) {
+	// Truncate existing implicitly partitioned column names.
+	newIdxColumnNames := oldKeyColumnNames[oldNumImplicitColumns:]
+
+	if allowImplicitPartitioning {
+		newImplicitCols, err = collectImplicitPartitionColumns(
+			columnLookupFn,
+			newIdxColumnNames[0],
+			partBy,
+			allowedNewColumnNames,
+		)
+		if err != nil {
+			return nil, newPartitioning, err
+		}
+	}
+	if len(newImplicitCols) > 0 {
+		// Prepend with new implicit column names.
+		newIdxColumnNames = make([]string, len(newImplicitCols), len(newImplicitCols)+len(newIdxColumnNames))
+		for i, col := range newImplicitCols {
+			newIdxColumnNames[i] = col.GetName()
+		}
+		newIdxColumnNames = append(newIdxColumnNames, oldKeyColumnNames[oldNumImplicitColumns:]...)
+	}
+
+	// If we had implicit column partitioning beforehand, check we have the
+	// same implicitly partitioned columns.
+	// Having different implicitly partitioned columns requires rewrites,
+	// which is outside the scope of createPartitioning.
+	if oldNumImplicitColumns > 0 {
+		if len(newImplicitCols) != oldNumImplicitColumns {
+			return nil, newPartitioning, errors.AssertionFailedf(
+				"mismatching number of implicit columns: old %d vs new %d",
+				oldNumImplicitColumns,
+				len(newImplicitCols),
+			)
+		}
+		for i, col := range newImplicitCols {
+			if oldKeyColumnNames[i] != col.GetName() {
+				return nil, newPartitioning, errors.AssertionFailedf("found new implicit partitioning at column ordinal %d", i)
+			}
+		}
+	}
+
+	newPartitioning, err = createPartitioningImpl(
+		ctx,
+		evalCtx,
+		columnLookupFn,
+		newIdxColumnNames,
+		partBy,
+		allowedNewColumnNames,
+		len(newImplicitCols),
+		0, /* colOffset */
+	)
+	if err != nil {
+		return nil, catpb.PartitioningDescriptor{}, err
+	}
+	return newImplicitCols, newPartitioning, nil
+}
+
+func init() {
+	// The declarative schema changer cannot depend on this package.
+	scdeps.CreatePartitioningCCL = createPartitioning
+}
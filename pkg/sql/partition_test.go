@@ -29,9 +29,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 )
 
-// Test the behavior of a binary that doesn't link in CCL when it comes to
-// dealing with partitions. Some things are expected to work, others aren't.
-func TestRemovePartitioningOSS(t *testing.T) {
+// Test that partitions and their zone configs can be altered and removed.
+func TestRemovePartitioning(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
 
@@ -48,7 +47,7 @@ func TestRemovePartitioningOSS(t *testing.T) {
 	tableDesc := desctestutils.TestingGetMutableExistingTableDescriptor(kvDB, keys.SystemSQLCodec, "t", "kv")
 	tableKey := catalogkeys.MakeDescMetadataKey(keys.SystemSQLCodec, tableDesc.ID)
 
-	// Hack in partitions. Doing this properly requires a CCL binary.
+	// Hack in partitions directly in the descriptor.
 	{
 		primaryIndex := *tableDesc.GetPrimaryIndex().IndexDesc()
 		primaryIndex.Partitioning = catpb.PartitioningDescriptor{
@@ -100,8 +99,7 @@ func TestRemovePartitioningOSS(t *testing.T) {
 		t.Fatalf("expected:\n%s\n\ngot:\n%s\n\n", exp, a)
 	}
 
-	// Hack in partition zone configs. This also requires a CCL binary to do
-	// properly.
+	// Hack in partition zone configs directly in system.zones.
 	zoneConfig := zonepb.ZoneConfig{
 		Subzones: []zonepb.Subzone{
 			{
@@ -130,15 +128,11 @@ func TestRemovePartitioningOSS(t *testing.T) {
 		}
 	}
 
-	// Some things don't work.
-	sqlDB.ExpectErr(t,
-		"OSS binaries do not include enterprise features",
-		`ALTER PARTITION p1 OF TABLE t.kv CONFIGURE ZONE USING DEFAULT`)
-	sqlDB.ExpectErr(t,
-		"OSS binaries do not include enterprise features",
-		`ALTER PARTITION p2 OF INDEX t.kv@foo CONFIGURE ZONE USING DEFAULT`)
+	// Partition zone configs can be altered.
+	sqlDB.Exec(t, `ALTER PARTITION p1 OF TABLE t.kv CONFIGURE ZONE USING DEFAULT`)
+	sqlDB.Exec(t, `ALTER PARTITION p2 OF INDEX t.kv@foo CONFIGURE ZONE USING DEFAULT`)
 
-	// But removing partitioning works.
+	// Removing partitioning works.
 	sqlDB.Exec(t, `ALTER TABLE t.kv PARTITION BY NOTHING`)
 	sqlDB.Exec(t, `ALTER INDEX t.kv@foo PARTITION BY NOTHING`)
 	sqlDB.Exec(t, `DELETE FROM system.zones WHERE id = $1`, tableDesc.ID)
@@ -13,17 +13,14 @@ package sql
 import (
 	"bytes"
 
-	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
-	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/covering"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 // GenerateSubzoneSpans constructs from a TableDescriptor the entries mapping
@@ -64,28 +61,9 @@ import (
 // slice. As space optimizations, all `Key`s and `EndKey`s of `SubzoneSpan` omit
 // the common prefix (the encoded table ID) and if `EndKey` is equal to
 // `Key.PrefixEnd()` it is omitted.
-//
-// This function has tests in the partitionccl package.
-//
-// TODO(benesch): remove the hasNewSubzones parameter when a statement to clear
-// all subzones at once is introduced.
 func GenerateSubzoneSpans(
-	st *cluster.Settings,
-	logicalClusterID uuid.UUID,
-	codec keys.SQLCodec,
-	tableDesc catalog.TableDescriptor,
-	subzones []zonepb.Subzone,
-	hasNewSubzones bool,
+	codec keys.SQLCodec, tableDesc catalog.TableDescriptor, subzones []zonepb.Subzone,
 ) ([]zonepb.SubzoneSpan, error) {
-	// Removing zone configs does not require a valid license.
-	if hasNewSubzones {
-		org := ClusterOrganization.Get(&st.SV)
-		if err := base.CheckEnterpriseEnabled(st, logicalClusterID, org,
-			"replication zones on indexes or partitions"); err != nil {
-			return nil, err
-		}
-	}
-
 	// We already completely avoid creating subzone spans for dropped indexes.
 	// Whether this was intentional is a different story, but it turns out to be
 	// pretty sane. Dropped elements may refer to dropped types and we aren't
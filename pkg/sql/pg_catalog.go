@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catprivilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -799,7 +800,6 @@ var (
 
 	// Avoid unused warning for constants.
 	_ = conTypeTrigger
-	_ = conTypeExclusion
 
 	fkActionNone       = tree.NewDString("a")
 	fkActionRestrict   = tree.NewDString("r")
@@ -1001,15 +1001,30 @@ func populateTableConstraints(
 				validity = " NOT VALID"
 			}
 			condef = tree.NewDString(fmt.Sprintf("CHECK ((%s))%s", displayExpr, validity))
+
+		case descpb.ConstraintTypeExclusion:
+			oid = h.ExclusionConstraintOid(db.GetID(), scName, table.GetID(), con.ExclusionConstraint)
+			contype = conTypeExclusion
+			if conkey, err = colIDArrayToDatum(con.ExclusionConstraint.ColumnIDs); err != nil {
+				return err
+			}
+			def, err := showExclusionConstraintDef(ctx, table, con.ExclusionConstraint, p.SemaCtx(), p.SessionData())
+			if err != nil {
+				return err
+			}
+			condef = tree.NewDString(def)
 		}
 
+		deferrability := con.Deferrability()
+		condeferrable := tree.MakeDBool(tree.DBool(deferrability != tree.NotDeferrableConstraint))
+		condeferred := tree.MakeDBool(tree.DBool(deferrability == tree.DeferrableInitiallyDeferred))
 		if err := addRow(
 			oid,                  // oid
 			dNameOrNull(conName), // conname
 			namespaceOid,         // connamespace
 			contype,              // contype
-			tree.DBoolFalse,      // condeferrable
-			tree.DBoolFalse,      // condeferred
+			condeferrable,        // condeferrable
+			condeferred,          // condeferred
 			tree.MakeDBool(tree.DBool(!con.Unvalidated)), // convalidated
 			tblOid,         // conrelid
 			oidZero,        // contypid
@@ -1610,6 +1625,9 @@ https://www.postgresql.org/docs/9.5/catalog-pg-description.html`,
 				objID = getOIDFromConstraint(constraint, dbContext.GetID(), schema.GetName(), tableDesc)
 				objSubID = tree.DZero
 				classOid = tree.NewDOid(catconstants.PgCatalogConstraintTableID)
+			case keys.FunctionCommentType:
+				objID = tree.NewDOid(tree.DInt(funcdesc.FuncIDToOID(descpb.ID(tree.MustBeDInt(objID)))))
+				classOid = tree.NewDOid(catconstants.PgCatalogProcTableID)
 			case keys.IndexCommentType:
 				objID = makeOidHasher().IndexOid(
 					descpb.ID(tree.MustBeDInt(objID)),
@@ -1658,6 +1676,13 @@ func getOIDFromConstraint(
 			tableID,
 			constraint.UniqueWithoutIndexConstraint,
 		)
+	} else if constraint.ExclusionConstraint != nil {
+		oid = hasher.ExclusionConstraintOid(
+			dbID,
+			schemaName,
+			tableID,
+			constraint.ExclusionConstraint,
+		)
 	} else if constraint.Index != nil {
 		if constraint.Index.ID == tableDesc.GetPrimaryIndexID() {
 			oid = hasher.PrimaryKeyConstraintOid(
@@ -2791,7 +2816,6 @@ var (
 	typTypeRange     = tree.NewDString("r")
 
 	// Avoid unused warning for constants.
-	_ = typTypeDomain
 	_ = typTypePseudo
 	_ = typTypeRange
 
@@ -2916,6 +2940,11 @@ func addPGTypeRow(
 		builtinPrefix = "enum_"
 		typType = typTypeEnum
 	}
+	if typ.IsCompositeType() {
+		builtinPrefix = "record_"
+		cat = typCategoryComposite
+		typType = typTypeComposite
+	}
 	if cat == typCategoryPseudo {
 		typType = typTypePseudo
 	}
@@ -2959,6 +2988,36 @@ func addPGTypeRow(
 	)
 }
 
+// addPGTypeRowForDomain adds the pg_type row for a domain. A domain has the
+// same representation and I/O functions as its base type, so the row is built
+// from the row of the base type, with the domain specific columns replaced.
+func addPGTypeRowForDomain(
+	h oidHasher,
+	nspOid tree.Datum,
+	owner tree.Datum,
+	typDesc catalog.TypeDescriptor,
+	typ *types.T,
+	addRow func(...tree.Datum) error,
+) error {
+	baseTyp := typ.DomainBaseType()
+	domain := typDesc.TypeDesc().Domain
+	typDefault := tree.DNull
+	if domain.DefaultExpr != nil {
+		typDefault = tree.NewDString(*domain.DefaultExpr)
+	}
+	return addPGTypeRow(h, nspOid, owner, baseTyp, func(row ...tree.Datum) error {
+		row[0] = tree.NewDOid(tree.DInt(typ.Oid()))          // oid
+		row[1] = tree.NewDName(typDesc.GetName())            // typname
+		row[6] = typTypeDomain                               // typtype
+		row[12] = oidZero                                    // typelem
+		row[13] = oidZero                                    // typarray
+		row[23] = tree.MakeDBool(tree.DBool(domain.NotNull)) // typnotnull
+		row[24] = tree.NewDOid(tree.DInt(baseTyp.Oid()))     // typbasetype
+		row[29] = typDefault                                 // typdefault
+		return addRow(row...)
+	})
+}
+
 func getSchemaAndTypeByTypeID(
 	ctx context.Context, p *planner, id descpb.ID,
 ) (string, catalog.TypeDescriptor, error) {
@@ -3024,6 +3083,9 @@ https://www.postgresql.org/docs/9.5/catalog-pg-type.html`,
 						if err != nil {
 							return err
 						}
+						if typDesc.GetKind() == descpb.TypeDescriptor_DOMAIN {
+							return addPGTypeRowForDomain(h, nspOid, getOwnerOID(typDesc), typDesc, typ, addRow)
+						}
 						return addPGTypeRow(h, nspOid, getOwnerOID(typDesc), typ, addRow)
 					},
 				)
@@ -3108,7 +3170,12 @@ https://www.postgresql.org/docs/9.5/catalog-pg-type.html`,
 				if err != nil {
 					return false, err
 				}
-				if err := addPGTypeRow(h, nspOid, getOwnerOID(typDesc), typ, addRow); err != nil {
+				if typDesc.GetKind() == descpb.TypeDescriptor_DOMAIN {
+					err = addPGTypeRowForDomain(h, nspOid, getOwnerOID(typDesc), typDesc, typ, addRow)
+				} else {
+					err = addPGTypeRow(h, nspOid, getOwnerOID(typDesc), typ, addRow)
+				}
+				if err != nil {
 					return false, err
 				}
 
@@ -3656,12 +3723,12 @@ https://www.postgresql.org/docs/14/view-pg-cursors.html`,
 				return err
 			}
 			if err := addRow(
-				tree.NewDString(string(name)), /* name */
-				tree.NewDString(c.statement),  /* statement */
-				tree.DBoolFalse,               /* is_holdable */
-				tree.DBoolFalse,               /* is_binary */
-				tree.DBoolFalse,               /* is_scrollable */
-				tz,                            /* creation_date */
+				tree.NewDString(string(name)),        /* name */
+				tree.NewDString(c.statement),         /* statement */
+				tree.MakeDBool(tree.DBool(c.hold)),   /* is_holdable */
+				tree.MakeDBool(tree.DBool(c.binary)), /* is_binary */
+				tree.MakeDBool(tree.DBool(c.scroll)), /* is_scrollable */
+				tz,                                   /* creation_date */
 			); err != nil {
 				return err
 			}
@@ -4341,6 +4408,7 @@ const (
 	enumEntryTypeTag
 	rewriteTypeTag
 	dbSchemaRoleTypeTag
+	exclusionConstraintTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -4374,6 +4442,11 @@ func (h oidHasher) writeUniqueConstraint(uc *descpb.UniqueWithoutIndexConstraint
 	h.writeStr(uc.Name)
 }
 
+func (h oidHasher) writeExclusionConstraint(ec *descpb.ExclusionConstraint) {
+	h.writeUInt32(uint32(ec.TableID))
+	h.writeStr(ec.Name)
+}
+
 func (h oidHasher) writeCheckConstraint(check *descpb.TableDescriptor_CheckConstraint) {
 	h.writeStr(check.Name)
 	h.writeStr(check.Expr)
@@ -4449,6 +4522,17 @@ func (h oidHasher) UniqueWithoutIndexConstraintOid(
 	return h.getOid()
 }
 
+func (h oidHasher) ExclusionConstraintOid(
+	dbID descpb.ID, scName string, tableID descpb.ID, ec *descpb.ExclusionConstraint,
+) *tree.DOid {
+	h.writeTypeTag(exclusionConstraintTypeTag)
+	h.writeDB(dbID)
+	h.writeSchema(scName)
+	h.writeTable(tableID)
+	h.writeExclusionConstraint(ec)
+	return h.getOid()
+}
+
 func (h oidHasher) UniqueConstraintOid(
 	dbID descpb.ID, scName string, tableID descpb.ID, indexID descpb.IndexID,
 ) *tree.DOid {
@@ -37,9 +37,9 @@ var _ func() = (*AuthBehaviors)(nil).ConnClose
 var _ RoleMapper = (*AuthBehaviors)(nil).MapRole
 
 // This is a hack for the unused-symbols linter. These two functions
-// are, at present, only called by the GSSAPI integration. The code
-// is guarded by a build tag, which is ignored by the linter.
-// TODO(#dev-inf): Update the linter to include the "gss" build tag.
+// are not called by the built-in methods, but remain available to
+// "ambient" methods registered with RegisterAuthMethod that obtain the
+// client identity or hold resources outside of the pgwire handshake.
 var _ = (*AuthBehaviors)(nil).SetConnClose
 var _ = (*AuthBehaviors)(nil).SetReplacementIdentity
 
@@ -0,0 +1,353 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/identmap"
+	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
+	"github.com/cockroachdb/cockroach/pkg/util/ldap"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains the "ldap" HBA method, which verifies the
+// password supplied by the client against an LDAP directory.
+//
+// Like in PostgreSQL, two modes are supported:
+//
+// - simple bind: the client's username is surrounded by ldapprefix
+//   and ldapsuffix to form the DN used to bind to the directory:
+//
+//     host all all all ldap ldapserver=ldap.example.com ldapprefix="uid=" "ldapsuffix=,ou=people,dc=example,dc=com"
+//
+// - search+bind: the server first binds with ldapbinddn and
+//   ldapbindpasswd (or anonymously), then searches ldapbasedn for the
+//   entry whose ldapsearchattribute (default "uid") matches the
+//   username, or that matches ldapsearchfilter where "$username" is
+//   replaced by the username. It then binds as the DN found:
+//
+//     host all all all ldap ldapserver=ldap.example.com "ldapbasedn=dc=example,dc=com" ldapsearchattribute=uid
+//
+// Options containing commas must be quoted as a whole, as shown above.
+//
+// The connection to the directory is in cleartext unless ldapscheme=ldaps
+// or ldaptls=1 (StartTLS) is specified. The server certificate is
+// verified against the system root CAs, plus the CA configured in
+// server.ldap_authentication.custom_ca if any.
+//
+// The "map" option can be used to map the username to a different SQL
+// user via the identity map.
+
+// ldapCustomCA is the cluster setting that holds a CA certificate
+// used to verify the LDAP server certificate.
+var ldapCustomCA = settings.RegisterValidatedStringSetting(
+	settings.TenantWritable,
+	"server.ldap_authentication.custom_ca",
+	"PEM-encoded CA certificate(s) trusted, in addition to the system root CAs, "+
+		"to verify the certificate of LDAP servers used by the ldap HBA method",
+	"",
+	func(_ *settings.Values, s string) error {
+		if s != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(s)) {
+			return errors.New("no valid PEM-encoded certificate found")
+		}
+		return nil
+	},
+).WithPublic()
+
+// ldapTimeout bounds the duration of the interactions with the LDAP
+// server for a single authentication attempt.
+const ldapTimeout = 10 * time.Second
+
+// ldapUsernamePlaceholder is replaced by the username in
+// ldapsearchfilter.
+const ldapUsernamePlaceholder = "$username"
+
+// ldapOptions are the HBA options accepted by the ldap method.
+var ldapOptions = []string{
+	"ldapserver", "ldapport", "ldapscheme", "ldaptls",
+	"ldapprefix", "ldapsuffix",
+	"ldapbasedn", "ldapbinddn", "ldapbindpasswd", "ldapsearchattribute", "ldapsearchfilter",
+	"map",
+}
+
+// ldapConf is the configuration of the ldap method, extracted from the
+// options of an HBA entry.
+type ldapConf struct {
+	addr     string
+	ldaps    bool
+	startTLS bool
+
+	// Simple bind mode.
+	prefix, suffix string
+
+	// Search+bind mode.
+	baseDN          string
+	bindDN          string
+	bindPassword    string
+	searchAttribute string
+	searchFilter    string
+}
+
+func (c *ldapConf) searchMode() bool {
+	return c.baseDN != ""
+}
+
+// makeLDAPConf validates the options of an HBA entry using the ldap
+// method and returns the corresponding configuration.
+func makeLDAPConf(entry hba.Entry) (*ldapConf, error) {
+	seen := map[string]bool{}
+	for _, opt := range entry.Options {
+		name := opt[0]
+		known := false
+		for _, o := range ldapOptions {
+			if name == o {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, errors.WithHintf(
+				errors.Newf("unknown option %q for HBA method %q", name, entry.Method.Value),
+				"Supported options: %s", strings.Join(ldapOptions, ", "))
+		}
+		if seen[name] {
+			return nil, errors.Newf("option %q specified more than once", name)
+		}
+		seen[name] = true
+	}
+
+	c := &ldapConf{
+		prefix:          entry.GetOption("ldapprefix"),
+		suffix:          entry.GetOption("ldapsuffix"),
+		baseDN:          entry.GetOption("ldapbasedn"),
+		bindDN:          entry.GetOption("ldapbinddn"),
+		bindPassword:    entry.GetOption("ldapbindpasswd"),
+		searchAttribute: entry.GetOption("ldapsearchattribute"),
+		searchFilter:    entry.GetOption("ldapsearchfilter"),
+	}
+
+	server := entry.GetOption("ldapserver")
+	if server == "" {
+		return nil, errors.New(`HBA method "ldap" requires option "ldapserver"`)
+	}
+	switch scheme := entry.GetOption("ldapscheme"); scheme {
+	case "", "ldap":
+	case "ldaps":
+		c.ldaps = true
+	default:
+		return nil, errors.Newf(`invalid value for "ldapscheme": %q (expected "ldap" or "ldaps")`, scheme)
+	}
+	switch tlsOpt := entry.GetOption("ldaptls"); tlsOpt {
+	case "", "0":
+	case "1":
+		c.startTLS = true
+	default:
+		return nil, errors.Newf(`invalid value for "ldaptls": %q (expected 0 or 1)`, tlsOpt)
+	}
+	if c.ldaps && c.startTLS {
+		return nil, errors.New(`"ldaptls=1" cannot be used with "ldapscheme=ldaps"`)
+	}
+	port := "389"
+	if c.ldaps {
+		port = "636"
+	}
+	if p := entry.GetOption("ldapport"); p != "" {
+		if n, err := strconv.Atoi(p); err != nil || n <= 0 || n > 65535 {
+			return nil, errors.Newf(`invalid value for "ldapport": %q`, p)
+		}
+		port = p
+	}
+	c.addr = net.JoinHostPort(server, port)
+
+	simpleBind := seen["ldapprefix"] || seen["ldapsuffix"]
+	if simpleBind {
+		if c.searchMode() || seen["ldapbinddn"] || seen["ldapbindpasswd"] ||
+			seen["ldapsearchattribute"] || seen["ldapsearchfilter"] {
+			return nil, errors.New(`cannot use "ldapbasedn", "ldapbinddn", "ldapbindpasswd", ` +
+				`"ldapsearchattribute" or "ldapsearchfilter" together with "ldapprefix" or "ldapsuffix"`)
+		}
+		return c, nil
+	}
+
+	if !c.searchMode() {
+		return nil, errors.New(
+			`HBA method "ldap" requires option "ldapbasedn", "ldapprefix" or "ldapsuffix" to be set`)
+	}
+	if c.searchAttribute != "" && c.searchFilter != "" {
+		return nil, errors.New(`cannot use "ldapsearchattribute" together with "ldapsearchfilter"`)
+	}
+	if (c.bindDN == "") != (c.bindPassword == "") {
+		return nil, errors.New(`"ldapbinddn" and "ldapbindpasswd" must be specified together`)
+	}
+	if c.searchAttribute == "" && c.searchFilter == "" {
+		c.searchAttribute = "uid"
+	}
+	if err := ldap.ParseFilter(c.filter("user")); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// filter returns the search filter used to find the directory entry of
+// the given user.
+func (c *ldapConf) filter(username string) string {
+	escaped := ldap.EscapeFilter(username)
+	if c.searchFilter != "" {
+		return strings.ReplaceAll(c.searchFilter, ldapUsernamePlaceholder, escaped)
+	}
+	return "(" + c.searchAttribute + "=" + escaped + ")"
+}
+
+// checkLDAPOptions is the CheckHBAEntry for the ldap method.
+func checkLDAPOptions(_ *settings.Values, entry hba.Entry) error {
+	_, err := makeLDAPConf(entry)
+	return err
+}
+
+// authLDAP is the AuthMethod constructor for HBA method "ldap":
+// authenticate using a cleartext password, verified by binding to an
+// LDAP directory.
+func authLDAP(
+	_ context.Context,
+	c AuthConn,
+	_ tls.ConnectionState,
+	execCfg *sql.ExecutorConfig,
+	entry *hba.Entry,
+	identMap *identmap.Conf,
+) (*AuthBehaviors, error) {
+	conf, err := makeLDAPConf(*entry)
+	if err != nil {
+		return nil, err
+	}
+	b := &AuthBehaviors{}
+	b.SetRoleMapper(HbaMapper(entry, identMap))
+	b.SetAuthenticator(func(
+		ctx context.Context,
+		systemIdentity security.SQLUsername,
+		clientConnection bool,
+		_ PasswordRetrievalFn,
+	) error {
+		// The password is sent in cleartext by the client; care should be
+		// taken by administrators to only use this method over secure
+		// connections.
+		if err := c.SendAuthRequest(authCleartextPassword, nil /* data */); err != nil {
+			return err
+		}
+		pwdData, err := c.GetPwdData()
+		if err != nil {
+			c.LogAuthFailed(ctx, eventpb.AuthFailReason_PRE_HOOK_ERROR, err)
+			return err
+		}
+		password, err := passwordString(pwdData)
+		if err != nil {
+			c.LogAuthFailed(ctx, eventpb.AuthFailReason_PRE_HOOK_ERROR, err)
+			return err
+		}
+		if password == "" {
+			c.LogAuthInfof(ctx, "empty password")
+			return security.NewErrPasswordUserAuthFailed(systemIdentity)
+		}
+
+		tlsConfig, err := ldapTLSConfig(&execCfg.Settings.SV)
+		if err != nil {
+			return err
+		}
+		if err := contextutil.RunWithTimeout(ctx, "ldap authentication", ldapTimeout,
+			func(ctx context.Context) error {
+				return conf.authenticate(ctx, tlsConfig, systemIdentity.Normalized(), password)
+			}); err != nil {
+			// The details are only logged, as they may reveal information
+			// about the directory.
+			c.LogAuthInfof(ctx, "LDAP authentication failed: %v", err)
+			return security.NewErrPasswordUserAuthFailed(systemIdentity)
+		}
+		return nil
+	})
+	return b, nil
+}
+
+// ldapTLSConfig returns the TLS configuration used to connect to LDAP
+// servers.
+func ldapTLSConfig(sv *settings.Values) (*tls.Config, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if ca := ldapCustomCA.Get(sv); ca != "" {
+		if !pool.AppendCertsFromPEM([]byte(ca)) {
+			return nil, errors.Newf("invalid %s", ldapCustomCA.Key())
+		}
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+// authenticate verifies the password of the given user against the
+// directory.
+func (c *ldapConf) authenticate(
+	ctx context.Context, tlsConfig *tls.Config, username, password string,
+) error {
+	var dialTLS *tls.Config
+	if c.ldaps {
+		dialTLS = tlsConfig
+	}
+	conn, err := ldap.Dial(ctx, c.addr, dialTLS)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	if c.startTLS {
+		if err := conn.StartTLS(ctx, tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	var userDN string
+	if c.searchMode() {
+		if err := conn.Bind(ctx, c.bindDN, c.bindPassword); err != nil {
+			return errors.Wrap(err, "binding to search the directory")
+		}
+		entries, err := conn.Search(ctx, ldap.SearchRequest{
+			BaseDN:     c.baseDN,
+			Scope:      ldap.ScopeWholeSubtree,
+			Filter:     c.filter(username),
+			Attributes: []string{"1.1"},
+			SizeLimit:  2,
+		})
+		if ldap.IsResultCode(err, ldap.ResultSizeLimitExceeded) || len(entries) > 1 {
+			return errors.Newf("more than one directory entry found for user %q", username)
+		} else if err != nil {
+			return errors.Wrap(err, "searching the directory")
+		} else if len(entries) == 0 {
+			return errors.Newf("no directory entry found for user %q", username)
+		}
+		userDN = entries[0].DN
+	} else {
+		// The username is interpolated into a DN; refuse characters that
+		// would change its structure.
+		if strings.ContainsAny(username, `,+"\<>;=#`) {
+			return errors.Newf("username %q contains characters not allowed in a DN", username)
+		}
+		userDN = c.prefix + username + c.suffix
+	}
+	return conn.Bind(ctx, userDN, password)
+}
@@ -0,0 +1,215 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"context"
+	"crypto/tls"
+	gosql "database/sql"
+	"net"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/security/securitytest"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/ldap/ldaptest"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLDAPConf(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		options string
+		err     string
+		check   func(t *testing.T, c *ldapConf)
+	}{
+		{`ldapserver=ldap.example.com ldapprefix=uid= "ldapsuffix=,dc=example,dc=com"`, "",
+			func(t *testing.T, c *ldapConf) {
+				require.Equal(t, "ldap.example.com:389", c.addr)
+				require.False(t, c.searchMode())
+				require.Equal(t, "uid=", c.prefix)
+				require.Equal(t, ",dc=example,dc=com", c.suffix)
+			}},
+		{`ldapserver=ldap.example.com ldapscheme=ldaps "ldapbasedn=dc=example,dc=com"`, "",
+			func(t *testing.T, c *ldapConf) {
+				require.Equal(t, "ldap.example.com:636", c.addr)
+				require.True(t, c.ldaps)
+				require.True(t, c.searchMode())
+				require.Equal(t, "(uid=a\\2a)", c.filter("a*"))
+			}},
+		{`ldapserver=::1 ldapport=1389 ldaptls=1 ldapbasedn=dc=com "ldapsearchfilter=(|(uid=$username)(mail=$username))"`, "",
+			func(t *testing.T, c *ldapConf) {
+				require.Equal(t, "[::1]:1389", c.addr)
+				require.True(t, c.startTLS)
+				require.Equal(t, "(|(uid=bob)(mail=bob))", c.filter("bob"))
+			}},
+		{`ldapprefix=uid=`, `requires option "ldapserver"`, nil},
+		{`ldapserver=x`, `requires option "ldapbasedn", "ldapprefix" or "ldapsuffix"`, nil},
+		{`ldapserver=x ldapprefix=uid= ldapbasedn=dc=com`, `cannot use "ldapbasedn"`, nil},
+		{`ldapserver=x ldapscheme=http ldapprefix=uid=`, `invalid value for "ldapscheme"`, nil},
+		{`ldapserver=x ldaptls=yes ldapprefix=uid=`, `invalid value for "ldaptls"`, nil},
+		{`ldapserver=x ldapscheme=ldaps ldaptls=1 ldapprefix=uid=`, `cannot be used with`, nil},
+		{`ldapserver=x ldapport=0 ldapprefix=uid=`, `invalid value for "ldapport"`, nil},
+		{`ldapserver=x ldapbasedn=dc=com ldapsearchattribute=uid ldapsearchfilter=(uid=$username)`,
+			`cannot use "ldapsearchattribute" together with "ldapsearchfilter"`, nil},
+		{`ldapserver=x ldapbasedn=dc=com ldapbinddn=cn=admin`, `must be specified together`, nil},
+		{`ldapserver=x ldapbasedn=dc=com ldapsearchfilter=uid=$username`, `invalid LDAP filter`, nil},
+		{`ldapserver=x ldapserver=y ldapprefix=uid=`, `specified more than once`, nil},
+		{`ldapserver=x ldapprefix=uid= foo=bar`, `unknown option "foo"`, nil},
+	} {
+		t.Run(tc.options, func(t *testing.T) {
+			conf, err := hba.Parse("host all all all ldap " + tc.options)
+			require.NoError(t, err)
+			c, err := makeLDAPConf(conf.Entries[0])
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, c)
+		})
+	}
+}
+
+func TestLDAPAuthentication(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	caPEM, err := securitytest.Asset(filepath.Join(security.EmbeddedCertsDir, security.EmbeddedCACert))
+	require.NoError(t, err)
+	certPEM, err := securitytest.Asset(filepath.Join(security.EmbeddedCertsDir, security.EmbeddedNodeCert))
+	require.NoError(t, err)
+	keyPEM, err := securitytest.Asset(filepath.Join(security.EmbeddedCertsDir, security.EmbeddedNodeKey))
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	directory, err := ldaptest.StartServer(ldaptest.ServerConfig{
+		TLSConfig:  &tls.Config{Certificates: []tls.Certificate{cert}},
+		RequireTLS: true,
+	})
+	require.NoError(t, err)
+	defer directory.Stop()
+	directory.AddEntry("cn=reader,dc=example,dc=com", "readerpw", nil)
+	directory.AddEntry("uid=alice,ou=people,dc=example,dc=com", "alicepw", map[string][]string{
+		"uid": {"alice"}, "mail": {"alice@example.com"},
+	})
+	directory.AddEntry("uid=bob,ou=people,dc=example,dc=com", "bobpw", map[string][]string{
+		"uid": {"bob"}, "mail": {"bob@example.com"},
+	})
+	_, port, err := net.SplitHostPort(directory.Addr())
+	require.NoError(t, err)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE USER alice`)
+	sqlDB.Exec(t, `CREATE USER carol`)
+	sv := &s.ClusterSettings().SV
+	ldapCustomCA.Override(ctx, sv, string(caPEM))
+
+	// Invalid configurations are rejected.
+	sqlDB.ExpectErr(t, `requires option "ldapbasedn", "ldapprefix" or "ldapsuffix"`,
+		`SET CLUSTER SETTING server.host_based_authentication.configuration = 'host all all all ldap ldapserver=x'`)
+	sqlDB.ExpectErr(t, `no valid PEM-encoded certificate found`,
+		`SET CLUSTER SETTING server.ldap_authentication.custom_ca = 'foo'`)
+
+	connect := func(user, password string) error {
+		pgURL, cleanup := sqlutils.PGUrlWithOptionalClientCerts(t, s.ServingSQLAddr(), t.Name(),
+			url.UserPassword(user, password), false /* withClientCerts */)
+		defer cleanup()
+		conn, err := gosql.Open("postgres", pgURL.String())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		var u string
+		if err := conn.QueryRow("SELECT current_user").Scan(&u); err != nil {
+			return err
+		}
+		if u != "alice" {
+			return errors.Newf("unexpected user %q", u)
+		}
+		return nil
+	}
+	setHBA := func(options string) {
+		conf := "host all all all ldap ldapserver=127.0.0.1 ldapport=" + port + " ldaptls=1 " + options
+		require.NoError(t, checkHBASyntaxBeforeUpdatingSetting(sv, conf))
+		connAuthConf.Override(ctx, sv, conf)
+	}
+	expectAuthFailure := func(user, password string) {
+		t.Helper()
+		err := connect(user, password)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "password authentication failed")
+	}
+
+	t.Run("simple bind", func(t *testing.T) {
+		setHBA(`ldapprefix=uid= "ldapsuffix=,ou=people,dc=example,dc=com"`)
+		require.NoError(t, connect("alice", "alicepw"))
+		expectAuthFailure("alice", "wrong")
+		expectAuthFailure("alice", "")
+		// The user must exist both in the directory and in SQL.
+		expectAuthFailure("bob", "bobpw")
+		expectAuthFailure("carol", "carolpw")
+	})
+
+	t.Run("search and bind", func(t *testing.T) {
+		setHBA(`"ldapbasedn=dc=example,dc=com" "ldapbinddn=cn=reader,dc=example,dc=com" ldapbindpasswd=readerpw`)
+		require.NoError(t, connect("alice", "alicepw"))
+		expectAuthFailure("alice", "wrong")
+		expectAuthFailure("carol", "carolpw")
+	})
+
+	t.Run("search filter", func(t *testing.T) {
+		setHBA(`"ldapbasedn=ou=people,dc=example,dc=com" "ldapbinddn=cn=reader,dc=example,dc=com" ` +
+			`ldapbindpasswd=readerpw "ldapsearchfilter=(mail=$username@example.com)"`)
+		require.NoError(t, connect("alice", "alicepw"))
+		expectAuthFailure("alice", "bobpw")
+	})
+
+	t.Run("identity map", func(t *testing.T) {
+		// Bob authenticates with their own directory credentials, and is
+		// mapped to the SQL user alice.
+		connIdentityMapConf.Override(ctx, sv, "ldap bob alice")
+		setHBA(`ldapprefix=uid= "ldapsuffix=,ou=people,dc=example,dc=com" map=ldap`)
+		require.NoError(t, connect("bob", "bobpw"))
+		expectAuthFailure("bob", "alicepw")
+		connIdentityMapConf.Override(ctx, sv, "")
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		setHBA(`ldapprefix=uid= "ldapsuffix=,ou=people,dc=example,dc=com"`)
+		ldapCustomCA.Override(ctx, sv, "")
+		expectAuthFailure("alice", "alicepw")
+	})
+
+	t.Run("no TLS", func(t *testing.T) {
+		// The directory refuses binds over cleartext connections.
+		ldapCustomCA.Override(ctx, sv, string(caPEM))
+		conf := "host all all all ldap ldapserver=127.0.0.1 ldapport=" + port +
+			` ldapprefix=uid= "ldapsuffix=,ou=people,dc=example,dc=com"`
+		connAuthConf.Override(ctx, sv, conf)
+		expectAuthFailure("alice", "alicepw")
+	})
+}
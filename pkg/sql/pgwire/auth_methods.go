@@ -40,9 +40,7 @@ import (
 // the HBA config loaded into the cluster setting
 // server.host_based_authentication.configuration.
 //
-// Other methods can be added using RegisterAuthMethod(). This is done
-// e.g. in the CCL modules to add support for GSS authentication using
-// Kerberos.
+// Other methods can be added using RegisterAuthMethod().
 
 func loadDefaultMethods() {
 	// The "password" method requires a clear text password.
@@ -84,6 +82,13 @@ func loadDefaultMethods() {
 	// The "trust" method accepts any connection attempt that matches
 	// the current rule.
 	RegisterAuthMethod("trust", authTrust, hba.ConnAny, NoOptionsAllowed)
+
+	// The "ldap" method requires a clear text password, which is
+	// verified by binding to an LDAP directory. See auth_ldap.go.
+	//
+	// As with "password", care should be taken by administrators to
+	// only accept this auth method over secure connections.
+	RegisterAuthMethod("ldap", authLDAP, hba.ConnAny, checkLDAPOptions)
 }
 
 // AuthMethod is a top-level factory for composing the various
@@ -104,6 +109,7 @@ var _ AuthMethod = authCertPassword
 var _ AuthMethod = authCertScram
 var _ AuthMethod = authTrust
 var _ AuthMethod = authReject
+var _ AuthMethod = authLDAP
 var _ AuthMethod = authSessionRevivalToken([]byte{})
 
 // authPassword is the AuthMethod constructor for HBA method
@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -42,6 +43,9 @@ const (
 	flush
 	// Some commands, like Describe, don't need a completion message.
 	noCompletionMsg
+	// notificationsDelivery results don't have a completion message either,
+	// but they flush the notifications buffered into them, if any.
+	notificationsDelivery
 )
 
 // commandResult is an implementation of sql.CommandResult that streams a
@@ -59,6 +63,7 @@ type commandResult struct {
 	buffer struct {
 		notices            []pgnotice.Notice
 		paramStatusUpdates []paramStatusUpdate
+		notifications      []notify.Notification
 	}
 
 	err error
@@ -91,6 +96,10 @@ type commandResult struct {
 	// statements.
 	bufferingDisabled bool
 
+	// copyOut is set for COPY ... TO STDOUT statements. The result rows are
+	// then sent as CopyData messages.
+	copyOut *copyOut
+
 	// released is set when the command result has been released so that its
 	// memory can be reused. It is also used to assert against use-after-free
 	// errors.
@@ -144,9 +153,18 @@ func (r *commandResult) Close(ctx context.Context, t sql.TransactionStatusIndica
 		}
 	}
 
+	for _, notification := range r.buffer.notifications {
+		if err := r.conn.bufferNotification(notification); err != nil {
+			panic(errors.NewAssertionErrorWithWrappedErrf(err, "unexpected err when sending notification"))
+		}
+	}
+
 	// Send a completion message, specific to the type of result.
 	switch r.typ {
 	case commandComplete:
+		if r.copyOut != nil && r.copyOut.started {
+			r.conn.bufferCopyDone(r)
+		}
 		tag := cookTag(
 			r.cmdCompleteTag, r.conn.writerState.tagBuf[:0], r.stmtType, r.rowsAffected,
 		)
@@ -170,6 +188,12 @@ func (r *commandResult) Close(ctx context.Context, t sql.TransactionStatusIndica
 		r.conn.maybeReallocate()
 	case noCompletionMsg:
 		// nothing to do
+	case notificationsDelivery:
+		if len(r.buffer.notifications) > 0 {
+			// The error is saved on conn.err.
+			_ /* err */ = r.conn.Flush(r.pos)
+			r.conn.maybeReallocate()
+		}
 	default:
 		panic(errors.AssertionFailedf("unknown type: %v", r.typ))
 	}
@@ -218,6 +242,9 @@ func (r *commandResult) AddRow(ctx context.Context, row tree.Datums) error {
 		return err
 	}
 	r.rowsAffected++
+	if r.copyOut != nil {
+		return r.conn.bufferCopyData(ctx, row, r)
+	}
 	return r.conn.bufferRow(ctx, row, r)
 }
 
@@ -232,7 +259,7 @@ func (r *commandResult) AddBatch(ctx context.Context, batch coldata.Batch) error
 
 // SupportsAddBatch is part of the sql.RestrictedCommandResult interface.
 func (r *commandResult) SupportsAddBatch() bool {
-	return true
+	return r.copyOut == nil
 }
 
 // DisableBuffering is part of the sql.RestrictedCommandResult interface.
@@ -241,6 +268,27 @@ func (r *commandResult) DisableBuffering() {
 	r.bufferingDisabled = true
 }
 
+// BufferedResultsLen is part of the sql.RestrictedCommandResult interface.
+func (r *commandResult) BufferedResultsLen() int {
+	r.assertNotReleased()
+	return r.conn.writerState.buf.Len()
+}
+
+// TruncateBufferedResults is part of the sql.RestrictedCommandResult interface.
+func (r *commandResult) TruncateBufferedResults(idx int) bool {
+	r.assertNotReleased()
+	if r.conn.writerState.fi.lastFlushed >= r.pos {
+		// Some results for this command have already been sent to the client.
+		return false
+	}
+	if idx < 0 || idx > r.conn.writerState.buf.Len() {
+		return false
+	}
+	r.conn.writerState.buf.Truncate(idx)
+	r.rowsAffected = 0
+	return true
+}
+
 // BufferParamStatusUpdate is part of the sql.RestrictedCommandResult interface.
 func (r *commandResult) BufferParamStatusUpdate(param string, val string) {
 	r.buffer.paramStatusUpdates = append(
@@ -254,16 +302,26 @@ func (r *commandResult) BufferNotice(notice pgnotice.Notice) {
 	r.buffer.notices = append(r.buffer.notices, notice)
 }
 
+// BufferNotification is part of the sql.NotificationSender interface.
+func (r *commandResult) BufferNotification(n notify.Notification) {
+	r.buffer.notifications = append(r.buffer.notifications, n)
+}
+
 // SetColumns is part of the sql.RestrictedCommandResult interface.
 func (r *commandResult) SetColumns(ctx context.Context, cols colinfo.ResultColumns) {
 	r.assertNotReleased()
 	r.conn.writerState.fi.registerCmd(r.pos)
-	if r.descOpt == sql.NeedRowDesc {
+	if r.copyOut != nil {
+		// COPY TO describes its rows with a CopyOutResponse, which is sent
+		// regardless of whether a row description was asked for.
+		r.conn.bufferCopyOutResponse(cols, r)
+	} else if r.descOpt == sql.NeedRowDesc {
 		_ /* err */ = r.conn.writeRowDescription(ctx, cols, r.formatCodes, &r.conn.writerState.buf)
 	}
 	r.types = make([]*types.T, len(cols))
 	for i, col := range cols {
-		r.types[i] = col.Typ
+		// Values of a domain are sent like values of its base type.
+		r.types[i] = col.Typ.DomainBaseType()
 	}
 }
 
@@ -367,6 +425,12 @@ func (c *conn) newCommandResult(
 		descOpt:        descOpt,
 		formatCodes:    formatCodes,
 	}
+	if copyTo, ok := stmt.(*tree.CopyTo); ok {
+		r.copyOut = newCopyOut(&copyTo.Options)
+		// The row limit of the portal doesn't apply to COPY, whose data is always
+		// sent in full.
+		return r
+	}
 	if limit == 0 {
 		return r
 	}
@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -751,6 +752,14 @@ func (c *conn) bufferNotice(ctx context.Context, noticeErr pgnotice.Notice) erro
 	return writeErrFields(ctx, c.sv, noticeErr, &c.msgBuilder, &c.writerState.buf)
 }
 
+func (c *conn) bufferNotification(n notify.Notification) error {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgNotificationResponse)
+	c.msgBuilder.putInt32(int32(n.PID))
+	c.msgBuilder.writeTerminatedString(n.Channel)
+	c.msgBuilder.writeTerminatedString(n.Payload)
+	return c.msgBuilder.finishMsg(&c.writerState.buf)
+}
+
 func (c *conn) sendInitialConnData(
 	ctx context.Context, sqlServer *sql.Server, onDefaultIntSizeChange func(newSize int32),
 ) (sql.ConnectionHandler, error) {
@@ -1705,6 +1714,11 @@ func (c *conn) CreateDrainResult(pos sql.CmdPos) sql.DrainResult {
 	return c.newMiscResult(pos, noCompletionMsg)
 }
 
+// CreateNotificationResult is part of the sql.ClientComm interface.
+func (c *conn) CreateNotificationResult(pos sql.CmdPos) sql.NotificationResult {
+	return c.newMiscResult(pos, notificationsDelivery)
+}
+
 // CreateBindResult is part of the sql.ClientComm interface.
 func (c *conn) CreateBindResult(pos sql.CmdPos) sql.BindResult {
 	return c.newMiscResult(pos, bindComplete)
@@ -0,0 +1,255 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
+)
+
+// copyOutBinarySignature is the header of the binary COPY format, followed by
+// the flags field and the length of the header extension area, both zero.
+const copyOutBinarySignature = "PGCOPY\n\377\r\n\000"
+
+// copyOut holds the state of a COPY ... TO STDOUT statement, whose result
+// rows are sent to the client as CopyData messages instead of DataRows.
+type copyOut struct {
+	format    tree.CopyFormat
+	delimiter byte
+	null      string
+	header    bool
+	quote     byte
+	escape    byte
+
+	// forceQuoteAll and forceQuoteNames hold the FORCE QUOTE option. They are
+	// resolved into forceQuote, indexed by column, once the result columns
+	// are known.
+	forceQuoteAll   bool
+	forceQuoteNames tree.NameList
+	forceQuote      []bool
+
+	// started is set once the CopyOutResponse message has been sent. Only then
+	// is the data terminated by a CopyDone message.
+	started bool
+
+	// scratch is used to encode the text representation of a value before it
+	// is escaped into the CopyData message.
+	scratch writeBuffer
+}
+
+// newCopyOut creates the copyOut for the given COPY TO options. The options
+// have been validated when the statement was planned, so the delimiter and
+// NULL options are known to be string constants.
+func newCopyOut(opts *tree.CopyOptions) *copyOut {
+	c := &copyOut{
+		format:    opts.CopyFormat,
+		delimiter: '\t',
+		null:      `\N`,
+		header:    opts.Header,
+		quote:     '"',
+
+		forceQuoteAll:   opts.ForceQuoteAll,
+		forceQuoteNames: opts.ForceQuote,
+	}
+	c.scratch.init(nil /* bytecount */)
+	if c.format == tree.CopyFormatCSV {
+		c.delimiter = ','
+		c.null = ""
+	}
+	if d, ok := opts.Delimiter.(*tree.StrVal); ok {
+		c.delimiter = d.RawString()[0]
+	}
+	if n, ok := opts.Null.(*tree.StrVal); ok {
+		c.null = n.RawString()
+	}
+	if opts.Quote != nil {
+		c.quote = opts.Quote.RawString()[0]
+	}
+	// Like in Postgres, the escape character defaults to the quote character.
+	c.escape = c.quote
+	if opts.Escape != nil {
+		c.escape = opts.Escape.RawString()[0]
+	}
+	return c
+}
+
+// bufferCopyOutResponse serializes a CopyOutResponse message for the given
+// columns, followed by the binary signature or the header line if the format
+// requires it.
+func (c *conn) bufferCopyOutResponse(cols colinfo.ResultColumns, r *commandResult) {
+	cp := r.copyOut
+	if cp.forceQuoteAll || len(cp.forceQuoteNames) > 0 {
+		cp.forceQuote = make([]bool, len(cols))
+		for i := range cols {
+			if cp.forceQuoteAll {
+				cp.forceQuote[i] = true
+				continue
+			}
+			for _, name := range cp.forceQuoteNames {
+				if string(name) == cols[i].Name {
+					cp.forceQuote[i] = true
+				}
+			}
+		}
+	}
+	fmtCode := pgwirebase.FormatText
+	if cp.format == tree.CopyFormatBinary {
+		fmtCode = pgwirebase.FormatBinary
+	}
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyOutResponse)
+	c.msgBuilder.writeByte(byte(fmtCode))
+	c.msgBuilder.putInt16(int16(len(cols)))
+	for range cols {
+		c.msgBuilder.putInt16(int16(fmtCode))
+	}
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(errors.NewAssertionErrorWithWrappedErrf(err, "unexpected err from buffer"))
+	}
+	cp.started = true
+
+	switch {
+	case cp.format == tree.CopyFormatBinary:
+		c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+		c.msgBuilder.writeString(copyOutBinarySignature)
+		c.msgBuilder.putInt32(0 /* flags */)
+		c.msgBuilder.putInt32(0 /* header extension length */)
+	case cp.header:
+		c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+		for i := range cols {
+			if i > 0 {
+				c.msgBuilder.writeByte(cp.delimiter)
+			}
+			cp.writeField(&c.msgBuilder, []byte(cols[i].Name), false /* force */)
+		}
+		c.msgBuilder.writeByte('\n')
+	default:
+		return
+	}
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(errors.NewAssertionErrorWithWrappedErrf(err, "unexpected err from buffer"))
+	}
+}
+
+// bufferCopyData serializes a row as a CopyData message in the format of the
+// COPY TO statement. Depending on the buffer size limit, bufferCopyData may
+// flush the buffered data to the connection.
+func (c *conn) bufferCopyData(ctx context.Context, row tree.Datums, r *commandResult) error {
+	cp := r.copyOut
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+	if cp.format == tree.CopyFormatBinary {
+		c.msgBuilder.putInt16(int16(len(row)))
+		for i, d := range row {
+			c.msgBuilder.writeBinaryDatum(ctx, d, r.location, r.types[i])
+		}
+	} else {
+		for i, d := range row {
+			if i > 0 {
+				c.msgBuilder.writeByte(cp.delimiter)
+			}
+			if d == tree.DNull {
+				c.msgBuilder.writeString(cp.null)
+				continue
+			}
+			// Encode the value like a text DataRow field, and strip the length
+			// prefix before escaping it.
+			cp.scratch.reset()
+			cp.scratch.writeTextDatum(ctx, d, r.conv, r.location, r.types[i])
+			if cp.scratch.err != nil {
+				c.msgBuilder.setError(cp.scratch.err)
+				break
+			}
+			force := cp.forceQuote != nil && cp.forceQuote[i]
+			cp.writeField(&c.msgBuilder, cp.scratch.wrapped.Bytes()[4:], force)
+		}
+		c.msgBuilder.writeByte('\n')
+	}
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(errors.NewAssertionErrorWithWrappedErrf(err, "unexpected err from buffer"))
+	}
+	return c.maybeFlush(r.pos, r.bufferingDisabled)
+}
+
+// bufferCopyDone terminates the data of a COPY TO statement, writing the
+// binary trailer first if needed.
+func (c *conn) bufferCopyDone(r *commandResult) {
+	if r.copyOut.format == tree.CopyFormatBinary {
+		c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+		c.msgBuilder.putInt16(-1)
+		if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+			panic(errors.NewAssertionErrorWithWrappedErrf(err, "unexpected err from buffer"))
+		}
+	}
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyDone)
+	if err := c.msgBuilder.finishMsg(&c.writerState.buf); err != nil {
+		panic(errors.NewAssertionErrorWithWrappedErrf(err, "unexpected err from buffer"))
+	}
+}
+
+// writeField writes the text representation of a non-NULL value, escaped
+// according to the text or CSV format. If force is set, a CSV value is always
+// quoted.
+func (cp *copyOut) writeField(b *writeBuffer, v []byte, force bool) {
+	if cp.format == tree.CopyFormatCSV {
+		cp.writeCSVField(b, v, force)
+		return
+	}
+	for _, ch := range v {
+		switch ch {
+		case '\\':
+			b.writeString(`\\`)
+		case '\b':
+			b.writeString(`\b`)
+		case '\f':
+			b.writeString(`\f`)
+		case '\n':
+			b.writeString(`\n`)
+		case '\r':
+			b.writeString(`\r`)
+		case '\t':
+			b.writeString(`\t`)
+		case '\v':
+			b.writeString(`\v`)
+		default:
+			if ch == cp.delimiter {
+				b.writeByte('\\')
+			}
+			b.writeByte(ch)
+		}
+	}
+}
+
+// writeCSVField writes a CSV value. Like in Postgres, the value is quoted if
+// it contains special characters or could be mistaken for the NULL string or
+// the end-of-data marker.
+func (cp *copyOut) writeCSVField(b *writeBuffer, v []byte, force bool) {
+	s := string(v)
+	needsQuote := force || s == cp.null || s == `\.` ||
+		strings.IndexByte(s, cp.delimiter) >= 0 ||
+		strings.IndexByte(s, cp.quote) >= 0 ||
+		strings.ContainsAny(s, "\r\n")
+	if !needsQuote {
+		b.write(v)
+		return
+	}
+	b.writeByte(cp.quote)
+	for _, ch := range v {
+		if ch == cp.quote || ch == cp.escape {
+			b.writeByte(cp.escape)
+		}
+		b.writeByte(ch)
+	}
+	b.writeByte(cp.quote)
+}
@@ -42,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgconn"
 	pgproto3 "github.com/jackc/pgproto3/v2"
 	pgx "github.com/jackc/pgx/v4"
 	"github.com/lib/pq"
@@ -2039,3 +2040,144 @@ func TestFailPrepareFailsTxn(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestPGNotifications checks that the notifications sent with NOTIFY are
+// delivered to the sessions which are listening on the channel once the
+// sending transaction commits.
+func TestPGNotifications(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	params := base.TestServerArgs{Insecure: true}
+	s, _, _ := serverutils.StartServer(t, params)
+
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+
+	host, ports, _ := net.SplitHostPort(s.ServingSQLAddr())
+	port, _ := strconv.Atoi(ports)
+
+	connCfg, err := pgx.ParseConfig(
+		fmt.Sprintf("postgresql://%s@%s:%d/defaultdb?sslmode=disable", security.RootUser, host, port),
+	)
+	require.NoError(t, err)
+	connCfg.TLSConfig = nil
+	connCfg.Logger = pgxTestLogger{}
+
+	listener, err := pgx.ConnectConfig(ctx, connCfg)
+	require.NoError(t, err)
+	defer func() { _ = listener.Close(ctx) }()
+	sender, err := pgx.ConnectConfig(ctx, connCfg)
+	require.NoError(t, err)
+	defer func() { _ = sender.Close(ctx) }()
+
+	_, err = listener.Exec(ctx, "LISTEN foo")
+	require.NoError(t, err)
+
+	waitForNotification := func() *pgconn.Notification {
+		waitCtx, cancel := context.WithTimeout(ctx, testutils.DefaultSucceedsSoonDuration)
+		defer cancel()
+		n, err := listener.WaitForNotification(waitCtx)
+		require.NoError(t, err)
+		return n
+	}
+
+	var senderPID uint32
+	require.NoError(t, sender.QueryRow(ctx, "SELECT pg_backend_pid()").Scan(&senderPID))
+
+	// Notifications sent by a transaction which rolls back are not delivered.
+	tx, err := sender.Begin(ctx)
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx, "NOTIFY foo, 'rolled back'")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback(ctx))
+
+	// Notifications on channels the session isn't listening on are not
+	// delivered either.
+	_, err = sender.Exec(ctx, "NOTIFY bar, 'ignored'")
+	require.NoError(t, err)
+
+	tx, err = sender.Begin(ctx)
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx, "NOTIFY foo, 'hello'")
+	require.NoError(t, err)
+	// Identical notifications sent by a transaction are only delivered once.
+	_, err = tx.Exec(ctx, "SELECT pg_notify('foo', 'hello')")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(ctx))
+
+	_, err = sender.Exec(ctx, "SELECT pg_notify('foo', 'world')")
+	require.NoError(t, err)
+
+	n := waitForNotification()
+	require.Equal(t, "foo", n.Channel)
+	require.Equal(t, "hello", n.Payload)
+	require.Equal(t, senderPID, n.PID)
+	n = waitForNotification()
+	require.Equal(t, "foo", n.Channel)
+	require.Equal(t, "world", n.Payload)
+
+	var channels []string
+	require.NoError(t, listener.QueryRow(
+		ctx, "SELECT array_agg(c) FROM pg_listening_channels() AS c",
+	).Scan(&channels))
+	require.Equal(t, []string{"foo"}, channels)
+
+	// After UNLISTEN, notifications are no longer delivered.
+	_, err = listener.Exec(ctx, "UNLISTEN *")
+	require.NoError(t, err)
+	_, err = sender.Exec(ctx, "NOTIFY foo")
+	require.NoError(t, err)
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = listener.WaitForNotification(waitCtx)
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+}
+
+// TestPGNotificationsMultiNode checks that the notifications sent on one node
+// are delivered to the sessions listening on another node.
+func TestPGNotificationsMultiNode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := serverutils.StartNewTestCluster(t, 2, base.TestClusterArgs{
+		ServerArgs: base.TestServerArgs{Insecure: true},
+	})
+	defer tc.Stopper().Stop(ctx)
+
+	connect := func(s serverutils.TestServerInterface) *pgx.Conn {
+		host, ports, _ := net.SplitHostPort(s.ServingSQLAddr())
+		port, _ := strconv.Atoi(ports)
+		connCfg, err := pgx.ParseConfig(
+			fmt.Sprintf("postgresql://%s@%s:%d/defaultdb?sslmode=disable", security.RootUser, host, port),
+		)
+		require.NoError(t, err)
+		connCfg.TLSConfig = nil
+		connCfg.Logger = pgxTestLogger{}
+		conn, err := pgx.ConnectConfig(ctx, connCfg)
+		require.NoError(t, err)
+		return conn
+	}
+
+	sender := connect(tc.Server(0))
+	defer func() { _ = sender.Close(ctx) }()
+	listener := connect(tc.Server(1))
+	defer func() { _ = listener.Close(ctx) }()
+
+	_, err := listener.Exec(ctx, "LISTEN foo")
+	require.NoError(t, err)
+
+	var senderPID uint32
+	require.NoError(t, sender.QueryRow(ctx, "SELECT pg_backend_pid()").Scan(&senderPID))
+	_, err = sender.Exec(ctx, "NOTIFY foo, 'from node 1'")
+	require.NoError(t, err)
+
+	waitCtx, cancel := context.WithTimeout(ctx, testutils.DefaultSucceedsSoonDuration)
+	defer cancel()
+	n, err := listener.WaitForNotification(waitCtx)
+	require.NoError(t, err)
+	require.Equal(t, "foo", n.Channel)
+	require.Equal(t, "from node 1", n.Payload)
+	require.Equal(t, senderPID, n.PID)
+}
@@ -33,7 +33,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/bitarray"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
-	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
@@ -290,31 +289,49 @@ func NewInvalidBinaryRepresentationErrorf(format string, args ...interface{}) er
 	return pgerror.Newf(pgcode.InvalidBinaryRepresentation, format, args...)
 }
 
-// validateArrayDimensions takes the number of dimensions and elements and
-// returns an error if we don't support that combination.
-func validateArrayDimensions(nDimensions int, nElements int) error {
-	switch nDimensions {
-	case 1:
-		break
-	case 0:
-		// 0-dimensional array means 0-length array: validate that.
-		if nElements == 0 {
-			break
+// validateArrayDimensions takes the dimensions and number of elements of an
+// array and returns an error if they do not describe a valid array.
+func validateArrayDimensions(dims []tree.ArrayDim, nElements int) error {
+	if len(dims) > tree.MaxArrayDims {
+		return pgerror.Newf(pgcode.ProgramLimitExceeded,
+			"number of array dimensions (%d) exceeds the maximum allowed (%d)",
+			len(dims), tree.MaxArrayDims)
+	}
+	n := 1
+	for _, dim := range dims {
+		if dim.Length < 0 || dim.Length > nElements {
+			return NewInvalidBinaryRepresentationErrorf("invalid array dimension length: %d", dim.Length)
 		}
-		fallthrough
-	default:
-		return unimplemented.NewWithIssuef(32552,
-			"%d-dimension arrays not supported; only 1-dimension", nDimensions)
+		n *= dim.Length
+	}
+	if len(dims) == 0 {
+		n = 0
+	}
+	if n != nElements {
+		return NewInvalidBinaryRepresentationErrorf(
+			"array dimensions describe %d elements, but the array has %d", n, nElements)
 	}
 	return nil
 }
 
+// pgtypeArrayDimensions converts the dimensions of an array decoded by pgtype
+// into the dimensions of a tree.DArray.
+func pgtypeArrayDimensions(pgDims []pgtype.ArrayDimension) []tree.ArrayDim {
+	dims := make([]tree.ArrayDim, len(pgDims))
+	for i, dim := range pgDims {
+		dims[i] = tree.ArrayDim{Length: int(dim.Length), LowerBound: int(dim.LowerBound)}
+	}
+	return dims
+}
+
 // DecodeDatum decodes bytes with specified type and format code into
 // a datum. If res is nil, then user defined types are not attempted
 // to be resolved.
 func DecodeDatum(
 	evalCtx *tree.EvalContext, t *types.T, code FormatCode, b []byte,
 ) (tree.Datum, error) {
+	// Values of a domain are encoded like values of its base type.
+	t = t.DomainBaseType()
 	id := t.Oid()
 	switch code {
 	case FormatText:
@@ -451,7 +468,8 @@ func DecodeDatum(
 			if arr.Status != pgtype.Present {
 				return tree.DNull, nil
 			}
-			if err := validateArrayDimensions(len(arr.Dimensions), len(arr.Elements)); err != nil {
+			dims := pgtypeArrayDimensions(arr.Dimensions)
+			if err := validateArrayDimensions(dims, len(arr.Elements)); err != nil {
 				return nil, err
 			}
 			out := tree.NewDArray(types.Int)
@@ -466,6 +484,9 @@ func DecodeDatum(
 					return nil, err
 				}
 			}
+			if err := out.SetDims(dims); err != nil {
+				return nil, err
+			}
 			return out, nil
 		case oid.T__text, oid.T__name:
 			var arr pgtype.TextArray
@@ -476,7 +497,8 @@ func DecodeDatum(
 			if arr.Status != pgtype.Present {
 				return tree.DNull, nil
 			}
-			if err := validateArrayDimensions(len(arr.Dimensions), len(arr.Elements)); err != nil {
+			dims := pgtypeArrayDimensions(arr.Dimensions)
+			if err := validateArrayDimensions(dims, len(arr.Elements)); err != nil {
 				return nil, err
 			}
 			out := tree.NewDArray(types.String)
@@ -497,6 +519,9 @@ func DecodeDatum(
 					return nil, err
 				}
 			}
+			if err := out.SetDims(dims); err != nil {
+				return nil, err
+			}
 			return out, nil
 		case oid.T_jsonb:
 			if err := validateStringBytes(b); err != nil {
@@ -957,12 +982,8 @@ func decodeBinaryArray(
 		ElemOid int32
 	}
 	var dim struct {
-		// The next two fields should be arrays of size Ndims. However, since
-		// we only support 1-dimensional arrays for now, for convenience we can
-		// leave them in this struct as such for `binary.Read` to parse for us.
-		DimSize int32
-		// Dim lower bound
-		_ int32
+		DimSize    int32
+		LowerBound int32
 	}
 	r := bytes.NewBuffer(b)
 	if err := binary.Read(r, binary.BigEndian, &hdr); err != nil {
@@ -975,14 +996,28 @@ func decodeBinaryArray(
 	if hdr.Ndims == 0 {
 		return arr, nil
 	}
-	if err := binary.Read(r, binary.BigEndian, &dim); err != nil {
-		return nil, err
+	if hdr.Ndims < 0 || hdr.Ndims > tree.MaxArrayDims {
+		return nil, NewInvalidBinaryRepresentationErrorf("invalid number of dimensions: %d", hdr.Ndims)
 	}
-	if err := validateArrayDimensions(int(hdr.Ndims), int(dim.DimSize)); err != nil {
-		return nil, err
+	// The header is followed by the size and lower bound of each dimension.
+	dims := make([]tree.ArrayDim, hdr.Ndims)
+	nElements := 1
+	for i := range dims {
+		if err := binary.Read(r, binary.BigEndian, &dim); err != nil {
+			return nil, err
+		}
+		if dim.DimSize < 0 || int(dim.DimSize) > len(b) {
+			return nil, NewInvalidBinaryRepresentationErrorf("invalid array dimension length: %d", dim.DimSize)
+		}
+		dims[i] = tree.ArrayDim{Length: int(dim.DimSize), LowerBound: int(dim.LowerBound)}
+		// Every element takes at least 4 bytes, so this also guards against
+		// overflow.
+		if nElements *= int(dim.DimSize); nElements > len(b) {
+			return nil, NewInvalidBinaryRepresentationErrorf("array dimensions exceed the data size")
+		}
 	}
 	var vlen int32
-	for i := int32(0); i < dim.DimSize; i++ {
+	for i := 0; i < nElements; i++ {
 		if err := binary.Read(r, binary.BigEndian, &vlen); err != nil {
 			return nil, err
 		}
@@ -1001,6 +1036,9 @@ func decodeBinaryArray(
 			return nil, err
 		}
 	}
+	if err := arr.SetDims(dims); err != nil {
+		return nil, err
+	}
 	return arr, nil
 }
 
@@ -13,12 +13,16 @@ func _() {
 	_ = x[ServerMsgBindComplete-50]
 	_ = x[ServerMsgCommandComplete-67]
 	_ = x[ServerMsgCloseComplete-51]
+	_ = x[ServerMsgCopyData-100]
+	_ = x[ServerMsgCopyDone-99]
 	_ = x[ServerMsgCopyInResponse-71]
+	_ = x[ServerMsgCopyOutResponse-72]
 	_ = x[ServerMsgDataRow-68]
 	_ = x[ServerMsgEmptyQuery-73]
 	_ = x[ServerMsgErrorResponse-69]
 	_ = x[ServerMsgNoticeResponse-78]
 	_ = x[ServerMsgNoData-110]
+	_ = x[ServerMsgNotificationResponse-65]
 	_ = x[ServerMsgParameterDescription-116]
 	_ = x[ServerMsgParameterStatus-83]
 	_ = x[ServerMsgParseComplete-49]
@@ -28,23 +32,26 @@ func _() {
 }
 
 const (
-	_ServerMessageType_name_0 = "ServerMsgParseCompleteServerMsgBindCompleteServerMsgCloseComplete"
-	_ServerMessageType_name_1 = "ServerMsgCommandCompleteServerMsgDataRowServerMsgErrorResponse"
-	_ServerMessageType_name_2 = "ServerMsgCopyInResponse"
-	_ServerMessageType_name_3 = "ServerMsgEmptyQuery"
-	_ServerMessageType_name_4 = "ServerMsgBackendKeyData"
-	_ServerMessageType_name_5 = "ServerMsgNoticeResponse"
-	_ServerMessageType_name_6 = "ServerMsgAuthServerMsgParameterStatusServerMsgRowDescription"
-	_ServerMessageType_name_7 = "ServerMsgReady"
-	_ServerMessageType_name_8 = "ServerMsgNoData"
-	_ServerMessageType_name_9 = "ServerMsgPortalSuspendedServerMsgParameterDescription"
+	_ServerMessageType_name_0  = "ServerMsgParseCompleteServerMsgBindCompleteServerMsgCloseComplete"
+	_ServerMessageType_name_1  = "ServerMsgNotificationResponse"
+	_ServerMessageType_name_2  = "ServerMsgCommandCompleteServerMsgDataRowServerMsgErrorResponse"
+	_ServerMessageType_name_3  = "ServerMsgCopyInResponseServerMsgCopyOutResponseServerMsgEmptyQuery"
+	_ServerMessageType_name_4  = "ServerMsgBackendKeyData"
+	_ServerMessageType_name_5  = "ServerMsgNoticeResponse"
+	_ServerMessageType_name_6  = "ServerMsgAuthServerMsgParameterStatusServerMsgRowDescription"
+	_ServerMessageType_name_7  = "ServerMsgReady"
+	_ServerMessageType_name_8  = "ServerMsgCopyDoneServerMsgCopyData"
+	_ServerMessageType_name_9  = "ServerMsgNoData"
+	_ServerMessageType_name_10 = "ServerMsgPortalSuspendedServerMsgParameterDescription"
 )
 
 var (
-	_ServerMessageType_index_0 = [...]uint8{0, 22, 43, 65}
-	_ServerMessageType_index_1 = [...]uint8{0, 24, 40, 62}
-	_ServerMessageType_index_6 = [...]uint8{0, 13, 37, 60}
-	_ServerMessageType_index_9 = [...]uint8{0, 24, 53}
+	_ServerMessageType_index_0  = [...]uint8{0, 22, 43, 65}
+	_ServerMessageType_index_2  = [...]uint8{0, 24, 40, 62}
+	_ServerMessageType_index_3  = [...]uint8{0, 23, 47, 66}
+	_ServerMessageType_index_6  = [...]uint8{0, 13, 37, 60}
+	_ServerMessageType_index_8  = [...]uint8{0, 17, 34}
+	_ServerMessageType_index_10 = [...]uint8{0, 24, 53}
 )
 
 func (i ServerMessageType) String() string {
@@ -52,13 +59,14 @@ func (i ServerMessageType) String() string {
 	case 49 <= i && i <= 51:
 		i -= 49
 		return _ServerMessageType_name_0[_ServerMessageType_index_0[i]:_ServerMessageType_index_0[i+1]]
+	case i == 65:
+		return _ServerMessageType_name_1
 	case 67 <= i && i <= 69:
 		i -= 67
-		return _ServerMessageType_name_1[_ServerMessageType_index_1[i]:_ServerMessageType_index_1[i+1]]
-	case i == 71:
-		return _ServerMessageType_name_2
-	case i == 73:
-		return _ServerMessageType_name_3
+		return _ServerMessageType_name_2[_ServerMessageType_index_2[i]:_ServerMessageType_index_2[i+1]]
+	case 71 <= i && i <= 73:
+		i -= 71
+		return _ServerMessageType_name_3[_ServerMessageType_index_3[i]:_ServerMessageType_index_3[i+1]]
 	case i == 75:
 		return _ServerMessageType_name_4
 	case i == 78:
@@ -68,11 +76,14 @@ func (i ServerMessageType) String() string {
 		return _ServerMessageType_name_6[_ServerMessageType_index_6[i]:_ServerMessageType_index_6[i+1]]
 	case i == 90:
 		return _ServerMessageType_name_7
+	case 99 <= i && i <= 100:
+		i -= 99
+		return _ServerMessageType_name_8[_ServerMessageType_index_8[i]:_ServerMessageType_index_8[i+1]]
 	case i == 110:
-		return _ServerMessageType_name_8
+		return _ServerMessageType_name_9
 	case 115 <= i && i <= 116:
 		i -= 115
-		return _ServerMessageType_name_9[_ServerMessageType_index_9[i]:_ServerMessageType_index_9[i+1]]
+		return _ServerMessageType_name_10[_ServerMessageType_index_10[i]:_ServerMessageType_index_10[i+1]]
 	default:
 		return "ServerMessageType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
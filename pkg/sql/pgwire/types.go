@@ -30,7 +30,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
-	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -58,6 +57,8 @@ type pgType struct {
 }
 
 func pgTypeForParserType(t *types.T) pgType {
+	// Like Postgres, describe columns of a domain type with the base type.
+	t = t.DomainBaseType()
 	size := -1
 	if s, variable := tree.DatumTypeSize(t); !variable {
 		size = int(s)
@@ -290,7 +291,7 @@ func (b *writeBuffer) writeTextColumnarElement(
 ) {
 	oldDCC := b.textFormatter.SetDataConversionConfig(conv)
 	defer b.textFormatter.SetDataConversionConfig(oldDCC)
-	typ := vecs.Vecs[vecIdx].Type()
+	typ := vecs.Vecs[vecIdx].Type().DomainBaseType()
 	if log.V(2) {
 		log.Infof(ctx, "pgwire writing TEXT columnar element of type: %s", typ)
 	}
@@ -716,23 +717,14 @@ func writeBinaryDatumNotNull(
 		b.write(v.EWKB())
 
 	case *tree.DArray:
-		if v.ParamTyp.Family() == types.ArrayFamily {
-			b.setError(unimplemented.NewWithIssueDetail(32552,
-				"binenc", "unsupported binary serialization of multidimensional arrays"))
-			return
-		}
-
 		initialLen := b.Len()
 
 		// Reserve bytes for writing length later.
 		b.putInt32(int32(0))
 
-		// Put the number of dimensions. We currently support 1d arrays only.
-		var ndims int32 = 1
-		if v.Len() == 0 {
-			ndims = 0
-		}
-		b.putInt32(ndims)
+		// Put the number of dimensions. Empty arrays have no dimensions.
+		dims := v.Dimensions()
+		b.putInt32(int32(len(dims)))
 		hasNulls := 0
 		if v.HasNulls {
 			hasNulls = 1
@@ -740,13 +732,12 @@ func writeBinaryDatumNotNull(
 		oid := v.ParamTyp.Oid()
 		b.putInt32(int32(hasNulls))
 		b.putInt32(int32(oid))
-		if v.Len() > 0 {
-			b.putInt32(int32(v.Len()))
-			// Lower bound, we only support a lower bound of 1.
-			b.putInt32(1)
-			for _, elem := range v.Array {
-				b.writeBinaryDatum(ctx, elem, sessionLoc, v.ParamTyp)
-			}
+		for _, dim := range dims {
+			b.putInt32(int32(dim.Length))
+			b.putInt32(int32(dim.LowerBound))
+		}
+		for _, elem := range v.Array {
+			b.writeBinaryDatum(ctx, elem, sessionLoc, v.ParamTyp)
 		}
 
 		lengthToWrite := b.Len() - (initialLen + 4)
@@ -769,7 +760,7 @@ func writeBinaryDatumNotNull(
 func (b *writeBuffer) writeBinaryColumnarElement(
 	ctx context.Context, vecs *coldata.TypedVecs, vecIdx int, rowIdx int, sessionLoc *time.Location,
 ) {
-	typ := vecs.Vecs[vecIdx].Type()
+	typ := vecs.Vecs[vecIdx].Type().DomainBaseType()
 	if log.V(2) {
 		log.Infof(ctx, "pgwire writing BINARY columnar element of type: %s", typ)
 	}
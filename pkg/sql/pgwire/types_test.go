@@ -151,6 +151,47 @@ func TestIntArrayRoundTrip(t *testing.T) {
 	}
 }
 
+func TestMultidimensionalArrayRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	defer evalCtx.Stop(context.Background())
+	defaultConv, defaultLoc := makeTestingConvCfg()
+
+	for _, s := range []string{
+		"{{1,2,3},{4,5,6}}",
+		"{{{1},{NULL}},{{3},{4}}}",
+		"[0:1]={1,2}",
+		"[-2:-1][3:4]={{1,2},{3,NULL}}",
+	} {
+		d, _, err := tree.ParseDArrayFromString(evalCtx, s, types.Int)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, code := range []pgwirebase.FormatCode{pgwirebase.FormatText, pgwirebase.FormatBinary} {
+			buf := newWriteBuffer(nil /* bytecount */)
+			buf.bytecount = metric.NewCounter(metric.Metadata{})
+			if code == pgwirebase.FormatText {
+				buf.writeTextDatum(context.Background(), d, defaultConv, defaultLoc, nil /* t */)
+			} else {
+				buf.writeBinaryDatum(context.Background(), d, defaultLoc, nil /* t */)
+			}
+			if buf.err != nil {
+				t.Fatal(buf.err)
+			}
+			b := buf.wrapped.Bytes()
+			got, err := pgwirebase.DecodeDatum(evalCtx, types.IntArray, code, b[4:])
+			if err != nil {
+				t.Fatalf("%s (%s): %v", s, code, err)
+			}
+			if got.Compare(evalCtx, d) != 0 {
+				t.Fatalf("%s (%s): expected %s, got %s", s, code, d, got)
+			}
+		}
+	}
+}
+
 func TestFloatConversion(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
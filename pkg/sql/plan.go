@@ -440,6 +440,15 @@ type cascadeMetadata struct {
 // return an error (for example, foreign key violation).
 type checkPlan struct {
 	plan planMaybePhysical
+
+	// deferrable is set if the check enforces a DEFERRABLE constraint. If the
+	// constraint is deferred, a violation detected by the check is not reported
+	// until the end of the transaction.
+	deferrable *exec.DeferrableConstraint
+
+	// keyVals returns the values of the constraint columns in a row produced by
+	// the check query. It is set if deferrable is set.
+	keyVals func(row tree.Datums) tree.Datums
 }
 
 // close calls Close on all plan trees.
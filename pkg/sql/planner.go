@@ -189,8 +189,17 @@ type planner struct {
 
 	sqlCursors sqlCursors
 
+	// sessionMonitor accounts for the memory of objects that outlive the
+	// current transaction, like WITH HOLD cursors. The transaction's objects are
+	// accounted for by extendedEvalCtx.Mon instead.
+	sessionMonitor *mon.BytesMonitor
+
 	createdSequences createdSequences
 
+	deferredConstraints deferredConstraints
+
+	notifications notifications
+
 	// avoidLeasedDescriptors, when true, instructs all code that
 	// accesses table/view descriptors to force reading the descriptors
 	// within the transaction. This is necessary to read descriptors
@@ -370,6 +379,7 @@ func newInternalPlanner(
 	}
 	p.semaCtx.SearchPath = sd.SearchPath
 	p.semaCtx.TypeResolver = p
+	p.semaCtx.FunctionResolver = p
 	p.semaCtx.DateStyle = sd.GetDateStyle()
 	p.semaCtx.IntervalStyle = sd.GetIntervalStyle()
 
@@ -419,6 +429,8 @@ func newInternalPlanner(
 	p.queryCacheSession.Init()
 	p.optPlanningCtx.init(p)
 	p.createdSequences = emptyCreatedSequences{}
+	p.deferredConstraints = emptyDeferredConstraints{}
+	p.notifications = emptyNotifications{}
 
 	return p, func() {
 		// Note that we capture ctx here. This is only valid as long as we create
@@ -43,6 +43,7 @@ const (
 	ZONECONFIG Kind = 10
 	CONNECT    Kind = 11
 	RULE       Kind = 12
+	EXECUTE    Kind = 13
 )
 
 // Privilege represents a privilege parsed from an Access Privilege Inquiry
@@ -70,17 +71,20 @@ const (
 	Table ObjectType = "table"
 	// Type represents a type object.
 	Type ObjectType = "type"
+	// Function represents a function object.
+	Function ObjectType = "function"
 )
 
 // Predefined sets of privileges.
 var (
-	AllPrivileges    = List{ALL, CONNECT, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG}
-	ReadData         = List{GRANT, SELECT}
-	ReadWriteData    = List{GRANT, SELECT, INSERT, DELETE, UPDATE}
-	DBPrivileges     = List{ALL, CONNECT, CREATE, DROP, GRANT, ZONECONFIG}
-	TablePrivileges  = List{ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, ZONECONFIG}
-	SchemaPrivileges = List{ALL, GRANT, CREATE, USAGE}
-	TypePrivileges   = List{ALL, GRANT, USAGE}
+	AllPrivileges      = List{ALL, CONNECT, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG, EXECUTE}
+	ReadData           = List{GRANT, SELECT}
+	ReadWriteData      = List{GRANT, SELECT, INSERT, DELETE, UPDATE}
+	DBPrivileges       = List{ALL, CONNECT, CREATE, DROP, GRANT, ZONECONFIG}
+	TablePrivileges    = List{ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, ZONECONFIG}
+	SchemaPrivileges   = List{ALL, GRANT, CREATE, USAGE}
+	TypePrivileges     = List{ALL, GRANT, USAGE}
+	FunctionPrivileges = List{ALL, GRANT, EXECUTE}
 )
 
 // Mask returns the bitmask for a given privilege.
@@ -95,7 +99,7 @@ func (k Kind) IsSetIn(bits uint32) bool {
 
 // ByValue is just an array of privilege kinds sorted by value.
 var ByValue = [...]Kind{
-	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG, CONNECT, RULE,
+	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG, CONNECT, RULE, EXECUTE,
 }
 
 // ByName is a map of string -> kind value.
@@ -112,6 +116,7 @@ var ByName = map[string]Kind{
 	"ZONECONFIG": ZONECONFIG,
 	"USAGE":      USAGE,
 	"RULE":       RULE,
+	"EXECUTE":    EXECUTE,
 }
 
 // List is a list of privileges.
@@ -268,6 +273,8 @@ func GetValidPrivilegesForObject(objectType ObjectType) List {
 		return DBPrivileges
 	case Type:
 		return TypePrivileges
+	case Function:
+		return FunctionPrivileges
 	case Any:
 		return AllPrivileges
 	default:
@@ -284,10 +291,11 @@ var privToACL = map[Kind]string{
 	UPDATE:  "w",
 	USAGE:   "U",
 	CONNECT: "c",
+	EXECUTE: "X",
 }
 
-// orderedPrivs is the list of privileges sorted in alphanumeric order based on the ACL character -> CUacdrw
-var orderedPrivs = List{CREATE, USAGE, INSERT, CONNECT, DELETE, SELECT, UPDATE}
+// orderedPrivs is the list of privileges sorted in alphanumeric order based on the ACL character -> CUXacdrw
+var orderedPrivs = List{CREATE, USAGE, EXECUTE, INSERT, CONNECT, DELETE, SELECT, UPDATE}
 
 // ListToACL converts a list of privileges to a list of Postgres
 // ACL items.
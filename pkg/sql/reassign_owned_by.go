@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/errors"
 )
 
@@ -250,6 +251,9 @@ func (n *reassignOwnedByNode) reassignTypeOwner(
 	if err != nil {
 		return err
 	}
+	if typDesc.GetKind() == descpb.TypeDescriptor_DOMAIN {
+		return n.reassignDomainOwner(mutableTypDesc.(*typedesc.Mutable), params)
+	}
 	arrayDesc, err := params.p.Descriptors().GetMutableTypeVersionByID(
 		params.ctx, params.p.txn, typDesc.GetArrayTypeID())
 	if err != nil {
@@ -287,6 +291,34 @@ func (n *reassignOwnedByNode) reassignTypeOwner(
 	return nil
 }
 
+// reassignDomainOwner changes the owner of a domain. Unlike other user
+// defined types, domains do not have an implicit array type whose owner must
+// be changed along with them.
+func (n *reassignOwnedByNode) reassignDomainOwner(
+	typDesc *typedesc.Mutable, params runParams,
+) error {
+	typeName, err := params.p.getQualifiedTypeName(params.ctx, typDesc)
+	if err != nil {
+		return err
+	}
+	owner, err := n.n.NewRole.ToSQLUsername(params.p.SessionData(), security.UsernameValidation)
+	if err != nil {
+		return err
+	}
+	typDesc.GetPrivileges().SetOwner(owner)
+	if err := params.p.logEvent(params.ctx,
+		typDesc.GetID(),
+		&eventpb.AlterTypeOwner{
+			TypeName: typeName.FQString(),
+			Owner:    owner.Normalized(),
+		}); err != nil {
+		return err
+	}
+	return params.p.writeTypeSchemaChange(
+		params.ctx, typDesc, tree.AsStringWithFQNames(n.n, params.p.Ann()),
+	)
+}
+
 func (n *reassignOwnedByNode) Next(runParams) (bool, error) { return false, nil }
 func (n *reassignOwnedByNode) Values() tree.Datums          { return tree.Datums{} }
 func (n *reassignOwnedByNode) Close(context.Context)        {}
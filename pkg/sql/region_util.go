@@ -597,7 +597,7 @@ type applyZoneConfigForMultiRegionTableOption func(
 	zoneConfig zonepb.ZoneConfig,
 	regionConfig multiregion.RegionConfig,
 	table catalog.TableDescriptor,
-) (hasNewSubzones bool, newZoneConfig zonepb.ZoneConfig, err error)
+) (newZoneConfig zonepb.ZoneConfig, err error)
 
 // applyZoneConfigForMultiRegionTableOptionNewIndexes applies table zone configs
 // for a newly added index which requires partitioning of individual indexes.
@@ -608,12 +608,12 @@ func applyZoneConfigForMultiRegionTableOptionNewIndexes(
 		zoneConfig zonepb.ZoneConfig,
 		regionConfig multiregion.RegionConfig,
 		table catalog.TableDescriptor,
-	) (hasNewSubzones bool, newZoneConfig zonepb.ZoneConfig, err error) {
+	) (newZoneConfig zonepb.ZoneConfig, err error) {
 		for _, indexID := range indexIDs {
 			for _, region := range regionConfig.Regions() {
 				zc, err := zoneConfigForMultiRegionPartition(region, regionConfig)
 				if err != nil {
-					return false, zoneConfig, err
+					return zoneConfig, err
 				}
 				zoneConfig.SetSubzone(zonepb.Subzone{
 					IndexID:       uint32(indexID),
@@ -622,7 +622,7 @@ func applyZoneConfigForMultiRegionTableOptionNewIndexes(
 				})
 			}
 		}
-		return true, zoneConfig, nil
+		return zoneConfig, nil
 	}
 }
 
@@ -636,7 +636,7 @@ func dropZoneConfigsForMultiRegionIndexes(
 		zoneConfig zonepb.ZoneConfig,
 		regionConfig multiregion.RegionConfig,
 		table catalog.TableDescriptor,
-	) (hasNewSubzones bool, newZoneConfig zonepb.ZoneConfig, err error) {
+	) (newZoneConfig zonepb.ZoneConfig, err error) {
 		// Clear all multi-region fields of the subzones. If this leaves them
 		// empty, they will automatically be removed.
 		zoneConfig.ClearFieldsOfAllSubzones(zonepb.MultiRegionZoneConfigFields)
@@ -646,7 +646,7 @@ func dropZoneConfigsForMultiRegionIndexes(
 			zoneConfig.NumReplicas = nil
 			zoneConfig.SubzoneSpans = nil
 		}
-		return false, zoneConfig, nil
+		return zoneConfig, nil
 	}
 }
 
@@ -674,16 +674,16 @@ func applyZoneConfigForMultiRegionTableOptionTableNewConfig(
 		zc zonepb.ZoneConfig,
 		regionConfig multiregion.RegionConfig,
 		table catalog.TableDescriptor,
-	) (bool, zonepb.ZoneConfig, error) {
+	) (zonepb.ZoneConfig, error) {
 		localityZoneConfig, err := zoneConfigForMultiRegionTable(
 			newConfig,
 			regionConfig,
 		)
 		if err != nil {
-			return false, zonepb.ZoneConfig{}, err
+			return zonepb.ZoneConfig{}, err
 		}
 		zc.CopyFromZone(localityZoneConfig, zonepb.MultiRegionZoneConfigFields)
-		return false, zc, nil
+		return zc, nil
 	}
 }
 
@@ -694,14 +694,14 @@ var ApplyZoneConfigForMultiRegionTableOptionTableAndIndexes = func(
 	zc zonepb.ZoneConfig,
 	regionConfig multiregion.RegionConfig,
 	table catalog.TableDescriptor,
-) (bool, zonepb.ZoneConfig, error) {
+) (zonepb.ZoneConfig, error) {
 	localityConfig := *table.GetLocalityConfig()
 	localityZoneConfig, err := zoneConfigForMultiRegionTable(
 		localityConfig,
 		regionConfig,
 	)
 	if err != nil {
-		return false, zonepb.ZoneConfig{}, err
+		return zonepb.ZoneConfig{}, err
 	}
 
 	// Wipe out the subzone multi-region fields before we copy over the
@@ -716,12 +716,11 @@ var ApplyZoneConfigForMultiRegionTableOptionTableAndIndexes = func(
 
 	zc.CopyFromZone(localityZoneConfig, zonepb.MultiRegionZoneConfigFields)
 
-	hasNewSubzones := table.IsLocalityRegionalByRow()
-	if hasNewSubzones {
+	if table.IsLocalityRegionalByRow() {
 		for _, region := range regionConfig.Regions() {
 			subzoneConfig, err := zoneConfigForMultiRegionPartition(region, regionConfig)
 			if err != nil {
-				return false, zc, err
+				return zc, err
 			}
 			for _, idx := range table.NonDropIndexes() {
 				zc.SetSubzone(zonepb.Subzone{
@@ -732,7 +731,7 @@ var ApplyZoneConfigForMultiRegionTableOptionTableAndIndexes = func(
 			}
 		}
 	}
-	return hasNewSubzones, zc, nil
+	return zc, nil
 }
 
 // applyZoneConfigForMultiRegionTableOptionRemoveGlobalZoneConfig signals
@@ -741,9 +740,9 @@ var applyZoneConfigForMultiRegionTableOptionRemoveGlobalZoneConfig = func(
 	zc zonepb.ZoneConfig,
 	regionConfig multiregion.RegionConfig,
 	table catalog.TableDescriptor,
-) (bool, zonepb.ZoneConfig, error) {
+) (zonepb.ZoneConfig, error) {
 	zc.CopyFromZone(*zonepb.NewZoneConfig(), zonepb.MultiRegionZoneConfigFields)
-	return false, zc, nil
+	return zc, nil
 }
 
 func prepareZoneConfigForMultiRegionTable(
@@ -764,9 +763,8 @@ func prepareZoneConfigForMultiRegionTable(
 		newZoneConfig = *currentZoneConfig
 	}
 
-	var hasNewSubzones bool
 	for _, opt := range opts {
-		newHasNewSubzones, modifiedNewZoneConfig, err := opt(
+		modifiedNewZoneConfig, err := opt(
 			newZoneConfig,
 			regionConfig,
 			table,
@@ -774,14 +772,11 @@ func prepareZoneConfigForMultiRegionTable(
 		if err != nil {
 			return nil, err
 		}
-		hasNewSubzones = newHasNewSubzones || hasNewSubzones
 		newZoneConfig = modifiedNewZoneConfig
 	}
 
 	// Mark the NumReplicas as 0 if we have subzones but no other features
 	// in the zone config. This signifies a placeholder.
-	// Note we do not use hasNewSubzones here as there may be existing subzones
-	// on the zone config which may still be a placeholder.
 	if isPlaceholderZoneConfigForMultiRegion(newZoneConfig) {
 		newZoneConfig.NumReplicas = proto.Int32(0)
 	}
@@ -814,7 +809,7 @@ func prepareZoneConfigForMultiRegionTable(
 		)
 	}
 	return prepareZoneConfigWrites(
-		ctx, execCfg, tableID, table, &newZoneConfig, hasNewSubzones,
+		ctx, execCfg, tableID, table, &newZoneConfig,
 	)
 }
 
@@ -911,7 +906,6 @@ func applyZoneConfigForMultiRegionDatabase(
 		nil, /* table */
 		&newZoneConfig,
 		execConfig,
-		false, /* hasNewSubzones */
 	); err != nil {
 		return err
 	}
@@ -1620,7 +1614,7 @@ func (v *zoneConfigForMultiRegionValidatorExistingMultiRegionObject) getExpected
 func (v *zoneConfigForMultiRegionValidatorExistingMultiRegionObject) getExpectedTableZoneConfig(
 	desc catalog.TableDescriptor,
 ) (zonepb.ZoneConfig, error) {
-	_, expectedZoneConfig, err := ApplyZoneConfigForMultiRegionTableOptionTableAndIndexes(
+	expectedZoneConfig, err := ApplyZoneConfigForMultiRegionTableOptionTableAndIndexes(
 		*zonepb.NewZoneConfig(),
 		v.regionConfig,
 		desc,
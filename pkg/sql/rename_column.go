@@ -113,6 +113,9 @@ func (p *planner) findColumnToRename(
 			)
 		}
 	}
+	if err := checkColumnNotReferencedByTriggers(tableDesc, oldName, "rename"); err != nil {
+		return nil, err
+	}
 	if oldName == newName {
 		// Noop.
 		return nil, nil
@@ -158,6 +158,13 @@ func (n *renameDatabaseNode) startExec(params runParams) error {
 			}
 
 			if err := tbDesc.ForeachDependedOnBy(func(dependedOn *descpb.TableDescriptor_Reference) error {
+				// The bodies of functions are stored as they were written, so they
+				// do not refer to the database by name unless the user did so.
+				if fnDesc, err := p.getDependentFunction(ctx, dependedOn.ID); err != nil {
+					return err
+				} else if fnDesc != nil {
+					return nil
+				}
 				dependentDesc, err := p.Descriptors().Direct().MustGetTableDescByID(ctx, p.txn, dependedOn.ID)
 				if err != nil {
 					return err
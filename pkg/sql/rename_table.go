@@ -278,6 +278,11 @@ func (n *renameTableNode) Close(context.Context)        {}
 func (p *planner) dependentViewError(
 	ctx context.Context, typeName, objName string, parentID, viewID descpb.ID, op string,
 ) error {
+	if fnDesc, err := p.getDependentFunction(ctx, viewID); err != nil {
+		return err
+	} else if fnDesc != nil {
+		return p.dependentFunctionError(ctx, typeName, objName, fnDesc, op)
+	}
 	viewDesc, err := p.Descriptors().Direct().MustGetTableDescByID(ctx, p.txn, viewID)
 	if err != nil {
 		return err
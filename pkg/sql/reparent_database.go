@@ -196,6 +196,16 @@ func (n *reparentDatabaseNode) startExec(params runParams) error {
 					return errors.Wrapf(err, errStr, n.db.Name, tbl.Name)
 				}
 				for _, ref := range tbl.GetDependedOnBy() {
+					if fnDesc, err := p.getDependentFunction(ctx, ref.ID); err != nil {
+						return errors.Wrapf(err, errStr, n.db.Name, tblName.FQString())
+					} else if fnDesc != nil {
+						fnName, err := p.getQualifiedFunctionName(ctx, fnDesc)
+						if err != nil {
+							return errors.Wrapf(err, errStr, n.db.Name, fnDesc.GetName())
+						}
+						names = append(names, fnName.FQString())
+						continue
+					}
 					dep, err := p.Descriptors().GetMutableTableVersionByID(ctx, ref.ID, p.txn)
 					if err != nil {
 						return errors.Wrapf(err, errStr, n.db.Name, tblName.FQString())
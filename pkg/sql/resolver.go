@@ -591,6 +591,21 @@ func getDescriptorsFromTargetListForPrivilegeChange(
 		return descs, nil
 	}
 
+	if targets.Functions != nil {
+		if len(targets.Functions) == 0 {
+			return nil, errNoFunction
+		}
+		descs := make([]catalog.Descriptor, 0, len(targets.Functions))
+		for i := range targets.Functions {
+			descriptor, err := p.resolveMutableFunction(ctx, &targets.Functions[i], required)
+			if err != nil {
+				return nil, err
+			}
+			descs = append(descs, descriptor)
+		}
+		return descs, nil
+	}
+
 	if targets.Schemas != nil {
 		if len(targets.Schemas) == 0 {
 			return nil, errNoSchema
@@ -52,8 +52,7 @@ func GetWaitPolicy(lockWaitPolicy descpb.ScanLockingWaitPolicy) lock.WaitPolicy
 		return lock.WaitPolicy_Block
 
 	case descpb.ScanLockingWaitPolicy_SKIP:
-		// Should not get here. Query should be rejected during planning.
-		panic(errors.AssertionFailedf("unsupported wait policy %s", lockWaitPolicy))
+		return lock.WaitPolicy_SkipLocked
 
 	case descpb.ScanLockingWaitPolicy_ERROR:
 		return lock.WaitPolicy_Error
@@ -26,9 +26,28 @@ import (
 // differently, because the standard NULL encoding conflicts with the
 // terminator byte. This NULL value is chosen to be larger than the
 // terminator but less than all existing encoded values.
+//
+// Arrays that are multidimensional or have a non-default lower bound encode
+// their dimensions right after the marker:
+// [arrayMarker, dimsMarker, enc(ndims), enc(len1), enc(lb1), ..., elements,
+// terminator].
+// The dimensions marker sorts after all encoded elements and the terminator,
+// so these arrays sort after all one-dimensional arrays with default bounds,
+// matching the order used by tree.DArray.Compare.
 func encodeArrayKey(b []byte, array *tree.DArray, dir encoding.Direction) ([]byte, error) {
+	if err := array.CheckDimsEncodable(); err != nil {
+		return nil, err
+	}
 	var err error
 	b = encoding.EncodeArrayKeyMarker(b, dir)
+	if array.Dims != nil {
+		b = encoding.EncodeArrayKeyDimensionsMarker(b, dir)
+		b = encodeArrayKeyDimension(b, len(array.Dims), dir)
+		for _, dim := range array.Dims {
+			b = encodeArrayKeyDimension(b, dim.Length, dir)
+			b = encodeArrayKeyDimension(b, dim.LowerBound, dir)
+		}
+	}
 	for _, elem := range array.Array {
 		if elem == tree.DNull {
 			b = encoding.EncodeNullWithinArrayKey(b, dir)
@@ -57,6 +76,26 @@ func decodeArrayKey(
 		return nil, nil, err
 	}
 
+	var dims []tree.ArrayDim
+	if len(buf) > 0 && encoding.IsNextByteArrayKeyDimensionsMarker(buf, dir) {
+		var ndims int
+		if buf, ndims, err = decodeArrayKeyDimension(buf[1:], dir); err != nil {
+			return nil, nil, err
+		}
+		if ndims < 1 || ndims > tree.MaxArrayDims {
+			return nil, nil, errors.AssertionFailedf("invalid number of array dimensions: %d", ndims)
+		}
+		dims = make([]tree.ArrayDim, ndims)
+		for i := range dims {
+			if buf, dims[i].Length, err = decodeArrayKeyDimension(buf, dir); err != nil {
+				return nil, nil, err
+			}
+			if buf, dims[i].LowerBound, err = decodeArrayKeyDimension(buf, dir); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
 	for {
 		if len(buf) == 0 {
 			return nil, nil, errors.AssertionFailedf("invalid array encoding (unterminated)")
@@ -79,5 +118,29 @@ func decodeArrayKey(
 			return nil, nil, err
 		}
 	}
+	if err := result.SetDims(dims); err != nil {
+		return nil, nil, err
+	}
 	return result, buf, nil
 }
+
+// encodeArrayKeyDimension encodes a single value describing the dimensions of
+// an array key.
+func encodeArrayKeyDimension(b []byte, v int, dir encoding.Direction) []byte {
+	if dir == encoding.Ascending {
+		return encoding.EncodeVarintAscending(b, int64(v))
+	}
+	return encoding.EncodeVarintDescending(b, int64(v))
+}
+
+// decodeArrayKeyDimension decodes a value encoded by encodeArrayKeyDimension.
+func decodeArrayKeyDimension(b []byte, dir encoding.Direction) ([]byte, int, error) {
+	var v int64
+	var err error
+	if dir == encoding.Ascending {
+		b, v, err = encoding.DecodeVarintAscending(b)
+	} else {
+		b, v, err = encoding.DecodeVarintDescending(b)
+	}
+	return b, int(v), err
+}
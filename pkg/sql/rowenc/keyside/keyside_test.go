@@ -246,3 +246,126 @@ func hasKeyEncoding(typ *types.T) bool {
 	}
 	return true
 }
+
+// TestEncodeDecodeMultidimensionalArray checks that arrays with multiple
+// dimensions or non-default lower bounds round-trip, and that their encoding
+// preserves the order of tree.DArray.Compare.
+func TestEncodeDecodeMultidimensionalArray(t *testing.T) {
+	ctx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	var datums []tree.Datum
+	for _, s := range []string{
+		"{}",
+		"{1,2,3,4}",
+		"{5}",
+		"{NULL}",
+		"[0:3]={1,2,3,4}",
+		"[-1:0]={1,2}",
+		"{{1,2},{3,4}}",
+		"{{1},{2}}",
+		"{{1,NULL},{3,4}}",
+		"[0:1][1:2]={{1,2},{3,4}}",
+		"{{{1,2}},{{3,4}}}",
+	} {
+		d, _, err := tree.ParseDArrayFromString(ctx, s, types.Int)
+		require.NoError(t, err)
+		datums = append(datums, d)
+	}
+	a := &tree.DatumAlloc{}
+	for _, dir := range []encoding.Direction{encoding.Ascending, encoding.Descending} {
+		for _, d1 := range datums {
+			b1, err := keyside.Encode(nil, d1, dir)
+			require.NoError(t, err)
+			decoded, rest, err := keyside.Decode(a, d1.ResolvedType(), b1, dir)
+			require.NoError(t, err)
+			require.Empty(t, rest)
+			require.Zero(t, decoded.Compare(ctx, d1), "%s decoded as %s", d1, decoded)
+			rest, err = keyside.Skip(b1)
+			require.NoError(t, err)
+			require.Empty(t, rest)
+
+			for _, d2 := range datums {
+				b2, err := keyside.Encode(nil, d2, dir)
+				require.NoError(t, err)
+				expected := d1.Compare(ctx, d2)
+				if dir == encoding.Descending {
+					expected = -expected
+				}
+				require.Equal(t, expected, bytes.Compare(b1, b2), "comparing %s and %s", d1, d2)
+			}
+		}
+	}
+}
+
+// TestDecodeArrayKeyWithoutDimensions checks that the array keys written
+// before arrays could have multiple dimensions or a non-default lower bound
+// still decode to one-dimensional arrays with the default lower bound, and
+// that the key encoding of these arrays is unchanged.
+func TestDecodeArrayKeyWithoutDimensions(t *testing.T) {
+	ctx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	a := &tree.DatumAlloc{}
+	for _, tc := range []struct {
+		dir      encoding.Direction
+		key      []byte
+		expected string
+	}{
+		{encoding.Ascending, []byte{63, 0}, "{}"},
+		{encoding.Ascending, []byte{63, 137, 138, 0}, "{1,2}"},
+		{encoding.Ascending, []byte{63, 137, 1, 0}, "{1,NULL}"},
+		{encoding.Descending, []byte{64, 135, 254, 135, 253, 255}, "{1,2}"},
+		{encoding.Descending, []byte{64, 254, 135, 253, 255}, "{NULL,2}"},
+	} {
+		expected, _, err := tree.ParseDArrayFromString(ctx, tc.expected, types.Int)
+		require.NoError(t, err)
+		// The key may be followed by another column whose encoding starts with
+		// the same byte as the dimensions marker.
+		key := append(append([]byte(nil), tc.key...), 0xFF, 0x00)
+		d, rest, err := keyside.Decode(a, types.IntArray, key, tc.dir)
+		require.NoError(t, err)
+		require.Equal(t, []byte{0xFF, 0x00}, rest)
+		require.Nil(t, d.(*tree.DArray).Dims)
+		require.Zero(t, d.Compare(ctx, expected), "%v decoded as %s", tc.key, d)
+
+		enc, err := keyside.Encode(nil, expected, tc.dir)
+		require.NoError(t, err)
+		require.Equal(t, tc.key, enc)
+	}
+}
+
+// TestEncodeArrayKeyDimsDisabled checks that arrays with multiple dimensions
+// or a non-default lower bound, including arrays nested in tuples, cannot be
+// key encoded while their encodings are disabled.
+func TestEncodeArrayKeyDimsDisabled(t *testing.T) {
+	ctx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	type testCase struct {
+		datum     tree.Datum
+		encodable bool
+	}
+	var testCases []testCase
+	for _, s := range []string{"{{1,2},{3,4}}", "[0:1]={1,2}", "{1,2}"} {
+		d, _, err := tree.ParseDArrayFromString(ctx, s, types.Int)
+		require.NoError(t, err)
+		encodable := d.(*tree.DArray).Dims == nil
+		testCases = append(testCases,
+			testCase{d, encodable},
+			testCase{tree.NewDTuple(types.MakeTuple([]*types.T{types.IntArray}), d), encodable},
+		)
+	}
+
+	tree.SetArrayDimsEncodingEnabled(false)
+	defer tree.SetArrayDimsEncodingEnabled(true)
+	for _, tc := range testCases {
+		_, err := keyside.Encode(nil, tc.datum, encoding.Ascending)
+		if tc.encodable {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err, "%s", tc.datum)
+			require.Regexp(t, "cannot be encoded until upgrade to version MultidimensionalArrays is finalized", err)
+		}
+	}
+
+	tree.SetArrayDimsEncodingEnabled(true)
+	for _, tc := range testCases {
+		_, err := keyside.Encode(nil, tc.datum, encoding.Ascending)
+		require.NoError(t, err)
+	}
+}
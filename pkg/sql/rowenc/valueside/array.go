@@ -23,6 +23,9 @@ func encodeArray(d *tree.DArray, scratch []byte) ([]byte, error) {
 	if err := d.Validate(); err != nil {
 		return scratch, err
 	}
+	if err := d.CheckDimsEncodable(); err != nil {
+		return scratch, err
+	}
 	scratch = scratch[0:0]
 	elementType, err := DatumTypeToArrayElementEncodingType(d.ParamTyp)
 
@@ -30,14 +33,17 @@ func encodeArray(d *tree.DArray, scratch []byte) ([]byte, error) {
 		return nil, err
 	}
 	header := arrayHeader{
-		hasNulls: d.HasNulls,
-		// TODO(justin): support multiple dimensions.
+		hasNulls:      d.HasNulls,
 		numDimensions: 1,
 		elementType:   elementType,
 		length:        uint64(d.Len()),
+		dims:          d.Dims,
 		// We don't encode the NULL bitmap in this function because we do it in lockstep with the
 		// main data.
 	}
+	if d.Dims != nil {
+		header.numDimensions = len(d.Dims)
+	}
 	scratch, err = encodeArrayHeader(header, scratch)
 	if err != nil {
 		return nil, err
@@ -76,6 +82,9 @@ func decodeArray(a *tree.DatumAlloc, arrayType *types.T, b []byte) (tree.Datum,
 	if err = result.MaybeSetCustomOid(arrayType); err != nil {
 		return nil, b, err
 	}
+	if err = result.SetDims(header.dims); err != nil {
+		return nil, b, err
+	}
 	var val tree.Datum
 	for i := uint64(0); i < header.length; i++ {
 		if header.isNull(i) {
@@ -107,6 +116,10 @@ type arrayHeader struct {
 	elementType encoding.Type
 	// length is the total number of elements encoded.
 	length uint64
+	// dims is the length and lower bound of each dimension. It is only set
+	// for arrays that are multidimensional or have a non-default lower bound;
+	// see tree.DArray.Dims.
+	dims []tree.ArrayDim
 	// nullBitmap is a compact representation of which array indexes
 	// have NULL values.
 	nullBitmap []byte
@@ -139,7 +152,10 @@ func makeBitVec(src []byte, length int) (b, bitVec []byte) {
 	return src[nullBitmapNumBytes:], src[:nullBitmapNumBytes]
 }
 
-const hasNullFlag = 1 << 4
+const (
+	hasNullFlag       = 1 << 4
+	hasDimensionsFlag = 1 << 5
+)
 
 // encodeArrayHeader is used by encodeArray to encode the header
 // at the beginning of the value encoding.
@@ -147,14 +163,22 @@ func encodeArrayHeader(h arrayHeader, buf []byte) ([]byte, error) {
 	// The header byte we append here is formatted as follows:
 	// * The low 4 bits encode the number of dimensions in the array.
 	// * The high 4 bits are flags, with the lowest representing whether the array
-	//   contains NULLs, and the rest reserved.
+	//   contains NULLs, the next one representing whether the length and lower
+	//   bound of each dimension follow the total length, and the rest reserved.
 	headerByte := h.numDimensions
 	if h.hasNulls {
 		headerByte = headerByte | hasNullFlag
 	}
+	if h.dims != nil {
+		headerByte = headerByte | hasDimensionsFlag
+	}
 	buf = append(buf, byte(headerByte))
 	buf = encoding.EncodeValueTag(buf, encoding.NoColumnID, h.elementType)
 	buf = encoding.EncodeNonsortingUvarint(buf, h.length)
+	for _, dim := range h.dims {
+		buf = encoding.EncodeNonsortingUvarint(buf, uint64(dim.Length))
+		buf = encoding.EncodeNonsortingStdlibVarint(buf, int64(dim.LowerBound))
+	}
 	return buf, nil
 }
 
@@ -165,6 +189,8 @@ func decodeArrayHeader(b []byte) (arrayHeader, []byte, error) {
 		return arrayHeader{}, b, errors.Errorf("buffer too small")
 	}
 	hasNulls := b[0]&hasNullFlag != 0
+	hasDimensions := b[0]&hasDimensionsFlag != 0
+	numDimensions := int(b[0] & 0x0f)
 	b = b[1:]
 	_, dataOffset, _, encType, err := encoding.DecodeValueTag(b)
 	if err != nil {
@@ -175,16 +201,36 @@ func decodeArrayHeader(b []byte) (arrayHeader, []byte, error) {
 	if err != nil {
 		return arrayHeader{}, b, err
 	}
+	var dims []tree.ArrayDim
+	if hasDimensions {
+		dims = make([]tree.ArrayDim, numDimensions)
+		for i := range dims {
+			var dimLength uint64
+			var lowerBound int64
+			b, _, dimLength, err = encoding.DecodeNonsortingUvarint(b)
+			if err != nil {
+				return arrayHeader{}, b, err
+			}
+			b, _, lowerBound, err = encoding.DecodeNonsortingStdlibVarint(b)
+			if err != nil {
+				return arrayHeader{}, b, err
+			}
+			dims[i] = tree.ArrayDim{Length: int(dimLength), LowerBound: int(lowerBound)}
+		}
+	} else {
+		// Arrays without encoded dimensions always have a single dimension.
+		numDimensions = 1
+	}
 	nullBitmap := []byte(nil)
 	if hasNulls {
 		b, nullBitmap = makeBitVec(b, int(length))
 	}
 	return arrayHeader{
-		hasNulls: hasNulls,
-		// TODO(justin): support multiple dimensions.
-		numDimensions: 1,
+		hasNulls:      hasNulls,
+		numDimensions: numDimensions,
 		elementType:   encType,
 		length:        length,
+		dims:          dims,
 		nullBitmap:    nullBitmap,
 	}, b, nil
 }
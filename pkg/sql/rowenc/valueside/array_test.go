@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 )
 
 type arrayEncodingTest struct {
@@ -108,6 +109,24 @@ func TestArrayEncoding(t *testing.T) {
 				HasNulls: true,
 			},
 			[]byte{17, 3, 9, 6, 1, 2, 4, 6, 8, 10, 12},
+		}, {
+			"two-dimensional int array",
+			tree.DArray{
+				ParamTyp: types.Int,
+				Array: tree.Datums{
+					tree.NewDInt(1), tree.NewDInt(2), tree.NewDInt(3), tree.NewDInt(4),
+				},
+				Dims: []tree.ArrayDim{{Length: 2, LowerBound: 1}, {Length: 2, LowerBound: 1}},
+			},
+			[]byte{34, 3, 4, 2, 2, 2, 2, 2, 4, 6, 8},
+		}, {
+			"int array with a non-default lower bound",
+			tree.DArray{
+				ParamTyp: types.Int,
+				Array:    tree.Datums{tree.NewDInt(1), tree.NewDInt(2)},
+				Dims:     []tree.ArrayDim{{Length: 2, LowerBound: 0}},
+			},
+			[]byte{33, 3, 2, 2, 0, 2, 4},
 		},
 	}
 
@@ -150,3 +169,93 @@ func BenchmarkArrayEncoding(b *testing.B) {
 		_, _ = encodeArray(&ary, nil)
 	}
 }
+
+// TestDecodeArrayWithoutDimensions checks that the encodings written before
+// arrays could have multiple dimensions or a non-default lower bound, which do
+// not have hasDimensionsFlag set, still decode to one-dimensional arrays with
+// the default lower bound.
+func TestDecodeArrayWithoutDimensions(t *testing.T) {
+	evalContext := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	for _, tc := range []struct {
+		encoding []byte
+		expected string
+	}{
+		{[]byte{1, 3, 0}, "{}"},
+		{[]byte{1, 3, 3, 2, 4, 6}, "{1,2,3}"},
+		{[]byte{17, 3, 3, 2, 2, 6}, "{1,NULL,3}"},
+	} {
+		d, rest, err := decodeArray(&tree.DatumAlloc{}, types.IntArray, tc.encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rest) != 0 {
+			t.Fatalf("expected %v to be fully consumed, got %v left", tc.encoding, rest)
+		}
+		a := d.(*tree.DArray)
+		if a.Dims != nil {
+			t.Fatalf("expected %v to decode without dimensions, got %v", tc.encoding, a.Dims)
+		}
+		if s := a.String(); s != tc.expected {
+			t.Fatalf("expected %v to decode to %s, got %s", tc.encoding, tc.expected, s)
+		}
+		// Arrays without dimensions are still encoded in the old format, so
+		// that they can be decoded by nodes running an older version.
+		enc, err := encodeArray(a, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(enc, tc.encoding) {
+			t.Fatalf("expected %s to encode to %v, got %v", a, tc.encoding, enc)
+		}
+		expected, _, err := tree.ParseDArrayFromString(evalContext, tc.expected, types.Int)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Compare(evalContext, expected) != 0 {
+			t.Fatalf("expected %v to decode to %s, got %s", tc.encoding, expected, d)
+		}
+	}
+}
+
+// TestEncodeArrayDimsDisabled checks that arrays with multiple dimensions or a
+// non-default lower bound, including arrays nested in tuples, cannot be value
+// encoded while their encodings are disabled.
+func TestEncodeArrayDimsDisabled(t *testing.T) {
+	evalContext := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	type testCase struct {
+		datum     tree.Datum
+		encodable bool
+	}
+	var testCases []testCase
+	for _, s := range []string{"{{1,2},{3,4}}", "[0:1]={1,2}", "{1,2}"} {
+		d, _, err := tree.ParseDArrayFromString(evalContext, s, types.Int)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encodable := d.(*tree.DArray).Dims == nil
+		testCases = append(testCases,
+			testCase{d, encodable},
+			testCase{tree.NewDTuple(types.MakeTuple([]*types.T{types.IntArray}), d), encodable},
+		)
+	}
+
+	tree.SetArrayDimsEncodingEnabled(false)
+	defer tree.SetArrayDimsEncodingEnabled(true)
+	for _, tc := range testCases {
+		_, err := Encode(nil, NoColumnID, tc.datum, nil /* scratch */)
+		if tc.encodable {
+			if err != nil {
+				t.Fatalf("unexpected error encoding %s: %v", tc.datum, err)
+			}
+		} else if !testutils.IsError(err, "cannot be encoded until upgrade to version MultidimensionalArrays is finalized") {
+			t.Fatalf("expected %s not to be encodable, got %v", tc.datum, err)
+		}
+	}
+
+	tree.SetArrayDimsEncodingEnabled(true)
+	for _, tc := range testCases {
+		if _, err := Encode(nil, NoColumnID, tc.datum, nil /* scratch */); err != nil {
+			t.Fatalf("unexpected error encoding %s: %v", tc.datum, err)
+		}
+	}
+}
@@ -50,7 +50,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
-	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
@@ -771,7 +770,7 @@ func (sc *SchemaChanger) exec(ctx context.Context) error {
 		}
 		// Some descriptors should be deleted if they are in the DROP state.
 		switch desc.(type) {
-		case catalog.SchemaDescriptor, catalog.DatabaseDescriptor:
+		case catalog.SchemaDescriptor, catalog.DatabaseDescriptor, catalog.FunctionDescriptor:
 			if desc.Dropped() {
 				if err := sc.execCfg.DB.Del(ctx, catalogkeys.MakeDescMetadataKey(sc.execCfg.Codec, desc.GetID())); err != nil {
 					return err
@@ -1937,12 +1936,8 @@ func maybeUpdateZoneConfigsForPKChange(
 
 	// Write the zone back. This call regenerates the index spans that apply
 	// to each partition in the index.
-	_, err = writeZoneConfig(ctx, txn, table.ID, table, zone, execCfg, false)
-	if err != nil && !sqlerrors.IsCCLRequiredError(err) {
-		return err
-	}
-
-	return nil
+	_, err = writeZoneConfig(ctx, txn, table.ID, table, zone, execCfg)
+	return err
 }
 
 // runStateMachineAndBackfill runs the schema change state machine followed by
@@ -2229,6 +2224,23 @@ func (sc *SchemaChanger) maybeDropValidatingConstraint(
 			"attempted to drop constraint %s, but it hadn't been added to the table descriptor yet",
 			constraint.UniqueWithoutIndex().Name,
 		)
+	} else if constraint.IsExclusion() {
+		if constraint.Exclusion().Validity == descpb.ConstraintValidity_Unvalidated {
+			return nil
+		}
+		for j, c := range desc.ExclusionConstraints {
+			if c.Name == constraint.Exclusion().Name {
+				desc.ExclusionConstraints = append(
+					desc.ExclusionConstraints[:j], desc.ExclusionConstraints[j+1:]...,
+				)
+				return nil
+			}
+		}
+		log.Infof(
+			ctx,
+			"attempted to drop constraint %s, but it hadn't been added to the table descriptor yet",
+			constraint.Exclusion().Name,
+		)
 	} else {
 		return errors.AssertionFailedf("unsupported constraint type: %d", constraint.ConstraintToUpdateDesc().ConstraintType)
 	}
@@ -278,6 +278,15 @@ func (b *builderState) SecondaryIndexPartitioningDescriptor(
 
 // ResolveTypeRef implements the scbuildstmt.TableHelpers interface.
 func (b *builderState) ResolveTypeRef(ref tree.ResolvableTypeReference) scpb.TypeT {
+	// Columns declared with a domain use the default of the domain, which the
+	// declarative schema changer does not apply, so leave them to the legacy
+	// schema changer.
+	if name, ok := ref.(*tree.UnresolvedObjectName); ok {
+		if _, typ := b.cr.MayResolveType(b.ctx, *name); typ != nil &&
+			typ.GetKind() == descpb.TypeDescriptor_DOMAIN {
+			panic(scerrors.NotImplementedErrorf(nil /* n */, "domain type %q", typ.GetName()))
+		}
+	}
 	toType, err := tree.ResolveType(b.ctx, ref, b.cr)
 	if err != nil {
 		panic(err)
@@ -527,6 +536,9 @@ func (b *builderState) ResolveEnumType(
 		// Implicit record types are not directly modifiable.
 		panic(pgerror.Newf(pgcode.DependentObjectsStillExist,
 			"cannot modify table record type %q", typ.GetName()))
+	case descpb.TypeDescriptor_COMPOSITE, descpb.TypeDescriptor_DOMAIN:
+		panic(scerrors.NotImplementedErrorf(nil, /* n */
+			"type %q of kind %s", typ.GetName(), typ.GetKind()))
 	default:
 		panic(errors.AssertionFailedf("unknown type kind %s", typ.GetKind()))
 	}
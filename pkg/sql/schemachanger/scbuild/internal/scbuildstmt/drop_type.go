@@ -24,6 +24,9 @@ import (
 
 // DropType implements DROP TYPE.
 func DropType(b BuildCtx, n *tree.DropType) {
+	if n.Domain {
+		panic(scerrors.NotImplementedErrorf(n, "DROP DOMAIN is not yet supported"))
+	}
 	if n.DropBehavior == tree.DropCascade {
 		panic(scerrors.NotImplementedErrorf(n, "DROP TYPE CASCADE is not yet supported"))
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
 	"github.com/cockroachdb/cockroach/pkg/util/iterutil"
@@ -160,6 +161,9 @@ func (w *walkCtx) walkType(typ catalog.TypeDescriptor) {
 			ArrayTypeID:   typ.GetArrayTypeID(),
 			IsMultiRegion: typ.GetKind() == descpb.TypeDescriptor_MULTIREGION_ENUM,
 		})
+	case descpb.TypeDescriptor_COMPOSITE, descpb.TypeDescriptor_DOMAIN:
+		panic(scerrors.NotImplementedErrorf(nil, /* n */
+			"type %q (%d) of kind %s", typ.GetName(), typ.GetID(), typ.GetKind()))
 	default:
 		panic(errors.AssertionFailedf("unsupported type kind %q", typ.GetKind()))
 	}
@@ -303,6 +307,10 @@ func (w *walkCtx) walkColumn(tbl catalog.TableDescriptor, col catalog.Column) {
 		panic(errors.NewAssertionErrorWithWrappedErrf(err, "column %q in table %q (%d)",
 			col.GetName(), tbl.GetName(), tbl.GetID()))
 	}
+	if col.GetType().IsDomain() {
+		panic(scerrors.NotImplementedErrorf(nil, /* n */
+			"column %q in table %q (%d) has a domain type", col.GetName(), tbl.GetName(), tbl.GetID()))
+	}
 	column := &scpb.Column{
 		TableID:                           tbl.GetID(),
 		ColumnID:                          col.GetID(),
@@ -218,8 +218,8 @@ func (d *buildDeps) MustGetSchemasForDatabase(
 	return schemas
 }
 
-// CreatePartitioningCCL is the public hook point for the CCL-licensed
-// partitioning creation code.
+// CreatePartitioningCCL is the hook point for the partitioning creation code,
+// which is injected by the sql package.
 var CreatePartitioningCCL scbuild.CreatePartitioningCCLCallback
 
 var _ scbuild.Dependencies = (*buildDeps)(nil)
@@ -2665,7 +2665,7 @@ months and years, use the timestamptz subtraction operator.`,
 			Types:      tree.ArgTypes{},
 			ReturnType: tree.FixedReturnType(types.TimestampTZ),
 			Fn:         followerReadTimestamp,
-			Info: fmt.Sprintf(`Returns a timestamp which is very likely to be safe to perform
+			Info: `Returns a timestamp which is very likely to be safe to perform
 against a follower replica.
 
 This function is intended to be used with an AS OF SYSTEM TIME clause to perform
@@ -2673,10 +2673,9 @@ historical reads against a time which is recent but sufficiently old for reads
 to be performed against the closest replica as opposed to the currently
 leaseholder for a given range.
 
-Note that this function requires an enterprise license on a CCL distribution to
-return a result that is less likely the closest replica. It is otherwise
-hardcoded as %s from the statement time, which may not result in reading from the
-nearest replica.`, defaultFollowerReadDuration),
+The offset from the statement time accounts for the closed timestamp target
+duration, the closed timestamp side transport interval and the closed timestamp
+propagation slack cluster settings.`,
 			Volatility: tree.VolatilityVolatile,
 		},
 	),
@@ -3479,9 +3478,7 @@ value if you rely on the HLC for accuracy.`,
 				dimen := int64(tree.MustBeDInt(args[1]))
 				return arrayLength(arr, dimen), nil
 			},
-			Info: "Calculates the length of `input` on the provided `array_dimension`. However, " +
-				"because CockroachDB doesn't yet support multi-dimensional arrays, the only supported" +
-				" `array_dimension` is **1**.",
+			Info:       "Calculates the length of `input` on the provided `array_dimension`.",
 			Volatility: tree.VolatilityImmutable,
 		},
 	),
@@ -3508,9 +3505,7 @@ value if you rely on the HLC for accuracy.`,
 				dimen := int64(tree.MustBeDInt(args[1]))
 				return arrayLower(arr, dimen), nil
 			},
-			Info: "Calculates the minimum value of `input` on the provided `array_dimension`. " +
-				"However, because CockroachDB doesn't yet support multi-dimensional arrays, the only " +
-				"supported `array_dimension` is **1**.",
+			Info:       "Calculates the minimum value of `input` on the provided `array_dimension`.",
 			Volatility: tree.VolatilityImmutable,
 		},
 	),
@@ -3522,11 +3517,46 @@ value if you rely on the HLC for accuracy.`,
 			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
 				arr := tree.MustBeDArray(args[0])
 				dimen := int64(tree.MustBeDInt(args[1]))
-				return arrayLength(arr, dimen), nil
+				return arrayUpper(arr, dimen), nil
+			},
+			Info:       "Calculates the maximum value of `input` on the provided `array_dimension`.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"array_ndims": makeBuiltin(arrayProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"input", types.AnyArray}},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				arr := tree.MustBeDArray(args[0])
+				if arr.NumDims() == 0 {
+					return tree.DNull, nil
+				}
+				return tree.NewDInt(tree.DInt(arr.NumDims())), nil
 			},
-			Info: "Calculates the maximum value of `input` on the provided `array_dimension`. " +
-				"However, because CockroachDB doesn't yet support multi-dimensional arrays, the only " +
-				"supported `array_dimension` is **1**.",
+			Info:       "Returns the number of dimensions of `input`.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"array_dims": makeBuiltin(arrayProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"input", types.AnyArray}},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				arr := tree.MustBeDArray(args[0])
+				dims := arr.Dimensions()
+				if len(dims) == 0 {
+					return tree.DNull, nil
+				}
+				var buf bytes.Buffer
+				for _, dim := range dims {
+					fmt.Fprintf(&buf, "[%d:%d]", dim.LowerBound, dim.LowerBound+dim.Length-1)
+				}
+				return tree.NewDString(buf.String()), nil
+			},
+			Info:       "Returns a text representation of the dimensions of `input`, such as `[1:2][1:3]`.",
 			Volatility: tree.VolatilityImmutable,
 		},
 	),
@@ -3601,8 +3631,13 @@ value if you rely on the HLC for accuracy.`,
 				if args[0] == tree.DNull {
 					return tree.DNull, nil
 				}
+				arr := tree.MustBeDArray(args[0])
+				if len(arr.Dims) > 1 {
+					return nil, pgerror.New(pgcode.FeatureNotSupported,
+						"removing elements from multidimensional arrays is not supported")
+				}
 				result := tree.NewDArray(typ)
-				for _, e := range tree.MustBeDArray(args[0]).Array {
+				for _, e := range arr.Array {
 					cmp, err := e.CompareError(ctx, args[1])
 					if err != nil {
 						return nil, err
@@ -3628,8 +3663,9 @@ value if you rely on the HLC for accuracy.`,
 				if args[0] == tree.DNull {
 					return tree.DNull, nil
 				}
+				arr := tree.MustBeDArray(args[0])
 				result := tree.NewDArray(typ)
-				for _, e := range tree.MustBeDArray(args[0]).Array {
+				for _, e := range arr.Array {
 					cmp, err := e.CompareError(ctx, args[1])
 					if err != nil {
 						return nil, err
@@ -3644,6 +3680,10 @@ value if you rely on the HLC for accuracy.`,
 						}
 					}
 				}
+				// The result has the same shape as the input.
+				if err := result.SetDims(arr.Dims); err != nil {
+					return nil, err
+				}
 				return result, nil
 			},
 			Info:       "Replace all occurrences of `toreplace` in `array` with `replacewith`.",
@@ -3659,13 +3699,18 @@ value if you rely on the HLC for accuracy.`,
 				if args[0] == tree.DNull {
 					return tree.DNull, nil
 				}
-				for i, e := range tree.MustBeDArray(args[0]).Array {
+				arr := tree.MustBeDArray(args[0])
+				lowerBound, err := arrayPositionLowerBound(arr)
+				if err != nil {
+					return nil, err
+				}
+				for i, e := range arr.Array {
 					cmp, err := e.CompareError(ctx, args[1])
 					if err != nil {
 						return nil, err
 					}
 					if cmp == 0 {
-						return tree.NewDInt(tree.DInt(i + 1)), nil
+						return tree.NewDInt(tree.DInt(i + lowerBound)), nil
 					}
 				}
 				return tree.DNull, nil
@@ -3683,14 +3728,19 @@ value if you rely on the HLC for accuracy.`,
 				if args[0] == tree.DNull {
 					return tree.DNull, nil
 				}
+				arr := tree.MustBeDArray(args[0])
+				lowerBound, err := arrayPositionLowerBound(arr)
+				if err != nil {
+					return nil, err
+				}
 				result := tree.NewDArray(types.Int)
-				for i, e := range tree.MustBeDArray(args[0]).Array {
+				for i, e := range arr.Array {
 					cmp, err := e.CompareError(ctx, args[1])
 					if err != nil {
 						return nil, err
 					}
 					if cmp == 0 {
-						if err := result.Append(tree.NewDInt(tree.DInt(i + 1))); err != nil {
+						if err := result.Append(tree.NewDInt(tree.DInt(i + lowerBound))); err != nil {
 							return nil, err
 						}
 					}
@@ -6279,6 +6329,28 @@ value if you rely on the HLC for accuracy.`,
 		},
 	),
 
+	// grouping is handled by the optimizer, which replaces it with the value
+	// for the grouping set of each output row.
+	"grouping": makeBuiltin(
+		tree.FunctionProperties{
+			NullableArgs: true,
+		},
+		tree.Overload{
+			Types: tree.VariadicType{
+				VarType: types.Any,
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return nil, pgerror.New(pgcode.Grouping,
+					"arguments to GROUPING must be grouping expressions of the associated query level")
+			},
+			Info: "Returns a bit mask of the arguments which are not included in the " +
+				"grouping set of the current row. The last argument corresponds to the " +
+				"least significant bit. The arguments must be GROUP BY expressions.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
 	GatewayRegionBuiltinName: makeBuiltin(
 		tree.FunctionProperties{
 			Category: categoryMultiRegion,
@@ -8560,33 +8632,41 @@ func cardinality(arr *tree.DArray) tree.Datum {
 }
 
 func arrayLength(arr *tree.DArray, dim int64) tree.Datum {
-	if arr.Len() == 0 || dim < 1 {
+	dims := arr.Dimensions()
+	if dim < 1 || dim > int64(len(dims)) {
 		return tree.DNull
 	}
-	if dim == 1 {
-		return tree.NewDInt(tree.DInt(arr.Len()))
-	}
-	a, ok := tree.AsDArray(arr.Array[0])
-	if !ok {
+	return tree.NewDInt(tree.DInt(dims[dim-1].Length))
+}
+
+func arrayLower(arr *tree.DArray, dim int64) tree.Datum {
+	dims := arr.Dimensions()
+	if dim < 1 || dim > int64(len(dims)) {
 		return tree.DNull
 	}
-	return arrayLength(a, dim-1)
+	return tree.NewDInt(tree.DInt(dims[dim-1].LowerBound))
 }
 
-var intOne = tree.NewDInt(tree.DInt(1))
-
-func arrayLower(arr *tree.DArray, dim int64) tree.Datum {
-	if arr.Len() == 0 || dim < 1 {
+func arrayUpper(arr *tree.DArray, dim int64) tree.Datum {
+	dims := arr.Dimensions()
+	if dim < 1 || dim > int64(len(dims)) {
 		return tree.DNull
 	}
-	if dim == 1 {
-		return intOne
+	return tree.NewDInt(tree.DInt(dims[dim-1].LowerBound + dims[dim-1].Length - 1))
+}
+
+// arrayPositionLowerBound returns the subscript of the first element of the
+// array, which must not be multidimensional, for array_position and
+// array_positions.
+func arrayPositionLowerBound(arr *tree.DArray) (int, error) {
+	if len(arr.Dims) > 1 {
+		return 0, pgerror.New(pgcode.FeatureNotSupported,
+			"searching for elements in multidimensional arrays is not supported")
 	}
-	a, ok := tree.AsDArray(arr.Array[0])
-	if !ok {
-		return tree.DNull
+	if arr.Dims != nil {
+		return arr.Dims[0].LowerBound, nil
 	}
-	return arrayLower(a, dim-1)
+	return 1, nil
 }
 
 var extractBuiltin = makeBuiltin(
@@ -9398,8 +9478,7 @@ var errInsufficientPriv = pgerror.New(
 
 // EvalFollowerReadOffset is a function used often with AS OF SYSTEM TIME queries
 // to determine the appropriate offset from now which is likely to be safe for
-// follower reads. It is injected by followerreadsccl. An error may be returned
-// if an enterprise license is not installed.
+// follower reads. It is injected by the sql package.
 var EvalFollowerReadOffset func(logicalClusterID uuid.UUID, _ *cluster.Settings) (time.Duration, error)
 
 func recentTimestamp(ctx *tree.EvalContext) (time.Time, error) {
@@ -9442,7 +9521,7 @@ func followerReadTimestamp(ctx *tree.EvalContext, _ tree.Datums) (tree.Datum, er
 
 var (
 	// WithMinTimestamp is an injectable function containing the implementation of the
-	// with_min_timestamp builtin.
+	// with_min_timestamp builtin. It is injected by the sql package.
 	WithMinTimestamp = func(ctx *tree.EvalContext, t time.Time) (time.Time, error) {
 		return time.Time{}, pgerror.Newf(
 			pgcode.CCLRequired,
@@ -9451,7 +9530,7 @@ var (
 		)
 	}
 	// WithMaxStaleness is an injectable function containing the implementation of the
-	// with_max_staleness builtin.
+	// with_max_staleness builtin. It is injected by the sql package.
 	WithMaxStaleness = func(ctx *tree.EvalContext, d duration.Duration) (time.Time, error) {
 		return time.Time{}, pgerror.Newf(
 			pgcode.CCLRequired,
@@ -9475,9 +9554,7 @@ func withMinTimestampInfo(nearestOnly bool) string {
 	return fmt.Sprintf(
 		`When used in the AS OF SYSTEM TIME clause of an single-statement,
 read-only transaction, CockroachDB chooses the newest timestamp before the min_timestamp
-that allows execution of the reads at the nearest available replica without blocking.%s
-
-Note this function requires an enterprise license on a CCL distribution.`,
+that allows execution of the reads at the nearest available replica without blocking.%s`,
 		nearestOnlyText,
 	)
 }
@@ -9490,9 +9567,7 @@ func withMaxStalenessInfo(nearestOnly bool) string {
 	return fmt.Sprintf(
 		`When used in the AS OF SYSTEM TIME clause of an single-statement,
 read-only transaction, CockroachDB chooses the newest timestamp within the staleness
-bound that allows execution of the reads at the nearest available replica without blocking.%s
-
-Note this function requires an enterprise license on a CCL distribution.`,
+bound that allows execution of the reads at the nearest available replica without blocking.%s`,
 		nearestOnlyText,
 	)
 }
@@ -13,6 +13,7 @@ package builtins
 import (
 	"bytes"
 	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -175,6 +176,17 @@ var generators = map[string]builtinDefinition{
 		),
 	),
 
+	"pg_listening_channels": makeBuiltin(genProps(),
+		// See https://www.postgresql.org/docs/current/functions-info.html#FUNCTIONS-INFO-SESSION-TABLE
+		makeGeneratorOverload(
+			tree.ArgTypes{},
+			types.String,
+			makeListeningChannelsGenerator,
+			"Returns the names of the channels the current session is listening on.",
+			tree.VolatilityStable,
+		),
+	),
+
 	"regexp_split_to_table": makeBuiltin(
 		genProps(),
 		makeGeneratorOverload(
@@ -887,6 +899,20 @@ func (s *arrayValueGenerator) Values() (tree.Datums, error) {
 	return tree.Datums{s.array.Array[s.nextIndex]}, nil
 }
 
+func makeListeningChannelsGenerator(
+	ctx *tree.EvalContext, _ tree.Datums,
+) (tree.ValueGenerator, error) {
+	channels := ctx.Planner.ListeningChannels()
+	sort.Strings(channels)
+	arr := tree.NewDArray(types.String)
+	for _, channel := range channels {
+		if err := arr.Append(tree.NewDString(channel)); err != nil {
+			return nil, err
+		}
+	}
+	return &arrayValueGenerator{array: arr}, nil
+}
+
 func makeExpandArrayGenerator(
 	evalCtx *tree.EvalContext, args tree.Datums,
 ) (tree.ValueGenerator, error) {
@@ -524,6 +524,28 @@ var pgBuiltins = map[string]builtinDefinition{
 		},
 	),
 
+	// See https://www.postgresql.org/docs/current/sql-notify.html.
+	"pg_notify": makeBuiltin(tree.FunctionProperties{NullableArgs: true, DistsqlBlocklist: true},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"channel", types.String}, {"payload", types.String}},
+			ReturnType: tree.FixedReturnType(types.Void),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				var channel, payload string
+				if args[0] != tree.DNull {
+					channel = string(tree.MustBeDString(args[0]))
+				}
+				if args[1] != tree.DNull {
+					payload = string(tree.MustBeDString(args[1]))
+				}
+				return tree.DVoidDatum, ctx.Planner.Notify(ctx.Context, channel, payload)
+			},
+			Info: "Sends a notification with the given payload on the given channel, " +
+				"like the NOTIFY statement. The notification is delivered when the " +
+				"current transaction commits.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	// See https://www.postgresql.org/docs/9.3/static/catalog-pg-database.html.
 	"pg_encoding_to_char": makeBuiltin(defProps(),
 		tree.Overload{
@@ -11,9 +11,13 @@
 package builtins
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
-	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/cockroach/pkg/streaming"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streampb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 )
 
 func initReplicationBuiltins() {
@@ -26,8 +30,6 @@ func initReplicationBuiltins() {
 	}
 }
 
-var errRequiresCCL = errors.New("replication streaming requires a CCL binary")
-
 // replication builtins contains the cluster to cluster replication built-in functions indexed by name.
 //
 // For use in other packages, see AllBuiltinNames and GetBuiltinProperties().
@@ -44,7 +46,17 @@ var replicationBuiltins = map[string]builtinDefinition{
 			},
 			ReturnType: tree.FixedReturnType(types.Int),
 			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
-				return nil, errRequiresCCL
+				mgr, err := streaming.GetStreamIngestManager(evalCtx)
+				if err != nil {
+					return nil, err
+				}
+				jobID := jobspb.JobID(tree.MustBeDInt(args[0]))
+				cutoverTime := tree.MustBeDTimestampTZ(args[1]).Time
+				cutoverTimestamp := hlc.Timestamp{WallTime: cutoverTime.UnixNano()}
+				if err := mgr.CompleteStreamIngestion(evalCtx, evalCtx.Txn, jobID, cutoverTimestamp); err != nil {
+					return nil, err
+				}
+				return tree.NewDInt(tree.DInt(jobID)), nil
 			},
 			Info: "This function can be used to signal a running stream ingestion job to complete. " +
 				"The job will eventually stop ingesting, revert to the specified timestamp and leave the " +
@@ -69,7 +81,16 @@ var replicationBuiltins = map[string]builtinDefinition{
 			},
 			ReturnType: tree.FixedReturnType(types.Int),
 			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
-				return nil, errRequiresCCL
+				mgr, err := streaming.GetReplicationStreamManager(evalCtx)
+				if err != nil {
+					return nil, err
+				}
+				tenantID := uint64(tree.MustBeDInt(args[0]))
+				streamID, err := mgr.StartReplicationStream(evalCtx, evalCtx.Txn, tenantID)
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDInt(tree.DInt(streamID)), nil
 			},
 			Info: "This function can be used on the producer side to start a replication stream for " +
 				"the specified tenant. The returned stream ID uniquely identifies created stream. " +
@@ -91,7 +112,24 @@ var replicationBuiltins = map[string]builtinDefinition{
 			},
 			ReturnType: tree.FixedReturnType(types.Bytes),
 			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
-				return nil, errRequiresCCL
+				mgr, err := streaming.GetReplicationStreamManager(evalCtx)
+				if err != nil {
+					return nil, err
+				}
+				streamID := streampb.StreamID(tree.MustBeDInt(args[0]))
+				frontier, err := hlc.ParseTimestamp(string(tree.MustBeDString(args[1])))
+				if err != nil {
+					return nil, err
+				}
+				status, err := mgr.HeartbeatReplicationStream(evalCtx, streamID, frontier, evalCtx.Txn)
+				if err != nil {
+					return nil, err
+				}
+				rawStatus, err := protoutil.Marshal(&status)
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDBytes(tree.DBytes(rawStatus)), nil
 			},
 			Info: "This function can be used on the consumer side to heartbeat its replication progress to " +
 				"a replication stream in the source cluster. The returns a StreamReplicationStatus message " +
@@ -115,7 +153,12 @@ var replicationBuiltins = map[string]builtinDefinition{
 				[]string{"stream_event"},
 			),
 			func(evalCtx *tree.EvalContext, args tree.Datums) (tree.ValueGenerator, error) {
-				return nil, errRequiresCCL
+				mgr, err := streaming.GetReplicationStreamManager(evalCtx)
+				if err != nil {
+					return nil, err
+				}
+				streamID := streampb.StreamID(tree.MustBeDInt(args[0]))
+				return mgr.StreamPartition(evalCtx, streamID, []byte(tree.MustBeDBytes(args[1])))
 			},
 			"Stream partition data",
 			tree.VolatilityVolatile,
@@ -133,7 +176,20 @@ var replicationBuiltins = map[string]builtinDefinition{
 			},
 			ReturnType: tree.FixedReturnType(types.Bytes),
 			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
-				return nil, errRequiresCCL
+				mgr, err := streaming.GetReplicationStreamManager(evalCtx)
+				if err != nil {
+					return nil, err
+				}
+				streamID := streampb.StreamID(tree.MustBeDInt(args[0]))
+				spec, err := mgr.GetReplicationStreamSpec(evalCtx, evalCtx.Txn, streamID)
+				if err != nil {
+					return nil, err
+				}
+				rawSpec, err := protoutil.Marshal(spec)
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDBytes(tree.DBytes(rawSpec)), nil
 			},
 			Info: "This function can be used on the consumer side to get a replication stream specification " +
 				"for the specified stream starting from the specified 'start_from' timestamp. The consumer will " +
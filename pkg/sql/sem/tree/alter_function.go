@@ -0,0 +1,69 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// AlterFunctionOptions represents a ALTER FUNCTION...action statement.
+type AlterFunctionOptions struct {
+	Function FuncObj
+	Options  FunctionOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterFunctionOptions) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER FUNCTION ")
+	ctx.FormatNode(&node.Function)
+	for _, option := range node.Options {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(option)
+	}
+}
+
+// AlterFunctionRename represents a ALTER FUNCTION...RENAME statement.
+type AlterFunctionRename struct {
+	Function FuncObj
+	NewName  Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterFunctionRename) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER FUNCTION ")
+	ctx.FormatNode(&node.Function)
+	ctx.WriteString(" RENAME TO ")
+	ctx.FormatNode(&node.NewName)
+}
+
+// AlterFunctionSetSchema represents a ALTER FUNCTION...SET SCHEMA statement.
+type AlterFunctionSetSchema struct {
+	Function      FuncObj
+	NewSchemaName Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterFunctionSetSchema) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER FUNCTION ")
+	ctx.FormatNode(&node.Function)
+	ctx.WriteString(" SET SCHEMA ")
+	ctx.FormatNode(&node.NewSchemaName)
+}
+
+// AlterFunctionSetOwner represents the ALTER FUNCTION...OWNER TO statement.
+type AlterFunctionSetOwner struct {
+	Function FuncObj
+	NewOwner RoleSpec
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterFunctionSetOwner) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER FUNCTION ")
+	ctx.FormatNode(&node.Function)
+	ctx.WriteString(" OWNER TO ")
+	ctx.FormatNode(&node.NewOwner)
+}
@@ -173,12 +173,13 @@ func (node *AlterTable) HoistAddColumnConstraints() {
 					targetCol = append(targetCol, d.References.Col)
 				}
 				fk := &ForeignKeyConstraintTableDef{
-					Table:    *d.References.Table,
-					FromCols: NameList{d.Name},
-					ToCols:   targetCol,
-					Name:     d.References.ConstraintName,
-					Actions:  d.References.Actions,
-					Match:    d.References.Match,
+					Table:      *d.References.Table,
+					FromCols:   NameList{d.Name},
+					ToCols:     targetCol,
+					Name:       d.References.ConstraintName,
+					Actions:    d.References.Actions,
+					Match:      d.References.Match,
+					Deferrable: d.References.Deferrable,
 				}
 				constraint := &AlterTableAddConstraint{
 					ConstraintDef:      fk,
@@ -1328,6 +1328,12 @@ func lookupCast(src, tgt *types.T, intervalStyleEnabled, dateStyleEnabled bool)
 		}, true
 	}
 
+	// Domains have dynamic OIDs, and are cast like their base types. This makes
+	// the casts between a domain and its base type implicit.
+	if src.IsDomain() || tgt.IsDomain() {
+		return lookupCast(src.DomainBaseType(), tgt.DomainBaseType(), intervalStyleEnabled, dateStyleEnabled)
+	}
+
 	// Enums have dynamic OIDs, so they can't be populated in castMap. Instead,
 	// we dynamically create cast structs for valid enum casts.
 	if srcFamily == types.EnumFamily && tgtFamily == types.StringFamily {
@@ -1492,7 +1498,24 @@ func PerformAssignmentCast(ctx *EvalContext, d Datum, t *types.T) (Datum, error)
 	return performCast(ctx, d, t, false /* truncateWidth */)
 }
 
+// CheckDomainConstraints returns an error if the datum does not satisfy the
+// NOT NULL and CHECK constraints of the domain typ. It is injected by the sql
+// package, since evaluating the constraints requires parsing them.
+var CheckDomainConstraints func(ctx *EvalContext, typ *types.T, d Datum) error
+
 func performCast(ctx *EvalContext, d Datum, t *types.T, truncateWidth bool) (Datum, error) {
+	if t.IsDomain() {
+		// Cast to the base type of the domain, and check that the result
+		// satisfies the constraints of the domain.
+		ret, err := performCast(ctx, d, t.DomainBaseType(), truncateWidth)
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckDomainConstraints(ctx, t, ret); err != nil {
+			return nil, err
+		}
+		return ret, nil
+	}
 	ret, err := performCastWithoutPrecisionTruncation(ctx, d, t, truncateWidth)
 	if err != nil {
 		return nil, err
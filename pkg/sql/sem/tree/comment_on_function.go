@@ -0,0 +1,35 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import "github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+
+// CommentOnFunction represents a COMMENT ON FUNCTION statement.
+type CommentOnFunction struct {
+	Function FuncObj
+	Comment  *string
+}
+
+// Format implements the NodeFormatter interface.
+func (n *CommentOnFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("COMMENT ON FUNCTION ")
+	ctx.FormatNode(&n.Function)
+	ctx.WriteString(" IS ")
+	if n.Comment != nil {
+		if ctx.flags.HasFlags(FmtHideConstants) {
+			ctx.WriteString("'_'")
+		} else {
+			lexbase.EncodeSQLStringWithFlags(&ctx.Buffer, *n.Comment, ctx.flags.EncodeFlags())
+		}
+	} else {
+		ctx.WriteString("NULL")
+	}
+}
@@ -23,6 +23,15 @@ type CopyFrom struct {
 	Options CopyOptions
 }
 
+// CopyTo represents a COPY TO statement. Either Table (with optional
+// Columns) or Statement is set.
+type CopyTo struct {
+	Table     TableName
+	Columns   NameList
+	Statement Statement
+	Options   CopyOptions
+}
+
 // CopyOptions describes options for COPY execution.
 type CopyOptions struct {
 	Destination Expr
@@ -30,6 +39,22 @@ type CopyOptions struct {
 	Delimiter   Expr
 	Null        Expr
 	Escape      *StrVal
+	Header      bool
+	Oids        bool
+	Freeze      bool
+	Quote       *StrVal
+	Encoding    *StrVal
+
+	// ForceQuote lists the columns whose non-NULL values are always quoted
+	// by a CSV COPY TO. ForceQuoteAll applies this to all columns.
+	ForceQuote    NameList
+	ForceQuoteAll bool
+	// ForceNotNull lists the columns whose values are never matched against
+	// the NULL string by a CSV COPY FROM.
+	ForceNotNull NameList
+	// ForceNull lists the columns whose values are matched against the NULL
+	// string by a CSV COPY FROM even when they are quoted.
+	ForceNull NameList
 }
 
 var _ NodeFormatter = &CopyOptions{}
@@ -53,6 +78,28 @@ func (node *CopyFrom) Format(ctx *FmtCtx) {
 	}
 }
 
+// Format implements the NodeFormatter interface.
+func (node *CopyTo) Format(ctx *FmtCtx) {
+	ctx.WriteString("COPY ")
+	if node.Statement != nil {
+		ctx.WriteString("(")
+		ctx.FormatNode(node.Statement)
+		ctx.WriteString(")")
+	} else {
+		ctx.FormatNode(&node.Table)
+		if len(node.Columns) > 0 {
+			ctx.WriteString(" (")
+			ctx.FormatNode(&node.Columns)
+			ctx.WriteString(")")
+		}
+	}
+	ctx.WriteString(" TO STDOUT")
+	if !node.Options.IsDefault() {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
 // Format implements the NodeFormatter interface
 func (o *CopyOptions) Format(ctx *FmtCtx) {
 	var addSep bool
@@ -97,11 +144,54 @@ func (o *CopyOptions) Format(ctx *FmtCtx) {
 		ctx.WriteString("ESCAPE ")
 		ctx.FormatNode(o.Escape)
 	}
+	if o.Header {
+		maybeAddSep()
+		ctx.WriteString("HEADER")
+	}
+	if o.Oids {
+		maybeAddSep()
+		ctx.WriteString("OIDS")
+	}
+	if o.Freeze {
+		maybeAddSep()
+		ctx.WriteString("FREEZE")
+	}
+	if o.Quote != nil {
+		maybeAddSep()
+		ctx.WriteString("QUOTE ")
+		ctx.FormatNode(o.Quote)
+	}
+	if o.ForceQuoteAll {
+		maybeAddSep()
+		ctx.WriteString("FORCE QUOTE *")
+	} else if len(o.ForceQuote) > 0 {
+		maybeAddSep()
+		ctx.WriteString("FORCE QUOTE ")
+		ctx.FormatNode(&o.ForceQuote)
+	}
+	if len(o.ForceNotNull) > 0 {
+		maybeAddSep()
+		ctx.WriteString("FORCE NOT NULL ")
+		ctx.FormatNode(&o.ForceNotNull)
+	}
+	if len(o.ForceNull) > 0 {
+		maybeAddSep()
+		ctx.WriteString("FORCE NULL ")
+		ctx.FormatNode(&o.ForceNull)
+	}
+	if o.Encoding != nil {
+		maybeAddSep()
+		ctx.WriteString("ENCODING ")
+		ctx.FormatNode(o.Encoding)
+	}
 }
 
 // IsDefault returns true if this struct has default value.
 func (o CopyOptions) IsDefault() bool {
-	return o == CopyOptions{}
+	return o.Destination == nil && o.CopyFormat == CopyFormatText && o.Delimiter == nil &&
+		o.Null == nil && o.Escape == nil && !o.Header && !o.Oids && !o.Freeze &&
+		o.Quote == nil && o.Encoding == nil && o.ForceQuote == nil && !o.ForceQuoteAll &&
+		o.ForceNotNull == nil && o.ForceNull == nil
 }
 
 // CombineWith merges other options into this struct. An error is returned if
@@ -137,6 +227,55 @@ func (o *CopyOptions) CombineWith(other *CopyOptions) error {
 		}
 		o.Escape = other.Escape
 	}
+	if other.Header {
+		if o.Header {
+			return pgerror.Newf(pgcode.Syntax, "header option specified multiple times")
+		}
+		o.Header = true
+	}
+	if other.Oids {
+		if o.Oids {
+			return pgerror.Newf(pgcode.Syntax, "oids option specified multiple times")
+		}
+		o.Oids = true
+	}
+	if other.Freeze {
+		if o.Freeze {
+			return pgerror.Newf(pgcode.Syntax, "freeze option specified multiple times")
+		}
+		o.Freeze = true
+	}
+	if other.Quote != nil {
+		if o.Quote != nil {
+			return pgerror.Newf(pgcode.Syntax, "quote option specified multiple times")
+		}
+		o.Quote = other.Quote
+	}
+	if other.ForceQuote != nil || other.ForceQuoteAll {
+		if o.ForceQuote != nil || o.ForceQuoteAll {
+			return pgerror.Newf(pgcode.Syntax, "force quote option specified multiple times")
+		}
+		o.ForceQuote = other.ForceQuote
+		o.ForceQuoteAll = other.ForceQuoteAll
+	}
+	if other.ForceNotNull != nil {
+		if o.ForceNotNull != nil {
+			return pgerror.Newf(pgcode.Syntax, "force not null option specified multiple times")
+		}
+		o.ForceNotNull = other.ForceNotNull
+	}
+	if other.ForceNull != nil {
+		if o.ForceNull != nil {
+			return pgerror.Newf(pgcode.Syntax, "force null option specified multiple times")
+		}
+		o.ForceNull = other.ForceNull
+	}
+	if other.Encoding != nil {
+		if o.Encoding != nil {
+			return pgerror.Newf(pgcode.Syntax, "encoding option specified multiple times")
+		}
+		o.Encoding = other.Encoding
+	}
 	return nil
 }
 
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/pretty"
@@ -322,12 +323,73 @@ func (l *EnumValueList) Format(ctx *FmtCtx) {
 	}
 }
 
-// CreateType represents a CREATE TYPE statement.
+// CompositeTypeElem is a single field of a composite type.
+type CompositeTypeElem struct {
+	Label Name
+	Type  ResolvableTypeReference
+}
+
+// DomainCheck is a CHECK constraint on a domain. The expression refers to the
+// value being checked as VALUE.
+type DomainCheck struct {
+	Name Name
+	Expr Expr
+}
+
+// DomainDef holds the base type, default and constraints of a domain.
+type DomainDef struct {
+	BaseType    ResolvableTypeReference
+	DefaultExpr Expr
+	NotNull     bool
+	Checks      []DomainCheck
+}
+
+// NewDomainDef constructs a DomainDef from the base type and the column
+// qualifications that followed it in a CREATE DOMAIN statement.
+func NewDomainDef(
+	typRef ResolvableTypeReference, qualifications []NamedColumnQualification,
+) (*DomainDef, error) {
+	d := &DomainDef{BaseType: typRef}
+	nullability := SilentNull
+	for _, c := range qualifications {
+		switch t := c.Qualification.(type) {
+		case *ColumnDefault:
+			if d.DefaultExpr != nil {
+				return nil, pgerror.New(pgcode.Syntax, "multiple default expressions")
+			}
+			d.DefaultExpr = t.Expr
+		case NotNullConstraint:
+			if nullability == Null {
+				return nil, pgerror.New(pgcode.Syntax, "conflicting NULL/NOT NULL constraints")
+			}
+			nullability = NotNull
+			d.NotNull = true
+		case NullConstraint:
+			if nullability == NotNull {
+				return nil, pgerror.New(pgcode.Syntax, "conflicting NULL/NOT NULL constraints")
+			}
+			nullability = Null
+		case *ColumnCheckConstraint:
+			d.Checks = append(d.Checks, DomainCheck{Name: c.Name, Expr: t.Expr})
+		default:
+			return nil, pgerror.New(pgcode.Syntax,
+				"only DEFAULT, NOT NULL, NULL and CHECK constraints are possible for domains")
+		}
+	}
+	return d, nil
+}
+
+// CreateType represents a CREATE TYPE or CREATE DOMAIN statement.
 type CreateType struct {
 	TypeName *UnresolvedObjectName
 	Variety  CreateTypeVariety
 	// EnumLabels is set when this represents a CREATE TYPE ... AS ENUM statement.
 	EnumLabels EnumValueList
+	// CompositeTypeList is set when this represents a CREATE TYPE ... AS (...)
+	// statement.
+	CompositeTypeList []CompositeTypeElem
+	// Domain is set when this represents a CREATE DOMAIN statement.
+	Domain *DomainDef
 	// IfNotExists is true if IF NOT EXISTS was requested.
 	IfNotExists bool
 }
@@ -336,7 +398,11 @@ var _ Statement = &CreateType{}
 
 // Format implements the NodeFormatter interface.
 func (node *CreateType) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE TYPE ")
+	if node.Variety == Domain {
+		ctx.WriteString("CREATE DOMAIN ")
+	} else {
+		ctx.WriteString("CREATE TYPE ")
+	}
 	if node.IfNotExists {
 		ctx.WriteString("IF NOT EXISTS ")
 	}
@@ -347,6 +413,38 @@ func (node *CreateType) Format(ctx *FmtCtx) {
 		ctx.WriteString("AS ENUM (")
 		ctx.FormatNode(&node.EnumLabels)
 		ctx.WriteString(")")
+	case Composite:
+		ctx.WriteString("AS (")
+		for i := range node.CompositeTypeList {
+			elem := &node.CompositeTypeList[i]
+			if i > 0 {
+				ctx.WriteString(", ")
+			}
+			ctx.FormatNode(&elem.Label)
+			ctx.WriteByte(' ')
+			ctx.FormatTypeReference(elem.Type)
+		}
+		ctx.WriteString(")")
+	case Domain:
+		ctx.WriteString("AS ")
+		ctx.FormatTypeReference(node.Domain.BaseType)
+		if node.Domain.DefaultExpr != nil {
+			ctx.WriteString(" DEFAULT ")
+			ctx.FormatNode(node.Domain.DefaultExpr)
+		}
+		if node.Domain.NotNull {
+			ctx.WriteString(" NOT NULL")
+		}
+		for i := range node.Domain.Checks {
+			check := &node.Domain.Checks[i]
+			if check.Name != "" {
+				ctx.WriteString(" CONSTRAINT ")
+				ctx.FormatNode(&check.Name)
+			}
+			ctx.WriteString(" CHECK (")
+			ctx.FormatNode(check.Expr)
+			ctx.WriteByte(')')
+		}
 	}
 }
 
@@ -368,6 +466,7 @@ func (*IndexTableDef) tableDef()                {}
 func (*FamilyTableDef) tableDef()               {}
 func (*ForeignKeyConstraintTableDef) tableDef() {}
 func (*CheckConstraintTableDef) tableDef()      {}
+func (*ExclusionConstraintTableDef) tableDef()  {}
 func (*LikeTableDef) tableDef()                 {}
 
 // TableDefs represents a list of table definitions.
@@ -446,6 +545,7 @@ type ColumnTableDef struct {
 		ConstraintName Name
 		Actions        ReferenceActions
 		Match          CompositeKeyMatchMethod
+		Deferrable     ConstraintDeferrability
 	}
 	Computed struct {
 		Computed bool
@@ -629,6 +729,7 @@ func NewColumnTableDef(
 			d.References.ConstraintName = c.Name
 			d.References.Actions = t.Actions
 			d.References.Match = t.Match
+			d.References.Deferrable = t.Deferrable
 		case *ColumnComputedDef:
 			if d.GeneratedIdentity.IsGeneratedAsIdentity {
 				return nil, pgerror.Newf(pgcode.Syntax,
@@ -799,6 +900,7 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 			ctx.WriteString(node.References.Match.String())
 		}
 		ctx.FormatNode(&node.References.Actions)
+		ctx.FormatNode(&node.References.Deferrable)
 	}
 	if node.IsComputed() {
 		ctx.WriteString(" AS (")
@@ -928,10 +1030,11 @@ type ColumnCheckConstraint struct {
 
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
-	Table   TableName
-	Col     Name // empty-string means use PK
-	Actions ReferenceActions
-	Match   CompositeKeyMatchMethod
+	Table      TableName
+	Col        Name // empty-string means use PK
+	Actions    ReferenceActions
+	Match      CompositeKeyMatchMethod
+	Deferrable ConstraintDeferrability
 }
 
 // ColumnComputedDef represents the description of a computed column.
@@ -1014,6 +1117,7 @@ type ConstraintTableDef interface {
 func (*UniqueConstraintTableDef) constraintTableDef()     {}
 func (*ForeignKeyConstraintTableDef) constraintTableDef() {}
 func (*CheckConstraintTableDef) constraintTableDef()      {}
+func (*ExclusionConstraintTableDef) constraintTableDef()  {}
 
 // UniqueConstraintTableDef represents a unique constraint within a CREATE
 // TABLE statement.
@@ -1021,6 +1125,7 @@ type UniqueConstraintTableDef struct {
 	IndexTableDef
 	PrimaryKey   bool
 	WithoutIndex bool
+	Deferrable   ConstraintDeferrability
 	IfNotExists  bool
 }
 
@@ -1066,12 +1171,90 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionByIndex != nil {
 		ctx.FormatNode(node.PartitionByIndex)
 	}
+	ctx.FormatNode(&node.Deferrable)
 	if node.Predicate != nil {
 		ctx.WriteString(" WHERE ")
 		ctx.FormatNode(node.Predicate)
 	}
 }
 
+// ExclusionConstraintTableDef represents an EXCLUDE constraint within a CREATE
+// TABLE statement. The constraint guarantees that no two rows of the table
+// match each other on all of its elements, as compared by the operator of each
+// element.
+type ExclusionConstraintTableDef struct {
+	Name Name
+	// AccessMethod is the access method named in USING, or empty if omitted.
+	AccessMethod Name
+	Elems        ExclusionConstraintElemList
+	Predicate    Expr
+	IfNotExists  bool
+}
+
+// SetName implements the ConstraintTableDef interface.
+func (node *ExclusionConstraintTableDef) SetName(name Name) {
+	node.Name = name
+}
+
+// SetIfNotExists implements the ConstraintTableDef interface.
+func (node *ExclusionConstraintTableDef) SetIfNotExists() {
+	node.IfNotExists = true
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExclusionConstraintTableDef) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.WriteString("CONSTRAINT ")
+		if node.IfNotExists {
+			ctx.WriteString("IF NOT EXISTS ")
+		}
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.WriteString("EXCLUDE ")
+	if node.AccessMethod != "" {
+		// The access method is a keyword-like name, so it is not anonymized.
+		ctx.WriteString("USING ")
+		ctx.WriteString(string(node.AccessMethod))
+		ctx.WriteByte(' ')
+	}
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Elems)
+	ctx.WriteByte(')')
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE (")
+		ctx.FormatNode(node.Predicate)
+		ctx.WriteByte(')')
+	}
+}
+
+// ExclusionConstraintElem is an element of an EXCLUDE constraint, which is
+// compared with the same element of other rows using Operator.
+type ExclusionConstraintElem struct {
+	IndexElem
+	Operator treecmp.ComparisonOperator
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExclusionConstraintElem) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&node.IndexElem)
+	ctx.WriteString(" WITH ")
+	ctx.WriteString(node.Operator.String())
+}
+
+// ExclusionConstraintElemList is list of ExclusionConstraintElem.
+type ExclusionConstraintElemList []ExclusionConstraintElem
+
+// Format pretty-prints the contained elements separated by commas.
+func (l *ExclusionConstraintElemList) Format(ctx *FmtCtx) {
+	for i := range *l {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*l)[i])
+	}
+}
+
 // ReferenceAction is the method used to maintain referential integrity through
 // foreign keys.
 type ReferenceAction int
@@ -1139,6 +1322,37 @@ func (c CompositeKeyMatchMethod) String() string {
 	return compositeKeyMatchMethodName[c]
 }
 
+// ConstraintDeferrability specifies whether the checks of a constraint can be
+// deferred to the end of the transaction (DEFERRABLE), and whether they are
+// deferred by default (INITIALLY DEFERRED). See SET CONSTRAINTS.
+type ConstraintDeferrability int
+
+// The values for ConstraintDeferrability.
+const (
+	NotDeferrableConstraint ConstraintDeferrability = iota
+	DeferrableInitiallyImmediate
+	DeferrableInitiallyDeferred
+)
+
+var constraintDeferrabilityName = [...]string{
+	NotDeferrableConstraint:      "NOT DEFERRABLE",
+	DeferrableInitiallyImmediate: "DEFERRABLE",
+	DeferrableInitiallyDeferred:  "DEFERRABLE INITIALLY DEFERRED",
+}
+
+func (d ConstraintDeferrability) String() string {
+	return constraintDeferrabilityName[d]
+}
+
+// Format implements the NodeFormatter interface. Nothing is printed for a
+// constraint which is not deferrable.
+func (d *ConstraintDeferrability) Format(ctx *FmtCtx) {
+	if *d != NotDeferrableConstraint {
+		ctx.WriteByte(' ')
+		ctx.WriteString(d.String())
+	}
+}
+
 // ForeignKeyConstraintTableDef represents a FOREIGN KEY constraint in the AST.
 type ForeignKeyConstraintTableDef struct {
 	Name        Name
@@ -1147,6 +1361,7 @@ type ForeignKeyConstraintTableDef struct {
 	ToCols      NameList
 	Actions     ReferenceActions
 	Match       CompositeKeyMatchMethod
+	Deferrable  ConstraintDeferrability
 	IfNotExists bool
 }
 
@@ -1178,6 +1393,7 @@ func (node *ForeignKeyConstraintTableDef) Format(ctx *FmtCtx) {
 	}
 
 	ctx.FormatNode(&node.Actions)
+	ctx.FormatNode(&node.Deferrable)
 }
 
 // SetName implements the ConstraintTableDef interface.
@@ -1586,12 +1802,13 @@ func (node *CreateTable) HoistConstraints() {
 					targetCol = append(targetCol, col.References.Col)
 				}
 				node.Defs = append(node.Defs, &ForeignKeyConstraintTableDef{
-					Table:    *col.References.Table,
-					FromCols: NameList{col.Name},
-					ToCols:   targetCol,
-					Name:     col.References.ConstraintName,
-					Actions:  col.References.Actions,
-					Match:    col.References.Match,
+					Table:      *col.References.Table,
+					FromCols:   NameList{col.Name},
+					ToCols:     targetCol,
+					Name:       col.References.ConstraintName,
+					Actions:    col.References.Actions,
+					Match:      col.References.Match,
+					Deferrable: col.References.Deferrable,
 				})
 				col.References.Table = nil
 			}
@@ -0,0 +1,320 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/errors"
+)
+
+// FunctionName represents a function name in a UDF statement like
+// CREATE FUNCTION or DROP FUNCTION.
+type FunctionName struct {
+	objName
+}
+
+// MakeFunctionNameFromPrefix returns a FunctionName with the given prefix
+// and function name.
+func MakeFunctionNameFromPrefix(prefix ObjectNamePrefix, object Name) FunctionName {
+	return FunctionName{objName{
+		ObjectName:       object,
+		ObjectNamePrefix: prefix,
+	}}
+}
+
+// MakeQualifiedFunctionName constructs a fully qualified function name.
+func MakeQualifiedFunctionName(db string, sc string, fn string) FunctionName {
+	return MakeFunctionNameFromPrefix(ObjectNamePrefix{
+		CatalogName:     Name(db),
+		ExplicitCatalog: true,
+		SchemaName:      Name(sc),
+		ExplicitSchema:  true,
+	}, Name(fn))
+}
+
+// Format implements the NodeFormatter interface.
+func (f *FunctionName) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&f.ObjectNamePrefix)
+	if f.ExplicitSchema || ctx.alwaysFormatTablePrefix() {
+		ctx.WriteByte('.')
+	}
+	ctx.FormatNode(&f.ObjectName)
+}
+
+func (f *FunctionName) String() string { return AsString(f) }
+
+// FQString renders the function name in full, not omitting the prefix
+// schema and catalog names. Suitable for logging, etc.
+func (f *FunctionName) FQString() string {
+	ctx := NewFmtCtx(FmtSimple)
+	ctx.FormatNode(&f.CatalogName)
+	ctx.WriteByte('.')
+	ctx.FormatNode(&f.SchemaName)
+	ctx.WriteByte('.')
+	ctx.FormatNode(&f.ObjectName)
+	return ctx.CloseAndGetString()
+}
+
+func (f *FunctionName) objectName() {}
+
+// CreateFunction represents a CREATE FUNCTION statement.
+type CreateFunction struct {
+	Replace    bool
+	FuncName   FunctionName
+	Args       FuncArgs
+	ReturnType FuncReturnType
+	Options    FunctionOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE ")
+	if node.Replace {
+		ctx.WriteString("OR REPLACE ")
+	}
+	ctx.WriteString("FUNCTION ")
+	ctx.FormatNode(&node.FuncName)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Args)
+	ctx.WriteString(") ")
+	ctx.WriteString("RETURNS ")
+	if node.ReturnType.IsSet {
+		ctx.WriteString("SETOF ")
+	}
+	ctx.FormatTypeReference(node.ReturnType.Type)
+	for _, option := range node.Options {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(option)
+	}
+}
+
+// FuncArgClass indicates what type of argument an arg is.
+type FuncArgClass int
+
+const (
+	// FunctionArgIn args can only be used as input.
+	FunctionArgIn FuncArgClass = iota
+	// FunctionArgOut args can only be used as output.
+	FunctionArgOut
+	// FunctionArgInOut args can be used as both input and output.
+	FunctionArgInOut
+	// FunctionArgVariadic args are variadic.
+	FunctionArgVariadic
+)
+
+// FuncArg represents an argument from a UDF signature.
+type FuncArg struct {
+	Name  Name
+	Type  ResolvableTypeReference
+	Class FuncArgClass
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArg) Format(ctx *FmtCtx) {
+	switch node.Class {
+	case FunctionArgOut:
+		ctx.WriteString("OUT ")
+	case FunctionArgInOut:
+		ctx.WriteString("IN OUT ")
+	case FunctionArgVariadic:
+		ctx.WriteString("VARIADIC ")
+	}
+	if node.Name != "" {
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.FormatTypeReference(node.Type)
+}
+
+// FuncArgs represents a list of FuncArg.
+type FuncArgs []FuncArg
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArgs) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// FuncReturnType represent the return type of UDF.
+type FuncReturnType struct {
+	Type  ResolvableTypeReference
+	IsSet bool
+}
+
+// FunctionOption is an interface representing UDF properties.
+type FunctionOption interface {
+	functionOption()
+	NodeFormatter
+}
+
+func (FunctionNullInputBehavior) functionOption() {}
+func (FunctionVolatility) functionOption()        {}
+func (FunctionLeakproof) functionOption()         {}
+func (FunctionBodyStr) functionOption()           {}
+func (FunctionLanguage) functionOption()          {}
+
+// FunctionNullInputBehavior represent the UDF property on null parameters.
+type FunctionNullInputBehavior int
+
+const (
+	// FunctionCalledOnNullInput indicates that the function will be given the
+	// chance to execute when presented with NULL input. This is the default if
+	// no null input behavior is specified.
+	FunctionCalledOnNullInput FunctionNullInputBehavior = iota
+	// FunctionReturnsNullOnNullInput indicates that the function will result in
+	// NULL given any NULL parameter.
+	FunctionReturnsNullOnNullInput
+	// FunctionStrict is the same as FunctionReturnsNullOnNullInput
+	FunctionStrict
+)
+
+// Format implements the NodeFormatter interface.
+func (node FunctionNullInputBehavior) Format(ctx *FmtCtx) {
+	switch node {
+	case FunctionCalledOnNullInput:
+		ctx.WriteString("CALLED ON NULL INPUT")
+	case FunctionReturnsNullOnNullInput:
+		ctx.WriteString("RETURNS NULL ON NULL INPUT")
+	case FunctionStrict:
+		ctx.WriteString("STRICT")
+	default:
+		panic(errors.AssertionFailedf("Unknown function option %d", node))
+	}
+}
+
+// FunctionVolatility represent UDF volatility property.
+type FunctionVolatility int
+
+const (
+	// FunctionVolatile represents volatility.Volatile. This is the default
+	// volatility if none is provided.
+	FunctionVolatile FunctionVolatility = iota
+	// FunctionImmutable represents volatility.Immutable.
+	FunctionImmutable
+	// FunctionStable represents volatility.Stable.
+	FunctionStable
+)
+
+// Format implements the NodeFormatter interface.
+func (node FunctionVolatility) Format(ctx *FmtCtx) {
+	switch node {
+	case FunctionVolatile:
+		ctx.WriteString("VOLATILE")
+	case FunctionImmutable:
+		ctx.WriteString("IMMUTABLE")
+	case FunctionStable:
+		ctx.WriteString("STABLE")
+	default:
+		panic(errors.AssertionFailedf("Unknown function option %d", node))
+	}
+}
+
+// FunctionLeakproof indicates whether if a UDF is leakproof or not.
+type FunctionLeakproof bool
+
+// Format implements the NodeFormatter interface.
+func (node FunctionLeakproof) Format(ctx *FmtCtx) {
+	if !node {
+		ctx.WriteString("NOT ")
+	}
+	ctx.WriteString("LEAKPROOF")
+}
+
+// FunctionLanguage indicates the language of the statements in the UDF function
+// body.
+type FunctionLanguage int
+
+const (
+	_ FunctionLanguage = iota
+	// FunctionLangSQL represent SQL language.
+	FunctionLangSQL
+)
+
+// Format implements the NodeFormatter interface.
+func (node FunctionLanguage) Format(ctx *FmtCtx) {
+	ctx.WriteString("LANGUAGE ")
+	switch node {
+	case FunctionLangSQL:
+		ctx.WriteString("SQL")
+	default:
+		panic(errors.AssertionFailedf("Unknown function option %d", node))
+	}
+}
+
+// AsFunctionLanguage converts a string to a FunctionLanguage if applicable.
+// Error is returned if string does not represent a valid UDF language.
+func AsFunctionLanguage(lang string) (FunctionLanguage, error) {
+	switch lang {
+	case "sql":
+		return FunctionLangSQL, nil
+	}
+	return 0, errors.Newf("language %q does not exist", lang)
+}
+
+// FunctionBodyStr is a string containing all statements in a UDF body.
+type FunctionBodyStr string
+
+// Format implements the NodeFormatter interface.
+func (node FunctionBodyStr) Format(ctx *FmtCtx) {
+	ctx.WriteString("AS ")
+	if ctx.flags.HasFlags(FmtHideConstants) {
+		ctx.WriteString("'_'")
+		return
+	}
+	lexbase.EncodeSQLStringWithFlags(&ctx.Buffer, string(node), ctx.flags.EncodeFlags())
+}
+
+// FunctionOptions represent a list of function options.
+type FunctionOptions []FunctionOption
+
+// Format implements the NodeFormatter interface.
+func (node *FunctionOptions) Format(ctx *FmtCtx) {
+	for i, option := range *node {
+		if i > 0 {
+			ctx.WriteByte(' ')
+		}
+		ctx.FormatNode(option)
+	}
+}
+
+// FuncObj represents a function object DROP FUNCTION, ALTER FUNCTION and
+// other statements are trying to operate on.
+type FuncObj struct {
+	FuncName FunctionName
+	Args     FuncArgs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FuncObj) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&node.FuncName)
+	if node.Args != nil {
+		ctx.WriteByte('(')
+		ctx.FormatNode(&node.Args)
+		ctx.WriteByte(')')
+	}
+}
+
+// FuncObjs is a slice of FuncObj.
+type FuncObjs []FuncObj
+
+// Format implements the NodeFormatter interface.
+func (node *FuncObjs) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
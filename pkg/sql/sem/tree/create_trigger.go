@@ -0,0 +1,112 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// TriggerActionTime specifies whether a trigger fires before or after the
+// row is modified.
+type TriggerActionTime int
+
+// TriggerActionTime values.
+const (
+	TriggerActionTimeBefore TriggerActionTime = iota
+	TriggerActionTimeAfter
+)
+
+var triggerActionTimeName = [...]string{
+	TriggerActionTimeBefore: "BEFORE",
+	TriggerActionTimeAfter:  "AFTER",
+}
+
+func (t TriggerActionTime) String() string {
+	return triggerActionTimeName[t]
+}
+
+// TriggerEvent is a kind of statement which fires a trigger.
+type TriggerEvent int
+
+// TriggerEvent values.
+const (
+	TriggerEventInsert TriggerEvent = iota
+	TriggerEventUpdate
+	TriggerEventDelete
+)
+
+var triggerEventName = [...]string{
+	TriggerEventInsert: "INSERT",
+	TriggerEventUpdate: "UPDATE",
+	TriggerEventDelete: "DELETE",
+}
+
+func (e TriggerEvent) String() string {
+	return triggerEventName[e]
+}
+
+// TriggerEvents is a list of trigger events.
+type TriggerEvents []TriggerEvent
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerEvents) Format(ctx *FmtCtx) {
+	for i, e := range *node {
+		if i > 0 {
+			ctx.WriteString(" OR ")
+		}
+		ctx.WriteString(e.String())
+	}
+}
+
+// Contains returns true if the list contains the given event.
+func (node TriggerEvents) Contains(e TriggerEvent) bool {
+	for _, ev := range node {
+		if ev == e {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateTrigger represents a CREATE TRIGGER statement.
+//
+// Only row-level triggers are supported. Unlike in Postgres, the arguments of
+// the function are arbitrary expressions rather than string literals, and they
+// can refer to the columns of the modified row as OLD.<column> and
+// NEW.<column>, like the WHEN condition.
+type CreateTrigger struct {
+	Name       Name
+	ActionTime TriggerActionTime
+	Events     TriggerEvents
+	Table      TableName
+	When       Expr
+	FuncName   FunctionName
+	Args       Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE TRIGGER ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteByte(' ')
+	ctx.WriteString(node.ActionTime.String())
+	ctx.WriteByte(' ')
+	ctx.FormatNode(&node.Events)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(&node.Table)
+	ctx.WriteString(" FOR EACH ROW")
+	if node.When != nil {
+		ctx.WriteString(" WHEN (")
+		ctx.FormatNode(node.When)
+		ctx.WriteByte(')')
+	}
+	ctx.WriteString(" EXECUTE FUNCTION ")
+	ctx.FormatNode(&node.FuncName)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Args)
+	ctx.WriteByte(')')
+}
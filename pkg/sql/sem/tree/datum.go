@@ -25,6 +25,7 @@ import (
 	"unsafe"
 
 	"github.com/cockroachdb/apd/v3"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/geo"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
@@ -38,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/stringencoding"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timetz"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -3564,15 +3566,32 @@ func AsJSON(
 	case *DJSON:
 		return t.JSON, nil
 	case *DArray:
-		builder := json.NewArrayBuilder(t.Len())
-		for _, e := range t.Array {
-			j, err := AsJSON(e, dcc, loc)
-			if err != nil {
-				return nil, err
+		dims := t.Dimensions()
+		idx := 0
+		// Multidimensional arrays are converted to nested JSON arrays.
+		var dimAsJSON func(level int) (json.JSON, error)
+		dimAsJSON = func(level int) (json.JSON, error) {
+			builder := json.NewArrayBuilder(dims[level].Length)
+			for i := 0; i < dims[level].Length; i++ {
+				var j json.JSON
+				var err error
+				if level == len(dims)-1 {
+					j, err = AsJSON(t.Array[idx], dcc, loc)
+					idx++
+				} else {
+					j, err = dimAsJSON(level + 1)
+				}
+				if err != nil {
+					return nil, err
+				}
+				builder.Add(j)
 			}
-			builder.Add(j)
+			return builder.Build(), nil
 		}
-		return builder.Build(), nil
+		if len(dims) == 0 {
+			return json.NewArrayBuilder(0).Build(), nil
+		}
+		return dimAsJSON(0)
 	case *DTuple:
 		builder := json.NewObjectBuilder(len(t.D))
 		// We need to make sure that t.typ is initialized before getting the tuple
@@ -4176,11 +4195,52 @@ type DArray struct {
 	// HasNonNulls is set to true if any of the datums within the are non-null.
 	// This is used in expression serialization (FmtParsable).
 	HasNonNulls bool
+	// Dims, if non-nil, describes the dimensions of the array, outermost first,
+	// and Array holds the elements in row-major order. A nil Dims describes the
+	// common case of an empty array or a one-dimensional array starting at
+	// FirstIndex. Use SetDims to change it, which keeps it normalized.
+	Dims []ArrayDim
 
 	// customOid, if non-0, is the oid of this array datum.
 	customOid oid.Oid
 }
 
+// ArrayDim describes one dimension of a DArray.
+type ArrayDim struct {
+	// Length is the number of elements along the dimension.
+	Length int
+	// LowerBound is the subscript of the first element along the dimension.
+	LowerBound int
+}
+
+// MaxArrayDims is the maximum number of dimensions an array may have. It
+// matches the limit in Postgres.
+const MaxArrayDims = 6
+
+// arrayDimsEncodingDisabled is set while the cluster may contain nodes which
+// are unable to decode the encodings of arrays with Dims.
+var arrayDimsEncodingDisabled syncutil.AtomicBool
+
+// SetArrayDimsEncodingEnabled sets whether arrays with multiple dimensions or
+// a non-default lower bound can be key or value encoded. The server disables
+// their encodings until the upgrade to clusterversion.MultidimensionalArrays
+// is finalized.
+func SetArrayDimsEncodingEnabled(enabled bool) {
+	arrayDimsEncodingDisabled.Set(!enabled)
+}
+
+// CheckDimsEncodable returns an error if the array has multiple dimensions or
+// a non-default lower bound while the encodings of such arrays are disabled.
+// It must be checked by all the key and value encodings of arrays.
+func (d *DArray) CheckDimsEncodable() error {
+	if d.Dims != nil && arrayDimsEncodingDisabled.Get() {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"arrays with multiple dimensions or a non-default lower bound cannot be encoded "+
+				"until upgrade to version %s is finalized", clusterversion.MultidimensionalArrays.String())
+	}
+	return nil
+}
+
 // NewDArray returns a DArray containing elements of the specified type.
 func NewDArray(paramTyp *types.T) *DArray {
 	return &DArray{ParamTyp: paramTyp}
@@ -4259,6 +4319,125 @@ func (d *DArray) FirstIndex() int {
 	return 1
 }
 
+// NumDims returns the number of dimensions of the array. Empty arrays have no
+// dimensions.
+func (d *DArray) NumDims() int {
+	if d.Dims != nil {
+		return len(d.Dims)
+	}
+	if d.Len() == 0 {
+		return 0
+	}
+	return 1
+}
+
+// Dimensions returns the dimensions of the array, outermost first. The result
+// must not be modified.
+func (d *DArray) Dimensions() []ArrayDim {
+	if d.Dims != nil {
+		return d.Dims
+	}
+	if d.Len() == 0 {
+		return nil
+	}
+	return []ArrayDim{{Length: d.Len(), LowerBound: d.FirstIndex()}}
+}
+
+// SetDims sets the dimensions of the array, which must be consistent with the
+// number of elements it holds. Passing nil resets the array to the default
+// shape.
+func (d *DArray) SetDims(dims []ArrayDim) error {
+	if dims == nil {
+		d.Dims = nil
+		return nil
+	}
+	if len(dims) > MaxArrayDims {
+		return pgerror.Newf(pgcode.ProgramLimitExceeded,
+			"number of array dimensions (%d) exceeds the maximum allowed (%d)", len(dims), MaxArrayDims)
+	}
+	n := 1
+	for _, dim := range dims {
+		if dim.Length < 0 {
+			return pgerror.New(pgcode.InvalidParameterValue, "array dimensions must not be negative")
+		}
+		if int64(dim.LowerBound)+int64(dim.Length) > math.MaxInt32 {
+			return pgerror.New(pgcode.ProgramLimitExceeded, "array upper bound is too large")
+		}
+		n *= dim.Length
+		if n > maxArrayLength {
+			return errors.WithStack(errArrayTooLongError)
+		}
+	}
+	if len(dims) == 0 {
+		n = 0
+	}
+	if n != d.Len() {
+		return errors.AssertionFailedf(
+			"array dimensions describe %d elements, but the array has %d", n, d.Len())
+	}
+	if n == 0 || (len(dims) == 1 && dims[0].LowerBound == d.FirstIndex()) {
+		d.Dims = nil
+	} else {
+		d.Dims = dims
+	}
+	return nil
+}
+
+// hasDefaultLowerBounds returns whether every dimension of the array starts at
+// FirstIndex.
+func (d *DArray) hasDefaultLowerBounds() bool {
+	for _, dim := range d.Dims {
+		if dim.LowerBound != d.FirstIndex() {
+			return false
+		}
+	}
+	return true
+}
+
+// compareArrayDims orders arrays by their shape. Arrays with the default shape
+// sort before all others, which are then ordered by their number of
+// dimensions followed by the length and lower bound of each dimension. This
+// must be kept in sync with the key encoding of arrays.
+//
+// Note that this differs from Postgres, whose array_cmp compares the elements
+// first and only uses the shape to break ties. Arrays with the default shape
+// predate multidimensional arrays, and their key encoding is a marker followed
+// by the elements and a terminator, with nothing to distinguish a trailing
+// shape from the encoding of the next key column. The shape of the other
+// arrays therefore has to be encoded before their elements, which makes it the
+// most significant part of the order.
+func compareArrayDims(a, b []ArrayDim) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	for i := range a {
+		if a[i].Length != b[i].Length {
+			if a[i].Length < b[i].Length {
+				return -1
+			}
+			return 1
+		}
+		if a[i].LowerBound != b[i].LowerBound {
+			if a[i].LowerBound < b[i].LowerBound {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
 // Compare implements the Datum interface.
 func (d *DArray) Compare(ctx *EvalContext, other Datum) int {
 	res, err := d.CompareError(ctx, other)
@@ -4278,6 +4457,9 @@ func (d *DArray) CompareError(ctx *EvalContext, other Datum) (int, error) {
 	if !ok {
 		return 0, makeUnsupportedComparisonMessage(d, other)
 	}
+	if c := compareArrayDims(d.Dims, v.Dims); c != 0 {
+		return c, nil
+	}
 	n := d.Len()
 	if n > v.Len() {
 		n = v.Len()
@@ -4307,6 +4489,9 @@ func (d *DArray) Prev(_ *EvalContext) (Datum, bool) {
 
 // Next implements the Datum interface.
 func (d *DArray) Next(_ *EvalContext) (Datum, bool) {
+	if d.Dims != nil {
+		return nil, false
+	}
 	a := DArray{ParamTyp: d.ParamTyp, Array: make(Datums, d.Len()+1)}
 	copy(a.Array, d.Array)
 	a.Array[len(a.Array)-1] = DNull
@@ -4343,6 +4528,10 @@ func (d *DArray) AmbiguousFormat() bool {
 		// a valid type. So an array of unknown type is (paradoxically) unambiguous.
 		return false
 	}
+	if !d.hasDefaultLowerBounds() {
+		// Arrays with custom lower bounds are formatted as strings.
+		return true
+	}
 	return !d.HasNonNulls
 }
 
@@ -4361,14 +4550,45 @@ func (d *DArray) Format(ctx *FmtCtx) {
 		defer func() { ctx.flags = oldFlags }()
 	}
 
-	ctx.WriteString("ARRAY[")
-	comma := ""
-	for _, v := range d.Array {
-		ctx.WriteString(comma)
-		ctx.FormatNode(v)
-		comma = ","
+	if !d.hasDefaultLowerBounds() {
+		// An ARRAY constructor cannot express custom lower bounds, so use the
+		// text representation of the array instead.
+		lexbase.EncodeSQLStringWithFlags(
+			&ctx.Buffer, AsStringWithFlags(d, FmtPgwireText), ctx.flags.EncodeFlags(),
+		)
+		return
+	}
+
+	d.formatElements(ctx, "ARRAY[", "]", func(v Datum) { ctx.FormatNode(v) })
+}
+
+// formatElements formats the elements of the array using formatElem, nesting
+// them by dimension between the open and close strings.
+func (d *DArray) formatElements(ctx *FmtCtx, open, close string, formatElem func(Datum)) {
+	dims := d.Dimensions()
+	if len(dims) == 0 {
+		ctx.WriteString(open)
+		ctx.WriteString(close)
+		return
 	}
-	ctx.WriteByte(']')
+	idx := 0
+	var formatDim func(level int)
+	formatDim = func(level int) {
+		ctx.WriteString(open)
+		for i := 0; i < dims[level].Length; i++ {
+			if i > 0 {
+				ctx.WriteByte(',')
+			}
+			if level == len(dims)-1 {
+				formatElem(d.Array[idx])
+				idx++
+			} else {
+				formatDim(level + 1)
+			}
+		}
+		ctx.WriteString(close)
+	}
+	formatDim(0)
 }
 
 const maxArrayLength = math.MaxInt32
@@ -4391,7 +4611,7 @@ func (d *DArray) Len() int {
 
 // Size implements the Datum interface.
 func (d *DArray) Size() uintptr {
-	sz := unsafe.Sizeof(*d)
+	sz := unsafe.Sizeof(*d) + uintptr(len(d.Dims))*unsafe.Sizeof(ArrayDim{})
 	for _, e := range d.Array {
 		dsz := e.Size()
 		sz += dsz
@@ -4412,6 +4632,9 @@ func (d *DArray) Append(v Datum) error {
 	if d.Len() >= maxArrayLength {
 		return errors.WithStack(errArrayTooLongError)
 	}
+	if len(d.Dims) > 1 {
+		return pgerror.New(pgcode.DataException, "argument must be empty or one-dimensional array")
+	}
 	if d.ParamTyp.Family() == types.ArrayFamily {
 		if v == DNull {
 			return errNonHomogeneousArray
@@ -4433,6 +4656,9 @@ func (d *DArray) Append(v Datum) error {
 		d.HasNonNulls = true
 	}
 	d.Array = append(d.Array, v)
+	if d.Dims != nil {
+		d.Dims = []ArrayDim{{Length: d.Dims[0].Length + 1, LowerBound: d.Dims[0].LowerBound}}
+	}
 	return d.Validate()
 }
 
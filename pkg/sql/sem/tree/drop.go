@@ -188,13 +188,19 @@ type DropType struct {
 	Names        []*UnresolvedObjectName
 	IfExists     bool
 	DropBehavior DropBehavior
+	// Domain is true if this represents a DROP DOMAIN statement.
+	Domain bool
 }
 
 var _ Statement = &DropType{}
 
 // Format implements the NodeFormatter interface.
 func (node *DropType) Format(ctx *FmtCtx) {
-	ctx.WriteString("DROP TYPE ")
+	if node.Domain {
+		ctx.WriteString("DROP DOMAIN ")
+	} else {
+		ctx.WriteString("DROP TYPE ")
+	}
 	if node.IfExists {
 		ctx.WriteString("IF EXISTS ")
 	}
@@ -231,3 +237,46 @@ func (node *DropSchema) Format(ctx *FmtCtx) {
 		ctx.WriteString(node.DropBehavior.String())
 	}
 }
+
+// DropFunction represents a DROP FUNCTION statement.
+type DropFunction struct {
+	Functions    FuncObjs
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP FUNCTION ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Functions)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteString(" ")
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
+
+// DropTrigger represents a DROP TRIGGER statement.
+type DropTrigger struct {
+	Name         Name
+	Table        TableName
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP TRIGGER ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(&node.Table)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/geo"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -332,7 +333,11 @@ func (op *BinOp) preferred() bool {
 func AppendToMaybeNullArray(typ *types.T, left Datum, right Datum) (Datum, error) {
 	result := NewDArray(typ)
 	if left != DNull {
-		for _, e := range MustBeDArray(left).Array {
+		arr := MustBeDArray(left)
+		if len(arr.Dims) > 1 {
+			return nil, errOneDimensionalArrayRequired
+		}
+		for _, e := range arr.Array {
 			if err := result.Append(e); err != nil {
 				return nil, err
 			}
@@ -352,7 +357,11 @@ func PrependToMaybeNullArray(typ *types.T, left Datum, right Datum) (Datum, erro
 		return nil, err
 	}
 	if right != DNull {
-		for _, e := range MustBeDArray(right).Array {
+		arr := MustBeDArray(right)
+		if len(arr.Dims) > 1 {
+			return nil, errOneDimensionalArrayRequired
+		}
+		for _, e := range arr.Array {
 			if err := result.Append(e); err != nil {
 				return nil, err
 			}
@@ -361,6 +370,10 @@ func PrependToMaybeNullArray(typ *types.T, left Datum, right Datum) (Datum, erro
 	return result, nil
 }
 
+var errOneDimensionalArrayRequired = pgerror.New(pgcode.DataException, "argument must be empty or one-dimensional array")
+
+var errIncompatibleArrayConcatenation = pgerror.New(pgcode.ArraySubscript, "cannot concatenate incompatible arrays")
+
 // TODO(justin): these might be improved by making arrays into an interface and
 // then introducing a ConcatenatedArray implementation which just references two
 // existing arrays. This would optimize the common case of appending an element
@@ -392,26 +405,76 @@ func initArrayElementConcatenation() {
 	}
 }
 
-// ConcatArrays concatenates two arrays.
+// ConcatArrays concatenates two arrays. As in Postgres, multidimensional
+// arrays are concatenated along their first dimension, and an array may also
+// be concatenated with an array of one fewer dimension, which is added to it as
+// a new sub-array.
 func ConcatArrays(typ *types.T, left Datum, right Datum) (Datum, error) {
 	if left == DNull && right == DNull {
 		return DNull, nil
 	}
 	result := NewDArray(typ)
+	var leftDims, rightDims []ArrayDim
 	if left != DNull {
-		for _, e := range MustBeDArray(left).Array {
+		arr := MustBeDArray(left)
+		leftDims = arr.Dimensions()
+		for _, e := range arr.Array {
 			if err := result.Append(e); err != nil {
 				return nil, err
 			}
 		}
 	}
 	if right != DNull {
-		for _, e := range MustBeDArray(right).Array {
+		arr := MustBeDArray(right)
+		rightDims = arr.Dimensions()
+		for _, e := range arr.Array {
 			if err := result.Append(e); err != nil {
 				return nil, err
 			}
 		}
 	}
+
+	var dims []ArrayDim
+	sameLengths := func(a, b []ArrayDim) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i].Length != b[i].Length {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case len(leftDims) == 0:
+		dims = rightDims
+	case len(rightDims) == 0:
+		dims = leftDims
+	case len(leftDims) == len(rightDims):
+		if !sameLengths(leftDims[1:], rightDims[1:]) {
+			return nil, errIncompatibleArrayConcatenation
+		}
+		dims = append([]ArrayDim(nil), leftDims...)
+		dims[0].Length += rightDims[0].Length
+	case len(leftDims) == len(rightDims)+1:
+		if !sameLengths(leftDims[1:], rightDims) {
+			return nil, errIncompatibleArrayConcatenation
+		}
+		dims = append([]ArrayDim(nil), leftDims...)
+		dims[0].Length++
+	case len(leftDims)+1 == len(rightDims):
+		if !sameLengths(leftDims, rightDims[1:]) {
+			return nil, errIncompatibleArrayConcatenation
+		}
+		dims = append([]ArrayDim(nil), rightDims...)
+		dims[0].Length++
+	default:
+		return nil, errIncompatibleArrayConcatenation
+	}
+	if err := result.SetDims(dims); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -3339,6 +3402,14 @@ type EvalPlanner interface {
 	// it is invalid.
 	RepairTTLScheduledJobForTable(ctx context.Context, tableID int64) error
 
+	// Notify sends a notification on the given channel when the current
+	// transaction commits, like the NOTIFY statement.
+	Notify(ctx context.Context, channel, payload string) error
+
+	// ListeningChannels returns the names of the channels the session is
+	// listening on.
+	ListeningChannels() []string
+
 	// QueryRowEx executes the supplied SQL statement and returns a single row, or
 	// nil if no row is found, or an error if more that one row is returned.
 	//
@@ -3909,6 +3980,15 @@ func (ctx *EvalContext) GetClusterTimestamp() (*DDecimal, error) {
 	return TimestampToDecimalDatum(ts), nil
 }
 
+// TxnIsoLevel returns the isolation level of the transaction of the
+// evaluation context, or serializable if there is no transaction.
+func (ctx *EvalContext) TxnIsoLevel() isolation.Level {
+	if ctx.Txn == nil {
+		return isolation.Serializable
+	}
+	return ctx.Txn.IsoLevel()
+}
+
 // HasPlaceholders returns true if this EvalContext's placeholders have been
 // assigned. Will be false during Prepare.
 func (ctx *EvalContext) HasPlaceholders() bool {
@@ -4213,8 +4293,9 @@ func (expr *CastExpr) Eval(ctx *EvalContext) (Datum, error) {
 		return nil, err
 	}
 
-	// NULL cast to anything is NULL.
-	if d == DNull {
+	// NULL cast to anything is NULL, unless the domain it is cast to does not
+	// allow NULL values.
+	if d == DNull && !expr.ResolvedType().IsDomain() {
 		return d, nil
 	}
 	d = UnwrapDatum(ctx, d)
@@ -4223,20 +4304,37 @@ func (expr *CastExpr) Eval(ctx *EvalContext) (Datum, error) {
 
 // Eval implements the TypedExpr interface.
 func (expr *IndirectionExpr) Eval(ctx *EvalContext) (Datum, error) {
-	var subscriptIdx int
+	slice := expr.Indirection.IsSlice()
+	// lower holds the subscripts for element access, and the lower bounds of
+	// slices. Omitted bounds are clamped to those of the array below.
+	lower := make([]int64, len(expr.Indirection))
+	upper := make([]int64, len(expr.Indirection))
 	for i, t := range expr.Indirection {
-		if t.Slice || i > 0 {
-			return nil, errors.AssertionFailedf("unsupported feature should have been rejected during planning")
+		lower[i], upper[i] = math.MinInt64, math.MaxInt64
+		if t.Begin != nil {
+			d, err := t.Begin.(TypedExpr).Eval(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if d == DNull {
+				return d, nil
+			}
+			lower[i] = int64(MustBeDInt(d))
 		}
-
-		d, err := t.Begin.(TypedExpr).Eval(ctx)
-		if err != nil {
-			return nil, err
+		if slice && !t.Slice {
+			// A subscript without a colon is treated as a slice starting at 1.
+			lower[i], upper[i] = 1, lower[i]
 		}
-		if d == DNull {
-			return d, nil
+		if t.End != nil {
+			d, err := t.End.(TypedExpr).Eval(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if d == DNull {
+				return d, nil
+			}
+			upper[i] = int64(MustBeDInt(d))
 		}
-		subscriptIdx = int(MustBeDInt(d))
 	}
 
 	d, err := expr.Expr.(TypedExpr).Eval(ctx)
@@ -4246,19 +4344,94 @@ func (expr *IndirectionExpr) Eval(ctx *EvalContext) (Datum, error) {
 	if d == DNull {
 		return d, nil
 	}
-
-	// Index into the DArray, using 1-indexing.
 	arr := MustBeDArray(d)
+	if slice {
+		return arraySlice(arr, lower, upper)
+	}
+	return arrayElement(arr, lower), nil
+}
 
-	// VECTOR types use 0-indexing.
-	switch arr.customOid {
-	case oid.T_oidvector, oid.T_int2vector:
-		subscriptIdx++
+// arrayElement returns the element of the array with the given subscripts, or
+// NULL if the subscripts are out of the bounds of the array, or if there is not
+// one subscript per dimension.
+func arrayElement(arr *DArray, subscripts []int64) Datum {
+	dims := arr.Dimensions()
+	if len(subscripts) != len(dims) {
+		return DNull
 	}
-	if subscriptIdx < 1 || subscriptIdx > arr.Len() {
-		return DNull, nil
+	offset := 0
+	for i, dim := range dims {
+		idx := subscripts[i] - int64(dim.LowerBound)
+		if idx < 0 || idx >= int64(dim.Length) {
+			return DNull
+		}
+		offset = offset*dim.Length + int(idx)
+	}
+	return arr.Array[offset]
+}
+
+// arraySlice returns the part of the array within the given bounds of each
+// dimension. Bounds are clamped to those of the array, and dimensions without
+// bounds are included in full. The result is empty if the bounds do not
+// overlap the array, and otherwise its dimensions start at the first index.
+func arraySlice(arr *DArray, lower, upper []int64) (*DArray, error) {
+	result := NewDArray(arr.ParamTyp)
+	result.customOid = arr.customOid
+	dims := arr.Dimensions()
+	if len(lower) > len(dims) {
+		return result, nil
+	}
+	sliceDims := make([]ArrayDim, len(dims))
+	starts := make([]int, len(dims))
+	for i, dim := range dims {
+		lo, hi := int64(dim.LowerBound), int64(dim.LowerBound)+int64(dim.Length)-1
+		if i < len(lower) {
+			if lower[i] > lo {
+				lo = lower[i]
+			}
+			if upper[i] < hi {
+				hi = upper[i]
+			}
+		}
+		if lo > hi {
+			return result, nil
+		}
+		sliceDims[i] = ArrayDim{Length: int(hi - lo + 1), LowerBound: result.FirstIndex()}
+		starts[i] = int(lo - int64(dim.LowerBound))
 	}
-	return arr.Array[subscriptIdx-1], nil
+
+	// Copy the elements in row-major order, iterating over the positions
+	// within the slice like an odometer.
+	pos := make([]int, len(dims))
+	for {
+		offset := 0
+		for i, dim := range dims {
+			offset = offset*dim.Length + starts[i] + pos[i]
+		}
+		elem := arr.Array[offset]
+		if elem == DNull {
+			result.HasNulls = true
+		} else {
+			result.HasNonNulls = true
+		}
+		result.Array = append(result.Array, elem)
+
+		i := len(pos) - 1
+		for ; i >= 0; i-- {
+			pos[i]++
+			if pos[i] < sliceDims[i].Length {
+				break
+			}
+			pos[i] = 0
+		}
+		if i < 0 {
+			break
+		}
+	}
+	if err := result.SetDims(sliceDims); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // Eval implements the TypedExpr interface.
@@ -4698,6 +4871,56 @@ func arrayOfType(typ *types.T) (*DArray, error) {
 	return NewDArray(typ.ArrayContents()), nil
 }
 
+// NewDArrayFromSubArrays returns a multidimensional array with elements of the
+// given type, built out of sub-arrays which must all have the same dimensions.
+// As in Postgres, NULL sub-arrays are treated as empty arrays, and the result
+// is empty if all the sub-arrays are empty.
+func NewDArrayFromSubArrays(paramTyp *types.T, subArrays Datums) (*DArray, error) {
+	array := NewDArray(paramTyp)
+	var dims []ArrayDim
+	haveEmpty := false
+	for _, v := range subArrays {
+		if v == DNull {
+			haveEmpty = true
+			continue
+		}
+		sub := MustBeDArray(v)
+		subDims := sub.Dimensions()
+		if len(subDims) == 0 {
+			haveEmpty = true
+			continue
+		}
+		if dims == nil {
+			dims = append([]ArrayDim{{LowerBound: 1}}, subDims...)
+		} else if len(dims)-1 != len(subDims) {
+			return nil, errNonHomogeneousArray
+		} else {
+			for i := range subDims {
+				if dims[i+1] != subDims[i] {
+					return nil, errNonHomogeneousArray
+				}
+			}
+		}
+		dims[0].Length++
+		array.Array = append(array.Array, sub.Array...)
+		array.HasNulls = array.HasNulls || sub.HasNulls
+		array.HasNonNulls = array.HasNonNulls || sub.HasNonNulls
+		if err := array.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if dims == nil {
+		return array, nil
+	}
+	if haveEmpty {
+		return nil, errNonHomogeneousArray
+	}
+	if err := array.SetDims(dims); err != nil {
+		return nil, err
+	}
+	return array, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t *Array) Eval(ctx *EvalContext) (Datum, error) {
 	array, err := arrayOfType(t.ResolvedType())
@@ -4705,6 +4928,16 @@ func (t *Array) Eval(ctx *EvalContext) (Datum, error) {
 		return nil, err
 	}
 
+	if t.HasSubArrays() {
+		subArrays := make(Datums, len(t.Exprs))
+		for i, v := range t.Exprs {
+			if subArrays[i], err = v.(TypedExpr).Eval(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return NewDArrayFromSubArrays(array.ParamTyp, subArrays)
+	}
+
 	for _, v := range t.Exprs {
 		d, err := v.(TypedExpr).Eval(ctx)
 		if err != nil {
@@ -4901,11 +5134,12 @@ func (t *Placeholder) Eval(ctx *EvalContext) (Datum, error) {
 		// All placeholders should be typed at this point.
 		return nil, errors.AssertionFailedf("missing type for placeholder %s", t)
 	}
-	if !e.ResolvedType().Equivalent(typ) {
+	if !e.ResolvedType().Equivalent(typ) || typ.IsDomain() {
 		// This happens when we overrode the placeholder's type during type
 		// checking, since the placeholder's type hint didn't match the desired
 		// type for the placeholder. In this case, we cast the expression to
-		// the desired type.
+		// the desired type. Values of a domain are always cast, so that they
+		// are checked against the constraints of the domain.
 		// TODO(jordan,mgartner): Introduce a restriction on what casts are
 		// allowed here. Most likely, only implicit casts should be allowed.
 		cast := NewTypedCastExpr(e, typ)
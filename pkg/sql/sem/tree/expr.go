@@ -416,6 +416,19 @@ func NewTypedIndirectionExpr(expr, index TypedExpr, typ *types.T) *IndirectionEx
 	return node
 }
 
+// NewTypedIndirectionExprWithSubscripts returns a new IndirectionExpr with
+// several subscripts, or with slices, that is verified to be well-typed.
+func NewTypedIndirectionExprWithSubscripts(
+	expr TypedExpr, subscripts ArraySubscripts, typ *types.T,
+) *IndirectionExpr {
+	node := &IndirectionExpr{
+		Expr:        expr,
+		Indirection: subscripts,
+	}
+	node.typ = typ
+	return node
+}
+
 // NewTypedCollateExpr returns a new CollateExpr that is verified to be well-typed.
 func NewTypedCollateExpr(expr TypedExpr, locale string) *CollateExpr {
 	node := &CollateExpr{
@@ -859,6 +872,18 @@ func (node *Array) Format(ctx *FmtCtx) {
 	}
 }
 
+// HasSubArrays returns whether the elements of the type-checked array
+// constructor are themselves arrays, which makes it construct a
+// multidimensional array.
+func (node *Array) HasSubArrays() bool {
+	for _, e := range node.Exprs {
+		if typed, ok := e.(TypedExpr); ok && typed.ResolvedType().Family() == types.ArrayFamily {
+			return true
+		}
+	}
+	return false
+}
+
 // ArrayFlatten represents a subquery array constructor.
 type ArrayFlatten struct {
 	Subquery Expr
@@ -1479,6 +1504,18 @@ func (a *ArraySubscripts) Format(ctx *FmtCtx) {
 	}
 }
 
+// IsSlice returns whether any of the subscripts is a slice, in which case all
+// of them are treated as slices and the subscripts select a sub-array rather
+// than an element.
+func (a ArraySubscripts) IsSlice() bool {
+	for _, s := range a {
+		if s.Slice {
+			return true
+		}
+	}
+	return false
+}
+
 // IndirectionExpr represents a subscript expression.
 type IndirectionExpr struct {
 	Expr        Expr
@@ -1686,6 +1723,7 @@ func (node *RangeCond) String() string        { return AsString(node) }
 func (node *StrVal) String() string           { return AsString(node) }
 func (node *Subquery) String() string         { return AsString(node) }
 func (node *Tuple) String() string            { return AsString(node) }
+func (node *GroupingSet) String() string      { return AsString(node) }
 func (node *TupleStar) String() string        { return AsString(node) }
 func (node *AnnotateTypeExpr) String() string { return AsString(node) }
 func (node *UnaryExpr) String() string        { return AsString(node) }
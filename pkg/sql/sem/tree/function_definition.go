@@ -174,6 +174,58 @@ func NewFunctionDefinition(
 	}
 }
 
+// NewFunctionDefinitionWithUDFs returns a definition for the function with the
+// given name which holds the overloads of the builtin definition def, if any,
+// followed by the given user-defined overloads. A user-defined overload with
+// the same argument types as a builtin overload is shadowed by it. Unlike
+// NewFunctionDefinition, no telemetry is collected for the user-defined
+// overloads since their signatures are user data.
+func NewFunctionDefinitionWithUDFs(
+	name string, def *FunctionDefinition, udfs []*Overload,
+) *FunctionDefinition {
+	ret := &FunctionDefinition{Name: name}
+	if def != nil {
+		ret.Definition = append(ret.Definition, def.Definition...)
+		ret.FunctionProperties = def.FunctionProperties
+	} else {
+		// The null input behavior of user-defined functions is handled when
+		// their bodies are planned.
+		ret.FunctionProperties = FunctionProperties{
+			NullableArgs: true,
+			Category:     "User-defined",
+		}
+	}
+	for _, udf := range udfs {
+		if !ret.shadows(udf) {
+			ret.Definition = append(ret.Definition, udf)
+		}
+	}
+	return ret
+}
+
+// shadows returns true if the definition already has an overload with the
+// same argument types as o.
+func (fd *FunctionDefinition) shadows(o *Overload) bool {
+	udfTypes := o.Types.Types()
+	for _, impl := range fd.Definition {
+		argTypes, ok := impl.params().(ArgTypes)
+		if !ok || len(argTypes) != len(udfTypes) {
+			continue
+		}
+		identical := true
+		for i := range argTypes {
+			if !argTypes[i].Typ.Identical(udfTypes[i]) {
+				identical = false
+				break
+			}
+		}
+		if identical {
+			return true
+		}
+	}
+	return false
+}
+
 // FunDefs holds pre-allocated FunctionDefinition instances
 // for every builtin function. Initialized by builtins.init().
 var FunDefs map[string]*FunctionDefinition
@@ -11,11 +11,13 @@
 package tree
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
+	"github.com/lib/pq/oid"
 )
 
 // Function names are used in expressions in the FuncExpr node.
@@ -62,6 +64,43 @@ func (fn *ResolvableFunctionReference) Resolve(
 	}
 }
 
+// FunctionReferenceResolver is the interface used to resolve function names,
+// including the names of user-defined functions, into function definitions.
+type FunctionReferenceResolver interface {
+	// ResolveFunction resolves the given function name into a definition
+	// holding all the overloads, builtin and user-defined, which are visible
+	// under that name.
+	ResolveFunction(
+		ctx context.Context, name *UnresolvedName, path sessiondata.SearchPath,
+	) (*FunctionDefinition, error)
+
+	// ResolveFunctionByOID resolves the user-defined function with the given
+	// OID into a definition holding its single overload, regardless of the
+	// search path and of the privileges of the current user.
+	ResolveFunctionByOID(ctx context.Context, oid oid.Oid) (*FunctionDefinition, error)
+}
+
+// ResolveFunctionReference resolves fn like Resolve, but uses the
+// FunctionResolver of the semantic context, if any, so that user-defined
+// functions are visible.
+func (sc *SemaContext) ResolveFunctionReference(
+	ctx context.Context, fn *ResolvableFunctionReference,
+) (*FunctionDefinition, error) {
+	if sc == nil {
+		return fn.Resolve(sessiondata.SearchPath{})
+	}
+	name, ok := fn.FunctionReference.(*UnresolvedName)
+	if !ok || sc.FunctionResolver == nil {
+		return fn.Resolve(sc.SearchPath)
+	}
+	fd, err := sc.FunctionResolver.ResolveFunction(ctx, name, sc.SearchPath)
+	if err != nil {
+		return nil, err
+	}
+	fn.FunctionReference = fd
+	return fd, nil
+}
+
 // WrapFunction creates a new ResolvableFunctionReference
 // holding a pre-resolved function. Helper for grammar rules.
 func WrapFunction(n string) ResolvableFunctionReference {
@@ -0,0 +1,62 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// Listen represents a LISTEN statement.
+type Listen struct {
+	ChannelName Name
+}
+
+var _ Statement = &Listen{}
+
+// Format implements the NodeFormatter interface.
+func (node *Listen) Format(ctx *FmtCtx) {
+	ctx.WriteString("LISTEN ")
+	ctx.FormatNode(&node.ChannelName)
+}
+
+// Unlisten represents an UNLISTEN statement.
+type Unlisten struct {
+	ChannelName Name
+	// All is set for UNLISTEN *.
+	All bool
+}
+
+var _ Statement = &Unlisten{}
+
+// Format implements the NodeFormatter interface.
+func (node *Unlisten) Format(ctx *FmtCtx) {
+	ctx.WriteString("UNLISTEN ")
+	if node.All {
+		ctx.WriteString("*")
+	} else {
+		ctx.FormatNode(&node.ChannelName)
+	}
+}
+
+// Notify represents a NOTIFY statement.
+type Notify struct {
+	ChannelName Name
+	// Payload is nil if no payload was specified.
+	Payload *StrVal
+}
+
+var _ Statement = &Notify{}
+
+// Format implements the NodeFormatter interface.
+func (node *Notify) Format(ctx *FmtCtx) {
+	ctx.WriteString("NOTIFY ")
+	ctx.FormatNode(&node.ChannelName)
+	if node.Payload != nil {
+		ctx.WriteString(", ")
+		ctx.FormatNode(node.Payload)
+	}
+}
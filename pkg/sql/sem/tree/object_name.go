@@ -22,6 +22,7 @@ type ObjectName interface {
 
 var _ ObjectName = &TableName{}
 var _ ObjectName = &TypeName{}
+var _ ObjectName = &FunctionName{}
 
 // objName is the internal type for a qualified object.
 type objName struct {
@@ -222,6 +223,19 @@ func (u *UnresolvedObjectName) ToTableName() TableName {
 	}}
 }
 
+// ToFunctionName converts the unresolved name to a function name.
+func (u *UnresolvedObjectName) ToFunctionName() FunctionName {
+	return FunctionName{objName{
+		ObjectName: Name(u.Parts[0]),
+		ObjectNamePrefix: ObjectNamePrefix{
+			SchemaName:      Name(u.Parts[1]),
+			CatalogName:     Name(u.Parts[2]),
+			ExplicitSchema:  u.NumParts >= 2,
+			ExplicitCatalog: u.NumParts >= 3,
+		},
+	}}
+}
+
 // ToUnresolvedName converts the unresolved object name to the more general
 // unresolved name.
 func (u *UnresolvedObjectName) ToUnresolvedName() *UnresolvedName {
@@ -111,6 +111,20 @@ type Overload struct {
 	// DistSQL. One example is when the type information for function arguments
 	// cannot be recovered.
 	DistsqlBlocklist bool
+
+	// IsUDF is set to true when this is a user-defined function overload.
+	// User-defined functions have no Fn; calls to them are planned by the
+	// optimizer from Body instead.
+	IsUDF bool
+	// Body is the SQL body of a user-defined function.
+	Body string
+	// CalledOnNullInput is true if the user-defined function is evaluated
+	// when any of its arguments is NULL. It is false for functions declared
+	// STRICT or RETURNS NULL ON NULL INPUT, which return NULL in that case.
+	CalledOnNullInput bool
+	// ReturnSet is true if the user-defined function was declared to return a
+	// set of rows.
+	ReturnSet bool
 }
 
 // params implements the overloadImpl interface.
@@ -12,6 +12,7 @@ package tree
 
 import (
 	"bytes"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -19,13 +20,15 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
-	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 )
 
 var enclosingError = pgerror.Newf(pgcode.InvalidTextRepresentation, "array must be enclosed in { and }")
 var extraTextError = pgerror.Newf(pgcode.InvalidTextRepresentation, "extra text after closing right brace")
-var nestedArraysNotSupportedError = unimplemented.NewWithIssueDetail(32552, "strcast", "nested arrays not supported")
 var malformedError = pgerror.Newf(pgcode.InvalidTextRepresentation, "malformed array")
+var nonMatchingSubArraysError = pgerror.Newf(pgcode.InvalidTextRepresentation, "multidimensional arrays must have sub-arrays with matching dimensions")
+var dimsMismatchError = pgerror.Newf(pgcode.InvalidTextRepresentation, "specified array dimensions do not match array contents")
+var boundsError = pgerror.Newf(pgcode.InvalidTextRepresentation, "upper bound cannot be less than lower bound")
+var tooManyDimsError = pgerror.Newf(pgcode.ProgramLimitExceeded, "number of array dimensions exceeds the maximum allowed (%d)", MaxArrayDims)
 
 func isQuoteChar(ch byte) bool {
 	return ch == '"'
@@ -123,6 +126,12 @@ type parseState struct {
 	dependsOnContext bool
 	result           *DArray
 	t                *types.T
+	// ndims is the number of dimensions of the array, or 0 if no element has
+	// been parsed yet.
+	ndims int
+	// lengths holds the length of each dimension seen so far, or -1 for
+	// dimensions that have not been seen yet.
+	lengths [MaxArrayDims]int
 }
 
 func (p *parseState) advance() {
@@ -162,8 +171,6 @@ func (p *parseState) parseElement() error {
 	var err error
 	r := p.peek()
 	switch r {
-	case '{':
-		return nestedArraysNotSupportedError
 	case '"':
 		p.advance()
 		next, err = p.parseQuotedString()
@@ -194,6 +201,122 @@ func (p *parseState) parseElement() error {
 	return p.result.Append(d)
 }
 
+// parseArray parses a brace-enclosed list of elements or sub-arrays at the
+// given nesting level.
+func (p *parseState) parseArray(level int) error {
+	if level >= MaxArrayDims {
+		return tooManyDimsError
+	}
+	if p.peek() != '{' {
+		return enclosingError
+	}
+	p.advance()
+	p.eatWhitespace()
+	length := 0
+	if p.peek() != '}' {
+		for {
+			if p.peek() == '{' {
+				if p.ndims != 0 && p.ndims <= level+1 {
+					return malformedError
+				}
+				if err := p.parseArray(level + 1); err != nil {
+					return err
+				}
+			} else {
+				if p.ndims == 0 {
+					p.ndims = level + 1
+				} else if p.ndims != level+1 {
+					return malformedError
+				}
+				if err := p.parseElement(); err != nil {
+					return err
+				}
+			}
+			length++
+			p.eatWhitespace()
+			if p.peek() != ',' {
+				break
+			}
+			p.advance()
+			p.eatWhitespace()
+		}
+	}
+	if p.eof() {
+		return enclosingError
+	}
+	if p.peek() != '}' {
+		return malformedError
+	}
+	p.advance()
+	p.eatWhitespace()
+	if p.lengths[level] == -1 {
+		p.lengths[level] = length
+	} else if p.lengths[level] != length {
+		return nonMatchingSubArraysError
+	}
+	return nil
+}
+
+// parseBound parses a single array bound of a dimension decoration.
+func (p *parseState) parseBound() (int, error) {
+	i := 0
+	if i < len(p.s) && (p.s[i] == '-' || p.s[i] == '+') {
+		i++
+	}
+	for i < len(p.s) && p.s[i] >= '0' && p.s[i] <= '9' {
+		i++
+	}
+	n, err := strconv.ParseInt(p.s[:i], 10, 32)
+	if err != nil {
+		return 0, malformedError
+	}
+	p.s = p.s[i:]
+	return int(n), nil
+}
+
+// parseDimsDecoration parses the optional dimension decoration that precedes
+// arrays with custom lower bounds, e.g. '[0:1][1:3]='. It returns nil if there
+// is no decoration.
+func (p *parseState) parseDimsDecoration() ([]ArrayDim, error) {
+	var dims []ArrayDim
+	for p.peek() == '[' {
+		if len(dims) == MaxArrayDims {
+			return nil, tooManyDimsError
+		}
+		p.advance()
+		lower, upper := 1, 0
+		b, err := p.parseBound()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() == ':' {
+			p.advance()
+			lower = b
+			if upper, err = p.parseBound(); err != nil {
+				return nil, err
+			}
+		} else {
+			upper = b
+		}
+		if p.peek() != ']' {
+			return nil, malformedError
+		}
+		p.advance()
+		if upper < lower {
+			return nil, boundsError
+		}
+		dims = append(dims, ArrayDim{Length: upper - lower + 1, LowerBound: lower})
+	}
+	if dims != nil {
+		if p.peek() != '=' {
+			return nil, malformedError
+		}
+		p.advance()
+		p.eatWhitespace()
+	}
+	return dims, nil
+}
+
 // ParseDArrayFromString parses the string-form of constructing arrays, handling
 // cases such as `'{1,2,3}'::INT[]`. The input type t is the type of the
 // parameter of the array to parse.
@@ -224,38 +347,42 @@ func doParseDArrayFromString(
 		result: NewDArray(t),
 		t:      t,
 	}
-
-	parser.eatWhitespace()
-	if parser.peek() != '{' {
-		return nil, false, enclosingError
-	}
-	parser.advance()
-	parser.eatWhitespace()
-	if parser.peek() != '}' {
-		if err := parser.parseElement(); err != nil {
-			return nil, false, err
-		}
-		parser.eatWhitespace()
-		for parser.peek() == ',' {
-			parser.advance()
-			parser.eatWhitespace()
-			if err := parser.parseElement(); err != nil {
-				return nil, false, err
-			}
-		}
+	for i := range parser.lengths {
+		parser.lengths[i] = -1
 	}
+
 	parser.eatWhitespace()
-	if parser.eof() {
-		return nil, false, enclosingError
+	decoration, err := parser.parseDimsDecoration()
+	if err != nil {
+		return nil, false, err
 	}
-	if parser.peek() != '}' {
-		return nil, false, malformedError
+	if err := parser.parseArray(0 /* level */); err != nil {
+		return nil, false, err
 	}
-	parser.advance()
-	parser.eatWhitespace()
 	if !parser.eof() {
 		return nil, false, extraTextError
 	}
 
+	var dims []ArrayDim
+	if parser.ndims > 0 {
+		dims = make([]ArrayDim, parser.ndims)
+		for i := range dims {
+			dims[i] = ArrayDim{Length: parser.lengths[i], LowerBound: parser.result.FirstIndex()}
+		}
+	}
+	if decoration != nil {
+		if len(decoration) != len(dims) {
+			return nil, false, dimsMismatchError
+		}
+		for i := range dims {
+			if decoration[i].Length != dims[i].Length {
+				return nil, false, dimsMismatchError
+			}
+		}
+		dims = decoration
+	}
+	if err := parser.result.SetDims(dims); err != nil {
+		return nil, false, err
+	}
 	return parser.result, parser.dependsOnContext, nil
 }
@@ -143,6 +143,48 @@ lo}`, types.String, Datums{NewDString(`hel`), NewDString(`lo`)}},
 	}
 }
 
+func TestParseMultidimensionalArray(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	testData := []struct {
+		str      string
+		ndims    int
+		expected string
+	}{
+		{`{{}}`, 0, `{}`},
+		{`{{1,2},{3,4}}`, 2, `{{1,2},{3,4}}`},
+		{` { { 1 } , { NULL } } `, 2, `{{1},{NULL}}`},
+		{`{{{1},{2}},{{3},{4}}}`, 3, `{{{1},{2}},{{3},{4}}}`},
+		{`[1:2]={1,2}`, 1, `{1,2}`},
+		{`[2]={1,2}`, 1, `{1,2}`},
+		{`[0:1]={1,2}`, 1, `[0:1]={1,2}`},
+		{`[-1:0][3:4]={{1,2},{3,4}}`, 2, `[-1:0][3:4]={{1,2},{3,4}}`},
+	}
+	for _, td := range testData {
+		t.Run(td.str, func(t *testing.T) {
+			evalContext := NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+			actual, _, err := ParseDArrayFromString(evalContext, td.str, types.Int)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual.NumDims() != td.ndims {
+				t.Fatalf("expected %d dimensions, got %d", td.ndims, actual.NumDims())
+			}
+			if s := AsStringWithFlags(actual, FmtPgwireText); s != td.expected {
+				t.Fatalf("expected %s, got %s", td.expected, s)
+			}
+			// The text representation must round-trip.
+			roundTripped, _, err := ParseDArrayFromString(evalContext, td.expected, types.Int)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual.Compare(evalContext, roundTripped) != 0 {
+				t.Fatalf("expected %s to round-trip, got %s", td.expected, roundTripped)
+			}
+		})
+	}
+}
+
 const randomArrayIterations = 1000
 const randomArrayMaxLength = 10
 const randomStringMaxLength = 1000
@@ -214,8 +256,15 @@ func TestParseArrayError(t *testing.T) {
 		{`{,}`, types.Int, `could not parse "{,}" as type int[]: malformed array`},
 		{`{}{}`, types.Int, `could not parse "{}{}" as type int[]: extra text after closing right brace`},
 		{`{} {}`, types.Int, `could not parse "{} {}" as type int[]: extra text after closing right brace`},
-		{`{{}}`, types.Int, `could not parse "{{}}" as type int[]: unimplemented: nested arrays not supported`},
-		{`{1, {1}}`, types.Int, `could not parse "{1, {1}}" as type int[]: unimplemented: nested arrays not supported`},
+		{`{1, {1}}`, types.Int, `could not parse "{1, {1}}" as type int[]: malformed array`},
+		{`{{1}, 1}`, types.Int, `could not parse "{{1}, 1}" as type int[]: malformed array`},
+		{`{{1}, {1, 2}}`, types.Int, `could not parse "{{1}, {1, 2}}" as type int[]: multidimensional arrays must have sub-arrays with matching dimensions`},
+		{`{{{{{{{1}}}}}}}`, types.Int, `could not parse "{{{{{{{1}}}}}}}" as type int[]: number of array dimensions exceeds the maximum allowed (6)`},
+		{`[1:2]={1}`, types.Int, `could not parse "[1:2]={1}" as type int[]: specified array dimensions do not match array contents`},
+		{`[1:2][1:1]={1,2}`, types.Int, `could not parse "[1:2][1:1]={1,2}" as type int[]: specified array dimensions do not match array contents`},
+		{`[2:1]={}`, types.Int, `could not parse "[2:1]={}" as type int[]: upper bound cannot be less than lower bound`},
+		{`[1:2]{1,2}`, types.Int, `could not parse "[1:2]{1,2}" as type int[]: malformed array`},
+		{`[a]={1}`, types.Int, `could not parse "[a]={1}" as type int[]: malformed array`},
 		{`{hello}`, types.Int, `could not parse "{hello}" as type int[]: could not parse "hello" as type int: strconv.ParseInt: parsing "hello": invalid syntax`},
 		{`{"hello}`, types.String, `could not parse "{\"hello}" as type string[]: malformed array`},
 		// It might be unnecessary to disallow this, but Postgres does.
@@ -114,8 +114,6 @@ func (d *DArray) pgwireFormat(ctx *FmtCtx) {
 	case oid.T_int2vector, oid.T_oidvector:
 		// vectors are serialized as a string of space-separated values.
 		sep := ""
-		// TODO(justin): add a test for nested arrays when #32552 is
-		// addressed.
 		for _, d := range d.Array {
 			ctx.WriteString(sep)
 			ctx.FormatNode(d)
@@ -127,10 +125,15 @@ func (d *DArray) pgwireFormat(ctx *FmtCtx) {
 	if ctx.HasFlags(FmtPGCatalog) {
 		ctx.WriteByte('\'')
 	}
-	ctx.WriteByte('{')
-	comma := ""
-	for _, v := range d.Array {
-		ctx.WriteString(comma)
+	if !d.hasDefaultLowerBounds() {
+		// Custom lower bounds are written as a decoration before the elements,
+		// e.g. '[0:1][1:2]={{1,2},{3,4}}'.
+		for _, dim := range d.Dims {
+			fmt.Fprintf(&ctx.Buffer, "[%d:%d]", dim.LowerBound, dim.LowerBound+dim.Length-1)
+		}
+		ctx.WriteByte('=')
+	}
+	d.formatElements(ctx, "{", "}", func(v Datum) {
 		switch dv := UnwrapDatum(nil, v).(type) {
 		case dNull:
 			ctx.WriteString("NULL")
@@ -146,9 +149,7 @@ func (d *DArray) pgwireFormat(ctx *FmtCtx) {
 			s := AsStringWithFlags(v, ctx.flags, FmtDataConversionConfig(ctx.dataConversionConfig))
 			pgwireFormatStringInArray(ctx, s)
 		}
-		comma = ","
-	}
-	ctx.WriteByte('}')
+	})
 	if ctx.HasFlags(FmtPGCatalog) {
 		ctx.WriteByte('\'')
 	}
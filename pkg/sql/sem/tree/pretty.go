@@ -1162,10 +1162,15 @@ func (node *Update) doc(p *PrettyCfg) pretty.Doc {
 }
 
 func (node *Delete) doc(p *PrettyCfg) pretty.Doc {
-	items := make([]pretty.TableRow, 6)
+	items := make([]pretty.TableRow, 7)
 	items = append(items,
 		node.With.docRow(p),
-		p.row("DELETE FROM", p.Doc(node.Table)),
+		p.row("DELETE FROM", p.Doc(node.Table)))
+	if len(node.Using) > 0 {
+		items = append(items,
+			p.row("USING", p.Doc(&node.Using)))
+	}
+	items = append(items,
 		node.Where.docRow(p),
 		node.OrderBy.docRow(p))
 	items = append(items, node.Limit.docTable(p)...)
@@ -1735,6 +1740,7 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [DEFERRABLE ...]
 	//    [WHERE ...]
 	//
 	// or (no constraint name):
@@ -1743,6 +1749,7 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [DEFERRABLE ...]
 	//    [WHERE ...]
 	//
 	clauses := make([]pretty.Doc, 0, 5)
@@ -1773,6 +1780,9 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	if node.PartitionByIndex != nil {
 		clauses = append(clauses, p.Doc(node.PartitionByIndex))
 	}
+	if node.Deferrable != NotDeferrableConstraint {
+		clauses = append(clauses, pretty.Keyword(node.Deferrable.String()))
+	}
 	if node.Predicate != nil {
 		clauses = append(clauses, p.nestUnder(pretty.Keyword("WHERE"), p.Doc(node.Predicate)))
 	}
@@ -1790,6 +1800,7 @@ func (node *ForeignKeyConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    REFERENCES tbl (...)
 	//    [MATCH ...]
 	//    [ACTIONS ...]
+	//    [DEFERRABLE ...]
 	//
 	// or (no constraint name):
 	//
@@ -1797,6 +1808,7 @@ func (node *ForeignKeyConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    REFERENCES tbl [(...)]
 	//    [MATCH ...]
 	//    [ACTIONS ...]
+	//    [DEFERRABLE ...]
 	//
 	clauses := make([]pretty.Doc, 0, 4)
 	title := pretty.ConcatSpace(
@@ -1823,6 +1835,10 @@ func (node *ForeignKeyConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 		clauses = append(clauses, actions)
 	}
 
+	if node.Deferrable != NotDeferrableConstraint {
+		clauses = append(clauses, pretty.Keyword(node.Deferrable.String()))
+	}
+
 	return p.nestUnder(title, pretty.Group(pretty.Stack(clauses...)))
 }
 
@@ -2004,6 +2020,9 @@ func (node *ColumnTableDef) docRow(p *PrettyCfg) pretty.TableRow {
 		if ref := p.Doc(&node.References.Actions); ref != pretty.Nil {
 			fkDetails = append(fkDetails, ref)
 		}
+		if node.References.Deferrable != NotDeferrableConstraint {
+			fkDetails = append(fkDetails, pretty.Keyword(node.References.Deferrable.String()))
+		}
 		fk := fkHead
 		if len(fkDetails) > 0 {
 			fk = p.nestUnder(fk, pretty.Group(pretty.Stack(fkDetails...)))
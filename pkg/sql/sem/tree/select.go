@@ -697,6 +697,50 @@ func (node *GroupBy) Format(ctx *FmtCtx) {
 	}
 }
 
+// GroupingSetType is the kind of a GroupingSet.
+type GroupingSetType int
+
+// GroupingSetType values.
+const (
+	// RollupGroupingSet represents ROLLUP (e1, ..., en), which is shorthand for
+	// the grouping sets (e1, ..., en), (e1, ..., en-1), ..., ().
+	RollupGroupingSet GroupingSetType = iota
+	// CubeGroupingSet represents CUBE (e1, ..., en), which is shorthand for all
+	// the subsets of (e1, ..., en).
+	CubeGroupingSet
+	// ExplicitGroupingSets represents GROUPING SETS (...).
+	ExplicitGroupingSets
+)
+
+var groupingSetTypeName = [...]string{
+	RollupGroupingSet:    "ROLLUP",
+	CubeGroupingSet:      "CUBE",
+	ExplicitGroupingSets: "GROUPING SETS",
+}
+
+func (t GroupingSetType) String() string {
+	return groupingSetTypeName[t]
+}
+
+// GroupingSet represents a ROLLUP, CUBE or GROUPING SETS item in a GROUP BY
+// clause. For ROLLUP and CUBE, each of the Exprs is a grouping element; a
+// Tuple element is a composite element whose expressions are grouped on
+// together. For GROUPING SETS, each of the Exprs is itself a GROUP BY item:
+// an expression, a Tuple (the empty Tuple being the empty grouping set), or a
+// nested GroupingSet.
+type GroupingSet struct {
+	Type  GroupingSetType
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSet) Format(ctx *FmtCtx) {
+	ctx.WriteString(node.Type.String())
+	ctx.WriteString(" (")
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
 // DistinctOn represents a DISTINCT ON clause.
 type DistinctOn []Expr
 
@@ -318,6 +318,42 @@ func (*AlterDefaultPrivileges) StatementType() StatementType { return TypeDCL }
 // StatementTag returns a short string identifying the type of statement.
 func (*AlterDefaultPrivileges) StatementTag() string { return "ALTER DEFAULT PRIVILEGES" }
 
+// StatementReturnType implements the Statement interface.
+func (*AlterFunctionOptions) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*AlterFunctionOptions) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterFunctionOptions) StatementTag() string { return "ALTER FUNCTION" }
+
+// StatementReturnType implements the Statement interface.
+func (*AlterFunctionRename) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*AlterFunctionRename) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterFunctionRename) StatementTag() string { return "ALTER FUNCTION" }
+
+// StatementReturnType implements the Statement interface.
+func (*AlterFunctionSetSchema) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*AlterFunctionSetSchema) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterFunctionSetSchema) StatementTag() string { return "ALTER FUNCTION" }
+
+// StatementReturnType implements the Statement interface.
+func (*AlterFunctionSetOwner) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*AlterFunctionSetOwner) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterFunctionSetOwner) StatementTag() string { return "ALTER FUNCTION" }
+
 // StatementReturnType implements the Statement interface.
 func (*AlterIndex) StatementReturnType() StatementReturnType { return DDL }
 
@@ -559,6 +595,33 @@ func (*CloseCursor) StatementType() StatementType { return TypeDCL }
 // StatementTag returns a short string identifying the type of statement.
 func (*CloseCursor) StatementTag() string { return "CLOSE" }
 
+// StatementReturnType implements the Statement interface.
+func (*Listen) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*Listen) StatementType() StatementType { return TypeDCL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Listen) StatementTag() string { return "LISTEN" }
+
+// StatementReturnType implements the Statement interface.
+func (*Unlisten) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*Unlisten) StatementType() StatementType { return TypeDCL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Unlisten) StatementTag() string { return "UNLISTEN" }
+
+// StatementReturnType implements the Statement interface.
+func (*Notify) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*Notify) StatementType() StatementType { return TypeDCL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Notify) StatementTag() string { return "NOTIFY" }
+
 // StatementReturnType implements the Statement interface.
 func (*CommentOnColumn) StatementReturnType() StatementReturnType { return DDL }
 
@@ -586,6 +649,15 @@ func (*CommentOnDatabase) StatementType() StatementType { return TypeDDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*CommentOnDatabase) StatementTag() string { return "COMMENT ON DATABASE" }
 
+// StatementReturnType implements the Statement interface.
+func (*CommentOnFunction) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*CommentOnFunction) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CommentOnFunction) StatementTag() string { return "COMMENT ON FUNCTION" }
+
 // StatementReturnType implements the Statement interface.
 func (*CommentOnSchema) StatementReturnType() StatementReturnType { return DDL }
 
@@ -631,6 +703,15 @@ func (*CopyFrom) StatementType() StatementType { return TypeDML }
 // StatementTag returns a short string identifying the type of statement.
 func (*CopyFrom) StatementTag() string { return "COPY" }
 
+// StatementReturnType implements the Statement interface.
+func (*CopyTo) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*CopyTo) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CopyTo) StatementTag() string { return "COPY" }
+
 // StatementReturnType implements the Statement interface.
 func (*CreateChangefeed) StatementReturnType() StatementReturnType { return Rows }
 
@@ -712,7 +793,12 @@ func (*CreateType) StatementReturnType() StatementReturnType { return DDL }
 func (*CreateType) StatementType() StatementType { return TypeDDL }
 
 // StatementTag implements the Statement interface.
-func (*CreateType) StatementTag() string { return "CREATE TYPE" }
+func (n *CreateType) StatementTag() string {
+	if n.Variety == Domain {
+		return "CREATE DOMAIN"
+	}
+	return "CREATE TYPE"
+}
 
 func (*CreateType) modifiesSchema() bool { return true }
 
@@ -727,6 +813,24 @@ func (*CreateRole) StatementTag() string { return "CREATE ROLE" }
 
 func (*CreateRole) hiddenFromShowQueries() {}
 
+// StatementReturnType implements the Statement interface.
+func (*CreateFunction) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*CreateFunction) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateFunction) StatementTag() string { return "CREATE FUNCTION" }
+
+// StatementReturnType implements the Statement interface.
+func (*CreateTrigger) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*CreateTrigger) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateTrigger) StatementTag() string { return "CREATE TRIGGER" }
+
 // StatementReturnType implements the Statement interface.
 func (*CreateView) StatementReturnType() StatementReturnType { return DDL }
 
@@ -823,6 +927,24 @@ func (*DropTable) StatementType() StatementType { return TypeDDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*DropTable) StatementTag() string { return "DROP TABLE" }
 
+// StatementReturnType implements the Statement interface.
+func (*DropFunction) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*DropFunction) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropFunction) StatementTag() string { return "DROP FUNCTION" }
+
+// StatementReturnType implements the Statement interface.
+func (*DropTrigger) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*DropTrigger) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropTrigger) StatementTag() string { return "DROP TRIGGER" }
+
 // StatementReturnType implements the Statement interface.
 func (*DropView) StatementReturnType() StatementReturnType { return DDL }
 
@@ -861,7 +983,12 @@ func (*DropType) StatementReturnType() StatementReturnType { return DDL }
 func (*DropType) StatementType() StatementType { return TypeDDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*DropType) StatementTag() string { return "DROP TYPE" }
+func (n *DropType) StatementTag() string {
+	if n.Domain {
+		return "DROP DOMAIN"
+	}
+	return "DROP TYPE"
+}
 
 // StatementReturnType implements the Statement interface.
 func (*DropSchema) StatementReturnType() StatementReturnType { return DDL }
@@ -1228,6 +1355,15 @@ func (*SetClusterSetting) StatementType() StatementType { return TypeDCL }
 // StatementTag returns a short string identifying the type of statement.
 func (*SetClusterSetting) StatementTag() string { return "SET CLUSTER SETTING" }
 
+// StatementReturnType implements the Statement interface.
+func (*SetConstraints) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*SetConstraints) StatementType() StatementType { return TypeTCL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*SetConstraints) StatementTag() string { return "SET CONSTRAINTS" }
+
 // StatementReturnType implements the Statement interface.
 func (*SetTransaction) StatementReturnType() StatementReturnType { return Ack }
 
@@ -1805,6 +1941,10 @@ func (*ValuesClause) StatementTag() string { return "VALUES" }
 func (n *AlterChangefeed) String() string                { return AsString(n) }
 func (n *AlterChangefeedCmds) String() string            { return AsString(n) }
 func (n *AlterBackup) String() string                    { return AsString(n) }
+func (n *AlterFunctionOptions) String() string           { return AsString(n) }
+func (n *AlterFunctionRename) String() string            { return AsString(n) }
+func (n *AlterFunctionSetSchema) String() string         { return AsString(n) }
+func (n *AlterFunctionSetOwner) String() string          { return AsString(n) }
 func (n *AlterIndex) String() string                     { return AsString(n) }
 func (n *AlterDatabaseOwner) String() string             { return AsString(n) }
 func (n *AlterDatabaseAddRegion) String() string         { return AsString(n) }
@@ -1848,14 +1988,19 @@ func (n *CancelQueries) String() string                  { return AsString(n) }
 func (n *CancelSessions) String() string                 { return AsString(n) }
 func (n *CannedOptPlan) String() string                  { return AsString(n) }
 func (n *CloseCursor) String() string                    { return AsString(n) }
+func (n *Listen) String() string                         { return AsString(n) }
+func (n *Unlisten) String() string                       { return AsString(n) }
+func (n *Notify) String() string                         { return AsString(n) }
 func (n *CommentOnColumn) String() string                { return AsString(n) }
 func (n *CommentOnConstraint) String() string            { return AsString(n) }
 func (n *CommentOnDatabase) String() string              { return AsString(n) }
+func (n *CommentOnFunction) String() string              { return AsString(n) }
 func (n *CommentOnSchema) String() string                { return AsString(n) }
 func (n *CommentOnIndex) String() string                 { return AsString(n) }
 func (n *CommentOnTable) String() string                 { return AsString(n) }
 func (n *CommitTransaction) String() string              { return AsString(n) }
 func (n *CopyFrom) String() string                       { return AsString(n) }
+func (n *CopyTo) String() string                         { return AsString(n) }
 func (n *CreateChangefeed) String() string               { return AsString(n) }
 func (n *CreateDatabase) String() string                 { return AsString(n) }
 func (n *CreateExtension) String() string                { return AsString(n) }
@@ -1865,6 +2010,8 @@ func (n *CreateTable) String() string                    { return AsString(n) }
 func (n *CreateSchema) String() string                   { return AsString(n) }
 func (n *CreateSequence) String() string                 { return AsString(n) }
 func (n *CreateStats) String() string                    { return AsString(n) }
+func (n *CreateFunction) String() string                 { return AsString(n) }
+func (n *CreateTrigger) String() string                  { return AsString(n) }
 func (n *CreateView) String() string                     { return AsString(n) }
 func (n *Deallocate) String() string                     { return AsString(n) }
 func (n *Delete) String() string                         { return AsString(n) }
@@ -1876,6 +2023,8 @@ func (n *DropSchema) String() string                     { return AsString(n) }
 func (n *DropSequence) String() string                   { return AsString(n) }
 func (n *DropTable) String() string                      { return AsString(n) }
 func (n *DropType) String() string                       { return AsString(n) }
+func (n *DropFunction) String() string                   { return AsString(n) }
+func (n *DropTrigger) String() string                    { return AsString(n) }
 func (n *DropView) String() string                       { return AsString(n) }
 func (n *DropRole) String() string                       { return AsString(n) }
 func (n *Execute) String() string                        { return AsString(n) }
@@ -1913,6 +2062,7 @@ func (n *Scrub) String() string                          { return AsString(n) }
 func (n *Select) String() string                         { return AsString(n) }
 func (n *SelectClause) String() string                   { return AsString(n) }
 func (n *SetClusterSetting) String() string              { return AsString(n) }
+func (n *SetConstraints) String() string                 { return AsString(n) }
 func (n *SetZoneConfig) String() string                  { return AsString(n) }
 func (n *SetSessionAuthorizationDefault) String() string { return AsString(n) }
 func (n *SetSessionCharacteristics) String() string      { return AsString(n) }
@@ -151,3 +151,14 @@ func ComparisonOpName(op ComparisonOperatorSymbol) string {
 	}
 	return comparisonOpName[op]
 }
+
+// LookupComparisonOperatorSymbol returns the comparison operator symbol whose
+// name, as returned by ComparisonOpName, is name.
+func LookupComparisonOperatorSymbol(name string) (ComparisonOperatorSymbol, bool) {
+	for i, n := range comparisonOpName {
+		if n == name {
+			return ComparisonOperatorSymbol(i), true
+		}
+	}
+	return 0, false
+}
@@ -24,18 +24,35 @@ type IsolationLevel int
 // IsolationLevel values
 const (
 	UnspecifiedIsolation IsolationLevel = iota
+	ReadCommittedIsolation
 	SerializableIsolation
 )
 
 var isolationLevelNames = [...]string{
-	UnspecifiedIsolation:  "UNSPECIFIED",
-	SerializableIsolation: "SERIALIZABLE",
+	UnspecifiedIsolation:   "UNSPECIFIED",
+	ReadCommittedIsolation: "READ COMMITTED",
+	SerializableIsolation:  "SERIALIZABLE",
 }
 
 // IsolationLevelMap is a map from string isolation level name to isolation
 // level, in the lowercase format that set isolation_level supports.
 var IsolationLevelMap = map[string]IsolationLevel{
-	"serializable": SerializableIsolation,
+	"read committed": ReadCommittedIsolation,
+	"serializable":   SerializableIsolation,
+}
+
+// IsolationLevelFromString converts a string into an IsolationLevel. The
+// levels that CockroachDB does not implement are mapped to the closest
+// stronger level that it does implement, as allowed by the SQL standard.
+func IsolationLevelFromString(level string) (IsolationLevel, bool) {
+	switch strings.ToUpper(level) {
+	case "READ UNCOMMITTED", "READ COMMITTED":
+		return ReadCommittedIsolation, true
+	case "SNAPSHOT", "REPEATABLE READ", "SERIALIZABLE":
+		return SerializableIsolation, true
+	default:
+		return UnspecifiedIsolation, false
+	}
 }
 
 func (i IsolationLevel) String() string {
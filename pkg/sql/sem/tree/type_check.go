@@ -50,6 +50,11 @@ type SemaContext struct {
 	// TypeResolver manages resolving type names into *types.T's.
 	TypeResolver TypeReferenceResolver
 
+	// FunctionResolver manages resolving function names, including
+	// user-defined functions, into function definitions. If it is nil, only
+	// builtin functions can be resolved.
+	FunctionResolver FunctionReferenceResolver
+
 	// TableNameResolver is used to resolve the fully qualified
 	// name of a table given its ID.
 	TableNameResolver QualifiedNameResolver
@@ -149,9 +154,13 @@ const (
 	// RejectVolatileFunctions rejects any volatile functions.
 	RejectVolatileFunctions
 
-	// RejectSubqueries rejects subqueries in scalar contexts.
+	// RejectSubqueries rejects subqueries in scalar contexts. User-defined
+	// functions are rejected as well since they are planned as subqueries.
 	RejectSubqueries
 
+	// RejectUDFs rejects calls to user-defined functions.
+	RejectUDFs
+
 	// RejectSpecial is used in common places like the LIMIT clause.
 	RejectSpecial = RejectAggregates | RejectGenerators | RejectWindowApplications
 )
@@ -528,7 +537,9 @@ func (expr *CastExpr) TypeCheck(
 		return nil, err
 	}
 	expr.Type = exprType
-	canElideCast := true
+	// A cast to a domain checks the constraints of the domain, so it can never
+	// be elided.
+	canElideCast := !exprType.IsDomain()
 	switch {
 	case isConstant(expr.Expr):
 		c := expr.Expr.(Constant)
@@ -604,22 +615,33 @@ func (expr *CastExpr) TypeCheck(
 func (expr *IndirectionExpr) TypeCheck(
 	ctx context.Context, semaCtx *SemaContext, desired *types.T,
 ) (TypedExpr, error) {
-	for i, t := range expr.Indirection {
-		if t.Slice {
-			return nil, unimplemented.NewWithIssuef(32551, "ARRAY slicing in %s", expr)
-		}
-		if i > 0 {
-			return nil, unimplemented.NewWithIssueDetailf(32552, "ind", "multidimensional indexing: %s", expr)
+	if len(expr.Indirection) > MaxArrayDims {
+		return nil, pgerror.Newf(pgcode.ProgramLimitExceeded,
+			"number of array dimensions (%d) exceeds the maximum allowed (%d)",
+			len(expr.Indirection), MaxArrayDims)
+	}
+	for _, t := range expr.Indirection {
+		if t.Begin != nil {
+			beginExpr, err := typeCheckAndRequire(ctx, semaCtx, t.Begin, types.Int, "ARRAY subscript")
+			if err != nil {
+				return nil, err
+			}
+			t.Begin = beginExpr
 		}
-
-		beginExpr, err := typeCheckAndRequire(ctx, semaCtx, t.Begin, types.Int, "ARRAY subscript")
-		if err != nil {
-			return nil, err
+		if t.End != nil {
+			endExpr, err := typeCheckAndRequire(ctx, semaCtx, t.End, types.Int, "ARRAY subscript")
+			if err != nil {
+				return nil, err
+			}
+			t.End = endExpr
 		}
-		t.Begin = beginExpr
 	}
 
-	subExpr, err := expr.Expr.TypeCheck(ctx, semaCtx, types.MakeArray(desired))
+	desiredArray := types.MakeArray(desired)
+	if expr.Indirection.IsSlice() {
+		desiredArray = desired
+	}
+	subExpr, err := expr.Expr.TypeCheck(ctx, semaCtx, desiredArray)
 	if err != nil {
 		return nil, err
 	}
@@ -628,7 +650,12 @@ func (expr *IndirectionExpr) TypeCheck(
 		return nil, pgerror.Newf(pgcode.DatatypeMismatch, "cannot subscript type %s because it is not an array", typ)
 	}
 	expr.Expr = subExpr
-	expr.typ = typ.ArrayContents()
+	if expr.Indirection.IsSlice() {
+		// Slicing an array returns an array of the same type.
+		expr.typ = typ
+	} else {
+		expr.typ = typ.ArrayContents()
+	}
 
 	telemetry.Inc(sqltelemetry.ArraySubscriptCounter)
 	return expr, nil
@@ -894,6 +921,23 @@ func NewInvalidFunctionUsageError(class FunctionClass, context string) error {
 	return pgerror.Newf(code, "%s functions are not allowed in %s", cat, context)
 }
 
+// checkUDFUsage checks whether a call to a user-defined function is allowed
+// in the current context.
+func (sc *SemaContext) checkUDFUsage() error {
+	if sc == nil {
+		return nil
+	}
+	if sc.Properties.required.rejectFlags&(RejectUDFs|RejectSubqueries) != 0 {
+		context := sc.Properties.required.context
+		if context == "" {
+			context = "this context"
+		}
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"user-defined functions are not allowed in %s", context)
+	}
+	return nil
+}
+
 // checkFunctionUsage checks whether a given built-in function is
 // allowed in the current context.
 func (sc *SemaContext) checkFunctionUsage(expr *FuncExpr, def *FunctionDefinition) error {
@@ -1004,11 +1048,7 @@ func CheckIsWindowOrAgg(def *FunctionDefinition) error {
 func (expr *FuncExpr) TypeCheck(
 	ctx context.Context, semaCtx *SemaContext, desired *types.T,
 ) (TypedExpr, error) {
-	var searchPath sessiondata.SearchPath
-	if semaCtx != nil {
-		searchPath = semaCtx.SearchPath
-	}
-	def, err := expr.Func.Resolve(searchPath)
+	def, err := semaCtx.ResolveFunctionReference(ctx, &expr.Func)
 	if err != nil {
 		return nil, err
 	}
@@ -1108,6 +1148,12 @@ func (expr *FuncExpr) TypeCheck(
 	}
 	overloadImpl := fns[0].(*Overload)
 
+	if overloadImpl.IsUDF {
+		if err := semaCtx.checkUDFUsage(); err != nil {
+			return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue, "%s()", def.Name)
+		}
+	}
+
 	if expr.IsWindowFunctionApplication() {
 		// Make sure the window function application is of either a built-in window
 		// function or of a builtin aggregate function.
@@ -1476,6 +1522,13 @@ func (expr DefaultVal) TypeCheck(
 	return nil, errInvalidDefaultUsage
 }
 
+// TypeCheck implements the Expr interface.
+func (expr *GroupingSet) TypeCheck(
+	_ context.Context, _ *SemaContext, desired *types.T,
+) (TypedExpr, error) {
+	return nil, pgerror.Newf(pgcode.Syntax, "%s can only appear in GROUP BY", expr.Type)
+}
+
 // TypeCheck implements the Expr interface.
 func (expr PartitionMinVal) TypeCheck(
 	_ context.Context, _ *SemaContext, desired *types.T,
@@ -1564,7 +1617,12 @@ func (expr *Array) TypeCheck(
 		return nil, err
 	}
 
-	expr.typ = types.MakeArray(typ)
+	if typ.Family() == types.ArrayFamily {
+		// An array of arrays is a multidimensional array of their elements.
+		expr.typ = types.MakeArray(typ.ArrayContents())
+	} else {
+		expr.typ = types.MakeArray(typ)
+	}
 	for i := range typedSubExprs {
 		expr.Exprs[i] = typedSubExprs[i]
 	}
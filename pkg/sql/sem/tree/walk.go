@@ -659,6 +659,16 @@ func (expr *Tuple) Walk(v Visitor) Expr {
 	return expr
 }
 
+// Walk implements the Expr interface.
+func (expr *GroupingSet) Walk(v Visitor) Expr {
+	if exprs, changed := walkExprSlice(v, expr.Exprs); changed {
+		exprCopy := *expr
+		exprCopy.Exprs = exprs
+		return &exprCopy
+	}
+	return expr
+}
+
 // Walk implements the Expr interface.
 func (expr *Array) Walk(v Visitor) Expr {
 	if exprs, changed := walkExprSlice(v, expr.Exprs); changed {
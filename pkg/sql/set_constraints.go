@@ -0,0 +1,32 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// SetConstraints sets whether the checks for DEFERRABLE constraints are
+// performed at the end of each statement or at the end of the transaction. The
+// constraints whose checks were deferred and which are now immediate are
+// validated right away.
+func (p *planner) SetConstraints(ctx context.Context, n *tree.SetConstraints) (planNode, error) {
+	if err := p.checkSetConstraintsNames(ctx, n.Names); err != nil {
+		return nil, err
+	}
+	immediate := p.deferredConstraints.setConstraints(n.Names, n.Deferred)
+	if err := p.validateDeferredConstraints(ctx, p.Txn(), immediate); err != nil {
+		return nil, err
+	}
+	return newZeroNode(nil /* columns */), nil
+}
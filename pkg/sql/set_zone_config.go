@@ -16,7 +16,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -31,7 +30,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
@@ -76,18 +74,6 @@ var supportedZoneConfigOptions = map[tree.Name]struct {
 	"global_reads": {
 		requiredType: types.Bool,
 		setter:       func(c *zonepb.ZoneConfig, d tree.Datum) { c.GlobalReads = proto.Bool(bool(tree.MustBeDBool(d))) },
-		checkAllowed: func(ctx context.Context, execCfg *ExecutorConfig, d tree.Datum) error {
-			if !tree.MustBeDBool(d) {
-				// Always allow the value to be unset.
-				return nil
-			}
-			return base.CheckEnterpriseEnabled(
-				execCfg.Settings,
-				execCfg.LogicalClusterID(),
-				execCfg.Organization(),
-				"global_reads",
-			)
-		},
 	},
 	"num_replicas": {
 		requiredType: types.Int,
@@ -783,13 +769,12 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 		}
 
 		// Write the partial zone configuration.
-		hasNewSubzones := !deleteZone && index != nil
 		execConfig := params.extendedEvalCtx.ExecCfg
 		zoneToWrite := partialZone
 		// TODO(ajwerner): This is extremely fragile because we accept a nil table
 		// all the way down here.
 		n.run.numAffected, err = writeZoneConfig(params.ctx, params.p.txn,
-			targetID, table, zoneToWrite, execConfig, hasNewSubzones)
+			targetID, table, zoneToWrite, execConfig)
 		if err != nil {
 			return err
 		}
@@ -1063,12 +1048,9 @@ func prepareZoneConfigWrites(
 	targetID descpb.ID,
 	table catalog.TableDescriptor,
 	zone *zonepb.ZoneConfig,
-	hasNewSubzones bool,
 ) (_ *zoneConfigUpdate, err error) {
 	if len(zone.Subzones) > 0 {
-		st := execCfg.Settings
-		zone.SubzoneSpans, err = GenerateSubzoneSpans(
-			st, execCfg.LogicalClusterID(), execCfg.Codec, table, zone.Subzones, hasNewSubzones)
+		zone.SubzoneSpans, err = GenerateSubzoneSpans(execCfg.Codec, table, zone.Subzones)
 		if err != nil {
 			return nil, err
 		}
@@ -1093,9 +1075,8 @@ func writeZoneConfig(
 	table catalog.TableDescriptor,
 	zone *zonepb.ZoneConfig,
 	execCfg *ExecutorConfig,
-	hasNewSubzones bool,
 ) (numAffected int, err error) {
-	update, err := prepareZoneConfigWrites(ctx, execCfg, targetID, table, zone, hasNewSubzones)
+	update, err := prepareZoneConfigWrites(ctx, execCfg, targetID, table, zone)
 	if err != nil {
 		return 0, err
 	}
@@ -1208,9 +1189,7 @@ func RemoveIndexZoneConfigs(
 	}
 
 	if zcRewriteNecessary {
-		// Ignore CCL required error to allow schema change to progress.
-		_, err = writeZoneConfig(ctx, txn, tableDesc.GetID(), tableDesc, zone, execCfg, false /* hasNewSubzones */)
-		if err != nil && !sqlerrors.IsCCLRequiredError(err) {
+		if _, err := writeZoneConfig(ctx, txn, tableDesc.GetID(), tableDesc, zone, execCfg); err != nil {
 			return err
 		}
 	}
@@ -385,6 +385,10 @@ func showForeignKeyConstraint(
 		buf.WriteString(" ON UPDATE ")
 		buf.WriteString(fk.OnUpdate.String())
 	}
+	if d := fk.Deferrability(); d != tree.NotDeferrableConstraint {
+		buf.WriteByte(' ')
+		buf.WriteString(d.String())
+	}
 	if fk.Validity != descpb.ConstraintValidity_Validated {
 		buf.WriteString(" NOT VALID")
 	}
@@ -631,6 +635,10 @@ func showConstraintClause(
 		}
 		f.WriteString(strings.Join(colNames, ", "))
 		f.WriteString(")")
+		if d := c.Deferrability(); d != tree.NotDeferrableConstraint {
+			f.WriteString(" ")
+			f.WriteString(d.String())
+		}
 		if c.IsPartial() {
 			f.WriteString(" WHERE ")
 			pred, err := schemaexpr.FormatExprForDisplay(ctx, desc, c.Predicate, semaCtx, sessionData, tree.FmtParsable)
@@ -643,6 +651,60 @@ func showConstraintClause(
 			f.WriteString(" NOT VALID")
 		}
 	}
+	for i := range desc.GetExclusionConstraints() {
+		c := &desc.GetExclusionConstraints()[i]
+		f.WriteString(",\n\t")
+		f.WriteString("CONSTRAINT ")
+		formatQuoteNames(&f.Buffer, c.Name)
+		f.WriteString(" ")
+		def, err := showExclusionConstraintDef(ctx, desc, c, semaCtx, sessionData)
+		if err != nil {
+			return err
+		}
+		f.WriteString(def)
+		if c.Validity != descpb.ConstraintValidity_Validated {
+			f.WriteString(" NOT VALID")
+		}
+	}
 	f.WriteString("\n)")
 	return nil
 }
+
+// showExclusionConstraintDef returns the definition of the given exclusion
+// constraint, in the form:
+//   EXCLUDE USING gist (a WITH =, b WITH &&) WHERE (predicate)
+func showExclusionConstraintDef(
+	ctx context.Context,
+	desc catalog.TableDescriptor,
+	c *descpb.ExclusionConstraint,
+	semaCtx *tree.SemaContext,
+	sessionData *sessiondata.SessionData,
+) (string, error) {
+	colNames, err := desc.NamesForColumnIDs(c.ColumnIDs)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("EXCLUDE USING ")
+	buf.WriteString(c.AccessMethod)
+	buf.WriteString(" (")
+	for i, name := range colNames {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		formatQuoteNames(&buf, name)
+		buf.WriteString(" WITH ")
+		buf.WriteString(c.Operators[i])
+	}
+	buf.WriteString(")")
+	if c.IsPartial() {
+		pred, err := schemaexpr.FormatExprForDisplay(ctx, desc, c.Predicate, semaCtx, sessionData, tree.FmtParsable)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(" WHERE (")
+		buf.WriteString(pred)
+		buf.WriteString(")")
+	}
+	return buf.String(), nil
+}
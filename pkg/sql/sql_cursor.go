@@ -15,14 +15,20 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
@@ -30,20 +36,12 @@ import (
 // DeclareCursor implements the DECLARE statement.
 // See https://www.postgresql.org/docs/current/sql-declare.html for details.
 func (p *planner) DeclareCursor(ctx context.Context, s *tree.DeclareCursor) (planNode, error) {
-	if s.Hold {
-		return nil, unimplemented.NewWithIssue(77101, "DECLARE CURSOR WITH HOLD")
-	}
-	if s.Binary {
-		return nil, unimplemented.NewWithIssue(77099, "DECLARE BINARY CURSOR")
-	}
-	if s.Scroll == tree.Scroll {
-		return nil, unimplemented.NewWithIssue(77102, "DECLARE SCROLL CURSOR")
-	}
-
 	return &delayedNode{
 		name: s.String(),
 		constructor: func(ctx context.Context, p *planner) (_ planNode, _ error) {
-			if p.extendedEvalCtx.TxnImplicit {
+			// A WITH HOLD cursor outlives the transaction that declared it, so it
+			// can be declared in an implicit transaction too.
+			if p.extendedEvalCtx.TxnImplicit && !s.Hold {
 				return nil, pgerror.Newf(pgcode.NoActiveSQLTransaction, "DECLARE CURSOR can only be used in transaction blocks")
 			}
 
@@ -97,6 +95,10 @@ func (p *planner) DeclareCursor(ctx context.Context, s *tree.DeclareCursor) (pla
 				txn:          p.txn,
 				statement:    statement,
 				created:      timeutil.Now(),
+				cols:         pt.main.planColumns(),
+				binary:       s.Binary,
+				scroll:       s.Scroll == tree.Scroll,
+				hold:         s.Hold,
 			}
 			if err := p.sqlCursors.addCursor(s.Name, cursor); err != nil {
 				// This case shouldn't happen because cursor names are scoped to a session,
@@ -123,7 +125,7 @@ func (p *planner) FetchCursor(
 			pgcode.InvalidCursorName, "cursor %q does not exist", s.Name,
 		)
 	}
-	if s.Count < 0 || s.FetchType == tree.FetchBackwardAll {
+	if !cursor.scroll && (s.Count < 0 || s.FetchType == tree.FetchBackwardAll) {
 		return nil, errBackwardScan
 	}
 	node := &fetchNode{
@@ -160,6 +162,18 @@ type fetchNode struct {
 }
 
 func (f *fetchNode) startExec(params runParams) error {
+	if f.cursor.scroll {
+		// Scrollable cursors are materialized on their first use, so that they
+		// can be moved backward.
+		if err := f.cursor.materialize(params.ctx, params.p); err != nil {
+			return err
+		}
+	}
+	if f.cursor.rows != nil {
+		// The rows of a materialized cursor are no longer read from the
+		// transaction.
+		return nil
+	}
 	state := f.cursor.txn.GetLeafTxnInputState(params.ctx)
 	// We need to make sure that we're reading at the same read sequence number
 	// that we had when we created the cursor, to preserve the "sensitivity"
@@ -170,6 +184,10 @@ func (f *fetchNode) startExec(params runParams) error {
 }
 
 func (f *fetchNode) Next(params runParams) (bool, error) {
+	if f.cursor.rows != nil {
+		return f.nextMaterialized(params.ctx)
+	}
+
 	if f.fetchType == tree.FetchAll {
 		return f.cursor.Next(params.ctx)
 	}
@@ -218,6 +236,45 @@ func (f *fetchNode) Next(params runParams) (bool, error) {
 	return f.cursor.Next(params.ctx)
 }
 
+// nextMaterialized is like Next, but for a cursor whose rows have been
+// materialized. Such a cursor can be positioned anywhere, and it can be moved
+// backward if it is scrollable.
+func (f *fetchNode) nextMaterialized(ctx context.Context) (bool, error) {
+	if !f.seeked {
+		f.seeked = true
+		switch f.fetchType {
+		case tree.FetchFirst:
+			return f.cursor.seek(ctx, 1)
+		case tree.FetchLast:
+			return f.cursor.seek(ctx, f.cursor.rows.lastRow())
+		case tree.FetchAbsolute:
+			pos := f.offset
+			if pos < 0 {
+				// Negative positions count backward from the end of the rows.
+				pos += f.cursor.rows.lastRow() + 1
+			}
+			return f.cursor.seek(ctx, pos)
+		case tree.FetchRelative:
+			return f.cursor.seek(ctx, f.cursor.curRow+f.offset)
+		}
+	}
+	switch f.fetchType {
+	case tree.FetchAll:
+		return f.cursor.seek(ctx, f.cursor.curRow+1)
+	case tree.FetchBackwardAll:
+		return f.cursor.seek(ctx, f.cursor.curRow-1)
+	}
+	switch {
+	case f.n > 0:
+		f.n--
+		return f.cursor.seek(ctx, f.cursor.curRow+1)
+	case f.n < 0:
+		f.n++
+		return f.cursor.seek(ctx, f.cursor.curRow-1)
+	}
+	return false, nil
+}
+
 func (f fetchNode) Values() tree.Datums {
 	return f.cursor.Cur()
 }
@@ -226,6 +283,9 @@ func (f fetchNode) Close(ctx context.Context) {
 	// We explicitly do not pass through the Close to our InternalRows, because
 	// running FETCH on a CURSOR does not close it.
 
+	if f.cursor.rows != nil {
+		return
+	}
 	// Reset the transaction's read sequence number to what it was before the
 	// fetch began, so that subsequent reads in the transaction can still see
 	// writes from that transaction.
@@ -252,25 +312,199 @@ func (p *planner) CloseCursor(ctx context.Context, n *tree.CloseCursor) (planNod
 type sqlCursor struct {
 	sqlutil.InternalRows
 	// txn is the transaction object that the internal executor for this cursor
-	// is running with.
+	// is running with. It is nil once the transaction that declared a WITH HOLD
+	// cursor has committed.
 	txn *kv.Txn
 	// readSeqNum is the sequence number of the transaction that the cursor was
 	// initialized with.
 	readSeqNum enginepb.TxnSeq
 	statement  string
 	created    time.Time
-	curRow     int64
+	// curRow is the position of the cursor: 0 before the first row, i when
+	// positioned on the i'th row, and one past the last row once all rows have
+	// been read.
+	curRow int64
+	// exhausted is set once the cursor's query has returned all of its rows.
+	exhausted bool
+	// cols describes the result rows of the cursor.
+	cols colinfo.ResultColumns
+
+	// binary, scroll and hold are the BINARY, SCROLL and WITH HOLD options the
+	// cursor was declared with.
+	binary bool
+	scroll bool
+	hold   bool
+	// committed is set once the transaction that declared a WITH HOLD cursor
+	// has committed. From then on, the cursor lives until it is closed or the
+	// session ends.
+	committed bool
+
+	// rows, if set, holds the rows of the cursor that were not yet read from
+	// its query when the cursor was materialized. Scrollable cursors are
+	// materialized on their first use, and WITH HOLD cursors are materialized
+	// when their transaction commits.
+	rows *cursorRows
 }
 
 // Next implements the InternalRows interface.
 func (s *sqlCursor) Next(ctx context.Context) (bool, error) {
+	if s.rows != nil {
+		return s.seek(ctx, s.curRow+1)
+	}
+	if s.exhausted {
+		return false, nil
+	}
 	more, err := s.InternalRows.Next(ctx)
 	if err == nil {
 		s.curRow++
+		s.exhausted = !more
 	}
 	return more, err
 }
 
+// Cur implements the InternalRows interface.
+func (s *sqlCursor) Cur() tree.Datums {
+	if s.rows != nil {
+		return s.rows.cur
+	}
+	return s.InternalRows.Cur()
+}
+
+// Close implements the InternalRows interface.
+func (s *sqlCursor) Close() error {
+	err := s.InternalRows.Close()
+	if s.rows != nil {
+		s.rows.close(context.Background())
+		s.rows = nil
+	}
+	return err
+}
+
+// seek moves a materialized cursor to the given position, returning whether
+// the cursor is now positioned on a row.
+func (s *sqlCursor) seek(ctx context.Context, pos int64) (bool, error) {
+	if (!s.scroll && pos < s.curRow) || (pos > 0 && pos <= s.rows.offset) {
+		return false, errBackwardScan
+	}
+	if pos <= 0 {
+		s.curRow = 0
+		return false, nil
+	}
+	if last := s.rows.lastRow(); pos > last {
+		s.curRow = last + 1
+		return false, nil
+	}
+	s.curRow = pos
+	return true, s.rows.load(ctx, pos)
+}
+
+// materialize reads all the remaining rows of the cursor's query into a
+// disk-backed row container. Afterwards, the cursor no longer reads from its
+// transaction.
+func (s *sqlCursor) materialize(ctx context.Context, p *planner) (retErr error) {
+	if s.rows != nil {
+		return nil
+	}
+	// Read at the sequence number the cursor was declared at, like FETCH does.
+	origTxnSeqNum := s.txn.GetLeafTxnInputState(ctx).ReadSeqNum
+	if err := s.txn.SetReadSeqNum(s.readSeqNum); err != nil {
+		return err
+	}
+	defer func() {
+		if err := s.txn.SetReadSeqNum(origTxnSeqNum); err != nil {
+			retErr = errors.CombineErrors(retErr, err)
+		}
+	}()
+
+	rows := newCursorRows(ctx, p, s.cols)
+	rows.offset = s.curRow
+	if s.exhausted {
+		rows.offset--
+	}
+	for !s.exhausted {
+		more, err := s.InternalRows.Next(ctx)
+		if err != nil {
+			rows.close(ctx)
+			return err
+		}
+		if !more {
+			break
+		}
+		if err := rows.addRow(ctx, s.InternalRows.Cur()); err != nil {
+			rows.close(ctx)
+			return err
+		}
+	}
+	s.exhausted = true
+	s.rows = rows
+	// The query is done, so release its resources right away.
+	return s.InternalRows.Close()
+}
+
+// cursorRows buffers the rows of a materialized cursor.
+type cursorRows struct {
+	memMonitor  *mon.BytesMonitor
+	diskMonitor *mon.BytesMonitor
+	rows        *rowcontainer.DiskBackedIndexedRowContainer
+	scratch     rowenc.EncDatumRow
+	// offset is the position of the cursor right before the first buffered
+	// row. It is non-zero if the cursor had already been moved when it was
+	// materialized.
+	offset int64
+	// cur is the row the cursor is positioned on.
+	cur tree.Datums
+}
+
+func newCursorRows(ctx context.Context, p *planner, cols colinfo.ResultColumns) *cursorRows {
+	// The rows may outlive the transaction, so their memory is accounted for
+	// against the session rather than the transaction.
+	distSQLCfg := &p.extendedEvalCtx.DistSQLPlanner.distSQLSrv.ServerConfig
+	c := &cursorRows{
+		memMonitor: execinfra.NewLimitedMonitorNoFlowCtx(
+			ctx, p.sessionMonitor, distSQLCfg, p.SessionData(), "sql-cursor-limited",
+		),
+		diskMonitor: execinfra.NewMonitor(ctx, distSQLCfg.ParentDiskMonitor, "sql-cursor-disk"),
+		scratch:     make(rowenc.EncDatumRow, len(cols)),
+	}
+	typs := make([]*types.T, len(cols))
+	for i := range cols {
+		typs[i] = cols[i].Typ
+	}
+	c.rows = rowcontainer.NewDiskBackedIndexedRowContainer(
+		colinfo.NoOrdering, typs, &p.extendedEvalCtx.EvalContext,
+		distSQLCfg.TempStorage, c.memMonitor, c.diskMonitor,
+	)
+	return c
+}
+
+func (c *cursorRows) addRow(ctx context.Context, row tree.Datums) error {
+	for i := range row {
+		c.scratch[i].Datum = row[i]
+	}
+	return c.rows.AddRow(ctx, c.scratch)
+}
+
+// lastRow returns the position of the last row of the cursor.
+func (c *cursorRows) lastRow() int64 {
+	return c.offset + int64(c.rows.Len())
+}
+
+// load sets cur to the row at the given position of the cursor.
+func (c *cursorRows) load(ctx context.Context, pos int64) error {
+	row, err := c.rows.GetRow(ctx, int(pos-c.offset-1))
+	if err != nil {
+		return err
+	}
+	c.cur, err = row.GetDatums(0, len(c.scratch))
+	return err
+}
+
+func (c *cursorRows) close(ctx context.Context) {
+	c.rows.Close(ctx)
+	c.memMonitor.Stop(ctx)
+	c.diskMonitor.Stop(ctx)
+}
+
 // sqlCursors contains a set of active cursors for a session.
 type sqlCursors interface {
 	// closeAll closes all cursors in the set.
@@ -300,6 +534,38 @@ func (c *cursorMap) closeAll() {
 	c.cursors = nil
 }
 
+// persistHoldCursors materializes the WITH HOLD cursors declared by the
+// current transaction, so that they can outlive it. It must be called before
+// the transaction commits.
+func (c *cursorMap) persistHoldCursors(ctx context.Context, p *planner) error {
+	for _, cursor := range c.cursors {
+		if !cursor.hold || cursor.committed {
+			continue
+		}
+		if err := cursor.materialize(ctx, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeTxnCursors closes the cursors declared by the transaction that just
+// finished. If the transaction committed, its WITH HOLD cursors are kept open.
+func (c *cursorMap) closeTxnCursors(committed bool) {
+	for name, cursor := range c.cursors {
+		if cursor.committed {
+			continue
+		}
+		if committed && cursor.hold {
+			cursor.committed = true
+			cursor.txn = nil
+			continue
+		}
+		_ = cursor.Close()
+		delete(c.cursors, name)
+	}
+}
+
 func (c *cursorMap) closeCursor(s tree.Name) error {
 	cursor, ok := c.cursors[s]
 	if !ok {
@@ -364,9 +630,13 @@ func (p *planner) checkNoConflictingCursors(stmt tree.Statement) error {
 	// We could improve this by matching the memo metadata's list of dependent
 	// schema objects in each open cursor with the objects being changed in the
 	// schema change.
-	if len(p.sqlCursors.list()) > 0 {
-		return unimplemented.NewWithIssue(74608, "cannot run schema change "+
-			"in a transaction with open DECLARE cursors")
+	for _, c := range p.sqlCursors.list() {
+		// WITH HOLD cursors from committed transactions are materialized and no
+		// longer depend on any schema objects.
+		if !c.committed {
+			return unimplemented.NewWithIssue(74608, "cannot run schema change "+
+				"in a transaction with open DECLARE cursors")
+		}
 	}
 	return nil
 }
@@ -156,6 +156,12 @@ func NewUndefinedTypeError(name tree.NodeFormatter) error {
 	return pgerror.Newf(pgcode.UndefinedObject, "type %q does not exist", tree.ErrString(name))
 }
 
+// NewUndefinedFunctionError creates an error that represents a missing
+// function.
+func NewUndefinedFunctionError(name tree.NodeFormatter) error {
+	return pgerror.Newf(pgcode.UndefinedFunction, "function %s does not exist", tree.ErrString(name))
+}
+
 // NewUndefinedRelationError creates an error that represents a missing database table or view.
 func NewUndefinedRelationError(name tree.NodeFormatter) error {
 	return pgerror.Newf(pgcode.UndefinedTable,
@@ -211,6 +217,12 @@ func NewTypeAlreadyExistsError(name string) error {
 	return pgerror.Newf(pgcode.DuplicateObject, "type %q already exists", name)
 }
 
+// NewFunctionAlreadyExistsError creates an error for a preexisting function
+// with the same argument types.
+func NewFunctionAlreadyExistsError(name tree.NodeFormatter) error {
+	return pgerror.Newf(pgcode.DuplicateFunction, "function %s already exists with same argument types", tree.ErrString(name))
+}
+
 // IsRelationAlreadyExistsError checks whether this is an error for a preexisting relation.
 func IsRelationAlreadyExistsError(err error) bool {
 	return errHasCode(err, pgcode.DuplicateRelation)
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -144,6 +145,8 @@ const (
 //   and should be fixed to this timestamp.
 // priority: The transaction's priority. Pass roachpb.UnspecifiedUserPriority if the txn arg is
 //   not nil.
+// isoLevel: The transaction's isolation level. Ignored if the txn arg is not
+//   nil.
 // readOnly: The read-only character of the new txn.
 // txn: If not nil, this txn will be used instead of creating a new txn. If so,
 //   all the other arguments need to correspond to the attributes of this txn
@@ -157,6 +160,7 @@ func (ts *txnState) resetForNewSQLTxn(
 	sqlTimestamp time.Time,
 	historicalTimestamp *hlc.Timestamp,
 	priority roachpb.UserPriority,
+	isoLevel isolation.Level,
 	readOnly tree.ReadWriteMode,
 	txn *kv.Txn,
 	tranCtx transitionCtx,
@@ -201,6 +205,9 @@ func (ts *txnState) resetForNewSQLTxn(
 		if err := ts.setPriorityLocked(priority); err != nil {
 			panic(err)
 		}
+		if err := ts.mu.txn.SetIsoLevel(isoLevel); err != nil {
+			panic(err)
+		}
 	} else {
 		if priority != roachpb.UnspecifiedUserPriority {
 			panic(errors.AssertionFailedf("unexpected priority when using an existing txn: %s", priority))
@@ -306,6 +313,12 @@ func (ts *txnState) setPriorityLocked(userPriority roachpb.UserPriority) error {
 	return nil
 }
 
+func (ts *txnState) setIsolationLevel(level isolation.Level) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.mu.txn.SetIsoLevel(level)
+}
+
 func (ts *txnState) setReadOnlyMode(mode tree.ReadWriteMode) error {
 	switch mode {
 	case tree.UnspecifiedReadWriteMode:
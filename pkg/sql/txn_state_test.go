@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -293,8 +294,8 @@ func TestTransitions(t *testing.T) {
 				return s, ts, emptyTxnID, nil
 			},
 			ev: eventTxnStart{ImplicitTxn: fsm.True},
-			evPayload: makeEventTxnStartPayload(pri, tree.ReadWrite, timeutil.Now(),
-				nil /* historicalTimestamp */, tranCtx, sessiondatapb.Normal),
+			evPayload: makeEventTxnStartPayload(pri, isolation.Serializable, tree.ReadWrite,
+				timeutil.Now(), nil /* historicalTimestamp */, tranCtx, sessiondatapb.Normal),
 			expState: stateOpen{ImplicitTxn: fsm.True, WasUpgraded: fsm.False},
 			expAdv: expAdvance{
 				// We expect to stayInPlace; upon starting a txn the statement is
@@ -318,8 +319,8 @@ func TestTransitions(t *testing.T) {
 				return s, ts, emptyTxnID, nil
 			},
 			ev: eventTxnStart{ImplicitTxn: fsm.False},
-			evPayload: makeEventTxnStartPayload(pri, tree.ReadWrite, timeutil.Now(),
-				nil /* historicalTimestamp */, tranCtx, sessiondatapb.Normal),
+			evPayload: makeEventTxnStartPayload(pri, isolation.Serializable, tree.ReadWrite,
+				timeutil.Now(), nil /* historicalTimestamp */, tranCtx, sessiondatapb.Normal),
 			expState: stateOpen{ImplicitTxn: fsm.False, WasUpgraded: fsm.False},
 			expAdv: expAdvance{
 				expCode: advanceOne,
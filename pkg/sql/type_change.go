@@ -769,6 +769,19 @@ func (t *typeSchemaChanger) canRemoveEnumValue(
 	descsCol *descs.Collection,
 ) error {
 	for _, ID := range typeDesc.ReferencingDescriptorIDs {
+		refDesc, err := descsCol.GetImmutableDescriptorByID(ctx, txn, ID, tree.CommonLookupFlags{
+			AvoidLeased: true,
+			Required:    true,
+		})
+		if err != nil {
+			return errors.Wrapf(err,
+				"could not validate enum value removal for %q", member.LogicalRepresentation)
+		}
+		if refDesc.DescriptorType() == catalog.Function {
+			// Functions only reference the type in their signature or body, not
+			// in any stored data.
+			continue
+		}
 		desc, err := descsCol.GetImmutableTableByID(ctx, txn, ID, tree.ObjectLookupFlags{
 			CommonLookupFlags: tree.CommonLookupFlags{
 				AvoidLeased: true,
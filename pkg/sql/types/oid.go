@@ -190,6 +190,11 @@ func init() {
 // CalcArrayOid returns the OID of the array type having elements of the given
 // type.
 func CalcArrayOid(elemTyp *T) oid.Oid {
+	if elemTyp.IsDomain() {
+		// Domains do not have their own array types, so arrays of a domain are
+		// arrays of its base type.
+		return CalcArrayOid(elemTyp.DomainBaseType())
+	}
 	o := elemTyp.Oid()
 	switch elemTyp.Family() {
 	case ArrayFamily:
@@ -214,6 +219,9 @@ func CalcArrayOid(elemTyp *T) oid.Oid {
 		return elemTyp.UserDefinedArrayOID()
 
 	case TupleFamily:
+		if elemTyp.IsCompositeType() {
+			return elemTyp.UserDefinedArrayOID()
+		}
 		if elemTyp.UserDefined() {
 			// We're currently not creating array types for implicitly-defined
 			// per-table record types. So, we cheat a little, and return, as the OID
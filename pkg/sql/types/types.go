@@ -197,6 +197,9 @@ type UserDefinedTypeMetadata struct {
 
 	// enumData is non-nil iff the metadata is for an ENUM type.
 	EnumData *EnumMetadata
+
+	// DomainData is non-nil iff the metadata is for a domain.
+	DomainData *DomainMetadata
 }
 
 // EnumMetadata is metadata about an ENUM needed for evaluation.
@@ -215,6 +218,21 @@ type EnumMetadata struct {
 	//  should occur, if at all.
 }
 
+// DomainMetadata is metadata about a domain needed for evaluation.
+type DomainMetadata struct {
+	// NotNull is set if values of the domain may not be NULL.
+	NotNull bool
+	// Checks are the CHECK constraints of the domain.
+	Checks []DomainCheck
+}
+
+// DomainCheck is a CHECK constraint of a domain. The serialized expression
+// refers to the value being checked as VALUE.
+type DomainCheck struct {
+	Name string
+	Expr string
+}
+
 func (e *EnumMetadata) debugString() string {
 	return fmt.Sprintf(
 		"PhysicalReps: %v; LogicalReps: %s",
@@ -1130,6 +1148,30 @@ func MakeLabeledTuple(contents []*T, labels []string) *T {
 	}}
 }
 
+// MakeCompositeType constructs a new instance of a user defined composite
+// type, which is a labeled tuple with its own OID and array type.
+func MakeCompositeType(typeOID, arrayTypeOID oid.Oid, contents []*T, labels []string) *T {
+	t := MakeLabeledTuple(contents, labels)
+	t.InternalType.Oid = typeOID
+	t.InternalType.UDTMetadata = &PersistentUserDefinedTypeMetadata{
+		ArrayTypeOID: arrayTypeOID,
+	}
+	return t
+}
+
+// MakeDomainType constructs a new instance of a domain over the given base
+// type. Values of the domain are represented like values of the base type; if
+// the base type is itself a domain, the new domain is over its base type.
+func MakeDomainType(typeOID oid.Oid, base *T) *T {
+	base = base.DomainBaseType()
+	t := &T{InternalType: base.InternalType}
+	t.InternalType.Oid = typeOID
+	t.InternalType.UDTMetadata = &PersistentUserDefinedTypeMetadata{
+		DomainBaseOID: base.Oid(),
+	}
+	return t
+}
+
 // Family specifies a group of types that are compatible with one another. Types
 // in the same family can be compared, assigned, etc., but may differ from one
 // another in width, precision, locale, and other attributes. For example, it is
@@ -1358,6 +1400,39 @@ func (t *T) UserDefined() bool {
 	return IsOIDUserDefinedType(t.Oid())
 }
 
+// IsCompositeType returns whether or not t is a user defined composite type.
+// Unlike the implicit record types of tables, composite types carry their
+// own array type.
+func (t *T) IsCompositeType() bool {
+	return t.Family() == TupleFamily && t.UserDefined() && t.InternalType.UDTMetadata != nil
+}
+
+// IsDomain returns whether or not t is a domain.
+func (t *T) IsDomain() bool {
+	return t.InternalType.UDTMetadata != nil && t.InternalType.UDTMetadata.DomainBaseOID != 0
+}
+
+// DomainBaseType returns the builtin type underlying the domain t, or t itself
+// if t is not a domain.
+func (t *T) DomainBaseType() *T {
+	if !t.IsDomain() {
+		return t
+	}
+	base := &T{InternalType: t.InternalType}
+	base.InternalType.Oid = t.InternalType.UDTMetadata.DomainBaseOID
+	base.InternalType.UDTMetadata = nil
+	return base
+}
+
+// domainName returns the name of the domain t, or the empty string if t has
+// not been hydrated.
+func (t *T) domainName() string {
+	if t.TypeMeta.Name == nil {
+		return ""
+	}
+	return t.TypeMeta.Name.Basename()
+}
+
 // IsOIDUserDefinedType returns whether or not o corresponds to a user
 // defined type.
 func IsOIDUserDefinedType(o oid.Oid) bool {
@@ -1414,6 +1489,12 @@ func (f Family) Name() string {
 //
 // TODO(andyk): Should these be changed to be the same as SQLStandardName?
 func (t *T) Name() string {
+	if t.IsDomain() {
+		if name := t.domainName(); name != "" {
+			return name
+		}
+		return t.DomainBaseType().Name()
+	}
 	switch fam := t.Family(); fam {
 	case AnyFamily:
 		return "anyelement"
@@ -1553,6 +1634,13 @@ func (t *T) TelemetryName() string {
 // This function is full of special cases. See backend/utils/adt/format_type.c
 // in Postgres.
 func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
+	if t.IsDomain() {
+		// Like Postgres, format a domain with its own name and no modifiers.
+		if name := t.domainName(); name != "" {
+			return name
+		}
+		return t.DomainBaseType().SQLStandardNameWithTypmod(haveTypmod, typmod)
+	}
 	var buf strings.Builder
 	switch t.Family() {
 	case AnyFamily:
@@ -1732,6 +1820,11 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 // This is different from SQLString() in that it must report SQL standard names
 // that are compatible with PostgreSQL client expectations.
 func (t *T) InformationSchemaName() string {
+	// Domains are reported as their base type; the domain itself is reported
+	// in the domain_name column.
+	if t.IsDomain() {
+		return t.DomainBaseType().InformationSchemaName()
+	}
 	// This is the same as SQLStandardName, except for the case of arrays.
 	if t.Family() == ArrayFamily {
 		return "ARRAY"
@@ -1747,6 +1840,12 @@ func (t *T) InformationSchemaName() string {
 // reproduce the type via parsing the string as a type. It is used in error
 // messages and also to produce the output of SHOW CREATE.
 func (t *T) SQLString() string {
+	if t.IsDomain() {
+		if t.TypeMeta.Name != nil {
+			return t.TypeMeta.Name.FQName()
+		}
+		return t.DomainBaseType().SQLString()
+	}
 	switch t.Family() {
 	case BitFamily:
 		o := t.Oid()
@@ -1841,6 +1940,10 @@ func (t *T) SQLString() string {
 			return "anyenum"
 		}
 		return t.TypeMeta.Name.FQName()
+	case TupleFamily:
+		if t.IsCompositeType() && t.TypeMeta.Name != nil {
+			return t.TypeMeta.Name.FQName()
+		}
 	}
 	return strings.ToUpper(t.Name())
 }
@@ -1860,6 +1963,9 @@ func (t *T) SQLStringForError() redact.RedactableString {
 		case ArrayFamily:
 			prefix = "ARRAY"
 		}
+		if t.IsDomain() {
+			prefix = "DOMAIN"
+		}
 		return redact.Sprintf("USER DEFINED %s: %s", redact.Safe(prefix), t.SQLString())
 	}
 	switch t.Family() {
@@ -2087,6 +2193,9 @@ func (t *InternalType) Identical(other *InternalType) bool {
 		if t.UDTMetadata.ArrayTypeOID != other.UDTMetadata.ArrayTypeOID {
 			return false
 		}
+		if t.UDTMetadata.DomainBaseOID != other.UDTMetadata.DomainBaseOID {
+			return false
+		}
 	} else if t.UDTMetadata != nil {
 		return false
 	} else if other.UDTMetadata != nil {
@@ -2119,6 +2228,13 @@ func (t *T) Unmarshal(data []byte) error {
 // setting required values. This is necessary to preserve backwards-
 // compatibility with older formats (e.g. restoring database from old backup).
 func (t *T) upgradeType() error {
+	if t.IsDomain() {
+		// The representation of a domain is that of its base type, so upgrade it
+		// as such and restore the domain's OID afterwards.
+		domainOID := t.InternalType.Oid
+		t.InternalType.Oid = t.InternalType.UDTMetadata.DomainBaseOID
+		defer func() { t.InternalType.Oid = domainOID }()
+	}
 	switch t.Family() {
 	case IntFamily:
 		// Check VisibleType field that was populated in previous versions.
@@ -2357,6 +2473,12 @@ func (t *T) MarshalTo(data []byte) (int, error) {
 // CRDB. This is necessary to preserve backwards-compatibility in mixed-version
 // scenarios, such as during upgrade.
 func (t *T) downgradeType() error {
+	if t.IsDomain() {
+		// See upgradeType.
+		domainOID := t.InternalType.Oid
+		t.InternalType.Oid = t.InternalType.UDTMetadata.DomainBaseOID
+		defer func() { t.InternalType.Oid = domainOID }()
+	}
 	// Set Family and VisibleType for 19.1 backwards-compatibility.
 	switch t.Family() {
 	case BitFamily:
@@ -414,9 +414,19 @@ func (ss scalarSlot) checkColumnTypes(row []tree.TypedExpr) error {
 // currently only includes checking for null values in non-nullable columns.
 func enforceLocalColumnConstraints(row tree.Datums, cols []catalog.Column) error {
 	for i, col := range cols {
-		if !col.IsNullable() && row[i] == tree.DNull {
+		if row[i] != tree.DNull {
+			continue
+		}
+		if !col.IsNullable() {
 			return sqlerrors.NewNonNullViolationError(col.GetName())
 		}
+		// Values that were not assigned to a column, and so were not cast to its
+		// type, must still satisfy the NOT NULL constraint of its domain.
+		if col.GetType().IsDomain() {
+			if err := checkDomainNotNull(col.GetType()); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
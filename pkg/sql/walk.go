@@ -344,6 +344,10 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterDatabaseDropSuperRegion{}):     "alter database alter super region",
 	reflect.TypeOf(&alterDatabaseAlterSuperRegion{}):    "alter database drop super region",
 	reflect.TypeOf(&alterDefaultPrivilegesNode{}):       "alter default privileges",
+	reflect.TypeOf(&alterFunctionOptionsNode{}):         "alter function",
+	reflect.TypeOf(&alterFunctionRenameNode{}):          "alter function rename",
+	reflect.TypeOf(&alterFunctionSetOwnerNode{}):        "alter function owner",
+	reflect.TypeOf(&alterFunctionSetSchemaNode{}):       "alter function set schema",
 	reflect.TypeOf(&alterIndexNode{}):                   "alter index",
 	reflect.TypeOf(&alterSequenceNode{}):                "alter sequence",
 	reflect.TypeOf(&alterSchemaNode{}):                  "alter schema",
@@ -363,6 +367,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&commentOnColumnNode{}):              "comment on column",
 	reflect.TypeOf(&commentOnConstraintNode{}):          "comment on constraint",
 	reflect.TypeOf(&commentOnDatabaseNode{}):            "comment on database",
+	reflect.TypeOf(&commentOnFunctionNode{}):            "comment on function",
 	reflect.TypeOf(&commentOnIndexNode{}):               "comment on index",
 	reflect.TypeOf(&commentOnTableNode{}):               "comment on table",
 	reflect.TypeOf(&commentOnSchemaNode{}):              "comment on schema",
@@ -370,11 +375,13 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&controlSchedulesNode{}):             "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):               "create database",
 	reflect.TypeOf(&createExtensionNode{}):              "create extension",
+	reflect.TypeOf(&createFunctionNode{}):               "create function",
 	reflect.TypeOf(&createIndexNode{}):                  "create index",
 	reflect.TypeOf(&createSequenceNode{}):               "create sequence",
 	reflect.TypeOf(&createSchemaNode{}):                 "create schema",
 	reflect.TypeOf(&createStatsNode{}):                  "create statistics",
 	reflect.TypeOf(&createTableNode{}):                  "create table",
+	reflect.TypeOf(&createTriggerNode{}):                "create trigger",
 	reflect.TypeOf(&createTypeNode{}):                   "create type",
 	reflect.TypeOf(&CreateRoleNode{}):                   "create user/role",
 	reflect.TypeOf(&createViewNode{}):                   "create view",
@@ -383,10 +390,12 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&deleteRangeNode{}):                  "delete range",
 	reflect.TypeOf(&distinctNode{}):                     "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):                 "drop database",
+	reflect.TypeOf(&dropFunctionNode{}):                 "drop function",
 	reflect.TypeOf(&dropIndexNode{}):                    "drop index",
 	reflect.TypeOf(&dropSequenceNode{}):                 "drop sequence",
 	reflect.TypeOf(&dropSchemaNode{}):                   "drop schema",
 	reflect.TypeOf(&dropTableNode{}):                    "drop table",
+	reflect.TypeOf(&dropTriggerNode{}):                  "drop trigger",
 	reflect.TypeOf(&dropTypeNode{}):                     "drop type",
 	reflect.TypeOf(&DropRoleNode{}):                     "drop user/role",
 	reflect.TypeOf(&dropViewNode{}):                     "drop view",
@@ -0,0 +1,193 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/errors"
+)
+
+// FileCipherStreamCreator wraps the KeyManager interface and provides functions to create a
+// FileStream for either a new file (using the active key provided by the KeyManager) or an
+// existing file (by looking up the key in the KeyManager).
+type FileCipherStreamCreator struct {
+	envType    enginepb.EnvType
+	keyManager PebbleKeyManager
+}
+
+const (
+	// The difference is 4 bytes, which are supplied by the counter.
+	ctrBlockSize = 16
+	ctrNonceSize = 12
+)
+
+// CreateNew creates a FileStream for a new file using the currently active key. It returns the
+// settings used, so that the caller can record these in a file registry.
+func (c *FileCipherStreamCreator) CreateNew(
+	ctx context.Context,
+) (*encryptionpb.EncryptionSettings, FileStream, error) {
+	key, err := c.keyManager.ActiveKeyForWriter(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings := &encryptionpb.EncryptionSettings{}
+	if key == nil || key.Info.EncryptionType == encryptionpb.EncryptionType_Plaintext {
+		settings.EncryptionType = encryptionpb.EncryptionType_Plaintext
+		stream := &filePlainStream{}
+		return settings, stream, nil
+	}
+	settings.EncryptionType = key.Info.EncryptionType
+	settings.KeyId = key.Info.KeyId
+	settings.Nonce = make([]byte, ctrNonceSize)
+	if _, err := rand.Read(settings.Nonce); err != nil {
+		return nil, nil, err
+	}
+	counterBytes := make([]byte, 4)
+	if _, err := rand.Read(counterBytes); err != nil {
+		return nil, nil, err
+	}
+	// Does not matter how we convert 4 random bytes into uint32
+	settings.Counter = binary.LittleEndian.Uint32(counterBytes)
+	ctrCS, err := newCTRBlockCipherStream(key, settings.Nonce, settings.Counter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return settings, &fileCipherStream{bcs: ctrCS}, nil
+}
+
+// CreateExisting creates a FileStream for an existing file by looking up the key described by
+// settings in the key manager.
+func (c *FileCipherStreamCreator) CreateExisting(
+	settings *encryptionpb.EncryptionSettings,
+) (FileStream, error) {
+	if settings == nil || settings.EncryptionType == encryptionpb.EncryptionType_Plaintext {
+		return &filePlainStream{}, nil
+	}
+	key, err := c.keyManager.GetKey(settings.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	ctrCS, err := newCTRBlockCipherStream(key, settings.Nonce, settings.Counter)
+	if err != nil {
+		return nil, err
+	}
+	return &fileCipherStream{bcs: ctrCS}, nil
+}
+
+// FileStream encrypts/decrypts byte slices at arbitrary file offsets.
+//
+// There are two implementations: a noop filePlainStream and a fileCipherStream that wraps
+// a ctrBlockCipherStream. The ctrBlockCipherStream does AES in counter mode (CTR). CTR
+// allows us to encrypt/decrypt at arbitrary file offsets without reading data elsewhere
+// in the file. CTR mode is the mode used by the encryption-at-rest implementation since
+// its first release.
+type FileStream interface {
+	// Encrypt encrypts the data, starting at fileOffset.
+	Encrypt(fileOffset int64, data []byte)
+	// Decrypt decrypts the data, starting at fileOffset.
+	Decrypt(fileOffset int64, data []byte)
+}
+
+// Implements a noop FileStream.
+type filePlainStream struct{}
+
+func (s *filePlainStream) Encrypt(fileOffset int64, data []byte) {}
+func (s *filePlainStream) Decrypt(fileOffset int64, data []byte) {}
+
+// Implements a FileStream with AES-CTR.
+type fileCipherStream struct {
+	bcs *cTRBlockCipherStream
+}
+
+// Encrypt implements the FileStream interface.
+func (s *fileCipherStream) Encrypt(fileOffset int64, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	blockIndex := uint64(fileOffset / int64(ctrBlockSize))
+	blockOffset := int(fileOffset % int64(ctrBlockSize))
+	var scratch [ctrBlockSize]byte
+	for len(data) > 0 {
+		// The num bytes that must be encrypted in this block (starting from offset).
+		dataLen := ctrBlockSize - blockOffset
+		if dataLen > len(data) {
+			dataLen = len(data)
+		}
+		// CTR works bytewise, so the bytes of scratch outside of the data being
+		// transformed can be ignored.
+		copy(scratch[blockOffset:blockOffset+dataLen], data[:dataLen])
+		s.bcs.transform(blockIndex, scratch[:])
+		copy(data[:dataLen], scratch[blockOffset:blockOffset+dataLen])
+		data = data[dataLen:]
+		blockOffset = 0
+		blockIndex++
+	}
+}
+
+// Decrypt implements the FileStream interface.
+func (s *fileCipherStream) Decrypt(fileOffset int64, data []byte) {
+	// AES-CTR is symmetric.
+	s.Encrypt(fileOffset, data)
+}
+
+// AES in CTR mode.
+type cTRBlockCipherStream struct {
+	key     *encryptionpb.SecretKey
+	nonce   [ctrNonceSize]byte
+	counter uint32
+
+	cBlock cipher.Block
+}
+
+func newCTRBlockCipherStream(
+	key *encryptionpb.SecretKey, nonce []byte, counter uint32,
+) (*cTRBlockCipherStream, error) {
+	switch key.Info.EncryptionType {
+	case encryptionpb.EncryptionType_AES128_CTR, encryptionpb.EncryptionType_AES192_CTR,
+		encryptionpb.EncryptionType_AES256_CTR:
+	default:
+		return nil, errors.Errorf("unknown encryption type %s", key.Info.EncryptionType)
+	}
+	if len(nonce) != ctrNonceSize {
+		return nil, errors.Errorf("nonce size %d is not equal to %d", len(nonce), ctrNonceSize)
+	}
+	stream := &cTRBlockCipherStream{key: key, counter: counter}
+	copy(stream.nonce[:], nonce)
+	var err error
+	if stream.cBlock, err = aes.NewCipher(key.Key); err != nil {
+		return nil, err
+	}
+	if stream.cBlock.BlockSize() != ctrBlockSize {
+		return nil, errors.Errorf("unexpected block size %d", stream.cBlock.BlockSize())
+	}
+	return stream, nil
+}
+
+// For CTR, decryption and encryption are the same. data must have length equal
+// to ctrBlockSize.
+func (s *cTRBlockCipherStream) transform(blockIndex uint64, data []byte) {
+	var iv [ctrBlockSize]byte
+	copy(iv[:], s.nonce[:])
+	// Add the counter to the block index, wrapping around on overflow.
+	binary.BigEndian.PutUint32(iv[ctrNonceSize:], uint32(uint64(s.counter)+blockIndex))
+	var keyStream [ctrBlockSize]byte
+	s.cBlock.Encrypt(keyStream[:], iv[:])
+	for i := 0; i < ctrBlockSize; i++ {
+		data[i] = data[i] ^ keyStream[i]
+	}
+}
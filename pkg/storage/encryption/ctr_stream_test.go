@@ -0,0 +1,173 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeyManager is a PebbleKeyManager with a fixed set of keys.
+type testKeyManager struct {
+	keys     map[string]*encryptionpb.SecretKey
+	activeID string
+}
+
+var _ PebbleKeyManager = &testKeyManager{}
+
+func (m *testKeyManager) ActiveKeyForWriter(ctx context.Context) (*encryptionpb.SecretKey, error) {
+	return m.GetKey(m.activeID)
+}
+
+func (m *testKeyManager) ActiveKeyInfoForStats() *encryptionpb.KeyInfo {
+	key, _ := m.GetKey(m.activeID)
+	if key == nil {
+		return nil
+	}
+	return key.Info
+}
+
+func (m *testKeyManager) GetKey(id string) (*encryptionpb.SecretKey, error) {
+	key, found := m.keys[id]
+	if !found {
+		return nil, fmt.Errorf("key %s not found", id)
+	}
+	return key, nil
+}
+
+func makeTestSecretKey(t *testing.T, encType encryptionpb.EncryptionType, id string) *encryptionpb.SecretKey {
+	var keyLength int
+	switch encType {
+	case encryptionpb.EncryptionType_AES128_CTR:
+		keyLength = 16
+	case encryptionpb.EncryptionType_AES192_CTR:
+		keyLength = 24
+	case encryptionpb.EncryptionType_AES256_CTR:
+		keyLength = 32
+	}
+	key := &encryptionpb.SecretKey{
+		Info: &encryptionpb.KeyInfo{EncryptionType: encType, KeyId: id},
+		Key:  make([]byte, keyLength),
+	}
+	_, err := rand.Read(key.Key)
+	require.NoError(t, err)
+	return key
+}
+
+// Checks that encrypting and decrypting at arbitrary offsets matches the
+// output of the standard library's CTR implementation.
+func TestFileCipherStream(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng, _ := randutil.NewTestRand()
+	for _, encType := range []encryptionpb.EncryptionType{
+		encryptionpb.EncryptionType_AES128_CTR,
+		encryptionpb.EncryptionType_AES192_CTR,
+		encryptionpb.EncryptionType_AES256_CTR,
+	} {
+		t.Run(encType.String(), func(t *testing.T) {
+			key := makeTestSecretKey(t, encType, "foo")
+			creator := &FileCipherStreamCreator{
+				envType: enginepb.EnvType_Data,
+				keyManager: &testKeyManager{
+					keys:     map[string]*encryptionpb.SecretKey{"foo": key},
+					activeID: "foo",
+				},
+			}
+			settings, stream, err := creator.CreateNew(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, encType, settings.EncryptionType)
+			require.Equal(t, "foo", settings.KeyId)
+
+			plaintext := make([]byte, 1000)
+			_, _ = rng.Read(plaintext)
+
+			// The expected ciphertext, computed with the standard library.
+			var iv [ctrBlockSize]byte
+			copy(iv[:], settings.Nonce)
+			binary.BigEndian.PutUint32(iv[ctrNonceSize:], settings.Counter)
+			block, err := aes.NewCipher(key.Key)
+			require.NoError(t, err)
+			expected := make([]byte, len(plaintext))
+			cipher.NewCTR(block, iv[:]).XORKeyStream(expected, plaintext)
+
+			// Encrypt in randomly sized pieces.
+			ciphertext := append([]byte(nil), plaintext...)
+			for offset := 0; offset < len(ciphertext); {
+				n := 1 + rng.Intn(50)
+				if offset+n > len(ciphertext) {
+					n = len(ciphertext) - offset
+				}
+				stream.Encrypt(int64(offset), ciphertext[offset:offset+n])
+				offset += n
+			}
+			require.Equal(t, expected, ciphertext)
+
+			// Decrypt random ranges with a stream for the existing file.
+			stream, err = creator.CreateExisting(settings)
+			require.NoError(t, err)
+			for i := 0; i < 100; i++ {
+				start := rng.Intn(len(ciphertext))
+				end := start + rng.Intn(len(ciphertext)-start+1)
+				data := append([]byte(nil), ciphertext[start:end]...)
+				stream.Decrypt(int64(start), data)
+				require.True(t, bytes.Equal(plaintext[start:end], data))
+			}
+		})
+	}
+}
+
+func TestFileCipherStreamPlaintext(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	creator := &FileCipherStreamCreator{
+		envType: enginepb.EnvType_Data,
+		keyManager: &testKeyManager{
+			keys: map[string]*encryptionpb.SecretKey{
+				plainKeyID: {Info: &encryptionpb.KeyInfo{
+					EncryptionType: encryptionpb.EncryptionType_Plaintext, KeyId: plainKeyID,
+				}},
+			},
+			activeID: plainKeyID,
+		},
+	}
+	settings, stream, err := creator.CreateNew(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, encryptionpb.EncryptionType_Plaintext, settings.EncryptionType)
+
+	data := []byte("hello world")
+	stream.Encrypt(5, data)
+	require.Equal(t, []byte("hello world"), data)
+
+	// Files without settings are plaintext.
+	stream, err = creator.CreateExisting(nil)
+	require.NoError(t, err)
+	stream.Decrypt(0, data)
+	require.Equal(t, []byte("hello world"), data)
+
+	// Unknown keys are an error.
+	_, err = creator.CreateExisting(&encryptionpb.EncryptionSettings{
+		EncryptionType: encryptionpb.EncryptionType_AES128_CTR, KeyId: "bar",
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,331 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package encryption implements encryption-at-rest for Pebble stores.
+//
+// High-level design:
+//
+// A Pebble store can be configured with a store key, supplied by the user in
+// a key file (see `cockroach gen encryption-key`). The store key is only used
+// to encrypt the registry of data keys (see DataKeyManager). The data keys are
+// generated by the DataKeyManager and used to encrypt all files written by
+// Pebble. They are rotated periodically, and whenever the store key changes.
+//
+// Each file written through one of the encrypted filesystems is recorded in
+// the storage.PebbleFileRegistry, together with the env type (store or data)
+// and the encryption settings (key ID, nonce and counter) needed to decrypt
+// it. Files absent from the registry are plaintext, which allows enabling
+// encryption on an existing store: the old files are rewritten with data keys
+// as they are compacted.
+//
+// Files are encrypted with AES in counter mode (see ctr_stream.go), which
+// allows reading and writing at arbitrary offsets.
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/pebble/vfs"
+)
+
+// encryptedFile implements vfs.File.
+type encryptedFile struct {
+	vfs.File
+	mu struct {
+		syncutil.Mutex
+		rOffset int64
+		wOffset int64
+		// wBuf is reused across writes to hold the encrypted data, since the
+		// caller's buffer must not be modified.
+		wBuf []byte
+	}
+	stream FileStream
+}
+
+// Write implements io.Writer. Writes always happen at the end of the data
+// written through this file.
+func (f *encryptedFile) Write(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if cap(f.mu.wBuf) < len(p) {
+		f.mu.wBuf = make([]byte, len(p))
+	}
+	buf := f.mu.wBuf[:len(p)]
+	copy(buf, p)
+	f.stream.Encrypt(f.mu.wOffset, buf)
+	n, err = f.File.Write(buf)
+	f.mu.wOffset += int64(n)
+	return n, err
+}
+
+// Read implements io.Reader.
+func (f *encryptedFile) Read(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n, err = f.ReadAt(p, f.mu.rOffset)
+	f.mu.rOffset += int64(n)
+	if err == io.EOF && n > 0 {
+		// io.Reader permits returning the error on the next call.
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt implements io.ReaderAt.
+func (f *encryptedFile) ReadAt(p []byte, off int64) (n int, err error) {
+	n, err = f.File.ReadAt(p, off)
+	if n > 0 {
+		f.stream.Decrypt(off, p[:n])
+	}
+	return n, err
+}
+
+// encryptedFS implements vfs.FS. Files created through it are encrypted with
+// the active key of the stream creator's key manager, and are recorded in the
+// file registry.
+type encryptedFS struct {
+	vfs.FS
+	fileRegistry  *storage.PebbleFileRegistry
+	streamCreator *FileCipherStreamCreator
+}
+
+var _ vfs.FS = &encryptedFS{}
+
+// Create implements vfs.FS.Create.
+func (fs *encryptedFS) Create(name string) (vfs.File, error) {
+	f, err := fs.FS.Create(name)
+	if err != nil {
+		return f, err
+	}
+	// NB: f.Close() must be called except in the case of a successful return.
+	settings, stream, err := fs.streamCreator.CreateNew(context.TODO())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// Add an entry for the file to the registry.
+	fproto := &enginepb.FileEntry{}
+	fproto.EnvType = fs.streamCreator.envType
+	if fproto.EncryptionSettings, err = protoutil.Marshal(settings); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := fs.fileRegistry.SetFileEntry(name, fproto); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &encryptedFile{File: f, stream: stream}, nil
+}
+
+// Link implements vfs.FS.Link.
+func (fs *encryptedFS) Link(oldname, newname string) error {
+	if err := fs.FS.Link(oldname, newname); err != nil {
+		return err
+	}
+	return fs.fileRegistry.MaybeLinkEntry(oldname, newname)
+}
+
+// Open implements vfs.FS.Open.
+func (fs *encryptedFS) Open(name string, opts ...vfs.OpenOption) (vfs.File, error) {
+	f, err := fs.FS.Open(name, opts...)
+	if err != nil {
+		return f, err
+	}
+	fileEntry := fs.fileRegistry.GetFileEntry(name)
+	var settings *encryptionpb.EncryptionSettings
+	if fileEntry != nil {
+		if fileEntry.EnvType != fs.streamCreator.envType {
+			f.Close()
+			return nil, fmt.Errorf("filename: %s has env %d not equal to FS env %d",
+				name, fileEntry.EnvType, fs.streamCreator.envType)
+		}
+		settings = &encryptionpb.EncryptionSettings{}
+		if err := protoutil.Unmarshal(fileEntry.EncryptionSettings, settings); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	stream, err := fs.streamCreator.CreateExisting(settings)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &encryptedFile{File: f, stream: stream}, nil
+}
+
+// Remove implements vfs.FS.Remove.
+func (fs *encryptedFS) Remove(name string) error {
+	if err := fs.FS.Remove(name); err != nil {
+		return err
+	}
+	return fs.fileRegistry.MaybeDeleteEntry(name)
+}
+
+// Rename implements vfs.FS.Rename. The file registry entry is copied before
+// the rename, so that a crash in between cannot leave an encrypted file
+// without an entry.
+func (fs *encryptedFS) Rename(oldname, newname string) error {
+	if err := fs.fileRegistry.MaybeCopyEntry(oldname, newname); err != nil {
+		return err
+	}
+	if err := fs.FS.Rename(oldname, newname); err != nil {
+		return err
+	}
+	return fs.fileRegistry.MaybeDeleteEntry(oldname)
+}
+
+// ReuseForWrite implements vfs.FS.ReuseForWrite.
+//
+// We cannot change the encryption settings of a file that is reused, since it
+// is written from the start. So this renames the file and then creates it
+// anew, which generates new settings.
+func (fs *encryptedFS) ReuseForWrite(oldname, newname string) (vfs.File, error) {
+	if err := fs.Rename(oldname, newname); err != nil {
+		return nil, err
+	}
+	return fs.Create(newname)
+}
+
+// encryptionStatsHandler implements storage.EncryptionStatsHandler.
+type encryptionStatsHandler struct {
+	storeKM *StoreKeyManager
+	dataKM  *DataKeyManager
+}
+
+var _ storage.EncryptionStatsHandler = &encryptionStatsHandler{}
+
+func (e *encryptionStatsHandler) GetEncryptionStatus() ([]byte, error) {
+	var s encryptionpb.EncryptionStatus
+	if e.storeKM.activeKey != nil {
+		s.ActiveStoreKey = e.storeKM.activeKey.Info
+	}
+	s.ActiveDataKey = e.dataKM.ActiveKeyInfoForStats()
+	return protoutil.Marshal(&s)
+}
+
+func (e *encryptionStatsHandler) GetDataKeysRegistry() ([]byte, error) {
+	return protoutil.Marshal(e.dataKM.getScrubbedRegistry())
+}
+
+func (e *encryptionStatsHandler) GetActiveDataKeyID() (string, error) {
+	if k := e.dataKM.ActiveKeyInfoForStats(); k != nil {
+		return k.KeyId, nil
+	}
+	return plainKeyID, nil
+}
+
+func (e *encryptionStatsHandler) GetActiveStoreKeyType() int32 {
+	if e.storeKM.activeKey != nil {
+		return int32(e.storeKM.activeKey.Info.EncryptionType)
+	}
+	return int32(encryptionpb.EncryptionType_Plaintext)
+}
+
+func (e *encryptionStatsHandler) GetKeyIDFromSettings(settings []byte) (string, error) {
+	var s encryptionpb.EncryptionSettings
+	if err := protoutil.Unmarshal(settings, &s); err != nil {
+		return "", err
+	}
+	return s.KeyId, nil
+}
+
+// newEncryptedEnv creates an encrypted environment and returns the vfs.FS to
+// use for reading and writing data. The optionBytes is a binary serialized
+// encryptionpb.EncryptionOptions.
+func newEncryptedEnv(
+	fs vfs.FS, fr *storage.PebbleFileRegistry, dbDir string, readOnly bool, optionBytes []byte,
+) (*storage.EncryptionEnv, error) {
+	options := &encryptionpb.EncryptionOptions{}
+	if err := protoutil.Unmarshal(optionBytes, options); err != nil {
+		return nil, err
+	}
+	if options.KeySource != encryptionpb.EncryptionKeySource_KeyFiles {
+		return nil, fmt.Errorf("unknown encryption key source: %d", options.KeySource)
+	}
+	ctx := context.TODO()
+	storeKeyManager := &StoreKeyManager{
+		fs:                fs,
+		activeKeyFilename: options.KeyFiles.CurrentKey,
+		oldKeyFilename:    options.KeyFiles.OldKey,
+	}
+	if err := storeKeyManager.Load(ctx); err != nil {
+		return nil, err
+	}
+	storeFS := &encryptedFS{
+		FS:           fs,
+		fileRegistry: fr,
+		streamCreator: &FileCipherStreamCreator{
+			envType:    enginepb.EnvType_Store,
+			keyManager: storeKeyManager,
+		},
+	}
+	dataKeyManager := &DataKeyManager{
+		fs:             storeFS,
+		dbDir:          dbDir,
+		rotationPeriod: options.DataKeyRotationPeriod,
+		readOnly:       readOnly,
+	}
+	if err := dataKeyManager.Load(ctx); err != nil {
+		return nil, err
+	}
+	dataFS := &encryptedFS{
+		FS:           fs,
+		fileRegistry: fr,
+		streamCreator: &FileCipherStreamCreator{
+			envType:    enginepb.EnvType_Data,
+			keyManager: dataKeyManager,
+		},
+	}
+
+	if !readOnly {
+		key, err := storeKeyManager.ActiveKeyForWriter(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := dataKeyManager.SetActiveStoreKeyInfo(ctx, key.Info); err != nil {
+			return nil, err
+		}
+	}
+
+	return &storage.EncryptionEnv{
+		Closer: dataKeyManager,
+		FS:     dataFS,
+		StatsHandler: &encryptionStatsHandler{
+			storeKM: storeKeyManager,
+			dataKM:  dataKeyManager,
+		},
+	}, nil
+}
+
+// canRegistryElide returns true for file registry entries of plaintext files,
+// since the absence of an entry implies plaintext.
+func canRegistryElide(entry *enginepb.FileEntry) bool {
+	if entry == nil {
+		return true
+	}
+	settings := &encryptionpb.EncryptionSettings{}
+	if err := protoutil.Unmarshal(entry.EncryptionSettings, settings); err != nil {
+		return false
+	}
+	return settings.EncryptionType == encryptionpb.EncryptionType_Plaintext
+}
+
+func init() {
+	storage.NewEncryptedEnvFunc = newEncryptedEnv
+	storage.CanRegistryElideFunc = canRegistryElide
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedFS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	mem := vfs.NewMem()
+	require.NoError(t, mem.MkdirAll("/bar", 0755))
+	keyID := writeTestKeyFile(t, mem, "16.key", 16)
+
+	ctx := context.Background()
+	fr := &storage.PebbleFileRegistry{FS: mem, DBDir: "/bar"}
+	require.NoError(t, fr.Load())
+	defer func() { require.NoError(t, fr.Close()) }()
+
+	optionBytes, err := StoreEncryptionSpec{
+		Path:           "/bar",
+		KeyPath:        "16.key",
+		OldKeyPath:     plainKeyName,
+		RotationPeriod: DefaultRotationPeriod,
+	}.ToEncryptionOptions()
+	require.NoError(t, err)
+	env, err := newEncryptedEnv(mem, fr, "/bar", false /* readOnly */, optionBytes)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, env.Closer.Close()) }()
+
+	// The key registry is encrypted with the store key.
+	var settings encryptionpb.EncryptionSettings
+	entry := fr.GetFileEntry("/bar/" + KeyRegistryFilename)
+	require.NotNil(t, entry)
+	require.NoError(t, protoutil.Unmarshal(entry.EncryptionSettings, &settings))
+	require.Equal(t, keyID, settings.KeyId)
+
+	contents := []byte("this is some secret data that spans more than one block")
+	f, err := env.FS.Create("/bar/foo")
+	require.NoError(t, err)
+	_, err = f.Write(contents[:10])
+	require.NoError(t, err)
+	_, err = f.Write(contents[10:])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// The file is encrypted with the active data key.
+	entry = fr.GetFileEntry("/bar/foo")
+	require.NotNil(t, entry)
+	require.NoError(t, protoutil.Unmarshal(entry.EncryptionSettings, &settings))
+	dataKeyID, err := env.StatsHandler.GetActiveDataKeyID()
+	require.NoError(t, err)
+	require.Equal(t, dataKeyID, settings.KeyId)
+
+	readRaw := func(fs vfs.FS, name string) []byte {
+		f, err := fs.Open(name)
+		require.NoError(t, err)
+		defer f.Close()
+		b, err := ioutil.ReadAll(f)
+		require.NoError(t, err)
+		return b
+	}
+	require.False(t, bytes.Contains(readRaw(mem, "/bar/foo"), []byte("secret")))
+	require.Equal(t, contents, readRaw(env.FS, "/bar/foo"))
+
+	// ReadAt at an arbitrary offset.
+	f, err = env.FS.Open("/bar/foo")
+	require.NoError(t, err)
+	buf := make([]byte, 6)
+	_, err = f.ReadAt(buf, 13)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), buf)
+	require.NoError(t, f.Close())
+
+	// Renames and links carry the registry entry along.
+	require.NoError(t, env.FS.Rename("/bar/foo", "/bar/baz"))
+	require.Nil(t, fr.GetFileEntry("/bar/foo"))
+	require.Equal(t, contents, readRaw(env.FS, "/bar/baz"))
+	require.NoError(t, env.FS.Link("/bar/baz", "/bar/qux"))
+	require.Equal(t, contents, readRaw(env.FS, "/bar/qux"))
+	require.NoError(t, env.FS.Remove("/bar/baz"))
+	require.Nil(t, fr.GetFileEntry("/bar/baz"))
+	require.Equal(t, contents, readRaw(env.FS, "/bar/qux"))
+
+	// Files that are not in the registry are plaintext.
+	f, err = mem.Create("/bar/plain")
+	require.NoError(t, err)
+	_, err = f.Write(contents)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Equal(t, contents, readRaw(env.FS, "/bar/plain"))
+}
+
+func TestPebbleEncryption(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	writeTestKeyFile(t, vfs.Default, filepath.Join(dir, "16.key"), 16)
+	writeTestKeyFile(t, vfs.Default, filepath.Join(dir, "other.key"), 16)
+	dbDir := filepath.Join(dir, "db")
+
+	openEngine := func(key string) (*storage.Pebble, error) {
+		optionBytes, err := StoreEncryptionSpec{
+			Path:           dbDir,
+			KeyPath:        filepath.Join(dir, key),
+			OldKeyPath:     plainKeyName,
+			RotationPeriod: DefaultRotationPeriod,
+		}.ToEncryptionOptions()
+		require.NoError(t, err)
+		return storage.Open(context.Background(), storage.Filesystem(dbDir),
+			storage.CacheSize(1<<20), storage.EncryptionAtRest(optionBytes))
+	}
+
+	key := roachpb.Key("a")
+	value := []byte("a very secret value")
+	db, err := openEngine("16.key")
+	require.NoError(t, err)
+	require.NoError(t, db.PutUnversioned(key, value))
+	require.NoError(t, db.Flush())
+
+	stats, err := db.GetEnvStats()
+	require.NoError(t, err)
+	require.Equal(t, int32(encryptionpb.EncryptionType_AES128_CTR), stats.EncryptionType)
+	require.Greater(t, stats.ActiveKeyFiles, uint64(0))
+	var status encryptionpb.EncryptionStatus
+	require.NoError(t, protoutil.Unmarshal(stats.EncryptionStatus, &status))
+	require.Equal(t, encryptionpb.EncryptionType_AES128_CTR, status.ActiveStoreKey.EncryptionType)
+	require.Equal(t, encryptionpb.EncryptionType_AES128_CTR, status.ActiveDataKey.EncryptionType)
+	db.Close()
+
+	// None of the files on disk contain the value in plaintext.
+	files, err := ioutil.ReadDir(dbDir)
+	require.NoError(t, err)
+	for _, fi := range files {
+		if fi.IsDir() {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dbDir, fi.Name()))
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(b, value), "found plaintext value in %s", fi.Name())
+	}
+
+	// The store cannot be opened with another key.
+	_, err = openEngine("other.key")
+	require.Error(t, err)
+
+	// Reopening with the same key reads the data back.
+	db, err = openEngine("16.key")
+	require.NoError(t, err)
+	defer db.Close()
+	v, err := db.MVCCGet(storage.MakeMVCCMetadataKey(key))
+	require.NoError(t, err)
+	require.Equal(t, value, v)
+}
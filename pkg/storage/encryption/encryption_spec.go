@@ -0,0 +1,241 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encryption
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/pflag"
+)
+
+// DefaultRotationPeriod is the default rotation period used by data key
+// managers.
+const DefaultRotationPeriod = time.Hour * 24 * 7 // 1 week, give or take time changes.
+
+// plainKeyName is the name of the plaintext key.
+const plainKeyName = "plain"
+
+// StoreEncryptionSpec contains the details that can be specified in the cli
+// via the --enterprise-encryption flag.
+type StoreEncryptionSpec struct {
+	Path           string
+	KeyPath        string
+	OldKeyPath     string
+	RotationPeriod time.Duration
+}
+
+// ToEncryptionOptions converts the spec to a serialized EncryptionOptions
+// protobuf.
+func (es StoreEncryptionSpec) ToEncryptionOptions() ([]byte, error) {
+	opts := encryptionpb.EncryptionOptions{
+		KeySource: encryptionpb.EncryptionKeySource_KeyFiles,
+		KeyFiles: &encryptionpb.EncryptionKeyFiles{
+			CurrentKey: es.KeyPath,
+			OldKey:     es.OldKeyPath,
+		},
+		DataKeyRotationPeriod: int64(es.RotationPeriod / time.Second),
+	}
+	return protoutil.Marshal(&opts)
+}
+
+// String returns a fully parsable version of the encryption spec.
+func (es StoreEncryptionSpec) String() string {
+	// All fields are set.
+	return fmt.Sprintf("path=%s,key=%s,old-key=%s,rotation-period=%s",
+		es.Path, es.KeyPath, es.OldKeyPath, es.RotationPeriod)
+}
+
+// NewStoreEncryptionSpec parses the string passed in and returns a new
+// StoreEncryptionSpec if parsing succeeds.
+func NewStoreEncryptionSpec(value string) (StoreEncryptionSpec, error) {
+	const pathField = "path"
+	var es StoreEncryptionSpec
+	es.RotationPeriod = DefaultRotationPeriod
+
+	used := make(map[string]struct{})
+	for _, split := range strings.Split(value, ",") {
+		if len(split) == 0 {
+			continue
+		}
+		subSplits := strings.SplitN(split, "=", 2)
+		if len(subSplits) == 1 {
+			return StoreEncryptionSpec{}, fmt.Errorf("field not in the form <key>=<value>: %s", split)
+		}
+		field := strings.ToLower(subSplits[0])
+		value := subSplits[1]
+		if _, ok := used[field]; ok {
+			return StoreEncryptionSpec{}, fmt.Errorf("%s field was used twice in encryption definition", field)
+		}
+		used[field] = struct{}{}
+
+		if len(field) == 0 {
+			return StoreEncryptionSpec{}, fmt.Errorf("empty field")
+		}
+		if len(value) == 0 {
+			return StoreEncryptionSpec{}, fmt.Errorf("no value specified for %s", field)
+		}
+
+		switch field {
+		case pathField:
+			var err error
+			es.Path, err = base.GetAbsoluteStorePath(pathField, value)
+			if err != nil {
+				return StoreEncryptionSpec{}, err
+			}
+		case "key":
+			if value == plainKeyName {
+				es.KeyPath = plainKeyName
+			} else {
+				var err error
+				es.KeyPath, err = base.GetAbsoluteStorePath("key", value)
+				if err != nil {
+					return StoreEncryptionSpec{}, err
+				}
+			}
+		case "old-key":
+			if value == plainKeyName {
+				es.OldKeyPath = plainKeyName
+			} else {
+				var err error
+				es.OldKeyPath, err = base.GetAbsoluteStorePath("old-key", value)
+				if err != nil {
+					return StoreEncryptionSpec{}, err
+				}
+			}
+		case "rotation-period":
+			var err error
+			es.RotationPeriod, err = time.ParseDuration(value)
+			if err != nil {
+				return StoreEncryptionSpec{}, errors.Wrapf(err, "could not parse rotation-duration value: %s", value)
+			}
+			if es.RotationPeriod < time.Second {
+				return StoreEncryptionSpec{}, fmt.Errorf("rotation-period must be at least 1s, got %s", value)
+			}
+		default:
+			return StoreEncryptionSpec{}, fmt.Errorf("%s is not a valid enterprise-encryption field", field)
+		}
+	}
+
+	// Check that all fields are set.
+	if es.Path == "" {
+		return StoreEncryptionSpec{}, fmt.Errorf("no path specified")
+	}
+	if es.KeyPath == "" {
+		return StoreEncryptionSpec{}, fmt.Errorf("no key specified")
+	}
+	if es.OldKeyPath == "" {
+		return StoreEncryptionSpec{}, fmt.Errorf("no old-key specified")
+	}
+
+	return es, nil
+}
+
+// EncryptionSpecList contains a slice of StoreEncryptionSpecs that implements
+// pflag's value interface.
+type EncryptionSpecList struct {
+	Specs []StoreEncryptionSpec
+}
+
+var _ pflag.Value = &EncryptionSpecList{}
+
+// String returns a string representation of all the StoreEncryptionSpecs.
+// This is part of pflag's value interface.
+func (encl EncryptionSpecList) String() string {
+	var buffer bytes.Buffer
+	for _, ss := range encl.Specs {
+		fmt.Fprintf(&buffer, "--%s=%s ", cliflags.EnterpriseEncryption.Name, ss)
+	}
+	// Trim the extra space from the end if it exists.
+	if l := buffer.Len(); l > 0 {
+		buffer.Truncate(l - 1)
+	}
+	return buffer.String()
+}
+
+// Type returns the underlying type in string form. This is part of pflag's
+// value interface.
+func (encl *EncryptionSpecList) Type() string {
+	return "EncryptionSpec"
+}
+
+// Set adds a new value to the EncryptionSpecList. It is the important part of
+// pflag's value interface.
+func (encl *EncryptionSpecList) Set(value string) error {
+	spec, err := NewStoreEncryptionSpec(value)
+	if err != nil {
+		return err
+	}
+	encl.Specs = append(encl.Specs, spec)
+	return nil
+}
+
+// PopulateStoreSpecWithEncryption iterates through the EncryptionSpecList and
+// looks for matching paths in the StoreSpecList. Any unmatched EncryptionSpec
+// causes an error.
+func PopulateStoreSpecWithEncryption(
+	storeSpecs base.StoreSpecList, encryptionSpecs EncryptionSpecList,
+) error {
+	for _, es := range encryptionSpecs.Specs {
+		found := false
+		for i := range storeSpecs.Specs {
+			if storeSpecs.Specs[i].Path != es.Path {
+				continue
+			}
+
+			// Found a matching path.
+			if storeSpecs.Specs[i].UseFileRegistry {
+				return fmt.Errorf("store with path %s already has an encryption setting",
+					storeSpecs.Specs[i].Path)
+			}
+
+			storeSpecs.Specs[i].UseFileRegistry = true
+			opts, err := es.ToEncryptionOptions()
+			if err != nil {
+				return err
+			}
+			storeSpecs.Specs[i].EncryptionOptions = opts
+			found = true
+			break
+		}
+		if !found {
+			return fmt.Errorf("no store with path %s found for encryption setting: %v", es.Path, es)
+		}
+	}
+	return nil
+}
+
+// EncryptionOptionsForStore takes a store directory and returns its
+// EncryptionOptions if a matching entry if found in the EncryptionSpecList.
+// The caller should appropriately populate the storage config.
+func EncryptionOptionsForStore(dir string, encryptionSpecs EncryptionSpecList) ([]byte, error) {
+	// We need an absolute path, but the input may have come in relative.
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find absolute path for %s ", dir)
+	}
+
+	for _, es := range encryptionSpecs.Specs {
+		if es.Path == path {
+			return es.ToEncryptionOptions()
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encryption
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewStoreEncryptionSpec verifies that the --enterprise-encryption arguments
+// are correctly parsed into StoreEncryptionSpecs.
+func TestNewStoreEncryptionSpec(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		value       string
+		expectedErr string
+		expected    StoreEncryptionSpec
+	}{
+		// path
+		{",", "no path specified", StoreEncryptionSpec{}},
+		{"", "no path specified", StoreEncryptionSpec{}},
+		{"/mnt/hda1", "field not in the form <key>=<value>: /mnt/hda1", StoreEncryptionSpec{}},
+		{"path=", "no value specified for path", StoreEncryptionSpec{}},
+		{"path=~/data", "path cannot start with '~': ~/data", StoreEncryptionSpec{}},
+		{"path=data,path=data2", "path field was used twice in encryption definition", StoreEncryptionSpec{}},
+
+		// The rest.
+		{"path=/data", "no key specified", StoreEncryptionSpec{}},
+		{"path=/data,key=/new.key", "no old-key specified", StoreEncryptionSpec{}},
+		{"path=/data,key=/new.key,old-key=/old.key,rotation-period=1", "could not parse rotation-duration value: 1", StoreEncryptionSpec{}},
+		{"path=/data,key=/new.key,old-key=/old.key,rotation-period=100ms", "rotation-period must be at least 1s, got 100ms", StoreEncryptionSpec{}},
+		{"path=/data,key=/new.key,old-key=/old.key,foo=bar", "foo is not a valid enterprise-encryption field", StoreEncryptionSpec{}},
+
+		// Valid values.
+		{"path=/data,key=/new.key,old-key=/old.key", "", StoreEncryptionSpec{Path: "/data", KeyPath: "/new.key", OldKeyPath: "/old.key", RotationPeriod: DefaultRotationPeriod}},
+		{"path=/data,key=plain,old-key=/old.key,rotation-period=1h", "", StoreEncryptionSpec{Path: "/data", KeyPath: "plain", OldKeyPath: "/old.key", RotationPeriod: time.Hour}},
+		{"path=/data,key=/new.key,old-key=plain", "", StoreEncryptionSpec{Path: "/data", KeyPath: "/new.key", OldKeyPath: "plain", RotationPeriod: DefaultRotationPeriod}},
+	}
+
+	for i, testCase := range testCases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			storeEncryptionSpec, err := NewStoreEncryptionSpec(testCase.value)
+			if testCase.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), testCase.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, storeEncryptionSpec)
+
+			// Now test String() to make sure the result can be parsed.
+			storeEncryptionSpecString := storeEncryptionSpec.String()
+			storeEncryptionSpec2, err := NewStoreEncryptionSpec(storeEncryptionSpecString)
+			require.NoError(t, err)
+			require.Equal(t, storeEncryptionSpec, storeEncryptionSpec2)
+		})
+	}
+}
+
+func TestPopulateStoreSpecWithEncryption(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	var encryptionSpecs EncryptionSpecList
+	require.NoError(t, encryptionSpecs.Set("path=data,key=/new.key,old-key=plain,rotation-period=1h"))
+
+	// An encryption spec must match a store.
+	storeSpecs := base.StoreSpecList{Specs: []base.StoreSpec{{Path: filepath.Join(cwd, "other")}}}
+	err = PopulateStoreSpecWithEncryption(storeSpecs, encryptionSpecs)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no store with path")
+
+	storeSpecs = base.StoreSpecList{Specs: []base.StoreSpec{
+		{Path: filepath.Join(cwd, "other")},
+		{Path: filepath.Join(cwd, "data")},
+	}}
+	require.NoError(t, PopulateStoreSpecWithEncryption(storeSpecs, encryptionSpecs))
+	require.False(t, storeSpecs.Specs[0].UseFileRegistry)
+	require.Nil(t, storeSpecs.Specs[0].EncryptionOptions)
+	require.True(t, storeSpecs.Specs[1].UseFileRegistry)
+
+	var opts encryptionpb.EncryptionOptions
+	require.NoError(t, protoutil.Unmarshal(storeSpecs.Specs[1].EncryptionOptions, &opts))
+	require.Equal(t, encryptionpb.EncryptionKeySource_KeyFiles, opts.KeySource)
+	require.Equal(t, "/new.key", opts.KeyFiles.CurrentKey)
+	require.Equal(t, "plain", opts.KeyFiles.OldKey)
+	require.Equal(t, int64(3600), opts.DataKeyRotationPeriod)
+
+	// Stores can only be configured once.
+	require.Error(t, PopulateStoreSpecWithEncryption(storeSpecs, encryptionSpecs))
+
+	// EncryptionOptionsForStore accepts relative paths.
+	b, err := EncryptionOptionsForStore("data", encryptionSpecs)
+	require.NoError(t, err)
+	require.Equal(t, storeSpecs.Specs[1].EncryptionOptions, b)
+	b, err = EncryptionOptionsForStore("other", encryptionSpecs)
+	require.NoError(t, err)
+	require.Nil(t, b)
+}
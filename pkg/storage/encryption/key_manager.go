@@ -0,0 +1,432 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// KeyRegistryFilename is the filename used for writing the data keys by the
+	// DataKeyManager. It is relative to the store directory.
+	KeyRegistryFilename = "COCKROACHDB_DATA_KEYS"
+
+	// The key filename for the StoreKeyManager when using plaintext.
+	storeFileNamePlain = "plain"
+	// The key id used in KeyInfos for plaintext.
+	plainKeyID = "plain"
+	// The length of a real key id.
+	keyIDLength = 32
+)
+
+// kmTimeNow is the time source used by the key managers. It is overridden in
+// tests.
+var kmTimeNow = timeutil.Now
+
+// PebbleKeyManager manages encryption keys. There are two implementations. See
+// encrypted_fs.go for high-level context.
+type PebbleKeyManager interface {
+	// ActiveKeyForWriter returns the currently active key for writing new
+	// files. A nil key means plaintext.
+	ActiveKeyForWriter(ctx context.Context) (*encryptionpb.SecretKey, error)
+	// ActiveKeyInfoForStats returns information about the currently active key,
+	// for observability. A nil return value means plaintext.
+	ActiveKeyInfoForStats() *encryptionpb.KeyInfo
+	// GetKey gets the key for the given id. Returns an error if the key was not
+	// found.
+	GetKey(id string) (*encryptionpb.SecretKey, error)
+}
+
+var _ PebbleKeyManager = &StoreKeyManager{}
+var _ PebbleKeyManager = &DataKeyManager{}
+
+// StoreKeyManager manages the user-provided store keys. Implements
+// PebbleKeyManager.
+type StoreKeyManager struct {
+	// Initialize the following before Load.
+	fs                vfs.FS
+	activeKeyFilename string
+	oldKeyFilename    string
+
+	// Implementation. Both are non-nil after a successful call to Load.
+	activeKey *encryptionpb.SecretKey
+	oldKey    *encryptionpb.SecretKey
+}
+
+// Load must be called before calling other functions.
+func (m *StoreKeyManager) Load(ctx context.Context) error {
+	var err error
+	m.activeKey, err = loadKeyFromFile(m.fs, m.activeKeyFilename)
+	if err != nil {
+		return err
+	}
+	m.oldKey, err = loadKeyFromFile(m.fs, m.oldKeyFilename)
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "loaded active store key: %s, old store key: %s",
+		proto.CompactTextString(m.activeKey.Info), proto.CompactTextString(m.oldKey.Info))
+	return nil
+}
+
+// ActiveKeyForWriter implements PebbleKeyManager.ActiveKeyForWriter.
+func (m *StoreKeyManager) ActiveKeyForWriter(ctx context.Context) (*encryptionpb.SecretKey, error) {
+	return m.activeKey, nil
+}
+
+// ActiveKeyInfoForStats implements PebbleKeyManager.ActiveKeyInfoForStats.
+func (m *StoreKeyManager) ActiveKeyInfoForStats() *encryptionpb.KeyInfo {
+	if m.activeKey == nil {
+		return nil
+	}
+	return m.activeKey.Info
+}
+
+// GetKey implements PebbleKeyManager.GetKey.
+func (m *StoreKeyManager) GetKey(id string) (*encryptionpb.SecretKey, error) {
+	if m.activeKey.Info.KeyId == id {
+		return m.activeKey, nil
+	}
+	if m.oldKey.Info.KeyId == id {
+		return m.oldKey, nil
+	}
+	return nil, fmt.Errorf("store key ID %s was not found", id)
+}
+
+// loadKeyFromFile reads a store key from filename. The file consists of
+// keyIDLength bytes of key ID followed by the raw key, as produced by
+// `cockroach gen encryption-key`. The special filename "plain" denotes
+// plaintext.
+func loadKeyFromFile(fs vfs.FS, filename string) (*encryptionpb.SecretKey, error) {
+	now := kmTimeNow().Unix()
+	key := &encryptionpb.SecretKey{}
+	key.Info = &encryptionpb.KeyInfo{}
+	if filename == storeFileNamePlain {
+		key.Info.EncryptionType = encryptionpb.EncryptionType_Plaintext
+		key.Info.KeyId = plainKeyID
+		key.Info.CreationTime = now
+		key.Info.Source = storeFileNamePlain
+		return key, nil
+	}
+
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	// keyIDLength bytes for the ID, followed by the key.
+	keyLength := len(b) - keyIDLength
+	switch keyLength {
+	case 16:
+		key.Info.EncryptionType = encryptionpb.EncryptionType_AES128_CTR
+	case 24:
+		key.Info.EncryptionType = encryptionpb.EncryptionType_AES192_CTR
+	case 32:
+		key.Info.EncryptionType = encryptionpb.EncryptionType_AES256_CTR
+	default:
+		return nil, fmt.Errorf("store key of unsupported length: %d", keyLength)
+	}
+	key.Key = b[keyIDLength:]
+	// Hex encoding to make it human readable.
+	key.Info.KeyId = hex.EncodeToString(b[:keyIDLength])
+	key.Info.CreationTime = now
+	key.Info.Source = filename
+
+	return key, nil
+}
+
+// DataKeyManager manages data keys. Implements PebbleKeyManager. Key rotation
+// is automatically enabled once SetActiveStoreKeyInfo has been called: the
+// active data key is replaced by a new one once it is older than the rotation
+// period, and whenever the active store key changes.
+//
+// The data keys are persisted in the key registry file, which is written using
+// the store FS, that is, encrypted using the active store key.
+type DataKeyManager struct {
+	// Initialize the following before Load.
+	fs             vfs.FS
+	dbDir          string
+	rotationPeriod int64 // seconds
+	readOnly       bool
+
+	// Implementation.
+	mu struct {
+		syncutil.Mutex
+		// Non-nil after Load().
+		keyRegistry *encryptionpb.DataKeysRegistry
+		// rotationEnabled => non-nil.
+		activeKey *encryptionpb.SecretKey
+		// Transitions to true when SetActiveStoreKeyInfo() is called for the
+		// first time.
+		rotationEnabled bool
+	}
+}
+
+func makeRegistryProto() *encryptionpb.DataKeysRegistry {
+	return &encryptionpb.DataKeysRegistry{
+		StoreKeys: make(map[string]*encryptionpb.KeyInfo),
+		DataKeys:  make(map[string]*encryptionpb.SecretKey),
+	}
+}
+
+// Close implements io.Closer.
+func (m *DataKeyManager) Close() error { return nil }
+
+// Load must be called before calling other methods.
+func (m *DataKeyManager) Load(ctx context.Context) error {
+	filename := m.fs.PathJoin(m.dbDir, KeyRegistryFilename)
+	_, err := m.fs.Stat(filename)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if oserror.IsNotExist(err) {
+		// File does not exist.
+		m.mu.keyRegistry = makeRegistryProto()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	f, err := m.fs.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	m.mu.keyRegistry = makeRegistryProto()
+	if err = protoutil.Unmarshal(b, m.mu.keyRegistry); err != nil {
+		return errors.Wrapf(err, "could not decode %s; is the correct store key provided?", filename)
+	}
+	if err = validateRegistry(m.mu.keyRegistry); err != nil {
+		return err
+	}
+	if m.mu.keyRegistry.ActiveDataKeyId != "" {
+		key, found := m.mu.keyRegistry.DataKeys[m.mu.keyRegistry.ActiveDataKeyId]
+		if !found {
+			// This should have resulted in an error in validateRegistry().
+			panic("unexpected inconsistent DataKeysRegistry")
+		}
+		m.mu.activeKey = key
+		log.Infof(ctx, "loaded active data key: %s", proto.CompactTextString(m.mu.activeKey.Info))
+	} else {
+		log.Infof(ctx, "no active data key yet")
+	}
+	return nil
+}
+
+// ActiveKeyForWriter implements PebbleKeyManager.ActiveKeyForWriter. It
+// rotates the active data key if it is older than the rotation period.
+func (m *DataKeyManager) ActiveKeyForWriter(ctx context.Context) (*encryptionpb.SecretKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mu.rotationEnabled {
+		now := kmTimeNow().Unix()
+		if now-m.mu.activeKey.Info.CreationTime > m.rotationPeriod {
+			keyRegistry := makeRegistryProto()
+			proto.Merge(keyRegistry, m.mu.keyRegistry)
+			if err := m.rotateDataKeyAndWrite(ctx, keyRegistry); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m.mu.activeKey, nil
+}
+
+// ActiveKeyInfoForStats implements PebbleKeyManager.ActiveKeyInfoForStats.
+func (m *DataKeyManager) ActiveKeyInfoForStats() *encryptionpb.KeyInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mu.activeKey == nil {
+		return nil
+	}
+	return m.mu.activeKey.Info
+}
+
+// GetKey implements PebbleKeyManager.GetKey.
+func (m *DataKeyManager) GetKey(id string) (*encryptionpb.SecretKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, found := m.mu.keyRegistry.DataKeys[id]
+	if !found {
+		return nil, fmt.Errorf("key %s is not found", id)
+	}
+	return key, nil
+}
+
+// SetActiveStoreKeyInfo sets the current active store key. Even though there
+// may be a valid active data key, a new one is generated when the store key
+// changes, so that the new data key is derived from the new store key.
+func (m *DataKeyManager) SetActiveStoreKeyInfo(
+	ctx context.Context, storeKeyInfo *encryptionpb.KeyInfo,
+) error {
+	if m.readOnly {
+		return errors.New("read only")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Enable data key rotation regardless of what case we go into.
+	m.mu.rotationEnabled = true
+	prevActiveStoreKey, found := m.mu.keyRegistry.StoreKeys[m.mu.keyRegistry.ActiveStoreKeyId]
+	if found && prevActiveStoreKey.KeyId == storeKeyInfo.KeyId && m.mu.activeKey != nil {
+		// The active store key has not changed and we already have an active
+		// data key, so no need to do anything.
+		return nil
+	}
+	// For keys other than plaintext, make sure the user is not reusing inactive
+	// keys.
+	if storeKeyInfo.EncryptionType != encryptionpb.EncryptionType_Plaintext {
+		if _, found := m.mu.keyRegistry.StoreKeys[storeKeyInfo.KeyId]; found {
+			return fmt.Errorf("new active store key ID %s already exists as an inactive key", storeKeyInfo.KeyId)
+		}
+	}
+
+	// The keyRegistry proto that will replace the current one.
+	keyRegistry := makeRegistryProto()
+	proto.Merge(keyRegistry, m.mu.keyRegistry)
+	keyRegistry.StoreKeys[storeKeyInfo.KeyId] = storeKeyInfo
+	keyRegistry.ActiveStoreKeyId = storeKeyInfo.KeyId
+	if storeKeyInfo.EncryptionType == encryptionpb.EncryptionType_Plaintext {
+		// Mark all data keys as exposed.
+		for _, key := range keyRegistry.DataKeys {
+			key.Info.WasExposed = true
+		}
+	}
+	return m.rotateDataKeyAndWrite(ctx, keyRegistry)
+}
+
+// getScrubbedRegistry returns a copy of the key registry without the raw data
+// keys.
+func (m *DataKeyManager) getScrubbedRegistry() *encryptionpb.DataKeysRegistry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := makeRegistryProto()
+	proto.Merge(r, m.mu.keyRegistry)
+	for _, v := range r.DataKeys {
+		v.Key = nil
+	}
+	return r
+}
+
+func validateRegistry(keyRegistry *encryptionpb.DataKeysRegistry) error {
+	if keyRegistry.ActiveStoreKeyId != "" && keyRegistry.StoreKeys[keyRegistry.ActiveStoreKeyId] == nil {
+		return fmt.Errorf("active store key %s not found", keyRegistry.ActiveStoreKeyId)
+	}
+	if keyRegistry.ActiveDataKeyId != "" && keyRegistry.DataKeys[keyRegistry.ActiveDataKeyId] == nil {
+		return fmt.Errorf("active data key %s not found", keyRegistry.ActiveDataKeyId)
+	}
+	return nil
+}
+
+// generateAndSetNewDataKey generates a new data key for the active store key
+// of keyRegistry, and makes it the active data key.
+func generateAndSetNewDataKey(
+	keyRegistry *encryptionpb.DataKeysRegistry,
+) (*encryptionpb.SecretKey, error) {
+	activeStoreKey := keyRegistry.StoreKeys[keyRegistry.ActiveStoreKeyId]
+	if activeStoreKey == nil {
+		panic("expected registry with active store key")
+	}
+	key := &encryptionpb.SecretKey{}
+	key.Info = &encryptionpb.KeyInfo{}
+	key.Info.EncryptionType = activeStoreKey.EncryptionType
+	key.Info.CreationTime = kmTimeNow().Unix()
+	key.Info.Source = "data key manager"
+	key.Info.ParentKeyId = activeStoreKey.KeyId
+
+	if activeStoreKey.EncryptionType == encryptionpb.EncryptionType_Plaintext {
+		key.Info.KeyId = plainKeyID
+		key.Info.WasExposed = true
+	} else {
+		var keyLength int
+		switch activeStoreKey.EncryptionType {
+		case encryptionpb.EncryptionType_AES128_CTR:
+			keyLength = 16
+		case encryptionpb.EncryptionType_AES192_CTR:
+			keyLength = 24
+		case encryptionpb.EncryptionType_AES256_CTR:
+			keyLength = 32
+		default:
+			return nil, fmt.Errorf("unknown encryption type %d for key ID %s",
+				activeStoreKey.EncryptionType, activeStoreKey.KeyId)
+		}
+		key.Key = make([]byte, keyLength)
+		if _, err := rand.Read(key.Key); err != nil {
+			return nil, err
+		}
+		keyID := make([]byte, keyIDLength)
+		if _, err := rand.Read(keyID); err != nil {
+			return nil, err
+		}
+		// Hex encoding to make it human readable.
+		key.Info.KeyId = hex.EncodeToString(keyID)
+		key.Info.WasExposed = false
+	}
+	keyRegistry.DataKeys[key.Info.KeyId] = key
+	keyRegistry.ActiveDataKeyId = key.Info.KeyId
+	return key, nil
+}
+
+// rotateDataKeyAndWrite generates a new active data key in keyRegistry,
+// persists keyRegistry and installs it as the current registry. m.mu must be
+// held.
+func (m *DataKeyManager) rotateDataKeyAndWrite(
+	ctx context.Context, keyRegistry *encryptionpb.DataKeysRegistry,
+) (err error) {
+	defer func() {
+		if err != nil {
+			log.Infof(ctx, "error while attempting to rotate data key: %s", err)
+		} else {
+			log.Infof(ctx, "rotated to new active data key: %s", proto.CompactTextString(m.mu.activeKey.Info))
+		}
+	}()
+
+	var newKey *encryptionpb.SecretKey
+	if newKey, err = generateAndSetNewDataKey(keyRegistry); err != nil {
+		return err
+	}
+	if err = validateRegistry(keyRegistry); err != nil {
+		return err
+	}
+	bytes, err := protoutil.Marshal(keyRegistry)
+	if err != nil {
+		return err
+	}
+	if err = fs.SafeWriteToFile(
+		m.fs, m.dbDir, m.fs.PathJoin(m.dbDir, KeyRegistryFilename), bytes,
+	); err != nil {
+		return err
+	}
+	m.mu.keyRegistry = keyRegistry
+	m.mu.activeKey = newKey
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/encryption/encryptionpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestKeyFile writes a store key file with a random ID and a key of the
+// given length, and returns the key ID.
+func writeTestKeyFile(t *testing.T, fs vfs.FS, filename string, keyLength int) string {
+	b := make([]byte, keyIDLength+keyLength)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	f, err := fs.Create(filename)
+	require.NoError(t, err)
+	_, err = f.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	return hex.EncodeToString(b[:keyIDLength])
+}
+
+func setTestTimeNow(now *time.Time) func() {
+	prev := kmTimeNow
+	kmTimeNow = func() time.Time { return *now }
+	return func() { kmTimeNow = prev }
+}
+
+func TestStoreKeyManager(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	mem := vfs.NewMem()
+	id16 := writeTestKeyFile(t, mem, "16.key", 16)
+	id24 := writeTestKeyFile(t, mem, "24.key", 24)
+	id32 := writeTestKeyFile(t, mem, "32.key", 32)
+	writeTestKeyFile(t, mem, "bad.key", 10)
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		active, old   string
+		activeID      string
+		activeType    encryptionpb.EncryptionType
+		oldID         string
+		expectedError string
+	}{
+		{"plain", "plain", plainKeyID, encryptionpb.EncryptionType_Plaintext, plainKeyID, ""},
+		{"16.key", "plain", id16, encryptionpb.EncryptionType_AES128_CTR, plainKeyID, ""},
+		{"24.key", "16.key", id24, encryptionpb.EncryptionType_AES192_CTR, id16, ""},
+		{"32.key", "24.key", id32, encryptionpb.EncryptionType_AES256_CTR, id24, ""},
+		{"bad.key", "plain", "", 0, "", "store key of unsupported length: 10"},
+		{"missing.key", "plain", "", 0, "", "missing.key"},
+	} {
+		t.Run(tc.active+"/"+tc.old, func(t *testing.T) {
+			m := &StoreKeyManager{fs: mem, activeKeyFilename: tc.active, oldKeyFilename: tc.old}
+			err := m.Load(ctx)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			key, err := m.ActiveKeyForWriter(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tc.activeID, key.Info.KeyId)
+			require.Equal(t, tc.activeType, key.Info.EncryptionType)
+			require.Equal(t, tc.activeID, m.ActiveKeyInfoForStats().KeyId)
+
+			key, err = m.GetKey(tc.oldID)
+			require.NoError(t, err)
+			require.Equal(t, tc.oldID, key.Info.KeyId)
+
+			_, err = m.GetKey("unknown")
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDataKeyManager(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	now := time.Unix(1000, 0)
+	defer setTestTimeNow(&now)()
+
+	mem := vfs.NewMem()
+	const dbDir = "/mydb"
+	require.NoError(t, mem.MkdirAll(dbDir, 0755))
+	ctx := context.Background()
+
+	storeKey1 := &encryptionpb.KeyInfo{
+		EncryptionType: encryptionpb.EncryptionType_AES128_CTR, KeyId: "store1", CreationTime: now.Unix(),
+	}
+	storeKey2 := &encryptionpb.KeyInfo{
+		EncryptionType: encryptionpb.EncryptionType_AES256_CTR, KeyId: "store2", CreationTime: now.Unix(),
+	}
+	plainKey := &encryptionpb.KeyInfo{
+		EncryptionType: encryptionpb.EncryptionType_Plaintext, KeyId: plainKeyID, CreationTime: now.Unix(),
+	}
+
+	load := func(readOnly bool) *DataKeyManager {
+		m := &DataKeyManager{fs: mem, dbDir: dbDir, rotationPeriod: 10, readOnly: readOnly}
+		require.NoError(t, m.Load(ctx))
+		return m
+	}
+
+	// No key registry yet: plaintext.
+	m := load(false /* readOnly */)
+	key, err := m.ActiveKeyForWriter(ctx)
+	require.NoError(t, err)
+	require.Nil(t, key)
+	require.Nil(t, m.ActiveKeyInfoForStats())
+
+	// Setting the store key generates a data key of the same type.
+	require.NoError(t, m.SetActiveStoreKeyInfo(ctx, storeKey1))
+	key1, err := m.ActiveKeyForWriter(ctx)
+	require.NoError(t, err)
+	require.Equal(t, encryptionpb.EncryptionType_AES128_CTR, key1.Info.EncryptionType)
+	require.Equal(t, "store1", key1.Info.ParentKeyId)
+	require.Len(t, key1.Key, 16)
+	require.False(t, key1.Info.WasExposed)
+
+	// Setting the same store key again is a noop.
+	require.NoError(t, m.SetActiveStoreKeyInfo(ctx, storeKey1))
+	key, err = m.ActiveKeyForWriter(ctx)
+	require.NoError(t, err)
+	require.Equal(t, key1.Info.KeyId, key.Info.KeyId)
+
+	// The data key is rotated once it is older than the rotation period.
+	now = now.Add(10 * time.Second)
+	key, err = m.ActiveKeyForWriter(ctx)
+	require.NoError(t, err)
+	require.Equal(t, key1.Info.KeyId, key.Info.KeyId)
+	now = now.Add(time.Second)
+	key2, err := m.ActiveKeyForWriter(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, key1.Info.KeyId, key2.Info.KeyId)
+
+	// Both keys remain available for reading.
+	for _, k := range []*encryptionpb.SecretKey{key1, key2} {
+		key, err = m.GetKey(k.Info.KeyId)
+		require.NoError(t, err)
+		require.Equal(t, k.Key, key.Key)
+	}
+
+	// The registry is persisted, and can be read by a read-only manager, which
+	// does not rotate keys.
+	ro := load(true /* readOnly */)
+	require.Equal(t, key2.Info.KeyId, ro.ActiveKeyInfoForStats().KeyId)
+	now = now.Add(time.Hour)
+	key, err = ro.ActiveKeyForWriter(ctx)
+	require.NoError(t, err)
+	require.Equal(t, key2.Info.KeyId, key.Info.KeyId)
+	require.EqualError(t, ro.SetActiveStoreKeyInfo(ctx, storeKey2), "read only")
+
+	// The scrubbed registry contains no key material.
+	r := ro.getScrubbedRegistry()
+	require.Len(t, r.DataKeys, 2)
+	for _, k := range r.DataKeys {
+		require.Nil(t, k.Key)
+	}
+	require.Len(t, ro.mu.keyRegistry.DataKeys[key2.Info.KeyId].Key, 16)
+
+	// Changing the store key generates a new data key.
+	m = load(false /* readOnly */)
+	require.NoError(t, m.SetActiveStoreKeyInfo(ctx, storeKey2))
+	key3, err := m.ActiveKeyForWriter(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "store2", key3.Info.ParentKeyId)
+	require.Equal(t, encryptionpb.EncryptionType_AES256_CTR, key3.Info.EncryptionType)
+
+	// An old store key cannot be made active again.
+	require.Error(t, m.SetActiveStoreKeyInfo(ctx, storeKey1))
+
+	// Switching to plaintext marks all data keys as exposed.
+	require.NoError(t, m.SetActiveStoreKeyInfo(ctx, plainKey))
+	key, err = m.ActiveKeyForWriter(ctx)
+	require.NoError(t, err)
+	require.Equal(t, encryptionpb.EncryptionType_Plaintext, key.Info.EncryptionType)
+	for _, k := range m.getScrubbedRegistry().DataKeys {
+		require.True(t, k.Info.WasExposed)
+	}
+}
@@ -875,9 +875,9 @@ type EnvStats struct {
 	// ActiveKeyBytes is the size of files using the active data key.
 	ActiveKeyBytes uint64
 	// EncryptionType is an enum describing the active encryption algorithm.
-	// See: ccl/storageccl/engineccl/enginepbccl/key_registry.proto
+	// See: storage/encryption/encryptionpb/key_registry.proto
 	EncryptionType int32
-	// EncryptionStatus is a serialized enginepbccl/stats.proto::EncryptionStatus protobuf.
+	// EncryptionStatus is a serialized storage/encryption/encryptionpb/stats.proto::EncryptionStatus protobuf.
 	EncryptionStatus []byte
 }
 
@@ -888,7 +888,7 @@ type EncryptionRegistries struct {
 	// serialized storage/engine/enginepb/file_registry.proto::FileRegistry
 	FileRegistry []byte
 	// KeyRegistry is the list of keys, scrubbed of actual key data.
-	// serialized ccl/storageccl/engineccl/enginepbccl/key_registry.proto::DataKeysRegistry
+	// serialized storage/encryption/encryptionpb/key_registry.proto::DataKeysRegistry
 	KeyRegistry []byte
 }
 
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/uncertainty"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -678,6 +679,13 @@ type MVCCGetOptions struct {
 	Uncertainty      uncertainty.Interval
 	// MemoryAccount is used for tracking memory allocations.
 	MemoryAccount *mon.BoundAccount
+	// SkipLocked indicates that the get should skip the key if it is locked by
+	// a conflicting transaction, as determined by the intents in the reader
+	// and by LockTable.
+	SkipLocked bool
+	// LockTable is used to determine whether the key is locked by a
+	// conflicting transaction when SkipLocked is set.
+	LockTable LockTableView
 }
 
 func (opts *MVCCGetOptions) validate() error {
@@ -687,9 +695,23 @@ func (opts *MVCCGetOptions) validate() error {
 	if opts.Inconsistent && opts.FailOnMoreRecent {
 		return errors.Errorf("cannot allow inconsistent reads with fail on more recent option")
 	}
+	if opts.Inconsistent && opts.SkipLocked {
+		return errors.Errorf("cannot allow inconsistent reads with skip locked option")
+	}
 	return nil
 }
 
+// LockTableView is a transaction-bound view into an in-memory collection of
+// key-level locks. It is used by reads that skip locked keys.
+type LockTableView interface {
+	// IsKeyLockedByConflictingTxn returns whether the specified key is locked or
+	// reserved by a conflicting transaction, given the caller's own desired
+	// locking strength. If so, true is returned. If the key is locked, the lock
+	// holder is also returned. Otherwise, if the key is reserved, nil is also
+	// returned.
+	IsKeyLockedByConflictingTxn(roachpb.Key, lock.Strength) (bool, *enginepb.TxnMeta)
+}
+
 func newMVCCIterator(reader Reader, inlineMeta bool, opts IterOptions) MVCCIterator {
 	iterKind := MVCCKeyAndIntentsIterKind
 	if inlineMeta {
@@ -722,6 +744,10 @@ func newMVCCIterator(reader Reader, inlineMeta bool, opts IterOptions) MVCCItera
 // timestamp. Similarly, a WriteIntentError will be returned if the read
 // observes another transaction's intent, even if it has a timestamp above
 // the read timestamp.
+//
+// When reading in "skip locked" mode, a nil value is returned if the key is
+// locked by a conflicting transaction, either through an intent or through a
+// lock in the provided LockTableView, instead of an error.
 func MVCCGet(
 	ctx context.Context, reader Reader, key roachpb.Key, timestamp hlc.Timestamp, opts MVCCGetOptions,
 ) (*roachpb.Value, *roachpb.Intent, error) {
@@ -763,6 +789,8 @@ func mvccGet(
 		inconsistent:     opts.Inconsistent,
 		tombstones:       opts.Tombstones,
 		failOnMoreRecent: opts.FailOnMoreRecent,
+		skipLocked:       opts.SkipLocked,
+		lockTable:        opts.LockTable,
 		keyBuf:           mvccScanner.keyBuf,
 	}
 
@@ -2295,6 +2323,8 @@ func mvccScanToBytes(
 		inconsistent:           opts.Inconsistent,
 		tombstones:             opts.Tombstones,
 		failOnMoreRecent:       opts.FailOnMoreRecent,
+		skipLocked:             opts.SkipLocked,
+		lockTable:              opts.LockTable,
 		keyBuf:                 mvccScanner.keyBuf,
 	}
 
@@ -2442,6 +2472,13 @@ type MVCCScanOptions struct {
 	MaxIntents int64
 	// MemoryAccount is used for tracking memory allocations.
 	MemoryAccount *mon.BoundAccount
+	// SkipLocked indicates that the scan should skip over keys that are locked
+	// by conflicting transactions, as determined by the intents in the reader
+	// and by LockTable, instead of returning WriteIntentErrors for them.
+	SkipLocked bool
+	// LockTable is used to determine whether keys are locked by conflicting
+	// transactions when SkipLocked is set.
+	LockTable LockTableView
 }
 
 func (opts *MVCCScanOptions) validate() error {
@@ -2451,6 +2488,9 @@ func (opts *MVCCScanOptions) validate() error {
 	if opts.Inconsistent && opts.FailOnMoreRecent {
 		return errors.Errorf("cannot allow inconsistent reads with fail on more recent option")
 	}
+	if opts.Inconsistent && opts.SkipLocked {
+		return errors.Errorf("cannot allow inconsistent reads with skip locked option")
+	}
 	return nil
 }
 
@@ -2505,6 +2545,10 @@ type MVCCScanResult struct {
 // the read timestamp, the maximum will be returned in the WriteTooOldError.
 // Similarly, a WriteIntentError will be returned if the scan observes another
 // transaction's intent, even if it has a timestamp above the read timestamp.
+//
+// When scanning in "skip locked" mode, keys that are locked by conflicting
+// transactions, either through intents or through locks in the provided
+// LockTableView, are omitted from the result instead of causing an error.
 func MVCCScan(
 	ctx context.Context,
 	reader Reader,
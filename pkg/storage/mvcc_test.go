@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/bootstrap"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -711,6 +712,106 @@ func TestMVCCScanWriteIntentError(t *testing.T) {
 
 // TestMVCCGetInconsistent verifies the behavior of get with
 // consistent set to false.
+// mockLockTableView is a LockTableView that reports the keys in locks as
+// locked by conflicting transactions.
+type mockLockTableView struct {
+	locks map[string]*enginepb.TxnMeta
+}
+
+func (lt mockLockTableView) IsKeyLockedByConflictingTxn(
+	key roachpb.Key, _ lock.Strength,
+) (bool, *enginepb.TxnMeta) {
+	holder, ok := lt.locks[string(key)]
+	return ok, holder
+}
+
+// TestMVCCSkipLocked verifies that reads configured to skip locked keys skip
+// over keys with conflicting intents and keys locked in the lock table,
+// instead of returning errors for them.
+func TestMVCCSkipLocked(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			ts1 := hlc.Timestamp{WallTime: 1}
+			ts2 := hlc.Timestamp{WallTime: 2}
+			ts3 := hlc.Timestamp{WallTime: 3}
+			ts4 := hlc.Timestamp{WallTime: 4}
+			for _, key := range []roachpb.Key{testKey1, testKey2, testKey3, testKey4} {
+				require.NoError(t, MVCCPut(ctx, engine, nil, key, ts1, value1, nil))
+			}
+			// txn1 holds an intent on testKey2.
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey2, ts2, value2, makeTxn(*txn1, ts2)))
+			// testKey4 has a committed value above the read timestamp.
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey4, ts4, value4, nil))
+			// txn2 holds an unreplicated lock on testKey3 and on testKey4.
+			lockTable := mockLockTableView{locks: map[string]*enginepb.TxnMeta{
+				string(testKey3): &txn2.TxnMeta,
+				string(testKey4): &txn2.TxnMeta,
+			}}
+
+			// Without skipping locked keys, the intent is returned as an error.
+			_, err := MVCCScan(ctx, engine, testKey1, testKey5, ts3, MVCCScanOptions{})
+			require.True(t, errors.HasType(err, (*roachpb.WriteIntentError)(nil)), "%v", err)
+
+			// Skipping locked keys omits the keys with the intent and the locks.
+			for _, failOnMoreRecent := range []bool{false, true} {
+				for _, reverse := range []bool{false, true} {
+					res, err := MVCCScan(ctx, engine, testKey1, testKey5, ts3, MVCCScanOptions{
+						FailOnMoreRecent: failOnMoreRecent,
+						Reverse:          reverse,
+						SkipLocked:       true,
+						LockTable:        lockTable,
+					})
+					require.NoError(t, err)
+					require.Len(t, res.KVs, 1)
+					require.Equal(t, testKey1, res.KVs[0].Key)
+					require.Empty(t, res.Intents)
+				}
+			}
+
+			// Without a lock on testKey4, a locking read observes its more recent
+			// committed value.
+			delete(lockTable.locks, string(testKey4))
+			_, err = MVCCScan(ctx, engine, testKey1, testKey5, ts3, MVCCScanOptions{
+				FailOnMoreRecent: true,
+				SkipLocked:       true,
+				LockTable:        lockTable,
+			})
+			require.True(t, errors.HasType(err, (*roachpb.WriteTooOldError)(nil)), "%v", err)
+
+			// Gets skip locked keys in the same way.
+			for _, key := range []roachpb.Key{testKey2, testKey3} {
+				value, intent, err := MVCCGet(ctx, engine, key, ts3, MVCCGetOptions{
+					SkipLocked: true,
+					LockTable:  lockTable,
+				})
+				require.NoError(t, err)
+				require.Nil(t, value)
+				require.Nil(t, intent)
+			}
+			value, _, err := MVCCGet(ctx, engine, testKey1, ts3, MVCCGetOptions{
+				SkipLocked: true,
+				LockTable:  lockTable,
+			})
+			require.NoError(t, err)
+			require.NotNil(t, value)
+
+			// Skipping locked keys is not compatible with inconsistent reads.
+			_, err = MVCCScan(ctx, engine, testKey1, testKey5, ts3, MVCCScanOptions{
+				Inconsistent: true,
+				SkipLocked:   true,
+			})
+			require.Regexp(t, "cannot allow inconsistent reads with skip locked option", err)
+		})
+	}
+}
+
 func TestMVCCGetInconsistent(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
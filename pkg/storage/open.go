@@ -133,7 +133,7 @@ func EncryptionAtRest(encryptionOptions []byte) ConfigOption {
 }
 
 // Hook configures a hook to initialize additional storage options. It's used
-// to initialize encryption-at-rest details in CCL builds.
+// to initialize encryption-at-rest details.
 func Hook(hookFunc func(*base.StorageConfig) error) ConfigOption {
 	return func(cfg *engineConfig) error {
 		if hookFunc == nil {
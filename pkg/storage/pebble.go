@@ -604,9 +604,9 @@ type PebbleConfig struct {
 
 // EncryptionStatsHandler provides encryption related stats.
 type EncryptionStatsHandler interface {
-	// Returns a serialized enginepbccl.EncryptionStatus.
+	// Returns a serialized encryptionpb.EncryptionStatus.
 	GetEncryptionStatus() ([]byte, error)
-	// Returns a serialized enginepbccl.DataKeysRegistry, scrubbed of key contents.
+	// Returns a serialized encryptionpb.DataKeysRegistry, scrubbed of key contents.
 	GetDataKeysRegistry() ([]byte, error)
 	// Returns the ID of the active data key, or "plain" if none.
 	GetActiveDataKeyID() (string, error)
@@ -678,9 +678,8 @@ type EncryptionEnv struct {
 var _ Engine = &Pebble{}
 
 // NewEncryptedEnvFunc creates an encrypted environment and returns the vfs.FS to use for reading
-// and writing data. This should be initialized by calling engineccl.Init() before calling
-// NewPebble(). The optionBytes is a binary serialized baseccl.EncryptionOptions, so that non-CCL
-// code does not depend on CCL code.
+// and writing data. It is set by the storage/encryption package, which depends on this package.
+// The optionBytes is a binary serialized encryptionpb.EncryptionOptions.
 var NewEncryptedEnvFunc func(fs vfs.FS, fr *PebbleFileRegistry, dbDir string, readOnly bool, optionBytes []byte) (*EncryptionEnv, error)
 
 // StoreIDSetter is used to set the store id in the log.
@@ -714,7 +713,7 @@ func ResolveEncryptedEnvOptions(cfg *PebbleConfig) (*PebbleFileRegistry, *Encryp
 	} else {
 		if err := fileRegistry.CheckNoRegistryFile(); err != nil {
 			return nil, nil, fmt.Errorf("encryption was used on this store before, but no encryption flags " +
-				"specified. You need a CCL build and must fully specify the --enterprise-encryption flag")
+				"specified. You must fully specify the --enterprise-encryption flag")
 		}
 		fileRegistry = nil
 	}
@@ -234,7 +234,7 @@ func (r *PebbleFileRegistry) maybeElideEntries() error {
 		entry := r.mu.entries[filename]
 
 		// Some entries may be elided. This is used within
-		// ccl/storageccl/engineccl to elide plaintext file entries.
+		// storage/encryption to elide plaintext file entries.
 		if CanRegistryElideFunc != nil && CanRegistryElideFunc(entry) {
 			batch.DeleteEntry(filename)
 			continue
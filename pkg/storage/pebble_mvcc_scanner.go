@@ -18,6 +18,7 @@ import (
 	"sync"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/uncertainty"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -338,6 +339,11 @@ type pebbleMVCCScanner struct {
 	isGet                    bool
 	keyBuf                   []byte
 	savedBuf                 []byte
+	// If true, skip over keys locked by conflicting transactions instead of
+	// returning them as intents. Locks that are not replicated as intents are
+	// consulted through lockTable.
+	skipLocked bool
+	lockTable  LockTableView
 	// cur* variables store the "current" record we're pointing to. Updated in
 	// updateCurrent. Note that the timestamp can be clobbered in the case of
 	// adding an intent from the intent history but is otherwise meaningful.
@@ -554,6 +560,13 @@ func (p *pebbleMVCCScanner) getAndAdvance(ctx context.Context) bool {
 		// ts == read_ts
 		if p.curUnsafeKey.Timestamp.EqOrdering(p.ts) {
 			if p.failOnMoreRecent {
+				if p.isKeyLockedByConflictingTxn(p.curUnsafeKey.Key) {
+					// 2a. The key is locked by a conflicting transaction and
+					// the scanner has been configured to skip locked keys.
+					// Skip over the key instead of returning a write too old
+					// error for it.
+					return p.advanceKey()
+				}
 				// 2. Our txn's read timestamp is equal to the most recent
 				// version's timestamp and the scanner has been configured to
 				// throw a write too old error on equal or more recent versions.
@@ -574,6 +587,12 @@ func (p *pebbleMVCCScanner) getAndAdvance(ctx context.Context) bool {
 
 		// ts > read_ts
 		if p.failOnMoreRecent {
+			if p.isKeyLockedByConflictingTxn(p.curUnsafeKey.Key) {
+				// 4a. The key is locked by a conflicting transaction and the
+				// scanner has been configured to skip locked keys. Skip over
+				// the key instead of returning a write too old error for it.
+				return p.advanceKey()
+			}
 			// 4. Our txn's read timestamp is less than the most recent
 			// version's timestamp and the scanner has been configured to
 			// throw a write too old error on equal or more recent versions.
@@ -685,6 +704,14 @@ func (p *pebbleMVCCScanner) getAndAdvance(ctx context.Context) bool {
 		return p.seekVersion(ctx, prevTS, false)
 	}
 
+	if !ownIntent && p.skipLocked {
+		// 10a. The key contains an intent which was not written by our
+		// transaction and would conflict with the read, but the scanner has
+		// been configured to skip locked keys. Skip over the key without
+		// returning the intent.
+		return p.advanceKey()
+	}
+
 	if !ownIntent {
 		// 10. The key contains an intent which was not written by our
 		// transaction and either:
@@ -893,6 +920,23 @@ func (p *pebbleMVCCScanner) advanceKeyAtNewKey(key []byte) bool {
 	return true
 }
 
+// isKeyLockedByConflictingTxn returns whether the scanner has been configured
+// to skip locked keys and the provided key is locked by a conflicting
+// transaction in the scanner's lock table view. Scans that fail on more recent
+// writes are locking scans, so they conflict with more locks than non-locking
+// scans do.
+func (p *pebbleMVCCScanner) isKeyLockedByConflictingTxn(key roachpb.Key) bool {
+	if !p.skipLocked || p.lockTable == nil {
+		return false
+	}
+	strength := lock.None
+	if p.failOnMoreRecent {
+		strength = lock.Exclusive
+	}
+	locked, _ := p.lockTable.IsKeyLockedByConflictingTxn(key, strength)
+	return locked
+}
+
 // Adds the specified key and value to the result set, excluding tombstones unless
 // p.tombstones is true. Advances to the next key unless we've reached the max
 // results limit.
@@ -905,6 +949,12 @@ func (p *pebbleMVCCScanner) addAndAdvance(
 		return p.advanceKey()
 	}
 
+	// Don't include keys locked by conflicting transactions if we've been
+	// instructed to skip locked keys.
+	if p.isKeyLockedByConflictingTxn(key) {
+		return p.advanceKey()
+	}
+
 	// Check if adding the key would exceed a limit.
 	if p.targetBytes > 0 && (p.results.bytes >= p.targetBytes || (p.targetBytesAvoidExcess &&
 		p.results.bytes+int64(p.results.sizeOf(len(rawKey), len(val))) > p.targetBytes)) {
@@ -0,0 +1,87 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package streaming defines the interfaces through which the SQL builtins
+// reach the implementation of cluster-to-cluster replication streams. The
+// producer side of a stream, in package streamproducer, runs on the source
+// cluster; the consumer side, in package streamingest, runs on the destination
+// cluster. Both depend on pkg/sql, which the builtins cannot import, so they
+// register themselves through the hooks below.
+package streaming
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streampb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// ReplicationStreamManager is the interface to the producer side of
+// replication streams.
+type ReplicationStreamManager interface {
+	// StartReplicationStream starts a stream replicating the keyspace of a
+	// tenant, and returns its ID.
+	StartReplicationStream(
+		evalCtx *tree.EvalContext, txn *kv.Txn, tenantID uint64,
+	) (streampb.StreamID, error)
+
+	// HeartbeatReplicationStream records that the consumer of a stream is
+	// alive and has ingested all the changes up to frontier, and returns the
+	// status of the stream.
+	HeartbeatReplicationStream(
+		evalCtx *tree.EvalContext, streamID streampb.StreamID, frontier hlc.Timestamp, txn *kv.Txn,
+	) (streampb.StreamReplicationStatus, error)
+
+	// StreamPartition returns a generator of the events of a partition of a
+	// stream, encoded as StreamEvent messages. opaqueSpec is an encoded
+	// StreamPartitionSpec.
+	StreamPartition(
+		evalCtx *tree.EvalContext, streamID streampb.StreamID, opaqueSpec []byte,
+	) (tree.ValueGenerator, error)
+
+	// GetReplicationStreamSpec returns the partitions of a stream.
+	GetReplicationStreamSpec(
+		evalCtx *tree.EvalContext, txn *kv.Txn, streamID streampb.StreamID,
+	) (*streampb.ReplicationStreamSpec, error)
+}
+
+// StreamIngestManager is the interface to the consumer side of replication
+// streams.
+type StreamIngestManager interface {
+	// CompleteStreamIngestion signals a running stream ingestion job to stop
+	// ingesting and to revert the ingested data to cutoverTimestamp.
+	CompleteStreamIngestion(
+		evalCtx *tree.EvalContext, txn *kv.Txn, jobID jobspb.JobID, cutoverTimestamp hlc.Timestamp,
+	) error
+}
+
+// GetReplicationStreamManagerHook is set by package streamproducer.
+var GetReplicationStreamManagerHook func(evalCtx *tree.EvalContext) (ReplicationStreamManager, error)
+
+// GetStreamIngestManagerHook is set by package streamingest.
+var GetStreamIngestManagerHook func(evalCtx *tree.EvalContext) (StreamIngestManager, error)
+
+// GetReplicationStreamManager returns the ReplicationStreamManager.
+func GetReplicationStreamManager(evalCtx *tree.EvalContext) (ReplicationStreamManager, error) {
+	if GetReplicationStreamManagerHook == nil {
+		return nil, errors.New("replication streams are not available in this binary")
+	}
+	return GetReplicationStreamManagerHook(evalCtx)
+}
+
+// GetStreamIngestManager returns the StreamIngestManager.
+func GetStreamIngestManager(evalCtx *tree.EvalContext) (StreamIngestManager, error) {
+	if GetStreamIngestManagerHook == nil {
+		return nil, errors.New("stream ingestion is not available in this binary")
+	}
+	return GetStreamIngestManagerHook(evalCtx)
+}
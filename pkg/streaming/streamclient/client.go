@@ -0,0 +1,195 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package streamclient implements the consumer side of the protocol of
+// replication streams. It talks to the source cluster over pgwire, using the
+// crdb_internal replication builtins.
+package streamclient
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streampb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v4"
+)
+
+// Client is a client of the source cluster of replication streams. It is
+// not safe for concurrent use, but the subscriptions it creates use their
+// own connections and may be used concurrently with it.
+type Client struct {
+	addr url.URL
+	conn *pgx.Conn
+}
+
+// NewClient connects to the source cluster of replication streams.
+// streamAddress is a postgres:// URL pointing at any of its nodes.
+func NewClient(ctx context.Context, streamAddress string) (*Client, error) {
+	addr, err := ParseAddress(streamAddress)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pgx.Connect(ctx, streamAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to %s", RedactAddress(streamAddress))
+	}
+	return &Client{addr: *addr, conn: conn}, nil
+}
+
+// ParseAddress parses the address of a source cluster.
+func ParseAddress(streamAddress string) (*url.URL, error) {
+	addr, err := url.Parse(streamAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid stream address")
+	}
+	if addr.Scheme != "postgres" && addr.Scheme != "postgresql" {
+		return nil, errors.Newf("stream address must use the postgres scheme, not %q", addr.Scheme)
+	}
+	return addr, nil
+}
+
+// RedactAddress removes the password from the address of a source cluster.
+func RedactAddress(streamAddress string) string {
+	addr, err := url.Parse(streamAddress)
+	if err != nil {
+		return "<invalid address>"
+	}
+	if _, ok := addr.User.Password(); ok {
+		addr.User = url.UserPassword(addr.User.Username(), "redacted")
+	}
+	return addr.String()
+}
+
+// Create starts a replication stream for the keyspace of a tenant.
+func (c *Client) Create(ctx context.Context, tenantID roachpb.TenantID) (streampb.StreamID, error) {
+	var streamID int64
+	if err := c.conn.QueryRow(ctx,
+		`SELECT crdb_internal.start_replication_stream($1)`, int64(tenantID.ToUint64()),
+	).Scan(&streamID); err != nil {
+		return 0, errors.Wrapf(err, "creating replication stream for tenant %s", tenantID)
+	}
+	return streampb.StreamID(streamID), nil
+}
+
+// Heartbeat reports that all the changes of a stream up to consumed have been
+// ingested, keeps the stream alive, and returns its status. An empty consumed
+// timestamp only keeps the stream alive.
+func (c *Client) Heartbeat(
+	ctx context.Context, streamID streampb.StreamID, consumed hlc.Timestamp,
+) (streampb.StreamReplicationStatus, error) {
+	var raw []byte
+	var status streampb.StreamReplicationStatus
+	if err := c.conn.QueryRow(ctx,
+		`SELECT crdb_internal.replication_stream_progress($1, $2)`, int64(streamID), consumed.String(),
+	).Scan(&raw); err != nil {
+		return status, errors.Wrapf(err, "heartbeating replication stream %d", streamID)
+	}
+	if err := protoutil.Unmarshal(raw, &status); err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+// Plan returns the partitions of a stream.
+func (c *Client) Plan(
+	ctx context.Context, streamID streampb.StreamID,
+) (*streampb.ReplicationStreamSpec, error) {
+	var raw []byte
+	if err := c.conn.QueryRow(ctx,
+		`SELECT crdb_internal.replication_stream_spec($1)`, int64(streamID),
+	).Scan(&raw); err != nil {
+		return nil, errors.Wrapf(err, "planning replication stream %d", streamID)
+	}
+	spec := &streampb.ReplicationStreamSpec{}
+	if err := protoutil.Unmarshal(raw, spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Subscribe starts streaming the events of a partition. It connects to the
+// node serving the partition with the credentials of the stream address.
+func (c *Client) Subscribe(
+	ctx context.Context, streamID streampb.StreamID, partition streampb.ReplicationStreamSpec_Partition,
+) (*Subscription, error) {
+	spec, err := protoutil.Marshal(&partition.PartitionSpec)
+	if err != nil {
+		return nil, err
+	}
+	addr := c.addr
+	if !partition.SQLAddress.IsEmpty() {
+		addr.Host = partition.SQLAddress.String()
+	}
+	conn, err := pgx.Connect(ctx, addr.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to %s", RedactAddress(addr.String()))
+	}
+	// Events must reach the consumer as soon as they are emitted.
+	if _, err := conn.Exec(ctx, `SET avoid_buffering = true`); err != nil {
+		_ = conn.Close(ctx)
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	rows, err := conn.Query(ctx,
+		`SELECT * FROM crdb_internal.stream_partition($1, $2)`, int64(streamID), spec)
+	if err != nil {
+		cancel()
+		_ = conn.Close(context.Background())
+		return nil, errors.Wrapf(err, "subscribing to partition %s of replication stream %d",
+			partition.ID, streamID)
+	}
+	return &Subscription{conn: conn, rows: rows, cancel: cancel}, nil
+}
+
+// Close closes the connection to the source cluster.
+func (c *Client) Close(ctx context.Context) error {
+	return c.conn.Close(ctx)
+}
+
+// Subscription is a stream of the events of a partition.
+type Subscription struct {
+	conn   *pgx.Conn
+	rows   pgx.Rows
+	cancel context.CancelFunc
+}
+
+// Next blocks until the next event of the partition is received. The stream
+// of events never ends: Next returns an error if it does, or if the context
+// passed to Subscribe is canceled.
+func (s *Subscription) Next() (*streampb.StreamEvent, error) {
+	if !s.rows.Next() {
+		if err := s.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("replication stream partition ended unexpectedly")
+	}
+	var raw []byte
+	if err := s.rows.Scan(&raw); err != nil {
+		return nil, err
+	}
+	event := &streampb.StreamEvent{}
+	if err := protoutil.Unmarshal(raw, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Close stops streaming and closes the connection of the subscription.
+func (s *Subscription) Close() {
+	// The query never completes on its own, so it must be canceled before the
+	// rows can be closed.
+	s.cancel()
+	s.rows.Close()
+	_ = s.conn.Close(context.Background())
+}
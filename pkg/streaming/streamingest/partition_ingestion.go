@@ -0,0 +1,115 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package streamingest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/bulk"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streamclient"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streampb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// maxBufferedBytes is the size above which the KVs received from a partition
+// are ingested before the next checkpoint of the partition.
+const maxBufferedBytes = 16 << 20
+
+// partitionCheckpoint reports that all the changes of a partition up to
+// resolved have been ingested.
+type partitionCheckpoint struct {
+	partitionID string
+	resolved    hlc.Timestamp
+}
+
+// ingestPartition subscribes to a partition of a stream and ingests its KVs,
+// at their original timestamps. The KVs are buffered, and ingested when the
+// partition emits a checkpoint, which is then sent on checkpoints. It runs
+// until ctx is canceled.
+func ingestPartition(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	client *streamclient.Client,
+	streamID streampb.StreamID,
+	partition streampb.ReplicationStreamSpec_Partition,
+	checkpoints chan<- partitionCheckpoint,
+) error {
+	sub, err := client.Subscribe(ctx, streamID, partition)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	batcher, err := bulk.MakeStreamSSTBatcher(ctx, execCfg.DB, execCfg.Settings,
+		execCfg.DistSQLSrv.BackupMonitor.MakeBoundAccount(), execCfg.DistSQLSrv.BulkSenderLimiter)
+	if err != nil {
+		return err
+	}
+	defer batcher.Close(ctx)
+
+	var buffered []storage.MVCCKeyValue
+	var bufferedBytes int
+	flush := func() error {
+		// SSTs must be written in order, and must not contain the same key
+		// twice, which the stream may emit if its rangefeeds are restarted.
+		sort.Slice(buffered, func(i, j int) bool {
+			return buffered[i].Key.Less(buffered[j].Key)
+		})
+		for i, kv := range buffered {
+			if i > 0 && kv.Key.Equal(buffered[i-1].Key) {
+				continue
+			}
+			if err := batcher.AddMVCCKey(ctx, kv.Key, kv.Value); err != nil {
+				return err
+			}
+		}
+		buffered = buffered[:0]
+		bufferedBytes = 0
+		return batcher.Flush(ctx)
+	}
+
+	for {
+		event, err := sub.Next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case event.Batch != nil:
+			for _, kv := range event.Batch.KeyValues {
+				buffered = append(buffered, storage.MVCCKeyValue{
+					Key:   storage.MVCCKey{Key: kv.Key, Timestamp: kv.Value.Timestamp},
+					Value: kv.Value.RawBytes,
+				})
+				bufferedBytes += len(kv.Key) + len(kv.Value.RawBytes)
+			}
+			if bufferedBytes >= maxBufferedBytes {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case event.Checkpoint != nil:
+			if err := flush(); err != nil {
+				return err
+			}
+			select {
+			case checkpoints <- partitionCheckpoint{
+				partitionID: partition.ID,
+				resolved:    event.Checkpoint.Resolved,
+			}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package streamingest
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/streaming"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+type streamIngestManager struct{}
+
+var _ streaming.StreamIngestManager = streamIngestManager{}
+
+// CompleteStreamIngestion is part of the streaming.StreamIngestManager
+// interface. It records the cutover time in the progress of the job, which
+// the job polls.
+func (streamIngestManager) CompleteStreamIngestion(
+	evalCtx *tree.EvalContext, txn *kv.Txn, jobID jobspb.JobID, cutoverTimestamp hlc.Timestamp,
+) error {
+	ctx := evalCtx.Ctx()
+	registry := evalCtx.Planner.ExecutorConfig().(*sql.ExecutorConfig).JobRegistry
+	return registry.UpdateJobWithTxn(ctx, jobID, txn, false, /* useReadLock */
+		func(txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+			details := md.Payload.GetStreamIngestion()
+			if details == nil {
+				return errors.Errorf("job %d is not a stream ingestion job", jobID)
+			}
+			if md.Status != jobs.StatusRunning {
+				return errors.Errorf("cannot cut over stream ingestion job %d in status %s",
+					jobID, md.Status)
+			}
+			progress := md.Progress.GetStreamIngest()
+			if !progress.CutoverTime.IsEmpty() {
+				return errors.Errorf("cutover time of stream ingestion job %d is already set to %s",
+					jobID, progress.CutoverTime)
+			}
+			if cutoverTimestamp.Less(details.StartTime) {
+				return errors.Errorf("cutover time %s is before the start time %s of stream ingestion job %d",
+					cutoverTimestamp, details.StartTime, jobID)
+			}
+			progress.CutoverTime = cutoverTimestamp
+			ju.UpdateProgress(md.Progress)
+			return nil
+		})
+}
+
+// newStreamIngestManager checks that stream ingestion may be used in the
+// session of evalCtx.
+func newStreamIngestManager(evalCtx *tree.EvalContext) (streaming.StreamIngestManager, error) {
+	isAdmin, err := evalCtx.SessionAccessor.HasAdminRole(evalCtx.Ctx())
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, pgerror.New(pgcode.InsufficientPrivilege,
+			"only users with the admin role can complete stream ingestion jobs")
+	}
+	return streamIngestManager{}, nil
+}
+
+func init() {
+	streaming.GetStreamIngestManagerHook = newStreamIngestManager
+}
@@ -0,0 +1,328 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package streamingest implements the destination side of replication
+// streams: RESTORE TENANT ... FROM REPLICATION STREAM creates a job which
+// ingests the stream of a tenant of a source cluster until it is cut over with
+// crdb_internal.complete_stream_ingestion_job().
+package streamingest
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streamclient"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streampb"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// minimumFlushInterval controls how often a stream ingestion job checkpoints
+// its progress.
+var minimumFlushInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"bulkio.stream_ingestion.minimum_flush_interval",
+	"controls the minimum interval between two checkpoints of the progress of a "+
+		"stream ingestion job; the changes after the last checkpoint are ingested again "+
+		"when a job is resumed",
+	5*time.Second,
+	settings.NonNegativeDuration,
+)
+
+// consumerHeartbeatFrequency controls how often a stream ingestion job
+// reports its progress to the source cluster.
+var consumerHeartbeatFrequency = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"stream_replication.consumer_heartbeat_frequency",
+	"controls the frequency at which a stream ingestion job heartbeats the "+
+		"replication stream it ingests",
+	30*time.Second,
+	settings.PositiveDuration,
+)
+
+// cutoverSignalPollInterval controls how often a stream ingestion job checks
+// whether it has been asked to cut over.
+var cutoverSignalPollInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"bulkio.stream_ingestion.cutover_signal_poll_interval",
+	"controls the frequency at which a stream ingestion job checks whether a "+
+		"cutover time has been set",
+	30*time.Second,
+	settings.PositiveDuration,
+)
+
+// errCutoverReached stops the ingestion of the partitions of a stream once
+// its high-water mark reaches the cutover time.
+var errCutoverReached = errors.New("cutover time reached")
+
+// streamIngestionResumer runs a stream ingestion job. Each partition of the
+// stream is ingested by its own goroutine, which reports the timestamps up to
+// which it has ingested the partition. The high-water mark of the job is the
+// minimum of these timestamps. Once it reaches the cutover time, the keyspace
+// of the tenant is reverted to the cutover time and the tenant is activated.
+type streamIngestionResumer struct {
+	job      *jobs.Job
+	settings *cluster.Settings
+}
+
+var _ jobs.Resumer = (*streamIngestionResumer)(nil)
+
+// Resume is part of the jobs.Resumer interface.
+func (s *streamIngestionResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	execCfg := execCtx.(sql.JobExecContext).ExecCfg()
+	details := s.job.Details().(jobspb.StreamIngestionDetails)
+
+	cutoverTime, err := s.ingest(ctx, execCfg, details)
+	if err != nil {
+		return err
+	}
+
+	log.Infof(ctx, "reverting tenant %s to cutover time %s", details.TenantID, cutoverTime)
+	if err := revertToCutover(ctx, execCfg, details.Span, cutoverTime); err != nil {
+		return errors.Wrap(err, "reverting to cutover time")
+	}
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		return sql.ActivateTenant(ctx, execCfg, txn, details.TenantID.ToUint64())
+	})
+}
+
+// ingest ingests the stream until its high-water mark reaches the cutover
+// time, and returns the cutover time.
+func (s *streamIngestionResumer) ingest(
+	ctx context.Context, execCfg *sql.ExecutorConfig, details jobspb.StreamIngestionDetails,
+) (hlc.Timestamp, error) {
+	// A resumed job picks up from its last checkpoint, after which the initial
+	// scan of the stream has completed. The partitions of the stream may have
+	// changed since, so they all resume from the high-water mark.
+	progress := s.job.Progress()
+	highWater := details.StartTime
+	initialScan := true
+	if hw := progress.GetHighWater(); hw != nil && !hw.IsEmpty() {
+		highWater = *hw
+		initialScan = false
+	}
+	cutoverTime := progress.GetStreamIngest().CutoverTime
+	if !cutoverTime.IsEmpty() && cutoverTime.LessEq(highWater) {
+		return cutoverTime, nil
+	}
+
+	client, err := streamclient.NewClient(ctx, details.StreamAddress)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	defer func() {
+		if err := client.Close(ctx); err != nil {
+			log.Warningf(ctx, "failed to close stream client: %v", err)
+		}
+	}()
+	streamID := streampb.StreamID(details.StreamID)
+	spec, err := client.Plan(ctx, streamID)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+
+	checkpoints := make(chan partitionCheckpoint)
+	resolved := make(map[string]hlc.Timestamp, len(spec.Partitions))
+	g := ctxgroup.WithContext(ctx)
+	for _, partition := range spec.Partitions {
+		partition := partition
+		partition.PartitionSpec.StartFrom = highWater
+		partition.PartitionSpec.InitialScan = initialScan
+		resolved[partition.ID] = highWater
+		g.GoCtx(func(ctx context.Context) error {
+			return ingestPartition(ctx, execCfg, client, streamID, partition, checkpoints)
+		})
+	}
+	g.GoCtx(func(ctx context.Context) error {
+		var err error
+		cutoverTime, err = s.trackProgress(ctx, execCfg, client, streamID, highWater, resolved, checkpoints)
+		return err
+	})
+	if err := g.Wait(); !errors.Is(err, errCutoverReached) {
+		return hlc.Timestamp{}, err
+	}
+	return cutoverTime, nil
+}
+
+// trackProgress maintains the high-water mark of the job from the
+// checkpoints of its partitions, checkpoints it, and reports it to the source
+// cluster. It returns errCutoverReached, along with the cutover time, once
+// the high-water mark reaches the cutover time.
+func (s *streamIngestionResumer) trackProgress(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	client *streamclient.Client,
+	streamID streampb.StreamID,
+	highWater hlc.Timestamp,
+	resolved map[string]hlc.Timestamp,
+	checkpoints <-chan partitionCheckpoint,
+) (hlc.Timestamp, error) {
+	sv := &s.settings.SV
+	// Only checkpointed progress is reported to the source cluster, since a
+	// resumed job only resumes from its checkpoint.
+	checkpointed := highWater
+	var lastCheckpoint time.Time
+	var cutoverTime hlc.Timestamp
+
+	heartbeatTimer, cutoverTimer := timeutil.NewTimer(), timeutil.NewTimer()
+	defer heartbeatTimer.Stop()
+	defer cutoverTimer.Stop()
+	heartbeatTimer.Reset(0)
+	cutoverTimer.Reset(0)
+	for {
+		if !cutoverTime.IsEmpty() && cutoverTime.LessEq(highWater) {
+			if err := s.checkpoint(ctx, highWater, resolved); err != nil {
+				return hlc.Timestamp{}, err
+			}
+			return cutoverTime, errCutoverReached
+		}
+
+		select {
+		case <-ctx.Done():
+			return hlc.Timestamp{}, ctx.Err()
+
+		case cp := <-checkpoints:
+			resolved[cp.partitionID] = cp.resolved
+			newHighWater := cp.resolved
+			for _, ts := range resolved {
+				newHighWater.Backward(ts)
+			}
+			if !highWater.Less(newHighWater) {
+				continue
+			}
+			highWater = newHighWater
+			if timeutil.Since(lastCheckpoint) < minimumFlushInterval.Get(sv) {
+				continue
+			}
+			if err := s.checkpoint(ctx, highWater, resolved); err != nil {
+				return hlc.Timestamp{}, err
+			}
+			checkpointed = highWater
+			lastCheckpoint = timeutil.Now()
+
+		case <-heartbeatTimer.C:
+			heartbeatTimer.Read = true
+			status, err := client.Heartbeat(ctx, streamID, checkpointed)
+			if err != nil {
+				return hlc.Timestamp{}, err
+			}
+			if status.StreamStatus == streampb.StreamReplicationStatus_STREAM_INACTIVE {
+				return hlc.Timestamp{}, errors.Errorf("replication stream %d is no longer active", streamID)
+			}
+			heartbeatTimer.Reset(consumerHeartbeatFrequency.Get(sv))
+
+		case <-cutoverTimer.C:
+			cutoverTimer.Read = true
+			// The cutover time is set through the progress of the job, so the job
+			// must be reloaded.
+			j, err := execCfg.JobRegistry.LoadJob(ctx, s.job.ID())
+			if err != nil {
+				return hlc.Timestamp{}, err
+			}
+			cutoverTime = j.Progress().GetStreamIngest().CutoverTime
+			cutoverTimer.Reset(cutoverSignalPollInterval.Get(sv))
+		}
+	}
+}
+
+// checkpoint records the high-water mark of the job and the progress of its
+// partitions.
+func (s *streamIngestionResumer) checkpoint(
+	ctx context.Context, highWater hlc.Timestamp, resolved map[string]hlc.Timestamp,
+) error {
+	return s.job.Update(ctx, nil /* txn */, func(
+		txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater,
+	) error {
+		progress := md.Progress.GetStreamIngest()
+		progress.PartitionProgress = make(
+			map[string]jobspb.StreamIngestionProgress_PartitionProgress, len(resolved))
+		for id, ts := range resolved {
+			progress.PartitionProgress[id] = jobspb.StreamIngestionProgress_PartitionProgress{
+				IngestedTimestamp: ts,
+			}
+		}
+		return jobs.UpdateHighwaterProgressed(highWater, md, ju)
+	})
+}
+
+// revertToCutover removes the KVs ingested after the cutover time, so that
+// the keyspace of the tenant is consistent as of the cutover time.
+func revertToCutover(
+	ctx context.Context, execCfg *sql.ExecutorConfig, span roachpb.Span, cutoverTime hlc.Timestamp,
+) error {
+	for span.Valid() {
+		var b kv.Batch
+		b.AddRawRequest(&roachpb.RevertRangeRequest{
+			RequestHeader: roachpb.RequestHeader{
+				Key:    span.Key,
+				EndKey: span.EndKey,
+			},
+			TargetTime:                          cutoverTime,
+			EnableTimeBoundIteratorOptimization: true,
+		})
+		b.Header.MaxSpanRequestKeys = sql.RevertTableDefaultBatchSize
+		if err := execCfg.DB.Run(ctx, &b); err != nil {
+			return err
+		}
+		resume := b.RawResponse().Responses[0].GetRevertRange().ResumeSpan
+		if resume == nil {
+			return nil
+		}
+		span = *resume
+	}
+	return errors.Errorf("invalid resume span: %s", span)
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. It removes the tenant
+// being ingested, along with the data ingested so far.
+func (s *streamIngestionResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
+	execCfg := execCtx.(sql.JobExecContext).ExecCfg()
+	tenantID := s.job.Details().(jobspb.StreamIngestionDetails).TenantID.ToUint64()
+	info, err := sql.GetTenantRecord(ctx, execCfg, nil /* txn */, tenantID)
+	if err != nil {
+		if pgerror.GetPGCode(err) == pgcode.UndefinedObject {
+			// The tenant may have been removed by a previous attempt.
+			return nil
+		}
+		return err
+	}
+	if info.State == descpb.TenantInfo_ACTIVE {
+		// The stream was cut over: the tenant is no longer ours to remove.
+		return nil
+	}
+	info.State = descpb.TenantInfo_DROP
+	return sql.GCTenantSync(ctx, execCfg, info)
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeStreamIngestion,
+		func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
+			return &streamIngestionResumer{
+				job:      job,
+				settings: settings,
+			}
+		},
+		jobs.UsesTenantCostControl,
+	)
+}
@@ -0,0 +1,187 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package streamingest
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streamclient"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streampb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// streamIngestionJobDescription returns the description of a stream
+// ingestion job, which is the statement it runs with the password of the
+// stream address redacted.
+func streamIngestionJobDescription(stmt *tree.StreamIngestion, streamAddress string) string {
+	redacted := *stmt
+	redacted.From = tree.StringOrPlaceholderOptList{
+		tree.NewDString(streamclient.RedactAddress(streamAddress)),
+	}
+	return tree.AsString(&redacted)
+}
+
+// ingestionPlanHook implements RESTORE TENANT ... FROM REPLICATION STREAM. It
+// creates the tenant, in the ADD state, starts a replication stream of the
+// tenant on the source cluster, and creates the job ingesting the stream.
+func ingestionPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	ingestionStmt, ok := stmt.(*tree.StreamIngestion)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if !p.SessionData().EnableStreamReplication {
+		return nil, nil, nil, false, errors.WithHint(
+			pgerror.New(pgcode.FeatureNotSupported,
+				"stream replication is only supported experimentally"),
+			"You can enable stream replication by running `SET enable_experimental_stream_replication = true`.",
+		)
+	}
+	if !ingestionStmt.Targets.TenantID.Specified {
+		return nil, nil, nil, false, pgerror.New(pgcode.FeatureNotSupported,
+			"only tenants can be restored from a replication stream")
+	}
+	if ingestionStmt.AsOf.Expr != nil {
+		return nil, nil, nil, false, pgerror.New(pgcode.FeatureNotSupported,
+			"AS OF SYSTEM TIME is not supported when restoring from a replication stream; "+
+				"use crdb_internal.complete_stream_ingestion_job() to pick the cutover time")
+	}
+
+	fromFn, err := p.TypeAsStringArray(ctx, tree.Exprs(ingestionStmt.From), "INGESTION")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if !p.ExecCfg().Codec.ForSystemTenant() {
+			return pgerror.New(pgcode.InsufficientPrivilege,
+				"only the system tenant can restore a tenant from a replication stream")
+		}
+		if err := p.RequireAdminRole(ctx, "RESTORE FROM REPLICATION STREAM"); err != nil {
+			return err
+		}
+
+		from, err := fromFn()
+		if err != nil {
+			return err
+		}
+		if len(from) != 1 {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"exactly one stream address must be specified, got %d", len(from))
+		}
+		streamAddress := from[0]
+		if _, err := streamclient.ParseAddress(streamAddress); err != nil {
+			return pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue)
+		}
+
+		// The tenant is created before the stream, so that a restore into an
+		// existing tenant does not leave a dangling stream on the source
+		// cluster. The keyspace of the tenant is filled by the stream, and the
+		// tenant is only activated once the stream is cut over.
+		tenantID := ingestionStmt.Targets.TenantID.TenantID
+		txn := p.ExtendedEvalContext().Txn
+		initialTenantZoneConfig, err := sql.GetHydratedZoneConfigForTenantsRange(ctx, txn)
+		if err != nil {
+			return err
+		}
+		info := &descpb.TenantInfoWithUsage{
+			TenantInfo: descpb.TenantInfo{
+				ID:    tenantID.ToUint64(),
+				State: descpb.TenantInfo_ADD,
+			},
+		}
+		if err := sql.CreateTenantRecord(ctx, p.ExecCfg(), txn, info, initialTenantZoneConfig); err != nil {
+			return err
+		}
+
+		streamID, startTime, err := startStream(ctx, streamAddress, tenantID)
+		if err != nil {
+			return err
+		}
+
+		prefix := keys.MakeTenantPrefix(tenantID)
+		details := jobspb.StreamIngestionDetails{
+			StreamAddress: streamAddress,
+			StreamID:      uint64(streamID),
+			Span:          roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()},
+			StartTime:     startTime,
+			TenantID:      tenantID,
+		}
+		jr := jobs.Record{
+			Description: streamIngestionJobDescription(ingestionStmt, streamAddress),
+			Username:    p.User(),
+			Details:     details,
+			Progress:    jobspb.StreamIngestionProgress{},
+		}
+		registry := p.ExecCfg().JobRegistry
+		jobID := registry.MakeJobID()
+		if _, err := registry.CreateAdoptableJobWithTxn(ctx, jr, jobID, txn); err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+		return nil
+	}
+	return fn, jobs.DetachedJobExecutionResultHeader, nil, false, nil
+}
+
+// startStream starts the replication stream of a tenant on the source
+// cluster, and returns the ID of the stream and the timestamp from which its
+// history is protected, which is the time from which it is ingested.
+func startStream(
+	ctx context.Context, streamAddress string, tenantID roachpb.TenantID,
+) (streampb.StreamID, hlc.Timestamp, error) {
+	client, err := streamclient.NewClient(ctx, streamAddress)
+	if err != nil {
+		return 0, hlc.Timestamp{}, err
+	}
+	defer func() {
+		if err := client.Close(ctx); err != nil {
+			log.Warningf(ctx, "failed to close stream client: %v", err)
+		}
+	}()
+
+	streamID, err := client.Create(ctx, tenantID)
+	if err != nil {
+		return 0, hlc.Timestamp{}, err
+	}
+	status, err := client.Heartbeat(ctx, streamID, hlc.Timestamp{})
+	if err != nil {
+		return 0, hlc.Timestamp{}, err
+	}
+	if status.StreamStatus != streampb.StreamReplicationStatus_STREAM_ACTIVE ||
+		status.ProtectedTimestamp == nil {
+		return 0, hlc.Timestamp{}, errors.Errorf(
+			"replication stream %d is not active: %s", streamID, status.StreamStatus)
+	}
+	return streamID, *status.ProtectedTimestamp, nil
+}
+
+func init() {
+	sql.AddPlanHook("restore from replication stream", ingestionPlanHook)
+}
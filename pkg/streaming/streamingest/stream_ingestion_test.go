@@ -0,0 +1,107 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package streamingest_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// TestTenantStreamingCutover replicates a tenant between two clusters, cuts
+// the stream over, and checks that the destination tenant holds the data of
+// the source tenant as of the cutover time.
+func TestTenantStreamingCutover(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	args := base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+		},
+	}
+
+	source, sourceDB, _ := serverutils.StartServer(t, args)
+	defer source.Stopper().Stop(ctx)
+	sourceSQL := sqlutils.MakeSQLRunner(sourceDB)
+	sourceSQL.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sourceSQL.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	sourceSQL.Exec(t, `SET CLUSTER SETTING stream_replication.min_checkpoint_frequency = '10ms'`)
+
+	tenantID := roachpb.MakeTenantID(10)
+	_, sourceTenantDB := serverutils.StartTenant(t, source, base.TestTenantArgs{TenantID: tenantID})
+	defer sourceTenantDB.Close()
+	sourceTenantSQL := sqlutils.MakeSQLRunner(sourceTenantDB)
+	sourceTenantSQL.Exec(t, `CREATE DATABASE d`)
+	sourceTenantSQL.Exec(t, `CREATE TABLE d.t (k INT PRIMARY KEY, v STRING)`)
+	sourceTenantSQL.Exec(t, `INSERT INTO d.t VALUES (1, 'one'), (2, 'two')`)
+
+	dest, destDB, _ := serverutils.StartServer(t, args)
+	defer dest.Stopper().Stop(ctx)
+	destSQL := sqlutils.MakeSQLRunner(destDB)
+	destSQL.Exec(t, `SET CLUSTER SETTING bulkio.stream_ingestion.minimum_flush_interval = '10ms'`)
+	destSQL.Exec(t, `SET CLUSTER SETTING bulkio.stream_ingestion.cutover_signal_poll_interval = '100ms'`)
+	destSQL.Exec(t, `SET CLUSTER SETTING stream_replication.consumer_heartbeat_frequency = '100ms'`)
+
+	pgURL, cleanupCerts := sqlutils.PGUrl(t, source.ServingSQLAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanupCerts()
+
+	destSQL.ExpectErr(t, "stream replication is only supported experimentally",
+		`RESTORE TENANT 10 FROM REPLICATION STREAM FROM $1`, pgURL.String())
+	destSQL.Exec(t, `SET enable_experimental_stream_replication = true`)
+	destSQL.ExpectErr(t, "AS OF SYSTEM TIME is not supported",
+		`RESTORE TENANT 10 FROM REPLICATION STREAM FROM $1 AS OF SYSTEM TIME '-1s'`, pgURL.String())
+
+	var jobID jobspb.JobID
+	destSQL.QueryRow(t,
+		`RESTORE TENANT 10 FROM REPLICATION STREAM FROM $1`, pgURL.String(),
+	).Scan(&jobID)
+
+	// The changes made after the stream is started are replicated, up to the
+	// cutover time.
+	sourceTenantSQL.Exec(t, `UPSERT INTO d.t VALUES (2, 'deux'), (3, 'three')`)
+	var cutover time.Time
+	sourceTenantSQL.QueryRow(t, `SELECT clock_timestamp()`).Scan(&cutover)
+	sourceTenantSQL.Exec(t, `INSERT INTO d.t VALUES (4, 'four')`)
+
+	destSQL.Exec(t, `SELECT crdb_internal.complete_stream_ingestion_job($1, $2)`, jobID, cutover)
+	destSQL.ExpectErr(t, "cutover time of stream ingestion job .* is already set",
+		`SELECT crdb_internal.complete_stream_ingestion_job($1, $2)`, jobID, cutover)
+	jobutils.WaitForJobToSucceed(t, destSQL, jobID)
+
+	destSQL.CheckQueryResults(t,
+		`SELECT active FROM system.tenants WHERE id = 10`, [][]string{{"true"}})
+	_, destTenantDB := serverutils.StartTenant(t, dest, base.TestTenantArgs{
+		TenantID: tenantID,
+		Existing: true,
+	})
+	defer destTenantDB.Close()
+	sqlutils.MakeSQLRunner(destTenantDB).CheckQueryResults(t,
+		`SELECT k, v FROM d.t ORDER BY k`,
+		[][]string{{"1", "one"}, {"2", "deux"}, {"3", "three"}},
+	)
+
+	destSQL.ExpectErr(t, `tenant "10" already exists`,
+		`RESTORE TENANT 10 FROM REPLICATION STREAM FROM $1`, pgURL.String())
+}
@@ -0,0 +1,18 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package streampb
+
+// StreamID is the ID of a replication stream. It is the ID of the job
+// producing the stream on the source cluster.
+type StreamID int64
+
+// SafeValue implements the redact.SafeValue interface.
+func (StreamID) SafeValue() {}
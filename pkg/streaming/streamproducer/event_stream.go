@@ -0,0 +1,268 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package streamproducer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streampb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// minCheckpointFrequency controls how often the partitions of a replication
+// stream emit checkpoints.
+var minCheckpointFrequency = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"stream_replication.min_checkpoint_frequency",
+	"controls the minimum interval between two checkpoints sent by a partition "+
+		"of a replication stream to its consumer",
+	10*time.Second,
+	settings.NonNegativeDuration,
+)
+
+// batchByteSize controls the size of the batches of KVs emitted by the
+// partitions of a replication stream.
+var batchByteSize = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"stream_replication.event_batch_byte_size",
+	"controls the size above which a partition of a replication stream sends "+
+		"the KVs it has accumulated to its consumer",
+	1<<20,
+	settings.PositiveInt,
+)
+
+// eventBufferSize is the number of rangefeed events buffered between the
+// rangefeed and the generator emitting them.
+const eventBufferSize = 1024
+
+var eventStreamReturnType = types.MakeLabeledTuple(
+	[]*types.T{types.Bytes},
+	[]string{"stream_event"},
+)
+
+// rangefeedEvent is either a KV change received from the rangefeed or an
+// advance of the rangefeed's frontier.
+type rangefeedEvent struct {
+	value    *roachpb.RangeFeedValue
+	resolved hlc.Timestamp
+}
+
+// eventStream is the generator behind crdb_internal.stream_partition(). It
+// runs a rangefeed over the spans of a partition and emits its KV changes,
+// batched, and its checkpoints, as encoded StreamEvent messages. The stream
+// never ends on its own: it runs until the consumer cancels its query.
+type eventStream struct {
+	streamID streampb.StreamID
+	execCfg  *sql.ExecutorConfig
+	spec     streampb.StreamPartitionSpec
+
+	feed   *rangefeed.RangeFeed
+	events chan rangefeedEvent
+	errCh  chan error
+	// pending is an event received, but not yet emitted, by the previous call
+	// to Next.
+	pending        *rangefeedEvent
+	lastCheckpoint time.Time
+	data           tree.Datums
+}
+
+var _ tree.ValueGenerator = (*eventStream)(nil)
+
+// streamPartition validates the spec of a partition of a stream and returns
+// the generator of its events.
+func streamPartition(
+	evalCtx *tree.EvalContext, streamID streampb.StreamID, opaqueSpec []byte,
+) (tree.ValueGenerator, error) {
+	var spec streampb.StreamPartitionSpec
+	if err := protoutil.Unmarshal(opaqueSpec, &spec); err != nil {
+		return nil, errors.Wrap(err, "invalid partition spec")
+	}
+	if len(spec.Spans) == 0 {
+		return nil, errors.New("partition spec has no spans")
+	}
+	execCfg := evalCtx.Planner.ExecutorConfig().(*sql.ExecutorConfig)
+	details, err := loadRunningStream(evalCtx.Ctx(), execCfg, streamID, evalCtx.Txn)
+	if err != nil {
+		return nil, err
+	}
+	for _, span := range spec.Spans {
+		contained := false
+		for _, streamSpan := range details.Spans {
+			contained = contained || streamSpan.Contains(span)
+		}
+		if !contained {
+			return nil, errors.Errorf("span %s is not replicated by stream %d", span, streamID)
+		}
+	}
+	return &eventStream{
+		streamID: streamID,
+		execCfg:  execCfg,
+		spec:     spec,
+	}, nil
+}
+
+// ResolvedType is part of the tree.ValueGenerator interface.
+func (s *eventStream) ResolvedType() *types.T {
+	return eventStreamReturnType
+}
+
+// Start is part of the tree.ValueGenerator interface.
+func (s *eventStream) Start(ctx context.Context, _ *kv.Txn) error {
+	if s.feed != nil {
+		return errors.AssertionFailedf("replication stream partition cannot be restarted")
+	}
+	s.events = make(chan rangefeedEvent, eventBufferSize)
+	s.errCh = make(chan error, 1)
+	push := func(ctx context.Context, ev rangefeedEvent) {
+		select {
+		case s.events <- ev:
+		case <-ctx.Done():
+		}
+	}
+
+	opts := []rangefeed.Option{
+		rangefeed.WithOnFrontierAdvance(func(ctx context.Context, frontier hlc.Timestamp) {
+			push(ctx, rangefeedEvent{resolved: frontier})
+		}),
+		rangefeed.WithOnInternalError(func(ctx context.Context, err error) {
+			select {
+			case s.errCh <- err:
+			default:
+			}
+		}),
+	}
+	if s.spec.InitialScan {
+		// The consumer ingests the KVs at their original timestamps.
+		opts = append(opts,
+			rangefeed.WithInitialScan(nil),
+			rangefeed.WithRowTimestampInInitialScan(true),
+		)
+	}
+	feed, err := s.execCfg.RangeFeedFactory.RangeFeed(ctx,
+		fmt.Sprintf("replication-stream-%d", s.streamID), s.spec.Spans, s.spec.StartFrom,
+		func(ctx context.Context, value *roachpb.RangeFeedValue) {
+			v := *value
+			push(ctx, rangefeedEvent{value: &v})
+		},
+		opts...,
+	)
+	if err != nil {
+		return err
+	}
+	s.feed = feed
+	return nil
+}
+
+// Next is part of the tree.ValueGenerator interface. It blocks until there is
+// an event to emit.
+func (s *eventStream) Next(ctx context.Context) (bool, error) {
+	for {
+		ev, err := s.nextEvent(ctx, true /* block */)
+		if err != nil {
+			return false, err
+		}
+		if ev.value != nil {
+			return true, s.setEvent(&streampb.StreamEvent{Batch: s.nextBatch(ctx, ev)})
+		}
+		// Checkpoints are emitted at most once per MinCheckpointFrequency. The
+		// skipped ones are superseded by the next one.
+		if timeutil.Since(s.lastCheckpoint) < s.spec.Config.MinCheckpointFrequency {
+			continue
+		}
+		s.lastCheckpoint = timeutil.Now()
+		return true, s.setEvent(&streampb.StreamEvent{
+			Checkpoint: &streampb.StreamEvent_StreamCheckpoint{Resolved: ev.resolved},
+		})
+	}
+}
+
+// nextEvent returns the next event received from the rangefeed. If block is
+// false and no event is available, it returns an empty event.
+func (s *eventStream) nextEvent(ctx context.Context, block bool) (rangefeedEvent, error) {
+	if ev := s.pending; ev != nil {
+		s.pending = nil
+		return *ev, nil
+	}
+	if !block {
+		select {
+		case ev := <-s.events:
+			return ev, nil
+		default:
+			return rangefeedEvent{}, nil
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return rangefeedEvent{}, ctx.Err()
+	case err := <-s.errCh:
+		return rangefeedEvent{}, err
+	case ev := <-s.events:
+		return ev, nil
+	}
+}
+
+// nextBatch returns a batch made of the KV change first, and of the KV
+// changes which immediately follow it and are already available. The batch
+// ends before the next checkpoint, so that checkpoints are emitted after all
+// the changes they cover.
+func (s *eventStream) nextBatch(ctx context.Context, first rangefeedEvent) *streampb.StreamEvent_Batch {
+	batch := &streampb.StreamEvent_Batch{}
+	var size int64
+	for ev := first; ; {
+		kv := roachpb.KeyValue{Key: ev.value.Key, Value: ev.value.Value}
+		batch.KeyValues = append(batch.KeyValues, kv)
+		size += int64(kv.Size())
+		if size >= s.spec.Config.BatchByteSize {
+			return batch
+		}
+		// Errors are only returned when blocking.
+		ev, _ = s.nextEvent(ctx, false /* block */)
+		if ev.value == nil {
+			if !ev.resolved.IsEmpty() {
+				s.pending = &ev
+			}
+			return batch
+		}
+	}
+}
+
+func (s *eventStream) setEvent(event *streampb.StreamEvent) error {
+	data, err := protoutil.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.data = tree.Datums{tree.NewDBytes(tree.DBytes(data))}
+	return nil
+}
+
+// Values is part of the tree.ValueGenerator interface.
+func (s *eventStream) Values() (tree.Datums, error) {
+	return s.data, nil
+}
+
+// Close is part of the tree.ValueGenerator interface.
+func (s *eventStream) Close(context.Context) {
+	if s.feed != nil {
+		s.feed.Close()
+	}
+}
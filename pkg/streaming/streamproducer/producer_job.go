@@ -0,0 +1,118 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package streamproducer
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// jobLivenessTimeout controls how long a replication stream is kept alive
+// without hearing from its consumer.
+var jobLivenessTimeout = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"stream_replication.job_liveness_timeout",
+	"controls how long a replication stream producer job waits for a heartbeat "+
+		"from its consumer before it fails and releases its protected timestamp",
+	time.Minute,
+	settings.PositiveDuration,
+)
+
+// livenessTrackFrequency controls how often the producer job of a replication
+// stream checks whether its consumer is still alive.
+var livenessTrackFrequency = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"stream_replication.stream_liveness_track_frequency",
+	"controls how often a replication stream producer job checks the liveness of its consumer",
+	time.Minute,
+	settings.PositiveDuration,
+)
+
+// producerJobResumer runs the producer job of a replication stream. The job
+// does not stream anything itself: the consumer pulls the events of the
+// stream through crdb_internal.stream_partition(). The job owns the protected
+// timestamp record which keeps the history of the replicated spans from being
+// garbage collected, and releases it once the consumer stops heartbeating.
+type producerJobResumer struct {
+	job      *jobs.Job
+	settings *cluster.Settings
+}
+
+var _ jobs.Resumer = (*producerJobResumer)(nil)
+
+// Resume is part of the jobs.Resumer interface.
+func (r *producerJobResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	execCfg := execCtx.(sql.JobExecContext).ExecCfg()
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	for {
+		// The consumer extends the expiration of the stream through the
+		// progress of the job, so it must be reloaded.
+		j, err := execCfg.JobRegistry.LoadJob(ctx, r.job.ID())
+		if err != nil {
+			return err
+		}
+		expiration := j.Progress().GetStreamReplication().Expiration
+		if expiration.Before(timeutil.Now()) {
+			return errors.Errorf("replication stream %d timed out", r.job.ID())
+		}
+
+		timer.Reset(livenessTrackFrequency.Get(&r.settings.SV))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			timer.Read = true
+		}
+	}
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (r *producerJobResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
+	execCfg := execCtx.(sql.JobExecContext).ExecCfg()
+	ptsID := r.job.Details().(jobspb.StreamReplicationDetails).ProtectedTimestampRecord
+	if ptsID == nil {
+		return nil
+	}
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		err := execCfg.ProtectedTimestampProvider.Release(ctx, txn, *ptsID)
+		if errors.Is(err, protectedts.ErrNotExists) {
+			// The record may have been released by a previous attempt.
+			log.Warningf(ctx, "protected timestamp record of replication stream %d not found", r.job.ID())
+			return nil
+		}
+		return err
+	})
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeStreamReplication,
+		func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
+			return &producerJobResumer{
+				job:      job,
+				settings: settings,
+			}
+		},
+		jobs.UsesTenantCostControl,
+	)
+}
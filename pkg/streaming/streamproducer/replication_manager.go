@@ -0,0 +1,93 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package streamproducer implements the source side of replication streams.
+//
+// A stream replicates the keyspace of a tenant. It is started with
+// crdb_internal.start_replication_stream() or CREATE REPLICATION STREAM, which
+// create a producer job whose ID is the ID of the stream. The consumer then
+// asks for the partitions of the stream with
+// crdb_internal.replication_stream_spec(), subscribes to each of them with
+// crdb_internal.stream_partition(), and periodically reports the timestamp up
+// to which it has ingested the stream with
+// crdb_internal.replication_stream_progress(). The producer job fails, and
+// releases the protected timestamp holding the history of the tenant, once
+// the consumer stops reporting its progress.
+package streamproducer
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/streaming"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streampb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+type replicationStreamManager struct{}
+
+var _ streaming.ReplicationStreamManager = replicationStreamManager{}
+
+// StartReplicationStream is part of the streaming.ReplicationStreamManager
+// interface.
+func (replicationStreamManager) StartReplicationStream(
+	evalCtx *tree.EvalContext, txn *kv.Txn, tenantID uint64,
+) (streampb.StreamID, error) {
+	return startReplicationStreamJob(evalCtx, txn, tenantID)
+}
+
+// HeartbeatReplicationStream is part of the
+// streaming.ReplicationStreamManager interface.
+func (replicationStreamManager) HeartbeatReplicationStream(
+	evalCtx *tree.EvalContext, streamID streampb.StreamID, frontier hlc.Timestamp, txn *kv.Txn,
+) (streampb.StreamReplicationStatus, error) {
+	return heartbeatReplicationStream(evalCtx, streamID, frontier, txn)
+}
+
+// StreamPartition is part of the streaming.ReplicationStreamManager
+// interface.
+func (replicationStreamManager) StreamPartition(
+	evalCtx *tree.EvalContext, streamID streampb.StreamID, opaqueSpec []byte,
+) (tree.ValueGenerator, error) {
+	return streamPartition(evalCtx, streamID, opaqueSpec)
+}
+
+// GetReplicationStreamSpec is part of the streaming.ReplicationStreamManager
+// interface.
+func (replicationStreamManager) GetReplicationStreamSpec(
+	evalCtx *tree.EvalContext, txn *kv.Txn, streamID streampb.StreamID,
+) (*streampb.ReplicationStreamSpec, error) {
+	return getReplicationStreamSpec(evalCtx, txn, streamID)
+}
+
+// newReplicationStreamManager checks that replication streams may be used in
+// the session of evalCtx.
+func newReplicationStreamManager(
+	evalCtx *tree.EvalContext,
+) (streaming.ReplicationStreamManager, error) {
+	if !evalCtx.Codec.ForSystemTenant() {
+		return nil, pgerror.New(pgcode.InsufficientPrivilege,
+			"replication streams can only be used by the system tenant")
+	}
+	isAdmin, err := evalCtx.SessionAccessor.HasAdminRole(evalCtx.Ctx())
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, pgerror.New(pgcode.InsufficientPrivilege,
+			"only users with the admin role can use replication streams")
+	}
+	return replicationStreamManager{}, nil
+}
+
+func init() {
+	streaming.GetReplicationStreamManagerHook = newReplicationStreamManager
+}
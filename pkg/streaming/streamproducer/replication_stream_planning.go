@@ -0,0 +1,75 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package streamproducer
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+var replicationStreamResultColumns = colinfo.ResultColumns{
+	{Name: "stream_id", Typ: types.Int},
+}
+
+// replicationStreamPlanHook implements CREATE REPLICATION STREAM FOR TENANT,
+// which starts a stream like crdb_internal.start_replication_stream(). The
+// consumer pulls the stream from the source cluster, so streams cannot be
+// pushed INTO a sink.
+func replicationStreamPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	stream, ok := stmt.(*tree.ReplicationStream)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+	if !stream.Targets.TenantID.Specified {
+		return nil, nil, nil, false, pgerror.New(pgcode.FeatureNotSupported,
+			"replication streams can only be created for tenants")
+	}
+	if stream.SinkURI != nil {
+		return nil, nil, nil, false, pgerror.New(pgcode.FeatureNotSupported,
+			"replication streams cannot be sent into a sink; "+
+				"they are pulled by RESTORE FROM REPLICATION STREAM on the destination cluster")
+	}
+	if stream.Options.Cursor != nil {
+		return nil, nil, nil, false, pgerror.New(pgcode.FeatureNotSupported,
+			"the cursor option is not supported for replication streams")
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		evalCtx := &p.ExtendedEvalContext().EvalContext
+		if _, err := newReplicationStreamManager(evalCtx); err != nil {
+			return err
+		}
+		streamID, err := startReplicationStreamJob(
+			evalCtx, p.ExtendedEvalContext().Txn, stream.Targets.TenantID.ToUint64())
+		if err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(streamID))}
+		return nil
+	}
+	return fn, replicationStreamResultColumns, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook("replication stream", replicationStreamPlanHook)
+}
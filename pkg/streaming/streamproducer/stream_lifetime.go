@@ -0,0 +1,225 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package streamproducer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobsprotectedts"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/streaming/streampb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// tenantSpan returns the span of the keyspace of a tenant.
+func tenantSpan(tenantID roachpb.TenantID) roachpb.Span {
+	prefix := keys.MakeTenantPrefix(tenantID)
+	return roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()}
+}
+
+// startReplicationStreamJob creates the producer job of a stream replicating
+// the keyspace of a tenant, and protects the history of the keyspace from the
+// statement time onwards. The ID of the job is the ID of the stream.
+func startReplicationStreamJob(
+	evalCtx *tree.EvalContext, txn *kv.Txn, tenantID uint64,
+) (streampb.StreamID, error) {
+	ctx := evalCtx.Ctx()
+	execCfg := evalCtx.Planner.ExecutorConfig().(*sql.ExecutorConfig)
+	tenant, err := sql.GetTenantRecord(ctx, execCfg, txn, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	if tenant.State != descpb.TenantInfo_ACTIVE {
+		return 0, errors.Errorf("tenant %d is not active", tenantID)
+	}
+
+	registry := execCfg.JobRegistry
+	tid := roachpb.MakeTenantID(tenantID)
+	span := tenantSpan(tid)
+	ptsID := uuid.MakeV4()
+	timeout := jobLivenessTimeout.Get(&execCfg.Settings.SV)
+	jr := jobs.Record{
+		JobID:       registry.MakeJobID(),
+		Description: fmt.Sprintf("replication stream for tenant %d", tenantID),
+		Username:    evalCtx.SessionData().User(),
+		Details: jobspb.StreamReplicationDetails{
+			Spans:                    []*roachpb.Span{&span},
+			ProtectedTimestampRecord: &ptsID,
+		},
+		Progress: jobspb.StreamReplicationProgress{
+			Expiration: timeutil.Now().Add(timeout),
+		},
+	}
+	if _, err := registry.CreateAdoptableJobWithTxn(ctx, jr, jr.JobID, txn); err != nil {
+		return 0, err
+	}
+
+	statementTime := hlc.Timestamp{WallTime: evalCtx.GetStmtTimestamp().UnixNano()}
+	rec := jobsprotectedts.MakeRecord(ptsID, int64(jr.JobID), statementTime,
+		nil /* deprecatedSpans */, jobsprotectedts.Jobs, ptpb.MakeTenantsTarget([]roachpb.TenantID{tid}))
+	if err := execCfg.ProtectedTimestampProvider.Protect(ctx, txn, rec); err != nil {
+		return 0, err
+	}
+	return streampb.StreamID(jr.JobID), nil
+}
+
+// heartbeatReplicationStream extends the expiration of a stream, and moves its
+// protected timestamp up to the frontier reported by the consumer. Since the
+// consumer resumes from its own checkpoint, which may lag behind the frontier
+// it reports, the protected timestamp never moves past the frontier.
+func heartbeatReplicationStream(
+	evalCtx *tree.EvalContext, streamID streampb.StreamID, frontier hlc.Timestamp, txn *kv.Txn,
+) (streampb.StreamReplicationStatus, error) {
+	ctx := evalCtx.Ctx()
+	execCfg := evalCtx.Planner.ExecutorConfig().(*sql.ExecutorConfig)
+	expiration := timeutil.Now().Add(jobLivenessTimeout.Get(&execCfg.Settings.SV))
+	return updateReplicationStreamProgress(ctx, execCfg, streamID, frontier, expiration, txn)
+}
+
+func updateReplicationStreamProgress(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	streamID streampb.StreamID,
+	frontier hlc.Timestamp,
+	expiration time.Time,
+	txn *kv.Txn,
+) (status streampb.StreamReplicationStatus, _ error) {
+	pts := execCfg.ProtectedTimestampProvider
+	err := execCfg.JobRegistry.UpdateJobWithTxn(ctx, jobspb.JobID(streamID), txn, false, /* useReadLock */
+		func(txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+			if md.Payload.GetStreamReplication() == nil {
+				return errors.Errorf("job %d is not a replication stream", streamID)
+			}
+			switch {
+			case md.Status == jobs.StatusRunning:
+				status.StreamStatus = streampb.StreamReplicationStatus_STREAM_ACTIVE
+			case md.Status == jobs.StatusPaused:
+				status.StreamStatus = streampb.StreamReplicationStatus_STREAM_PAUSED
+			case md.Status.Terminal():
+				status.StreamStatus = streampb.StreamReplicationStatus_STREAM_INACTIVE
+				// The protected timestamp record may already be released.
+				return nil
+			default:
+				status.StreamStatus = streampb.StreamReplicationStatus_UNKNOWN_STREAM_STATUS_RETRY
+				return nil
+			}
+
+			ptsID := *md.Payload.GetStreamReplication().ProtectedTimestampRecord
+			rec, err := pts.GetRecord(ctx, txn, ptsID)
+			if err != nil {
+				return err
+			}
+			protected := rec.Timestamp
+			if status.StreamStatus != streampb.StreamReplicationStatus_STREAM_ACTIVE {
+				status.ProtectedTimestamp = &protected
+				return nil
+			}
+			if protected.Less(frontier) {
+				if err := pts.UpdateTimestamp(ctx, txn, ptsID, frontier); err != nil {
+					return err
+				}
+				protected = frontier
+			}
+			status.ProtectedTimestamp = &protected
+			md.Progress.GetStreamReplication().Expiration = expiration
+			ju.UpdateProgress(md.Progress)
+			return nil
+		})
+	if jobs.HasJobNotFoundError(err) {
+		status.StreamStatus = streampb.StreamReplicationStatus_STREAM_INACTIVE
+		return status, nil
+	}
+	return status, err
+}
+
+// loadRunningStream returns the details of the producer job of a running
+// stream.
+func loadRunningStream(
+	ctx context.Context, execCfg *sql.ExecutorConfig, streamID streampb.StreamID, txn *kv.Txn,
+) (jobspb.StreamReplicationDetails, error) {
+	j, err := execCfg.JobRegistry.LoadJobWithTxn(ctx, jobspb.JobID(streamID), txn)
+	if err != nil {
+		return jobspb.StreamReplicationDetails{}, errors.Wrapf(err, "loading replication stream %d", streamID)
+	}
+	details, ok := j.Details().(jobspb.StreamReplicationDetails)
+	if !ok {
+		return jobspb.StreamReplicationDetails{}, errors.Errorf("job %d is not a replication stream", streamID)
+	}
+	if status := j.Status(); status != jobs.StatusRunning {
+		return jobspb.StreamReplicationDetails{}, errors.Errorf(
+			"replication stream %d is not running: %s", streamID, status)
+	}
+	return details, nil
+}
+
+// getReplicationStreamSpec partitions the spans of a stream by the nodes
+// holding their leases. Each partition is served by its node, which streams
+// the changes it observes locally.
+func getReplicationStreamSpec(
+	evalCtx *tree.EvalContext, txn *kv.Txn, streamID streampb.StreamID,
+) (*streampb.ReplicationStreamSpec, error) {
+	ctx := evalCtx.Ctx()
+	jobExecCtx := evalCtx.JobExecContext.(sql.JobExecContext)
+	execCfg := jobExecCtx.ExecCfg()
+	details, err := loadRunningStream(ctx, execCfg, streamID, txn)
+	if err != nil {
+		return nil, err
+	}
+
+	spans := make([]roachpb.Span, 0, len(details.Spans))
+	for _, span := range details.Spans {
+		spans = append(spans, *span)
+	}
+	dsp := jobExecCtx.DistSQLPlanner()
+	planCtx := dsp.NewPlanningCtx(ctx, jobExecCtx.ExtendedEvalContext(), nil, /* planner */
+		nil /* txn */, sql.DistributionTypeSystemTenantOnly)
+	spanPartitions, err := dsp.PartitionSpans(ctx, planCtx, spans)
+	if err != nil {
+		return nil, err
+	}
+
+	config := streampb.StreamPartitionSpec_ExecutionConfig{
+		MinCheckpointFrequency: minCheckpointFrequency.Get(&execCfg.Settings.SV),
+		BatchByteSize:          batchByteSize.Get(&execCfg.Settings.SV),
+	}
+	spec := &streampb.ReplicationStreamSpec{
+		Partitions: make([]streampb.ReplicationStreamSpec_Partition, 0, len(spanPartitions)),
+	}
+	for _, sp := range spanPartitions {
+		nodeDesc, err := dsp.GetSQLInstanceInfo(sp.SQLInstanceID)
+		if err != nil {
+			return nil, err
+		}
+		spec.Partitions = append(spec.Partitions, streampb.ReplicationStreamSpec_Partition{
+			ID:         strconv.Itoa(int(sp.SQLInstanceID)),
+			SQLAddress: *nodeDesc.CheckedSQLAddress(),
+			PartitionSpec: streampb.StreamPartitionSpec{
+				Spans:  sp.Spans,
+				Config: config,
+			},
+		})
+	}
+	return spec, nil
+}
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/datadriven"
@@ -65,7 +66,9 @@ func WalkWithNewServer(
 // can be used to specify types to ignore. ErrorResponse messages are
 // immediately returned as errors unless they are the expected type, in which
 // case they will marshal to an empty ErrorResponse message since our error
-// detail specifics differ from Postgres.
+// detail specifics differ from Postgres. Like in "send", CopyData messages
+// are output as strings, or as base64-encoded BinaryData if they are not
+// valid UTF-8.
 //
 // "receive": Like "until", but only output matching messages instead of all
 // messages.
@@ -257,6 +260,20 @@ func MsgsToJSONWithIgnore(msgs []pgproto3.BackendMessage, args *datadriven.TestD
 			}); err != nil {
 				panic(err)
 			}
+		} else if data, ok := msg.(*pgproto3.CopyData); ok {
+			out := struct {
+				Type       string
+				Data       string `json:",omitempty"`
+				BinaryData []byte `json:",omitempty"`
+			}{Type: "CopyData"}
+			if utf8.Valid(data.Data) {
+				out.Data = string(data.Data)
+			} else {
+				out.BinaryData = data.Data
+			}
+			if err := enc.Encode(out); err != nil {
+				panic(err)
+			}
 		} else if err := enc.Encode(msg); err != nil {
 			panic(err)
 		}
@@ -280,6 +297,8 @@ func toMessage(typ string) interface{} {
 		return &pgproto3.CopyDone{}
 	case "CopyInResponse":
 		return &pgproto3.CopyInResponse{}
+	case "CopyOutResponse":
+		return &pgproto3.CopyOutResponse{}
 	case "DataRow":
 		return &pgproto3.DataRow{}
 	case "Describe":
@@ -129,6 +129,10 @@ type Reader struct {
 	// It is set to comma (',') by NewReader.
 	Comma rune
 
+	// Quote is the character used to quote fields. It must be a single-byte
+	// character. It is set to `"` by NewReader.
+	Quote rune
+
 	// Escape is the character used to escape certain characters (e.g. `"` (Quote),
 	// `,`) and itself. It is set to `"` by NewReader.
 	Escape rune
@@ -186,6 +190,7 @@ type Reader struct {
 func NewReader(r io.Reader) *Reader {
 	return &Reader{
 		Comma:  ',',
+		Quote:  '"',
 		Escape: '"',
 		r:      bufio.NewReader(r),
 	}
@@ -270,10 +275,10 @@ func nextRune(b []byte) rune {
 }
 
 func (r *Reader) stripEscapeForReadRecord(in []byte) (ret []byte, trailingEscape bool) {
-	// Special speedup: calls to this always assume `"` escape characters should
-	// have no "s in the incoming byte array, so we can just return the byte
-	// array back.
-	if r.Escape == '"' {
+	// Special speedup: calls to this always assume that when the escape
+	// character is the quote character, there are no quotes in the incoming
+	// byte array, so we can just return the byte array back.
+	if r.Escape == r.Quote {
 		return in, false
 	}
 	ret = make([]byte, 0, len(in))
@@ -286,9 +291,9 @@ func (r *Reader) stripEscapeForReadRecord(in []byte) (ret []byte, trailingEscape
 				return ret, true
 			}
 			// Look at the next character.
-			// We only escape the escape character itself and the `"` character.
+			// We only escape the escape character itself and the quote character.
 			nextRu, nextRuLength := utf8.DecodeRune(in[next:])
-			if nextRu == r.Escape || nextRu == '"' {
+			if nextRu == r.Escape || nextRu == r.Quote {
 				curr = next
 				next = curr + nextRuLength
 			}
@@ -342,7 +347,8 @@ func (r *Reader) readRecord(dst []Record) ([]Record, error) {
 
 	// Parse each field in the record.
 	var err error
-	const quoteLen = len(`"`)
+	const quoteLen = 1
+	quote := byte(r.Quote)
 	commaLen := utf8.RuneLen(r.Comma)
 	recLine := r.numLine // Starting line for record
 	r.recordBuffer = r.recordBuffer[:0]
@@ -353,7 +359,7 @@ parseField:
 		if r.TrimLeadingSpace {
 			line = bytes.TrimLeftFunc(line, unicode.IsSpace)
 		}
-		if len(line) == 0 || line[0] != '"' {
+		if len(line) == 0 || line[0] != quote {
 			// Non-quoted string field
 			quoted = append(quoted, false)
 			i := bytes.IndexRune(line, r.Comma)
@@ -365,7 +371,7 @@ parseField:
 			}
 			// Check to make sure a quote does not appear in field.
 			if !r.LazyQuotes {
-				if j := bytes.IndexByte(field, '"'); j >= 0 {
+				if j := bytes.IndexByte(field, quote); j >= 0 {
 					col := utf8.RuneCount(fullLine[:len(fullLine)-len(line[j:])])
 					err = &ParseError{StartLine: recLine, Line: r.numLine, Column: col, Err: ErrBareQuote}
 					break parseField
@@ -383,31 +389,31 @@ parseField:
 			quoted = append(quoted, true)
 			line = line[quoteLen:]
 			for {
-				i := bytes.IndexByte(line, '"')
+				i := bytes.IndexByte(line, quote)
 				if i >= 0 {
 					// Note hasTrailingEscape is only true for escape characters that
-					// are not " - if it is ", IndexByte would guarantee there are no
-					// " characters beforehand.
+					// are not the quote character - if it is, IndexByte would
+					// guarantee there are no quote characters beforehand.
 					contents, hasTrailingEscape := r.stripEscapeForReadRecord(line[:i])
 					r.recordBuffer = append(r.recordBuffer, contents...)
 					line = line[i+quoteLen:]
-					// If we are at a `"` character, and we have a character before
-					// that is an escape character, we are hitting a single " char.
-					if r.Escape != '"' && hasTrailingEscape {
-						r.recordBuffer = append(r.recordBuffer, '"')
+					// If we are at a quote character, and we have a character before
+					// that is an escape character, we are hitting a single quote char.
+					if r.Escape != r.Quote && hasTrailingEscape {
+						r.recordBuffer = append(r.recordBuffer, quote)
 						continue
 					}
 					// Hit next quote.
 					switch rn := nextRune(line); {
-					case rn == '"':
+					case rn == r.Quote:
 						// Do not expect "" if the escape character is different.
-						if r.Escape != '"' {
+						if r.Escape != r.Quote {
 							col := utf8.RuneCount(fullLine[:len(fullLine)-len(line)-quoteLen])
 							err = &ParseError{StartLine: recLine, Line: r.numLine, Column: col, Err: ErrQuote}
 							break parseField
 						}
 						// `""` sequence (append quote).
-						r.recordBuffer = append(r.recordBuffer, '"')
+						r.recordBuffer = append(r.recordBuffer, quote)
 						line = line[quoteLen:]
 					case rn == r.Comma:
 						// `",` sequence (end of field).
@@ -420,7 +426,7 @@ parseField:
 						break parseField
 					case r.LazyQuotes:
 						// `"` sequence (bare quote).
-						r.recordBuffer = append(r.recordBuffer, '"')
+						r.recordBuffer = append(r.recordBuffer, quote)
 					default:
 						// `"*` sequence (invalid non-escaped quote).
 						col := utf8.RuneCount(fullLine[:len(fullLine)-len(line)-quoteLen])
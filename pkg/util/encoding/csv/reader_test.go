@@ -31,6 +31,7 @@ func TestRead(t *testing.T) {
 
 		// These fields are copied into the Reader
 		Comma              rune
+		Quote              rune
 		Escape             rune
 		Comment            rune
 		UseFieldsPerRecord bool // false (default) means FieldsPerRecord is -1
@@ -428,6 +429,18 @@ x,,,
 		Escape: 'x',
 		Input:  `"x"` + "\n",
 		Error:  &ParseError{StartLine: 1, Line: 2, Column: 0, Err: ErrQuote},
+	}, {
+		Name:   "QuoteText",
+		Quote:  '\'',
+		Escape: '\'',
+		Input:  `'a,b','it''s',"c"` + "\n",
+		Output: [][]Record{{Record{`a,b`, true}, Record{`it's`, true}, Record{`"c"`, false}}},
+	}, {
+		Name:   "QuoteTextWithEscape",
+		Quote:  '\'',
+		Escape: '\\',
+		Input:  `'a\'b','\\',c` + "\n",
+		Output: [][]Record{{Record{`a'b`, true}, Record{`\`, true}, Record{`c`, false}}},
 	}}
 
 	for _, tt := range tests {
@@ -437,6 +450,9 @@ x,,,
 			if tt.Comma != 0 {
 				r.Comma = tt.Comma
 			}
+			if tt.Quote != 0 {
+				r.Quote = tt.Quote
+			}
 			if tt.Escape != 0 {
 				r.Escape = tt.Escape
 			}
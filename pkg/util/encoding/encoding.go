@@ -112,6 +112,14 @@ const (
 	// Because of the context, they cannot be ambiguous with these other bytes.
 	ascendingNullWithinArrayKey  byte = 0x01
 	descendingNullWithinArrayKey byte = 0xFE
+	// Arrays whose shape differs from the default (one dimension with a lower
+	// bound of 1) have their dimensions encoded right after the array marker,
+	// preceded by this byte. It sorts after (or before, when descending) the
+	// terminator and all encoded elements, so such arrays sort after all
+	// arrays with the default shape. Like the NULL encodings above, these
+	// bytes are only ambiguous outside of an encoded array key.
+	arrayKeyDimensionsMarker           byte = 0xFF
+	arrayKeyDescendingDimensionsMarker byte = 0x00
 
 	// IntMin is chosen such that the range of int tags does not overlap the
 	// ascii character set that is frequently used in testing.
@@ -1928,6 +1936,12 @@ func prettyPrintFirstValue(dir Direction, b []byte) ([]byte, string, error) {
 		if err != nil {
 			return nil, "", err
 		}
+		if len(buf) > 0 && IsNextByteArrayKeyDimensionsMarker(buf, encDir) {
+			buf, err = prettyPrintArrayKeyDimensions(&build, buf[1:], encDir)
+			if err != nil {
+				return nil, "", err
+			}
+		}
 		build.WriteString("ARRAY[")
 		first := true
 		// Use the array key decoding logic, but instead of calling out
@@ -2088,6 +2102,34 @@ func prettyPrintFirstValue(dir Direction, b []byte) ([]byte, string, error) {
 	}
 }
 
+// prettyPrintArrayKeyDimensions writes the dimensions of an array key, which
+// follow the dimensions marker, in the [lower:upper] form used by Postgres,
+// and returns the remaining byte slice.
+func prettyPrintArrayKeyDimensions(
+	build *strings.Builder, buf []byte, dir Direction,
+) ([]byte, error) {
+	decode := DecodeVarintAscending
+	if dir == Descending {
+		decode = DecodeVarintDescending
+	}
+	buf, ndims, err := decode(buf)
+	if err != nil {
+		return nil, err
+	}
+	for i := int64(0); i < ndims; i++ {
+		var length, lowerBound int64
+		if buf, length, err = decode(buf); err != nil {
+			return nil, err
+		}
+		if buf, lowerBound, err = decode(buf); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(build, "[%d:%d]", lowerBound, lowerBound+length-1)
+	}
+	build.WriteByte('=')
+	return buf, nil
+}
+
 // UndoPrefixEnd is a partial inverse for roachpb.Key.PrefixEnd.
 //
 // In general, we can't undo PrefixEnd because it is lossy; we don't know how
@@ -3144,6 +3186,30 @@ func IsNextByteArrayEncodedNull(buf []byte, dir Direction) bool {
 	return buf[0] == expected
 }
 
+// EncodeArrayKeyDimensionsMarker adds the marker that precedes the encoded
+// dimensions of a multidimensional array key to buf and returns the new
+// buffer.
+func EncodeArrayKeyDimensionsMarker(buf []byte, dir Direction) []byte {
+	switch dir {
+	case Ascending:
+		return append(buf, arrayKeyDimensionsMarker)
+	case Descending:
+		return append(buf, arrayKeyDescendingDimensionsMarker)
+	default:
+		panic("invalid direction")
+	}
+}
+
+// IsNextByteArrayKeyDimensionsMarker returns if the first byte in the input
+// is the marker preceding the dimensions of an array key.
+func IsNextByteArrayKeyDimensionsMarker(buf []byte, dir Direction) bool {
+	expected := arrayKeyDimensionsMarker
+	if dir == Descending {
+		expected = arrayKeyDescendingDimensionsMarker
+	}
+	return buf[0] == expected
+}
+
 // ValidateAndConsumeArrayKeyMarker checks that the marker at the front
 // of buf is valid for an array of the given direction, and consumes it
 // if so. It returns an error if the tag is invalid.
@@ -0,0 +1,397 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package ldap implements a minimal LDAPv3 client (RFC 4511), sufficient
+// to authenticate users against a directory: simple bind, search and
+// StartTLS.
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"github.com/cockroachdb/cockroach/pkg/util/ldap/internal/ber"
+	"github.com/cockroachdb/errors"
+)
+
+// Protocol operation tags (RFC 4511, section 4.2 onwards). These are
+// all in the application class.
+const (
+	opBindRequest           = 0
+	opBindResponse          = 1
+	opUnbindRequest         = 2
+	opSearchRequest         = 3
+	opSearchResultEntry     = 4
+	opSearchResultDone      = 5
+	opSearchResultReference = 19
+	opExtendedRequest       = 23
+	opExtendedResponse      = 24
+)
+
+// startTLSOID is the name of the StartTLS extended operation (RFC 4511,
+// section 4.14).
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// ResultCode is an LDAP result code (RFC 4511, section 4.1.9).
+type ResultCode int
+
+// Result codes used by this package.
+const (
+	ResultSuccess            ResultCode = 0
+	ResultOperationsError    ResultCode = 1
+	ResultProtocolError      ResultCode = 2
+	ResultSizeLimitExceeded  ResultCode = 4
+	ResultNoSuchObject       ResultCode = 32
+	ResultInvalidCredentials ResultCode = 49
+	ResultUnwillingToPerform ResultCode = 53
+)
+
+func (c ResultCode) String() string {
+	switch c {
+	case ResultSuccess:
+		return "success"
+	case ResultOperationsError:
+		return "operationsError"
+	case ResultProtocolError:
+		return "protocolError"
+	case ResultSizeLimitExceeded:
+		return "sizeLimitExceeded"
+	case ResultNoSuchObject:
+		return "noSuchObject"
+	case ResultInvalidCredentials:
+		return "invalidCredentials"
+	case ResultUnwillingToPerform:
+		return "unwillingToPerform"
+	default:
+		return fmt.Sprintf("resultCode(%d)", int(c))
+	}
+}
+
+// Error is returned when an LDAP server reports that an operation
+// failed.
+type Error struct {
+	ResultCode ResultCode
+	MatchedDN  string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("LDAP error %d (%s)", int(e.ResultCode), e.ResultCode)
+	}
+	return fmt.Sprintf("LDAP error %d (%s): %s", int(e.ResultCode), e.ResultCode, e.Message)
+}
+
+// IsResultCode returns whether err is an LDAP error with the given
+// result code.
+func IsResultCode(err error, code ResultCode) bool {
+	var lErr *Error
+	return errors.As(err, &lErr) && lErr.ResultCode == code
+}
+
+// Scope is the scope of a search operation.
+type Scope int
+
+const (
+	// ScopeBaseObject limits the search to the base object.
+	ScopeBaseObject Scope = 0
+	// ScopeSingleLevel limits the search to the immediate children of the
+	// base object.
+	ScopeSingleLevel Scope = 1
+	// ScopeWholeSubtree searches the base object and all its
+	// descendants.
+	ScopeWholeSubtree Scope = 2
+)
+
+// SearchRequest describes a search operation.
+type SearchRequest struct {
+	BaseDN string
+	Scope  Scope
+	// Filter is a search filter in the string representation of RFC
+	// 4515. Values interpolated into it should be escaped with
+	// EscapeFilter.
+	Filter string
+	// Attributes lists the attributes to return. If empty, all user
+	// attributes are returned; use []string{"1.1"} to return none.
+	Attributes []string
+	// SizeLimit, if non-zero, is the maximum number of entries to
+	// return.
+	SizeLimit int
+}
+
+// Entry is an entry returned by a search.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Conn is a connection to an LDAP server. It is not safe for concurrent
+// use.
+type Conn struct {
+	addr  string
+	conn  net.Conn
+	r     *bufio.Reader
+	msgID int64
+}
+
+// Dial connects to the LDAP server at addr (host:port). If tlsConfig is
+// non-nil, the connection uses LDAP over TLS (ldaps); otherwise it is in
+// cleartext until StartTLS is called.
+func Dial(ctx context.Context, addr string, tlsConfig *tls.Config) (*Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to LDAP server %s", addr)
+	}
+	c := &Conn{addr: addr, conn: conn, r: bufio.NewReader(conn)}
+	if tlsConfig != nil {
+		if err := c.handshake(ctx, tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// StartTLS upgrades the connection to TLS using the StartTLS extended
+// operation.
+func (c *Conn) StartTLS(ctx context.Context, tlsConfig *tls.Config) error {
+	resp, err := c.roundTrip(ctx, ber.NewConstructed(ber.ClassApplication, opExtendedRequest,
+		ber.NewPrimitive(ber.ClassContext, 0, []byte(startTLSOID))), opExtendedResponse)
+	if err != nil {
+		return errors.Wrap(err, "LDAP StartTLS")
+	}
+	if err := checkResult(resp); err != nil {
+		return errors.Wrap(err, "LDAP StartTLS")
+	}
+	return c.handshake(ctx, tlsConfig)
+}
+
+func (c *Conn) handshake(ctx context.Context, tlsConfig *tls.Config) error {
+	if tlsConfig.ServerName == "" {
+		host, _, err := net.SplitHostPort(c.addr)
+		if err != nil {
+			return err
+		}
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = host
+	}
+	tlsConn := tls.Client(c.conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return errors.Wrap(err, "LDAP TLS handshake")
+	}
+	c.conn = tlsConn
+	c.r = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// Bind performs a simple bind (RFC 4513, section 5.1.3) with the given
+// DN and password. Unauthenticated binds, i.e. with a DN but no
+// password, are refused: many servers treat them as anonymous binds
+// that always succeed, which would make the password optional. An
+// anonymous bind is performed when both the DN and password are empty.
+func (c *Conn) Bind(ctx context.Context, dn, password string) error {
+	if dn != "" && password == "" {
+		return &Error{ResultCode: ResultUnwillingToPerform, Message: "unauthenticated bind refused"}
+	}
+	resp, err := c.roundTrip(ctx, ber.NewConstructed(ber.ClassApplication, opBindRequest,
+		ber.NewInteger(3),
+		ber.NewString(dn),
+		ber.NewPrimitive(ber.ClassContext, 0, []byte(password)),
+	), opBindResponse)
+	if err != nil {
+		return errors.Wrap(err, "LDAP bind")
+	}
+	return checkResult(resp)
+}
+
+// Search performs a search and returns the entries found. Search result
+// references (referrals) are ignored.
+func (c *Conn) Search(ctx context.Context, req SearchRequest) ([]Entry, error) {
+	filter, err := compileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	attrs := ber.NewSequence()
+	for _, a := range req.Attributes {
+		attrs.Children = append(attrs.Children, ber.NewString(a))
+	}
+	msgID, err := c.send(ctx, ber.NewConstructed(ber.ClassApplication, opSearchRequest,
+		ber.NewString(req.BaseDN),
+		ber.NewEnumerated(int64(req.Scope)),
+		ber.NewEnumerated(0 /* neverDerefAliases */),
+		ber.NewInteger(int64(req.SizeLimit)),
+		ber.NewInteger(0 /* timeLimit */),
+		ber.NewBool(false /* typesOnly */),
+		filter,
+		attrs,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "LDAP search")
+	}
+
+	var entries []Entry
+	for {
+		op, err := c.receive(ctx, msgID)
+		if err != nil {
+			return nil, errors.Wrap(err, "LDAP search")
+		}
+		switch {
+		case op.Is(ber.ClassApplication, opSearchResultEntry):
+			e, err := decodeEntry(op)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		case op.Is(ber.ClassApplication, opSearchResultReference):
+		case op.Is(ber.ClassApplication, opSearchResultDone):
+			if err := checkResult(op); err != nil {
+				return entries, err
+			}
+			return entries, nil
+		default:
+			return nil, errors.Newf("LDAP search: unexpected response tag %d", op.Tag)
+		}
+	}
+}
+
+// Close sends an unbind request and closes the connection.
+func (c *Conn) Close() error {
+	_, _ = c.send(context.Background(), ber.NewPrimitive(ber.ClassApplication, opUnbindRequest, nil))
+	return c.conn.Close()
+}
+
+// roundTrip sends a request and reads the single response expected for
+// it.
+func (c *Conn) roundTrip(ctx context.Context, op *ber.Packet, respTag byte) (*ber.Packet, error) {
+	msgID, err := c.send(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.receive(ctx, msgID)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Is(ber.ClassApplication, respTag) {
+		return nil, errors.Newf("unexpected response tag %d", resp.Tag)
+	}
+	return resp, nil
+}
+
+func (c *Conn) send(ctx context.Context, op *ber.Packet) (int64, error) {
+	c.msgID++
+	msg := ber.NewSequence(ber.NewInteger(c.msgID), op)
+	c.setDeadline(ctx)
+	_, err := c.conn.Write(msg.Encode(nil))
+	return c.msgID, err
+}
+
+// receive reads the next message, which must be a response to msgID,
+// and returns its protocol operation.
+func (c *Conn) receive(ctx context.Context, msgID int64) (*ber.Packet, error) {
+	c.setDeadline(ctx)
+	msg, err := ber.ReadPacket(c.r)
+	if err != nil {
+		return nil, err
+	}
+	if !msg.Is(ber.ClassUniversal, ber.TagSequence) || len(msg.Children) < 2 {
+		return nil, errors.New("malformed LDAP message")
+	}
+	id, err := msg.Children[0].Integer()
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		// An unsolicited notification; the only one defined is Notice of
+		// Disconnection.
+		return nil, errors.New("LDAP server closed the connection")
+	}
+	if id != msgID {
+		return nil, errors.Newf("unexpected LDAP message ID %d, expected %d", id, msgID)
+	}
+	return msg.Children[1], nil
+}
+
+func (c *Conn) setDeadline(ctx context.Context) {
+	deadline, _ := ctx.Deadline()
+	// A zero deadline clears any previous one.
+	_ = c.conn.SetDeadline(deadline)
+}
+
+// checkResult returns an *Error if the LDAPResult at the start of the
+// response does not indicate success.
+func checkResult(resp *ber.Packet) error {
+	code, matchedDN, message, err := decodeResult(resp)
+	if err != nil {
+		return err
+	}
+	if code != ResultSuccess {
+		return &Error{ResultCode: code, MatchedDN: matchedDN, Message: message}
+	}
+	return nil
+}
+
+func decodeResult(resp *ber.Packet) (code ResultCode, matchedDN, message string, err error) {
+	if len(resp.Children) < 3 {
+		return 0, "", "", errors.New("malformed LDAP result")
+	}
+	c, err := resp.Children[0].Integer()
+	if err != nil {
+		return 0, "", "", err
+	}
+	if matchedDN, err = resp.Children[1].Str(); err != nil {
+		return 0, "", "", err
+	}
+	if message, err = resp.Children[2].Str(); err != nil {
+		return 0, "", "", err
+	}
+	return ResultCode(c), matchedDN, message, nil
+}
+
+func decodeEntry(op *ber.Packet) (Entry, error) {
+	dnPacket, err := op.Child(0)
+	if err != nil {
+		return Entry{}, err
+	}
+	dn, err := dnPacket.Str()
+	if err != nil {
+		return Entry{}, err
+	}
+	e := Entry{DN: dn, Attributes: map[string][]string{}}
+	attrs, err := op.Child(1)
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, attr := range attrs.Children {
+		typ, err := attr.Child(0)
+		if err != nil {
+			return Entry{}, err
+		}
+		name, err := typ.Str()
+		if err != nil {
+			return Entry{}, err
+		}
+		vals, err := attr.Child(1)
+		if err != nil {
+			return Entry{}, err
+		}
+		for _, v := range vals.Children {
+			s, err := v.Str()
+			if err != nil {
+				return Entry{}, err
+			}
+			e.Attributes[name] = append(e.Attributes[name], s)
+		}
+	}
+	return e, nil
+}
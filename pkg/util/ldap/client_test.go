@@ -0,0 +1,230 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ldap
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/ldap/ldaptest"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	s, err := ldaptest.StartServer(ldaptest.ServerConfig{})
+	require.NoError(t, err)
+	defer s.Stop()
+	s.AddEntry("uid=alice,dc=example,dc=com", "", map[string][]string{
+		"uid":         {"Alice"},
+		"mail":        {"alice@example.com", "a@example.org"},
+		"objectClass": {"person", "inetOrgPerson"},
+	})
+	c, err := Dial(ctx, s.Addr(), nil /* tlsConfig */)
+	require.NoError(t, err)
+	defer c.Close()
+
+	for _, tc := range []struct {
+		filter string
+		match  bool
+		err    string
+	}{
+		{"(uid=alice)", true, ""},
+		{"(UID=ALICE)", true, ""},
+		{"(uid=bob)", false, ""},
+		{"(uid=*)", true, ""},
+		{"(cn=*)", false, ""},
+		{"(mail=*@example.org)", true, ""},
+		{"(mail=al*@*.com)", true, ""},
+		{"(mail=b*)", false, ""},
+		{"(&(objectClass=person)(uid=alice))", true, ""},
+		{"(&(objectClass=person)(uid=bob))", false, ""},
+		{"(|(uid=bob)(mail=a@example.org))", true, ""},
+		{"(!(uid=bob))", true, ""},
+		{"(uid=" + EscapeFilter("ali*") + ")", false, ""},
+		{"(uid=\\41lice)", true, ""},
+		{"uid=alice", false, "expected '('"},
+		{"(uid=alice", false, "expected ')'"},
+		{"(uid=alice))", false, "trailing"},
+		{"(&)", false, "empty filter list"},
+		{"(=alice)", false, "missing attribute"},
+		{"(uid:dn:=alice)", false, "extensible match"},
+		{"(uid=\\4)", false, "invalid escape"},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			if tc.err != "" {
+				err := ParseFilter(tc.filter)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, ParseFilter(tc.filter))
+			// The encoded filter is evaluated by the test server.
+			entries, err := c.Search(ctx, SearchRequest{
+				BaseDN: "dc=example,dc=com", Scope: ScopeWholeSubtree, Filter: tc.filter,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.match, len(entries) == 1)
+		})
+	}
+
+	require.Equal(t, `a\2ab\28c\29d\5c`, EscapeFilter(`a*b(c)d\`))
+}
+
+// makeTestTLSConfigs returns a server TLS configuration with a
+// self-signed certificate for 127.0.0.1, and a client configuration
+// that trusts it.
+func makeTestTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ldap test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client = &tls.Config{RootCAs: pool}
+	return server, client
+}
+
+func TestClient(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	serverTLS, clientTLS := makeTestTLSConfigs(t)
+
+	populate := func(s *ldaptest.Server) {
+		s.AddEntry("cn=admin,dc=example,dc=com", "adminpw", nil)
+		s.AddEntry("ou=people,dc=example,dc=com", "", map[string][]string{"ou": {"people"}})
+		s.AddEntry("uid=alice,ou=people,dc=example,dc=com", "alicepw", map[string][]string{
+			"uid": {"alice"}, "objectClass": {"person"}, "mail": {"alice@example.com"},
+		})
+		s.AddEntry("uid=bob,ou=people,dc=example,dc=com", "bobpw", map[string][]string{
+			"uid": {"bob"}, "objectClass": {"person"},
+		})
+	}
+
+	run := func(t *testing.T, c *Conn) {
+		// Binds.
+		require.NoError(t, c.Bind(ctx, "uid=alice,ou=people,dc=example,dc=com", "alicepw"))
+		err := c.Bind(ctx, "uid=alice,ou=people,dc=example,dc=com", "wrong")
+		require.True(t, IsResultCode(err, ResultInvalidCredentials), "%v", err)
+		err = c.Bind(ctx, "uid=alice,ou=people,dc=example,dc=com", "")
+		require.True(t, IsResultCode(err, ResultUnwillingToPerform), "%v", err)
+		require.NoError(t, c.Bind(ctx, "", ""))
+
+		// Searches.
+		require.NoError(t, c.Bind(ctx, "cn=admin,dc=example,dc=com", "adminpw"))
+		entries, err := c.Search(ctx, SearchRequest{
+			BaseDN: "dc=example,dc=com",
+			Scope:  ScopeWholeSubtree,
+			Filter: "(&(objectClass=person)(uid=alice))",
+		})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "uid=alice,ou=people,dc=example,dc=com", entries[0].DN)
+		require.Equal(t, []string{"alice@example.com"}, entries[0].Attributes["mail"])
+
+		entries, err = c.Search(ctx, SearchRequest{
+			BaseDN:     "ou=people,dc=example,dc=com",
+			Scope:      ScopeSingleLevel,
+			Filter:     "(objectClass=person)",
+			Attributes: []string{"uid"},
+		})
+		require.NoError(t, err)
+		var uids []string
+		for _, e := range entries {
+			require.NotContains(t, e.Attributes, "mail")
+			uids = append(uids, e.Attributes["uid"]...)
+		}
+		sort.Strings(uids)
+		require.Equal(t, []string{"alice", "bob"}, uids)
+
+		entries, err = c.Search(ctx, SearchRequest{
+			BaseDN: "dc=example,dc=com", Scope: ScopeBaseObject, Filter: "(objectClass=person)",
+		})
+		require.NoError(t, err)
+		require.Len(t, entries, 0)
+
+		_, err = c.Search(ctx, SearchRequest{
+			BaseDN: "dc=example,dc=com", Scope: ScopeWholeSubtree, Filter: "(objectClass=*)", SizeLimit: 1,
+		})
+		require.True(t, IsResultCode(err, ResultSizeLimitExceeded), "%v", err)
+	}
+
+	t.Run("cleartext", func(t *testing.T) {
+		s, err := ldaptest.StartServer(ldaptest.ServerConfig{})
+		require.NoError(t, err)
+		defer s.Stop()
+		populate(s)
+		c, err := Dial(ctx, s.Addr(), nil /* tlsConfig */)
+		require.NoError(t, err)
+		defer c.Close()
+		run(t, c)
+	})
+
+	t.Run("starttls", func(t *testing.T) {
+		s, err := ldaptest.StartServer(ldaptest.ServerConfig{TLSConfig: serverTLS, RequireTLS: true})
+		require.NoError(t, err)
+		defer s.Stop()
+		populate(s)
+		c, err := Dial(ctx, s.Addr(), nil /* tlsConfig */)
+		require.NoError(t, err)
+		defer c.Close()
+		// The server refuses binds until TLS is set up.
+		err = c.Bind(ctx, "uid=alice,ou=people,dc=example,dc=com", "alicepw")
+		require.True(t, IsResultCode(err, ldaptest.ResultConfidentialityRequired), "%v", err)
+		require.NoError(t, c.StartTLS(ctx, clientTLS))
+		run(t, c)
+	})
+
+	t.Run("ldaps", func(t *testing.T) {
+		s, err := ldaptest.StartServer(ldaptest.ServerConfig{TLSConfig: serverTLS, LDAPS: true})
+		require.NoError(t, err)
+		defer s.Stop()
+		populate(s)
+		c, err := Dial(ctx, s.Addr(), clientTLS)
+		require.NoError(t, err)
+		defer c.Close()
+		run(t, c)
+
+		// The server certificate is verified.
+		_, err = Dial(ctx, s.Addr(), &tls.Config{})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,228 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ldap
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/ldap/internal/ber"
+	"github.com/cockroachdb/errors"
+)
+
+// Filter choice tags (RFC 4511, section 4.5.1.7).
+const (
+	filterAnd            = 0
+	filterOr             = 1
+	filterNot            = 2
+	filterEqualityMatch  = 3
+	filterSubstrings     = 4
+	filterGreaterOrEqual = 5
+	filterLessOrEqual    = 6
+	filterPresent        = 7
+	filterApproxMatch    = 8
+)
+
+// Substring choice tags.
+const (
+	substringInitial = 0
+	substringAny     = 1
+	substringFinal   = 2
+)
+
+// EscapeFilter escapes a string for safe inclusion as an assertion
+// value in a search filter, as described in RFC 4515, section 3.
+func EscapeFilter(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '*', '(', ')', '\\', 0:
+			sb.WriteByte('\\')
+			sb.WriteString(hex.EncodeToString([]byte{c}))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// ParseFilter checks the syntax of a search filter in the string
+// representation of RFC 4515. Extensible match filters are not
+// supported.
+func ParseFilter(filter string) error {
+	_, err := compileFilter(filter)
+	return err
+}
+
+// compileFilter converts a search filter in its string representation
+// to its BER encoding.
+func compileFilter(filter string) (*ber.Packet, error) {
+	p := filterParser{s: filter}
+	f, err := p.parseFilter()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid LDAP filter %q", filter)
+	}
+	if p.pos != len(p.s) {
+		return nil, errors.Newf("invalid LDAP filter %q: unexpected trailing characters", filter)
+	}
+	return f, nil
+}
+
+type filterParser struct {
+	s   string
+	pos int
+}
+
+func (p *filterParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *filterParser) expect(c byte) error {
+	if p.pos >= len(p.s) || p.s[p.pos] != c {
+		return errors.Newf("expected %q at position %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseFilter() (*ber.Packet, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var f *ber.Packet
+	var err error
+	switch p.peek() {
+	case '&', '|':
+		tag := byte(filterAnd)
+		if p.peek() == '|' {
+			tag = filterOr
+		}
+		p.pos++
+		f = ber.NewConstructed(ber.ClassContext, tag)
+		for p.peek() == '(' {
+			var c *ber.Packet
+			if c, err = p.parseFilter(); err != nil {
+				return nil, err
+			}
+			f.Children = append(f.Children, c)
+		}
+		if len(f.Children) == 0 {
+			return nil, errors.Newf("empty filter list at position %d", p.pos)
+		}
+	case '!':
+		p.pos++
+		var c *ber.Packet
+		if c, err = p.parseFilter(); err != nil {
+			return nil, err
+		}
+		f = ber.NewConstructed(ber.ClassContext, filterNot, c)
+	default:
+		if f, err = p.parseItem(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (p *filterParser) parseItem() (*ber.Packet, error) {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune("=~<>()", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	attr := p.s[start:p.pos]
+	if attr == "" {
+		return nil, errors.Newf("missing attribute description at position %d", start)
+	}
+	if strings.ContainsRune(attr, ':') {
+		return nil, errors.New("extensible match filters are not supported")
+	}
+	tag := byte(filterEqualityMatch)
+	switch p.peek() {
+	case '~':
+		tag = filterApproxMatch
+		p.pos++
+	case '>':
+		tag = filterGreaterOrEqual
+		p.pos++
+	case '<':
+		tag = filterLessOrEqual
+		p.pos++
+	}
+	if err := p.expect('='); err != nil {
+		return nil, err
+	}
+	start = p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != ')' && p.s[p.pos] != '(' {
+		p.pos++
+	}
+	raw := p.s[start:p.pos]
+
+	if tag != filterEqualityMatch || !strings.Contains(raw, "*") {
+		value, err := unescapeFilterValue(raw)
+		if err != nil {
+			return nil, err
+		}
+		return ber.NewConstructed(ber.ClassContext, tag, ber.NewString(attr), ber.NewString(value)), nil
+	}
+	if raw == "*" {
+		return ber.NewPrimitive(ber.ClassContext, filterPresent, []byte(attr)), nil
+	}
+
+	// Substring filter.
+	parts := strings.Split(raw, "*")
+	subs := ber.NewSequence()
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		value, err := unescapeFilterValue(part)
+		if err != nil {
+			return nil, err
+		}
+		subTag := byte(substringAny)
+		if i == 0 {
+			subTag = substringInitial
+		} else if i == len(parts)-1 {
+			subTag = substringFinal
+		}
+		subs.Children = append(subs.Children, ber.NewPrimitive(ber.ClassContext, subTag, []byte(value)))
+	}
+	return ber.NewConstructed(ber.ClassContext, filterSubstrings, ber.NewString(attr), subs), nil
+}
+
+func unescapeFilterValue(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i+3 > len(s) {
+			return "", errors.Newf("invalid escape sequence in %q", s)
+		}
+		b, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", errors.Newf("invalid escape sequence in %q", s)
+		}
+		sb.Write(b)
+		i += 2
+	}
+	return sb.String(), nil
+}
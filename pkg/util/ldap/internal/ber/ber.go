@@ -0,0 +1,307 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package ber contains a minimal encoder and decoder for the subset of
+// the ASN.1 Basic Encoding Rules (BER) used by LDAPv3 (RFC 4511,
+// section 5.1). Only definite-length encodings and tag numbers below 31
+// are supported, which is all that LDAP requires.
+//
+// encoding/asn1 is not used because it only accepts DER, and common
+// LDAP servers (e.g. OpenLDAP) use non-minimal length encodings.
+package ber
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Class is the class of a BER tag.
+type Class byte
+
+// Tag classes.
+const (
+	ClassUniversal   Class = 0x00
+	ClassApplication Class = 0x40
+	ClassContext     Class = 0x80
+)
+
+// Universal tag numbers.
+const (
+	TagBoolean     = 0x01
+	TagInteger     = 0x02
+	TagOctetString = 0x04
+	TagEnumerated  = 0x0a
+	TagSequence    = 0x10
+	TagSet         = 0x11
+)
+
+const (
+	berConstructed = 0x20
+	berTagMask     = 0x1f
+	berClassMask   = 0xc0
+
+	// maxPacketSize bounds the size of a single LDAP message, to
+	// protect against malicious or broken peers.
+	maxPacketSize = 16 << 20
+)
+
+// Packet is a decoded BER element. Constructed elements have children;
+// primitive elements have a value.
+type Packet struct {
+	Class       Class
+	Constructed bool
+	Tag         byte
+	Value       []byte
+	Children    []*Packet
+}
+
+// NewConstructed returns a constructed packet with the given children.
+func NewConstructed(class Class, tag byte, children ...*Packet) *Packet {
+	return &Packet{Class: class, Constructed: true, Tag: tag, Children: children}
+}
+
+// NewSequence returns a SEQUENCE with the given children.
+func NewSequence(children ...*Packet) *Packet {
+	return NewConstructed(ClassUniversal, TagSequence, children...)
+}
+
+// NewSet returns a SET with the given children.
+func NewSet(children ...*Packet) *Packet {
+	return NewConstructed(ClassUniversal, TagSet, children...)
+}
+
+// NewPrimitive returns a primitive packet with the given value.
+func NewPrimitive(class Class, tag byte, value []byte) *Packet {
+	return &Packet{Class: class, Tag: tag, Value: value}
+}
+
+// NewString returns an OCTET STRING.
+func NewString(s string) *Packet {
+	return NewPrimitive(ClassUniversal, TagOctetString, []byte(s))
+}
+
+// NewBool returns a BOOLEAN.
+func NewBool(b bool) *Packet {
+	v := byte(0)
+	if b {
+		v = 0xff
+	}
+	return NewPrimitive(ClassUniversal, TagBoolean, []byte{v})
+}
+
+// NewInteger returns an INTEGER.
+func NewInteger(i int64) *Packet {
+	return NewPrimitive(ClassUniversal, TagInteger, encodeInteger(i))
+}
+
+// NewEnumerated returns an ENUMERATED.
+func NewEnumerated(i int64) *Packet {
+	return NewPrimitive(ClassUniversal, TagEnumerated, encodeInteger(i))
+}
+
+// Is returns whether the packet has the given class and tag.
+func (p *Packet) Is(class Class, tag byte) bool {
+	return p.Class == class && p.Tag == tag
+}
+
+// Child returns the i-th child of a constructed packet, or an error if
+// there is no such child.
+func (p *Packet) Child(i int) (*Packet, error) {
+	if !p.Constructed || i >= len(p.Children) {
+		return nil, errors.Newf("malformed LDAP message: missing element %d", i)
+	}
+	return p.Children[i], nil
+}
+
+// Str returns the value of a primitive packet as a string.
+func (p *Packet) Str() (string, error) {
+	if p.Constructed {
+		return "", errors.New("malformed LDAP message: expected string")
+	}
+	return string(p.Value), nil
+}
+
+// Integer returns the value of an INTEGER or ENUMERATED packet.
+func (p *Packet) Integer() (int64, error) {
+	if p.Constructed || len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, errors.New("malformed LDAP message: expected integer")
+	}
+	// Sign-extend from the first byte.
+	i := int64(int8(p.Value[0]))
+	for _, b := range p.Value[1:] {
+		i = i<<8 | int64(b)
+	}
+	return i, nil
+}
+
+// Boolean returns the value of a BOOLEAN packet.
+func (p *Packet) Boolean() (bool, error) {
+	if p.Constructed || len(p.Value) != 1 {
+		return false, errors.New("malformed LDAP message: expected boolean")
+	}
+	return p.Value[0] != 0, nil
+}
+
+func encodeInteger(i int64) []byte {
+	n := 1
+	for v := i; v > 127 || v < -128; v >>= 8 {
+		n++
+	}
+	b := make([]byte, n)
+	for j := n - 1; j >= 0; j-- {
+		b[j] = byte(i)
+		i >>= 8
+	}
+	return b
+}
+
+// Encode appends the BER encoding of the packet to buf.
+func (p *Packet) Encode(buf []byte) []byte {
+	var content []byte
+	if p.Constructed {
+		for _, c := range p.Children {
+			content = c.Encode(content)
+		}
+	} else {
+		content = p.Value
+	}
+	id := byte(p.Class) | p.Tag
+	if p.Constructed {
+		id |= berConstructed
+	}
+	buf = append(buf, id)
+	buf = appendLength(buf, len(content))
+	return append(buf, content...)
+}
+
+func appendLength(buf []byte, l int) []byte {
+	if l < 0x80 {
+		return append(buf, byte(l))
+	}
+	n := 0
+	for v := l; v > 0; v >>= 8 {
+		n++
+	}
+	buf = append(buf, 0x80|byte(n))
+	for j := n - 1; j >= 0; j-- {
+		buf = append(buf, byte(l>>(8*j)))
+	}
+	return buf
+}
+
+// ReadPacket reads and decodes a single BER element from r.
+func ReadPacket(r *bufio.Reader) (*Packet, error) {
+	id, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	l, err := readLength(r)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]byte, l)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	return decodeContent(id, content)
+}
+
+// DecodePacket decodes a single BER element, which must span all of b.
+func DecodePacket(b []byte) (*Packet, error) {
+	p, rest, err := decodeOne(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("malformed LDAP message: trailing data")
+	}
+	return p, nil
+}
+
+func decodeOne(b []byte) (*Packet, []byte, error) {
+	if len(b) < 2 {
+		return nil, nil, errors.New("malformed LDAP message: truncated element")
+	}
+	id := b[0]
+	b = b[1:]
+	var l int
+	if b[0] < 0x80 {
+		l = int(b[0])
+		b = b[1:]
+	} else {
+		n := int(b[0] & 0x7f)
+		b = b[1:]
+		if n == 0 || n > 4 || len(b) < n {
+			return nil, nil, errors.New("malformed LDAP message: unsupported length encoding")
+		}
+		for _, c := range b[:n] {
+			l = l<<8 | int(c)
+		}
+		b = b[n:]
+	}
+	if l > len(b) {
+		return nil, nil, errors.New("malformed LDAP message: truncated element")
+	}
+	p, err := decodeContent(id, b[:l])
+	return p, b[l:], err
+}
+
+func decodeContent(id byte, content []byte) (*Packet, error) {
+	if id&berTagMask == berTagMask {
+		return nil, errors.New("malformed LDAP message: unsupported high tag number")
+	}
+	p := &Packet{
+		Class:       Class(id & berClassMask),
+		Constructed: id&berConstructed != 0,
+		Tag:         id & berTagMask,
+	}
+	if !p.Constructed {
+		p.Value = content
+		return p, nil
+	}
+	for len(content) > 0 {
+		var c *Packet
+		var err error
+		c, content, err = decodeOne(content)
+		if err != nil {
+			return nil, err
+		}
+		p.Children = append(p.Children, c)
+	}
+	return p, nil
+}
+
+func readLength(r *bufio.Reader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b < 0x80 {
+		return int(b), nil
+	}
+	n := int(b & 0x7f)
+	if n == 0 || n > 4 {
+		return 0, errors.New("malformed LDAP message: unsupported length encoding")
+	}
+	l := 0
+	for i := 0; i < n; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		l = l<<8 | int(b)
+	}
+	if l > maxPacketSize {
+		return 0, errors.Newf("LDAP message too large: %d bytes", l)
+	}
+	return l, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ber
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBEREncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, i := range []int64{0, 1, 127, 128, 255, 256, -1, -128, -129, 1 << 40} {
+		p, err := DecodePacket(NewInteger(i).Encode(nil))
+		require.NoError(t, err)
+		v, err := p.Integer()
+		require.NoError(t, err)
+		require.Equal(t, i, v)
+	}
+
+	// Long lengths round-trip, including through the streaming reader.
+	long := string(bytes.Repeat([]byte("x"), 1000))
+	msg := NewSequence(NewInteger(7), NewConstructed(ClassApplication, 0,
+		NewString(long), NewBool(true), NewPrimitive(ClassContext, 0, nil)))
+	b := msg.Encode(nil)
+	p, err := ReadPacket(bufio.NewReader(bytes.NewReader(b)))
+	require.NoError(t, err)
+	require.Equal(t, b, p.Encode(nil))
+	op, err := p.Child(1)
+	require.NoError(t, err)
+	require.True(t, op.Is(ClassApplication, 0))
+	s, err := op.Children[0].Str()
+	require.NoError(t, err)
+	require.Equal(t, long, s)
+
+	// Non-minimal length encodings, as produced by some servers, are
+	// accepted.
+	p, err = DecodePacket([]byte{0x04, 0x84, 0, 0, 0, 2, 'h', 'i'})
+	require.NoError(t, err)
+	require.Equal(t, []byte("hi"), p.Value)
+
+	_, err = DecodePacket([]byte{0x30, 0x05, 0x04, 0x01})
+	require.Error(t, err)
+}
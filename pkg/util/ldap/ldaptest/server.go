@@ -0,0 +1,447 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package ldaptest provides an in-process LDAP server for tests.
+package ldaptest
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/util/ldap/internal/ber"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// Protocol operation tags (RFC 4511, section 4.2 onwards).
+const (
+	opBindRequest       = 0
+	opBindResponse      = 1
+	opUnbindRequest     = 2
+	opSearchRequest     = 3
+	opSearchResultEntry = 4
+	opSearchResultDone  = 5
+	opExtendedRequest   = 23
+	opExtendedResponse  = 24
+)
+
+// startTLSOID is the name of the StartTLS extended operation.
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// Filter choice tags (RFC 4511, section 4.5.1.7).
+const (
+	filterAnd           = 0
+	filterOr            = 1
+	filterNot           = 2
+	filterEqualityMatch = 3
+	filterSubstrings    = 4
+	filterPresent       = 7
+)
+
+// Substring choice tags.
+const (
+	substringInitial = 0
+	substringAny     = 1
+	substringFinal   = 2
+)
+
+// Search scopes.
+const (
+	scopeBaseObject  = 0
+	scopeSingleLevel = 1
+)
+
+// Result codes (RFC 4511, section 4.1.9) returned by the server. They
+// are untyped so that they can be compared to an ldap.ResultCode.
+const (
+	resultSuccess           = 0
+	resultProtocolError     = 2
+	resultSizeLimitExceeded = 4
+	// ResultConfidentialityRequired is returned by the server when TLS
+	// is required but the client has not set it up.
+	ResultConfidentialityRequired = 13
+	resultInvalidCredentials      = 49
+)
+
+// ServerConfig configures a Server.
+type ServerConfig struct {
+	// TLSConfig, if set, enables StartTLS, or LDAP over TLS if LDAPS is
+	// set.
+	TLSConfig *tls.Config
+	// LDAPS makes the server expect TLS from the start of the connection.
+	LDAPS bool
+	// RequireTLS makes the server refuse binds over cleartext
+	// connections.
+	RequireTLS bool
+}
+
+// Server is an in-process LDAP server suitable for tests. It serves
+// a flat, in-memory directory and supports simple binds, searches and
+// StartTLS.
+type Server struct {
+	cfg ServerConfig
+	ln  net.Listener
+	wg  sync.WaitGroup
+
+	mu struct {
+		syncutil.Mutex
+		entries map[string]testEntry
+		conns   map[net.Conn]struct{}
+		binds   int
+	}
+}
+
+type testEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// StartServer starts a Server listening on a local port.
+func StartServer(cfg ServerConfig) (*Server, error) {
+	if cfg.LDAPS && cfg.TLSConfig == nil {
+		return nil, errors.New("LDAPS requires a TLS configuration")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LDAPS {
+		ln = tls.NewListener(ln, cfg.TLSConfig)
+	}
+	s := &Server{cfg: cfg, ln: ln}
+	s.mu.entries = map[string]testEntry{}
+	s.mu.conns = map[net.Conn]struct{}{}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the host:port address of the server.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// AddEntry adds an entry to the directory. If password is non-empty,
+// the entry can be used to bind.
+func (s *Server) AddEntry(dn, password string, attrs map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.entries[normalizeDN(dn)] = testEntry{dn: dn, password: password, attrs: attrs}
+}
+
+// Binds returns the number of bind requests received so far.
+func (s *Server) Binds() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.binds
+}
+
+// Stop shuts down the server and closes all connections.
+func (s *Server) Stop() {
+	_ = s.ln.Close()
+	s.mu.Lock()
+	for c := range s.mu.conns {
+		_ = c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.mu.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+func (s *Server) serveConn(rawConn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.mu.conns, rawConn)
+		s.mu.Unlock()
+		_ = rawConn.Close()
+	}()
+	conn := rawConn
+	secure := s.cfg.LDAPS
+	r := bufio.NewReader(conn)
+	write := func(msgID int64, op *ber.Packet) bool {
+		_, err := conn.Write(ber.NewSequence(ber.NewInteger(msgID), op).Encode(nil))
+		return err == nil
+	}
+	result := func(tag byte, code int, message string) *ber.Packet {
+		return ber.NewConstructed(ber.ClassApplication, tag,
+			ber.NewEnumerated(int64(code)), ber.NewString(""), ber.NewString(message))
+	}
+
+	for {
+		msg, err := ber.ReadPacket(r)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		msgID, err := msg.Children[0].Integer()
+		if err != nil {
+			return
+		}
+		op := msg.Children[1]
+		switch {
+		case op.Is(ber.ClassApplication, opUnbindRequest):
+			return
+
+		case op.Is(ber.ClassApplication, opBindRequest):
+			s.mu.Lock()
+			s.mu.binds++
+			s.mu.Unlock()
+			if s.cfg.RequireTLS && !secure {
+				if !write(msgID, result(opBindResponse, ResultConfidentialityRequired, "TLS required")) {
+					return
+				}
+				continue
+			}
+			code, message := s.bind(op)
+			if !write(msgID, result(opBindResponse, code, message)) {
+				return
+			}
+
+		case op.Is(ber.ClassApplication, opSearchRequest):
+			entries, code, message := s.search(op)
+			for _, e := range entries {
+				if !write(msgID, e) {
+					return
+				}
+			}
+			if !write(msgID, result(opSearchResultDone, code, message)) {
+				return
+			}
+
+		case op.Is(ber.ClassApplication, opExtendedRequest):
+			name := ""
+			if len(op.Children) > 0 {
+				name = string(op.Children[0].Value)
+			}
+			if name != startTLSOID || s.cfg.TLSConfig == nil || secure {
+				if !write(msgID, result(opExtendedResponse, resultProtocolError, "unsupported operation")) {
+					return
+				}
+				continue
+			}
+			if !write(msgID, result(opExtendedResponse, resultSuccess, "")) {
+				return
+			}
+			tlsConn := tls.Server(conn, s.cfg.TLSConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, secure = tlsConn, bufio.NewReader(tlsConn), true
+
+		default:
+			// Unsupported operations get a protocol error; there is no
+			// generic response so we use an extended response.
+			if !write(msgID, result(opExtendedResponse, resultProtocolError, "unsupported operation")) {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) bind(op *ber.Packet) (int, string) {
+	if len(op.Children) < 3 {
+		return resultProtocolError, "malformed bind request"
+	}
+	dn, _ := op.Children[1].Str()
+	auth := op.Children[2]
+	if !auth.Is(ber.ClassContext, 0) {
+		return resultProtocolError, "only simple binds are supported"
+	}
+	password := string(auth.Value)
+	if dn == "" && password == "" {
+		// Anonymous bind.
+		return resultSuccess, ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.mu.entries[normalizeDN(dn)]
+	if !ok || e.password == "" || e.password != password {
+		return resultInvalidCredentials, "invalid credentials"
+	}
+	return resultSuccess, ""
+}
+
+func (s *Server) search(op *ber.Packet) ([]*ber.Packet, int, string) {
+	if len(op.Children) < 8 {
+		return nil, resultProtocolError, "malformed search request"
+	}
+	base, _ := op.Children[0].Str()
+	scope, _ := op.Children[1].Integer()
+	sizeLimit, _ := op.Children[3].Integer()
+	filter := op.Children[6]
+	var requested []string
+	for _, a := range op.Children[7].Children {
+		requested = append(requested, string(a.Value))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	base = normalizeDN(base)
+	var results []*ber.Packet
+	for key, e := range s.mu.entries {
+		if !inScope(key, base, scope) || !matchFilter(filter, e.attrs) {
+			continue
+		}
+		if sizeLimit > 0 && int64(len(results)) >= sizeLimit {
+			return results, resultSizeLimitExceeded, ""
+		}
+		attrs := ber.NewSequence()
+		for name, vals := range e.attrs {
+			if !wantAttr(requested, name) {
+				continue
+			}
+			set := ber.NewSet()
+			for _, v := range vals {
+				set.Children = append(set.Children, ber.NewString(v))
+			}
+			attrs.Children = append(attrs.Children, ber.NewSequence(ber.NewString(name), set))
+		}
+		results = append(results, ber.NewConstructed(ber.ClassApplication, opSearchResultEntry,
+			ber.NewString(e.dn), attrs))
+	}
+	return results, resultSuccess, ""
+}
+
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(parts[i]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func inScope(dn, base string, scope int64) bool {
+	switch scope {
+	case scopeBaseObject:
+		return dn == base
+	case scopeSingleLevel:
+		i := strings.IndexByte(dn, ',')
+		return i >= 0 && dn[i+1:] == base
+	default:
+		return dn == base || base == "" || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+func wantAttr(requested []string, name string) bool {
+	if len(requested) == 0 {
+		return true
+	}
+	for _, r := range requested {
+		if strings.EqualFold(r, name) || r == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func attrValues(attrs map[string][]string, name string) []string {
+	for k, v := range attrs {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// matchFilter evaluates an encoded filter against an entry's attributes.
+// Comparisons are case-insensitive. Ordering and approximate matches are
+// not supported and never match.
+func matchFilter(f *ber.Packet, attrs map[string][]string) bool {
+	if f.Class != ber.ClassContext {
+		return false
+	}
+	switch f.Tag {
+	case filterAnd:
+		for _, c := range f.Children {
+			if !matchFilter(c, attrs) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, c := range f.Children {
+			if matchFilter(c, attrs) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return len(f.Children) == 1 && !matchFilter(f.Children[0], attrs)
+	case filterPresent:
+		return len(attrValues(attrs, string(f.Value))) > 0
+	case filterEqualityMatch:
+		if len(f.Children) != 2 {
+			return false
+		}
+		want := string(f.Children[1].Value)
+		for _, v := range attrValues(attrs, string(f.Children[0].Value)) {
+			if strings.EqualFold(v, want) {
+				return true
+			}
+		}
+		return false
+	case filterSubstrings:
+		if len(f.Children) != 2 {
+			return false
+		}
+		for _, v := range attrValues(attrs, string(f.Children[0].Value)) {
+			if matchSubstrings(strings.ToLower(v), f.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func matchSubstrings(v string, subs []*ber.Packet) bool {
+	for _, sub := range subs {
+		s := strings.ToLower(string(sub.Value))
+		switch sub.Tag {
+		case substringInitial:
+			if !strings.HasPrefix(v, s) {
+				return false
+			}
+			v = v[len(s):]
+		case substringAny:
+			i := strings.Index(v, s)
+			if i < 0 {
+				return false
+			}
+			v = v[i+len(s):]
+		case substringFinal:
+			if !strings.HasSuffix(v, s) {
+				return false
+			}
+		}
+	}
+	return true
+}